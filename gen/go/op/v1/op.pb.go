@@ -15,6 +15,7 @@ package opv1
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -204,6 +205,55 @@ func (Status) EnumDescriptor() ([]byte, []int) {
 	return file_op_v1_op_proto_rawDescGZIP(), []int{2}
 }
 
+type InvokeChunk_Stream int32
+
+const (
+	InvokeChunk_STREAM_UNSPECIFIED InvokeChunk_Stream = 0
+	InvokeChunk_STDOUT             InvokeChunk_Stream = 1
+	InvokeChunk_STDERR             InvokeChunk_Stream = 2
+)
+
+// Enum value maps for InvokeChunk_Stream.
+var (
+	InvokeChunk_Stream_name = map[int32]string{
+		0: "STREAM_UNSPECIFIED",
+		1: "STDOUT",
+		2: "STDERR",
+	}
+	InvokeChunk_Stream_value = map[string]int32{
+		"STREAM_UNSPECIFIED": 0,
+		"STDOUT":             1,
+		"STDERR":             2,
+	}
+)
+
+func (x InvokeChunk_Stream) Enum() *InvokeChunk_Stream {
+	p := new(InvokeChunk_Stream)
+	*p = x
+	return p
+}
+
+func (x InvokeChunk_Stream) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (InvokeChunk_Stream) Descriptor() protoreflect.EnumDescriptor {
+	return file_op_v1_op_proto_enumTypes[3].Descriptor()
+}
+
+func (InvokeChunk_Stream) Type() protoreflect.EnumType {
+	return &file_op_v1_op_proto_enumTypes[3]
+}
+
+func (x InvokeChunk_Stream) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use InvokeChunk_Stream.Descriptor instead.
+func (InvokeChunk_Stream) EnumDescriptor() ([]byte, []int) {
+	return file_op_v1_op_proto_rawDescGZIP(), []int{6, 0}
+}
+
 // HolonIdentity is the complete civil status of a holon.
 type HolonIdentity struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -367,7 +417,18 @@ type DiscoverRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Directory to scan. Defaults to the current working directory.
 	// @example "./organic-programming"
-	RootDir       string `protobuf:"bytes,1,opt,name=root_dir,json=rootDir,proto3" json:"root_dir,omitempty"`
+	RootDir string `protobuf:"bytes,1,opt,name=root_dir,json=rootDir,proto3" json:"root_dir,omitempty"`
+	// Keep only holons in one of these languages. Values are ORed within a
+	// filter and the filters are ANDed; an empty filter keeps every holon.
+	// Matching ignores case.
+	// @example ["go"]
+	Langs []string `protobuf:"bytes,2,rep,name=langs,proto3" json:"langs,omitempty"`
+	// Keep only holons of one of these clades, as holon.yaml spells them.
+	// @example ["deterministic/pure"]
+	Clades []string `protobuf:"bytes,3,rep,name=clades,proto3" json:"clades,omitempty"`
+	// Keep only holons with one of these statuses, as holon.yaml spells them.
+	// @example ["stable"]
+	Statuses      []string `protobuf:"bytes,4,rep,name=statuses,proto3" json:"statuses,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -409,6 +470,27 @@ func (x *DiscoverRequest) GetRootDir() string {
 	return ""
 }
 
+func (x *DiscoverRequest) GetLangs() []string {
+	if x != nil {
+		return x.Langs
+	}
+	return nil
+}
+
+func (x *DiscoverRequest) GetClades() []string {
+	if x != nil {
+		return x.Clades
+	}
+	return nil
+}
+
+func (x *DiscoverRequest) GetStatuses() []string {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
 type DiscoverResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Holons discovered under the requested root and cache.
@@ -534,9 +616,31 @@ type InvokeRequest struct {
 	Holon string `protobuf:"bytes,1,opt,name=holon,proto3" json:"holon,omitempty"`
 	// Arguments passed to the holon command.
 	// @example ["build","./cmd/rob-go"]
-	Args          []string `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	Args []string `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	// ID CancelInvoke knows this invocation by. The server assigns a fresh
+	// one when empty; an ID already in flight is ALREADY_EXISTS.
+	// @example "nightly-build"
+	InvokeId string `protobuf:"bytes,3,opt,name=invoke_id,json=invokeId,proto3" json:"invoke_id,omitempty"`
+	// Environment variables for the command, merged onto the server's own
+	// environment unless clear_env is set.
+	// @example {"LOG_LEVEL":"debug"}
+	Env map[string]string `protobuf:"bytes,4,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Directory the command runs in, relative to the server's sandbox root
+	// (op serve --sandbox-root, default: its working directory). A
+	// directory outside the sandbox is INVALID_ARGUMENT. Empty keeps the
+	// server's working directory.
+	// @example "holons/rob-go"
+	WorkingDir string `protobuf:"bytes,5,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
+	// Run the command with env alone instead of the server's environment
+	// plus env.
+	ClearEnv bool `protobuf:"varint,6,opt,name=clear_env,json=clearEnv,proto3" json:"clear_env,omitempty"`
+	// Seconds the command may run. When they pass, its process group gets
+	// SIGTERM, then SIGKILL, and the call fails with DEADLINE_EXCEEDED
+	// naming the timeout. Zero means no limit beyond the call's own.
+	// @example 300
+	TimeoutSeconds uint32 `protobuf:"varint,7,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *InvokeRequest) Reset() {
@@ -583,6 +687,41 @@ func (x *InvokeRequest) GetArgs() []string {
 	return nil
 }
 
+func (x *InvokeRequest) GetInvokeId() string {
+	if x != nil {
+		return x.InvokeId
+	}
+	return ""
+}
+
+func (x *InvokeRequest) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *InvokeRequest) GetWorkingDir() string {
+	if x != nil {
+		return x.WorkingDir
+	}
+	return ""
+}
+
+func (x *InvokeRequest) GetClearEnv() bool {
+	if x != nil {
+		return x.ClearEnv
+	}
+	return false
+}
+
+func (x *InvokeRequest) GetTimeoutSeconds() uint32 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
 type InvokeResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Process exit code returned by the holon command.
@@ -590,7 +729,9 @@ type InvokeResponse struct {
 	// Standard output captured from the command.
 	Stdout string `protobuf:"bytes,2,opt,name=stdout,proto3" json:"stdout,omitempty"`
 	// Standard error captured from the command.
-	Stderr        string `protobuf:"bytes,3,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	Stderr string `protobuf:"bytes,3,opt,name=stderr,proto3" json:"stderr,omitempty"`
+	// ID the invocation ran under.
+	InvokeId      string `protobuf:"bytes,4,opt,name=invoke_id,json=invokeId,proto3" json:"invoke_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -646,6 +787,220 @@ func (x *InvokeResponse) GetStderr() string {
 	return ""
 }
 
+func (x *InvokeResponse) GetInvokeId() string {
+	if x != nil {
+		return x.InvokeId
+	}
+	return ""
+}
+
+// InvokeChunk is one piece of an InvokeStream: output from the command,
+// or, last, its exit code.
+type InvokeChunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Which of the command's outputs data was written to.
+	Stream InvokeChunk_Stream `protobuf:"varint,1,opt,name=stream,proto3,enum=op.v1.InvokeChunk_Stream" json:"stream,omitempty"`
+	// Types that are valid to be assigned to Body:
+	//
+	//	*InvokeChunk_Data
+	//	*InvokeChunk_ExitCode
+	Body isInvokeChunk_Body `protobuf_oneof:"body"`
+	// ID of the invocation, for CancelInvoke. Set on every chunk; the first
+	// chunk, sent once the command has started, carries nothing else.
+	InvokeId      string `protobuf:"bytes,4,opt,name=invoke_id,json=invokeId,proto3" json:"invoke_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InvokeChunk) Reset() {
+	*x = InvokeChunk{}
+	mi := &file_op_v1_op_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InvokeChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvokeChunk) ProtoMessage() {}
+
+func (x *InvokeChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_op_v1_op_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvokeChunk.ProtoReflect.Descriptor instead.
+func (*InvokeChunk) Descriptor() ([]byte, []int) {
+	return file_op_v1_op_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *InvokeChunk) GetStream() InvokeChunk_Stream {
+	if x != nil {
+		return x.Stream
+	}
+	return InvokeChunk_STREAM_UNSPECIFIED
+}
+
+func (x *InvokeChunk) GetBody() isInvokeChunk_Body {
+	if x != nil {
+		return x.Body
+	}
+	return nil
+}
+
+func (x *InvokeChunk) GetData() []byte {
+	if x != nil {
+		if x, ok := x.Body.(*InvokeChunk_Data); ok {
+			return x.Data
+		}
+	}
+	return nil
+}
+
+func (x *InvokeChunk) GetExitCode() int32 {
+	if x != nil {
+		if x, ok := x.Body.(*InvokeChunk_ExitCode); ok {
+			return x.ExitCode
+		}
+	}
+	return 0
+}
+
+func (x *InvokeChunk) GetInvokeId() string {
+	if x != nil {
+		return x.InvokeId
+	}
+	return ""
+}
+
+type isInvokeChunk_Body interface {
+	isInvokeChunk_Body()
+}
+
+type InvokeChunk_Data struct {
+	// Output bytes, in the order the command wrote them.
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3,oneof"`
+}
+
+type InvokeChunk_ExitCode struct {
+	// Process exit code; set only on the final chunk.
+	ExitCode int32 `protobuf:"varint,3,opt,name=exit_code,json=exitCode,proto3,oneof"`
+}
+
+func (*InvokeChunk_Data) isInvokeChunk_Body() {}
+
+func (*InvokeChunk_ExitCode) isInvokeChunk_Body() {}
+
+type CancelInvokeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID of the invocation to cancel, from InvokeRequest, InvokeChunk or the
+	// server's log.
+	// @required
+	// @example "5b0c7f3e-6f1d-4c52-9d38-0f7a1c2e8b41"
+	InvokeId      string `protobuf:"bytes,1,opt,name=invoke_id,json=invokeId,proto3" json:"invoke_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelInvokeRequest) Reset() {
+	*x = CancelInvokeRequest{}
+	mi := &file_op_v1_op_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelInvokeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelInvokeRequest) ProtoMessage() {}
+
+func (x *CancelInvokeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_op_v1_op_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelInvokeRequest.ProtoReflect.Descriptor instead.
+func (*CancelInvokeRequest) Descriptor() ([]byte, []int) {
+	return file_op_v1_op_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CancelInvokeRequest) GetInvokeId() string {
+	if x != nil {
+		return x.InvokeId
+	}
+	return ""
+}
+
+type CancelInvokeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Holon the cancelled command was dispatched to.
+	Holon string `protobuf:"bytes,1,opt,name=holon,proto3" json:"holon,omitempty"`
+	// Arguments of the cancelled command.
+	Args          []string `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelInvokeResponse) Reset() {
+	*x = CancelInvokeResponse{}
+	mi := &file_op_v1_op_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelInvokeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelInvokeResponse) ProtoMessage() {}
+
+func (x *CancelInvokeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_op_v1_op_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelInvokeResponse.ProtoReflect.Descriptor instead.
+func (*CancelInvokeResponse) Descriptor() ([]byte, []int) {
+	return file_op_v1_op_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CancelInvokeResponse) GetHolon() string {
+	if x != nil {
+		return x.Holon
+	}
+	return ""
+}
+
+func (x *CancelInvokeResponse) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
 type CreateIdentityRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// @required
@@ -669,7 +1024,7 @@ type CreateIdentityRequest struct {
 
 func (x *CreateIdentityRequest) Reset() {
 	*x = CreateIdentityRequest{}
-	mi := &file_op_v1_op_proto_msgTypes[6]
+	mi := &file_op_v1_op_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -681,7 +1036,7 @@ func (x *CreateIdentityRequest) String() string {
 func (*CreateIdentityRequest) ProtoMessage() {}
 
 func (x *CreateIdentityRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_op_v1_op_proto_msgTypes[6]
+	mi := &file_op_v1_op_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -694,7 +1049,7 @@ func (x *CreateIdentityRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateIdentityRequest.ProtoReflect.Descriptor instead.
 func (*CreateIdentityRequest) Descriptor() ([]byte, []int) {
-	return file_op_v1_op_proto_rawDescGZIP(), []int{6}
+	return file_op_v1_op_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *CreateIdentityRequest) GetGivenName() string {
@@ -771,7 +1126,7 @@ type CreateIdentityResponse struct {
 
 func (x *CreateIdentityResponse) Reset() {
 	*x = CreateIdentityResponse{}
-	mi := &file_op_v1_op_proto_msgTypes[7]
+	mi := &file_op_v1_op_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -783,7 +1138,7 @@ func (x *CreateIdentityResponse) String() string {
 func (*CreateIdentityResponse) ProtoMessage() {}
 
 func (x *CreateIdentityResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_op_v1_op_proto_msgTypes[7]
+	mi := &file_op_v1_op_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -796,7 +1151,7 @@ func (x *CreateIdentityResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateIdentityResponse.ProtoReflect.Descriptor instead.
 func (*CreateIdentityResponse) Descriptor() ([]byte, []int) {
-	return file_op_v1_op_proto_rawDescGZIP(), []int{7}
+	return file_op_v1_op_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *CreateIdentityResponse) GetIdentity() *HolonIdentity {
@@ -817,14 +1172,17 @@ type ShowIdentityRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Full UUID or prefix.
 	// @required
-	Uuid          string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Uuid string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	// HolonIdentity fields to populate, e.g. ["uuid", "given_name"].
+	// Empty returns every field.
+	FieldMask     *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=field_mask,json=fieldMask,proto3" json:"field_mask,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ShowIdentityRequest) Reset() {
 	*x = ShowIdentityRequest{}
-	mi := &file_op_v1_op_proto_msgTypes[8]
+	mi := &file_op_v1_op_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -836,7 +1194,7 @@ func (x *ShowIdentityRequest) String() string {
 func (*ShowIdentityRequest) ProtoMessage() {}
 
 func (x *ShowIdentityRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_op_v1_op_proto_msgTypes[8]
+	mi := &file_op_v1_op_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -849,7 +1207,7 @@ func (x *ShowIdentityRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ShowIdentityRequest.ProtoReflect.Descriptor instead.
 func (*ShowIdentityRequest) Descriptor() ([]byte, []int) {
-	return file_op_v1_op_proto_rawDescGZIP(), []int{8}
+	return file_op_v1_op_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *ShowIdentityRequest) GetUuid() string {
@@ -859,6 +1217,13 @@ func (x *ShowIdentityRequest) GetUuid() string {
 	return ""
 }
 
+func (x *ShowIdentityRequest) GetFieldMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.FieldMask
+	}
+	return nil
+}
+
 type ShowIdentityResponse struct {
 	state    protoimpl.MessageState `protogen:"open.v1"`
 	Identity *HolonIdentity         `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
@@ -871,7 +1236,7 @@ type ShowIdentityResponse struct {
 
 func (x *ShowIdentityResponse) Reset() {
 	*x = ShowIdentityResponse{}
-	mi := &file_op_v1_op_proto_msgTypes[9]
+	mi := &file_op_v1_op_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -883,7 +1248,7 @@ func (x *ShowIdentityResponse) String() string {
 func (*ShowIdentityResponse) ProtoMessage() {}
 
 func (x *ShowIdentityResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_op_v1_op_proto_msgTypes[9]
+	mi := &file_op_v1_op_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -896,7 +1261,7 @@ func (x *ShowIdentityResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ShowIdentityResponse.ProtoReflect.Descriptor instead.
 func (*ShowIdentityResponse) Descriptor() ([]byte, []int) {
-	return file_op_v1_op_proto_rawDescGZIP(), []int{9}
+	return file_op_v1_op_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *ShowIdentityResponse) GetIdentity() *HolonIdentity {
@@ -920,17 +1285,138 @@ func (x *ShowIdentityResponse) GetRawContent() string {
 	return ""
 }
 
+type DeleteIdentityRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Full UUID; a prefix is refused so a deletion never picks a holon by
+	// chance.
+	// @required
+	Uuid string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	// Remove the holon's whole directory, not only its identity file.
+	Purge bool `protobuf:"varint,2,opt,name=purge,proto3" json:"purge,omitempty"`
+	// Delete even a stable or pinned holon.
+	Force         bool `protobuf:"varint,3,opt,name=force,proto3" json:"force,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteIdentityRequest) Reset() {
+	*x = DeleteIdentityRequest{}
+	mi := &file_op_v1_op_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteIdentityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteIdentityRequest) ProtoMessage() {}
+
+func (x *DeleteIdentityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_op_v1_op_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteIdentityRequest.ProtoReflect.Descriptor instead.
+func (*DeleteIdentityRequest) Descriptor() ([]byte, []int) {
+	return file_op_v1_op_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DeleteIdentityRequest) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *DeleteIdentityRequest) GetPurge() bool {
+	if x != nil {
+		return x.Purge
+	}
+	return false
+}
+
+func (x *DeleteIdentityRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type DeleteIdentityResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Identity *HolonIdentity         `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
+	// The identity file, or with purge the directory, that was removed.
+	RemovedPath   string `protobuf:"bytes,2,opt,name=removed_path,json=removedPath,proto3" json:"removed_path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteIdentityResponse) Reset() {
+	*x = DeleteIdentityResponse{}
+	mi := &file_op_v1_op_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteIdentityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteIdentityResponse) ProtoMessage() {}
+
+func (x *DeleteIdentityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_op_v1_op_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteIdentityResponse.ProtoReflect.Descriptor instead.
+func (*DeleteIdentityResponse) Descriptor() ([]byte, []int) {
+	return file_op_v1_op_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DeleteIdentityResponse) GetIdentity() *HolonIdentity {
+	if x != nil {
+		return x.Identity
+	}
+	return nil
+}
+
+func (x *DeleteIdentityResponse) GetRemovedPath() string {
+	if x != nil {
+		return x.RemovedPath
+	}
+	return ""
+}
+
 type ListIdentitiesRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Directory to scan. Default: current dir.
-	RootDir       string `protobuf:"bytes,1,opt,name=root_dir,json=rootDir,proto3" json:"root_dir,omitempty"`
+	RootDir string `protobuf:"bytes,1,opt,name=root_dir,json=rootDir,proto3" json:"root_dir,omitempty"`
+	// HolonIdentity fields to populate in each entry, e.g. ["uuid"].
+	// Empty returns every field.
+	FieldMask     *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=field_mask,json=fieldMask,proto3" json:"field_mask,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ListIdentitiesRequest) Reset() {
 	*x = ListIdentitiesRequest{}
-	mi := &file_op_v1_op_proto_msgTypes[10]
+	mi := &file_op_v1_op_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -942,7 +1428,7 @@ func (x *ListIdentitiesRequest) String() string {
 func (*ListIdentitiesRequest) ProtoMessage() {}
 
 func (x *ListIdentitiesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_op_v1_op_proto_msgTypes[10]
+	mi := &file_op_v1_op_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -955,7 +1441,7 @@ func (x *ListIdentitiesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListIdentitiesRequest.ProtoReflect.Descriptor instead.
 func (*ListIdentitiesRequest) Descriptor() ([]byte, []int) {
-	return file_op_v1_op_proto_rawDescGZIP(), []int{10}
+	return file_op_v1_op_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *ListIdentitiesRequest) GetRootDir() string {
@@ -965,6 +1451,13 @@ func (x *ListIdentitiesRequest) GetRootDir() string {
 	return ""
 }
 
+func (x *ListIdentitiesRequest) GetFieldMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.FieldMask
+	}
+	return nil
+}
+
 type ListIdentitiesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Entries       []*HolonEntry          `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
@@ -974,7 +1467,7 @@ type ListIdentitiesResponse struct {
 
 func (x *ListIdentitiesResponse) Reset() {
 	*x = ListIdentitiesResponse{}
-	mi := &file_op_v1_op_proto_msgTypes[11]
+	mi := &file_op_v1_op_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -986,7 +1479,7 @@ func (x *ListIdentitiesResponse) String() string {
 func (*ListIdentitiesResponse) ProtoMessage() {}
 
 func (x *ListIdentitiesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_op_v1_op_proto_msgTypes[11]
+	mi := &file_op_v1_op_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -999,7 +1492,7 @@ func (x *ListIdentitiesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListIdentitiesResponse.ProtoReflect.Descriptor instead.
 func (*ListIdentitiesResponse) Descriptor() ([]byte, []int) {
-	return file_op_v1_op_proto_rawDescGZIP(), []int{11}
+	return file_op_v1_op_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *ListIdentitiesResponse) GetEntries() []*HolonEntry {
@@ -1013,7 +1506,7 @@ var File_op_v1_op_proto protoreflect.FileDescriptor
 
 const file_op_v1_op_proto_rawDesc = "" +
 	"\n" +
-	"\x0eop/v1/op.proto\x12\x05op.v1\"\xce\x03\n" +
+	"\x0eop/v1/op.proto\x12\x05op.v1\x1a google/protobuf/field_mask.proto\"\xce\x03\n" +
 	"\rHolonIdentity\x12\x12\n" +
 	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x1d\n" +
 	"\n" +
@@ -1031,9 +1524,12 @@ const file_op_v1_op_proto_rawDesc = "" +
 	"\aaliases\x18\x12 \x03(\tR\aaliases\x12!\n" +
 	"\fgenerated_by\x18\x14 \x01(\tR\vgeneratedBy\x12\x12\n" +
 	"\x04lang\x18\x15 \x01(\tR\x04lang\x120\n" +
-	"\fproto_status\x18\x16 \x01(\x0e2\r.op.v1.StatusR\vprotoStatus\",\n" +
+	"\fproto_status\x18\x16 \x01(\x0e2\r.op.v1.StatusR\vprotoStatus\"v\n" +
 	"\x0fDiscoverRequest\x12\x19\n" +
-	"\broot_dir\x18\x01 \x01(\tR\arootDir\"d\n" +
+	"\broot_dir\x18\x01 \x01(\tR\arootDir\x12\x14\n" +
+	"\x05langs\x18\x02 \x03(\tR\x05langs\x12\x16\n" +
+	"\x06clades\x18\x03 \x03(\tR\x06clades\x12\x1a\n" +
+	"\bstatuses\x18\x04 \x03(\tR\bstatuses\"d\n" +
 	"\x10DiscoverResponse\x12+\n" +
 	"\aentries\x18\x01 \x03(\v2\x11.op.v1.HolonEntryR\aentries\x12#\n" +
 	"\rpath_binaries\x18\x02 \x03(\tR\fpathBinaries\"{\n" +
@@ -1041,14 +1537,41 @@ const file_op_v1_op_proto_rawDesc = "" +
 	"HolonEntry\x120\n" +
 	"\bidentity\x18\x01 \x01(\v2\x14.op.v1.HolonIdentityR\bidentity\x12\x16\n" +
 	"\x06origin\x18\x02 \x01(\tR\x06origin\x12#\n" +
-	"\rrelative_path\x18\x03 \x01(\tR\frelativePath\"9\n" +
+	"\rrelative_path\x18\x03 \x01(\tR\frelativePath\"\xa6\x02\n" +
 	"\rInvokeRequest\x12\x14\n" +
 	"\x05holon\x18\x01 \x01(\tR\x05holon\x12\x12\n" +
-	"\x04args\x18\x02 \x03(\tR\x04args\"]\n" +
+	"\x04args\x18\x02 \x03(\tR\x04args\x12\x1b\n" +
+	"\tinvoke_id\x18\x03 \x01(\tR\binvokeId\x12/\n" +
+	"\x03env\x18\x04 \x03(\v2\x1d.op.v1.InvokeRequest.EnvEntryR\x03env\x12\x1f\n" +
+	"\vworking_dir\x18\x05 \x01(\tR\n" +
+	"workingDir\x12\x1b\n" +
+	"\tclear_env\x18\x06 \x01(\bR\bclearEnv\x12'\n" +
+	"\x0ftimeout_seconds\x18\a \x01(\rR\x0etimeoutSeconds\x1a6\n" +
+	"\bEnvEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"z\n" +
 	"\x0eInvokeResponse\x12\x1b\n" +
 	"\texit_code\x18\x01 \x01(\x05R\bexitCode\x12\x16\n" +
 	"\x06stdout\x18\x02 \x01(\tR\x06stdout\x12\x16\n" +
-	"\x06stderr\x18\x03 \x01(\tR\x06stderr\"\xb7\x02\n" +
+	"\x06stderr\x18\x03 \x01(\tR\x06stderr\x12\x1b\n" +
+	"\tinvoke_id\x18\x04 \x01(\tR\binvokeId\"\xd4\x01\n" +
+	"\vInvokeChunk\x121\n" +
+	"\x06stream\x18\x01 \x01(\x0e2\x19.op.v1.InvokeChunk.StreamR\x06stream\x12\x14\n" +
+	"\x04data\x18\x02 \x01(\fH\x00R\x04data\x12\x1d\n" +
+	"\texit_code\x18\x03 \x01(\x05H\x00R\bexitCode\x12\x1b\n" +
+	"\tinvoke_id\x18\x04 \x01(\tR\binvokeId\"8\n" +
+	"\x06Stream\x12\x16\n" +
+	"\x12STREAM_UNSPECIFIED\x10\x00\x12\n" +
+	"\n" +
+	"\x06STDOUT\x10\x01\x12\n" +
+	"\n" +
+	"\x06STDERR\x10\x02B\x06\n" +
+	"\x04body\"2\n" +
+	"\x13CancelInvokeRequest\x12\x1b\n" +
+	"\tinvoke_id\x18\x01 \x01(\tR\binvokeId\"@\n" +
+	"\x14CancelInvokeResponse\x12\x14\n" +
+	"\x05holon\x18\x01 \x01(\tR\x05holon\x12\x12\n" +
+	"\x04args\x18\x02 \x03(\tR\x04args\"\xb7\x02\n" +
 	"\x15CreateIdentityRequest\x12\x1d\n" +
 	"\n" +
 	"given_name\x18\x01 \x01(\tR\tgivenName\x12\x1f\n" +
@@ -1065,16 +1588,27 @@ const file_op_v1_op_proto_rawDesc = "" +
 	" \x01(\tR\toutputDir\"g\n" +
 	"\x16CreateIdentityResponse\x120\n" +
 	"\bidentity\x18\x01 \x01(\v2\x14.op.v1.HolonIdentityR\bidentity\x12\x1b\n" +
-	"\tfile_path\x18\x02 \x01(\tR\bfilePath\")\n" +
+	"\tfile_path\x18\x02 \x01(\tR\bfilePath\"d\n" +
 	"\x13ShowIdentityRequest\x12\x12\n" +
-	"\x04uuid\x18\x01 \x01(\tR\x04uuid\"\x86\x01\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x129\n" +
+	"\n" +
+	"field_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\tfieldMask\"\x86\x01\n" +
 	"\x14ShowIdentityResponse\x120\n" +
 	"\bidentity\x18\x01 \x01(\v2\x14.op.v1.HolonIdentityR\bidentity\x12\x1b\n" +
 	"\tfile_path\x18\x02 \x01(\tR\bfilePath\x12\x1f\n" +
 	"\vraw_content\x18\x03 \x01(\tR\n" +
-	"rawContent\"2\n" +
+	"rawContent\"W\n" +
+	"\x15DeleteIdentityRequest\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x14\n" +
+	"\x05purge\x18\x02 \x01(\bR\x05purge\x12\x14\n" +
+	"\x05force\x18\x03 \x01(\bR\x05force\"m\n" +
+	"\x16DeleteIdentityResponse\x120\n" +
+	"\bidentity\x18\x01 \x01(\v2\x14.op.v1.HolonIdentityR\bidentity\x12!\n" +
+	"\fremoved_path\x18\x02 \x01(\tR\vremovedPath\"m\n" +
 	"\x15ListIdentitiesRequest\x12\x19\n" +
-	"\broot_dir\x18\x01 \x01(\tR\arootDir\"E\n" +
+	"\broot_dir\x18\x01 \x01(\tR\arootDir\x129\n" +
+	"\n" +
+	"field_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\tfieldMask\"E\n" +
 	"\x16ListIdentitiesResponse\x12+\n" +
 	"\aentries\x18\x01 \x03(\v2\x11.op.v1.HolonEntryR\aentries*\xc6\x01\n" +
 	"\x05Clade\x12\x15\n" +
@@ -1100,13 +1634,16 @@ const file_op_v1_op_proto_rawDesc = "" +
 	"\x06STABLE\x10\x02\x12\x0e\n" +
 	"\n" +
 	"DEPRECATED\x10\x03\x12\b\n" +
-	"\x04DEAD\x10\x042\xe6\x02\n" +
+	"\x04DEAD\x10\x042\xba\x04\n" +
 	"\tOPService\x12;\n" +
 	"\bDiscover\x12\x16.op.v1.DiscoverRequest\x1a\x17.op.v1.DiscoverResponse\x125\n" +
-	"\x06Invoke\x12\x14.op.v1.InvokeRequest\x1a\x15.op.v1.InvokeResponse\x12M\n" +
+	"\x06Invoke\x12\x14.op.v1.InvokeRequest\x1a\x15.op.v1.InvokeResponse\x12:\n" +
+	"\fInvokeStream\x12\x14.op.v1.InvokeRequest\x1a\x12.op.v1.InvokeChunk0\x01\x12G\n" +
+	"\fCancelInvoke\x12\x1a.op.v1.CancelInvokeRequest\x1a\x1b.op.v1.CancelInvokeResponse\x12M\n" +
 	"\x0eCreateIdentity\x12\x1c.op.v1.CreateIdentityRequest\x1a\x1d.op.v1.CreateIdentityResponse\x12M\n" +
 	"\x0eListIdentities\x12\x1c.op.v1.ListIdentitiesRequest\x1a\x1d.op.v1.ListIdentitiesResponse\x12G\n" +
-	"\fShowIdentity\x12\x1a.op.v1.ShowIdentityRequest\x1a\x1b.op.v1.ShowIdentityResponseB;Z9github.com/organic-programming/grace-op/gen/go/op/v1;opv1b\x06proto3"
+	"\fShowIdentity\x12\x1a.op.v1.ShowIdentityRequest\x1a\x1b.op.v1.ShowIdentityResponse\x12M\n" +
+	"\x0eDeleteIdentity\x12\x1c.op.v1.DeleteIdentityRequest\x1a\x1d.op.v1.DeleteIdentityResponseB;Z9github.com/organic-programming/grace-op/gen/go/op/v1;opv1b\x06proto3"
 
 var (
 	file_op_v1_op_proto_rawDescOnce sync.Once
@@ -1120,52 +1657,71 @@ func file_op_v1_op_proto_rawDescGZIP() []byte {
 	return file_op_v1_op_proto_rawDescData
 }
 
-var file_op_v1_op_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_op_v1_op_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_op_v1_op_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_op_v1_op_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
 var file_op_v1_op_proto_goTypes = []any{
 	(Clade)(0),                     // 0: op.v1.Clade
 	(ReproductionMode)(0),          // 1: op.v1.ReproductionMode
 	(Status)(0),                    // 2: op.v1.Status
-	(*HolonIdentity)(nil),          // 3: op.v1.HolonIdentity
-	(*DiscoverRequest)(nil),        // 4: op.v1.DiscoverRequest
-	(*DiscoverResponse)(nil),       // 5: op.v1.DiscoverResponse
-	(*HolonEntry)(nil),             // 6: op.v1.HolonEntry
-	(*InvokeRequest)(nil),          // 7: op.v1.InvokeRequest
-	(*InvokeResponse)(nil),         // 8: op.v1.InvokeResponse
-	(*CreateIdentityRequest)(nil),  // 9: op.v1.CreateIdentityRequest
-	(*CreateIdentityResponse)(nil), // 10: op.v1.CreateIdentityResponse
-	(*ShowIdentityRequest)(nil),    // 11: op.v1.ShowIdentityRequest
-	(*ShowIdentityResponse)(nil),   // 12: op.v1.ShowIdentityResponse
-	(*ListIdentitiesRequest)(nil),  // 13: op.v1.ListIdentitiesRequest
-	(*ListIdentitiesResponse)(nil), // 14: op.v1.ListIdentitiesResponse
+	(InvokeChunk_Stream)(0),        // 3: op.v1.InvokeChunk.Stream
+	(*HolonIdentity)(nil),          // 4: op.v1.HolonIdentity
+	(*DiscoverRequest)(nil),        // 5: op.v1.DiscoverRequest
+	(*DiscoverResponse)(nil),       // 6: op.v1.DiscoverResponse
+	(*HolonEntry)(nil),             // 7: op.v1.HolonEntry
+	(*InvokeRequest)(nil),          // 8: op.v1.InvokeRequest
+	(*InvokeResponse)(nil),         // 9: op.v1.InvokeResponse
+	(*InvokeChunk)(nil),            // 10: op.v1.InvokeChunk
+	(*CancelInvokeRequest)(nil),    // 11: op.v1.CancelInvokeRequest
+	(*CancelInvokeResponse)(nil),   // 12: op.v1.CancelInvokeResponse
+	(*CreateIdentityRequest)(nil),  // 13: op.v1.CreateIdentityRequest
+	(*CreateIdentityResponse)(nil), // 14: op.v1.CreateIdentityResponse
+	(*ShowIdentityRequest)(nil),    // 15: op.v1.ShowIdentityRequest
+	(*ShowIdentityResponse)(nil),   // 16: op.v1.ShowIdentityResponse
+	(*DeleteIdentityRequest)(nil),  // 17: op.v1.DeleteIdentityRequest
+	(*DeleteIdentityResponse)(nil), // 18: op.v1.DeleteIdentityResponse
+	(*ListIdentitiesRequest)(nil),  // 19: op.v1.ListIdentitiesRequest
+	(*ListIdentitiesResponse)(nil), // 20: op.v1.ListIdentitiesResponse
+	nil,                            // 21: op.v1.InvokeRequest.EnvEntry
+	(*fieldmaskpb.FieldMask)(nil),  // 22: google.protobuf.FieldMask
 }
 var file_op_v1_op_proto_depIdxs = []int32{
 	0,  // 0: op.v1.HolonIdentity.clade:type_name -> op.v1.Clade
 	2,  // 1: op.v1.HolonIdentity.status:type_name -> op.v1.Status
 	1,  // 2: op.v1.HolonIdentity.reproduction:type_name -> op.v1.ReproductionMode
 	2,  // 3: op.v1.HolonIdentity.proto_status:type_name -> op.v1.Status
-	6,  // 4: op.v1.DiscoverResponse.entries:type_name -> op.v1.HolonEntry
-	3,  // 5: op.v1.HolonEntry.identity:type_name -> op.v1.HolonIdentity
-	0,  // 6: op.v1.CreateIdentityRequest.clade:type_name -> op.v1.Clade
-	1,  // 7: op.v1.CreateIdentityRequest.reproduction:type_name -> op.v1.ReproductionMode
-	3,  // 8: op.v1.CreateIdentityResponse.identity:type_name -> op.v1.HolonIdentity
-	3,  // 9: op.v1.ShowIdentityResponse.identity:type_name -> op.v1.HolonIdentity
-	6,  // 10: op.v1.ListIdentitiesResponse.entries:type_name -> op.v1.HolonEntry
-	4,  // 11: op.v1.OPService.Discover:input_type -> op.v1.DiscoverRequest
-	7,  // 12: op.v1.OPService.Invoke:input_type -> op.v1.InvokeRequest
-	9,  // 13: op.v1.OPService.CreateIdentity:input_type -> op.v1.CreateIdentityRequest
-	13, // 14: op.v1.OPService.ListIdentities:input_type -> op.v1.ListIdentitiesRequest
-	11, // 15: op.v1.OPService.ShowIdentity:input_type -> op.v1.ShowIdentityRequest
-	5,  // 16: op.v1.OPService.Discover:output_type -> op.v1.DiscoverResponse
-	8,  // 17: op.v1.OPService.Invoke:output_type -> op.v1.InvokeResponse
-	10, // 18: op.v1.OPService.CreateIdentity:output_type -> op.v1.CreateIdentityResponse
-	14, // 19: op.v1.OPService.ListIdentities:output_type -> op.v1.ListIdentitiesResponse
-	12, // 20: op.v1.OPService.ShowIdentity:output_type -> op.v1.ShowIdentityResponse
-	16, // [16:21] is the sub-list for method output_type
-	11, // [11:16] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	7,  // 4: op.v1.DiscoverResponse.entries:type_name -> op.v1.HolonEntry
+	4,  // 5: op.v1.HolonEntry.identity:type_name -> op.v1.HolonIdentity
+	21, // 6: op.v1.InvokeRequest.env:type_name -> op.v1.InvokeRequest.EnvEntry
+	3,  // 7: op.v1.InvokeChunk.stream:type_name -> op.v1.InvokeChunk.Stream
+	0,  // 8: op.v1.CreateIdentityRequest.clade:type_name -> op.v1.Clade
+	1,  // 9: op.v1.CreateIdentityRequest.reproduction:type_name -> op.v1.ReproductionMode
+	4,  // 10: op.v1.CreateIdentityResponse.identity:type_name -> op.v1.HolonIdentity
+	22, // 11: op.v1.ShowIdentityRequest.field_mask:type_name -> google.protobuf.FieldMask
+	4,  // 12: op.v1.ShowIdentityResponse.identity:type_name -> op.v1.HolonIdentity
+	4,  // 13: op.v1.DeleteIdentityResponse.identity:type_name -> op.v1.HolonIdentity
+	22, // 14: op.v1.ListIdentitiesRequest.field_mask:type_name -> google.protobuf.FieldMask
+	7,  // 15: op.v1.ListIdentitiesResponse.entries:type_name -> op.v1.HolonEntry
+	5,  // 16: op.v1.OPService.Discover:input_type -> op.v1.DiscoverRequest
+	8,  // 17: op.v1.OPService.Invoke:input_type -> op.v1.InvokeRequest
+	8,  // 18: op.v1.OPService.InvokeStream:input_type -> op.v1.InvokeRequest
+	11, // 19: op.v1.OPService.CancelInvoke:input_type -> op.v1.CancelInvokeRequest
+	13, // 20: op.v1.OPService.CreateIdentity:input_type -> op.v1.CreateIdentityRequest
+	19, // 21: op.v1.OPService.ListIdentities:input_type -> op.v1.ListIdentitiesRequest
+	15, // 22: op.v1.OPService.ShowIdentity:input_type -> op.v1.ShowIdentityRequest
+	17, // 23: op.v1.OPService.DeleteIdentity:input_type -> op.v1.DeleteIdentityRequest
+	6,  // 24: op.v1.OPService.Discover:output_type -> op.v1.DiscoverResponse
+	9,  // 25: op.v1.OPService.Invoke:output_type -> op.v1.InvokeResponse
+	10, // 26: op.v1.OPService.InvokeStream:output_type -> op.v1.InvokeChunk
+	12, // 27: op.v1.OPService.CancelInvoke:output_type -> op.v1.CancelInvokeResponse
+	14, // 28: op.v1.OPService.CreateIdentity:output_type -> op.v1.CreateIdentityResponse
+	20, // 29: op.v1.OPService.ListIdentities:output_type -> op.v1.ListIdentitiesResponse
+	16, // 30: op.v1.OPService.ShowIdentity:output_type -> op.v1.ShowIdentityResponse
+	18, // 31: op.v1.OPService.DeleteIdentity:output_type -> op.v1.DeleteIdentityResponse
+	24, // [24:32] is the sub-list for method output_type
+	16, // [16:24] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_op_v1_op_proto_init() }
@@ -1173,13 +1729,17 @@ func file_op_v1_op_proto_init() {
 	if File_op_v1_op_proto != nil {
 		return
 	}
+	file_op_v1_op_proto_msgTypes[6].OneofWrappers = []any{
+		(*InvokeChunk_Data)(nil),
+		(*InvokeChunk_ExitCode)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_op_v1_op_proto_rawDesc), len(file_op_v1_op_proto_rawDesc)),
-			NumEnums:      3,
-			NumMessages:   12,
+			NumEnums:      4,
+			NumMessages:   18,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -27,9 +27,12 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	OPService_Discover_FullMethodName       = "/op.v1.OPService/Discover"
 	OPService_Invoke_FullMethodName         = "/op.v1.OPService/Invoke"
+	OPService_InvokeStream_FullMethodName   = "/op.v1.OPService/InvokeStream"
+	OPService_CancelInvoke_FullMethodName   = "/op.v1.OPService/CancelInvoke"
 	OPService_CreateIdentity_FullMethodName = "/op.v1.OPService/CreateIdentity"
 	OPService_ListIdentities_FullMethodName = "/op.v1.OPService/ListIdentities"
 	OPService_ShowIdentity_FullMethodName   = "/op.v1.OPService/ShowIdentity"
+	OPService_DeleteIdentity_FullMethodName = "/op.v1.OPService/DeleteIdentity"
 )
 
 // OPServiceClient is the client API for OPService service.
@@ -44,6 +47,16 @@ type OPServiceClient interface {
 	// Invoke dispatches a command to a holon by name.
 	// @example {"holon":"rob-go","args":["build","./cmd/rob-go"]}
 	Invoke(ctx context.Context, in *InvokeRequest, opts ...grpc.CallOption) (*InvokeResponse, error)
+	// InvokeStream is Invoke with the command's output sent as it is
+	// produced, ending with a chunk that carries the exit code. Cancelling
+	// the call kills the command.
+	// @example {"holon":"rob-go","args":["test","./..."]}
+	InvokeStream(ctx context.Context, in *InvokeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[InvokeChunk], error)
+	// CancelInvoke kills the command of an Invoke or InvokeStream still in
+	// flight, identified by its invoke_id. The cancelled call fails with
+	// CANCELLED; an unknown or finished ID is NOT_FOUND.
+	// @example {"invoke_id":"5b0c7f3e-6f1d-4c52-9d38-0f7a1c2e8b41"}
+	CancelInvoke(ctx context.Context, in *CancelInvokeRequest, opts ...grpc.CallOption) (*CancelInvokeResponse, error)
 	// CreateIdentity creates a new holon identity.
 	// @example {"given_name":"Megg","family_name":"FFmpeg","motto":"Cut precisely.","composer":"B. ALTER","clade":"DETERMINISTIC_IO_BOUND","output_dir":"holons/megg-ffmpeg"}
 	CreateIdentity(ctx context.Context, in *CreateIdentityRequest, opts ...grpc.CallOption) (*CreateIdentityResponse, error)
@@ -53,6 +66,10 @@ type OPServiceClient interface {
 	// ShowIdentity retrieves a holon's identity by UUID.
 	// @example {"uuid":"31d98dfa-c08e-4db5-ab4f-180cecad50ef"}
 	ShowIdentity(ctx context.Context, in *ShowIdentityRequest, opts ...grpc.CallOption) (*ShowIdentityResponse, error)
+	// DeleteIdentity removes a local holon's identity file, or with purge
+	// its whole directory.
+	// @example {"uuid":"31d98dfa-c08e-4db5-ab4f-180cecad50ef","purge":true}
+	DeleteIdentity(ctx context.Context, in *DeleteIdentityRequest, opts ...grpc.CallOption) (*DeleteIdentityResponse, error)
 }
 
 type oPServiceClient struct {
@@ -83,6 +100,35 @@ func (c *oPServiceClient) Invoke(ctx context.Context, in *InvokeRequest, opts ..
 	return out, nil
 }
 
+func (c *oPServiceClient) InvokeStream(ctx context.Context, in *InvokeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[InvokeChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OPService_ServiceDesc.Streams[0], OPService_InvokeStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[InvokeRequest, InvokeChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OPService_InvokeStreamClient = grpc.ServerStreamingClient[InvokeChunk]
+
+func (c *oPServiceClient) CancelInvoke(ctx context.Context, in *CancelInvokeRequest, opts ...grpc.CallOption) (*CancelInvokeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelInvokeResponse)
+	err := c.cc.Invoke(ctx, OPService_CancelInvoke_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *oPServiceClient) CreateIdentity(ctx context.Context, in *CreateIdentityRequest, opts ...grpc.CallOption) (*CreateIdentityResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CreateIdentityResponse)
@@ -113,6 +159,16 @@ func (c *oPServiceClient) ShowIdentity(ctx context.Context, in *ShowIdentityRequ
 	return out, nil
 }
 
+func (c *oPServiceClient) DeleteIdentity(ctx context.Context, in *DeleteIdentityRequest, opts ...grpc.CallOption) (*DeleteIdentityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteIdentityResponse)
+	err := c.cc.Invoke(ctx, OPService_DeleteIdentity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // OPServiceServer is the server API for OPService service.
 // All implementations must embed UnimplementedOPServiceServer
 // for forward compatibility.
@@ -125,6 +181,16 @@ type OPServiceServer interface {
 	// Invoke dispatches a command to a holon by name.
 	// @example {"holon":"rob-go","args":["build","./cmd/rob-go"]}
 	Invoke(context.Context, *InvokeRequest) (*InvokeResponse, error)
+	// InvokeStream is Invoke with the command's output sent as it is
+	// produced, ending with a chunk that carries the exit code. Cancelling
+	// the call kills the command.
+	// @example {"holon":"rob-go","args":["test","./..."]}
+	InvokeStream(*InvokeRequest, grpc.ServerStreamingServer[InvokeChunk]) error
+	// CancelInvoke kills the command of an Invoke or InvokeStream still in
+	// flight, identified by its invoke_id. The cancelled call fails with
+	// CANCELLED; an unknown or finished ID is NOT_FOUND.
+	// @example {"invoke_id":"5b0c7f3e-6f1d-4c52-9d38-0f7a1c2e8b41"}
+	CancelInvoke(context.Context, *CancelInvokeRequest) (*CancelInvokeResponse, error)
 	// CreateIdentity creates a new holon identity.
 	// @example {"given_name":"Megg","family_name":"FFmpeg","motto":"Cut precisely.","composer":"B. ALTER","clade":"DETERMINISTIC_IO_BOUND","output_dir":"holons/megg-ffmpeg"}
 	CreateIdentity(context.Context, *CreateIdentityRequest) (*CreateIdentityResponse, error)
@@ -134,6 +200,10 @@ type OPServiceServer interface {
 	// ShowIdentity retrieves a holon's identity by UUID.
 	// @example {"uuid":"31d98dfa-c08e-4db5-ab4f-180cecad50ef"}
 	ShowIdentity(context.Context, *ShowIdentityRequest) (*ShowIdentityResponse, error)
+	// DeleteIdentity removes a local holon's identity file, or with purge
+	// its whole directory.
+	// @example {"uuid":"31d98dfa-c08e-4db5-ab4f-180cecad50ef","purge":true}
+	DeleteIdentity(context.Context, *DeleteIdentityRequest) (*DeleteIdentityResponse, error)
 	mustEmbedUnimplementedOPServiceServer()
 }
 
@@ -150,6 +220,12 @@ func (UnimplementedOPServiceServer) Discover(context.Context, *DiscoverRequest)
 func (UnimplementedOPServiceServer) Invoke(context.Context, *InvokeRequest) (*InvokeResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Invoke not implemented")
 }
+func (UnimplementedOPServiceServer) InvokeStream(*InvokeRequest, grpc.ServerStreamingServer[InvokeChunk]) error {
+	return status.Error(codes.Unimplemented, "method InvokeStream not implemented")
+}
+func (UnimplementedOPServiceServer) CancelInvoke(context.Context, *CancelInvokeRequest) (*CancelInvokeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelInvoke not implemented")
+}
 func (UnimplementedOPServiceServer) CreateIdentity(context.Context, *CreateIdentityRequest) (*CreateIdentityResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method CreateIdentity not implemented")
 }
@@ -159,6 +235,9 @@ func (UnimplementedOPServiceServer) ListIdentities(context.Context, *ListIdentit
 func (UnimplementedOPServiceServer) ShowIdentity(context.Context, *ShowIdentityRequest) (*ShowIdentityResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ShowIdentity not implemented")
 }
+func (UnimplementedOPServiceServer) DeleteIdentity(context.Context, *DeleteIdentityRequest) (*DeleteIdentityResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteIdentity not implemented")
+}
 func (UnimplementedOPServiceServer) mustEmbedUnimplementedOPServiceServer() {}
 func (UnimplementedOPServiceServer) testEmbeddedByValue()                   {}
 
@@ -216,6 +295,35 @@ func _OPService_Invoke_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OPService_InvokeStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(InvokeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OPServiceServer).InvokeStream(m, &grpc.GenericServerStream[InvokeRequest, InvokeChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OPService_InvokeStreamServer = grpc.ServerStreamingServer[InvokeChunk]
+
+func _OPService_CancelInvoke_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelInvokeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OPServiceServer).CancelInvoke(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OPService_CancelInvoke_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OPServiceServer).CancelInvoke(ctx, req.(*CancelInvokeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _OPService_CreateIdentity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateIdentityRequest)
 	if err := dec(in); err != nil {
@@ -270,6 +378,24 @@ func _OPService_ShowIdentity_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OPService_DeleteIdentity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteIdentityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OPServiceServer).DeleteIdentity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OPService_DeleteIdentity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OPServiceServer).DeleteIdentity(ctx, req.(*DeleteIdentityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // OPService_ServiceDesc is the grpc.ServiceDesc for OPService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -285,6 +411,10 @@ var OPService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Invoke",
 			Handler:    _OPService_Invoke_Handler,
 		},
+		{
+			MethodName: "CancelInvoke",
+			Handler:    _OPService_CancelInvoke_Handler,
+		},
 		{
 			MethodName: "CreateIdentity",
 			Handler:    _OPService_CreateIdentity_Handler,
@@ -297,7 +427,17 @@ var OPService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ShowIdentity",
 			Handler:    _OPService_ShowIdentity_Handler,
 		},
+		{
+			MethodName: "DeleteIdentity",
+			Handler:    _OPService_DeleteIdentity_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "InvokeStream",
+			Handler:       _OPService_InvokeStream_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "op/v1/op.proto",
 }
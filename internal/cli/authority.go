@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+)
+
+// extractAuthorityFlag removes --authority from args and returns its value.
+func extractAuthorityFlag(args []string) (string, []string, error) {
+	host := ""
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--authority":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return "", nil, fmt.Errorf("--authority requires a host")
+			}
+			host = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--authority="):
+			host = strings.TrimPrefix(args[i], "--authority=")
+			if strings.TrimSpace(host) == "" {
+				return "", nil, fmt.Errorf("--authority requires a host")
+			}
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+	return host, remaining, nil
+}
+
+// pinAuthority sets host as the :authority of every connection this
+// invocation opens. release restores the authority set before.
+func pinAuthority(host string) func() {
+	if host == "" {
+		return func() {}
+	}
+	return grpcclient.SetAuthority(host)
+}
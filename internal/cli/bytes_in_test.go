@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// startBlobServer serves blob.v1.Store/Put, which echoes a
+// `message Blob { bytes data = 1; }`, and writes the descriptor set op
+// grpc needs to call it without reflection.
+func startBlobServer(t *testing.T) (address, descriptorSet string) {
+	t.Helper()
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("blob.proto"),
+		Package: proto.String("blob.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Blob"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name: proto.String("data"), JsonName: proto.String("data"), Number: proto.Int32(1),
+				Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:  descriptorpb.FieldDescriptorProto_TYPE_BYTES.Enum(),
+			}},
+		}},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name:   proto.String("Store"),
+			Method: []*descriptorpb.MethodDescriptorProto{{Name: proto.String("Put"), InputType: proto.String(".blob.v1.Blob"), OutputType: proto.String(".blob.v1.Blob")}},
+		}},
+	}
+	fd, err := protodesc.NewFile(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := fd.Messages().Get(0)
+
+	raw, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	descriptorSet = filepath.Join(t.TempDir(), "blob.pb")
+	if err := os.WriteFile(descriptorSet, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	s.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "blob.v1.Store",
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Put",
+			Handler: func(_ any, _ context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+				msg := dynamicpb.NewMessage(blob)
+				if err := dec(msg); err != nil {
+					return nil, err
+				}
+				return msg, nil
+			},
+		}},
+	}, struct{}{})
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String(), descriptorSet
+}
+
+func TestGRPCReadsBytesFileReferencesOnlyWithBytesIn(t *testing.T) {
+	address, descriptorSet := startBlobServer(t)
+	payload := filepath.Join(t.TempDir(), "payload.bin")
+	if err := os.WriteFile(payload, []byte("payload"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	input := `{"data":"@` + payload + `"}`
+
+	want := base64.StdEncoding.EncodeToString([]byte("payload"))
+	var stdout string
+	_ = captureStderr(t, func() {
+		stdout = captureStdout(t, func() {
+			if code := Run([]string{"--format", "json", "grpc://" + address, "Put", input, "--descriptor-set", descriptorSet}, "0.1.0-test"); code != 1 {
+				t.Fatalf("code = %d, want 1 without --bytes-in", code)
+			}
+		})
+	})
+	if strings.Contains(stdout, want) {
+		t.Fatalf("stdout = %q; the file was read without --bytes-in", stdout)
+	}
+
+	stdout = captureStdout(t, func() {
+		if code := Run([]string{"--format", "json", "grpc://" + address, "Put", input, "--descriptor-set", descriptorSet, "--bytes-in"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("code = %d, want 0 with --bytes-in", code)
+		}
+	})
+	if !strings.Contains(stdout, want) {
+		t.Fatalf("stdout = %q, want the file's content echoed as %s", stdout, want)
+	}
+}
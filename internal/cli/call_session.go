@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+)
+
+// sessionCall is one --call method:input pair.
+type sessionCall struct {
+	Method    string
+	InputJSON string
+}
+
+// holonCaller calls methods of one holon until its release func is called.
+type holonCaller func(ctx context.Context, method, inputJSON string) (*grpcclient.CallResult, error)
+
+// extractCallFlags removes every --call method[:json] from args. The method
+// is mapped as a holon command is (list becomes ListIdentities); the input
+// defaults to {}.
+func extractCallFlags(args []string) ([]sessionCall, []string, error) {
+	var calls []sessionCall
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if !isCallFlag(args[i]) {
+			remaining = append(remaining, args[i])
+			continue
+		}
+		value, hasValue := strings.CutPrefix(args[i], "--call=")
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("--call requires method:json")
+			}
+			value = args[i+1]
+			i++
+		}
+		call, err := parseSessionCall(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		calls = append(calls, call)
+	}
+	return calls, remaining, nil
+}
+
+func isCallFlag(arg string) bool {
+	return arg == "--call" || strings.HasPrefix(arg, "--call=")
+}
+
+func parseSessionCall(value string) (sessionCall, error) {
+	method, input, _ := strings.Cut(value, ":")
+	method = strings.TrimSpace(method)
+	if method == "" {
+		return sessionCall{}, fmt.Errorf("--call %q: missing method (use method:json)", value)
+	}
+	input = strings.TrimSpace(input)
+	if input == "" {
+		input = "{}"
+	}
+	return sessionCall{Method: mapCommandNameToMethod(method), InputJSON: input}, nil
+}
+
+// runCallSession makes calls in order on one connection to holon and prints
+// each result. It stops at the first failure; the holon is stopped either
+// way.
+func runCallSession(ctx context.Context, format Format, holon string, calls []sessionCall) int {
+	call, release, err := openHolonCaller(ctx, holon)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op: %s\n", rpcErrorMessage(err))
+		return 1
+	}
+	defer release()
+
+	emit := func(method string, result *grpcclient.CallResult) int {
+		return printRPCResult(format, method, result)
+	}
+	if streamsJSONArray(format) {
+		// Every response is an element of one array, closed even when a
+		// call fails.
+		array := newJSONArrayWriter(os.Stdout)
+		defer array.Close()
+		emit = func(_ string, result *grpcclient.CallResult) int {
+			if err := array.Add([]byte(result.Output)); err != nil {
+				fmt.Fprintf(os.Stderr, "op: write output: %v\n", err)
+				return 1
+			}
+			return 0
+		}
+	}
+
+	for _, c := range calls {
+		result, err := call(ctx, c.Method, c.InputJSON)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "op: %s: %s\n", c.Method, rpcErrorMessage(err))
+			printRequiredFieldsHint(os.Stderr, holon, c.Method, err)
+			return 1
+		}
+		if code := emit(c.Method, result); code != 0 {
+			return code
+		}
+	}
+	return 0
+}
+
+// openHolonCaller connects to holon through the transport chain for a
+// series of calls. A holon launched on stdio:// is kept running and a
+// running endpoint keeps one connection until release; other transports
+// connect for each call.
+func openHolonCaller(ctx context.Context, holon string) (holonCaller, func(), error) {
+	decision, err := transportDecision(ctx, holon)
+	if err != nil {
+		return func(ctx context.Context, method, inputJSON string) (*grpcclient.CallResult, error) {
+			return callHolon(ctx, holon, method, inputJSON)
+		}, func() {}, nil
+	}
+
+	switch {
+	case isRunningEndpoint(decision):
+		address, err := endpointDialAddress(decision.Endpoint)
+		if err != nil {
+			return nil, nil, err
+		}
+		client := grpcclient.NewClient(address)
+		return client.Call, func() { _ = client.Close() }, nil
+	case decision.Scheme == "stdio":
+		binary, err := resolveHolon(holon)
+		if err != nil {
+			return nil, nil, holonNotFoundError(holon)
+		}
+		session, err := startStdioSession(ctx, binary)
+		if err != nil {
+			return nil, nil, err
+		}
+		return session.Call, session.Close, nil
+	default:
+		return func(ctx context.Context, method, inputJSON string) (*grpcclient.CallResult, error) {
+			return callHolon(ctx, holon, method, inputJSON)
+		}, func() {}, nil
+	}
+}
+
+// startStdioSession launches binaryPath with `serve --listen stdio://` from
+// its launch directory, as callViaStdio does, and keeps it running.
+func startStdioSession(ctx context.Context, binaryPath string) (*grpcclient.StdioSession, error) {
+	startCtx, cancel := grpcclient.CallContext(ctx, grpcclient.Timeout())
+	defer cancel()
+
+	cmd := exec.Command(binaryPath, "serve", "--listen", "stdio://")
+	cmd.Dir = launchDir(ctx, binaryPath)
+	session, err := grpcclient.StartStdioSession(startCtx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("dial stdio: %w", err)
+	}
+	return session, nil
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/organic-programming/go-holons/pkg/transport"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+const envCallSessionHelper = "OP_TEST_CALL_SESSION_LOG"
+
+// TestCallSessionHelperProcess is not a test: launched through the holon
+// seedStdioHelperHolon writes, it records its start in the file named by
+// $OP_TEST_CALL_SESSION_LOG and serves grpc.health.v1 with reflection on
+// stdio:// until stopped.
+func TestCallSessionHelperProcess(t *testing.T) {
+	logPath := os.Getenv(envCallSessionHelper)
+	if logPath == "" {
+		return
+	}
+	if f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644); err == nil {
+		_, _ = f.WriteString("started\n")
+		_ = f.Close()
+	}
+	listener, err := transport.Listen("stdio://")
+	if err != nil {
+		os.Exit(1)
+	}
+	s := grpc.NewServer()
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	reflection.Register(s)
+	_ = s.Serve(listener)
+	os.Exit(0)
+}
+
+// seedStdioHelperHolon writes a holon named name under root whose binary
+// runs TestCallSessionHelperProcess, and returns the file each start is
+// recorded in.
+func seedStdioHelperHolon(t *testing.T, root, name string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script holon binaries are not supported on Windows")
+	}
+	dir := filepath.Join(root, name)
+	writeRunServiceFixture(t, dir, name)
+	binary := filepath.Join(dir, ".op", "build", "bin", name)
+	if err := os.MkdirAll(filepath.Dir(binary), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\nexec '" + os.Args[0] + "' -test.run='^TestCallSessionHelperProcess$'\n"
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	logPath := filepath.Join(t.TempDir(), "starts.log")
+	t.Setenv(envCallSessionHelper, logPath)
+	return logPath
+}
+
+func TestHolonCallFlagsShareOneStdioProcess(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	t.Setenv("OPPATH", t.TempDir())
+	logPath := seedStdioHelperHolon(t, root, "checker")
+
+	code := 0
+	stdout := captureStdout(t, func() {
+		code = Run([]string{"checker", "--transport", "stdio", "--call", "Check", "--call", `grpc.health.v1.Health/Check:{"service":""}`, "--call", "Check:"}, "test")
+	})
+	if code != 0 {
+		t.Fatalf("exit code = %d, stdout = %q", code, stdout)
+	}
+	if n := strings.Count(stdout, "SERVING"); n != 3 {
+		t.Fatalf("stdout = %q, want three SERVING responses", stdout)
+	}
+	starts, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(starts), "started"); n != 1 {
+		t.Fatalf("the holon started %d times, want once", n)
+	}
+
+	var stderr string
+	stdout = captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			code = Run([]string{"checker", "--transport", "stdio", "--call", "Check", "--call", "Nope", "--call", "Check"}, "test")
+		})
+	})
+	if code != 1 || !strings.Contains(stderr, "Nope") {
+		t.Fatalf("exit code = %d, stderr = %q; want the failing call reported", code, stderr)
+	}
+	if n := strings.Count(stdout, "SERVING"); n != 1 {
+		t.Fatalf("stdout = %q, want only the call before the failure", stdout)
+	}
+
+	stdout = captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			code = Run([]string{"--format", "json-stream", "checker", "--transport", "stdio", "--call", "Check", "--call", "Nope"}, "test")
+		})
+	})
+	var responses []map[string]any
+	if err := json.Unmarshal([]byte(stdout), &responses); err != nil || len(responses) != 1 || code != 1 {
+		t.Fatalf("exit code = %d, stdout = %q; want the array closed after the first response (%v)", code, stdout, err)
+	}
+
+	stderr = captureStderr(t, func() {
+		code = Run([]string{"checker", "Check", "--call", "Check"}, "test")
+	})
+	if code != 1 || !strings.Contains(stderr, "--call cannot be combined with a command") {
+		t.Fatalf("exit code = %d, stderr = %q", code, stderr)
+	}
+}
+
+func TestParseSessionCall(t *testing.T) {
+	for value, want := range map[string]sessionCall{
+		"list":                   {Method: "ListIdentities", InputJSON: "{}"},
+		`Ping:{"message":"a:b"}`: {Method: "Ping", InputJSON: `{"message":"a:b"}`},
+		"echo.v1.Echo/Ping: {} ": {Method: "echo.v1.Echo/Ping", InputJSON: "{}"},
+	} {
+		got, err := parseSessionCall(value)
+		if err != nil || got != want {
+			t.Fatalf("parseSessionCall(%q) = %+v, %v; want %+v", value, got, err, want)
+		}
+	}
+	if _, err := parseSessionCall(":{}"); err == nil {
+		t.Fatal("a --call without a method should be rejected")
+	}
+}
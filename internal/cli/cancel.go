@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+
+	"google.golang.org/grpc"
+)
+
+// cancelReport is what op cancel prints once the command is cancelled.
+type cancelReport struct {
+	InvokeID string   `json:"invoke_id"`
+	Server   string   `json:"server"`
+	Holon    string   `json:"holon"`
+	Args     []string `json:"args,omitempty"`
+}
+
+// cmdCancel runs `op cancel <invoke-id> [--server <uri>]`: it asks an OP
+// server to kill the command of an Invoke or InvokeStream in flight. The
+// server is, by default, the running op serve, then the op daemon.
+func cmdCancel(format Format, args []string) int {
+	server, args, err := extractServerFlag(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op cancel: %v\n", err)
+		return 1
+	}
+	if len(args) != 1 || strings.TrimSpace(args[0]) == "" {
+		fmt.Fprintln(os.Stderr, "usage: op cancel <invoke-id> [--server <tcp://host:port|unix://path>]")
+		return 1
+	}
+	id := strings.TrimSpace(args[0])
+
+	if server == "" {
+		if server, err = defaultCancelServer(); err != nil {
+			fmt.Fprintf(os.Stderr, "op cancel: %v\n", err)
+			return 1
+		}
+	}
+	address, err := endpointDialAddress(server)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op cancel: %v\n", err)
+		return 1
+	}
+	conn, err := grpc.NewClient(address, grpcclient.DialOptions()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op cancel: connect to %s: %v\n", server, err)
+		return 1
+	}
+	defer conn.Close()
+
+	ctx, cancel := grpcclient.CallContext(context.Background(), grpcclient.Timeout())
+	defer cancel()
+	resp, err := opv1.NewOPServiceClient(conn).CancelInvoke(ctx, &opv1.CancelInvokeRequest{InvokeId: id})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op cancel: %s\n", rpcErrorMessage(err))
+		return 1
+	}
+
+	report := cancelReport{InvokeID: id, Server: server, Holon: resp.GetHolon(), Args: resp.GetArgs()}
+	if format == FormatJSON {
+		printJSON(report)
+		return 0
+	}
+	fmt.Printf("cancelled %s: %s\n", id, strings.TrimSpace(report.Holon+" "+strings.Join(report.Args, " ")))
+	return 0
+}
+
+// extractServerFlag removes --server <uri> from args. grpc://host:port is
+// accepted as tcp://host:port.
+func extractServerFlag(args []string) (string, []string, error) {
+	server := ""
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--server":
+			if i+1 >= len(args) {
+				return "", nil, fmt.Errorf("--server requires a value")
+			}
+			server = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--server="):
+			server = strings.TrimPrefix(args[i], "--server=")
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+	if rest, ok := strings.CutPrefix(server, "grpc://"); ok {
+		server = "tcp://" + rest
+	}
+	return server, remaining, nil
+}
+
+// defaultCancelServer is the endpoint of the running op serve, or else the
+// op daemon's socket.
+func defaultCancelServer() (string, error) {
+	if entry, ok := liveEndpoint(opServeHolon); ok {
+		return entry.URI, nil
+	}
+	socket, err := filepath.Abs(daemonSocketPath())
+	if err == nil && daemonRunning(socket) {
+		return "unix://" + socket, nil
+	}
+	return "", fmt.Errorf("no op serve or op daemon running; use --server <uri>")
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+
+	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestCancelStopsInvokeInFlight(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	chdirForTest(t, t.TempDir())
+	t.Setenv("OPPATH", t.TempDir())
+	address := startOPServer(t)
+
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	stream, err := opv1.NewOPServiceClient(conn).InvokeStream(context.Background(), &opv1.InvokeRequest{Holon: "sh", Args: []string{"-c", "exec sleep 30"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"--format", "json", "cancel", first.GetInvokeId(), "--server", "grpc://" + address}, "0.1.0-test"); code != 0 {
+			t.Fatalf("cancel returned %d, want 0", code)
+		}
+	})
+	var report cancelReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil || report.InvokeID != first.GetInvokeId() || report.Holon != "sh" {
+		t.Fatalf("report = %+v (%v) from %q", report, err, stdout)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Fatalf("stream error = %v, want Canceled", err)
+	}
+
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"cancel", first.GetInvokeId(), "--server", "tcp://" + address}, "0.1.0-test"); code != 1 {
+			t.Fatalf("cancelling again returned %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "[NotFound]") {
+		t.Fatalf("stderr = %q, want NotFound", stderr)
+	}
+
+	stderr = captureStderr(t, func() {
+		if code := Run([]string{"cancel", "nightly"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("cancel without a server returned %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "no op serve or op daemon running") {
+		t.Fatalf("stderr = %q", stderr)
+	}
+}
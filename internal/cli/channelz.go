@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+)
+
+const channelzUsage = "usage: op channelz <grpc://host:port|grpc+unix://path|host:port>"
+
+// cmdChannelz runs `op channelz <address>`: it dumps the top channels,
+// servers and sockets a server reports through grpc.channelz.v1, to chase
+// connection leaks and subchannel states. The server must opt in with
+// op serve --channelz.
+func cmdChannelz(format Format, args []string) int {
+	if len(args) != 1 || strings.HasPrefix(args[0], "--") {
+		fmt.Fprintln(os.Stderr, channelzUsage)
+		return 1
+	}
+	address, err := parseRPCAddress(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op channelz: %v\n", err)
+		return 1
+	}
+
+	dump, err := grpcclient.DumpChannelz(address)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op channelz: %s\n", rpcErrorMessage(err))
+		return 1
+	}
+
+	if format == FormatJSON {
+		out, err := json.MarshalIndent(dump, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "op channelz: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(out))
+		return 0
+	}
+
+	printChannelzDump(dump)
+	return 0
+}
+
+func printChannelzDump(dump *grpcclient.ChannelzDump) {
+	fmt.Println("Servers:")
+	if len(dump.Servers) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, srv := range dump.Servers {
+		fmt.Printf("  server %d%s  %s\n", srv.ID, channelzName(srv.Name), channelzCalls(srv.Calls))
+		for _, socket := range srv.ListenSockets {
+			fmt.Printf("    listen %d  %s\n", socket.ID, socket.Local)
+		}
+		for _, socket := range srv.Sockets {
+			fmt.Printf("    socket %s\n", channelzSocket(socket))
+		}
+	}
+
+	fmt.Println("Channels:")
+	if len(dump.Channels) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, ch := range dump.Channels {
+		printChannelzChannel(ch, "  ", "channel")
+	}
+}
+
+func printChannelzChannel(ch grpcclient.ChannelzChannel, indent, kind string) {
+	fmt.Printf("%s%s %d%s  %s  %s  %s\n", indent, kind, ch.ID, channelzName(ch.Name), ch.Target, ch.State, channelzCalls(ch.Calls))
+	for _, socket := range ch.Sockets {
+		fmt.Printf("%s  socket %s\n", indent, channelzSocket(socket))
+	}
+	for _, sub := range ch.Subchannels {
+		printChannelzChannel(sub, indent+"  ", "subchannel")
+	}
+}
+
+func channelzName(name string) string {
+	if name == "" {
+		return ""
+	}
+	return " (" + name + ")"
+}
+
+func channelzCalls(calls grpcclient.ChannelzCalls) string {
+	return fmt.Sprintf("calls: %d started, %d succeeded, %d failed", calls.Started, calls.Succeeded, calls.Failed)
+}
+
+func channelzSocket(socket grpcclient.ChannelzSocket) string {
+	return fmt.Sprintf("%d  %s <-> %s  streams: %d started, %d succeeded, %d failed  messages: %d sent, %d received",
+		socket.ID, socket.Local, socket.Remote,
+		socket.StreamsStarted, socket.StreamsSucceeded, socket.StreamsFailed,
+		socket.MessagesSent, socket.MessagesReceived)
+}
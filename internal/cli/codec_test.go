@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// startContentTypeServer serves health and reflection, recording the
+// content-type of each health call. With only set, other content-types are
+// refused as a server without that codec would.
+func startContentTypeServer(t *testing.T, only string) (string, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var seen []string
+	interceptor := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if info.FullMethod == healthpb.Health_Check_FullMethodName {
+			md, _ := metadata.FromIncomingContext(ctx)
+			contentType := strings.Join(md.Get("content-type"), ",")
+			mu.Lock()
+			seen = append(seen, contentType)
+			mu.Unlock()
+			if only != "" && contentType != only {
+				return nil, status.Errorf(codes.Internal, "unsupported content-type %q", contentType)
+			}
+		}
+		return handler(ctx, req)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(grpc.UnaryInterceptor(interceptor))
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	reflection.Register(s)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String(), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), seen...)
+	}
+}
+
+func TestGRPCCodecSetsContentSubtype(t *testing.T) {
+	address, seen := startContentTypeServer(t, "")
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"grpc://" + address, "Check", "--codec", "vtproto"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("--codec call returned %d, want 0", code)
+		}
+	})
+	if !strings.Contains(stdout, "SERVING") {
+		t.Fatalf("stdout = %q", stdout)
+	}
+	_ = captureStdout(t, func() {
+		if code := Run([]string{"grpc://" + address, "Check"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("default call returned %d, want 0", code)
+		}
+	})
+
+	got := seen()
+	if len(got) != 2 || got[0] != "application/grpc+vtproto" || got[1] != "application/grpc" {
+		t.Fatalf("content-types = %q; want the codec only on the --codec call", got)
+	}
+}
+
+func TestGRPCCodecReportsRejectionAndInvalidNames(t *testing.T) {
+	address, _ := startContentTypeServer(t, "application/grpc")
+
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"grpc://" + address, "Check", "--codec=flatbuffers"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("rejected codec returned %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, `server rejected content-subtype "flatbuffers" (--codec)`) {
+		t.Fatalf("stderr = %q", stderr)
+	}
+
+	stderr = captureStderr(t, func() {
+		if code := Run([]string{"grpc://" + address, "Check", "--codec", "flat buffers"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("invalid codec name returned %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "invalid codec name") {
+		t.Fatalf("stderr = %q", stderr)
+	}
+}
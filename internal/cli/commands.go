@@ -3,6 +3,8 @@
 package cli
 
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,24 +15,37 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"strings"
 	"syscall"
-	"text/tabwriter"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
-	"github.com/organic-programming/go-holons/pkg/transport"
-	"github.com/organic-programming/grace-op/internal/grpcclient"
 	"github.com/organic-programming/grace-op/internal/holons"
 	"github.com/organic-programming/grace-op/internal/server"
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+
+	"google.golang.org/grpc"
 )
 
 // Run dispatches the command and returns an exit code.
 func Run(args []string, version string) int {
-	format, quiet, args, err := parseGlobalOptions(args)
+	opts, args, err := parseGlobalFlags(args)
+	if err == nil && opts.ReflectProto == "" {
+		opts.ReflectProto, err = envReflectProtocol()
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "op: %v\n", err)
 		return 1
 	}
+	format := opts.Format
+	defer pinExplain(opts.Explain, os.Stderr)()
+	explainGlobalFlags(opts)
+	defer pinReflectProtocol(opts.ReflectProto)()
+	defer pinMaxInputSize(opts.MaxInputSize)()
+	defer pinTimeout(opts.Timeout)()
+	defer closeMemComposers()
 	if len(args) == 0 {
 		PrintUsage()
 		return 1
@@ -38,90 +53,279 @@ func Run(args []string, version string) int {
 
 	cmd := args[0]
 	rest := args[1:]
+	if err := checkOutputShape(format, commandOutputShape(cmd, rest)); err != nil {
+		fmt.Fprintf(os.Stderr, "op: %v\n", err)
+		return exitUsage
+	}
 
-	switch cmd {
-	// --- OP's own commands ---
-	case "check":
-		return cmdLifecycle(format, quiet, holons.OperationCheck, rest)
-	case "build":
-		return cmdLifecycle(format, quiet, holons.OperationBuild, rest)
-	case "test":
-		return cmdLifecycle(format, quiet, holons.OperationTest, rest)
-	case "clean":
-		return cmdLifecycle(format, quiet, holons.OperationClean, rest)
-	case "install":
-		return cmdInstall(format, quiet, rest)
-	case "uninstall":
-		return cmdUninstall(format, quiet, rest)
-	case "mod":
-		return cmdMod(format, quiet, rest)
-	case "run":
-		return cmdRun(format, quiet, rest)
-	case "discover":
-		return cmdDiscover(format)
-	case "inspect":
-		return cmdInspect(format, rest)
-	case "mcp":
-		return cmdMCP(rest, version)
-	case "tools":
-		return cmdTools(format, rest)
-	case "env":
-		return cmdEnv(format, rest)
-	case "serve":
-		return cmdServe(rest)
-	case "version":
-		fmt.Printf("op %s\n", version)
-		return 0
-	case "completion":
-		return cmdCompletion(rest)
-	case "__complete":
-		return cmdComplete(rest)
-	case "help", "--help", "-h":
-		PrintUsage()
-		return 0
-	case "new", "list", "show":
-		return cmdWho(format, quiet, cmd, rest)
+	explainRoute(cmd)
+	if run, ok := opCommands[cmd]; ok {
+		return run(commandRun{opts: opts, name: cmd, args: rest, version: version})
+	}
 
-	// --- URI dispatch: grpc://, grpc+stdio://, grpc+unix://, grpc+ws:// ---
-	default:
-		if strings.HasPrefix(cmd, "grpc://") ||
-			strings.HasPrefix(cmd, "grpc+stdio://") ||
-			strings.HasPrefix(cmd, "grpc+unix://") ||
-			strings.HasPrefix(cmd, "grpc+ws://") ||
-			strings.HasPrefix(cmd, "grpc+wss://") {
+	// --- URI dispatch: grpc://, grpc+stdio://, grpc+unix://, grpc+ws://, mem:// ---
+	return withHolonConfig(func() int {
+		if isGRPCURI(cmd) {
 			return cmdGRPC(format, cmd, rest)
 		}
 		return cmdHolon(format, cmd, rest)
+	})
+}
+
+// commandRun is what one of op's own commands runs with.
+type commandRun struct {
+	opts globalOptions
+	// name is the command word, for handlers shared by several commands.
+	name    string
+	args    []string
+	version string
+}
+
+// opCommands routes op's own commands; any other first argument names a
+// holon or a gRPC URI. Run dispatches through it, --explain and completion
+// read it. It is filled in init because __complete refers back to it.
+var opCommands map[string]func(commandRun) int
+
+func init() {
+	lifecycle := func(op holons.Operation) func(commandRun) int {
+		return func(r commandRun) int { return cmdLifecycle(r.opts.Format, r.opts.Quiet, op, r.args) }
+	}
+	who := func(r commandRun) int { return cmdWho(r.opts.Format, r.opts.Quiet, r.name, r.args) }
+	help := func(commandRun) int {
+		PrintUsage()
+		return 0
+	}
+	opCommands = map[string]func(commandRun) int{
+		"check":     lifecycle(holons.OperationCheck),
+		"build":     lifecycle(holons.OperationBuild),
+		"test":      lifecycle(holons.OperationTest),
+		"clean":     lifecycle(holons.OperationClean),
+		"install":   func(r commandRun) int { return cmdInstall(r.opts.Format, r.opts.Quiet, r.args) },
+		"uninstall": func(r commandRun) int { return cmdUninstall(r.opts.Format, r.opts.Quiet, r.args) },
+		"init":      func(r commandRun) int { return cmdInit(r.args) },
+		"mod":       func(r commandRun) int { return cmdMod(r.opts.Format, r.opts.Quiet, r.args) },
+		"run":       func(r commandRun) int { return cmdRun(r.opts.Format, r.opts.Quiet, r.args) },
+		"discover":  func(r commandRun) int { return cmdDiscover(r.opts.Format, r.args) },
+		"inspect":   func(r commandRun) int { return cmdInspect(r.opts.Format, r.args) },
+		"reflect":   func(r commandRun) int { return cmdReflect(r.opts.Format, r.args) },
+		"channelz":  func(r commandRun) int { return cmdChannelz(r.opts.Format, r.args) },
+		"compare": func(r commandRun) int {
+			return withHolonConfig(func() int { return cmdCompare(r.opts.Format, r.args) })
+		},
+		"example": func(r commandRun) int { return cmdExample(r.opts.Format, r.args) },
+		"schema":  func(r commandRun) int { return cmdSchema(r.opts.Format, r.args) },
+		"poll": func(r commandRun) int {
+			return withHolonConfig(func() int { return cmdPoll(r.opts.Format, r.args) })
+		},
+		"methods": func(r commandRun) int { return cmdMethods(r.opts.Format, r.args) },
+		"mcp":     func(r commandRun) int { return cmdMCP(r.args, r.version) },
+		"tools":   func(r commandRun) int { return cmdTools(r.opts.Format, r.args) },
+		"env":     func(r commandRun) int { return cmdEnv(r.opts, r.args) },
+		"serve":   func(r commandRun) int { return cmdServe(r.args) },
+		"stop":    func(r commandRun) int { return cmdStop(r.opts.Format, r.args) },
+		"ps":      func(r commandRun) int { return cmdPs(r.opts.Format, r.args) },
+		"which": func(r commandRun) int {
+			return withHolonConfig(func() int { return cmdWhich(r.opts.Format, r.args) })
+		},
+		"cancel": func(r commandRun) int { return cmdCancel(r.opts.Format, r.args) },
+		"daemon": func(r commandRun) int { return cmdDaemon(r.opts.Format, r.args) },
+		"version": func(r commandRun) int {
+			fmt.Printf("op %s\n", r.version)
+			return 0
+		},
+		"completion": func(r commandRun) int { return cmdCompletion(r.args) },
+		"__complete": func(r commandRun) int { return cmdComplete(r.args) },
+		"help":       help,
+		"--help":     help,
+		"-h":         help,
+		"new":        who,
+		"list":       who,
+		"show":       who,
+		"delete":     who,
+		"plan":       who,
+		"apply":      who,
+		"pin":        who,
+		"releases":   who,
+		"grpc": func(r commandRun) int {
+			return withHolonConfig(func() int { return cmdGRPCEndpointFile(r.opts.Format, r.args) })
+		},
 	}
 }
 
+// isGRPCURI reports whether cmd is an address op dispatches to directly.
+func isGRPCURI(cmd string) bool {
+	for _, prefix := range []string{"grpc://", "grpcs://", "grpc+stdio://", "grpc+unix://", "grpc+ws://", "grpc+wss://", "mem://"} {
+		if strings.HasPrefix(cmd, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // PrintUsage displays the help text.
 func PrintUsage() {
 	fmt.Print(`op — the Organic Programming CLI
 
 Global flags (must come before <holon> or URI):
   -f, --format <text|json>              output format for RPC responses (default: text)
+                                         json-stream writes responses as one JSON array, each
+                                         element as it arrives (streams, fan-outs, --call);
+                                         protobin writes the raw response message bytes;
+                                         protobin-delimited prefixes each with its varint length;
+                                         none of these applies to op's own reports (exit 2 when asked);
+                                         text tables fit the terminal, wide keeps every column in full
   -q, --quiet                           suppress progress and suggestions
+  --reflect-proto <v1|v1alpha|auto>     gRPC reflection service to use (default: auto, v1 then v1alpha;
+                                         also $OP_REFLECT_PROTO)
+  --max-input-size <bytes>              refuse request JSON larger than this, e.g. 64KiB (default: 4MiB)
+  --timeout <duration>                  deadline for connecting to a holon and calling it, e.g. 30s or 2m
+                                         (default: 10s)
+  --explain                             narrate each dispatch step on stderr: flags, routing, method,
+                                         transport override and choice, language, launch or dial, how
+                                         the method was resolved, call; requests over 200 bytes are cut
 
 Holon dispatch (transport chain):
-  op <holon> <command> [args]            dispatch via mem://, stdio://, or tcp://
+  op <holon> <command> [args]            dispatch via mem://, stdio://, or tcp://; a holon already
+                                         running from op run/op serve is dialed on its endpoint
+  op <holon> <command> [args]            a lowercase <command> that names no method (or args after
+                                         "--") runs the holon's binary as a command line instead;
+                                         with OP_DAEMON set, op daemon runs it in op's working
+                                         directory and environment and streams its output back
+                                         (piped stdin is not forwarded, so that runs it locally)
+    --env KEY=VAL                        ahead of <command>: set a variable for the command, merged
+                                         onto op's environment; repeat the flag for several
+    --clear-env                          ahead of <command>: give the command only the --env variables
+    --cwd <dir>                          ahead of <command>: run the command in <dir>, relative to op's
+                                         working directory (default: op's working directory)
+    --timeout <duration>                 ahead of <command>: stop the command once it has run this long
+                                         (SIGTERM, then SIGKILL 2s later) and exit 124
+  op '<glob>' <command> [args]           dispatch to every matching holon (e.g. 'sophia-*')
+  op --all <command> [args]              dispatch to every discovered holon
+    --concurrency <n>                    ahead of <command>: number of holons called in parallel
+                                         (default: 1)
+    --fail-fast                          ahead of <command>: cancel outstanding calls as soon as one
+                                         fails
+  --request-id <id>                      x-request-id sent with the call (default: a fresh UUID)
+  --verbose                              print the request ID and chosen transport to stderr
+  --run-dir <dir>                        working directory for a launched holon (default: the holon's directory)
+  --transport <mem|stdio|tcp|unix>       force a transport instead of the chain's choice (also $OP_TRANSPORT)
+  --dry-run                              explain the transport choice without calling the holon
+  --call <method>[:<json>]               call several methods in one session, in order; repeat the flag
+                                         (a holon launched on stdio:// serves them all, then is stopped)
+  --data-template <tmpl|@file|@->        render request JSON from a Go text/template
+                                         (functions: now, uuid, env "NAME", json; quote values with json,
+                                         e.g. '{"motto": {{env "MOTTO" | json}}}')
+  --template <tmpl>                      render the response through a Go text/template instead of --format
+                                         (e.g. '{{len .entries}} holons'; functions: json, join, default)
+  <method> -                             read the request JSON from stdin
+  --bytes-out <dir>                      write the response's bytes fields to files in <dir>, shown as
+                                         "@<file>" (also on gRPC URI dispatch)
+  --bytes-in                             read a request bytes field given as "@<file>" from that file
+                                         (also on gRPC URI dispatch)
+  --compress gzip                        compress the call's messages, for large responses over a
+                                         remote link (also on gRPC URI dispatch)
+  --from-field <path>                    use the object at <path> in the piped JSON (e.g. identity)
 
 Direct gRPC URI dispatch:
   op grpc://<host:port> <method>         gRPC over TCP (existing server)
+  op grpcs://<host:port> <method>        gRPC over TLS; verifies the server certificate
+    --cacert <file>                      PEM CA bundle to verify it with (default: system roots)
+    --cert <file> --key <file>           client certificate and key, for mutual TLS
   op grpc+stdio://<holon> <method>       gRPC over stdio pipe (ephemeral)
   op grpc+unix://<path> <method>         gRPC over Unix socket
   op grpc+ws://<host:port> <method>      gRPC over WebSocket
   op grpc+wss://<host:port> <method>     gRPC over secure WebSocket
+  op mem://<holon> <method> [json]       gRPC in-process, for a Go holon compiled into op
+    --verbose                            print the request ID and which holon answered
+                                         (via HolonMeta.Describe)
+    --expect-holon <slug|uuid>           fail before the call if another holon answers
+    --request-id <id>                    x-request-id sent with the call (default: a fresh UUID)
+    --authority <host>                   :authority (and WebSocket Host) to send instead of the
+                                         dial target's, for servers behind a routing proxy
+    --keepalive-time <duration>          ping the server after this long idle (default: off); keeps
+                                         connections alive through NAT/LB idle timeouts, e.g. 30s
+                                         (gRPC's minimum is 10s; the server must allow pings that often)
+    --keepalive-timeout <duration>       close the connection when a ping is unanswered this long
+                                         (default: 20s), e.g. 10s
+    --timeout <duration>                 deadline for the call itself, overriding any
+                                         timeouts: entry in .holonconfig, e.g. 2m
+    --codec <name>                       gRPC content-subtype for the call, for servers with a custom
+                                         codec (default: proto); the body stays protobuf-encoded
+    --compress gzip                      compress the call's messages, for large responses over a
+                                         remote link (default: uncompressed); op serve understands it
+    --descriptor-set <file>              resolve methods from a FileDescriptorSet instead of
+                                         reflection (protoc --descriptor_set_out --include_imports);
+                                         gzip .pb.gz accepted, repeat to merge several sets
+    streaming methods print each response as one JSON line as it arrives; a client-streaming
+    method called without a request argument reads one JSON request per line from stdin
+    (grpc://, grpcs:// and grpc+unix:// addresses)
+    (env: OP_PROTO_PREFIXES=protos/,api/ lists the prefixes tried when reflection
+     cannot find an imported .proto by its bare name; default: protos/)
+  op grpc --endpoint-file <path> <method>
+                                         call the address a server wrote to path (host:port, or a
+                                         grpc://, tcp://, unix:// or ws:// URI), waiting for it to appear;
+                                         only a newline-terminated line is read (op run/op serve --addr-file)
+    --endpoint-timeout <duration>        how long to wait for the file (default: 5s)
+  op poll <grpc://host:port|holon> <method> [json] --until <condition>
+                                         repeat a call until the condition holds; a path the
+                                         method's response cannot have is an error
+    --interval <duration>                wait between attempts (default: 2s)
+    --max-attempts <n>                   give up after n calls (default: 30)
   op run <holon> [flags]                 build if needed, then launch in foreground
   op run <holon>:<port>                  shorthand for --listen tcp://:<port>
+  op stop <holon>                        stop a holon started by op run or op serve from this
+                                         workspace (.holon/run/<holon>.json): SIGTERM, then SIGKILL
+                                         if it is still running after 5s; a holon whose endpoint does
+                                         not answer is not signalled, as its pid may have been reused
+  op ps                                  list holons started by op run or op serve from this workspace,
+                                         with their pid, listen URI and whether they are live or dead;
+                                         entries whose process has exited are removed once listed
+  op cancel <invoke-id> [--server <uri>] kill an Invoke or InvokeStream command still in flight on the
+                                         running op serve or op daemon (ids are in the server's log)
+  op which <holon> [--transport <t>]     show how op <holon> is dispatched without launching it: the
+                                         binary paths checked and matched, the holon's lang, and the
+                                         transport the chain picks, with any --transport/OP_TRANSPORT override
 
 OP commands:
+  op init [<dir>] [--force]              create holons/ and a commented .holonconfig template
   op list [root]                         list local + cached holons natively
   op show <uuid-or-prefix>               display a holon identity natively
+    --mask <fields>                      only populate these identity fields, e.g. uuid,givenName;
+                                         trims the response itself, so a daemon sends only these
+                                         fields; --template on RPC calls only changes printing
+  op delete <uuid> [--purge] [--force]   delete a local holon's identity file natively
+    --purge                              remove the holon's whole directory, not only the file
+    --force                              delete even a stable or pinned holon
   op new [--json <payload>]              create a holon identity natively
   op new --list                          list shipped holon templates
+  op new --batch <file> [--fail-fast]    create one identity per CSV row or JSON array item
+    --dry-run                            validate every row and report what would be created
   op new --template <name> <holon-name>  generate a holon scaffold from a template
+  op plan <dir> [--root <dir>]           diff desired holon.yaml files against current identities
+  op apply <dir> [--root <dir>] [--prune]
+                                         create/update identities to match <dir> (--prune deletes,
+                                         except stable or pinned holons, as op who delete does)
+  op pin <uuid-or-prefix> --from-binary <path>
+                                         detect a built binary's version/commit/os/arch and pin it in holon.yaml
+    --version, --tag, --commit, --os, --arch
+                                         set pin fields explicitly (override detected values)
+  op releases [root] [--unpinned-only]   audit each holon's pinned version, tag, commit and os/arch
   op inspect <slug|host:port> [--json]   inspect a holon's API offline or via Describe
+  op compare <holon> <method> [json]     call a method over mem, stdio and tcp and diff the responses
+  op example <grpc://host:port|holon> <method>
+                                         print an editable request skeleton for a method
+  op schema <grpc://host:port|holon> <method>
+                                         print a method's request and response fields as a tree
+                                         (--format json prints a JSON Schema document)
+  op reflect <grpc://host:port> [--dump] list methods via server reflection
+                                         (--dump prints raw file descriptors and dependencies)
+  op channelz <grpc://host:port>         dump channels, servers and sockets (needs op serve --channelz)
+  op methods [--timeout <d>] [--refresh] list the RPC methods of every discoverable holon
+    --summary                            count unary and streaming methods per service instead
+  op methods <grpc://host:port|grpc+unix://path>
+                                         list one running server's methods (e.g. op serve) via reflection
+  op methods <holon>                     list one holon's methods by service, reached like a call
+                                         (mem://, stdio:// or its running endpoint)
   op mcp <slug> [slug2...]               start an MCP server for one or more holons
   op tools <slug> [--format <fmt>]       output tool definitions (openai, anthropic, mcp)
   op check [<holon-or-path>]             validate holon.yaml and prerequisites
@@ -131,7 +335,8 @@ OP commands:
   op install [<holon-or-path>] [flags]   install a built artifact into $OPBIN
   op uninstall <holon>                   remove an installed artifact from $OPBIN
   op mod <command>                       manage holon.mod and holon.sum
-  op env [--init] [--shell]              print resolved OPPATH / OPBIN / ROOT
+  op env [--init] [--shell]              print resolved OPPATH / OPBIN / ROOT, then every setting op
+                                         resolved and its source (default, manifest, project-config, env or flag)
 
 Build flags:
   --target <macos|linux|windows|ios|ios-simulator|tvos|tvos-simulator|watchos|watchos-simulator|visionos|visionos-simulator|android|all>   platform target (default: current OS)
@@ -143,13 +348,56 @@ Install flags:
   --link-applications                          symlink installed .app bundles into /Applications (macOS only)
 
 Run flags:
-  --listen <URI>                               listen address for service holons (default: stdio://)
+  --listen <URI>                               listen address for service holons (default: $OP_LISTEN or stdio://)
   --no-build                                   fail if the artifact is missing instead of building
   --target <...>                               pass build target through if a build is needed
   --mode <debug|release|profile>               pass build mode through if a build is needed
+  --wait                                       start in the background and return once the server accepts connections
+  --wait-timeout <duration>                    how long --wait polls for readiness (default: 10s)
+  --run-dir <dir>                              working directory for the holon (default: the holon's directory)
+  --addr-file <path>                           once ready, write the holon's address to <path> as one line
+                                               (implies --wait; read it back with op grpc --endpoint-file)
+  (with --format json, op run prints {holon, pid, listen, status} and moves the holon's stdout to stderr)
 
   op discover                            list available holons
+    --path <dir>                         scan <dir> instead of the working directory; repeat for several
+    --max-depth <n>                      descend at most <n> directories below each scanned root
+    --since/--until <YYYY-MM-DD>         only holons born in that window (inclusive)
+    --include-unborn                     keep holons with a missing or malformed born date
+    --lang, --clade, --status <value>    only holons with that lang, clade or status; repeat the flag
+                                         or separate values with commas to match any of them, and
+                                         combine flags to match all (e.g. --lang go --status stable)
+    --strict                             fail when a holon.yaml cannot be parsed (default: warn and skip it)
+    --include-bare                       also list directories with a binary named after them but no holon.yaml yet
+    --verbose                            report scan progress (directories walked, holons found) on stderr
   op serve [--listen tcp://:9090]        start OP's own gRPC server
+    --no-reflect, --no-health            skip the reflection or grpc.health.v1 service, both served
+                                         by default
+                                         (env: OP_LISTEN, OP_NO_REFLECT, OP_NO_HEALTH; flags take precedence)
+                                         (stdio:// serves a single connection; reconnecting clients need tcp:// or unix://)
+    --idle-timeout <duration>            exit once no RPC has been in flight for <duration> (e.g. 30s);
+                                         guards stdio:// servers whose client hangs without closing.
+                                         op already stops the stdio holons it launches when a call ends
+    --channelz                           register grpc.channelz.v1 (off by default: it exposes internals);
+                                         inspect it with op channelz
+    --socket-mode <octal>                unix:// only: chmod the socket after bind (e.g. 0660)
+    --socket-owner <user:group>          unix:// only: chown the socket after bind so that account can connect
+                                         (Unix only; usually needs op serve to run as root)
+    --oneshot                            exit cleanly after one RPC has been answered (same as --max-requests 1)
+    --max-requests <n>                   exit cleanly after <n> RPCs have been answered; reflection and
+                                         health calls are not counted, so clients can look methods up first
+    --sandbox-root <dir>                 directory an Invoke's working_dir must stay in (default: the
+                                         server's working directory)
+    --addr-file <path>                   write the bound tcp:// or unix:// address to <path> as one line
+                                         once listening, and remove it on exit
+  op daemon [start|stop|status]          keep OP's own gRPC server warm on a Unix socket
+    --socket <path>                      socket path (env: OP_DAEMON_SOCKET; default: $OPPATH/run/op.sock)
+    --idle-timeout <duration>            start only: exit once idle for <duration>
+                                         OP_DAEMON=on forwards op list and holon command lines
+                                         (op <holon> <command>) to a running daemon;
+                                         OP_DAEMON=auto also starts one on first use (idle timeout 30m);
+                                         RPC calls (op <holon> <Method>) are not forwarded; a command
+                                         the daemon cannot run is reported and runs locally
   op version                             show op version
   op help                                this message
 `)
@@ -165,27 +413,68 @@ type discoverEntry struct {
 	Lang         string `json:"lang"`
 	Clade        string `json:"clade"`
 	Status       string `json:"status"`
+	Born         string `json:"born,omitempty"`
 	RelativePath string `json:"relative_path"`
 	Origin       string `json:"origin"`
+	Binary       string `json:"binary,omitempty"`
 }
 
 type discoverOutput struct {
-	Entries           []discoverEntry `json:"entries"`
-	InstalledBinaries []string        `json:"installed_binaries,omitempty"`
-	PathBinaries      []string        `json:"path_binaries"`
+	Entries           []discoverEntry   `json:"entries"`
+	InstalledBinaries []string          `json:"installed_binaries,omitempty"`
+	PathBinaries      []string          `json:"path_binaries"`
+	Invalid           []discoverInvalid `json:"invalid,omitempty"`
 }
 
-func cmdDiscover(format Format) int {
-	located, err := holons.DiscoverLocalHolons()
+// discoverInvalid is a holon manifest discover skipped because it could not
+// be parsed.
+type discoverInvalid struct {
+	Path   string `json:"path"`
+	Origin string `json:"origin"`
+	Error  string `json:"error"`
+}
+
+// cmdDiscover lists every discoverable holon. Manifests that fail to parse
+// are skipped with a warning so one bad file cannot hide the rest; --strict
+// makes them fail the command.
+func cmdDiscover(format Format, args []string) int {
+	strict, args := extractBoolFlag(args, "--strict")
+	includeBare, args := extractBoolFlag(args, "--include-bare")
+	verbose, args := extractBoolFlag(args, "--verbose")
+	scan, args, err := extractDiscoverScanFlags(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op discover: %v\n", err)
+		return 1
+	}
+	filter, err := parseDiscoverArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op discover: %v\n", err)
+		return 1
+	}
+
+	var onProgress func(holons.ScanProgress)
+	if verbose {
+		onProgress = discoverProgress()
+	}
+	scan.Progress = onProgress
+	located, invalidLocal, err := holons.DiscoverLocalHolonsWithOptions(scan)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "op discover: %v\n", err)
 		return 1
 	}
-	cached, err := holons.DiscoverCachedHolons()
+	cached, invalidCached, err := holons.DiscoverCachedHolonsWithProgress(onProgress)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "op discover: %v\n", err)
 		return 1
 	}
+	invalid := make([]discoverInvalid, 0, len(invalidLocal)+len(invalidCached))
+	for _, bad := range append(invalidLocal, invalidCached...) {
+		invalid = append(invalid, discoverInvalid{
+			Path:   filepath.ToSlash(filepath.Join(bad.RelativePath, filepath.Base(bad.Path))),
+			Origin: discoverOrigin(bad.Origin),
+			Error:  bad.Err.Error(),
+		})
+	}
 
 	entries := make([]discoverEntry, 0, len(located)+len(cached))
 	for _, h := range append(append([]holons.LocalHolon{}, located...), cached...) {
@@ -201,18 +490,34 @@ func cmdDiscover(format Format) int {
 			Lang:         h.Identity.Lang,
 			Clade:        h.Identity.Clade,
 			Status:       h.Identity.Status,
+			Born:         h.Identity.Born,
 			RelativePath: h.RelativePath,
 			Origin:       discoverOrigin(h.Origin),
 		})
 	}
-	installedHolons := holons.DiscoverInOPBIN()
-	pathHolons := discoverInPath()
+	if includeBare {
+		bare, err := holons.DiscoverBareHolonsWithOptions(scan)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "op discover: %v\n", err)
+			return 1
+		}
+		entries = append(entries, bareDiscoverEntries(bare)...)
+	}
+	entries = filter.apply(entries)
+
+	// Bare binaries carry no identity, so any filter leaves them out.
+	var installedHolons, pathHolons []string
+	if !filter.active() {
+		installedHolons = holons.DiscoverInOPBIN()
+		pathHolons = discoverInPath()
+	}
 
 	if format == FormatJSON {
 		payload := discoverOutput{
 			Entries:           entries,
 			InstalledBinaries: installedHolons,
 			PathBinaries:      pathHolons,
+			Invalid:           invalid,
 		}
 		out, err := json.MarshalIndent(payload, "", "  ")
 		if err != nil {
@@ -220,23 +525,40 @@ func cmdDiscover(format Format) int {
 			return 1
 		}
 		fmt.Println(string(out))
-		return 0
+		if len(entries) == 0 && filter.active() {
+			fmt.Fprintln(os.Stderr, "op discover: no holons match the filters")
+		}
+	} else if len(entries) == 0 && filter.active() {
+		fmt.Println("No holons match the filters.")
+	} else {
+		printDiscoverTable(format, entries, installedHolons, pathHolons)
 	}
 
-	printDiscoverTable(entries, installedHolons, pathHolons)
+	for _, bad := range invalid {
+		fmt.Fprintf(os.Stderr, "op discover: warning: skipped %s: %s\n", bad.Path, bad.Error)
+	}
+	if strict && len(invalid) > 0 {
+		fmt.Fprintf(os.Stderr, "op discover: %d invalid holon manifest(s) (--strict)\n", len(invalid))
+		return 1
+	}
 	return 0
 }
 
-func printDiscoverTable(entries []discoverEntry, installedHolons, pathHolons []string) {
+func printDiscoverTable(format Format, entries []discoverEntry, installedHolons, pathHolons []string) {
 	if len(entries) == 0 {
 		fmt.Println("No holons found in known roots.")
 	} else {
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "SLUG\tNAME\tLANG\tCLADE\tSTATUS\tORIGIN\tUUID")
+		t := newTable(
+			tableColumn{Header: "SLUG"},
+			tableColumn{Header: "NAME", Truncate: true},
+			tableColumn{Header: "LANG", Drop: 2},
+			tableColumn{Header: "CLADE", Drop: 3},
+			tableColumn{Header: "STATUS"},
+			tableColumn{Header: "ORIGIN", Drop: 4},
+			tableColumn{Header: "UUID", Drop: 5, Truncate: true},
+		)
 		for _, entry := range entries {
-			fmt.Fprintf(
-				w,
-				"%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.add(
 				defaultDash(entry.Slug),
 				discoverDisplayName(entry),
 				defaultDash(entry.Lang),
@@ -246,7 +568,7 @@ func printDiscoverTable(entries []discoverEntry, installedHolons, pathHolons []s
 				defaultDash(entry.UUID),
 			)
 		}
-		_ = w.Flush()
+		t.render(os.Stdout, tableWidth(format), "")
 	}
 
 	if len(installedHolons) > 0 {
@@ -264,6 +586,26 @@ func printDiscoverTable(entries []discoverEntry, installedHolons, pathHolons []s
 	}
 }
 
+// bareDiscoverEntries lists holons in progress: only the directory and its
+// binary are known, so the status is "unknown".
+func bareDiscoverEntries(bare []holons.BareHolon) []discoverEntry {
+	entries := make([]discoverEntry, 0, len(bare))
+	for _, b := range bare {
+		binary, err := filepath.Rel(b.Dir, b.Binary)
+		if err != nil {
+			binary = filepath.Base(b.Binary)
+		}
+		entries = append(entries, discoverEntry{
+			Slug:         filepath.Base(b.Dir),
+			Status:       "unknown",
+			RelativePath: b.RelativePath,
+			Origin:       "local",
+			Binary:       filepath.ToSlash(filepath.Join(b.RelativePath, binary)),
+		})
+	}
+	return entries
+}
+
 func discoverDisplayName(entry discoverEntry) string {
 	name := strings.TrimSpace(entry.GivenName + " " + entry.FamilyName)
 	if name == "" {
@@ -280,16 +622,42 @@ func discoverOrigin(origin string) string {
 }
 
 func cmdServe(args []string) int {
-	// Support both --listen <URI> and legacy --port <port>
-	listenURI := flagOrDefault(args, "--listen", "")
-	if listenURI == "" {
-		port := flagOrDefault(args, "--port", "9090")
-		listenURI = "tcp://:" + port
+	cfg, ignored, err := parseServeArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op serve: %v\n", err)
+		return 1
+	}
+	for _, arg := range ignored {
+		fmt.Fprintf(os.Stderr, "op serve: ignoring unknown argument %q\n", arg)
 	}
-	noReflect := flagValue(args, "--no-reflect")
-	reflect := noReflect == ""
 
-	if err := server.ListenAndServe(listenURI, reflect); err != nil {
+	opts := server.ServeOptions{
+		Reflect:     cfg.Reflect,
+		Health:      cfg.Health,
+		IdleTimeout: cfg.IdleTimeout,
+		Channelz:    cfg.Channelz,
+		SocketMode:  cfg.SocketMode,
+		SocketOwner: cfg.SocketOwner,
+		MaxRequests: cfg.MaxRequests,
+		SandboxRoot: cfg.SandboxRoot,
+	}
+	opts.Listening = func(addr net.Addr) {
+		uri := boundListenURI(cfg.ListenURI, addr)
+		if uri == "" {
+			return
+		}
+		recordEndpoint(opServeHolon, uri, os.Getpid())
+		if cfg.AddrFile != "" {
+			if err := writeAddrFile(cfg.AddrFile, uri); err != nil {
+				fmt.Fprintf(os.Stderr, "op serve: %v\n", err)
+			}
+		}
+	}
+	defer forgetEndpoint(opServeHolon, os.Getpid())
+	if cfg.AddrFile != "" {
+		defer os.Remove(cfg.AddrFile)
+	}
+	if err := server.ListenAndServeWithOptions(cfg.ListenURI, opts); err != nil {
 		fmt.Fprintf(os.Stderr, "op serve: %v\n", err)
 		return 1
 	}
@@ -302,6 +670,10 @@ type runOptions struct {
 	NoBuild        bool
 	Target         string
 	Mode           string
+	Wait           bool
+	WaitTimeout    time.Duration
+	RunDir         string
+	AddrFile       string
 }
 
 // cmdRun builds a holon artifact if needed, then launches it in the foreground.
@@ -311,7 +683,11 @@ func cmdRun(format Format, globalQuiet bool, args []string) int {
 
 	holonName, opts, err := parseRunArgs(args)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "op run: %v\n", err)
+		if format == FormatJSON {
+			printJSON(runReport{Holon: holonName, Status: runStatusFailed, Error: err.Error()})
+		} else {
+			fmt.Fprintf(os.Stderr, "op run: %v\n", err)
+		}
 		return 1
 	}
 	printer := commandProgress(format, quiet)
@@ -327,41 +703,40 @@ func cmdRun(format Format, globalQuiet bool, args []string) int {
 		printer.Step("launching " + holonName + "...")
 		cmd, err := commandForInstalledArtifact(binary, resolvedTarget, opts.ListenURI)
 		if err != nil {
-			printer.Done("run failed", err)
-			fmt.Fprintf(os.Stderr, "op run: %v\n", err)
-			return 1
+			return runFailed(format, printer, holonName, err)
 		}
-		cmd.Stdin = os.Stdin
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		if err := runForeground(cmd); err != nil {
-			if code, ok := commandExitCode(err); ok {
-				return code
+		cmd.Dir = opts.RunDir
+		if cmd.Dir == "" {
+			if resolvedTarget != nil {
+				cmd.Dir = resolvedTarget.Manifest.Dir
+			} else {
+				cmd.Dir = holonRunDir(binary)
 			}
-			printer.Done("run failed", err)
-			fmt.Fprintf(os.Stderr, "op run: %v\n", err)
-			return 1
 		}
-		printer.Done(fmt.Sprintf("%s exited in %s", holonName, humanElapsed(printer)), nil)
-		return 0
+		if opts.Wait {
+			if resolvedTarget != nil && resolvedTarget.Manifest.Manifest.Kind == holons.KindComposite {
+				err := fmt.Errorf("--wait is only supported for service holons")
+				return runFailed(format, printer, holonName, err)
+			}
+			return runUntilReady(format, printer, holonName, cmd, opts)
+		}
+		listen := opts.ListenURI
+		if resolvedTarget != nil && resolvedTarget.Manifest.Manifest.Kind == holons.KindComposite {
+			listen = ""
+		}
+		return runInForeground(format, printer, holonName, listen, cmd)
 	}
 
 	target, err := holons.ResolveTarget(holonName)
 	if err != nil {
-		printer.Done("run failed", err)
-		fmt.Fprintf(os.Stderr, "op run: %v\n", err)
-		return 1
+		return runFailed(format, printer, holonName, err)
 	}
 	if target.ManifestErr != nil {
-		printer.Done("run failed", target.ManifestErr)
-		fmt.Fprintf(os.Stderr, "op run: %v\n", target.ManifestErr)
-		return 1
+		return runFailed(format, printer, holonName, target.ManifestErr)
 	}
 	if target.Manifest == nil {
 		err := fmt.Errorf("no %s found in %s", holons.ManifestFileName, target.RelativePath)
-		printer.Done("run failed", err)
-		fmt.Fprintf(os.Stderr, "op run: %v\n", err)
-		return 1
+		return runFailed(format, printer, holonName, err)
 	}
 
 	ctx, err := holons.ResolveBuildContext(target.Manifest, holons.BuildOptions{
@@ -369,43 +744,31 @@ func cmdRun(format Format, globalQuiet bool, args []string) int {
 		Mode:   opts.Mode,
 	})
 	if err != nil {
-		printer.Done("run failed", err)
-		fmt.Fprintf(os.Stderr, "op run: %v\n", err)
-		return 1
+		return runFailed(format, printer, holonName, err)
 	}
 	if ctx.Target == "all" {
 		err := fmt.Errorf("target %q cannot be launched", ctx.Target)
-		printer.Done("run failed", err)
-		fmt.Fprintf(os.Stderr, "op run: %v\n", err)
-		return 1
+		return runFailed(format, printer, holonName, err)
 	}
 
 	isComposite := target.Manifest.Manifest.Kind == holons.KindComposite
 	if isComposite && opts.ListenExplicit {
 		err := fmt.Errorf("--listen is only supported for service holons")
-		printer.Done("run failed", err)
-		fmt.Fprintf(os.Stderr, "op run: %v\n", err)
-		return 1
+		return runFailed(format, printer, holonName, err)
 	}
 
 	artifactPath := target.Manifest.ArtifactPath(ctx)
 	if artifactPath == "" {
 		err := fmt.Errorf("no artifact declared for target %q mode %q", ctx.Target, ctx.Mode)
-		printer.Done("run failed", err)
-		fmt.Fprintf(os.Stderr, "op run: %v\n", err)
-		return 1
+		return runFailed(format, printer, holonName, err)
 	}
 	if _, err := os.Stat(artifactPath); err != nil {
 		if !os.IsNotExist(err) {
-			printer.Done("run failed", err)
-			fmt.Fprintf(os.Stderr, "op run: %v\n", err)
-			return 1
+			return runFailed(format, printer, holonName, err)
 		}
 		if opts.NoBuild {
 			err := fmt.Errorf("artifact missing: %s", artifactPath)
-			printer.Done("run failed", err)
-			fmt.Fprintf(os.Stderr, "op run: %v\n", err)
-			return 1
+			return runFailed(format, printer, holonName, err)
 		}
 		printer.Step("building " + holonName + "...")
 		if _, err := holons.ExecuteLifecycle(holons.OperationBuild, holonName, holons.BuildOptions{
@@ -413,25 +776,23 @@ func cmdRun(format Format, globalQuiet bool, args []string) int {
 			Mode:     opts.Mode,
 			Progress: printer,
 		}); err != nil {
-			printer.Done("run failed", err)
-			fmt.Fprintf(os.Stderr, "op run: %v\n", err)
-			return 1
+			return runFailed(format, printer, holonName, err)
 		}
 		if _, err := os.Stat(artifactPath); err != nil {
 			if os.IsNotExist(err) {
 				err = fmt.Errorf("artifact missing: %s", artifactPath)
 			}
-			printer.Done("run failed", err)
-			fmt.Fprintf(os.Stderr, "op run: %v\n", err)
-			return 1
+			return runFailed(format, printer, holonName, err)
 		}
 	}
 
 	cmd, err := commandForArtifact(target.Manifest, ctx, opts.ListenURI)
 	if err != nil {
-		printer.Done("run failed", err)
-		fmt.Fprintf(os.Stderr, "op run: %v\n", err)
-		return 1
+		return runFailed(format, printer, holonName, err)
+	}
+	cmd.Dir = opts.RunDir
+	if cmd.Dir == "" {
+		cmd.Dir = target.Manifest.Dir
 	}
 	isApp := target.Manifest.Manifest.Kind == holons.KindComposite &&
 		isMacAppBundle(target.Manifest.ArtifactPath(ctx))
@@ -440,19 +801,18 @@ func cmdRun(format Format, globalQuiet bool, args []string) int {
 	} else {
 		printer.Step("launching " + holonName + "...")
 	}
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := runForeground(cmd); err != nil {
-		if code, ok := commandExitCode(err); ok {
-			return code
+	if opts.Wait {
+		if isComposite {
+			err := fmt.Errorf("--wait is only supported for service holons")
+			return runFailed(format, printer, holonName, err)
 		}
-		printer.Done("run failed", err)
-		fmt.Fprintf(os.Stderr, "op run: %v\n", err)
-		return 1
+		return runUntilReady(format, printer, holonName, cmd, opts)
 	}
-	printer.Done(fmt.Sprintf("%s exited in %s", holonName, humanElapsed(printer)), nil)
-	return 0
+	listen := opts.ListenURI
+	if isComposite {
+		listen = ""
+	}
+	return runInForeground(format, printer, holonName, listen, cmd)
 }
 
 // cmdGRPC handles gRPC URI dispatching.
@@ -460,33 +820,146 @@ func cmdRun(format Format, globalQuiet bool, args []string) int {
 // Transport schemes:
 //   - grpc://host:port <method>       → TCP to existing server
 //   - grpc://host:port                → list available methods
+//   - grpcs://host:port <method>      → TLS to existing server
 //   - grpc://holon <method>           → ephemeral TCP: start binary, call, stop
 //   - grpc+stdio://holon <method>     → stdio pipe: launch, pipe, call, done
 //   - grpc+unix://path <method>       → Unix domain socket connection
+//   - mem://holon <method>            → in-process: the holon's mem composer
 func cmdGRPC(format Format, uri string, args []string) int {
+	args, err := applyStdinInput(args)
+	if err == nil {
+		args, err = applyDataTemplate(args)
+	}
+	var check connectCheck
+	if err == nil {
+		check, args, err = extractConnectCheckFlags(args)
+	}
+	var requestID string
+	if err == nil {
+		requestID, args, err = extractRequestIDFlag(args)
+	}
+	var authority string
+	if err == nil {
+		authority, args, err = extractAuthorityFlag(args)
+	}
+	if err == nil && authority != "" && strings.HasPrefix(uri, "grpc+stdio://") {
+		err = fmt.Errorf("--authority does not apply to grpc+stdio:// addresses")
+	}
+	if err == nil && authority != "" && strings.HasPrefix(uri, "mem://") {
+		err = fmt.Errorf("--authority does not apply to mem:// addresses")
+	}
+	var keepalive keepaliveFlags
+	if err == nil {
+		keepalive, args, err = extractKeepaliveFlags(args)
+	}
+	var tlsConf *tls.Config
+	if err == nil {
+		var flags tlsFlags
+		flags, args, err = extractTLSFlags(args)
+		switch {
+		case err != nil:
+		case strings.HasPrefix(uri, "grpcs://"):
+			tlsConf, err = grpcclient.LoadTLSConfig(flags.CACert, flags.Cert, flags.Key)
+		case flags.set():
+			err = fmt.Errorf("--cacert, --cert and --key only apply to grpcs:// addresses")
+		}
+	}
+	var timeout time.Duration
+	if err == nil {
+		timeout, args, err = extractTimeoutFlag(args)
+	}
+	var descriptorSets []string
+	if err == nil {
+		descriptorSets, args, err = extractDescriptorSetFlags(args)
+	}
+	var codec string
+	if err == nil {
+		codec, args, err = extractCodecFlag(args)
+	}
+	var compress string
+	if err == nil {
+		compress, args, err = extractCompressFlag(args)
+	}
+	var bytesOut string
+	if err == nil {
+		bytesOut, args, err = extractBytesOutFlag(args)
+	}
+	bytesIn, args := extractBoolFlag(args, "--bytes-in")
+	releaseCodec := func() {}
+	if err == nil {
+		releaseCodec, err = pinCodec(codec)
+	}
+	releaseCompressor := func() {}
+	if err == nil {
+		releaseCompressor, err = pinCompressor(compress)
+	}
+	releaseDescriptors := func() {}
+	if err == nil {
+		releaseDescriptors, err = pinDescriptorSets(descriptorSets)
+	}
+	releaseTemplate := func() {}
+	if err == nil {
+		args, releaseTemplate, err = extractOutputTemplateFlag(format, args)
+	}
+	if err != nil {
+		releaseCodec()
+		releaseCompressor()
+		releaseDescriptors()
+		fmt.Fprintf(os.Stderr, "op grpc: %v\n", err)
+		return 1
+	}
+	defer releaseCodec()
+	defer releaseCompressor()
+	defer releaseDescriptors()
+	defer releaseTemplate()
+	defer pinCallTimeout(timeout)()
+	defer pinAuthority(authority)()
+	defer pinKeepalive(keepalive)()
+	defer pinBytesFiles(bytesIn, bytesOut)()
+
+	requestID, release := pinRequestID(requestID)
+	defer release()
+	if check.Verbose {
+		fmt.Fprintf(os.Stderr, "request-id: %s\n", requestID)
+	}
+	explainGRPCArgs(args)
+
 	switch {
-	case strings.HasPrefix(uri, "grpc+stdio://"):
-		return cmdGRPCStdio(format, uri, args)
 	case strings.HasPrefix(uri, "grpc+unix://"):
-		return cmdGRPCDirect(format, "unix://"+strings.TrimPrefix(uri, "grpc+unix://"), args)
+		explainf("dialing unix socket %s directly", strings.TrimPrefix(uri, "grpc+unix://"))
+		return cmdGRPCDirect(format, "unix://"+strings.TrimPrefix(uri, "grpc+unix://"), args, check, nil)
+	case strings.HasPrefix(uri, "grpcs://"):
+		address := strings.TrimPrefix(uri, "grpcs://")
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			fmt.Fprintf(os.Stderr, "op grpc: grpcs:// needs host:port, got %q\n", address)
+			return 1
+		}
+		explainf("dialing %s directly over TLS", address)
+		return cmdGRPCDirect(format, address, args, check, tlsConf)
+	case strings.HasPrefix(uri, "grpc+stdio://"):
+		return cmdGRPCStdio(format, uri, args, check)
+	case strings.HasPrefix(uri, "mem://"):
+		explainf("calling the in-process composer for %s over mem://", strings.TrimPrefix(uri, "mem://"))
+		return cmdGRPCMem(format, strings.TrimPrefix(uri, "mem://"), args, check)
 	case strings.HasPrefix(uri, "grpc+ws://") || strings.HasPrefix(uri, "grpc+wss://"):
-		return cmdGRPCWebSocket(format, uri, args)
+		explainf("dialing %s over a WebSocket", webSocketDialURI(uri))
+		return cmdGRPCWebSocket(format, uri, args, check)
 	default:
-		return cmdGRPCTCP(format, uri, args)
+		return cmdGRPCTCP(format, uri, args, check)
 	}
 }
 
 // cmdGRPCTCP handles grpc://host:port and grpc://holon (ephemeral TCP).
-func cmdGRPCTCP(format Format, uri string, args []string) int {
+func cmdGRPCTCP(format Format, uri string, args []string, check connectCheck) int {
 	address := strings.TrimPrefix(uri, "grpc://")
 
 	_, _, err := net.SplitHostPort(address)
 	isHostPort := err == nil
 
 	if isHostPort {
-		return cmdGRPCDirect(format, address, args)
+		explainf("dialing %s directly", address)
+		return cmdGRPCDirect(format, address, args, check, nil)
 	}
-
 	// Ephemeral TCP mode: address is a holon name
 	holonName := address
 	if len(args) < 1 {
@@ -495,64 +968,98 @@ func cmdGRPCTCP(format Format, uri string, args []string) int {
 		return 1
 	}
 
-	scheme, err := selectTransport(holonName)
+	decision, err := selectTransportWithReason(holonName, nil)
 	if err == nil {
-		switch scheme {
-		case "mem":
-			return cmdGRPCMem(format, holonName, args)
-		case "stdio":
-			return cmdGRPCStdio(format, "grpc+stdio://"+holonName, args)
+		explainDecision(holonName, decision)
+		switch {
+		case isRunningEndpoint(decision):
+			explainf("dialing the running instance at %s", decision.Endpoint)
+			return cmdGRPCEndpoint(format, decision.Endpoint, args, check)
+		case decision.Scheme == "mem":
+			explainf("calling the in-process composer for %s over mem://", holonName)
+			return cmdGRPCMem(format, holonName, args, check)
+		case decision.Scheme == "stdio":
+			return cmdGRPCStdio(format, "grpc+stdio://"+holonName, args, check)
 		}
+	} else {
+		explainf("transport chain found no way to reach %s (%v); falling back to an ephemeral tcp:// port", holonName, err)
 	}
 
 	binary, err := resolveHolon(holonName)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "op grpc: holon %q not found\n", holonName)
+		fmt.Fprintf(os.Stderr, "op grpc: %v\n", holonNotFoundError(holonName))
 		return 1
 	}
 
-	// Pick an ephemeral port via SDK transport
-	lis, err := transport.Listen("tcp://:0")
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "op grpc: cannot allocate port: %v\n", err)
-		return 1
+	method := args[0]
+	inputJSON := "{}"
+	if len(args) > 1 {
+		inputJSON = args[1]
 	}
-	port := fmt.Sprintf("%d", lis.Addr().(*net.TCPAddr).Port)
-	lis.Close()
 
-	cmd := exec.Command(binary, "serve", "--listen", "tcp://:"+port)
-	cmd.Stderr = os.Stderr
-	if err := cmd.Start(); err != nil {
-		fmt.Fprintf(os.Stderr, "op grpc: cannot start %s: %v\n", holonName, err)
+	explainf("launching %s on an ephemeral tcp:// port", binary)
+	result, err := explainedCall(func() (*grpcclient.CallResult, error) {
+		return callViaEphemeralTCP(context.Background(), holonName, binary, method, inputJSON, check)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op grpc: %s\n", rpcErrorMessage(err))
 		return 1
 	}
-	defer func() {
-		cmd.Process.Kill() //nolint:errcheck
-		cmd.Wait()         //nolint:errcheck
-	}()
 
-	target := fmt.Sprintf("localhost:%s", port)
-	ready := false
-	for i := 0; i < 50; i++ {
-		conn, err := net.DialTimeout("tcp", target, 100*time.Millisecond)
-		if err == nil {
-			conn.Close()
-			ready = true
-			break
+	return printRPCResult(format, method, result)
+}
+
+// callViaEphemeralTCP starts the holon binary on a free TCP port, waits for
+// it to accept connections, runs check, calls the method, and stops the
+// process.
+func callViaEphemeralTCP(ctx context.Context, holonName, binary, method, inputJSON string, check connectCheck) (*grpcclient.CallResult, error) {
+	var result *grpcclient.CallResult
+	err := withEphemeralHolon(ctx, holonName, binary, func(target string) error {
+		if err := verifyConnectedHolon(target, check); err != nil {
+			return err
 		}
-		time.Sleep(100 * time.Millisecond)
+		var err error
+		result, err = grpcclient.DialContext(ctx, target, method, inputJSON)
+		return err
+	})
+	return result, err
+}
+
+// callViaEndpoint calls a holon already listening on uri, a registered
+// tcp:// or unix:// endpoint, after running check against it.
+func callViaEndpoint(ctx context.Context, uri, method, inputJSON string, check connectCheck) (*grpcclient.CallResult, error) {
+	address, err := endpointDialAddress(uri)
+	if err != nil {
+		return nil, err
 	}
-	if !ready {
-		fmt.Fprintf(os.Stderr, "op grpc: %s did not start within 5s on port %s\n", holonName, port)
-		return 1
+	if err := verifyConnectedHolon(address, check); err != nil {
+		return nil, err
+	}
+	return grpcclient.DialContext(ctx, address, method, inputJSON)
+}
+
+// cmdGRPCEndpoint handles grpc://holon when that holon is already running:
+// the call goes to its registered endpoint instead of a fresh process.
+func cmdGRPCEndpoint(format Format, uri string, args []string, check connectCheck) int {
+	method := args[0]
+	inputJSON := "{}"
+	if len(args) > 1 {
+		inputJSON = args[1]
 	}
 
-	return cmdGRPCDirect(format, target, args)
+	result, err := explainedCall(func() (*grpcclient.CallResult, error) {
+		return callViaEndpoint(context.Background(), uri, method, inputJSON, check)
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op grpc: %s\n", rpcErrorMessage(err))
+		return 1
+	}
+	return printRPCResult(format, method, result)
 }
 
 // cmdGRPCStdio handles grpc+stdio://holon — launches the holon with
 // serve --listen stdio:// and communicates via stdin/stdout pipes.
-func cmdGRPCStdio(format Format, uri string, args []string) int {
+func cmdGRPCStdio(format Format, uri string, args []string, check connectCheck) int {
 	holonName := strings.TrimPrefix(uri, "grpc+stdio://")
 	if len(args) < 1 {
 		fmt.Fprintln(os.Stderr, "op grpc: method required")
@@ -562,7 +1069,7 @@ func cmdGRPCStdio(format Format, uri string, args []string) int {
 
 	binary, err := resolveHolon(holonName)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "op grpc: holon %q not found\n", holonName)
+		fmt.Fprintf(os.Stderr, "op grpc: %v\n", holonNotFoundError(holonName))
 		return 1
 	}
 
@@ -572,22 +1079,22 @@ func cmdGRPCStdio(format Format, uri string, args []string) int {
 		inputJSON = []byte(args[1])
 	}
 
-	result, err := callViaStdio(binary, method, inputJSON)
+	explainf("launching %s serve --listen stdio://", binary)
+	result, err := explainedCall(func() (*grpcclient.CallResult, error) {
+		return callViaStdio(context.Background(), binary, method, inputJSON, check)
+	})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "op grpc: %v\n", err)
+		fmt.Fprintf(os.Stderr, "op grpc: %s\n", rpcErrorMessage(err))
 		return 1
 	}
 
-	fmt.Println(formatRPCOutput(format, method, result))
-	return 0
+	return printRPCResult(format, method, result)
 }
 
 // cmdGRPCWebSocket handles grpc+ws://host:port[/path] and grpc+wss://...
 // Connects to an existing WebSocket gRPC server.
-func cmdGRPCWebSocket(format Format, uri string, args []string) int {
-	// Convert grpc+ws://host:port → ws://host:port
-	// Convert grpc+wss://host:port → wss://host:port
-	wsURI := strings.TrimPrefix(uri, "grpc+")
+func cmdGRPCWebSocket(format Format, uri string, args []string, check connectCheck) int {
+	wsURI := webSocketDialURI(uri)
 
 	if len(args) < 1 {
 		fmt.Fprintln(os.Stderr, "op grpc: method required")
@@ -601,27 +1108,50 @@ func cmdGRPCWebSocket(format Format, uri string, args []string) int {
 		inputJSON = args[1]
 	}
 
-	// Ensure path includes /grpc if not specified
-	if !strings.Contains(wsURI[5:], "/") { // skip "ws://" prefix
-		wsURI += "/grpc"
+	if err := verifyWebSocketHolon(wsURI, check); err != nil {
+		fmt.Fprintf(os.Stderr, "op grpc: %s\n", rpcErrorMessage(err))
+		return 1
 	}
 
-	result, err := grpcclient.DialWebSocket(wsURI, method, inputJSON)
+	result, err := explainedCall(func() (*grpcclient.CallResult, error) {
+		return grpcclient.DialWebSocket(wsURI, method, inputJSON)
+	})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "op grpc: %v\n", err)
+		fmt.Fprintf(os.Stderr, "op grpc: %s\n", rpcErrorMessage(err))
 		return 1
 	}
 
-	fmt.Println(formatRPCOutput(format, method, []byte(result.Output)))
-	return 0
+	return printRPCResult(format, method, result)
+}
+
+// webSocketDialURI turns grpc+ws://host:port[/path] into ws://host:port/path
+// and grpc+wss:// into wss://, defaulting the path to /grpc.
+func webSocketDialURI(uri string) string {
+	wsURI := strings.TrimPrefix(uri, "grpc+")
+	_, hostAndPath, _ := strings.Cut(wsURI, "://")
+	if !strings.Contains(hostAndPath, "/") {
+		wsURI += "/grpc"
+	}
+	return wsURI
 }
 
 // cmdGRPCDirect calls an RPC on an existing gRPC server at the given address.
-func cmdGRPCDirect(format Format, address string, args []string) int {
+func cmdGRPCDirect(format Format, address string, args []string, check connectCheck, tlsConf *tls.Config) int {
+	var extra []grpc.DialOption
+	if tlsConf != nil {
+		extra = append(extra, grpcclient.TLSDialOption(tlsConf))
+	}
+	if err := verifyConnectedHolon(address, check, extra...); err != nil {
+		err = grpcclient.ExplainHTTP1Error(grpcclient.ExplainTLSError(err, address), address, tlsConf)
+		fmt.Fprintf(os.Stderr, "op grpc: %s\n", rpcErrorMessage(err))
+		return 1
+	}
+
 	if len(args) == 0 {
-		methods, err := grpcclient.ListMethods(address)
+		methods, err := grpcclient.ListMethods(address, extra...)
+		err = grpcclient.ExplainHTTP1Error(grpcclient.ExplainTLSError(err, address), address, tlsConf)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "op grpc: %v\n", err)
+			fmt.Fprintf(os.Stderr, "op grpc: %s\n", rpcErrorMessage(err))
 			return 1
 		}
 		fmt.Printf("Available methods at %s:\n", address)
@@ -637,14 +1167,35 @@ func cmdGRPCDirect(format Format, address string, args []string) int {
 		inputJSON = args[1]
 	}
 
-	result, err := grpcclient.Dial(address, method, inputJSON)
+	// A client-streaming method with no request argument reads its requests
+	// from stdin, one JSON object per line.
+	emit, endStream := streamEmitter(format, method)
+	sink := grpcclient.StreamSink{Emit: emit}
+	if len(args) < 2 {
+		sink.Input = stdinInput
+	}
+	ctx := grpcclient.WithStream(context.Background(), sink)
+
+	result, err := explainedCall(func() (*grpcclient.CallResult, error) {
+		if tlsConf != nil {
+			return grpcclient.DialTLSContext(ctx, address, method, inputJSON, tlsConf)
+		}
+		return grpcclient.DialContext(ctx, address, method, inputJSON)
+	})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "op grpc: %v\n", err)
+		_ = endStream()
+		fmt.Fprintf(os.Stderr, "op grpc: %s\n", rpcErrorMessage(err))
 		return 1
 	}
+	if result.Streamed {
+		if err := endStream(); err != nil {
+			fmt.Fprintf(os.Stderr, "op grpc: write output: %v\n", err)
+			return 1
+		}
+		return 0
+	}
 
-	fmt.Println(formatRPCOutput(format, method, []byte(result.Output)))
-	return 0
+	return printRPCResult(format, method, result)
 }
 
 func discoverInPath() []string {
@@ -653,49 +1204,173 @@ func discoverInPath() []string {
 
 // --- Namespace dispatch ---
 
-// cmdHolon runs `op <holon> <command> [args...]` through the transport chain.
+// cmdHolon runs `op <holon> <command> [args...]` through the transport
+// chain, or as the holon's own command line when <command> cannot name one
+// of its methods.
 func cmdHolon(format Format, holon string, args []string) int {
-	if len(args) == 0 {
+	if !isHolonPattern(holon) && isHolonCommandLine(args) {
+		explainf("%q is not a method, so op runs %s's own command line", strings.Join(args, " "), holon)
+		return cmdDispatch(holon, args)
+	}
+	args, err := applyStdinInput(args)
+	if err == nil {
+		args, err = applyDataTemplate(args)
+	}
+	var requestID, runDir string
+	if err == nil {
+		requestID, args, err = extractRequestIDFlag(args)
+	}
+	if err == nil {
+		runDir, args, err = extractRunDirFlag(args)
+	}
+	var override *TransportOverride
+	if err == nil {
+		override, args, err = extractTransportFlag(args)
+	}
+	var bytesOut string
+	if err == nil {
+		bytesOut, args, err = extractBytesOutFlag(args)
+	}
+	bytesIn, args := extractBoolFlag(args, "--bytes-in")
+	var compress string
+	if err == nil {
+		compress, args, err = extractCompressFlag(args)
+	}
+	var calls []sessionCall
+	if err == nil {
+		calls, args, err = extractCallFlags(args)
+	}
+	dryRun, args := extractBoolFlag(args, "--dry-run")
+	releaseTemplate := func() {}
+	if err == nil {
+		args, releaseTemplate, err = extractOutputTemplateFlag(format, args)
+	}
+	if err == nil && isHolonPattern(holon) {
+		switch {
+		case runDir != "":
+			err = fmt.Errorf("--run-dir cannot be used with a holon pattern")
+		case override != nil:
+			err = fmt.Errorf("--transport cannot be used with a holon pattern")
+		case dryRun:
+			err = fmt.Errorf("--dry-run cannot be used with a holon pattern")
+		case len(calls) > 0:
+			err = fmt.Errorf("--call cannot be used with a holon pattern")
+		}
+	}
+	releaseCompressor := func() {}
+	if err == nil {
+		releaseCompressor, err = pinCompressor(compress)
+	}
+	if err != nil {
+		releaseTemplate()
+		fmt.Fprintf(os.Stderr, "op: %v\n", err)
+		return 1
+	}
+	defer releaseTemplate()
+	defer releaseCompressor()
+	defer pinBytesFiles(bytesIn, bytesOut)()
+	verbose, args := extractBoolFlag(args, "--verbose")
+	ctx := withTransportOverride(withRunDir(context.Background(), runDir), override)
+	if dryRun {
+		return printHolonDryRun(ctx, format, holon)
+	}
+	if isHolonPattern(holon) {
+		// Without --request-id, each fanned-out call carries its own ID.
+		if requestID != "" {
+			ctx = grpcclient.ContextWithRequestID(ctx, requestID)
+		}
+		return cmdHolonFanOut(ctx, format, holon, args)
+	}
+	if len(calls) > 0 && len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "op: --call cannot be combined with a command (got %q)\n", args[0])
+		return 1
+	}
+	if len(args) == 0 && len(calls) == 0 {
 		fmt.Fprintf(os.Stderr, "op: missing command for holon %q\n", holon)
 		return 1
 	}
 
+	if requestID == "" {
+		requestID = grpcclient.NewRequestID()
+	}
+	ctx = grpcclient.ContextWithRequestID(ctx, requestID)
+	if verbose {
+		fmt.Fprintf(os.Stderr, "request-id: %s\n", requestID)
+		if decision, err := transportDecision(ctx, holon); err == nil {
+			fmt.Fprintf(os.Stderr, "transport: %s (%s)\n", decision.Scheme, decision.Reason)
+		}
+	}
+
+	if len(calls) > 0 {
+		return runCallSession(ctx, format, holon, calls)
+	}
+
 	method, inputJSON, err := mapHolonCommandToRPC(args)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "op: %v\n", err)
 		return 1
 	}
+	explainf("command %q maps to method %s with input %s", args[0], method, explainInput(inputJSON))
 
-	scheme, err := selectTransport(holon)
+	result, err := callHolon(ctx, holon, method, inputJSON)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "op: %v\n", err)
+		fmt.Fprintf(os.Stderr, "op: %s\n", rpcErrorMessage(err))
+		printRequiredFieldsHint(os.Stderr, holon, method, err)
 		return 1
 	}
+	explainf("printing the result as %s", format)
+	return printRPCResult(format, method, result)
+}
+
+// callHolon invokes one RPC on a holon through the transport chain.
+func callHolon(ctx context.Context, holon, method, inputJSON string) (*grpcclient.CallResult, error) {
+	decision, err := transportDecision(ctx, holon)
+	if err != nil {
+		explainf("transport chain found no way to reach %s: %v", holon, err)
+		if _, resolveErr := holons.ResolveTarget(holon); resolveErr != nil {
+			return nil, holonNotFoundError(holon)
+		}
+		return nil, err
+	}
+	explainDecision(holon, decision)
 
-	switch scheme {
-	case "mem":
-		output, err := callViaMem(holon, method, inputJSON)
+	return explainedCall(func() (*grpcclient.CallResult, error) {
+		return callHolonVia(ctx, decision, holon, method, inputJSON)
+	})
+}
+
+// callHolonVia invokes one RPC on a holon over the transport decision chose.
+func callHolonVia(ctx context.Context, decision TransportDecision, holon, method, inputJSON string) (*grpcclient.CallResult, error) {
+	switch {
+	case decision.Scheme == "unix":
+		_, path, err := listenDialTarget(decision.Endpoint)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "op: %v\n", err)
-			return 1
+			return nil, err
 		}
-		fmt.Println(formatRPCOutput(format, method, []byte(output)))
-		return 0
+		explainf("dialing unix socket %s", path)
+		return callViaUnix(ctx, path, method, inputJSON)
+	case isRunningEndpoint(decision):
+		explainf("dialing the running instance at %s", decision.Endpoint)
+		return callViaEndpoint(ctx, decision.Endpoint, method, inputJSON, connectCheck{})
+	}
+	switch decision.Scheme {
+	case "mem":
+		explainf("calling the in-process composer for %s over mem://", holon)
+		return callViaMem(ctx, holon, method, inputJSON, connectCheck{})
 	case "stdio":
 		binary, err := resolveHolon(holon)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "op: unknown holon %q\n", holon)
-			return 1
+			return nil, holonNotFoundError(holon)
 		}
-		output, err := callViaStdio(binary, method, []byte(inputJSON))
+		explainf("launching %s serve --listen stdio://", binary)
+		return callViaStdio(ctx, binary, method, []byte(inputJSON), connectCheck{})
+	default:
+		binary, err := resolveHolon(holon)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "op: %v\n", err)
-			return 1
+			return nil, holonNotFoundError(holon)
 		}
-		fmt.Println(formatRPCOutput(format, method, output))
-		return 0
-	default:
-		return cmdGRPCTCP(format, "grpc://"+holon, []string{method, inputJSON})
+		explainf("launching %s on an ephemeral tcp:// port", binary)
+		return callViaEphemeralTCP(ctx, holon, binary, method, inputJSON, connectCheck{})
 	}
 }
 
@@ -727,6 +1402,23 @@ func mapHolonCommandToRPC(args []string) (method string, inputJSON string, err e
 			return "", "", err
 		}
 		return method, string(payload), nil
+	case "delete":
+		req, err := parseDeleteArgs(rest)
+		if err != nil {
+			return "", "", fmt.Errorf("delete: %w", err)
+		}
+		input := map[string]any{"uuid": req.GetUuid()}
+		if req.GetPurge() {
+			input["purge"] = true
+		}
+		if req.GetForce() {
+			input["force"] = true
+		}
+		payload, err := json.Marshal(input)
+		if err != nil {
+			return "", "", err
+		}
+		return method, string(payload), nil
 	default:
 		return method, "{}", nil
 	}
@@ -740,33 +1432,90 @@ func mapCommandNameToMethod(command string) string {
 		return "ListIdentities"
 	case "show":
 		return "ShowIdentity"
+	case "delete":
+		return "DeleteIdentity"
 	default:
 		return command
 	}
 }
 
+// isHolonCommandLine reports whether args are a holon's own command line
+// rather than an RPC call: they start with an invoke flag or "--", or with
+// a word that cannot name a method. Methods are capitalized, as gRPC names
+// them, qualified by their service, or one of the identity commands
+// mapCommandNameToMethod knows.
+func isHolonCommandLine(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	word := args[0]
+	name, _, _ := strings.Cut(word, "=")
+	switch name {
+	case "--env", "--cwd", "--clear-env", "--timeout", "--":
+		return true
+	}
+	if strings.HasPrefix(word, "-") || strings.ContainsAny(word, "./") || mapCommandNameToMethod(word) != word {
+		return false
+	}
+	first, _ := utf8.DecodeRuneInString(word)
+	return unicode.IsLower(first)
+}
+
 func looksLikeJSON(value string) bool {
 	trimmed := strings.TrimSpace(value)
 	return strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")
 }
 
 // cmdDispatch runs `op <holon> <command> [args...]` by finding the
-// holon binary and executing it as a subprocess.
+// holon binary and executing it as a subprocess. With OP_DAEMON set the
+// daemon runs it and streams its output back. --env, --clear-env, --cwd and
+// --timeout ahead of <command> shape the command's process either way.
 func cmdDispatch(holon string, args []string) int {
+	opts, args, err := extractInvokeFlags(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op: %v\n", err)
+		return 1
+	}
+	if code, ok := daemonInvoke(holon, args, opts); ok {
+		return code
+	}
+
 	// Try to find the holon binary by selector.
 	binary, err := resolveHolon(holon)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "op: unknown holon %q\n", holon)
+		fmt.Fprintf(os.Stderr, "op: %v\n", holonNotFoundError(holon))
 		fmt.Fprintln(os.Stderr, "Run 'op discover' to see available holons.")
 		return 1
 	}
 
-	cmd := exec.Command(binary, args...)
+	// The command stays in op's process group, the terminal's foreground
+	// one, so it can read the terminal and Ctrl-C reaches it directly; op
+	// only notes the interrupt. --timeout sends it SIGTERM, then SIGKILL
+	// once localKillGrace has passed.
+	interrupted, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx := context.Background()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Dir, cmd.Env = opts.Dir, server.InvokeEnv(opts.Env, opts.ClearEnv)
+	cmd.Cancel = func() error { return terminateProcess(cmd.Process) }
+	cmd.WaitDelay = localKillGrace
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			fmt.Fprintf(os.Stderr, "op: %s timed out after %s\n", holon, opts.Timeout)
+			return exitTimedOut
+		}
+		if interrupted.Err() != nil {
+			return exitInterrupted
+		}
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			return exitErr.ExitCode()
 		}
@@ -776,6 +1525,19 @@ func cmdDispatch(holon string, args []string) int {
 	return 0
 }
 
+// localKillGrace is how long a command op runs itself has between SIGTERM
+// and SIGKILL once its --timeout has passed.
+const localKillGrace = 2 * time.Second
+
+// terminateProcess asks p to stop: SIGTERM, or a kill where there are no
+// signals.
+func terminateProcess(p *os.Process) error {
+	if runtime.GOOS == "windows" {
+		return p.Kill()
+	}
+	return p.Signal(syscall.SIGTERM)
+}
+
 // resolveHolon finds a holon binary by selector.
 func resolveHolon(name string) (string, error) {
 	return holons.ResolveBinary(name)
@@ -814,7 +1576,12 @@ func commandForInstalledArtifact(path string, target *holons.Target, listenURI s
 }
 
 func parseRunArgs(args []string) (string, runOptions, error) {
-	opts := runOptions{ListenURI: "stdio://"}
+	opts := runOptions{ListenURI: defaultRunListen, WaitTimeout: defaultRunWaitTimeout}
+	// OP_LISTEN replaces the default only; it is not an explicit --listen, so
+	// composites, which take no listen address, ignore it.
+	if value, ok := lookupNonEmptyEnv(envListen); ok {
+		opts.ListenURI = value
+	}
 	var positional []string
 
 	for i := 0; i < len(args); i++ {
@@ -840,6 +1607,36 @@ func parseRunArgs(args []string) (string, runOptions, error) {
 			}
 			opts.Mode = args[i+1]
 			i++
+		case args[i] == "--wait":
+			opts.Wait = true
+		case args[i] == "--wait-timeout":
+			if i+1 >= len(args) {
+				return "", opts, fmt.Errorf("--wait-timeout requires a value")
+			}
+			timeout, err := time.ParseDuration(args[i+1])
+			if err != nil || timeout <= 0 {
+				return "", opts, fmt.Errorf("invalid --wait-timeout %q", args[i+1])
+			}
+			opts.Wait = true
+			opts.WaitTimeout = timeout
+			i++
+		case args[i] == "--run-dir":
+			if i+1 >= len(args) {
+				return "", opts, fmt.Errorf("--run-dir requires a value")
+			}
+			dir, err := validRunDir(args[i+1])
+			if err != nil {
+				return "", opts, err
+			}
+			opts.RunDir = dir
+			i++
+		case args[i] == "--addr-file":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return "", opts, fmt.Errorf("--addr-file requires a path")
+			}
+			opts.Wait = true
+			opts.AddrFile = args[i+1]
+			i++
 		case strings.HasPrefix(args[i], "--"):
 			return "", opts, fmt.Errorf("unknown flag %q", args[i])
 		default:
@@ -866,6 +1663,9 @@ func parseRunArgs(args []string) (string, runOptions, error) {
 	if holonName == "" {
 		return "", opts, fmt.Errorf("requires <holon> [flags]")
 	}
+	if opts.Wait && !isNetworkListenURI(opts.ListenURI) {
+		return "", opts, fmt.Errorf("--wait requires a tcp:// or unix:// --listen URI")
+	}
 
 	return holonName, opts, nil
 }
@@ -1216,10 +2016,13 @@ func xmlPlistEscape(value string) string {
 	return plistEscaper.Replace(value)
 }
 
-func runForeground(cmd *exec.Cmd) error {
+func runForeground(cmd *exec.Cmd, started func()) error {
 	if err := cmd.Start(); err != nil {
 		return err
 	}
+	if started != nil {
+		started()
+	}
 
 	waitCh := make(chan error, 1)
 	go func() {
@@ -1278,43 +2081,122 @@ func flagOrDefault(args []string, key, defaultVal string) string {
 }
 
 func parseGlobalOptions(args []string) (Format, bool, []string, error) {
-	format := FormatText
-	quiet := false
+	opts, rest, err := parseGlobalFlags(args)
+	return opts.Format, opts.Quiet, rest, err
+}
+
+// globalOptions are the flags accepted before the command.
+type globalOptions struct {
+	Format Format
+	Quiet  bool
+	// ReflectProto is empty unless --reflect-proto was given.
+	ReflectProto grpcclient.ReflectProtocol
+	// MaxInputSize is zero unless --max-input-size was given.
+	MaxInputSize int
+	// Timeout is zero unless --timeout was given.
+	Timeout time.Duration
+	// Explain narrates every dispatch decision on stderr.
+	Explain bool
+	// Given lists the global flags set on the command line, by long name,
+	// so op env can tell a flag from a default.
+	Given []string
+}
+
+func parseGlobalFlags(args []string) (globalOptions, []string, error) {
+	opts := globalOptions{Format: FormatText}
 	i := 0
 	for i < len(args) {
+		name, _, _ := strings.Cut(args[i], "=")
+		switch name {
+		case "-q":
+			name = "--quiet"
+		case "-f":
+			name = "--format"
+		}
 		switch {
 		case args[i] == "--quiet" || args[i] == "-q":
-			quiet = true
+			opts.Quiet = true
+			i++
+		case args[i] == "--explain":
+			opts.Explain = true
 			i++
 		case args[i] == "--format" || args[i] == "-f":
 			if i+1 >= len(args) {
-				return "", false, nil, fmt.Errorf("%s requires a value (text or json)", args[i])
+				return globalOptions{}, nil, fmt.Errorf("%s requires a value (text or json)", args[i])
 			}
 			parsed, err := parseFormat(args[i+1])
 			if err != nil {
-				return "", false, nil, err
+				return globalOptions{}, nil, err
 			}
-			format = parsed
+			opts.Format = parsed
 			i += 2
 		case strings.HasPrefix(args[i], "--format="):
 			parsed, err := parseFormat(strings.TrimPrefix(args[i], "--format="))
 			if err != nil {
-				return "", false, nil, err
+				return globalOptions{}, nil, err
 			}
-			format = parsed
+			opts.Format = parsed
 			i++
 		case strings.HasPrefix(args[i], "-f="):
 			parsed, err := parseFormat(strings.TrimPrefix(args[i], "-f="))
 			if err != nil {
-				return "", false, nil, err
+				return globalOptions{}, nil, err
 			}
-			format = parsed
+			opts.Format = parsed
 			i++
+		case args[i] == "--reflect-proto" || strings.HasPrefix(args[i], "--reflect-proto="):
+			value, hasValue := strings.CutPrefix(args[i], "--reflect-proto=")
+			step := 1
+			if !hasValue {
+				if i+1 >= len(args) {
+					return globalOptions{}, nil, fmt.Errorf("--reflect-proto requires a value (v1, v1alpha or auto)")
+				}
+				value, step = args[i+1], 2
+			}
+			parsed, err := grpcclient.ParseReflectProtocol(value)
+			if err != nil {
+				return globalOptions{}, nil, fmt.Errorf("--reflect-proto: %w", err)
+			}
+			opts.ReflectProto = parsed
+			i += step
+		case args[i] == "--max-input-size" || strings.HasPrefix(args[i], "--max-input-size="):
+			value, hasValue := strings.CutPrefix(args[i], "--max-input-size=")
+			step := 1
+			if !hasValue {
+				if i+1 >= len(args) {
+					return globalOptions{}, nil, fmt.Errorf("--max-input-size requires a size, e.g. 1048576 or 8MiB")
+				}
+				value, step = args[i+1], 2
+			}
+			parsed, err := parseByteSize(value)
+			if err != nil {
+				return globalOptions{}, nil, fmt.Errorf("--max-input-size: %w", err)
+			}
+			opts.MaxInputSize = parsed
+			i += step
+		case args[i] == "--timeout" || strings.HasPrefix(args[i], "--timeout="):
+			value, hasValue := strings.CutPrefix(args[i], "--timeout=")
+			step := 1
+			if !hasValue {
+				if i+1 >= len(args) {
+					return globalOptions{}, nil, fmt.Errorf("--timeout requires a duration, e.g. 30s or 2m")
+				}
+				value, step = args[i+1], 2
+			}
+			parsed, err := time.ParseDuration(strings.TrimSpace(value))
+			if err != nil || parsed <= 0 {
+				return globalOptions{}, nil, fmt.Errorf("--timeout: invalid duration %q (use e.g. 30s or 2m)", value)
+			}
+			opts.Timeout = parsed
+			i += step
 		default:
-			return format, quiet, args[i:], nil
+			return opts, args[i:], nil
+		}
+		if !slices.Contains(opts.Given, name) {
+			opts.Given = append(opts.Given, name)
 		}
 	}
-	return format, quiet, nil, nil
+	return opts, nil, nil
 }
 
 func parseGlobalFormat(args []string) (Format, []string, error) {
@@ -1326,9 +2208,17 @@ func parseFormat(value string) (Format, error) {
 	switch Format(strings.ToLower(strings.TrimSpace(value))) {
 	case FormatText:
 		return FormatText, nil
+	case FormatWide:
+		return FormatWide, nil
 	case FormatJSON:
 		return FormatJSON, nil
+	case FormatJSONStream:
+		return FormatJSONStream, nil
+	case FormatProtobin:
+		return FormatProtobin, nil
+	case FormatProtobinDelimited:
+		return FormatProtobinDelimited, nil
 	default:
-		return "", fmt.Errorf("invalid --format %q (supported: text, json)", value)
+		return "", fmt.Errorf("invalid --format %q (supported: text, wide, json, json-stream, protobin, protobin-delimited)", value)
 	}
 }
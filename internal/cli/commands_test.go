@@ -9,12 +9,18 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
 	"github.com/organic-programming/grace-op/internal/holons"
 	"github.com/organic-programming/grace-op/internal/identity"
 	opmod "github.com/organic-programming/grace-op/internal/mod"
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+
+	"google.golang.org/protobuf/proto"
 )
 
 func TestVersionCommand(t *testing.T) {
@@ -104,6 +110,59 @@ func TestRunNativeShowCommand(t *testing.T) {
 	}
 }
 
+func TestRunNativeListProtobinFormat(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "who", givenName: "who", familyName: "Holon", lang: "go"})
+
+	output := captureStdout(t, func() {
+		code := Run([]string{"--format", "protobin", "list"}, "0.1.0-test")
+		if code != 0 {
+			t.Fatalf("list --format protobin returned %d, want 0", code)
+		}
+	})
+
+	var resp opv1.ListIdentitiesResponse
+	if err := proto.Unmarshal([]byte(output), &resp); err != nil {
+		t.Fatalf("protobin output does not decode: %v", err)
+	}
+	if len(resp.GetEntries()) == 0 || resp.GetEntries()[0].GetIdentity().GetGivenName() != "who" {
+		t.Fatalf("decoded entries = %v", resp.GetEntries())
+	}
+}
+
+func TestRunListMaskLeavesOtherFieldsEmpty(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "alpha", givenName: "Alpha", familyName: "Holon", lang: "go"})
+
+	output := captureStdout(t, func() {
+		if code := Run([]string{"--format", "protobin", "list", "--mask", "uuid,givenName"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("list --mask returned %d, want 0", code)
+		}
+	})
+	var resp opv1.ListIdentitiesResponse
+	if err := proto.Unmarshal([]byte(output), &resp); err != nil {
+		t.Fatalf("protobin output does not decode: %v", err)
+	}
+	if len(resp.GetEntries()) != 1 {
+		t.Fatalf("entries = %v", resp.GetEntries())
+	}
+	id := resp.GetEntries()[0].GetIdentity()
+	if id.GetUuid() != "transport-test-alpha" || id.GetGivenName() != "Alpha" || id.GetFamilyName() != "" || id.GetLang() != "" {
+		t.Fatalf("masked identity = %v, want only uuid and given name", id)
+	}
+
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"show", "transport-test-alpha", "--mask", "nickname"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("show with an unknown mask field returned %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, `no field "nickname"`) {
+		t.Fatalf("stderr = %q", stderr)
+	}
+}
+
 func TestRunNativeNewCommandJSON(t *testing.T) {
 	root := t.TempDir()
 	chdirForTest(t, root)
@@ -138,6 +197,86 @@ func TestRunNativeNewCommandJSON(t *testing.T) {
 	}
 }
 
+func TestRunNativeNewBatchReportsPerRowFailures(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+
+	batch := filepath.Join(root, "holons.csv")
+	data := "given_name,family_name,motto,composer,clade\nAlpha,Builder,Builds.,test,deterministic/io_bound\nBeta,Broken,,test,deterministic/io_bound\n"
+	if err := os.WriteFile(batch, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := captureStdout(t, func() {
+		code := Run([]string{"--quiet", "new", "--batch", batch}, "0.1.0-test")
+		if code != 1 {
+			t.Fatalf("new --batch returned %d, want 1", code)
+		}
+	})
+
+	if _, err := os.Stat(filepath.Join(root, "holons", "alpha-builder", identity.ManifestFileName)); err != nil {
+		t.Fatalf("row 1 manifest missing: %v", err)
+	}
+	for _, expected := range []string{"row 1: created Alpha Builder", "row 2: error: motto is required", "1 created, 1 failed"} {
+		if !strings.Contains(output, expected) {
+			t.Fatalf("batch output missing %q: %q", expected, output)
+		}
+	}
+}
+
+func TestRunNativeNewBatchDryRunWritesNothing(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+
+	batch := filepath.Join(root, "holons.json")
+	data := `[{"given_name":"Alpha","family_name":"Builder","motto":"Builds.","composer":"test","clade":"deterministic/io_bound"}]`
+	if err := os.WriteFile(batch, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := captureStdout(t, func() {
+		code := Run([]string{"--quiet", "new", "--batch", batch, "--dry-run"}, "0.1.0-test")
+		if code != 0 {
+			t.Fatalf("new --batch --dry-run returned %d, want 0", code)
+		}
+	})
+
+	if !strings.Contains(output, "row 1: would create Alpha Builder") || !strings.Contains(output, "1 valid, 0 invalid") {
+		t.Fatalf("dry-run output = %q", output)
+	}
+	if _, err := os.Stat(filepath.Join(root, "holons")); !os.IsNotExist(err) {
+		t.Fatalf("dry run created holons directory: %v", err)
+	}
+}
+
+func TestRunPlanCommandListsCreates(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+
+	desired := filepath.Join(root, "desired", "alpha")
+	if err := os.MkdirAll(desired, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := "schema: holon/v0\nuuid: \"plan-alpha\"\ngiven_name: \"Alpha\"\nfamily_name: \"Builder\"\nmotto: \"Builds.\"\ncomposer: \"test\"\nclade: \"deterministic/pure\"\n"
+	if err := os.WriteFile(filepath.Join(desired, identity.ManifestFileName), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := captureStdout(t, func() {
+		code := Run([]string{"plan", "desired"}, "0.1.0-test")
+		if code != 0 {
+			t.Fatalf("plan returned %d, want 0", code)
+		}
+	})
+
+	if !strings.Contains(output, "+ create Alpha Builder") || !strings.Contains(output, "Plan: 1 to create, 0 to update, 0 to delete.") {
+		t.Fatalf("plan output = %q", output)
+	}
+	if _, err := os.Stat(filepath.Join(root, "holons")); !os.IsNotExist(err) {
+		t.Fatalf("plan wrote to disk: %v", err)
+	}
+}
+
 func TestRunNewListTemplates(t *testing.T) {
 	root := t.TempDir()
 	chdirForTest(t, root)
@@ -258,6 +397,17 @@ func TestMapHolonCommandToRPC(t *testing.T) {
 			args:    []string{"show"},
 			wantErr: true,
 		},
+		{
+			name:       "delete with purge",
+			args:       []string{"delete", "abc123", "--purge"},
+			wantMethod: "DeleteIdentity",
+			wantInput:  `{"purge":true,"uuid":"abc123"}`,
+		},
+		{
+			name:    "delete missing uuid",
+			args:    []string{"delete", "--force"},
+			wantErr: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -641,6 +791,255 @@ func TestDiscoverCommandJSONFormat(t *testing.T) {
 	}
 }
 
+func TestDiscoverSkipsUnparseableManifestWithWarning(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedTransportHolon(t, root, transportHolonSeed{
+		dirName:    "who",
+		binaryName: "who",
+		givenName:  "who",
+		familyName: "Holon",
+		aliases:    []string{"who"},
+		lang:       "go",
+	})
+	broken := filepath.Join(root, "holons", "broken")
+	if err := os.MkdirAll(broken, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(broken, "holon.yaml"), []byte("given_name: [unterminated\n\t::: garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var code int
+	stdout, stderr := captureOutput(t, func() {
+		code = Run([]string{"discover"}, "0.1.0-test")
+	})
+	if code != 0 {
+		t.Fatalf("discover returned %d, want 0; stderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "who Holon") {
+		t.Fatalf("valid holon should still be listed: %q", stdout)
+	}
+	if !strings.Contains(stderr, "warning: skipped holons/broken/holon.yaml") {
+		t.Fatalf("stderr should name the invalid manifest: %q", stderr)
+	}
+
+	stdout, _ = captureOutput(t, func() {
+		code = Run([]string{"--format", "json", "discover", "--strict"}, "0.1.0-test")
+	})
+	if code != 1 {
+		t.Fatalf("discover --strict returned %d, want 1", code)
+	}
+	var payload discoverOutput
+	if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+		t.Fatalf("unmarshal %q: %v", stdout, err)
+	}
+	if len(payload.Entries) != 1 || len(payload.Invalid) != 1 || payload.Invalid[0].Path != "holons/broken/holon.yaml" {
+		t.Fatalf("payload = %+v", payload)
+	}
+}
+
+func TestDiscoverIncludeBareListsHolonsWithoutManifest(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedTransportHolon(t, root, transportHolonSeed{
+		dirName:    "who",
+		binaryName: "who",
+		givenName:  "who",
+		familyName: "Holon",
+		aliases:    []string{"who"},
+		lang:       "go",
+	})
+	draft := filepath.Join(root, "holons", "draft", ".op", "build", "bin")
+	notes := filepath.Join(root, "holons", "notes")
+	for _, dir := range []string{draft, notes} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(draft, "draft"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// Not executable, so not a holon in progress.
+	if err := os.WriteFile(filepath.Join(notes, "notes"), []byte("todo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	discover := func(args ...string) discoverOutput {
+		t.Helper()
+		var code int
+		stdout := captureStdout(t, func() {
+			code = Run(append([]string{"--format", "json", "discover"}, args...), "0.1.0-test")
+		})
+		if code != 0 {
+			t.Fatalf("discover %v returned %d, want 0", args, code)
+		}
+		var payload discoverOutput
+		if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+			t.Fatalf("unmarshal %q: %v", stdout, err)
+		}
+		return payload
+	}
+
+	if payload := discover(); len(payload.Entries) != 1 {
+		t.Fatalf("bare holons should be opt-in: %+v", payload.Entries)
+	}
+
+	payload := discover("--include-bare")
+	if len(payload.Entries) != 2 {
+		t.Fatalf("entries = %+v, want who and draft", payload.Entries)
+	}
+	bare := payload.Entries[1]
+	if bare.Slug != "draft" || bare.Status != "unknown" || bare.RelativePath != "holons/draft" || bare.Binary != "holons/draft/.op/build/bin/draft" {
+		t.Fatalf("bare entry = %+v", bare)
+	}
+}
+
+func TestDiscoverCommandFiltersByBornDate(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedTransportHolon(t, root, transportHolonSeed{
+		dirName:    "who",
+		givenName:  "who",
+		familyName: "Holon",
+		lang:       "go",
+	})
+
+	output := captureStdout(t, func() {
+		code := Run([]string{"discover", "--since", "2026-02-01", "--until=2026-02-20"}, "0.1.0-test")
+		if code != 0 {
+			t.Fatalf("discover --since/--until returned %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, "who-holon") {
+		t.Fatalf("discover in window missing holon: %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		code := Run([]string{"discover", "--since", "2026-03-01"}, "0.1.0-test")
+		if code != 0 {
+			t.Fatalf("discover --since returned %d, want 0", code)
+		}
+	})
+	if strings.Contains(output, "who-holon") {
+		t.Fatalf("discover --since kept holon born earlier: %q", output)
+	}
+
+	if code := Run([]string{"discover", "--since", "last-week"}, "0.1.0-test"); code != 1 {
+		t.Fatalf("discover with malformed --since returned %d, want 1", code)
+	}
+}
+
+func TestDiscoverCommandFiltersByIdentity(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "who", givenName: "who", familyName: "Holon", lang: "go"})
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "rob", givenName: "rob", familyName: "Holon", lang: "rust"})
+
+	slugs := func(args ...string) []string {
+		t.Helper()
+		var code int
+		stdout := captureStdout(t, func() {
+			code = Run(append([]string{"--format", "json", "discover"}, args...), "0.1.0-test")
+		})
+		if code != 0 {
+			t.Fatalf("discover %v returned %d, want 0", args, code)
+		}
+		var payload discoverOutput
+		if err := json.Unmarshal([]byte(stdout), &payload); err != nil {
+			t.Fatalf("unmarshal %q: %v", stdout, err)
+		}
+		var got []string
+		for _, entry := range payload.Entries {
+			got = append(got, entry.Slug)
+		}
+		slices.Sort(got)
+		return got
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"--lang", "Go"}, []string{"who-holon"}},
+		{[]string{"--lang", "go", "--lang=rust"}, []string{"rob-holon", "who-holon"}},
+		{[]string{"--lang", "go,rust", "--status", "draft", "--clade", "deterministic/pure"}, []string{"rob-holon", "who-holon"}},
+		{[]string{"--lang", "rust", "--status", "stable"}, nil},
+	} {
+		if got := slugs(tc.args...); !slices.Equal(got, tc.want) {
+			t.Fatalf("discover %v = %v, want %v", tc.args, got, tc.want)
+		}
+	}
+
+	output := captureStdout(t, func() {
+		if code := Run([]string{"discover", "--status", "dead"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("discover with no match returned %d, want 0", code)
+		}
+	})
+	if !strings.Contains(output, "No holons match the filters.") {
+		t.Fatalf("discover with no match printed %q", output)
+	}
+	if code := Run([]string{"discover", "--lang"}, "0.1.0-test"); code != 1 {
+		t.Fatalf("discover --lang without a value returned %d, want 1", code)
+	}
+}
+
+func TestDiscoverFilterHandlesMissingBorn(t *testing.T) {
+	filter, err := parseDiscoverArgs([]string{"--since", "2026-01-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := []discoverEntry{{Slug: "dated", Born: "2026-02-12"}, {Slug: "unborn"}, {Slug: "garbled", Born: "someday"}}
+
+	if got := filter.apply(entries); len(got) != 1 || got[0].Slug != "dated" {
+		t.Fatalf("filtered = %+v, want only dated", got)
+	}
+	filter.IncludeUnborn = true
+	if got := filter.apply(entries); len(got) != 3 {
+		t.Fatalf("filtered with --include-unborn = %+v, want all entries", got)
+	}
+}
+
+func TestExtractDiscoverScanFlags(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	for _, dir := range []string{"services", "tools"} {
+		if err := os.Mkdir(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	scan, rest, err := extractDiscoverScanFlags([]string{"--path", "services", "--lang", "go", "--path=tools", "--max-depth=2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(scan.Roots, []string{"services", "tools"}) || scan.MaxDepth != 2 {
+		t.Fatalf("scan = %+v, want both roots and max depth 2", scan)
+	}
+	if !slices.Equal(rest, []string{"--lang", "go"}) {
+		t.Fatalf("remaining = %q, want the filter flags only", rest)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--path"}, "--path requires a value"},
+		{[]string{"--max-depth"}, "--max-depth requires a value"},
+		{[]string{"--max-depth", "0"}, `invalid --max-depth "0"`},
+		{[]string{"--max-depth=deep"}, `invalid --max-depth "deep"`},
+		{[]string{"--path", "missing"}, "--path missing:"},
+		{[]string{"--path", "notes.txt"}, "--path notes.txt: not a directory"},
+	} {
+		if _, _, err := extractDiscoverScanFlags(tc.args); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("extractDiscoverScanFlags(%q) error = %v, want %q", tc.args, err, tc.want)
+		}
+	}
+}
+
 func TestDiscoverCommandIncludesCachedAndInstalledHolons(t *testing.T) {
 	root := t.TempDir()
 	chdirForTest(t, root)
@@ -802,6 +1201,60 @@ func TestEnvCommandJSONFormat(t *testing.T) {
 	}
 }
 
+func TestEnvCommandReportsSettingSources(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	t.Setenv("OPPATH", filepath.Join(root, ".runtime"))
+	t.Setenv("OPBIN", "")
+	t.Setenv(envTransport, "stdio")
+	t.Setenv(envReflectProto, "v1alpha")
+	if err := os.WriteFile(holonConfigFile, []byte("timeouts:\n  Infer: 2m\ntransports:\n  who: grpc://127.0.0.1:9091\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "atlas", givenName: "Atlas", familyName: "Holon", aliases: []string{"maps"}, lang: "go"})
+
+	output := captureStdout(t, func() {
+		if code := Run([]string{"--format", "json", "--reflect-proto", "v1", "env"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("env returned %d, want 0", code)
+		}
+	})
+	var payload envOutput
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("env json output is invalid: %v\noutput=%s", err, output)
+	}
+	got := make(map[string]envSetting)
+	for _, setting := range payload.Settings {
+		got[setting.Name] = setting
+	}
+	for name, want := range map[string]envSetting{
+		"oppath":         {Value: filepath.Join(root, ".runtime"), Source: sourceEnv, Origin: "OPPATH"},
+		"opbin":          {Value: filepath.Join(root, ".runtime", "bin"), Source: sourceDefault},
+		"format":         {Value: "json", Source: sourceFlag, Origin: "--format"},
+		"reflect-proto":  {Value: "v1", Source: sourceFlag, Origin: "--reflect-proto"},
+		"transport":      {Value: "stdio", Source: sourceEnv, Origin: envTransport},
+		"daemon":         {Value: daemonModeOff, Source: sourceDefault},
+		"timeout":        {Value: grpcclient.DefaultTimeout.String(), Source: sourceDefault},
+		"serve.listen":   {Value: defaultServeListen, Source: sourceDefault},
+		"run.listen":     {Value: defaultRunListen, Source: sourceDefault},
+		"aliases.maps":   {Value: "atlas-holon", Source: sourceManifest},
+		"timeouts.Infer": {Value: "2m0s", Source: sourceProjectConfig},
+		"transports.who": {Value: "tcp://127.0.0.1:9091", Source: sourceProjectConfig},
+	} {
+		setting := got[name]
+		if setting.Value != want.Value || setting.Source != want.Source || (want.Origin != "" && setting.Origin != want.Origin) {
+			t.Fatalf("%s = %+v, want %+v", name, setting, want)
+		}
+	}
+	if !strings.HasSuffix(got["timeouts.Infer"].Origin, holonConfigFile) {
+		t.Fatalf("timeouts.Infer origin = %q, want the .holonconfig path", got["timeouts.Infer"].Origin)
+	}
+
+	output = captureStdout(t, func() { Run([]string{"env"}, "0.1.0-test") })
+	if !strings.Contains(output, "SETTING") || !strings.Contains(output, "env (OP_TRANSPORT)") {
+		t.Fatalf("env text output = %q, want a settings table with sources", output)
+	}
+}
+
 func TestInstallCommand(t *testing.T) {
 	if _, err := exec.LookPath("go"); err != nil {
 		t.Skip("go command not available")
@@ -1741,6 +2194,68 @@ func TestRunCommandNoBuildFailsWhenArtifactMissing(t *testing.T) {
 	}
 }
 
+func TestRunCommandJSONReportsFailure(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+
+	dir := filepath.Join(root, "demo")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "holon.yaml"), []byte("schema: holon/v0\nkind: native\nbuild:\n  runner: go-module\nrequires:\n  commands: [go]\n  files: [go.mod]\nartifacts:\n  binary: demo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := captureStdout(t, func() {
+		code := Run([]string{"--format", "json", "run", "--no-build", "demo"}, "0.1.0-test")
+		if code != 1 {
+			t.Fatalf("run returned %d, want 1", code)
+		}
+	})
+
+	var report runReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("unmarshal %q: %v", stdout, err)
+	}
+	if report.Holon != "demo" || report.Status != runStatusFailed || !strings.Contains(report.Error, "artifact missing") {
+		t.Fatalf("report = %+v", report)
+	}
+}
+
+func TestRunCommandJSONReportsExit(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not available")
+	}
+
+	root := t.TempDir()
+	chdirForTest(t, root)
+
+	dir := filepath.Join(root, "demo")
+	writeRunServiceFixture(t, dir, "demo")
+	buildRunBinary(t, dir, filepath.Join(dir, ".op", "build", "bin", "demo"), "./cmd/demo")
+
+	stdout, stderr := captureOutput(t, func() {
+		code := Run([]string{"--format", "json", "run", "demo", "--listen", "tcp://127.0.0.1:9099"}, "0.1.0-test")
+		if code != 0 {
+			t.Fatalf("run returned %d, want 0", code)
+		}
+	})
+
+	var report runReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("stdout is not a single JSON report: %q: %v", stdout, err)
+	}
+	if report.Status != runStatusExited || report.ExitCode == nil || *report.ExitCode != 0 || report.PID == 0 {
+		t.Fatalf("report = %+v", report)
+	}
+	if report.Listen != "tcp://127.0.0.1:9099" {
+		t.Fatalf("listen = %q", report.Listen)
+	}
+	if !strings.Contains(stderr, "serve --listen tcp://127.0.0.1:9099") {
+		t.Fatalf("child output should move to stderr in JSON mode: %q", stderr)
+	}
+}
+
 func TestRunCommandUsesInstalledBinaryWithoutSource(t *testing.T) {
 	if _, err := exec.LookPath("go"); err != nil {
 		t.Skip("go command not available")
@@ -1782,6 +2297,23 @@ func TestRunCommandUsesInstalledBinaryWithoutSource(t *testing.T) {
 	}
 }
 
+func TestParseRunArgsWait(t *testing.T) {
+	_, opts, err := parseRunArgs([]string{"demo", "--listen", "tcp://127.0.0.1:9090", "--wait-timeout", "3s"})
+	if err != nil {
+		t.Fatalf("parseRunArgs returned error: %v", err)
+	}
+	if !opts.Wait || opts.WaitTimeout != 3*time.Second {
+		t.Fatalf("wait options = (%v, %s), want (true, 3s)", opts.Wait, opts.WaitTimeout)
+	}
+
+	if _, _, err := parseRunArgs([]string{"demo", "--wait"}); err == nil || !strings.Contains(err.Error(), "--wait requires") {
+		t.Fatalf("parseRunArgs --wait with stdio listen error = %v", err)
+	}
+	if _, _, err := parseRunArgs([]string{"demo", "--listen", "tcp://:0", "--wait-timeout", "soon"}); err == nil {
+		t.Fatal("parseRunArgs accepted invalid --wait-timeout")
+	}
+}
+
 func TestGRPCURIWithoutPortRequiresMethodForEphemeralHolon(t *testing.T) {
 	stderr := captureStderr(t, func() {
 		code := Run([]string{"grpc://rob-go"}, "0.1.0-test")
@@ -1923,6 +2455,62 @@ func TestParseGlobalOptions(t *testing.T) {
 	}
 }
 
+func TestParseGlobalFlagsReflectProto(t *testing.T) {
+	opts, args, err := parseGlobalFlags([]string{"--reflect-proto", "v1alpha", "-q", "grpc://127.0.0.1:9090"})
+	if err != nil {
+		t.Fatalf("parseGlobalFlags returned error: %v", err)
+	}
+	if opts.ReflectProto != grpcclient.ReflectV1Alpha || !opts.Quiet || len(args) != 1 {
+		t.Fatalf("opts = %+v, args = %#v", opts, args)
+	}
+
+	opts, _, err = parseGlobalFlags([]string{"--reflect-proto=v1", "list"})
+	if err != nil || opts.ReflectProto != grpcclient.ReflectV1 {
+		t.Fatalf("--reflect-proto=v1: opts = %+v, err = %v", opts, err)
+	}
+	if _, _, err := parseGlobalFlags([]string{"--reflect-proto", "v3", "list"}); err == nil {
+		t.Fatal("--reflect-proto v3 should be rejected")
+	}
+
+	t.Setenv(envReflectProto, "sometimes")
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"list"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("an invalid %s should fail, got %d", envReflectProto, code)
+		}
+	})
+	if !strings.Contains(stderr, envReflectProto) {
+		t.Fatalf("stderr = %q, want it to name %s", stderr, envReflectProto)
+	}
+}
+
+func TestMaxInputSizeRefusesLargeRequests(t *testing.T) {
+	for value, want := range map[string]int{"1024": 1024, "64KiB": 64 << 10, "2M": 2 << 20} {
+		opts, _, err := parseGlobalFlags([]string{"--max-input-size", value, "list"})
+		if err != nil || opts.MaxInputSize != want {
+			t.Fatalf("--max-input-size %s: opts = %+v, err = %v", value, opts, err)
+		}
+	}
+	for _, value := range []string{"0", "-1", "lots", "9KB"} {
+		if _, _, err := parseGlobalFlags([]string{"--max-input-size=" + value, "list"}); err == nil {
+			t.Fatalf("--max-input-size=%s should be rejected", value)
+		}
+	}
+
+	address := startSlowHealthServer(t, 0)
+	stderr := captureStderr(t, func() {
+		code := Run([]string{"--max-input-size", "16", "grpc://" + address, "Check", `{"service":"a-rather-long-service-name"}`}, "0.1.0-test")
+		if code != 1 {
+			t.Fatalf("oversized input returned %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "over the 16-byte limit") {
+		t.Fatalf("stderr = %q", stderr)
+	}
+	if got := grpcclient.MaxInputSize(); got != grpcclient.DefaultMaxInputSize {
+		t.Fatalf("limit after the run = %d, want the default restored", got)
+	}
+}
+
 func captureStdout(t *testing.T, fn func()) string {
 	t.Helper()
 
@@ -2091,3 +2679,125 @@ func executableSuffixForRunTest() string {
 	}
 	return ""
 }
+
+func TestPinFromBinaryRecordsDetectedBuild(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	t.Setenv("OPPATH", filepath.Join(root, ".oppath"))
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "pinme", givenName: "Pin", familyName: "Me", lang: "go"})
+
+	binary := filepath.Join(root, "pinme")
+	script := "#!/bin/sh\necho '{\"version\":\"0.4.0\",\"commit\":\"feedbee\",\"os\":\"darwin\",\"arch\":\"amd64\"}'\n"
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := captureStdout(t, func() {
+		code := Run([]string{"pin", "transport-test-pinme", "--from-binary", binary, "--arch", "arm64"}, "0.1.0-test")
+		if code != 0 {
+			t.Fatalf("op pin returned %d, want 0", code)
+		}
+	})
+	for _, want := range []string{"Detected version 0.4.0, commit feedbee, darwin/amd64", "Pinned pin-me", "darwin/arm64"} {
+		if !strings.Contains(stdout, want) {
+			t.Fatalf("output missing %q:\n%s", want, stdout)
+		}
+	}
+
+	id, _, err := identity.ReadHolonYAML(filepath.Join(root, "holons", "pinme", identity.ManifestFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := identity.VersionPin{Version: "0.4.0", Commit: "feedbee", OS: "darwin", Arch: "arm64"}
+	if id.Pin == nil || *id.Pin != want {
+		t.Fatalf("pin = %+v, want %+v", id.Pin, want)
+	}
+
+	_ = captureStderr(t, func() {
+		if code := Run([]string{"pin", "transport-test-pinme"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("op pin without fields returned %d, want 1", code)
+		}
+	})
+}
+
+func TestReleasesListsPinsAndFiltersUnpinned(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	t.Setenv("OPPATH", filepath.Join(root, ".oppath"))
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "shipped", givenName: "Shipped", familyName: "Holon", lang: "go"})
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "drifting", givenName: "Drifting", familyName: "Holon", lang: "go"})
+
+	_ = captureStdout(t, func() {
+		args := []string{"pin", "transport-test-shipped", "--version", "1.0.0", "--tag", "v1.0.0", "--commit", "0123456789abcdef0123", "--os", "linux", "--arch", "amd64"}
+		if code := Run(args, "0.1.0-test"); code != 0 {
+			t.Fatalf("op pin returned %d, want 0", code)
+		}
+	})
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"releases"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("op releases returned %d, want 0", code)
+		}
+	})
+	for _, want := range []string{"shipped-holon", "1.0.0", "v1.0.0", "0123456789ab ", "linux/amd64", "drifting-holon", "UNPINNED", "1 of 2 holons have no pinned version."} {
+		if !strings.Contains(stdout, want) {
+			t.Fatalf("op releases output missing %q:\n%s", want, stdout)
+		}
+	}
+
+	stdout = captureStdout(t, func() {
+		if code := Run([]string{"--format", "json", "releases", "--unpinned-only"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("op releases --unpinned-only returned %d, want 0", code)
+		}
+	})
+	var report releasesReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid JSON %q: %v", stdout, err)
+	}
+	if report.Unpinned != 1 || len(report.Releases) != 1 || report.Releases[0].Slug != "drifting-holon" || report.Releases[0].Pinned {
+		t.Fatalf("report = %+v", report)
+	}
+}
+
+func TestDiscoverVerboseReportsProgressOnStderrOnly(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	t.Setenv("OPPATH", filepath.Join(root, ".oppath"))
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "alpha", givenName: "Alpha", familyName: "Holon", lang: "go"})
+
+	quietOut := captureStdout(t, func() {
+		if code := Run([]string{"--format", "json", "discover"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("op discover returned %d, want 0", code)
+		}
+	})
+	stdout, stderr := captureOutput(t, func() {
+		if code := Run([]string{"--format", "json", "discover", "--verbose"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("op discover --verbose returned %d, want 0", code)
+		}
+	})
+	if stdout != quietOut {
+		t.Fatalf("--verbose changed stdout:\n%s\nwant:\n%s", stdout, quietOut)
+	}
+	if !strings.Contains(stderr, "scanned local:") || !strings.Contains(stderr, "1 holons found") {
+		t.Fatalf("stderr missing scan progress:\n%s", stderr)
+	}
+}
+
+func TestCompletionListsTheRoutedCommands(t *testing.T) {
+	stdout := captureStdout(t, func() { completeVerbs("") })
+	verbs := strings.Fields(stdout)
+	if !slices.IsSorted(verbs) {
+		t.Fatalf("verbs = %v, want them sorted", verbs)
+	}
+	for _, verb := range verbs {
+		if opCommands[verb] == nil {
+			t.Fatalf("completion offers %q, which Run does not route", verb)
+		}
+	}
+	for name := range opCommands {
+		hidden := strings.HasPrefix(name, "-") || strings.HasPrefix(name, "_")
+		if slices.Contains(verbs, name) == hidden {
+			t.Fatalf("%q: offered = %v, hidden = %v", name, !hidden, hidden)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+)
+
+const compareUsage = "usage: op compare <holon> <method> [json]"
+
+// compareResult is one transport's answer in `op compare`.
+type compareResult struct {
+	Transport string          `json:"transport"`
+	Status    string          `json:"status"`
+	LatencyMS int64           `json:"latency_ms"`
+	Output    json.RawMessage `json:"output,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Diff      string          `json:"diff"`
+
+	latency   time.Duration
+	canonical string
+}
+
+type compareReport struct {
+	Holon     string          `json:"holon"`
+	Method    string          `json:"method"`
+	Baseline  string          `json:"baseline,omitempty"`
+	Identical bool            `json:"identical"`
+	Results   []compareResult `json:"results"`
+}
+
+// cmdCompare runs `op compare <holon> <method> [json]`: it calls the method
+// over every transport the holon can be reached by (mem, stdio, tcp) and
+// reports whether the responses agree, with the latency of each call.
+func cmdCompare(format Format, args []string) int {
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, compareUsage)
+		return 1
+	}
+	holon := args[0]
+	method, inputJSON, err := mapHolonCommandToRPC(args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op compare: %v\n", err)
+		return 1
+	}
+
+	transports, err := compareTransports(holon)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op compare: %v\n", err)
+		return 1
+	}
+
+	report := compareReport{Holon: holon, Method: method}
+	for _, t := range transports {
+		start := time.Now()
+		result, err := t.call(method, inputJSON)
+		entry := compareResult{Transport: t.name, latency: time.Since(start)}
+		entry.LatencyMS = entry.latency.Milliseconds()
+		if err != nil {
+			entry.Status = "error"
+			entry.Error = rpcErrorMessage(err)
+		} else {
+			entry.Status = "ok"
+			entry.canonical = canonicalCompareOutput(result.Output)
+			entry.Output = compareOutputJSON(entry.canonical)
+		}
+		report.Results = append(report.Results, entry)
+	}
+	report.Baseline, report.Identical = diffCompareResults(report.Results)
+
+	if format == FormatJSON {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "op compare: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(out))
+	} else {
+		printCompareText(report)
+	}
+
+	if !report.Identical {
+		return 1
+	}
+	return 0
+}
+
+type compareTransport struct {
+	name string
+	call func(method, inputJSON string) (*grpcclient.CallResult, error)
+}
+
+// compareTransports lists the transports op could use to reach holon, in
+// transport-chain order.
+func compareTransports(holon string) ([]compareTransport, error) {
+	var transports []compareTransport
+
+	if memTransportCandidate(holon).Available {
+		transports = append(transports, compareTransport{
+			name: "mem",
+			call: func(method, inputJSON string) (*grpcclient.CallResult, error) {
+				return callViaMem(context.Background(), holon, method, inputJSON, connectCheck{})
+			},
+		})
+	}
+
+	if binary, err := resolveHolon(holon); err == nil {
+		transports = append(transports,
+			compareTransport{
+				name: "stdio",
+				call: func(method, inputJSON string) (*grpcclient.CallResult, error) {
+					return callViaStdio(context.Background(), binary, method, []byte(inputJSON), connectCheck{})
+				},
+			},
+			compareTransport{
+				name: "tcp",
+				call: func(method, inputJSON string) (*grpcclient.CallResult, error) {
+					return callViaEphemeralTCP(context.Background(), holon, binary, method, inputJSON, connectCheck{})
+				},
+			},
+		)
+	}
+
+	if len(transports) == 0 {
+		return nil, holonNotFoundError(holon)
+	}
+	return transports, nil
+}
+
+// canonicalCompareOutput re-indents JSON output with sorted keys so that
+// responses differing only in field order or whitespace compare equal.
+func canonicalCompareOutput(output string) string {
+	value, err := decodeJSONValue([]byte(output))
+	if err != nil {
+		return strings.TrimSpace(output)
+	}
+	out, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return strings.TrimSpace(output)
+	}
+	return string(out)
+}
+
+// compareOutputJSON is output for the JSON report: as is when it is JSON,
+// otherwise as a JSON string, so the report stays valid.
+func compareOutputJSON(output string) json.RawMessage {
+	if json.Valid([]byte(output)) {
+		return json.RawMessage(output)
+	}
+	quoted, _ := json.Marshal(output)
+	return quoted
+}
+
+// diffCompareResults fills in each result's Diff against the first successful
+// response and reports that baseline transport and whether every transport
+// succeeded with the same response.
+func diffCompareResults(results []compareResult) (string, bool) {
+	baseline := -1
+	for i := range results {
+		if results[i].Status == "ok" {
+			baseline = i
+			break
+		}
+	}
+
+	identical := baseline >= 0
+	for i := range results {
+		switch {
+		case results[i].Status != "ok":
+			results[i].Diff = "-"
+			identical = false
+		case i == baseline:
+			results[i].Diff = "baseline"
+		default:
+			differ, total := countDifferingLines(results[baseline].canonical, results[i].canonical)
+			if differ == 0 {
+				results[i].Diff = "identical"
+				continue
+			}
+			results[i].Diff = fmt.Sprintf("%d of %d lines differ from %s", differ, total, results[baseline].Transport)
+			identical = false
+		}
+	}
+
+	if baseline < 0 {
+		return "", false
+	}
+	return results[baseline].Transport, identical
+}
+
+func countDifferingLines(a, b string) (int, int) {
+	left := strings.Split(a, "\n")
+	right := strings.Split(b, "\n")
+	total := max(len(left), len(right))
+	differ := 0
+	for i := 0; i < total; i++ {
+		if i >= len(left) || i >= len(right) || left[i] != right[i] {
+			differ++
+		}
+	}
+	return differ, total
+}
+
+func printCompareText(report compareReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TRANSPORT\tSTATUS\tLATENCY\tDIFF")
+	for _, r := range report.Results {
+		status := r.Status
+		if r.Error != "" {
+			status += ": " + r.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Transport, status, r.latency.Round(time.Millisecond), r.Diff)
+	}
+	_ = w.Flush()
+
+	switch {
+	case report.Identical:
+		fmt.Printf("All %d transports returned identical responses.\n", len(report.Results))
+	case report.Baseline == "":
+		fmt.Println("No transport returned a response.")
+	default:
+		fmt.Printf("Responses differ across transports (baseline: %s).\n", report.Baseline)
+	}
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalCompareOutputIgnoresKeyOrderAndWhitespace(t *testing.T) {
+	a := canonicalCompareOutput(`{"b": 1, "a": {"y": true, "x": "v"}}`)
+	b := canonicalCompareOutput(`{"a":{"x":"v","y":true},"b":1}`)
+	if a != b {
+		t.Fatalf("canonical forms differ:\n%s\n---\n%s", a, b)
+	}
+}
+
+func TestCompareReportStaysValidJSONForNonJSONOutput(t *testing.T) {
+	report := compareReport{Results: []compareResult{
+		{Transport: "stdio", Status: "ok", Output: compareOutputJSON(canonicalCompareOutput("not json {"))},
+		{Transport: "tcp", Status: "ok", Output: compareOutputJSON(canonicalCompareOutput(`{"a": 1}`))},
+	}}
+	out, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("report does not marshal: %v", err)
+	}
+	if !strings.Contains(string(out), `"output":"not json {"`) || !strings.Contains(string(out), `"output":{`) {
+		t.Fatalf("report = %s, want the non-JSON output as a string and the JSON one as is", out)
+	}
+}
+
+func TestDiffCompareResults(t *testing.T) {
+	results := []compareResult{
+		{Transport: "mem", Status: "ok", canonical: canonicalCompareOutput(`{"entries":[{"uuid":"1"}]}`)},
+		{Transport: "stdio", Status: "ok", canonical: canonicalCompareOutput(`{"entries":[{"uuid":"1"}]}`)},
+		{Transport: "tcp", Status: "ok", canonical: canonicalCompareOutput(`{"entries":[{"uuid":"2"}]}`)},
+	}
+
+	baseline, identical := diffCompareResults(results)
+	if baseline != "mem" || identical {
+		t.Fatalf("baseline = %q, identical = %v; want mem, false", baseline, identical)
+	}
+	if results[0].Diff != "baseline" || results[1].Diff != "identical" {
+		t.Fatalf("diffs = %q, %q", results[0].Diff, results[1].Diff)
+	}
+	if !strings.Contains(results[2].Diff, "differ from mem") {
+		t.Fatalf("tcp diff = %q, want a difference from mem", results[2].Diff)
+	}
+}
+
+func TestDiffCompareResultsTreatsErrorsAsMismatch(t *testing.T) {
+	results := []compareResult{
+		{Transport: "stdio", Status: "error", Error: "boom"},
+		{Transport: "tcp", Status: "ok", canonical: "{}"},
+	}
+
+	baseline, identical := diffCompareResults(results)
+	if baseline != "tcp" || identical {
+		t.Fatalf("baseline = %q, identical = %v; want tcp, false", baseline, identical)
+	}
+	if results[0].Diff != "-" {
+		t.Fatalf("error diff = %q, want -", results[0].Diff)
+	}
+}
+
+func TestCompareUnknownHolon(t *testing.T) {
+	chdirForTest(t, t.TempDir())
+
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"compare", "missing", "list"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("code = %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, `op compare: holon "missing" not found`) {
+		t.Fatalf("stderr = %q", stderr)
+	}
+}
@@ -2,8 +2,10 @@ package cli
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/organic-programming/grace-op/internal/holons"
@@ -36,7 +38,7 @@ func cmdComplete(args []string) int {
 	}
 
 	switch verb {
-	case "build", "run", "install", "check", "test", "clean", "inspect", "show":
+	case "build", "run", "install", "check", "test", "clean", "inspect", "show", "which":
 		completeSlugs(prefix)
 	case "uninstall":
 		completeInstalled(prefix)
@@ -92,15 +94,13 @@ func completeInstalled(prefix string) {
 	}
 }
 
-// completeVerbs lists op subcommands matching the prefix.
+// completeVerbs lists op subcommands matching the prefix, in order,
+// leaving out help flags and hidden commands.
 func completeVerbs(prefix string) {
-	verbs := []string{
-		"build", "check", "clean", "completion", "discover",
-		"env", "help", "inspect", "install", "list", "mcp",
-		"mod", "new", "run", "serve", "show", "test", "tools",
-		"uninstall", "version",
-	}
-	for _, v := range verbs {
+	for _, v := range slices.Sorted(maps.Keys(opCommands)) {
+		if strings.HasPrefix(v, "-") || strings.HasPrefix(v, "_") {
+			continue
+		}
 		if strings.HasPrefix(v, prefix) {
 			fmt.Println(v)
 		}
@@ -120,7 +120,7 @@ _op() {
     fi
 
     case "${words[2]}" in
-        build|run|install|check|test|clean|inspect|show)
+        build|run|install|check|test|clean|inspect|show|which)
             local -a slugs
             slugs=($(op __complete "${words[2]}" "${words[CURRENT]}"))
             _describe 'holons' slugs
@@ -147,7 +147,7 @@ const bashCompletion = `_op() {
     fi
 
     case "${COMP_WORDS[1]}" in
-        build|run|install|check|test|clean|inspect|show)
+        build|run|install|check|test|clean|inspect|show|which)
             COMPREPLY=($(compgen -W "$(op __complete "${COMP_WORDS[1]}" "$cur")" -- "$cur"))
             ;;
         uninstall)
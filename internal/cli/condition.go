@@ -0,0 +1,242 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	inspectpkg "github.com/organic-programming/grace-op/internal/inspect"
+)
+
+// responseCondition is a check against a JSON response, written
+// `<path> <op> <json literal>` (e.g. `.status == "done"`, `.count >= 3`) or
+// a bare `<path>`, which holds when the value is present and truthy.
+type responseCondition struct {
+	expr    string
+	path    string
+	op      string
+	literal any
+	// checked is set once the path is known to name a response field, by
+	// checkFields.
+	checked bool
+}
+
+var conditionOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func parseResponseCondition(expr string) (responseCondition, error) {
+	cond := responseCondition{expr: strings.TrimSpace(expr)}
+	if cond.expr == "" {
+		return cond, fmt.Errorf("empty condition")
+	}
+
+	// The operator is the earliest one in the expression, so operators
+	// inside the literal (e.g. `.note == "a<b"`) are left alone.
+	at := -1
+	for _, op := range conditionOperators {
+		if i := strings.Index(cond.expr, op); i >= 0 && (at < 0 || i < at) {
+			at, cond.op = i, op
+		}
+	}
+	if at >= 0 {
+		cond.path = strings.TrimSpace(cond.expr[:at])
+		raw := []byte(strings.TrimSpace(cond.expr[at+len(cond.op):]))
+		literal, err := decodeJSONValue(raw)
+		if err != nil || !json.Valid(raw) {
+			return cond, fmt.Errorf("condition %q: right-hand side is not a JSON value", expr)
+		}
+		cond.literal = literal
+	}
+	if cond.op == "" {
+		cond.path = cond.expr
+	}
+	if _, err := splitJSONPath(cond.path); err != nil {
+		return cond, fmt.Errorf("condition %q: %w", expr, err)
+	}
+	if cond.op != "" && cond.op != "==" && cond.op != "!=" {
+		if _, ok := cond.literal.(json.Number); !ok {
+			return cond, fmt.Errorf("condition %q: %s needs a number", expr, cond.op)
+		}
+	}
+	return cond, nil
+}
+
+// checkFields makes sure the path names a field of a response with fields,
+// by proto or JSON name, so a typo fails instead of reading as a zero value.
+// Fields described without their nested messages end the check there.
+func (c *responseCondition) checkFields(fields []inspectpkg.Field) error {
+	segments, err := splitJSONPath(c.path)
+	if err != nil {
+		return err
+	}
+	walked := ""
+	for i := 0; i < len(segments); i++ {
+		if fields == nil {
+			break
+		}
+		field, ok := findConditionField(fields, segments[i])
+		if !ok {
+			return fmt.Errorf("condition %q: the response has no field %q at %s", c.expr, segments[i], jsonPathLabel(walked))
+		}
+		if walked != "" {
+			walked += "."
+		}
+		walked += segments[i]
+		// An index or a map key selects an element of the same type.
+		if (field.Label == inspectpkg.FieldLabelRepeated || field.Label == inspectpkg.FieldLabelMap) && i+1 < len(segments) {
+			i++
+			walked += "." + segments[i]
+		}
+		fields = field.NestedFields
+		if fields == nil && i+1 < len(segments) && isScalarType(field.Type, field.MapValueType, field.EnumValues) {
+			return fmt.Errorf("condition %q: %s is a %s, which has no field %q", c.expr, walked, field.Type, segments[i+1])
+		}
+	}
+	c.checked = true
+	return nil
+}
+
+func findConditionField(fields []inspectpkg.Field, name string) (inspectpkg.Field, bool) {
+	for _, field := range fields {
+		if field.Name == name || inspectpkg.FieldJSONName(field) == name {
+			return field, true
+		}
+	}
+	return inspectpkg.Field{}, false
+}
+
+// isScalarType reports whether a field of typeName (or, for a map, of
+// valueType) holds a scalar or an enum rather than a message.
+func isScalarType(typeName, valueType string, enumValues []inspectpkg.EnumValue) bool {
+	if valueType != "" {
+		typeName = valueType
+	}
+	if len(enumValues) > 0 {
+		return true
+	}
+	switch strings.TrimSpace(typeName) {
+	case "string", "bytes", "bool", "float", "double",
+		"int32", "uint32", "sint32", "fixed32", "sfixed32",
+		"int64", "uint64", "sint64", "fixed64", "sfixed64":
+		return true
+	}
+	return false
+}
+
+// holds evaluates the condition against one JSON response. protojson leaves
+// out fields at their zero value, so once checkFields has confirmed the path,
+// a field missing from the response is taken to be 0, false, "" or empty,
+// whichever the literal is. An unchecked path the response does not have
+// returns a *jsonFieldMissingError; any other path it cannot select never
+// holds.
+func (c responseCondition) holds(response []byte) (bool, error) {
+	document, err := decodeJSONValue(response)
+	if err != nil {
+		return false, fmt.Errorf("response is not JSON: %w", err)
+	}
+	value, err := selectJSONPath(document, c.path)
+	var missing *jsonFieldMissingError
+	unset := errors.As(err, &missing)
+	if unset && !c.checked {
+		return false, missing
+	}
+	if err != nil && !unset {
+		return false, nil
+	}
+
+	switch c.op {
+	case "":
+		return !unset && jsonTruthy(value), nil
+	case "==":
+		if unset {
+			return jsonZero(c.literal), nil
+		}
+		return jsonEqual(value, c.literal), nil
+	case "!=":
+		if unset {
+			return !jsonZero(c.literal), nil
+		}
+		return !jsonEqual(value, c.literal), nil
+	}
+
+	got, ok := jsonFloat(value)
+	if unset {
+		got, ok = 0, true
+	}
+	if !ok {
+		return false, nil
+	}
+	want, _ := jsonFloat(c.literal)
+	switch c.op {
+	case "<":
+		return got < want, nil
+	case "<=":
+		return got <= want, nil
+	case ">":
+		return got > want, nil
+	default:
+		return got >= want, nil
+	}
+}
+
+func jsonTruthy(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case json.Number:
+		f, _ := v.Float64()
+		return f != 0
+	default:
+		return true
+	}
+}
+
+// jsonZero reports whether value is the zero value of its JSON type: null,
+// false, 0, "", [] or {}.
+func jsonZero(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	default:
+		return !jsonTruthy(value)
+	}
+}
+
+// jsonEqual compares decoded JSON values; numbers compare by value, so 1 and
+// 1.0 are equal. protojson renders 64-bit integers as strings, so a string
+// also equals the number it spells.
+func jsonEqual(a, b any) bool {
+	_, aNumber := a.(json.Number)
+	_, bNumber := b.(json.Number)
+	if !aNumber && !bNumber {
+		return reflect.DeepEqual(a, b)
+	}
+	if af, ok := jsonFloat(a); ok {
+		if bf, ok := jsonFloat(b); ok {
+			return af == bf
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func jsonFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := json.Number(v).Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
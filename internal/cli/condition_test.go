@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	inspectpkg "github.com/organic-programming/grace-op/internal/inspect"
+)
+
+func TestResponseConditionHolds(t *testing.T) {
+	response := []byte(`{"status":"done","count":"3","ratio":0.5,"job":{"ready":true},"note":"a<b"}`)
+	cases := map[string]bool{
+		`.status == "done"`:    true,
+		`.status != "done"`:    false,
+		`.count >= 3`:          true,
+		`.count > 3`:           false,
+		`.ratio < 1`:           true,
+		`.job.ready`:           true,
+		`.job.ready == false`:  false,
+		`.status.x == 0`:       false,
+		`.note == "a<b"`:       true,
+		`.status == "pending"`: false,
+	}
+	for expr, want := range cases {
+		cond, err := parseResponseCondition(expr)
+		if err != nil {
+			t.Fatalf("parse %s: %v", expr, err)
+		}
+		got, err := cond.holds(response)
+		if err != nil {
+			t.Fatalf("holds %s: %v", expr, err)
+		}
+		if got != want {
+			t.Fatalf("%s = %v, want %v", expr, got, want)
+		}
+	}
+}
+
+func TestResponseConditionTakesOmittedFieldsAsZero(t *testing.T) {
+	// protojson leaves out count: 0, done: false and note: "".
+	response := []byte(`{"job":{}}`)
+	fields := []inspectpkg.Field{
+		{Name: "count", Type: "int64"},
+		{Name: "note", Type: "string"},
+		{Name: "job", Type: "demo.v1.Job", NestedFields: []inspectpkg.Field{{Name: "done", Type: "bool"}}},
+	}
+	cases := map[string]bool{
+		`.count == 0`:        true,
+		`.count != 0`:        false,
+		`.count < 1`:         true,
+		`.count >= 1`:        false,
+		`.job.done == false`: true,
+		`.job.done == true`:  false,
+		`.job.done`:          false,
+		`.note == ""`:        true,
+		`.note != ""`:        false,
+		`.note == "x"`:       false,
+	}
+	for expr, want := range cases {
+		cond, err := parseResponseCondition(expr)
+		if err != nil {
+			t.Fatalf("parse %s: %v", expr, err)
+		}
+		if err := cond.checkFields(fields); err != nil {
+			t.Fatalf("check %s: %v", expr, err)
+		}
+		if got, err := cond.holds(response); err != nil || got != want {
+			t.Fatalf("%s = %v (%v), want %v", expr, got, err, want)
+		}
+	}
+}
+
+func TestParseResponseConditionRejectsBadInput(t *testing.T) {
+	for _, expr := range []string{"", `.status == done`, `.count > "3"`} {
+		if _, err := parseResponseCondition(expr); err == nil {
+			t.Fatalf("expected %q to be rejected", expr)
+		}
+	}
+}
+
+func TestResponseConditionRejectsPathsTheResponseCannotHave(t *testing.T) {
+	fields := []inspectpkg.Field{
+		{Name: "entries", Type: "demo.v1.Entry", Label: inspectpkg.FieldLabelRepeated, NestedFields: []inspectpkg.Field{
+			{Name: "given_name", JSONName: "givenName", Type: "string"},
+		}},
+		{Name: "status", Type: "string"},
+	}
+	for _, expr := range []string{`.entries[0].givenName == "a"`, `.entries[0].given_name == "a"`, `.entries`} {
+		cond, err := parseResponseCondition(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cond.checkFields(fields); err != nil {
+			t.Fatalf("check %s: %v", expr, err)
+		}
+	}
+	for _, expr := range []string{`.entris == 0`, `.entries[0].givn_name == ""`, `.status.x == 0`} {
+		cond, err := parseResponseCondition(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cond.checkFields(fields); err == nil {
+			t.Fatalf("check %s: want an error for a path the response cannot have", expr)
+		}
+	}
+
+	// Unchecked, a missing field is reported rather than taken as zero.
+	cond, err := parseResponseCondition(`.job.missing.x == 0`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var missing *jsonFieldMissingError
+	if ok, err := cond.holds([]byte(`{"job":{}}`)); ok || !errors.As(err, &missing) {
+		t.Fatalf("holds = %v, %v; want a missing-field error", ok, err)
+	}
+}
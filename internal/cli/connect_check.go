@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	holonmetav1 "github.com/organic-programming/go-holons/gen/go/holonmeta/v1"
+	"github.com/organic-programming/grace-op/internal/holons"
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// connectCheck asks which holon answered at an address before the requested
+// call is made. It is opt-in: without --verbose or --expect-holon no extra
+// round-trip happens.
+type connectCheck struct {
+	Verbose     bool
+	ExpectHolon string
+}
+
+func (c connectCheck) enabled() bool {
+	return c.Verbose || c.ExpectHolon != ""
+}
+
+// extractConnectCheckFlags removes --verbose and --expect-holon from args.
+func extractConnectCheckFlags(args []string) (connectCheck, []string, error) {
+	var check connectCheck
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--verbose":
+			check.Verbose = true
+		case args[i] == "--expect-holon":
+			if i+1 >= len(args) {
+				return check, nil, fmt.Errorf("--expect-holon requires a slug or uuid")
+			}
+			check.ExpectHolon = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--expect-holon="):
+			check.ExpectHolon = strings.TrimPrefix(args[i], "--expect-holon=")
+			if check.ExpectHolon == "" {
+				return check, nil, fmt.Errorf("--expect-holon requires a slug or uuid")
+			}
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+	return check, remaining, nil
+}
+
+// verifyConnectedHolon dials address and checks which holon answers there,
+// as verifyHolonOn does.
+func verifyConnectedHolon(address string, check connectCheck, extra ...grpc.DialOption) error {
+	if !check.enabled() {
+		return nil
+	}
+
+	conn, err := grpc.NewClient(address, grpcclient.DialOptions(extra...)...)
+	if err != nil {
+		return fmt.Errorf("connect to %s: %w", address, err)
+	}
+	defer conn.Close()
+	return verifyHolonOn(conn, address, check)
+}
+
+// verifyWebSocketHolon checks which holon answers at wsURI over a WebSocket
+// of its own, as verifyHolonOn does.
+func verifyWebSocketHolon(wsURI string, check connectCheck) error {
+	if !check.enabled() {
+		return nil
+	}
+
+	ctx, cancel := grpcclient.CallContext(context.Background(), grpcclient.Timeout())
+	defer cancel()
+	conn, err := grpcclient.DialWebSocketConn(ctx, wsURI)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return verifyHolonOn(conn, wsURI, check)
+}
+
+// verifyHolonOn asks the server behind conn to Describe itself, prints the
+// reported slug under --verbose, and fails when it does not match
+// --expect-holon. label names the server in messages. A server without
+// HolonMeta can only fail an expectation.
+func verifyHolonOn(conn grpc.ClientConnInterface, label string, check connectCheck) error {
+	if !check.enabled() {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), grpcclient.Timeout())
+	defer cancel()
+
+	response, err := holonmetav1.NewHolonMetaClient(conn).Describe(ctx, &holonmetav1.DescribeRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		if check.ExpectHolon != "" {
+			return fmt.Errorf("cannot verify holon at %s: it does not implement HolonMeta.Describe", label)
+		}
+		fmt.Fprintf(os.Stderr, "connected to %s (holon identity unavailable)\n", label)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("describe %s: %w", label, err)
+	}
+
+	slug := response.GetSlug()
+	if check.Verbose {
+		fmt.Fprintf(os.Stderr, "connected to %s at %s\n", slug, label)
+	}
+	if check.ExpectHolon != "" && !holonMatchesExpectation(slug, check.ExpectHolon) {
+		return fmt.Errorf("expected holon %s at %s, but %q answered", check.ExpectHolon, label, slug)
+	}
+	return nil
+}
+
+// holonMatchesExpectation compares the reported slug with an expected slug or
+// with the slug of a discovered holon (local or cached) whose UUID, or UUID
+// prefix, is expected.
+func holonMatchesExpectation(slug, expected string) bool {
+	if strings.EqualFold(slug, expected) {
+		return true
+	}
+	local, _ := holons.DiscoverLocalHolons()
+	cached, _ := holons.DiscoverCachedHolons()
+	for _, holon := range append(local, cached...) {
+		if strings.HasPrefix(holon.Identity.UUID, expected) {
+			return holon.Identity.Slug() == slug
+		}
+	}
+	return false
+}
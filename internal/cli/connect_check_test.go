@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	holonmetav1 "github.com/organic-programming/go-holons/gen/go/holonmeta/v1"
+)
+
+func TestExtractConnectCheckFlags(t *testing.T) {
+	check, rest, err := extractConnectCheckFlags([]string{"--verbose", "Ping", "--expect-holon", "echo-server", "{}"})
+	if err != nil {
+		t.Fatalf("extractConnectCheckFlags: %v", err)
+	}
+	if !check.Verbose || check.ExpectHolon != "echo-server" {
+		t.Fatalf("check = %+v", check)
+	}
+	if strings.Join(rest, " ") != "Ping {}" {
+		t.Fatalf("remaining args = %v, want [Ping {}]", rest)
+	}
+
+	if _, _, err := extractConnectCheckFlags([]string{"Ping", "--expect-holon"}); err == nil {
+		t.Fatal("expected an error for --expect-holon without a value")
+	}
+
+	check, rest, err = extractConnectCheckFlags([]string{"Ping", "-v"})
+	if err != nil {
+		t.Fatalf("extractConnectCheckFlags: %v", err)
+	}
+	if check.Verbose || strings.Join(rest, " ") != "Ping -v" {
+		t.Fatalf("check = %+v, rest = %v, want -v left to the call", check, rest)
+	}
+}
+
+func TestHolonMatchesExpectationResolvesDiscoveredUUIDPrefix(t *testing.T) {
+	runtimeHome := t.TempDir()
+	t.Setenv("OPPATH", runtimeHome)
+	seedTransportHolon(t, filepath.Join(runtimeHome, "cache"), transportHolonSeed{
+		dirName:    "atlas",
+		givenName:  "Atlas",
+		familyName: "Holon",
+		lang:       "go",
+	})
+	// The expected UUID belongs to a cached holon, outside the directory op
+	// runs from.
+	chdirForTest(t, t.TempDir())
+
+	if !holonMatchesExpectation("atlas-holon", "transport-test-at") {
+		t.Fatal("a UUID prefix of a discovered holon did not match its slug")
+	}
+	if holonMatchesExpectation("echo-server", "transport-test-at") {
+		t.Fatal("a UUID prefix matched another holon's slug")
+	}
+}
+
+func TestGRPCExpectHolonChecksMemHolons(t *testing.T) {
+	registerHealthComposer(t, "health-mem")
+
+	stderr := captureStderr(t, func() {
+		code := Run([]string{"mem://health-mem", "--expect-holon", "health-mem", "Check"}, "0.1.0-test")
+		if code != 1 {
+			t.Fatalf("code = %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "cannot verify holon at mem://health-mem") {
+		t.Fatalf("stderr = %q, want the mem holon to be checked", stderr)
+	}
+}
+
+func TestGRPCExpectHolonRejectsOtherHolon(t *testing.T) {
+	address := startDescribeServer(t, &holonmetav1.DescribeResponse{Slug: "echo-server"})
+
+	stderr := captureStderr(t, func() {
+		code := Run([]string{"grpc://" + address, "--expect-holon", "rob-go", "Ping"}, "0.1.0-test")
+		if code != 1 {
+			t.Fatalf("code = %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, `expected holon rob-go`) || !strings.Contains(stderr, `"echo-server" answered`) {
+		t.Fatalf("stderr = %q, want a holon mismatch", stderr)
+	}
+}
+
+func TestGRPCVerboseReportsConnectedHolon(t *testing.T) {
+	address := startDescribeServer(t, &holonmetav1.DescribeResponse{Slug: "echo-server"})
+
+	stderr := captureStderr(t, func() {
+		Run([]string{"grpc://" + address, "--verbose", "--expect-holon", "echo-server"}, "0.1.0-test")
+	})
+	if !strings.Contains(stderr, "connected to echo-server at "+address) {
+		t.Fatalf("stderr = %q, want the connected holon", stderr)
+	}
+	if strings.Contains(stderr, "expected holon") {
+		t.Fatalf("stderr = %q, want the expectation to pass", stderr)
+	}
+}
@@ -0,0 +1,382 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
+	openv "github.com/organic-programming/grace-op/internal/env"
+	"github.com/organic-programming/grace-op/internal/server"
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// op daemon keeps OP's own gRPC server running on a Unix socket so op list
+// and a holon's own command line (op <holon> <command>, through
+// InvokeStream) can skip op's startup and discovery setup. Holon RPC calls
+// resolve and reach holons from the caller's working directory, so they
+// always run in the CLI. OP_DAEMON decides whether the CLI forwards to it:
+// unset or "off" never, "on" when a daemon is already running, "auto" also
+// starting one on first use.
+const (
+	envDaemon       = "OP_DAEMON"
+	envDaemonSocket = "OP_DAEMON_SOCKET"
+)
+
+const (
+	daemonModeOff  = "off"
+	daemonModeOn   = "on"
+	daemonModeAuto = "auto"
+)
+
+// daemonAutoIdleTimeout bounds how long a daemon started by OP_DAEMON=auto
+// outlives its last request.
+const daemonAutoIdleTimeout = 30 * time.Minute
+
+// daemonStartTimeout is how long auto-start and op daemon stop wait for the
+// socket to appear or go away.
+const daemonStartTimeout = 5 * time.Second
+
+type daemonStatus struct {
+	Running bool   `json:"running"`
+	Socket  string `json:"socket"`
+	PID     int    `json:"pid,omitempty"`
+}
+
+// cmdDaemon runs `op daemon [start|stop|status] [--socket <path>]`.
+func cmdDaemon(format Format, args []string) int {
+	verb := "start"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		verb, args = args[0], args[1:]
+	}
+
+	socket, idleTimeout, err := parseDaemonArgs(verb, args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op daemon: %v\n", err)
+		return 1
+	}
+
+	switch verb {
+	case "start":
+		if err := runDaemon(socket, idleTimeout); err != nil {
+			fmt.Fprintf(os.Stderr, "op daemon: %v\n", err)
+			return 1
+		}
+		return 0
+	case "stop":
+		if err := stopDaemon(socket); err != nil {
+			fmt.Fprintf(os.Stderr, "op daemon stop: %v\n", err)
+			return 1
+		}
+		if format != FormatJSON {
+			fmt.Printf("op daemon on %s stopped\n", socket)
+		}
+		return 0
+	case "status":
+		status := daemonStatus{Running: daemonRunning(socket), Socket: socket}
+		if status.Running {
+			status.PID, _ = readDaemonPID(socket)
+		}
+		if format == FormatJSON {
+			printJSON(status)
+		} else if status.Running {
+			fmt.Printf("op daemon running on %s (pid %d)\n", socket, status.PID)
+		} else {
+			fmt.Printf("op daemon not running (socket %s)\n", socket)
+		}
+		if !status.Running {
+			return 1
+		}
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "op daemon: unknown subcommand %q (want start, stop or status)\n", verb)
+		return 1
+	}
+}
+
+func parseDaemonArgs(verb string, args []string) (string, time.Duration, error) {
+	socket := daemonSocketPath()
+	var idleTimeout time.Duration
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--socket", "--idle-timeout":
+			if name == "--idle-timeout" && verb != "start" {
+				return "", 0, fmt.Errorf("--idle-timeout only applies to op daemon start")
+			}
+			if !hasValue {
+				if i+1 >= len(args) {
+					return "", 0, fmt.Errorf("%s requires a value", name)
+				}
+				value = args[i+1]
+				i++
+			}
+			if name == "--socket" {
+				if strings.TrimSpace(value) == "" {
+					return "", 0, fmt.Errorf("--socket requires a value")
+				}
+				socket = value
+				continue
+			}
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout <= 0 {
+				return "", 0, fmt.Errorf("invalid --idle-timeout %q", value)
+			}
+			idleTimeout = timeout
+		default:
+			return "", 0, fmt.Errorf("unknown argument %q", args[i])
+		}
+	}
+	abs, err := filepath.Abs(socket)
+	if err != nil {
+		return "", 0, err
+	}
+	return abs, idleTimeout, nil
+}
+
+// daemonSocketPath is OP_DAEMON_SOCKET, or $OPPATH/run/op.sock.
+func daemonSocketPath() string {
+	if socket := strings.TrimSpace(os.Getenv(envDaemonSocket)); socket != "" {
+		return socket
+	}
+	return filepath.Join(openv.OPPATH(), "run", "op.sock")
+}
+
+func daemonPIDPath(socket string) string {
+	return socket + ".pid"
+}
+
+// daemonMode reads OP_DAEMON. Unknown values count as off so a typo never
+// reroutes commands.
+func daemonMode() string {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(envDaemon))) {
+	case "1", "on", "true", "yes":
+		return daemonModeOn
+	case daemonModeAuto:
+		return daemonModeAuto
+	default:
+		return daemonModeOff
+	}
+}
+
+// daemonRunning reports whether something accepts connections on socket.
+func daemonRunning(socket string) bool {
+	conn, err := net.DialTimeout("unix", socket, 200*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// runDaemon serves OP's gRPC service on socket until SIGINT or SIGTERM.
+func runDaemon(socket string, idleTimeout time.Duration) error {
+	if daemonRunning(socket) {
+		return fmt.Errorf("already running on %s", socket)
+	}
+	if err := os.MkdirAll(filepath.Dir(socket), 0o700); err != nil {
+		return err
+	}
+	if err := checkDaemonSocketDir(filepath.Dir(socket)); err != nil {
+		return err
+	}
+	// Nothing answers on the socket, so any file left there is stale.
+	if err := os.Remove(socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	pidPath := daemonPIDPath(socket)
+	if err := os.WriteFile(pidPath, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644); err != nil {
+		return err
+	}
+	defer os.Remove(pidPath)
+
+	signals := []os.Signal{os.Interrupt}
+	if runtime.GOOS != "windows" {
+		signals = append(signals, syscall.SIGTERM)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), signals...)
+	defer stop()
+
+	// Forwarded commands run in the caller's working directory, wherever it
+	// is. That is safe only because the socket is 0600 in a directory no
+	// other account can write to or traverse, so whoever connects could run
+	// them there itself.
+	return server.ListenAndServeContext(ctx, "unix://"+socket, server.ServeOptions{
+		Reflect:     true,
+		Health:      true,
+		IdleTimeout: idleTimeout,
+		SocketMode:  0o600,
+		SandboxRoot: string(filepath.Separator),
+	})
+}
+
+// checkDaemonSocketDir refuses a socket directory that group or others can
+// write to or traverse: they could reach the socket before its mode is
+// set, or swap it for their own.
+func checkDaemonSocketDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if perm := info.Mode().Perm(); perm&0o033 != 0 {
+		return fmt.Errorf("socket directory %s is mode %04o; other accounts could reach the daemon (chmod 700 it)", dir, perm)
+	}
+	return nil
+}
+
+func readDaemonPID(socket string) (int, error) {
+	data, err := os.ReadFile(daemonPIDPath(socket))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// stopDaemon signals the daemon recorded in the socket's PID file and waits
+// for the socket to stop answering.
+func stopDaemon(socket string) error {
+	if !daemonRunning(socket) {
+		return fmt.Errorf("not running on %s", socket)
+	}
+	pid, err := readDaemonPID(socket)
+	if err != nil {
+		return fmt.Errorf("read pid file: %w", err)
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if runtime.GOOS == "windows" {
+		err = process.Kill()
+	} else {
+		err = process.Signal(syscall.SIGTERM)
+	}
+	if err != nil {
+		return fmt.Errorf("signal pid %d: %w", pid, err)
+	}
+
+	deadline := time.Now().Add(daemonStartTimeout)
+	for daemonRunning(socket) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("pid %d still serving %s after %s", pid, socket, daemonStartTimeout)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return nil
+}
+
+// startDaemon launches `op daemon` in the background for OP_DAEMON=auto and
+// waits for its socket. The daemon runs in a session of its own, so a ^C or
+// hangup meant for the command that started it does not stop it. It logs
+// to <socket>.log.
+func startDaemon(socket string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(socket), 0o700); err != nil {
+		return err
+	}
+	logFile, err := os.OpenFile(socket+".log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(exe, "daemon", "start", "--socket", socket, "--idle-timeout", daemonAutoIdleTimeout.String())
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	detachProcess(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	deadline := time.Now().Add(daemonStartTimeout)
+	for !daemonRunning(socket) {
+		select {
+		case err := <-exited:
+			return fmt.Errorf("daemon exited during startup: %v (see %s.log)", err, socket)
+		default:
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("daemon did not listen on %s within %s", socket, daemonStartTimeout)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return nil
+}
+
+// dialDaemon connects to the daemon when OP_DAEMON allows it, starting one
+// first in auto mode. ok is false when the command should run locally.
+func dialDaemon() (*grpc.ClientConn, bool) {
+	mode := daemonMode()
+	if mode == daemonModeOff {
+		return nil, false
+	}
+	socket, err := filepath.Abs(daemonSocketPath())
+	if err != nil {
+		return nil, false
+	}
+	if !daemonRunning(socket) {
+		if mode != daemonModeAuto {
+			return nil, false
+		}
+		if err := startDaemon(socket); err != nil {
+			fmt.Fprintf(os.Stderr, "op: daemon auto-start failed, running locally: %v\n", err)
+			return nil, false
+		}
+	}
+	conn, err := grpc.NewClient("unix://"+socket, grpcclient.DialOptions()...)
+	if err != nil {
+		return nil, false
+	}
+	return conn, true
+}
+
+// daemonListIdentities forwards `op list` to the daemon. The root is made
+// absolute because the daemon has its own working directory. Any failure
+// falls back to running locally, reported once the daemon was reached.
+func daemonListIdentities(root string, mask *fieldmaskpb.FieldMask) (*opv1.ListIdentitiesResponse, bool) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, false
+	}
+	conn, ok := dialDaemon()
+	if !ok {
+		return nil, false
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), grpcclient.Timeout())
+	defer cancel()
+	resp, err := opv1.NewOPServiceClient(conn).ListIdentities(ctx, &opv1.ListIdentitiesRequest{RootDir: absRoot, FieldMask: mask})
+	if err != nil {
+		reportDaemonFallback("op list", err)
+		return nil, false
+	}
+	return resp, true
+}
+
+// reportDaemonFallback tells the user that the daemon was reached but could
+// not run command, which then runs locally.
+func reportDaemonFallback(command string, err error) {
+	fmt.Fprintf(os.Stderr, "op: daemon could not run %s, running locally: %s\n", command, rpcErrorMessage(err))
+}
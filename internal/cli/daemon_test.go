@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/organic-programming/grace-op/internal/server"
+
+	"google.golang.org/grpc"
+)
+
+func TestDaemonForwardsListIdentities(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	t.Setenv("OPPATH", filepath.Join(root, ".oppath"))
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "alpha", givenName: "Alpha", familyName: "Holon", lang: "go"})
+
+	// Unix socket paths are length-limited, so keep this one short.
+	dir, err := os.MkdirTemp("", "opd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "op.sock")
+	t.Setenv(envDaemonSocket, socket)
+	t.Setenv(envDaemon, "on")
+
+	if _, ok := daemonListIdentities(".", nil); ok {
+		t.Fatal("with no daemon running, op list should run locally")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.ListenAndServeContext(ctx, "unix://"+socket, server.ServeOptions{}) }()
+	stopped := false
+	stop := func() {
+		if !stopped {
+			stopped = true
+			cancel()
+			if err := <-done; err != nil {
+				t.Errorf("daemon server returned %v", err)
+			}
+		}
+	}
+	t.Cleanup(stop)
+	deadline := time.Now().Add(5 * time.Second)
+	for !daemonRunning(socket) {
+		if time.Now().After(deadline) {
+			t.Fatal("daemon socket never came up")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	resp, ok := daemonListIdentities(".", nil)
+	if !ok {
+		t.Fatal("op list should be forwarded to the running daemon")
+	}
+	if len(resp.GetEntries()) != 1 || resp.GetEntries()[0].GetIdentity().GetGivenName() != "Alpha" {
+		t.Fatalf("entries = %v", resp.GetEntries())
+	}
+	if got := resp.GetEntries()[0].GetRelativePath(); got != filepath.Join("holons", "alpha") {
+		t.Fatalf("relative path = %q; the daemon should resolve the caller's root", got)
+	}
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"--format", "json", "daemon", "status"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("daemon status returned %d, want 0", code)
+		}
+	})
+	var status daemonStatus
+	if err := json.Unmarshal([]byte(stdout), &status); err != nil || !status.Running || status.Socket != socket {
+		t.Fatalf("status = %q (%v)", stdout, err)
+	}
+
+	stop()
+	if _, err := os.Stat(socket); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("socket should be removed on graceful stop, stat err = %v", err)
+	}
+	_ = captureStdout(t, func() {
+		if code := Run([]string{"daemon", "status"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("daemon status after stop returned %d, want 1", code)
+		}
+	})
+}
+
+func TestRunDaemonRefusesAnOpenSocketDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no Unix permissions")
+	}
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	err := runDaemon(filepath.Join(dir, "op.sock"), 0)
+	if err == nil || !strings.Contains(err.Error(), "chmod 700") {
+		t.Fatalf("runDaemon in a 0755 directory = %v, want a refusal", err)
+	}
+	if _, err := os.Stat(daemonPIDPath(filepath.Join(dir, "op.sock"))); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("refused daemon left a pid file: %v", err)
+	}
+}
+
+func TestDaemonModeAndArgs(t *testing.T) {
+	for value, want := range map[string]string{"": daemonModeOff, "on": daemonModeOn, "1": daemonModeOn, "AUTO": daemonModeAuto, "sometimes": daemonModeOff} {
+		t.Setenv(envDaemon, value)
+		if got := daemonMode(); got != want {
+			t.Fatalf("%s=%q: mode = %q, want %q", envDaemon, value, got, want)
+		}
+	}
+
+	if _, _, err := parseDaemonArgs("stop", []string{"--idle-timeout", "1m"}); err == nil {
+		t.Fatal("--idle-timeout should be rejected for op daemon stop")
+	}
+	socket, idle, err := parseDaemonArgs("start", []string{"--socket=/tmp/op-test.sock", "--idle-timeout", "90s"})
+	if err != nil || socket != "/tmp/op-test.sock" || idle != 90*time.Second {
+		t.Fatalf("parseDaemonArgs = %q, %s, %v", socket, idle, err)
+	}
+}
+
+func TestDaemonFallbackToLocalIsReported(t *testing.T) {
+	chdirForTest(t, t.TempDir())
+	dir, err := os.MkdirTemp("", "opd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "op.sock")
+	t.Setenv(envDaemonSocket, socket)
+	t.Setenv(envDaemon, "on")
+
+	// Something answers on the socket, but not OP's service.
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	stderr := captureStderr(t, func() {
+		if _, ok := daemonListIdentities(".", nil); ok {
+			t.Fatal("op list should run locally when the daemon cannot")
+		}
+	})
+	if !strings.Contains(stderr, "daemon could not run op list, running locally") {
+		t.Fatalf("stderr = %q, want the fallback reported", stderr)
+	}
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// dataTemplateFuncs is the function set available to --data-template.
+var dataTemplateFuncs = template.FuncMap{
+	"now":  func() string { return time.Now().UTC().Format(time.RFC3339) },
+	"uuid": uuid.NewString,
+	"env":  os.Getenv,
+	// json encodes a value as a JSON literal, quotes and escapes included:
+	// {"motto": {{env "MOTTO" | json}}}.
+	"json": outputTemplateFuncs["json"],
+}
+
+// applyDataTemplate removes --data-template from args and, when present,
+// inserts the rendered JSON right after the method or command name so the
+// existing positional-JSON handling picks it up.
+func applyDataTemplate(args []string) ([]string, error) {
+	source, args, err := extractDataTemplateFlag(args)
+	if err != nil || source == "" {
+		return args, err
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("--data-template needs a method")
+	}
+	if len(args) > 1 && looksLikeJSON(args[1]) {
+		return nil, fmt.Errorf("--data-template cannot be combined with a JSON argument")
+	}
+
+	text, err := readDataTemplate(source)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := renderDataTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0, len(args)+1)
+	out = append(out, args[0], rendered)
+	return append(out, args[1:]...), nil
+}
+
+// extractDataTemplateFlag removes --data-template <template> from args.
+func extractDataTemplateFlag(args []string) (string, []string, error) {
+	source := ""
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--data-template":
+			if i+1 >= len(args) {
+				return "", nil, fmt.Errorf("--data-template requires a value")
+			}
+			source = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--data-template="):
+			source = strings.TrimPrefix(args[i], "--data-template=")
+		default:
+			remaining = append(remaining, args[i])
+			continue
+		}
+		if strings.TrimSpace(source) == "" {
+			return "", nil, fmt.Errorf("--data-template requires a value")
+		}
+	}
+	return source, remaining, nil
+}
+
+// readDataTemplate returns the template text. "@-" reads it from stdin and
+// "@<path>" from a file; anything else is the template itself.
+func readDataTemplate(source string) (string, error) {
+	switch {
+	case source == "@-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("read --data-template from stdin: %w", err)
+		}
+		return string(data), nil
+	case strings.HasPrefix(source, "@"):
+		data, err := os.ReadFile(strings.TrimPrefix(source, "@"))
+		if err != nil {
+			return "", fmt.Errorf("read --data-template: %w", err)
+		}
+		return string(data), nil
+	default:
+		return source, nil
+	}
+}
+
+// renderDataTemplate executes text as a Go text/template and checks that the
+// result is valid JSON before anything is dialed.
+func renderDataTemplate(text string) (string, error) {
+	tmpl, err := template.New("data").Funcs(dataTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse --data-template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return "", fmt.Errorf("render --data-template: %w", err)
+	}
+
+	rendered := strings.TrimSpace(buf.String())
+	if !json.Valid([]byte(rendered)) {
+		return "", fmt.Errorf("rendered --data-template is not valid JSON: %s", rendered)
+	}
+	return rendered, nil
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyDataTemplateRendersFunctions(t *testing.T) {
+	t.Setenv("OP_TEST_CLADE", "deterministic")
+
+	args, err := applyDataTemplate([]string{
+		"CreateIdentity",
+		"--data-template", `{"id":"{{uuid}}","born":"{{now}}","clade":"{{env "OP_TEST_CLADE"}}"}`,
+	})
+	if err != nil {
+		t.Fatalf("applyDataTemplate returned error: %v", err)
+	}
+	if len(args) != 2 || args[0] != "CreateIdentity" {
+		t.Fatalf("args = %q, want [CreateIdentity <json>]", args)
+	}
+
+	var payload map[string]string
+	if err := json.Unmarshal([]byte(args[1]), &payload); err != nil {
+		t.Fatalf("rendered payload is not JSON: %v", err)
+	}
+	if len(payload["id"]) != 36 || payload["born"] == "" || payload["clade"] != "deterministic" {
+		t.Fatalf("rendered payload = %v", payload)
+	}
+}
+
+func TestApplyDataTemplateReadsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "body.tmpl")
+	if err := os.WriteFile(path, []byte(`{"uuid":"{{env "OP_TEST_UUID"}}"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OP_TEST_UUID", "abc")
+
+	args, err := applyDataTemplate([]string{"show", "--data-template=@" + path})
+	if err != nil {
+		t.Fatalf("applyDataTemplate returned error: %v", err)
+	}
+	if got := strings.Join(args, " "); got != `show {"uuid":"abc"}` {
+		t.Fatalf("args = %q", got)
+	}
+}
+
+func TestApplyDataTemplateRejectsInvalidJSON(t *testing.T) {
+	_, err := applyDataTemplate([]string{"CreateIdentity", "--data-template", `{"born":{{now}}}`})
+	if err == nil || !strings.Contains(err.Error(), "not valid JSON") {
+		t.Fatalf("applyDataTemplate error = %v, want invalid JSON error", err)
+	}
+
+	_, err = applyDataTemplate([]string{"CreateIdentity", `{}`, "--data-template", `{}`})
+	if err == nil {
+		t.Fatal("applyDataTemplate accepted both a template and a JSON argument")
+	}
+}
+
+func TestApplyDataTemplateJSONEscapesValues(t *testing.T) {
+	t.Setenv("OP_TEST_MOTTO", `say "hi" \ bye`)
+
+	args, err := applyDataTemplate([]string{"CreateIdentity", "--data-template", `{"motto":{{env "OP_TEST_MOTTO" | json}}}`})
+	if err != nil {
+		t.Fatalf("applyDataTemplate returned error: %v", err)
+	}
+	var payload map[string]string
+	if err := json.Unmarshal([]byte(args[1]), &payload); err != nil || payload["motto"] != `say "hi" \ bye` {
+		t.Fatalf("rendered payload = %s (%v)", args[1], err)
+	}
+}
+
+func TestApplyDataTemplateNeedsAMethod(t *testing.T) {
+	if _, err := applyDataTemplate([]string{"--data-template", `{}`}); err == nil || !strings.Contains(err.Error(), "needs a method") {
+		t.Fatalf("applyDataTemplate error = %v, want a missing-method error", err)
+	}
+}
+
+func TestApplyDataTemplateWithoutFlagKeepsArgs(t *testing.T) {
+	args, err := applyDataTemplate([]string{"list", "/tmp"})
+	if err != nil {
+		t.Fatalf("applyDataTemplate returned error: %v", err)
+	}
+	if strings.Join(args, " ") != "list /tmp" {
+		t.Fatalf("args = %q", args)
+	}
+}
@@ -0,0 +1,318 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+)
+
+// keepaliveFlags holds --keepalive-time and --keepalive-timeout.
+type keepaliveFlags struct {
+	Time    time.Duration
+	Timeout time.Duration
+}
+
+// extractKeepaliveFlags removes --keepalive-time and --keepalive-timeout
+// from args.
+func extractKeepaliveFlags(args []string) (keepaliveFlags, []string, error) {
+	var flags keepaliveFlags
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		var target *time.Duration
+		switch name {
+		case "--keepalive-time":
+			target = &flags.Time
+		case "--keepalive-timeout":
+			target = &flags.Timeout
+		default:
+			remaining = append(remaining, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return flags, nil, fmt.Errorf("%s requires a duration", name)
+			}
+			value = args[i+1]
+			i++
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return flags, nil, fmt.Errorf("invalid %s %q", name, value)
+		}
+		*target = parsed
+	}
+	if flags.Timeout > 0 && flags.Time == 0 {
+		return flags, nil, fmt.Errorf("--keepalive-timeout requires --keepalive-time")
+	}
+	return flags, remaining, nil
+}
+
+// pinKeepalive turns keepalive pings on for every connection this
+// invocation opens. release restores the parameters set before.
+func pinKeepalive(flags keepaliveFlags) func() {
+	if flags.Time == 0 {
+		return func() {}
+	}
+	return grpcclient.SetKeepalive(flags.Time, flags.Timeout)
+}
+
+// envReflectProto is the fallback for --reflect-proto.
+const envReflectProto = "OP_REFLECT_PROTO"
+
+// envReflectProtocol reads OP_REFLECT_PROTO; empty when unset.
+func envReflectProtocol() (grpcclient.ReflectProtocol, error) {
+	value, ok := lookupNonEmptyEnv(envReflectProto)
+	if !ok {
+		return "", nil
+	}
+	parsed, err := grpcclient.ParseReflectProtocol(value)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", envReflectProto, err)
+	}
+	return parsed, nil
+}
+
+// pinReflectProtocol makes every reflection stream this invocation opens
+// use p. release restores the protocol set before.
+func pinReflectProtocol(p grpcclient.ReflectProtocol) func() {
+	if p == "" {
+		return func() {}
+	}
+	return grpcclient.SetReflectProtocol(p)
+}
+
+// extractDescriptorSetFlags removes every --descriptor-set from args and
+// returns the files in order.
+func extractDescriptorSetFlags(args []string) ([]string, []string, error) {
+	var paths []string
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--descriptor-set":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return nil, nil, fmt.Errorf("--descriptor-set requires a file")
+			}
+			paths = append(paths, args[i+1])
+			i++
+		case strings.HasPrefix(args[i], "--descriptor-set="):
+			path := strings.TrimPrefix(args[i], "--descriptor-set=")
+			if strings.TrimSpace(path) == "" {
+				return nil, nil, fmt.Errorf("--descriptor-set requires a file")
+			}
+			paths = append(paths, path)
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+	return paths, remaining, nil
+}
+
+// pinDescriptorSets loads the descriptor sets in paths so calls this
+// invocation makes resolve their services without reflection. release
+// restores the services set before.
+func pinDescriptorSets(paths []string) (func(), error) {
+	if len(paths) == 0 {
+		return func() {}, nil
+	}
+	services, err := grpcclient.LoadDescriptorSets(paths...)
+	if err != nil {
+		return func() {}, fmt.Errorf("--descriptor-set: %w", err)
+	}
+	return grpcclient.SetDescriptorServices(services), nil
+}
+
+// parseByteSize reads a positive size in bytes, with an optional KiB or MiB
+// suffix (K and M are accepted too).
+func parseByteSize(value string) (int, error) {
+	text := strings.TrimSpace(value)
+	unit := 1
+	for _, suffix := range []struct {
+		name string
+		size int
+	}{{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"K", 1 << 10}, {"M", 1 << 20}} {
+		if trimmed, ok := strings.CutSuffix(text, suffix.name); ok {
+			text, unit = trimmed, suffix.size
+			break
+		}
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || n <= 0 || n > math.MaxInt/unit {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return n * unit, nil
+}
+
+// pinMaxInputSize bounds the request JSON every call of this invocation
+// accepts. release restores the limit set before.
+func pinMaxInputSize(n int) func() {
+	if n <= 0 {
+		return func() {}
+	}
+	return grpcclient.SetMaxInputSize(n)
+}
+
+// tlsFlags holds --cacert, --cert and --key for grpcs:// addresses.
+type tlsFlags struct {
+	CACert string
+	Cert   string
+	Key    string
+}
+
+func (f tlsFlags) set() bool {
+	return f.CACert != "" || f.Cert != "" || f.Key != ""
+}
+
+// extractTLSFlags removes --cacert, --cert and --key from args.
+func extractTLSFlags(args []string) (tlsFlags, []string, error) {
+	var flags tlsFlags
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		var target *string
+		switch name {
+		case "--cacert":
+			target = &flags.CACert
+		case "--cert":
+			target = &flags.Cert
+		case "--key":
+			target = &flags.Key
+		default:
+			remaining = append(remaining, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return tlsFlags{}, nil, fmt.Errorf("%s requires a file", name)
+			}
+			value = args[i+1]
+			i++
+		}
+		if strings.TrimSpace(value) == "" {
+			return tlsFlags{}, nil, fmt.Errorf("%s requires a file", name)
+		}
+		*target = value
+	}
+	return flags, remaining, nil
+}
+
+// extractCodecFlag removes --codec from args and returns its value.
+func extractCodecFlag(args []string) (string, []string, error) {
+	name := ""
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--codec":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return "", nil, fmt.Errorf("--codec requires a name")
+			}
+			name = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--codec="):
+			name = strings.TrimPrefix(args[i], "--codec=")
+			if strings.TrimSpace(name) == "" {
+				return "", nil, fmt.Errorf("--codec requires a name")
+			}
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+	return name, remaining, nil
+}
+
+// pinCodec makes every method call this invocation makes use name as its
+// content-subtype. release restores the codec set before.
+func pinCodec(name string) (func(), error) {
+	if name == "" {
+		return func() {}, nil
+	}
+	restore, err := grpcclient.SetContentSubtype(name)
+	if err != nil {
+		return func() {}, fmt.Errorf("--codec: %w", err)
+	}
+	return restore, nil
+}
+
+// extractCompressFlag removes --compress from args and returns its value.
+func extractCompressFlag(args []string) (string, []string, error) {
+	name := ""
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--compress":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return "", nil, fmt.Errorf("--compress requires a compressor (gzip)")
+			}
+			name = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--compress="):
+			name = strings.TrimPrefix(args[i], "--compress=")
+			if strings.TrimSpace(name) == "" {
+				return "", nil, fmt.Errorf("--compress requires a compressor (gzip)")
+			}
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+	return name, remaining, nil
+}
+
+// pinCompressor makes every method call this invocation makes compress its
+// messages with name. release restores the compressor set before.
+func pinCompressor(name string) (func(), error) {
+	if name == "" {
+		return func() {}, nil
+	}
+	restore, err := grpcclient.SetCompressor(name)
+	if err != nil {
+		return func() {}, fmt.Errorf("--compress: %w", err)
+	}
+	return restore, nil
+}
+
+// extractBytesOutFlag removes --bytes-out from args and returns its value.
+func extractBytesOutFlag(args []string) (string, []string, error) {
+	dir := ""
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--bytes-out":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return "", nil, fmt.Errorf("--bytes-out requires a directory")
+			}
+			dir = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--bytes-out="):
+			dir = strings.TrimPrefix(args[i], "--bytes-out=")
+			if strings.TrimSpace(dir) == "" {
+				return "", nil, fmt.Errorf("--bytes-out requires a directory")
+			}
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+	return dir, remaining, nil
+}
+
+// pinBytesFiles lets the calls this invocation makes read bytes fields
+// given as "@file" when readFiles is set, from --bytes-in, and with a
+// non-empty dir write the bytes fields of their responses to files in dir.
+// release restores the settings before.
+func pinBytesFiles(readFiles bool, dir string) func() {
+	restoreReferences := func() {}
+	if readFiles {
+		restoreReferences = grpcclient.SetFileReferences(true)
+	}
+	restoreDir := func() {}
+	if dir != "" {
+		restoreDir = grpcclient.SetBytesOutputDir(dir)
+	}
+	return func() {
+		restoreDir()
+		restoreReferences()
+	}
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+)
+
+func TestExtractKeepaliveFlags(t *testing.T) {
+	flags, rest, err := extractKeepaliveFlags([]string{"Watch", "--keepalive-time", "30s", "--keepalive-timeout=10s", "{}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flags.Time != 30*time.Second || flags.Timeout != 10*time.Second {
+		t.Fatalf("flags = %+v", flags)
+	}
+	if strings.Join(rest, " ") != "Watch {}" {
+		t.Fatalf("remaining args = %q", rest)
+	}
+
+	if _, _, err := extractKeepaliveFlags([]string{"--keepalive-timeout", "10s"}); err == nil {
+		t.Fatal("--keepalive-timeout without --keepalive-time should fail")
+	}
+	if _, _, err := extractKeepaliveFlags([]string{"--keepalive-time", "soon"}); err == nil {
+		t.Fatal("an invalid duration should fail")
+	}
+}
+
+func TestExtractDescriptorSetFlagsKeepsEveryFile(t *testing.T) {
+	paths, rest, err := extractDescriptorSetFlags([]string{"Check", "--descriptor-set", "a.pb.gz", "--descriptor-set=b.pb", "{}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(paths, ",") != "a.pb.gz,b.pb" {
+		t.Fatalf("paths = %q", paths)
+	}
+	if strings.Join(rest, " ") != "Check {}" {
+		t.Fatalf("remaining args = %q", rest)
+	}
+
+	if _, _, err := extractDescriptorSetFlags([]string{"--descriptor-set"}); err == nil {
+		t.Fatal("--descriptor-set without a file should fail")
+	}
+	release, err := pinDescriptorSets([]string{"missing.pb"})
+	release()
+	if err == nil || !strings.Contains(err.Error(), "--descriptor-set") {
+		t.Fatalf("error = %v, want a --descriptor-set load failure", err)
+	}
+}
+
+func TestExtractBytesOutFlag(t *testing.T) {
+	dir, rest, err := extractBytesOutFlag([]string{"Render", "--bytes-out", "out", "{}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir != "out" || strings.Join(rest, " ") != "Render {}" {
+		t.Fatalf("dir = %q, remaining args = %q", dir, rest)
+	}
+	if dir, _, err := extractBytesOutFlag([]string{"--bytes-out=frames"}); err != nil || dir != "frames" {
+		t.Fatalf("dir = %q, error = %v", dir, err)
+	}
+	if _, _, err := extractBytesOutFlag([]string{"--bytes-out"}); err == nil {
+		t.Fatal("--bytes-out without a directory should fail")
+	}
+}
+
+func TestNestedPinsRestoreTheOuterSetting(t *testing.T) {
+	releaseOuter := pinMaxInputSize(64)
+	defer releaseOuter()
+
+	releaseInner := pinMaxInputSize(16)
+	if got := grpcclient.MaxInputSize(); got != 16 {
+		t.Fatalf("MaxInputSize() = %d inside the inner pin, want 16", got)
+	}
+	releaseInner()
+	if got := grpcclient.MaxInputSize(); got != 64 {
+		t.Fatalf("MaxInputSize() = %d after the inner release, want the outer 64", got)
+	}
+
+	releaseOuter()
+	if got := grpcclient.MaxInputSize(); got != grpcclient.DefaultMaxInputSize {
+		t.Fatalf("MaxInputSize() = %d after both releases, want the default", got)
+	}
+}
+
+func TestPinBytesFilesReadsFilesOnlyWithBytesIn(t *testing.T) {
+	release := pinBytesFiles(false, "out")
+	if grpcclient.FileReferences() {
+		t.Fatal("file references are read without --bytes-in")
+	}
+	release()
+
+	release = pinBytesFiles(true, "")
+	defer release()
+	if !grpcclient.FileReferences() {
+		t.Fatal("--bytes-in did not enable file references")
+	}
+}
+
+func TestNestedCompressorPinKeepsTheOuterCompressor(t *testing.T) {
+	releaseOuter, err := pinCompressor("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer releaseOuter()
+
+	releaseInner, err := pinCompressor("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	releaseInner()
+	if got := len(grpcclient.CallOptions()); got != 1 {
+		t.Fatalf("CallOptions() has %d options after the inner release, want the outer gzip", got)
+	}
+
+	releaseOuter()
+	if got := len(grpcclient.CallOptions()); got != 0 {
+		t.Fatalf("CallOptions() has %d options after both releases, want none", got)
+	}
+}
+
+func TestHolonCallAcceptsCompress(t *testing.T) {
+	var code int
+	stderr := captureStderr(t, func() {
+		code = Run([]string{"some-holon", "show", "--compress", "zstd"}, "0.1.0-test")
+	})
+	if code != 1 || !strings.Contains(stderr, `--compress: unsupported compressor "zstd"`) {
+		t.Fatalf("op <holon> --compress zstd = %d, stderr %q, want the compressor rejected", code, stderr)
+	}
+}
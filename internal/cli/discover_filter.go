@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/organic-programming/grace-op/internal/holons"
+)
+
+// discoverFilter narrows `op discover` entries. The zero value keeps
+// everything.
+type discoverFilter struct {
+	Since         time.Time
+	Until         time.Time
+	IncludeUnborn bool
+	// Identity holds --lang, --clade and --status.
+	Identity holons.IdentityFilter
+}
+
+func (f discoverFilter) active() bool {
+	return f.bornActive() || f.Identity.Active()
+}
+
+func (f discoverFilter) bornActive() bool {
+	return !f.Since.IsZero() || !f.Until.IsZero()
+}
+
+// match reports whether entry passes the identity filter and falls inside
+// the Born date window. Entries with a missing or malformed Born date only
+// match a window when IncludeUnborn is set.
+func (f discoverFilter) match(entry discoverEntry) bool {
+	if !f.Identity.Match(entry.Lang, entry.Clade, entry.Status) {
+		return false
+	}
+	if !f.bornActive() {
+		return true
+	}
+	born, err := parseBornDate(entry.Born)
+	if err != nil {
+		return f.IncludeUnborn
+	}
+	if !f.Since.IsZero() && born.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && born.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+func (f discoverFilter) apply(entries []discoverEntry) []discoverEntry {
+	if !f.active() {
+		return entries
+	}
+	kept := make([]discoverEntry, 0, len(entries))
+	for _, entry := range entries {
+		if f.match(entry) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+func parseDiscoverArgs(args []string) (discoverFilter, error) {
+	var filter discoverFilter
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--since", "--until":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return filter, fmt.Errorf("%s requires a date (YYYY-MM-DD)", name)
+				}
+				value = args[i+1]
+				i++
+			}
+			date, err := parseBornDate(value)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s %q (want YYYY-MM-DD)", name, value)
+			}
+			if name == "--since" {
+				filter.Since = date
+			} else {
+				filter.Until = date
+			}
+		case "--lang", "--clade", "--status":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return filter, fmt.Errorf("%s requires a value", name)
+				}
+				value = args[i+1]
+				i++
+			}
+			values := splitFilterValues(value)
+			if len(values) == 0 {
+				return filter, fmt.Errorf("%s requires a value", name)
+			}
+			switch name {
+			case "--lang":
+				filter.Identity.Langs = append(filter.Identity.Langs, values...)
+			case "--clade":
+				filter.Identity.Clades = append(filter.Identity.Clades, values...)
+			default:
+				filter.Identity.Statuses = append(filter.Identity.Statuses, values...)
+			}
+		case "--include-unborn":
+			if hasValue {
+				return filter, fmt.Errorf("--include-unborn does not take a value")
+			}
+			filter.IncludeUnborn = true
+		default:
+			return filter, fmt.Errorf("unknown argument %q", args[i])
+		}
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && filter.Until.Before(filter.Since) {
+		return filter, fmt.Errorf("--until is before --since")
+	}
+	return filter, nil
+}
+
+// extractDiscoverScanFlags removes every --path and --max-depth from args.
+// Each --path must be a directory.
+func extractDiscoverScanFlags(args []string) (holons.ScanOptions, []string, error) {
+	var scan holons.ScanOptions
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		if name != "--path" && name != "--max-depth" {
+			remaining = append(remaining, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return scan, nil, fmt.Errorf("%s requires a value", name)
+			}
+			value = args[i+1]
+			i++
+		}
+		if name == "--max-depth" {
+			depth, err := strconv.Atoi(value)
+			if err != nil || depth < 1 {
+				return scan, nil, fmt.Errorf("invalid --max-depth %q (want a positive integer)", value)
+			}
+			scan.MaxDepth = depth
+			continue
+		}
+		info, err := os.Stat(value)
+		if err != nil {
+			return scan, nil, fmt.Errorf("--path %s: %w", value, err)
+		}
+		if !info.IsDir() {
+			return scan, nil, fmt.Errorf("--path %s: not a directory", value)
+		}
+		scan.Roots = append(scan.Roots, value)
+	}
+	return scan, remaining, nil
+}
+
+// splitFilterValues splits a comma-separated --lang, --clade or --status
+// value, dropping empty items.
+func splitFilterValues(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// parseBornDate accepts the YYYY-MM-DD form holon.yaml uses, as well as a
+// full RFC 3339 timestamp, and truncates to the day.
+func parseBornDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+}
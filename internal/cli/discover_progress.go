@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/organic-programming/grace-op/internal/holons"
+	"github.com/organic-programming/grace-op/internal/progress"
+)
+
+// discoverProgressInterval is the least time between two in-flight progress
+// lines of op discover --verbose. Completions are always reported.
+const discoverProgressInterval = 200 * time.Millisecond
+
+// discoverProgress returns a holons.ScanProgress callback for op discover
+// --verbose. It reports on stderr so stdout stays unchanged for pipes.
+func discoverProgress() func(holons.ScanProgress) {
+	printer := progress.New(os.Stderr)
+	var last time.Time
+	return func(p holons.ScanProgress) {
+		if p.Done {
+			printer.Done(formatScanProgress("scanned", p), nil)
+			last = time.Time{}
+			return
+		}
+		if now := time.Now(); last.IsZero() || now.Sub(last) >= discoverProgressInterval {
+			last = now
+			printer.Step(formatScanProgress("scanning", p))
+		}
+	}
+}
+
+func formatScanProgress(verb string, p holons.ScanProgress) string {
+	return fmt.Sprintf("%s %s: %d dirs, %d holons found", verb, p.Origin, p.Dirs, p.Found)
+}
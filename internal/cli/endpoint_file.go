@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+const endpointFileUsage = "usage: op grpc --endpoint-file <path> [--endpoint-timeout <duration>] [<method> [json]]"
+
+// defaultEndpointWait is how long op grpc --endpoint-file waits for a
+// server that is still starting to write its address.
+const defaultEndpointWait = 5 * time.Second
+
+// endpointPollInterval is how often the endpoint file is checked while
+// waiting for it.
+const endpointPollInterval = 50 * time.Millisecond
+
+// cmdGRPCEndpointFile runs `op grpc --endpoint-file <path> <method>`: it
+// waits for path to hold an address, then dispatches to it as if the
+// address had been given as a URI.
+func cmdGRPCEndpointFile(format Format, args []string) int {
+	path, wait, args, err := parseEndpointFileArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op grpc: %v\n", err)
+		fmt.Fprintln(os.Stderr, endpointFileUsage)
+		return 1
+	}
+	uri, err := waitForEndpointFile(path, wait)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op grpc: %v\n", err)
+		return 1
+	}
+	return cmdGRPC(format, uri, args)
+}
+
+func parseEndpointFileArgs(args []string) (string, time.Duration, []string, error) {
+	path := ""
+	wait := defaultEndpointWait
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		if name != "--endpoint-file" && name != "--endpoint-timeout" {
+			remaining = append(remaining, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return "", 0, nil, fmt.Errorf("%s requires a value", name)
+			}
+			value = args[i+1]
+			i++
+		}
+		if name == "--endpoint-file" {
+			if strings.TrimSpace(value) == "" {
+				return "", 0, nil, fmt.Errorf("--endpoint-file requires a path")
+			}
+			path = value
+			continue
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			return "", 0, nil, fmt.Errorf("invalid --endpoint-timeout %q", value)
+		}
+		wait = parsed
+	}
+	if path == "" {
+		return "", 0, nil, fmt.Errorf("--endpoint-file is required")
+	}
+	return path, wait, remaining, nil
+}
+
+// waitForEndpointFile polls path until it holds an address or wait runs
+// out. A file that exists but has no complete line yet counts as not
+// written yet, so a writer that does not rename into place is never read
+// mid-address.
+func waitForEndpointFile(path string, wait time.Duration) (string, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		if line := firstCompleteLine(string(data)); line != "" {
+			return endpointURI(line)
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return "", fmt.Errorf("%s did not appear within %s", path, wait)
+			}
+			return "", fmt.Errorf("%s holds no complete address line after %s", path, wait)
+		}
+		time.Sleep(endpointPollInterval)
+	}
+}
+
+// firstCompleteLine returns the first non-blank line of text that ends
+// with a newline; a trailing line without one may still be growing.
+func firstCompleteLine(text string) string {
+	end := strings.LastIndexByte(text, '\n')
+	if end < 0 {
+		return ""
+	}
+	for _, line := range strings.Split(text[:end], "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// writeAddrFile records the listen URI a server is bound to in path, as
+// one newline-terminated line written through a rename, for op grpc
+// --endpoint-file and scripts to read. A wildcard host is written as the
+// loopback address clients dial.
+func writeAddrFile(path, listenURI string) error {
+	if network, address, err := listenDialTarget(listenURI); err == nil {
+		listenURI = network + "://" + address
+	}
+	if err := writeFileAtomic(path, []byte(listenURI+"\n")); err != nil {
+		return fmt.Errorf("--addr-file: %w", err)
+	}
+	return nil
+}
+
+// endpointURI turns an address read from an endpoint file into a URI op
+// dispatches: grpc URIs are kept, listen URIs (tcp://, unix://, ws://,
+// wss://) are mapped to their grpc counterpart, and a bare host:port means
+// grpc://.
+func endpointURI(address string) (string, error) {
+	if strings.HasPrefix(address, "grpc://") || strings.HasPrefix(address, "grpc+") {
+		return address, nil
+	}
+	for _, scheme := range []string{"tcp", "unix", "ws", "wss"} {
+		if rest, ok := strings.CutPrefix(address, scheme+"://"); ok {
+			if scheme == "tcp" {
+				return "grpc://" + rest, nil
+			}
+			return "grpc+" + scheme + "://" + rest, nil
+		}
+	}
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return "grpc://" + address, nil
+	}
+	return "", fmt.Errorf("endpoint file holds %q, not an address (want host:port or a grpc://, tcp://, unix:// or ws:// URI)", address)
+}
+
+// hasEndpointMethod reports whether `op grpc` args name a method besides
+// the endpoint flags.
+func hasEndpointMethod(args []string) bool {
+	_, _, rest, err := parseEndpointFileArgs(args)
+	return err == nil && len(rest) > 0
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEndpointURIMapsListenAddresses(t *testing.T) {
+	for address, want := range map[string]string{
+		"127.0.0.1:4321":         "grpc://127.0.0.1:4321",
+		"tcp://127.0.0.1:4321":   "grpc://127.0.0.1:4321",
+		"unix:///tmp/op.sock":    "grpc+unix:///tmp/op.sock",
+		"ws://127.0.0.1:80/grpc": "grpc+ws://127.0.0.1:80/grpc",
+		"grpc+wss://example:443": "grpc+wss://example:443",
+	} {
+		if got, err := endpointURI(address); err != nil || got != want {
+			t.Fatalf("endpointURI(%q) = %q, %v; want %q", address, got, err, want)
+		}
+	}
+	if _, err := endpointURI("starting..."); err == nil {
+		t.Fatal("a line that is not an address should be rejected")
+	}
+}
+
+func TestGRPCEndpointFileWaitsForTheAddress(t *testing.T) {
+	address := startSlowHealthServer(t, 0)
+	path := filepath.Join(t.TempDir(), "addr")
+
+	// The server creates the file before it knows its port, as a writer
+	// that truncates first would.
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = os.WriteFile(path, nil, 0o644)
+		time.Sleep(100 * time.Millisecond)
+		_ = os.WriteFile(path, []byte("tcp://"+address+"\n"), 0o644)
+	}()
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"grpc", "--endpoint-file", path, "Check"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("op grpc --endpoint-file returned %d, want 0", code)
+		}
+	})
+	if !strings.Contains(stdout, "SERVING") {
+		t.Fatalf("stdout = %q", stdout)
+	}
+
+	missing := filepath.Join(t.TempDir(), "never")
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"grpc", "--endpoint-file=" + missing, "--endpoint-timeout", "100ms", "Check"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("missing endpoint file returned %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "did not appear within 100ms") {
+		t.Fatalf("stderr = %q", stderr)
+	}
+}
+
+func TestWaitForEndpointFileNeedsACompleteLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "addr")
+	if err := os.WriteFile(path, []byte("127.0.0.1:43"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := waitForEndpointFile(path, 100*time.Millisecond); err == nil || !strings.Contains(err.Error(), "no complete address line") {
+		t.Fatalf("a line without its newline was read: %v", err)
+	}
+
+	if err := writeAddrFile(path, "tcp://[::]:4321"); err != nil {
+		t.Fatal(err)
+	}
+	uri, err := waitForEndpointFile(path, 100*time.Millisecond)
+	if err != nil || uri != "grpc://127.0.0.1:4321" {
+		t.Fatalf("waitForEndpointFile = %q, %v; want the loopback address writeAddrFile wrote", uri, err)
+	}
+}
@@ -0,0 +1,325 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	openv "github.com/organic-programming/grace-op/internal/env"
+)
+
+// endpointProbeTimeout bounds the dial that checks a registered endpoint
+// still answers before the transport chain picks it.
+const endpointProbeTimeout = 200 * time.Millisecond
+
+// endpointLockWait bounds how long a state file update waits for another
+// op process's lock; endpointLockStale is the age past which a lock is
+// taken to be left by a process that died holding it.
+const (
+	endpointLockWait  = 2 * time.Second
+	endpointLockStale = 10 * time.Second
+)
+
+// opServeHolon is the name op serve registers its listener under.
+const opServeHolon = "op"
+
+// runningEndpoint is where a holon launched by op run or op serve listens,
+// and the process listening there.
+type runningEndpoint struct {
+	Holon string `json:"holon"`
+	URI   string `json:"uri"`
+	PID   int    `json:"pid"`
+}
+
+// runStateDir is .holon/run under the workspace root, op's working
+// directory: each holon op run or op serve started from there has a
+// <holon>.json in it, and only calls made from the same workspace find it.
+func runStateDir() string {
+	return filepath.Join(openv.Root(), ".holon", "run")
+}
+
+// endpointPath is the state file of holonName.
+func endpointPath(holonName string) string {
+	return filepath.Join(runStateDir(), url.PathEscape(endpointKey(holonName))+".json")
+}
+
+func endpointKey(holonName string) string {
+	return strings.ToLower(strings.TrimSpace(holonName))
+}
+
+// loadEndpoint reads holonName's state file. A missing or unreadable file
+// means the holon is not tracked.
+func loadEndpoint(holonName string) (runningEndpoint, bool) {
+	var entry runningEndpoint
+	data, err := os.ReadFile(endpointPath(holonName))
+	if err != nil || json.Unmarshal(data, &entry) != nil || entry.PID <= 0 {
+		return runningEndpoint{}, false
+	}
+	return entry, true
+}
+
+// loadEndpoints reads every state file, keyed by lower-cased holon name.
+func loadEndpoints() map[string]runningEndpoint {
+	endpoints := map[string]runningEndpoint{}
+	files, err := os.ReadDir(runStateDir())
+	if err != nil {
+		return endpoints
+	}
+	for _, file := range files {
+		name, ok := strings.CutSuffix(file.Name(), ".json")
+		if !ok || file.IsDir() {
+			continue
+		}
+		key, err := url.PathUnescape(name)
+		if err != nil {
+			continue
+		}
+		if entry, ok := loadEndpoint(key); ok {
+			endpoints[key] = entry
+		}
+	}
+	return endpoints
+}
+
+// saveEndpoint replaces holonName's state file through a rename, so a
+// concurrent reader never sees half a file.
+func saveEndpoint(holonName string, entry runningEndpoint) error {
+	path := endpointPath(holonName)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so a reader sees either the old file or the whole new one.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// lockEndpoint takes holonName's lock file, so that one op process reading,
+// checking and then writing or removing its state file is not interleaved
+// with another doing the same. A lock older than endpointLockStale was left
+// by a process that died holding it and is broken.
+func lockEndpoint(holonName string) (unlock func(), err error) {
+	path := endpointPath(holonName) + ".lock"
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(endpointLockWait)
+	for {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			_ = file.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > endpointLockStale {
+			_ = os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s still held after %s", path, endpointLockWait)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// recordEndpoint registers uri as where holonName, run by pid, listens.
+// The registry is a cache: failing to write it only costs later calls a
+// fresh launch, so errors are reported but never fail the command.
+func recordEndpoint(holonName, uri string, pid int) {
+	unlock, err := lockEndpoint(holonName)
+	if err == nil {
+		defer unlock()
+		err = saveEndpoint(holonName, runningEndpoint{Holon: strings.TrimSpace(holonName), URI: uri, PID: pid})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op: record endpoint of %s: %v\n", holonName, err)
+	}
+}
+
+// forgetEndpoint removes holonName's state file if pid still owns it; a
+// newer launch of the same holon keeps its own.
+func forgetEndpoint(holonName string, pid int) {
+	unlock, err := lockEndpoint(holonName)
+	if err != nil {
+		return
+	}
+	defer unlock()
+	entry, ok := loadEndpoint(holonName)
+	if !ok || entry.PID != pid {
+		return
+	}
+	_ = os.Remove(endpointPath(holonName))
+}
+
+// recordEndpointWhenReady registers listenURI for holonName once pid
+// accepts connections on it. The returned func stops waiting and removes
+// the entry; call it when the process exits. Listeners without a fixed
+// network address (stdio://, port 0) are not registered.
+func recordEndpointWhenReady(holonName, listenURI string, pid int) func() {
+	if !isNetworkListenURI(listenURI) || hasEphemeralPort(listenURI) {
+		return func() {}
+	}
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for {
+			if dialListenURI(listenURI) == nil {
+				recordEndpoint(holonName, listenURI, pid)
+				return
+			}
+			select {
+			case <-done:
+				return
+			case <-time.After(runWaitPollInterval):
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+		forgetEndpoint(holonName, pid)
+	}
+}
+
+// liveEndpoint returns the registered endpoint of holonName when its
+// process is alive and it accepts a connection. An entry is pruned only
+// once it is certainly stale: its process is gone, or nothing listens on
+// its endpoint any more. A probe that merely timed out, as a busy holon's
+// may, leaves it for the next call.
+func liveEndpoint(holonName string) (runningEndpoint, bool) {
+	entry, ok := loadEndpoint(holonName)
+	if !ok {
+		return runningEndpoint{}, false
+	}
+	if !processAlive(entry.PID) {
+		forgetEndpoint(holonName, entry.PID)
+		return runningEndpoint{}, false
+	}
+	err := probeEndpoint(entry.URI)
+	if err == nil {
+		return entry, true
+	}
+	if endpointGone(err) {
+		forgetEndpoint(holonName, entry.PID)
+	}
+	return runningEndpoint{}, false
+}
+
+// endpointGone reports whether a failed probe proves nothing listens on the
+// endpoint: the connection was refused or the socket file is missing.
+func endpointGone(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ENOENT) || errors.Is(err, os.ErrNotExist)
+}
+
+func probeEndpoint(uri string) error {
+	network, address, err := listenDialTarget(uri)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialTimeout(network, address, endpointProbeTimeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// processAlive reports whether pid exists. Windows cannot probe a process
+// with signal 0, so there only the dial decides.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// boundListenURI is the URI clients dial to reach a listener opened for
+// listenURI at addr, with a tcp:// port 0 resolved. Other transports have
+// no address to register and yield "".
+func boundListenURI(listenURI string, addr net.Addr) string {
+	switch {
+	case strings.HasPrefix(listenURI, "tcp://"):
+		return "tcp://" + addr.String()
+	case strings.HasPrefix(listenURI, "unix://"):
+		return listenURI
+	default:
+		return ""
+	}
+}
+
+// endpointDialAddress converts a registered listen URI into the address
+// grpcclient dials: host:port, or unix://path.
+func endpointDialAddress(uri string) (string, error) {
+	network, address, err := listenDialTarget(uri)
+	if err != nil {
+		return "", err
+	}
+	if network == "unix" {
+		return "unix://" + address, nil
+	}
+	return address, nil
+}
+
+// runningCandidate is the transport chain's first candidate: the endpoint
+// of an instance of holonName that is already running. ok is false when
+// none is registered or the registered one is gone.
+func runningCandidate(holonName string) (TransportCandidate, bool) {
+	entry, ok := liveEndpoint(holonName)
+	if !ok {
+		return TransportCandidate{}, false
+	}
+	scheme := "tcp"
+	if strings.HasPrefix(entry.URI, "unix://") {
+		scheme = "unix"
+	}
+	return TransportCandidate{
+		Scheme:    scheme,
+		Available: true,
+		Endpoint:  entry.URI,
+		Reason:    fmt.Sprintf("already running (pid %d); dialing its endpoint", entry.PID),
+	}, true
+}
+
+// isRunningEndpoint reports whether a decision dials a running instance
+// rather than launching one; only running endpoints are network URIs.
+func isRunningEndpoint(decision TransportDecision) bool {
+	return isNetworkListenURI(decision.Endpoint)
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestTransportChainDialsRunningHolon(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "beta", binaryName: "beta", givenName: "beta", familyName: "Holon", aliases: []string{"beta"}, lang: "go"})
+
+	address := startOPServer(t)
+	recordEndpoint("Beta", "tcp://"+address, os.Getpid())
+
+	decision, err := selectTransportWithReason("beta", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Scheme != "tcp" || decision.Endpoint != "tcp://"+address || !isRunningEndpoint(decision) {
+		t.Fatalf("decision = %+v, want the running endpoint", decision)
+	}
+	if len(decision.Candidates) != 4 || decision.Candidates[1].Scheme != "mem" {
+		t.Fatalf("candidates = %+v, want the running endpoint ahead of mem, stdio and tcp", decision.Candidates)
+	}
+
+	result, err := callHolon(context.Background(), "beta", "ListIdentities", "{}")
+	if err != nil {
+		t.Fatalf("call through the running endpoint failed: %v", err)
+	}
+	if !strings.Contains(result.Output, `"givenName": "beta"`) {
+		t.Fatalf("output = %q, want the seeded holon listed", result.Output)
+	}
+
+	forced, err := selectTransportWithReason("beta", &TransportOverride{Scheme: "unix", Source: "flag"})
+	if err == nil || !strings.Contains(err.Error(), "transport unix (from --transport) not available") {
+		t.Fatalf("decision = %+v, error = %v; unix should be refused with no unix endpoint", forced, err)
+	}
+}
+
+func TestTransportChainPrunesStaleEndpoints(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+
+	exited := exec.Command(os.Args[0], "-test.run=^$")
+	if err := exited.Run(); err != nil {
+		t.Fatal(err)
+	}
+	address := startOPServer(t)
+	recordEndpoint("gone", "tcp://"+address, exited.Process.Pid)
+	recordEndpoint("closed", "unix://"+root+"/missing.sock", os.Getpid())
+
+	for _, name := range []string{"gone", "closed"} {
+		if _, ok := runningCandidate(name); ok {
+			t.Fatalf("%s: a stale endpoint was offered", name)
+		}
+	}
+	if endpoints := loadEndpoints(); len(endpoints) != 0 {
+		t.Fatalf("registry = %+v, want stale entries pruned", endpoints)
+	}
+}
+
+func TestRecordEndpointWhenReadyForgetsOnExit(t *testing.T) {
+	chdirForTest(t, t.TempDir())
+	address := startOPServer(t)
+
+	forget := recordEndpointWhenReady("beta", "tcp://"+address, os.Getpid())
+	// The endpoint is recorded from the background once a dial succeeds.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok := liveEndpoint("beta"); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("beta was never registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	recordEndpoint("other", "tcp://"+address, os.Getpid()+1)
+	forget()
+	endpoints := loadEndpoints()
+	if _, ok := endpoints["beta"]; ok {
+		t.Fatalf("registry = %+v, want beta forgotten once it exits", endpoints)
+	}
+	if _, ok := endpoints["other"]; !ok {
+		t.Fatalf("registry = %+v, want other entries kept", endpoints)
+	}
+
+	noop := recordEndpointWhenReady("beta", "tcp://127.0.0.1:0", os.Getpid())
+	noop()
+	if _, ok := loadEndpoints()["beta"]; ok {
+		t.Fatal("an ephemeral port should not be registered")
+	}
+}
+
+func TestLiveEndpointKeepsEntryOnProbeTimeout(t *testing.T) {
+	if endpointGone(&net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded}) {
+		t.Fatal("a timed-out probe should not prune the entry")
+	}
+	if !endpointGone(&net.OpError{Op: "dial", Net: "tcp", Err: &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED}}) {
+		t.Fatal("a refused probe should prune the entry")
+	}
+
+	chdirForTest(t, t.TempDir())
+	// A closed listener refuses the probe.
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := lis.Addr().String()
+	_ = lis.Close()
+	recordEndpoint("refused", "tcp://"+address, os.Getpid())
+	if _, ok := liveEndpoint("refused"); ok {
+		t.Fatal("a refused endpoint was offered")
+	}
+	if _, ok := loadEndpoint("refused"); ok {
+		t.Fatal("a refused endpoint was kept")
+	}
+}
+
+func TestEndpointLockSerializesAndBreaksStaleLocks(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+
+	unlock, err := lockEndpoint("beta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := make(chan struct{})
+	go func() {
+		recordEndpoint("beta", "tcp://127.0.0.1:1", os.Getpid())
+		close(recorded)
+	}()
+	select {
+	case <-recorded:
+		t.Fatal("recordEndpoint wrote while another process held the lock")
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlock()
+	<-recorded
+	if _, ok := loadEndpoint("beta"); !ok {
+		t.Fatal("beta was not recorded once the lock was released")
+	}
+
+	// A lock left by a process that died is broken.
+	lock := filepath.Join(root, ".holon", "run", "beta.json.lock")
+	if err := os.WriteFile(lock, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * endpointLockStale)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatal(err)
+	}
+	forgetEndpoint("beta", os.Getpid())
+	if _, ok := loadEndpoint("beta"); ok {
+		t.Fatal("forgetEndpoint did not break the stale lock")
+	}
+}
@@ -2,22 +2,58 @@ package cli
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 
 	openv "github.com/organic-programming/grace-op/internal/env"
+	"github.com/organic-programming/grace-op/internal/holons"
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
 )
 
 type envOutput struct {
-	OPPATH      string `json:"oppath"`
-	OPBIN       string `json:"opbin"`
-	ROOT        string `json:"root"`
-	Initialized bool   `json:"initialized,omitempty"`
-	Shell       string `json:"shell,omitempty"`
+	OPPATH      string       `json:"oppath"`
+	OPBIN       string       `json:"opbin"`
+	ROOT        string       `json:"root"`
+	Initialized bool         `json:"initialized,omitempty"`
+	Shell       string       `json:"shell,omitempty"`
+	Settings    []envSetting `json:"settings,omitempty"`
+	// ConfigError is why .holonconfig, or the manifests aliases come from,
+	// could not be read; the settings they would have set are left out.
+	ConfigError string `json:"config_error,omitempty"`
 }
 
-func cmdEnv(format Format, args []string) int {
+// Where an effective setting came from, weakest first: a later source
+// overrides an earlier one.
+const (
+	sourceDefault = "default"
+	// sourceManifest is a holon's holon.yaml, which aliases come from.
+	sourceManifest      = "manifest"
+	sourceProjectConfig = "project-config"
+	sourceEnv           = "env"
+	sourceFlag          = "flag"
+)
+
+// envSetting is one value op resolved for this invocation, with its source
+// and the variable, flag or file that set it.
+type envSetting struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	Origin string `json:"origin,omitempty"`
+}
+
+// cmdEnv runs `op env`: the runtime paths, then every setting op resolved
+// and where each came from, to debug precedence between defaults,
+// .holonconfig, OP_* variables and global flags.
+func cmdEnv(opts globalOptions, args []string) int {
+	format := opts.Format
 	var (
 		initDirs   bool
 		shell      bool
@@ -60,6 +96,19 @@ func cmdEnv(format Format, args []string) int {
 	if shell {
 		payload.Shell = openv.ShellSnippet()
 	}
+	// A broken .holonconfig is reported with everything else op resolved,
+	// which is when op env is most useful.
+	var configErr error
+	if !initDirs && !shell {
+		payload.Settings, configErr = effectiveSettings(opts)
+		if configErr != nil {
+			payload.ConfigError = configErr.Error()
+		}
+	}
+	code := 0
+	if configErr != nil {
+		code = 1
+	}
 
 	if format == FormatJSON {
 		out, err := json.MarshalIndent(payload, "", "  ")
@@ -68,7 +117,7 @@ func cmdEnv(format Format, args []string) int {
 			return 1
 		}
 		fmt.Println(string(out))
-		return 0
+		return code
 	}
 
 	if shell {
@@ -91,5 +140,137 @@ func cmdEnv(format Format, args []string) int {
 	fmt.Printf("OPPATH=%s\n", payload.OPPATH)
 	fmt.Printf("OPBIN=%s\n", payload.OPBIN)
 	fmt.Printf("ROOT=%s\n", payload.ROOT)
-	return 0
+	fmt.Println()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SETTING\tVALUE\tSOURCE")
+	for _, setting := range payload.Settings {
+		source := setting.Source
+		if setting.Origin != "" {
+			source += " (" + setting.Origin + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", setting.Name, setting.Value, source)
+	}
+	_ = tw.Flush()
+	if configErr != nil {
+		fmt.Fprintf(os.Stderr, "op env: %v\n", configErr)
+	}
+	return code
+}
+
+// effectiveSettings resolves each setting the way the command that uses it
+// does, recording which source won. An error reading .holonconfig leaves
+// its settings out of the others.
+func effectiveSettings(opts globalOptions) ([]envSetting, error) {
+	fromEnv := func(name, key, fallback string) envSetting {
+		if value, ok := lookupNonEmptyEnv(key); ok {
+			return envSetting{Name: name, Value: value, Source: sourceEnv, Origin: key}
+		}
+		return envSetting{Name: name, Value: fallback, Source: sourceDefault}
+	}
+	fromFlag := func(name, flag, value string, fallback envSetting) envSetting {
+		if slices.Contains(opts.Given, flag) {
+			return envSetting{Name: name, Value: value, Source: sourceFlag, Origin: flag}
+		}
+		return fallback
+	}
+
+	oppath := fromEnv("oppath", "OPPATH", openv.OPPATH())
+	oppath.Value = openv.OPPATH()
+	opbin := fromEnv("opbin", "OPBIN", openv.OPBIN())
+	opbin.Value = openv.OPBIN()
+
+	maxInput := grpcclient.DefaultMaxInputSize
+	if opts.MaxInputSize > 0 {
+		maxInput = opts.MaxInputSize
+	}
+	settings := []envSetting{
+		oppath,
+		opbin,
+		{Name: "root", Value: openv.Root(), Source: sourceDefault, Origin: "working directory"},
+		{Name: "roots", Value: strings.Join([]string{openv.Root(), openv.CacheDir()}, ", "), Source: sourceDefault, Origin: "discovery: root and $OPPATH/cache"},
+		fromFlag("format", "--format", string(opts.Format), envSetting{Name: "format", Value: string(FormatText), Source: sourceDefault}),
+		fromFlag("quiet", "--quiet", "true", envSetting{Name: "quiet", Value: "false", Source: sourceDefault}),
+		fromFlag("reflect-proto", "--reflect-proto", string(opts.ReflectProto), fromEnv("reflect-proto", envReflectProto, string(grpcclient.ReflectAuto))),
+		fromFlag("max-input-size", "--max-input-size", strconv.Itoa(maxInput), envSetting{Name: "max-input-size", Value: strconv.Itoa(maxInput), Source: sourceDefault}),
+		fromFlag("timeout", "--timeout", opts.Timeout.String(), envSetting{Name: "timeout", Value: grpcclient.DefaultTimeout.String(), Source: sourceDefault}),
+		fromEnv("transport", envTransport, strings.Join(transportChainOrder, " -> ")),
+		fromEnv("proto-prefixes", grpcclient.EnvProtoPrefixes, strings.Join(grpcclient.ProtoPrefixes(), ",")),
+		fromEnv("serve.listen", envListen, defaultServeListen),
+		fromEnv("run.listen", envListen, defaultRunListen),
+		fromEnv("daemon", envDaemon, daemonModeOff),
+		fromEnv("daemon-socket", envDaemonSocket, daemonSocketPath()),
+	}
+
+	var configErrs []error
+	aliases, err := aliasSettings()
+	if err != nil {
+		configErrs = append(configErrs, err)
+	}
+	settings = append(settings, aliases...)
+
+	config, err := loadHolonConfig(holonConfigFile)
+	if err != nil {
+		configErrs = append(configErrs, err)
+	}
+	timeouts := config.Timeouts
+	methods := make([]string, 0, len(timeouts))
+	for method := range timeouts {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	origin, _ := filepath.Abs(holonConfigFile)
+	for _, method := range methods {
+		settings = append(settings, envSetting{
+			Name:   "timeouts." + method,
+			Value:  timeouts[method].String(),
+			Source: sourceProjectConfig,
+			Origin: origin,
+		})
+	}
+
+	transports := config.Transports
+	holonNames := make([]string, 0, len(transports))
+	for name := range transports {
+		holonNames = append(holonNames, name)
+	}
+	sort.Strings(holonNames)
+	for _, name := range holonNames {
+		settings = append(settings, envSetting{
+			Name:   "transports." + transports[name].Holon,
+			Value:  transportOverrideValue(transports[name]),
+			Source: sourceProjectConfig,
+			Origin: origin,
+		})
+	}
+	return settings, errors.Join(configErrs...)
+}
+
+// aliasSettings lists the aliases of every holon discovery finds, each with
+// the name it dispatches to, as op <alias> resolves them.
+func aliasSettings() ([]envSetting, error) {
+	local, err := holons.DiscoverLocalHolons()
+	if err != nil {
+		return nil, fmt.Errorf("discover aliases: %w", err)
+	}
+	cached, err := holons.DiscoverCachedHolons()
+	if err != nil {
+		return nil, fmt.Errorf("discover aliases: %w", err)
+	}
+	var settings []envSetting
+	seen := make(map[string]bool)
+	for _, h := range append(local, cached...) {
+		name := h.Identity.Slug()
+		if name == "" {
+			name = filepath.Base(h.Dir)
+		}
+		for _, alias := range h.Identity.Aliases {
+			if alias = strings.TrimSpace(alias); alias == "" || seen[alias] {
+				continue
+			}
+			seen[alias] = true
+			settings = append(settings, envSetting{Name: "aliases." + alias, Value: name, Source: sourceManifest, Origin: h.IdentityPath})
+		}
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Name < settings[j].Name })
+	return settings, nil
 }
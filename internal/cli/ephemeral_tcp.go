@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/organic-programming/go-holons/pkg/transport"
+)
+
+// ephemeralLaunchAttempts bounds how often a holon is relaunched on a fresh
+// port when the one picked for it turns out to be taken.
+const ephemeralLaunchAttempts = 3
+
+// ephemeralStderrTail is how much of a launched holon's stderr is kept to
+// explain why it exited.
+const ephemeralStderrTail = 4 << 10
+
+// ephemeralExitGrace is how long a failed call waits for its holon to
+// exit, to tell a holon that lost its port from one that is still running.
+const ephemeralExitGrace = 500 * time.Millisecond
+
+// errEphemeralPortTaken marks a launch worth retrying on another port.
+var errEphemeralPortTaken = errors.New("port taken")
+
+// ephemeralPort picks the port for the next ephemeral launch. The port is
+// free when picked but not reserved, so another process can take it before
+// the holon binds it; withEphemeralHolon detects that and retries.
+var ephemeralPort = func() (int, error) {
+	lis, err := transport.Listen("tcp://:0")
+	if err != nil {
+		return 0, fmt.Errorf("cannot allocate port: %w", err)
+	}
+	defer lis.Close()
+	return lis.Addr().(*net.TCPAddr).Port, nil
+}
+
+// ephemeralHolon is a holon launched on a free TCP port for one use.
+type ephemeralHolon struct {
+	name   string
+	port   int
+	target string
+	exited chan error
+	stderr *tailBuffer
+	cmd    *exec.Cmd
+}
+
+// stop kills the process and waits for it.
+func (h *ephemeralHolon) stop() {
+	h.cmd.Process.Kill() //nolint:errcheck
+	err := <-h.exited
+	h.exited <- err
+}
+
+// lostPort waits up to grace for the holon to exit, and reports
+// errEphemeralPortTaken when it exited because its port was taken: another
+// process answered on the port in its place. A holon still running, or one
+// that exited for another reason, lost nothing.
+func (h *ephemeralHolon) lostPort(grace time.Duration) error {
+	select {
+	case err := <-h.exited:
+		h.exited <- err
+		if lost := exitedBeforeListening(h.name, h.port, err, h.stderr.String()); errors.Is(lost, errEphemeralPortTaken) {
+			return lost
+		}
+	case <-time.After(grace):
+	}
+	return nil
+}
+
+// withEphemeralHolon launches binary with `serve --listen tcp://:<port>`,
+// waits until its port accepts connections, runs use against that address
+// and kills the process. A port that already answers before the launch, or
+// a child that exits before listening because the port was taken
+// meanwhile, is retried with a fresh port; so is a use that failed while
+// the child exited because its port was taken, since another process
+// answered for it. Only a child that actually exited is retried: one still
+// running keeps use's error. A child that exits for any other reason fails
+// the launch at once with its exit status and the end of its stderr.
+func withEphemeralHolon(ctx context.Context, holonName, binary string, use func(target string) error) error {
+	var lastErr error
+	for attempt := 1; attempt <= ephemeralLaunchAttempts; attempt++ {
+		holon, err := launchOnEphemeralPort(ctx, holonName, binary)
+		if err == nil {
+			err = use(holon.target)
+			if err != nil {
+				if lost := holon.lostPort(ephemeralExitGrace); lost != nil {
+					err = lost
+				}
+			}
+			holon.stop()
+		}
+		if !errors.Is(err, errEphemeralPortTaken) {
+			return err
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("%w (gave up after %d attempts)", lastErr, ephemeralLaunchAttempts)
+}
+
+func launchOnEphemeralPort(ctx context.Context, holonName, binary string) (*ephemeralHolon, error) {
+	port, err := ephemeralPort()
+	if err != nil {
+		return nil, err
+	}
+	target := net.JoinHostPort("localhost", strconv.Itoa(port))
+
+	if conn, err := net.DialTimeout("tcp", target, 100*time.Millisecond); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("%w: port %d already in use before %s started", errEphemeralPortTaken, port, holonName)
+	}
+
+	cmd := exec.Command(binary, "serve", "--listen", "tcp://:"+strconv.Itoa(port))
+	cmd.Dir = launchDir(ctx, binary)
+	stderr := &tailBuffer{max: ephemeralStderrTail}
+	cmd.Stderr = io.MultiWriter(os.Stderr, stderr)
+	// Copying stderr must not keep stop waiting on a grandchild that
+	// inherited it.
+	cmd.WaitDelay = time.Second
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("cannot start %s: %w", holonName, err)
+	}
+	holon := &ephemeralHolon{name: holonName, port: port, target: target, exited: make(chan error, 1), stderr: stderr, cmd: cmd}
+	go func() { holon.exited <- cmd.Wait() }()
+
+	for i := 0; i < 50 && ctx.Err() == nil; i++ {
+		select {
+		case err := <-holon.exited:
+			holon.exited <- err
+			return nil, exitedBeforeListening(holonName, port, err, stderr.String())
+		default:
+		}
+		conn, err := net.DialTimeout("tcp", target, 100*time.Millisecond)
+		if err == nil {
+			conn.Close()
+			return holon, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	holon.stop()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%s did not start within 5s on port %d", holonName, port)
+}
+
+// exitedBeforeListening explains why a launched holon exited before it
+// served its port, marking it errEphemeralPortTaken when the port was taken.
+func exitedBeforeListening(holonName string, port int, err error, stderr string) error {
+	if isAddrInUse(stderr) {
+		return fmt.Errorf("%w: %s exited before listening on port %d: %v", errEphemeralPortTaken, holonName, port, err)
+	}
+	msg := fmt.Sprintf("%s exited before listening on port %d (%s)", holonName, port, exitStatus(err))
+	if tail := strings.TrimSpace(stderr); tail != "" {
+		msg += ": " + lastLine(tail)
+	}
+	return errors.New(msg)
+}
+
+// isAddrInUse reports whether a holon's stderr says its listen address was
+// taken, as the Unix and Windows socket errors word it.
+func isAddrInUse(stderr string) bool {
+	lower := strings.ToLower(stderr)
+	return strings.Contains(lower, "address already in use") ||
+		strings.Contains(lower, "only one usage of each socket address")
+}
+
+// lastLine returns the last non-empty line of text.
+func lastLine(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// tailBuffer keeps the last max bytes written to it.
+type tailBuffer struct {
+	mu  sync.Mutex
+	max int
+	buf []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.max; over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithEphemeralHolonRetriesTakenPort(t *testing.T) {
+	root := t.TempDir()
+	seedEchoHolon(t, root)
+	binary := filepath.Join(root, "holons", "echo-server", ".op", "build", "bin", "echo-server")
+
+	squatter, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer squatter.Close()
+	takenPort := squatter.Addr().(*net.TCPAddr).Port
+
+	pickFree := ephemeralPort
+	picks := 0
+	ephemeralPort = func() (int, error) {
+		picks++
+		if picks == 1 {
+			return takenPort, nil
+		}
+		return pickFree()
+	}
+	t.Cleanup(func() { ephemeralPort = pickFree })
+
+	err = withEphemeralHolon(context.Background(), "echo-server", binary, func(target string) error {
+		if strings.HasSuffix(target, ":"+strconv.Itoa(takenPort)) {
+			t.Fatalf("target = %s, want a port other than the taken %d", target, takenPort)
+		}
+		conn, err := net.Dial("tcp", target)
+		if err != nil {
+			t.Fatalf("holon not reachable at %s: %v", target, err)
+		}
+		return conn.Close()
+	})
+	if err != nil {
+		t.Fatalf("withEphemeralHolon: %v", err)
+	}
+	if picks != 2 {
+		t.Fatalf("picked %d ports, want 2", picks)
+	}
+}
+
+func TestWithEphemeralHolonGivesUpWhenChildKeepsExiting(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "exits")
+	script := "#!/bin/sh\necho 'listen tcp :1: bind: address already in use' >&2\nexit 1\n"
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	pickFree := ephemeralPort
+	picks := 0
+	ephemeralPort = func() (int, error) {
+		picks++
+		return pickFree()
+	}
+	t.Cleanup(func() { ephemeralPort = pickFree })
+
+	err := withEphemeralHolon(context.Background(), "exits", binary, unusedEphemeralHolon(t))
+	if err == nil {
+		t.Fatal("expected withEphemeralHolon to fail")
+	}
+	if picks != ephemeralLaunchAttempts {
+		t.Fatalf("picked %d ports, want %d", picks, ephemeralLaunchAttempts)
+	}
+	for _, want := range []string{"exited before listening", "gave up after 3 attempts"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error = %q, want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestWithEphemeralHolonFailsFastWhenChildCrashes(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "crashes")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\necho 'panic: no config' >&2\nexit 3\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	pickFree := ephemeralPort
+	picks := 0
+	ephemeralPort = func() (int, error) {
+		picks++
+		return pickFree()
+	}
+	t.Cleanup(func() { ephemeralPort = pickFree })
+
+	started := time.Now()
+	var err error
+	captureStderr(t, func() {
+		err = withEphemeralHolon(context.Background(), "crashes", binary, unusedEphemeralHolon(t))
+	})
+	if err == nil {
+		t.Fatal("expected withEphemeralHolon to fail")
+	}
+	if picks != 1 {
+		t.Fatalf("picked %d ports, want a crash not to be retried", picks)
+	}
+	for _, want := range []string{"crashes exited before listening", "exit code 3", "panic: no config"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error = %q, want it to contain %q", err, want)
+		}
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Fatalf("launch took %s, want it to fail as soon as the child exits", elapsed)
+	}
+}
+
+// unusedEphemeralHolon is a use for launches that must fail before it.
+func unusedEphemeralHolon(t *testing.T) func(string) error {
+	return func(target string) error {
+		t.Errorf("use called with %s, want the launch to fail", target)
+		return nil
+	}
+}
+
+func TestWithEphemeralHolonRetriesWhenTheChildLostItsPort(t *testing.T) {
+	root := t.TempDir()
+	seedEchoHolon(t, root)
+	echo := filepath.Join(root, "holons", "echo-server", ".op", "build", "bin", "echo-server")
+
+	// The first launch loses its port to a squatter that takes it after
+	// the check that it is free, and exits once the squatter has
+	// answered; the second serves.
+	dir := t.TempDir()
+	started, squatted := filepath.Join(dir, "started"), filepath.Join(dir, "squatted")
+	binary := filepath.Join(dir, "loses-port")
+	script := "#!/bin/sh\nif [ -f '" + started + "' ]; then exec '" + echo + "' \"$@\"; fi\n" +
+		"touch '" + started + "'\nwhile [ ! -f '" + squatted + "' ]; do sleep 0.01; done\nsleep 0.1\n" +
+		"echo 'listen tcp: bind: address already in use' >&2\nexit 1\n"
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	pickFree := ephemeralPort
+	picks := 0
+	ephemeralPort = func() (int, error) {
+		picks++
+		port, err := pickFree()
+		if err != nil || picks > 1 {
+			return port, err
+		}
+		go func() {
+			for {
+				if _, err := os.Stat(started); err == nil {
+					break
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+			squatter, err := net.Listen("tcp", "localhost:"+strconv.Itoa(port))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			t.Cleanup(func() { squatter.Close() })
+			os.WriteFile(squatted, nil, 0o644) //nolint:errcheck
+		}()
+		return port, nil
+	}
+	t.Cleanup(func() { ephemeralPort = pickFree })
+
+	var uses []string
+	captureStderr(t, func() {
+		err := withEphemeralHolon(context.Background(), "loses-port", binary, func(target string) error {
+			uses = append(uses, target)
+			// Any gRPC answer, even Unimplemented, is the child's; the
+			// squatter never answers.
+			conn, err := grpc.NewClient(target, grpcclient.DialOptions()...)
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+			if code := status.Code(err); code == codes.DeadlineExceeded || code == codes.Unavailable {
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("withEphemeralHolon: %v", err)
+		}
+	})
+	if picks != 2 || len(uses) != 2 {
+		t.Fatalf("picked %d ports for %d uses, want the lost port retried once", picks, len(uses))
+	}
+}
+
+func TestWithEphemeralHolonKeepsTheErrorOfARunningChild(t *testing.T) {
+	root := t.TempDir()
+	seedEchoHolon(t, root)
+	binary := filepath.Join(root, "holons", "echo-server", ".op", "build", "bin", "echo-server")
+
+	pickFree := ephemeralPort
+	picks := 0
+	ephemeralPort = func() (int, error) {
+		picks++
+		return pickFree()
+	}
+	t.Cleanup(func() { ephemeralPort = pickFree })
+
+	useErr := errors.New("call failed")
+	err := withEphemeralHolon(context.Background(), "echo-server", binary, func(string) error { return useErr })
+	if !errors.Is(err, useErr) {
+		t.Fatalf("error = %v, want the use's error", err)
+	}
+	if picks != 1 {
+		t.Fatalf("picked %d ports, want a running child not to be relaunched", picks)
+	}
+}
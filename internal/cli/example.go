@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/organic-programming/grace-op/internal/didyoumean"
+	inspectpkg "github.com/organic-programming/grace-op/internal/inspect"
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+)
+
+const exampleUsage = "usage: op example <grpc://host:port|grpc+unix://path|holon> <method>"
+
+// cmdExample runs `op example <target> <method>`: it prints a request
+// skeleton for method with a placeholder for every field, ready to edit and
+// pass back as input. Addresses are described through reflection; a holon
+// name is described offline from its protos, which also supplies @example
+// values. Text output lists each enum's valid values after the skeleton, and
+// the members of each oneof, of which the skeleton sets only the first;
+// --format json prints the skeleton alone.
+func cmdExample(format Format, args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, exampleUsage)
+		return 1
+	}
+	target, method := args[0], args[1]
+
+	fields, err := exampleFields(target, method)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op example: %s\n", rpcErrorMessage(err))
+		return 1
+	}
+
+	out, err := json.MarshalIndent(inspectpkg.Skeleton(fields), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op example: %v\n", err)
+		return 1
+	}
+	fmt.Println(string(out))
+
+	if format != FormatJSON {
+		if enums := collectEnumValues("", fields); len(enums) > 0 {
+			fmt.Println()
+			fmt.Println("Enum values:")
+			for _, line := range enums {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+		if oneofs := collectOneofs("", fields); len(oneofs) > 0 {
+			fmt.Println()
+			fmt.Println("Oneofs (the skeleton sets the first member):")
+			for _, line := range oneofs {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+	}
+	return 0
+}
+
+func exampleFields(target, method string) ([]inspectpkg.Field, error) {
+	described, err := describeTargetMethod(target, method)
+	if err != nil {
+		return nil, err
+	}
+	return described.InputFields, nil
+}
+
+// describeTargetMethod describes method of target: through reflection when
+// target is an address, offline from its protos when it is a holon name.
+func describeTargetMethod(target, method string) (inspectpkg.Method, error) {
+	if strings.Contains(target, "://") || strings.Contains(target, ":") {
+		address, err := parseRPCAddress(target)
+		if err != nil {
+			return inspectpkg.Method{}, err
+		}
+		desc, err := grpcclient.DescribeMethod(address, method)
+		if err != nil {
+			return inspectpkg.Method{}, err
+		}
+		return inspectpkg.MethodFromDescriptor(desc), nil
+	}
+
+	catalog, err := inspectpkg.LoadLocal(target)
+	if err != nil {
+		return inspectpkg.Method{}, err
+	}
+	name := canonicalMethodName(method)
+	var available []string
+	for _, binding := range catalog.Methods {
+		if binding.Method.Name == name {
+			return binding.Method, nil
+		}
+		available = append(available, binding.Method.Name)
+	}
+	msg := fmt.Sprintf("method %q not found in %s", method, target)
+	return inspectpkg.Method{}, errors.New(didyoumean.WithHint(msg, didyoumean.ClosestMethods(method, available)))
+}
+
+// collectOneofs lists "path: a, b, c" for every oneof, descending into
+// nested messages.
+func collectOneofs(prefix string, fields []inspectpkg.Field) []string {
+	var out []string
+	names, members := inspectpkg.Oneofs(fields)
+	for _, name := range names {
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		out = append(out, fmt.Sprintf("%s: %s", path, strings.Join(members[name], ", ")))
+	}
+	for _, field := range fields {
+		path := field.Name
+		if prefix != "" {
+			path = prefix + "." + field.Name
+		}
+		out = append(out, collectOneofs(path, field.NestedFields)...)
+	}
+	return out
+}
+
+// collectEnumValues lists "path: A, B, C" for every enum-typed field,
+// descending into nested messages.
+func collectEnumValues(prefix string, fields []inspectpkg.Field) []string {
+	var out []string
+	for _, field := range fields {
+		path := field.Name
+		if prefix != "" {
+			path = prefix + "." + field.Name
+		}
+		if len(field.EnumValues) > 0 {
+			names := make([]string, 0, len(field.EnumValues))
+			for _, value := range field.EnumValues {
+				names = append(names, value.Name)
+			}
+			out = append(out, fmt.Sprintf("%s: %s", path, strings.Join(names, ", ")))
+		}
+		out = append(out, collectEnumValues(path, field.NestedFields)...)
+	}
+	return out
+}
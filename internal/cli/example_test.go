@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExampleCommandFromReflection(t *testing.T) {
+	address := startOPServer(t)
+
+	output := captureStdout(t, func() {
+		if code := Run([]string{"example", "grpc://" + address, "CreateIdentity"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("code = %d, want 0", code)
+		}
+	})
+
+	skeleton, enums, _ := strings.Cut(output, "\nEnum values:\n")
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(skeleton), &payload); err != nil {
+		t.Fatalf("skeleton is not JSON: %v\n%s", err, output)
+	}
+	if payload["given_name"] != "" {
+		t.Fatalf("given_name = %#v, want an empty string placeholder", payload["given_name"])
+	}
+	if aliases, ok := payload["aliases"].([]any); !ok || len(aliases) != 1 {
+		t.Fatalf("aliases = %#v, want a single-element array", payload["aliases"])
+	}
+	if !strings.Contains(enums, "clade: CLADE_UNSPECIFIED, DETERMINISTIC_PURE") {
+		t.Fatalf("enum sidecar missing clade values:\n%s", enums)
+	}
+}
+
+func TestExampleCommandOfflineUsesProtoExamples(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedInspectableHolon(t, root)
+
+	output := captureStdout(t, func() {
+		if code := Run([]string{"--format", "json", "example", "rob-go", "Build"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("code = %d, want 0", code)
+		}
+	})
+
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, output)
+	}
+	if payload["package"] != "./cmd/rob" {
+		t.Fatalf("package = %#v, want the @example value", payload["package"])
+	}
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/organic-programming/grace-op/internal/holons"
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+)
+
+// explainer narrates, for --explain, every decision op makes for a
+// command as numbered lines. Fanned-out calls share it, so steps are
+// numbered under a lock.
+type explainer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	step int
+}
+
+// activeExplainer is nil unless --explain was given; explainf is then a
+// no-op.
+var activeExplainer *explainer
+
+// pinExplain makes explainf write to w for this invocation when on.
+// release restores the explainer set before, if any.
+func pinExplain(on bool, w io.Writer) func() {
+	if !on {
+		return func() {}
+	}
+	previous := activeExplainer
+	activeExplainer = &explainer{w: w}
+	return func() { activeExplainer = previous }
+}
+
+func explainf(format string, args ...any) {
+	e := activeExplainer
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.step++
+	fmt.Fprintf(e.w, "explain %d. %s\n", e.step, fmt.Sprintf(format, args...))
+}
+
+// explainGlobalFlags narrates the global options in effect and which of
+// them the command line set.
+func explainGlobalFlags(opts globalOptions) {
+	if activeExplainer == nil {
+		return
+	}
+	reflectProto, timeout := string(opts.ReflectProto), "default"
+	if reflectProto == "" {
+		reflectProto = string(grpcclient.ReflectAuto)
+	}
+	if opts.Timeout > 0 {
+		timeout = opts.Timeout.String()
+	}
+	given := "none"
+	if len(opts.Given) > 0 {
+		given = strings.Join(opts.Given, ", ")
+	}
+	explainf("global flags: format=%s quiet=%t reflect-proto=%s timeout=%s (given: %s)",
+		opts.Format, opts.Quiet, reflectProto, timeout, given)
+}
+
+// explainRoute narrates which of op's three dispatch paths cmd takes.
+func explainRoute(cmd string) {
+	switch {
+	case opCommands[cmd] != nil:
+		explainf("route: %q is an op command", cmd)
+	case isGRPCURI(cmd):
+		explainf("route: %q is a gRPC URI; only grpc://<holon> goes through the transport chain", cmd)
+	default:
+		explainf("route: %q is not an op command, so it names a holon: namespace dispatch", cmd)
+	}
+}
+
+// explainGRPCArgs narrates the method and request a gRPC URI call makes.
+func explainGRPCArgs(args []string) {
+	switch len(args) {
+	case 0:
+		explainf("no method given; a host:port or unix address lists its methods")
+	case 1:
+		explainf("method %s with no request argument: an empty request, or one JSON object per stdin line for a client stream", args[0])
+	default:
+		explainf("method %s with input %s", args[0], explainInput(args[1]))
+	}
+}
+
+// explainDecision narrates how the transport chain chose decision for
+// holon: the override it honoured, the holon's language and the choice.
+func explainDecision(holon string, decision TransportDecision) {
+	if activeExplainer == nil {
+		return
+	}
+	if decision.Override != nil {
+		explainf("transport override: %s=%s", transportOverrideOrigin(decision.Override), transportOverrideValue(decision.Override))
+	} else {
+		explainf("transport override: none in --transport, %s or %s; the chain decides", envTransport, holonConfigFile)
+	}
+	if target, err := holons.ResolveTarget(holon); err != nil {
+		explainf("language: unknown, %s is not resolved: %v", holon, err)
+	} else if target.Identity == nil || target.Identity.Lang == "" {
+		explainf("language: unknown, %s has no identity lang", target.Dir)
+	} else {
+		explainf("language: %s, from %s", target.Identity.Lang, target.IdentityPath)
+	}
+	for _, candidate := range decision.Candidates {
+		state := "unavailable"
+		if candidate.Available {
+			state = "available"
+		}
+		explainf("candidate %s: %s (%s)", candidate.Scheme, state, candidate.Reason)
+	}
+	explainf("transport: %s, because %s", decision.Scheme, decision.Reason)
+}
+
+// explainInputLimit bounds how much of a request --explain prints; the
+// rest is counted, not shown.
+const explainInputLimit = 200
+
+// explainInput renders a request for narration, cut to explainInputLimit
+// bytes so large or sensitive payloads do not flood the terminal.
+func explainInput(inputJSON string) string {
+	if len(inputJSON) <= explainInputLimit {
+		return inputJSON
+	}
+	return fmt.Sprintf("%s... (%d bytes, %d not shown)", strings.ToValidUTF8(inputJSON[:explainInputLimit], ""), len(inputJSON), len(inputJSON)-explainInputLimit)
+}
+
+// explainedCall runs call and narrates how it ended, as explainCall does.
+func explainedCall(call func() (*grpcclient.CallResult, error)) (*grpcclient.CallResult, error) {
+	start := time.Now()
+	result, err := call()
+	explainCall(result, err, time.Since(start))
+	return result, err
+}
+
+// explainCall narrates how a call ended: how its method was resolved, by
+// the compiled-in or loaded descriptors or by server reflection, or the
+// error.
+func explainCall(result *grpcclient.CallResult, err error, elapsed time.Duration) {
+	if activeExplainer == nil {
+		return
+	}
+	elapsed = elapsed.Round(time.Millisecond)
+	if err != nil {
+		explainf("call failed after %s: %s", elapsed, rpcErrorMessage(err))
+		return
+	}
+	method := result.Method
+	if result.Service != "" {
+		method = result.Service + "/" + result.Method
+	}
+	if result.Resolution != "" {
+		method += " through " + result.Resolution
+	}
+	explainf("resolved %s; call returned after %s", method, elapsed)
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+)
+
+func TestExplainNarratesNamespaceDispatch(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	t.Setenv("OPPATH", t.TempDir())
+	t.Setenv(envTransport, "")
+	writeRunServiceFixture(t, filepath.Join(root, "who"), "who")
+	address := startSlowHealthServer(t, 0)
+	config := "transports:\n  who: grpc://" + address + "\n"
+	if err := os.WriteFile(filepath.Join(root, holonConfigFile), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout string
+	stderr := captureStderr(t, func() {
+		stdout = captureStdout(t, func() {
+			if code := Run([]string{"--explain", "who", "Check"}, "0.1.0-test"); code != 0 {
+				t.Fatalf("code = %d, want 0", code)
+			}
+		})
+	})
+	if !strings.Contains(stdout, "SERVING") {
+		t.Fatalf("stdout = %q, want the result", stdout)
+	}
+
+	// Each step is numbered, in dispatch order.
+	steps := []string{
+		"explain 1. global flags: format=text quiet=false",
+		"explain 2. route: \"who\" is not an op command",
+		"maps to method Check",
+		"transport override: .holonconfig transports.who=tcp://" + address,
+		"language:",
+		"transport: tcp, because forced by .holonconfig",
+		"dialing the running instance at tcp://" + address,
+		"resolved grpc.health.v1.Health/Check",
+		"printing the result as text",
+	}
+	rest := stderr
+	for _, step := range steps {
+		i := strings.Index(rest, step)
+		if i < 0 {
+			t.Fatalf("stderr lacks %q after the previous steps:\n%s", step, stderr)
+		}
+		rest = rest[i+len(step):]
+	}
+}
+
+func TestExplainNarratesGRPCURIDispatch(t *testing.T) {
+	address := startSlowHealthServer(t, 0)
+
+	stderr := captureStderr(t, func() {
+		_ = captureStdout(t, func() {
+			if code := Run([]string{"--explain", "grpc://" + address, "Check"}, "0.1.0-test"); code != 0 {
+				t.Fatalf("code = %d, want 0", code)
+			}
+		})
+	})
+	steps := []string{
+		"route: \"grpc://" + address + "\" is a gRPC URI",
+		"method Check with no request argument",
+		"dialing " + address + " directly",
+		"resolved grpc.health.v1.Health/Check through " + grpcclient.ResolvedByReflection,
+	}
+	rest := stderr
+	for _, step := range steps {
+		i := strings.Index(rest, step)
+		if i < 0 {
+			t.Fatalf("stderr lacks %q after the previous steps:\n%s", step, stderr)
+		}
+		rest = rest[i+len(step):]
+	}
+}
+
+func TestExplainInputCutsLargeRequests(t *testing.T) {
+	if got := explainInput(`{"a":1}`); got != `{"a":1}` {
+		t.Fatalf("explainInput = %q, want a short request unchanged", got)
+	}
+	large := `{"blob":"` + strings.Repeat("x", 1000) + `"}`
+	got := explainInput(large)
+	if len(got) > explainInputLimit+40 || !strings.Contains(got, "1011 bytes") {
+		t.Fatalf("explainInput = %q, want it cut with the full size named", got)
+	}
+}
+
+func TestExplainIsSilentByDefault(t *testing.T) {
+	stderr := captureStderr(t, func() {
+		_ = captureStdout(t, func() { Run([]string{"version"}, "0.1.0-test") })
+	})
+	if strings.Contains(stderr, "explain ") {
+		t.Fatalf("stderr = %q, want no narration without --explain", stderr)
+	}
+}
+
+func TestNestedExplainPinRestoresTheOuterExplainer(t *testing.T) {
+	var outer, inner strings.Builder
+	releaseOuter := pinExplain(true, &outer)
+	defer releaseOuter()
+
+	releaseInner := pinExplain(true, &inner)
+	explainf("inner step")
+	releaseInner()
+	explainf("outer step")
+	if !strings.Contains(inner.String(), "inner step") || !strings.Contains(outer.String(), "outer step") {
+		t.Fatalf("outer = %q, inner = %q; want each step in its own explainer", outer.String(), inner.String())
+	}
+
+	releaseOuter()
+	if activeExplainer != nil {
+		t.Fatal("explainf should be off after both releases")
+	}
+}
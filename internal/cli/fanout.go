@@ -0,0 +1,353 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/organic-programming/grace-op/internal/holons"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// fanOutResult records the outcome of one holon call in a fan-out dispatch.
+type fanOutResult struct {
+	Holon  string          `json:"holon"`
+	Output json.RawMessage `json:"output,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	// Cancelled marks a call abandoned under --fail-fast after another
+	// holon's call failed.
+	Cancelled bool `json:"cancelled,omitempty"`
+
+	wire []byte
+}
+
+type fanOutReport struct {
+	Method    string         `json:"method"`
+	Results   []fanOutResult `json:"results"`
+	Succeeded int            `json:"succeeded"`
+	Failed    int            `json:"failed"`
+	Cancelled int            `json:"cancelled,omitempty"`
+	// FirstFailure names the holon whose failure cancelled the rest under
+	// --fail-fast.
+	FirstFailure string `json:"first_failure,omitempty"`
+}
+
+// isHolonPattern reports whether a holon selector targets several holons:
+// a glob such as "sophia-*" or the --all flag.
+func isHolonPattern(holon string) bool {
+	return holon == "--all" || strings.ContainsAny(holon, "*?[")
+}
+
+// cmdHolonFanOut runs `op <pattern> <command> [args...]` against every
+// discovered holon whose name matches the pattern and prints a combined
+// report tagged with each holon's name.
+func cmdHolonFanOut(ctx context.Context, format Format, pattern string, args []string) int {
+	if pattern == "--all" {
+		pattern = "*"
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		fmt.Fprintf(os.Stderr, "op: invalid holon pattern %q: %v\n", pattern, err)
+		return 1
+	}
+
+	concurrency, failFast, args, err := extractFanOutFlags(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op: %v\n", err)
+		return 1
+	}
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "op: missing command for holons matching %q\n", pattern)
+		return 1
+	}
+
+	method, inputJSON, err := mapHolonCommandToRPC(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op: %v\n", err)
+		return 1
+	}
+
+	names, err := matchHolonNames(pattern)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op: %v\n", err)
+		return 1
+	}
+	if len(names) == 0 {
+		fmt.Fprintf(os.Stderr, "op: no holons match %q\n", pattern)
+		return 1
+	}
+
+	// json-stream writes each holon's result as its call completes;
+	// failures stay in the array as they do in the json report.
+	var array *jsonArrayWriter
+	var arrayErr error
+	var done func(fanOutResult)
+	if streamsJSONArray(format) {
+		array = newJSONArrayWriter(os.Stdout)
+		done = func(result fanOutResult) {
+			if arrayErr != nil {
+				return
+			}
+			element, err := json.Marshal(result)
+			if err == nil {
+				err = array.Add(element)
+			}
+			arrayErr = err
+		}
+	}
+
+	results, firstFailure := runFanOut(ctx, names, concurrency, failFast, method, inputJSON, done)
+	report := fanOutReport{
+		Method:       method,
+		Results:      results,
+		FirstFailure: firstFailure,
+	}
+	for _, result := range report.Results {
+		switch {
+		case result.Cancelled:
+			report.Cancelled++
+		case result.Error != "":
+			report.Failed++
+		default:
+			report.Succeeded++
+		}
+	}
+
+	switch {
+	case format == FormatJSON && outputTemplate == nil:
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "op: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(out))
+	case array != nil:
+		if err := array.Close(); arrayErr == nil {
+			arrayErr = err
+		}
+		if arrayErr != nil {
+			fmt.Fprintf(os.Stderr, "op: write output: %v\n", arrayErr)
+			return 1
+		}
+	case isProtobin(format):
+		// Successful responses go to stdout in holon order; failures can
+		// only be reported on stderr.
+		for _, result := range report.Results {
+			if result.Error != "" {
+				fmt.Fprintf(os.Stderr, "op: %s: %s\n", result.Holon, result.Error)
+				continue
+			}
+			if err := writeProtobin(os.Stdout, format, result.wire); err != nil {
+				fmt.Fprintf(os.Stderr, "op: write output: %v\n", err)
+				return 1
+			}
+		}
+	default:
+		printFanOutText(format, report)
+	}
+
+	if report.Failed > 0 || report.Cancelled > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runFanOut calls method on every holon, at most concurrency at a time.
+// Results keep the order of names. By default every call runs and failures
+// are aggregated; with failFast the first failure cancels the outstanding
+// calls, and its holon is returned. A non-nil done is given each result as
+// its call completes, one at a time.
+func runFanOut(parent context.Context, names []string, concurrency int, failFast bool, method, inputJSON string, done func(fanOutResult)) ([]fanOutResult, string) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]fanOutResult, len(names))
+	var firstFailure string
+	var mu sync.Mutex
+
+	g, ctx := errgroup.WithContext(parent)
+	g.SetLimit(concurrency)
+	for i, name := range names {
+		g.Go(func() error {
+			result := fanOutResult{Holon: name}
+			defer func() {
+				results[i] = result
+				if done != nil {
+					mu.Lock()
+					done(result)
+					mu.Unlock()
+				}
+			}()
+
+			if ctx.Err() != nil {
+				result.Error, result.Cancelled = "cancelled", true
+				return nil
+			}
+			call, err := callHolon(ctx, name, method, inputJSON)
+			switch {
+			case err != nil && ctx.Err() != nil:
+				result.Error, result.Cancelled = "cancelled", true
+			case err != nil:
+				result.Error = rpcErrorMessage(err)
+				if failFast {
+					mu.Lock()
+					if firstFailure == "" {
+						firstFailure = name
+					}
+					mu.Unlock()
+					return err
+				}
+			default:
+				result.Output = fanOutOutput([]byte(call.Output))
+				result.wire = call.Wire
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return results, firstFailure
+}
+
+func printFanOutText(format Format, report fanOutReport) {
+	for i, result := range report.Results {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("== %s ==\n", result.Holon)
+		if result.Cancelled {
+			fmt.Printf("cancelled after %s failed\n", report.FirstFailure)
+			continue
+		}
+		if result.Error != "" {
+			fmt.Printf("error: %s\n", result.Error)
+			continue
+		}
+		out, err := renderRPCOutput(format, report.Method, result.Output)
+		if err != nil {
+			fmt.Printf("error: %v\n", err)
+			continue
+		}
+		if out != "" {
+			fmt.Println(out)
+		}
+	}
+	summary := fmt.Sprintf("%d succeeded, %d failed", report.Succeeded, report.Failed)
+	if report.Cancelled > 0 {
+		summary += fmt.Sprintf(", %d cancelled after %s failed (--fail-fast)", report.Cancelled, report.FirstFailure)
+	}
+	fmt.Printf("\n%s\n", summary)
+}
+
+// fanOutOutput keeps valid JSON responses as-is and wraps anything else as a
+// JSON string so the combined report stays valid JSON.
+func fanOutOutput(output []byte) json.RawMessage {
+	trimmed := strings.TrimSpace(string(output))
+	if trimmed == "" {
+		return nil
+	}
+	if json.Valid([]byte(trimmed)) {
+		return json.RawMessage(trimmed)
+	}
+	quoted, _ := json.Marshal(trimmed)
+	return quoted
+}
+
+// matchHolonNames returns the dispatch names of discovered holons whose slug,
+// directory name, or alias matches the glob pattern.
+func matchHolonNames(pattern string) ([]string, error) {
+	local, err := holons.DiscoverLocalHolons()
+	if err != nil {
+		return nil, err
+	}
+	cached, err := holons.DiscoverCachedHolons()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+	var names []string
+	for _, h := range append(append([]holons.LocalHolon{}, local...), cached...) {
+		name := h.Identity.Slug()
+		if name == "" {
+			name = filepath.Base(h.Dir)
+		}
+		candidates := append([]string{name, filepath.Base(h.Dir)}, h.Identity.Aliases...)
+		if !anyGlobMatch(pattern, candidates) {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func anyGlobMatch(pattern string, candidates []string) bool {
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if ok, _ := path.Match(pattern, candidate); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// extractBoolFlag removes every occurrence of the boolean flag name from args
+// and reports whether it was present.
+func extractBoolFlag(args []string, name string) (bool, []string) {
+	found := false
+	remaining := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == name {
+			found = true
+			continue
+		}
+		remaining = append(remaining, arg)
+	}
+	return found, remaining
+}
+
+// extractFanOutFlags removes --concurrency <n> and --fail-fast from the
+// front of args. It stops at the command word, so arguments meant for the
+// holons keep flags of the same name.
+func extractFanOutFlags(args []string) (concurrency int, failFast bool, rest []string, err error) {
+	concurrency = 1
+	i := 0
+	for ; i < len(args); i++ {
+		value := ""
+		switch {
+		case args[i] == "--fail-fast":
+			failFast = true
+			continue
+		case args[i] == "--concurrency":
+			if i+1 >= len(args) {
+				return 0, false, nil, fmt.Errorf("--concurrency requires a value")
+			}
+			value = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--concurrency="):
+			value = strings.TrimPrefix(args[i], "--concurrency=")
+		default:
+			return concurrency, failFast, args[i:], nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return 0, false, nil, fmt.Errorf("invalid --concurrency %q (want a positive integer)", value)
+		}
+		concurrency = n
+	}
+	return concurrency, failFast, args[i:], nil
+}
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestMatchHolonNamesGlob(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "sophia-a", givenName: "Sophia", familyName: "A", lang: "go"})
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "sophia-b", givenName: "Sophia", familyName: "B", lang: "go"})
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "atlas", givenName: "Atlas", familyName: "Holon", aliases: []string{"maps"}, lang: "go"})
+
+	names, err := matchHolonNames("sophia-*")
+	if err != nil {
+		t.Fatalf("matchHolonNames returned error: %v", err)
+	}
+	if len(names) != 2 || names[0] != "sophia-a" || names[1] != "sophia-b" {
+		t.Fatalf("names = %v, want [sophia-a sophia-b]", names)
+	}
+
+	names, err = matchHolonNames("ma?s")
+	if err != nil {
+		t.Fatalf("matchHolonNames returned error: %v", err)
+	}
+	if len(names) != 1 || names[0] != "atlas-holon" {
+		t.Fatalf("names = %v, want [atlas-holon]", names)
+	}
+}
+
+func TestHolonFanOutReportsPerHolonErrors(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "sophia-a", givenName: "Sophia", familyName: "A", lang: "go"})
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "sophia-b", givenName: "Sophia", familyName: "B", lang: "go"})
+
+	var code int
+	output := captureStdout(t, func() {
+		code = Run([]string{"--format", "json", "sophia-*", "--concurrency", "2", "list"}, "0.1.0-test")
+	})
+	if code != 1 {
+		t.Fatalf("fan-out returned %d, want 1", code)
+	}
+
+	var report fanOutReport
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("unmarshal report: %v\n%s", err, output)
+	}
+	if report.Method != "ListIdentities" {
+		t.Fatalf("method = %q, want ListIdentities", report.Method)
+	}
+	if len(report.Results) != 2 || report.Failed != 2 || report.Succeeded != 0 {
+		t.Fatalf("report = %+v, want 2 failed results", report)
+	}
+	for _, result := range report.Results {
+		if result.Error == "" {
+			t.Fatalf("result for %s has no error", result.Holon)
+		}
+	}
+
+	output = captureStdout(t, func() {
+		code = Run([]string{"--format", "json-stream", "sophia-*", "list"}, "0.1.0-test")
+	})
+	var results []fanOutResult
+	if err := json.Unmarshal([]byte(output), &results); err != nil || code != 1 {
+		t.Fatalf("exit code = %d, unmarshal results: %v\n%s", code, err, output)
+	}
+	if len(results) != 2 || results[0].Holon != "sophia-a" || results[1].Error == "" {
+		t.Fatalf("results = %+v, want one element per holon with its error", results)
+	}
+}
+
+func TestHolonFanOutFailFastCancelsOutstandingCalls(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+
+	for _, name := range []string{"a", "b", "c"} {
+		seedTransportHolon(t, root, transportHolonSeed{dirName: "sophia-" + name, givenName: "Sophia", familyName: name, lang: "go"})
+	}
+
+	var code int
+	output := captureStdout(t, func() {
+		code = Run([]string{"--format", "json", "sophia-*", "--fail-fast", "list"}, "0.1.0-test")
+	})
+	if code != 1 {
+		t.Fatalf("fan-out returned %d, want 1", code)
+	}
+
+	var report fanOutReport
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("unmarshal report: %v\n%s", err, output)
+	}
+	if report.Failed != 1 || report.Cancelled != 2 || report.FirstFailure != "sophia-a" {
+		t.Fatalf("report = %+v, want sophia-a failed and 2 cancelled", report)
+	}
+	for _, result := range report.Results[1:] {
+		if !result.Cancelled {
+			t.Fatalf("result for %s was not cancelled: %+v", result.Holon, result)
+		}
+	}
+}
+
+func TestRunFanOutHandsOverEachResultAsItCompletes(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+
+	for _, name := range []string{"a", "b", "c"} {
+		seedTransportHolon(t, root, transportHolonSeed{dirName: "sophia-" + name, givenName: "Sophia", familyName: name, lang: "go"})
+	}
+	names := []string{"sophia-a", "sophia-b", "sophia-c"}
+
+	var done []fanOutResult
+	results, _ := runFanOut(context.Background(), names, 2, false, "ListIdentities", "{}", func(result fanOutResult) {
+		done = append(done, result)
+	})
+	if len(done) != len(names) {
+		t.Fatalf("done was called %d times, want %d", len(done), len(names))
+	}
+	seen := map[string]bool{}
+	for _, result := range done {
+		if result.Error == "" || seen[result.Holon] {
+			t.Fatalf("done got %+v, want each holon once with its error", done)
+		}
+		seen[result.Holon] = true
+	}
+	for i, result := range results {
+		if result.Holon != names[i] {
+			t.Fatalf("results[%d] = %s, want %s", i, result.Holon, names[i])
+		}
+	}
+}
+
+func TestExtractFanOutFlags(t *testing.T) {
+	n, failFast, rest, err := extractFanOutFlags([]string{"--concurrency=4", "--fail-fast", "list", "--concurrency", "2", "--fail-fast"})
+	if err != nil {
+		t.Fatalf("extractFanOutFlags returned error: %v", err)
+	}
+	want := []string{"list", "--concurrency", "2", "--fail-fast"}
+	if n != 4 || !failFast || !slices.Equal(rest, want) {
+		t.Fatalf("got (%d, %v, %q), want (4, true, %q): flags after the command belong to it", n, failFast, rest, want)
+	}
+	if _, _, _, err := extractFanOutFlags([]string{"--concurrency", "0"}); err == nil {
+		t.Fatal("expected error for --concurrency 0")
+	}
+	if _, _, _, err := extractFanOutFlags([]string{"--concurrency"}); err == nil {
+		t.Fatal("expected error for --concurrency without a value")
+	}
+}
@@ -4,12 +4,18 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 
 	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
 
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -18,9 +24,27 @@ type Format string
 
 const (
 	FormatText Format = "text"
+	// FormatWide is text output whose tables keep every column at full
+	// width instead of fitting the terminal.
+	FormatWide Format = "wide"
 	FormatJSON Format = "json"
+	// FormatJSONStream writes every response as an element of one JSON
+	// array, each as it arrives, for consumers that need a single document.
+	FormatJSONStream Format = "json-stream"
+	// FormatProtobin writes the raw serialized response message. It is only
+	// meaningful for a single response; use FormatProtobinDelimited when
+	// several messages go to the same stream.
+	FormatProtobin Format = "protobin"
+	// FormatProtobinDelimited prefixes each serialized response with its
+	// varint-encoded length.
+	FormatProtobinDelimited Format = "protobin-delimited"
 )
 
+// isProtobin reports whether format writes protobuf wire bytes.
+func isProtobin(format Format) bool {
+	return format == FormatProtobin || format == FormatProtobinDelimited
+}
+
 // FormatResponse formats a gRPC response for CLI output.
 func FormatResponse(format Format, resp proto.Message) string {
 	if resp == nil {
@@ -33,13 +57,15 @@ func FormatResponse(format Format, resp proto.Message) string {
 
 	switch typed := resp.(type) {
 	case *opv1.ListIdentitiesResponse:
-		return formatListIdentitiesText(typed)
+		return formatListIdentitiesText(typed, format)
 	case *opv1.ShowIdentityResponse:
 		return formatShowIdentityText(typed)
 	case *opv1.CreateIdentityResponse:
 		return formatCreateIdentityText(typed)
+	case *opv1.DeleteIdentityResponse:
+		return formatDeleteIdentityText(typed)
 	case *opv1.DiscoverResponse:
-		return formatDiscoverText(typed)
+		return formatDiscoverText(typed, format)
 	default:
 		return marshalProtoJSONForOutput(resp)
 	}
@@ -50,6 +76,11 @@ func formatRPCOutput(format Format, method string, payload []byte) string {
 	if trimmed == "" {
 		return ""
 	}
+	if format == FormatText || format == FormatWide {
+		if text, ok := scalarText(trimmed); ok {
+			return text
+		}
+	}
 
 	resp := responseMessageForMethod(method)
 	if resp == nil {
@@ -70,6 +101,8 @@ func responseMessageForMethod(method string) proto.Message {
 		return &opv1.ListIdentitiesResponse{}
 	case "ShowIdentity":
 		return &opv1.ShowIdentityResponse{}
+	case "DeleteIdentity":
+		return &opv1.DeleteIdentityResponse{}
 	case "Discover":
 		return &opv1.DiscoverResponse{}
 	default:
@@ -87,6 +120,16 @@ func formatCreateIdentityText(resp *opv1.CreateIdentityResponse) string {
 	return strings.TrimSpace(b.String())
 }
 
+func formatDeleteIdentityText(resp *opv1.DeleteIdentityResponse) string {
+	var b strings.Builder
+	b.WriteString("Identity deleted\n")
+	if resp.GetRemovedPath() != "" {
+		fmt.Fprintf(&b, "Removed: %s\n", resp.GetRemovedPath())
+	}
+	appendIdentityTable(&b, resp.GetIdentity())
+	return strings.TrimSpace(b.String())
+}
+
 func formatShowIdentityText(resp *opv1.ShowIdentityResponse) string {
 	var b strings.Builder
 	if resp.GetFilePath() != "" {
@@ -99,55 +142,21 @@ func formatShowIdentityText(resp *opv1.ShowIdentityResponse) string {
 	return strings.TrimSpace(b.String())
 }
 
-func formatListIdentitiesText(resp *opv1.ListIdentitiesResponse) string {
+func formatListIdentitiesText(resp *opv1.ListIdentitiesResponse, format Format) string {
 	if len(resp.GetEntries()) == 0 {
 		return "No identities found."
 	}
 
 	var b strings.Builder
-	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "SLUG\tUUID\tNAME\tCLADE\tSTATUS\tLANG\tORIGIN\tPATH")
-	for _, entry := range resp.GetEntries() {
-		id := entry.GetIdentity()
-		fmt.Fprintf(
-			w,
-			"%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-			defaultDash(identitySlug(id)),
-			shortUUID(id.GetUuid()),
-			displayName(id),
-			cladeLabel(id.GetClade()),
-			statusLabel(id.GetStatus()),
-			defaultDash(id.GetLang()),
-			defaultDash(entry.GetOrigin()),
-			defaultDash(entry.GetRelativePath()),
-		)
-	}
-	_ = w.Flush()
+	identityTable(resp.GetEntries(), format).render(&b, tableWidth(format), "")
 	return strings.TrimSpace(b.String())
 }
 
-func formatDiscoverText(resp *opv1.DiscoverResponse) string {
+func formatDiscoverText(resp *opv1.DiscoverResponse, format Format) string {
 	var b strings.Builder
 
 	if len(resp.GetEntries()) > 0 {
-		w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "SLUG\tUUID\tNAME\tCLADE\tSTATUS\tLANG\tORIGIN\tPATH")
-		for _, entry := range resp.GetEntries() {
-			id := entry.GetIdentity()
-			fmt.Fprintf(
-				w,
-				"%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-				defaultDash(identitySlug(id)),
-				shortUUID(id.GetUuid()),
-				displayName(id),
-				cladeLabel(id.GetClade()),
-				statusLabel(id.GetStatus()),
-				defaultDash(id.GetLang()),
-				defaultDash(entry.GetOrigin()),
-				defaultDash(entry.GetRelativePath()),
-			)
-		}
-		_ = w.Flush()
+		identityTable(resp.GetEntries(), format).render(&b, tableWidth(format), "")
 	}
 
 	if len(resp.GetPathBinaries()) > 0 {
@@ -166,6 +175,40 @@ func formatDiscoverText(resp *opv1.DiscoverResponse) string {
 	return strings.TrimSpace(b.String())
 }
 
+// identityTable lays out ListIdentities and Discover entries. On a narrow
+// terminal PATH and NAME are truncated, then ORIGIN, CLADE, LANG, UUID and
+// PATH are dropped in that order; --format wide also shows full UUIDs.
+func identityTable(entries []*opv1.HolonEntry, format Format) *table {
+	t := newTable(
+		tableColumn{Header: "SLUG"},
+		tableColumn{Header: "UUID", Drop: 2},
+		tableColumn{Header: "NAME", Truncate: true},
+		tableColumn{Header: "CLADE", Drop: 4},
+		tableColumn{Header: "STATUS"},
+		tableColumn{Header: "LANG", Drop: 3},
+		tableColumn{Header: "ORIGIN", Drop: 5},
+		tableColumn{Header: "PATH", Drop: 1, Truncate: true},
+	)
+	for _, entry := range entries {
+		id := entry.GetIdentity()
+		uuid := shortUUID(id.GetUuid())
+		if format == FormatWide {
+			uuid = defaultDash(id.GetUuid())
+		}
+		t.add(
+			defaultDash(identitySlug(id)),
+			uuid,
+			displayName(id),
+			cladeLabel(id.GetClade()),
+			statusLabel(id.GetStatus()),
+			defaultDash(id.GetLang()),
+			defaultDash(entry.GetOrigin()),
+			defaultDash(entry.GetRelativePath()),
+		)
+	}
+	return t
+}
+
 func appendIdentityTable(b *strings.Builder, id *opv1.HolonIdentity) {
 	if id == nil {
 		return
@@ -282,6 +325,33 @@ func marshalProtoJSONForOutput(msg proto.Message) string {
 	return string(out)
 }
 
+// scalarText renders a top-level JSON string, number, bool or null for text
+// output: strings unquoted, the rest verbatim. It reports false for objects,
+// arrays and payloads that are not JSON.
+func scalarText(value string) (string, bool) {
+	if value == "" || value[0] == '{' || value[0] == '[' || !json.Valid([]byte(value)) {
+		return "", false
+	}
+	var scalar any
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+	if err := decoder.Decode(&scalar); err != nil {
+		return "", false
+	}
+	switch v := scalar.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case nil:
+		return "null", true
+	default:
+		return "", false
+	}
+}
+
 func normalizeJSON(value string) string {
 	var pretty bytes.Buffer
 	if err := json.Indent(&pretty, []byte(value), "", "  "); err != nil {
@@ -289,3 +359,94 @@ func normalizeJSON(value string) string {
 	}
 	return pretty.String()
 }
+
+// printRPCResult writes one RPC response to stdout in the requested format.
+// Binary formats write the wire bytes as-is, without a trailing newline.
+func printRPCResult(format Format, method string, result *grpcclient.CallResult) int {
+	if isProtobin(format) {
+		if err := writeProtobin(os.Stdout, format, result.Wire); err != nil {
+			fmt.Fprintf(os.Stderr, "op: write output: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	if streamsJSONArray(format) {
+		if err := writeJSONArray(os.Stdout, []byte(result.Output)); err != nil {
+			fmt.Fprintf(os.Stderr, "op: write output: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	out, err := renderRPCOutput(format, method, []byte(result.Output))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op: %v\n", err)
+		return 1
+	}
+	fmt.Println(out)
+	return 0
+}
+
+// streamEmitter prints each response of a streaming call as it arrives: one
+// JSON object per line by default, rendered through --template when one is
+// set, as elements of one JSON array with --format json-stream, or
+// length-prefixed with --format protobin-delimited. end finishes the output
+// once the call returns, whether it succeeded or not: it closes the
+// json-stream array and does nothing for the other formats.
+func streamEmitter(format Format, method string) (emit func(*grpcclient.CallResult) error, end func() error) {
+	if streamsJSONArray(format) {
+		array := newJSONArrayWriter(os.Stdout)
+		return func(result *grpcclient.CallResult) error {
+			return array.Add([]byte(result.Output))
+		}, array.Close
+	}
+	return func(result *grpcclient.CallResult) error {
+		switch {
+		case format == FormatProtobin:
+			return fmt.Errorf("%s streams its responses; use --format protobin-delimited", method)
+		case isProtobin(format):
+			return writeProtobin(os.Stdout, format, result.Wire)
+		case outputTemplate != nil:
+			out, err := renderOutputTemplate(outputTemplate, []byte(result.Output))
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Println(out)
+			return err
+		default:
+			return grpcclient.WriteNDJSON(os.Stdout)(result)
+		}
+	}, func() error { return nil }
+}
+
+// renderRPCOutput renders one response through --template when one is set,
+// and through the built-in formats otherwise.
+func renderRPCOutput(format Format, method string, payload []byte) (string, error) {
+	if outputTemplate != nil {
+		return renderOutputTemplate(outputTemplate, payload)
+	}
+	return formatRPCOutput(format, method, payload), nil
+}
+
+func writeProtobin(w io.Writer, format Format, wire []byte) error {
+	if format == FormatProtobinDelimited {
+		if _, err := w.Write(protowire.AppendVarint(nil, uint64(len(wire)))); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(wire)
+	return err
+}
+
+// rpcErrorMessage renders an RPC failure with its gRPC status code up front,
+// e.g. "[NotFound] call /op.v1.OPService/ShowIdentity: holon not found".
+// Errors that carry no gRPC status (dial, exec, argument errors) are returned
+// unchanged.
+func rpcErrorMessage(err error) string {
+	st, ok := status.FromError(err)
+	if !ok || st == nil {
+		return err.Error()
+	}
+	code := st.Code().String()
+	msg := strings.Replace(err.Error(), "rpc error: code = "+code+" desc = ", "", 1)
+	return "[" + code + "] " + msg
+}
@@ -1,10 +1,19 @@
 package cli
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
 	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestFormatResponse_ListIdentitiesText(t *testing.T) {
@@ -98,3 +107,68 @@ func TestFormatRPCOutput_MethodAwareText(t *testing.T) {
 		t.Fatalf("expected text formatting, got: %q", out)
 	}
 }
+
+func TestFormatRPCOutput_ScalarText(t *testing.T) {
+	cases := map[string]string{
+		`"ok"`:         "ok",
+		`"line\nnext"`: "line\nnext",
+		`42.5`:         "42.5",
+		`true`:         "true",
+		`null`:         "null",
+		`plain text`:   "plain text",
+	}
+	for payload, want := range cases {
+		if got := formatRPCOutput(FormatText, "Echo", []byte(payload)); got != want {
+			t.Fatalf("text output for %s = %q, want %q", payload, got, want)
+		}
+	}
+
+	if got := formatRPCOutput(FormatJSON, "Echo", []byte(` "ok" `)); got != `"ok"` {
+		t.Fatalf("json output = %q, want the quoted JSON string", got)
+	}
+}
+
+func TestRPCErrorMessageShowsStatusCode(t *testing.T) {
+	wrapped := fmt.Errorf("call /op.v1.OPService/ShowIdentity: %w", status.Error(codes.NotFound, "holon abc not found"))
+	if got, want := rpcErrorMessage(wrapped), "[NotFound] call /op.v1.OPService/ShowIdentity: holon abc not found"; got != want {
+		t.Fatalf("rpcErrorMessage = %q, want %q", got, want)
+	}
+
+	plain := errors.New("start ./demo: permission denied")
+	if got := rpcErrorMessage(plain); got != plain.Error() {
+		t.Fatalf("rpcErrorMessage(non-RPC) = %q, want unchanged", got)
+	}
+}
+
+func TestPrintRPCResultProtobinWritesWireBytes(t *testing.T) {
+	resp := &opv1.ShowIdentityResponse{FilePath: "holons/alpha/holon.yaml"}
+	wire, err := proto.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &grpcclient.CallResult{Output: `{"filePath":"holons/alpha/holon.yaml"}`, Wire: wire}
+
+	raw := captureStdout(t, func() {
+		if code := printRPCResult(FormatProtobin, "ShowIdentity", result); code != 0 {
+			t.Fatalf("printRPCResult returned %d", code)
+		}
+	})
+	if raw != string(wire) {
+		t.Fatalf("protobin output = %q, want exact wire bytes %q", raw, wire)
+	}
+
+	delimited := captureStdout(t, func() {
+		printRPCResult(FormatProtobinDelimited, "ShowIdentity", result)
+		printRPCResult(FormatProtobinDelimited, "ShowIdentity", result)
+	})
+	reader := bufio.NewReader(strings.NewReader(delimited))
+	for i := 0; i < 2; i++ {
+		var got opv1.ShowIdentityResponse
+		if err := protodelim.UnmarshalFrom(reader, &got); err != nil {
+			t.Fatalf("read delimited message %d: %v", i, err)
+		}
+		if got.GetFilePath() != resp.GetFilePath() {
+			t.Fatalf("message %d file_path = %q", i, got.GetFilePath())
+		}
+	}
+}
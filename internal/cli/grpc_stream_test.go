@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestGRPCStreamsNDJSONBothWays(t *testing.T) {
+	address := startSlowHealthServer(t, 0)
+
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(grpc_reflection_v1alpha.File_grpc_reflection_v1alpha_reflection_proto),
+	}}
+	data, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	descriptorSet := filepath.Join(t.TempDir(), "reflection.pb")
+	if err := os.WriteFile(descriptorSet, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	previous := stdinInput
+	stdinInput = strings.NewReader("{\"listServices\":\"\"}\n{\"listServices\":\"\"}\n")
+	t.Cleanup(func() { stdinInput = previous })
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"grpc://" + address, "ServerReflectionInfo", "--descriptor-set", descriptorSet}, "0.1.0-test"); code != 0 {
+			t.Fatalf("streaming call returned %d, want 0", code)
+		}
+	})
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("stdout = %q, want one JSON line per request", stdout)
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "{") || !strings.Contains(line, "grpc.health.v1.Health") {
+			t.Fatalf("line %q is not a listServices response", line)
+		}
+	}
+
+	stdinInput = strings.NewReader("{\"listServices\":\"\"}\n{\"listServices\":\"\"}\n")
+	stdout = captureStdout(t, func() {
+		if code := Run([]string{"--format", "json-stream", "grpc://" + address, "ServerReflectionInfo", "--descriptor-set", descriptorSet}, "0.1.0-test"); code != 0 {
+			t.Fatalf("json-stream call returned %d, want 0", code)
+		}
+	})
+	var responses []map[string]any
+	if err := json.Unmarshal([]byte(stdout), &responses); err != nil || len(responses) != 2 {
+		t.Fatalf("stdout = %q, want one JSON array of two responses (%v)", stdout, err)
+	}
+
+	stdinInput = strings.NewReader("")
+	stdout = captureStdout(t, func() {
+		if code := Run([]string{"--format", "json-stream", "grpc://" + address, "ServerReflectionInfo", "--descriptor-set", descriptorSet}, "0.1.0-test"); code != 0 {
+			t.Fatalf("json-stream call without requests returned %d, want 0", code)
+		}
+	})
+	if stdout != "[]\n" {
+		t.Fatalf("stdout = %q, want an empty array", stdout)
+	}
+
+	stdinInput = strings.NewReader("{\"listServices\":\"\"}\n")
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"--format", "protobin", "grpc://" + address, "ServerReflectionInfo", "--descriptor-set", descriptorSet}, "0.1.0-test"); code != 1 {
+			t.Fatalf("protobin stream returned %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "protobin-delimited") {
+		t.Fatalf("stderr = %q, want a hint towards protobin-delimited", stderr)
+	}
+}
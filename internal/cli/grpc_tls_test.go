@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// testPKI is a throwaway CA with a server certificate for 127.0.0.1 and a
+// client certificate, written as PEM files under dir.
+type testPKI struct {
+	dir    string
+	pool   *x509.CertPool
+	server tls.Certificate
+}
+
+func newTestPKI(t *testing.T) testPKI {
+	t.Helper()
+	dir := t.TempDir()
+
+	caKey := newTestKey(t)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "op test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, filepath.Join(dir, "ca.pem"), "CERTIFICATE", caDER)
+
+	issue := func(serial int64, name string, usage x509.ExtKeyUsage) tls.Certificate {
+		key := newTestKey(t)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writePEM(t, filepath.Join(dir, name+".pem"), "CERTIFICATE", der)
+		writePEM(t, filepath.Join(dir, name+"-key.pem"), "EC PRIVATE KEY", keyDER)
+		return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+	pki := testPKI{dir: dir, pool: pool, server: issue(2, "server", x509.ExtKeyUsageServerAuth)}
+	issue(3, "client", x509.ExtKeyUsageClientAuth)
+	return pki
+}
+
+func (p testPKI) path(name string) string {
+	return filepath.Join(p.dir, name)
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// startTLSHealthServer serves health and reflection over TLS. With
+// requireClient, it only accepts clients presenting a certificate from the
+// test CA.
+func startTLSHealthServer(t *testing.T, pki testPKI, requireClient bool) string {
+	t.Helper()
+
+	conf := &tls.Config{Certificates: []tls.Certificate{pki.server}}
+	if requireClient {
+		conf.ClientAuth = tls.RequireAndVerifyClientCert
+		conf.ClientCAs = pki.pool
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(grpc.Creds(credentials.NewTLS(conf)))
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	reflection.Register(s)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String()
+}
+
+func TestGRPCSCallsOverVerifiedTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	address := startTLSHealthServer(t, pki, false)
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"grpcs://" + address, "Check", "--cacert", pki.path("ca.pem")}, "0.1.0-test"); code != 0 {
+			t.Fatalf("grpcs call returned %d, want 0", code)
+		}
+	})
+	if !strings.Contains(stdout, "SERVING") {
+		t.Fatalf("stdout = %q", stdout)
+	}
+
+	listing := captureStdout(t, func() {
+		if code := Run([]string{"grpcs://" + address, "--cacert=" + pki.path("ca.pem")}, "0.1.0-test"); code != 0 {
+			t.Fatalf("grpcs method listing returned %d, want 0", code)
+		}
+	})
+	if !strings.Contains(listing, "grpc.health.v1.Health/Check") {
+		t.Fatalf("listing = %q", listing)
+	}
+
+	// Without the test CA, the certificate cannot be verified.
+	_ = captureStderr(t, func() {
+		if code := Run([]string{"grpcs://" + address, "Check"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("unverifiable server returned %d, want 1", code)
+		}
+	})
+}
+
+func TestGRPCSPresentsClientCertificate(t *testing.T) {
+	pki := newTestPKI(t)
+	address := startTLSHealthServer(t, pki, true)
+
+	_ = captureStderr(t, func() {
+		if code := Run([]string{"grpcs://" + address, "Check", "--cacert", pki.path("ca.pem")}, "0.1.0-test"); code != 1 {
+			t.Fatalf("call without a client certificate returned %d, want 1", code)
+		}
+	})
+	stdout := captureStdout(t, func() {
+		args := []string{"grpcs://" + address, "Check", "--cacert", pki.path("ca.pem"), "--cert", pki.path("client.pem"), "--key", pki.path("client-key.pem")}
+		if code := Run(args, "0.1.0-test"); code != 0 {
+			t.Fatalf("mutual TLS call returned %d, want 0", code)
+		}
+	})
+	if !strings.Contains(stdout, "SERVING") {
+		t.Fatalf("stdout = %q", stdout)
+	}
+}
+
+func TestGRPCSReportsPlaintextServer(t *testing.T) {
+	address := startSlowHealthServer(t, 0)
+
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"grpcs://" + address, "Check"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("grpcs to a plaintext server returned %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "answered in plaintext, not TLS; use grpc://") {
+		t.Fatalf("stderr = %q", stderr)
+	}
+
+	stderr = captureStderr(t, func() {
+		if code := Run([]string{"grpc://" + address, "Check", "--cacert", "ca.pem"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("--cacert on grpc:// returned %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "only apply to grpcs://") {
+		t.Fatalf("stderr = %q", stderr)
+	}
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/organic-programming/go-holons/pkg/transport"
+)
+
+// startOPWebSocketServer serves OP over ws:// on path and returns the
+// listener's host:port.
+func startOPWebSocketServer(t *testing.T, path string) string {
+	t.Helper()
+
+	lis, err := transport.Listen("ws://127.0.0.1:0" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serveOP(t, lis)
+
+	hostPort := strings.TrimPrefix(lis.Addr().String(), "ws://")
+	hostPort, _, _ = strings.Cut(hostPort, "/")
+	return hostPort
+}
+
+func seedWebSocketTestHolon(t *testing.T) {
+	t.Helper()
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "alpha", givenName: "Alpha", familyName: "Holon", lang: "go"})
+}
+
+func TestWebSocketDialURI(t *testing.T) {
+	for uri, want := range map[string]string{
+		"grpc+ws://127.0.0.1:9090":          "ws://127.0.0.1:9090/grpc",
+		"grpc+ws://127.0.0.1:9090/custom":   "ws://127.0.0.1:9090/custom",
+		"grpc+wss://example.com:443":        "wss://example.com:443/grpc",
+		"grpc+wss://example.com:443/api/ws": "wss://example.com:443/api/ws",
+		"grpc+ws://localhost:9090/grpc":     "ws://localhost:9090/grpc",
+	} {
+		if got := webSocketDialURI(uri); got != want {
+			t.Errorf("webSocketDialURI(%q) = %q, want %q", uri, got, want)
+		}
+	}
+}
+
+func TestGRPCWebSocketDispatchDefaultsToGRPCPath(t *testing.T) {
+	seedWebSocketTestHolon(t)
+	address := startOPWebSocketServer(t, "")
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"--format", "json", "grpc+ws://" + address, "Discover"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("grpc+ws Discover returned %d, want 0", code)
+		}
+	})
+	var resp map[string]any
+	if err := json.Unmarshal([]byte(stdout), &resp); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, stdout)
+	}
+	if !strings.Contains(stdout, "Alpha") {
+		t.Fatalf("Discover output missing the seeded holon:\n%s", stdout)
+	}
+
+	stdout = captureStdout(t, func() {
+		if code := Run([]string{"grpc+ws://" + address + "/grpc", "Discover"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("grpc+ws with an explicit /grpc path returned %d, want 0", code)
+		}
+	})
+	if !strings.Contains(stdout, "Alpha") {
+		t.Fatalf("text output missing the seeded holon:\n%s", stdout)
+	}
+
+	stdout = captureStdout(t, func() {
+		if code := Run([]string{"grpc+ws://" + address, "op.v1.OPService/Discover"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("grpc+ws with a qualified method returned %d, want 0", code)
+		}
+	})
+	if !strings.Contains(stdout, "Alpha") {
+		t.Fatalf("qualified Discover output missing the seeded holon:\n%s", stdout)
+	}
+}
+
+func TestGRPCWebSocketDispatchKeepsExplicitPath(t *testing.T) {
+	seedWebSocketTestHolon(t)
+	address := startOPWebSocketServer(t, "/api/ws")
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"--format", "json", "grpc+ws://" + address + "/api/ws", "Discover"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("grpc+ws on /api/ws returned %d, want 0", code)
+		}
+	})
+	if !strings.Contains(stdout, "Alpha") {
+		t.Fatalf("Discover output missing the seeded holon:\n%s", stdout)
+	}
+
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"grpc+ws://" + address, "Discover"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("grpc+ws on the default path returned %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "ws://"+address+"/grpc") {
+		t.Fatalf("stderr should name the defaulted /grpc URL: %q", stderr)
+	}
+}
+
+func TestGRPCWebSocketSecureDispatchDefaultsToGRPCPath(t *testing.T) {
+	// Nothing listens on a port that was just released, so the dial fails and
+	// its error shows the URL op derived from grpc+wss://.
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := lis.Addr().String()
+	_ = lis.Close()
+
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"grpc+wss://" + address, "Discover"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("grpc+wss to a closed port returned %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "wss://"+address+"/grpc") {
+		t.Fatalf("stderr should name wss://%s/grpc: %q", address, stderr)
+	}
+}
+
+func TestGRPCWebSocketDispatchRequiresMethod(t *testing.T) {
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"grpc+ws://127.0.0.1:1"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("grpc+ws without a method returned %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "method required") {
+		t.Fatalf("stderr = %q, want a missing-method error", stderr)
+	}
+}
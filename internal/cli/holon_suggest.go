@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/organic-programming/grace-op/internal/didyoumean"
+	"github.com/organic-programming/grace-op/internal/holons"
+)
+
+// holonNotFoundError reports name as unknown and, when it looks like a typo,
+// suggests the closest known holon names.
+func holonNotFoundError(name string) error {
+	msg := fmt.Sprintf("holon %q not found", name)
+	return errors.New(didyoumean.WithHint(msg, didyoumean.Closest(name, knownHolonNames())))
+}
+
+// knownHolonNames collects the names a user could have meant: discovered
+// slugs, their aliases and binary names, and artifacts installed in OPBIN.
+func knownHolonNames() []string {
+	var names []string
+	local, _ := holons.DiscoverLocalHolons()
+	cached, _ := holons.DiscoverCachedHolons()
+	for _, h := range append(local, cached...) {
+		names = append(names, h.Identity.Slug(), filepath.Base(h.Dir))
+		names = append(names, h.Identity.Aliases...)
+		if h.Manifest != nil {
+			names = append(names, h.Manifest.BinaryName())
+		}
+	}
+	for _, entry := range holons.DiscoverInOPBIN() {
+		name, _, _ := strings.Cut(entry, " -> ")
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+
+	"gopkg.in/yaml.v3"
+)
+
+// holonConfigFile is the workspace config `op init` writes, read from the
+// current directory.
+const holonConfigFile = ".holonconfig"
+
+// holonConfig is the part of .holonconfig op reads. Other keys are ignored.
+type holonConfig struct {
+	Timeouts   map[string]string `yaml:"timeouts"`
+	Transports map[string]string `yaml:"transports"`
+}
+
+// readHolonConfig parses path. A missing file is an empty config.
+func readHolonConfig(path string) (holonConfig, error) {
+	var cfg holonConfig
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// holonSettings is .holonconfig with its values parsed.
+type holonSettings struct {
+	Timeouts   map[string]time.Duration
+	Transports map[string]*TransportOverride
+}
+
+// loadHolonConfig reads path once and parses both of its sections. A
+// missing file is an empty config. Each section that does not parse is
+// reported, and left empty in the result.
+func loadHolonConfig(path string) (holonSettings, error) {
+	cfg, err := readHolonConfig(path)
+	if err != nil {
+		return holonSettings{}, err
+	}
+	timeouts, timeoutsErr := cfg.methodTimeouts(path)
+	transports, transportsErr := cfg.transportOverrides(path)
+	return holonSettings{Timeouts: timeouts, Transports: transports}, errors.Join(timeoutsErr, transportsErr)
+}
+
+// methodTimeouts parses the timeouts map read from path.
+func (cfg holonConfig) methodTimeouts(path string) (map[string]time.Duration, error) {
+	if len(cfg.Timeouts) == 0 {
+		return nil, nil
+	}
+	timeouts := make(map[string]time.Duration, len(cfg.Timeouts))
+	for method, value := range cfg.Timeouts {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("%s: timeouts: invalid duration %q for %s", path, value, method)
+		}
+		timeouts[method] = timeout
+	}
+	return timeouts, nil
+}
+
+// transportOverrides parses the transports map read from path, keyed by
+// lower-cased holon name. A value is a bare scheme, which forces that step
+// of the chain, or a tcp://, grpc:// or unix:// address, which is dialed
+// instead of launching the holon.
+func (cfg holonConfig) transportOverrides(path string) (map[string]*TransportOverride, error) {
+	if len(cfg.Transports) == 0 {
+		return nil, nil
+	}
+	overrides := make(map[string]*TransportOverride, len(cfg.Transports))
+	for holon, value := range cfg.Transports {
+		override, err := parseConfigTransport(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: transports: %s: %w", path, holon, err)
+		}
+		override.Holon = holon
+		overrides[strings.ToLower(strings.TrimSpace(holon))] = override
+	}
+	return overrides, nil
+}
+
+// withHolonConfig runs a command that calls or dispatches to holons with
+// the timeouts and transports of .holonconfig applied. Only those commands
+// read the file, so a broken one fails them alone; op env reports it and
+// op init --force replaces it.
+func withHolonConfig(run func() int) int {
+	settings, err := loadHolonConfig(holonConfigFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op: %v\n", err)
+		return 1
+	}
+	defer pinMethodTimeouts(settings.Timeouts)()
+	defer pinConfigTransports(settings.Transports)()
+	return run()
+}
+
+// configTransports holds the .holonconfig transports of this invocation.
+var configTransports map[string]*TransportOverride
+
+// pinConfigTransports makes overrides the transports the chain falls back
+// to after --transport and OP_TRANSPORT. release restores the ones before.
+func pinConfigTransports(overrides map[string]*TransportOverride) func() {
+	previous := configTransports
+	configTransports = overrides
+	return func() { configTransports = previous }
+}
+
+// pinMethodTimeouts applies timeouts to every call this invocation makes.
+// release restores the timeouts set before.
+func pinMethodTimeouts(timeouts map[string]time.Duration) func() {
+	if len(timeouts) == 0 {
+		return func() {}
+	}
+	return grpcclient.SetMethodTimeouts(timeouts)
+}
+
+// pinCallTimeout makes timeout bound every call this invocation makes,
+// taking precedence over .holonconfig. release restores the deadline set
+// before.
+func pinCallTimeout(timeout time.Duration) func() {
+	if timeout <= 0 {
+		return func() {}
+	}
+	return grpcclient.SetCallTimeout(timeout)
+}
+
+// pinTimeout makes timeout, from the global --timeout, the deadline every
+// connection and call of this invocation gets instead of its default.
+// release restores the deadline set before.
+func pinTimeout(timeout time.Duration) func() {
+	if timeout <= 0 {
+		return func() {}
+	}
+	return grpcclient.SetTimeout(timeout)
+}
+
+// extractTimeoutFlag removes --timeout from args and returns its duration.
+func extractTimeoutFlag(args []string) (time.Duration, []string, error) {
+	var timeout time.Duration
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		if name != "--timeout" {
+			remaining = append(remaining, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return 0, nil, fmt.Errorf("--timeout requires a duration")
+			}
+			value = args[i+1]
+			i++
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			return 0, nil, fmt.Errorf("invalid --timeout %q", value)
+		}
+		timeout = parsed
+	}
+	return timeout, remaining, nil
+}
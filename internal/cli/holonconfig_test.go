@@ -0,0 +1,265 @@
+package cli
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// startSlowHealthServer serves health checks that take delay to answer.
+func startSlowHealthServer(t *testing.T, delay time.Duration) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if info.FullMethod == healthpb.Health_Check_FullMethodName {
+			time.Sleep(delay)
+		}
+		return handler(ctx, req)
+	}))
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	reflection.Register(s)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String()
+}
+
+func TestHolonConfigTimeoutsBoundCallsUnlessOverridden(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	config := "timeouts:\n  grpc.health.v1.Health/Check: 50ms\n"
+	if err := os.WriteFile(filepath.Join(root, holonConfigFile), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	address := startSlowHealthServer(t, 300*time.Millisecond)
+
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"grpc://" + address, "Check"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("code = %d, want 1 past the configured timeout", code)
+		}
+	})
+	if !strings.Contains(stderr, "DeadlineExceeded") {
+		t.Fatalf("stderr = %q, want a deadline error", stderr)
+	}
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"grpc://" + address, "Check", "--timeout", "5s"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("code = %d, want 0 with --timeout overriding the config", code)
+		}
+	})
+	if !strings.Contains(stdout, "SERVING") {
+		t.Fatalf("stdout = %q", stdout)
+	}
+}
+
+func TestLoadHolonConfigRejectsInvalidDurations(t *testing.T) {
+	dir := t.TempDir()
+	if settings, err := loadHolonConfig(filepath.Join(dir, holonConfigFile)); err != nil || settings.Timeouts != nil {
+		t.Fatalf("missing config = %v, %v; want no timeouts", settings.Timeouts, err)
+	}
+
+	path := filepath.Join(dir, holonConfigFile)
+	if err := os.WriteFile(path, []byte("timeouts:\n  Infer: soon\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadHolonConfig(path); err == nil || !strings.Contains(err.Error(), `"soon"`) {
+		t.Fatalf("error = %v, want the invalid duration named", err)
+	}
+}
+
+func TestBrokenHolonConfigFailsOnlyCalls(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	if err := os.WriteFile(holonConfigFile, []byte("timeouts:\n  Infer: soon\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	captureStdout(t, func() {
+		if code := Run([]string{"version"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("version returned %d, want 0 despite the broken config", code)
+		}
+	})
+
+	var code int
+	var stdout string
+	stderr := captureStderr(t, func() {
+		stdout = captureStdout(t, func() { code = Run([]string{"env"}, "0.1.0-test") })
+	})
+	if code != 1 || !strings.Contains(stdout, "SETTING") || !strings.Contains(stderr, `"soon"`) {
+		t.Fatalf("env = %d, stdout %q, stderr %q; want the settings and the config error", code, stdout, stderr)
+	}
+
+	stderr = captureStderr(t, func() {
+		code = Run([]string{"grpc://127.0.0.1:1", "Check"}, "0.1.0-test")
+	})
+	if code != 1 || !strings.Contains(stderr, `"soon"`) {
+		t.Fatalf("call = %d, stderr %q; want the config error", code, stderr)
+	}
+}
+
+func TestHolonConfigTransportDialsUnixSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets are not supported on Windows")
+	}
+	root := t.TempDir()
+	chdirForTest(t, root)
+	t.Setenv("OPPATH", t.TempDir())
+	t.Setenv(envTransport, "")
+	// No binary is built: the call must reach the socket, not launch a holon.
+	writeRunServiceFixture(t, filepath.Join(root, "who"), "who")
+
+	// t.TempDir paths can exceed the unix socket path limit.
+	socketDir, err := os.MkdirTemp("", "op-who")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(socketDir) })
+	socket := filepath.Join(socketDir, "who.sock")
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	reflection.Register(s)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	config := "transports:\n  who: unix://" + socket + "\n"
+	if err := os.WriteFile(filepath.Join(root, holonConfigFile), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"who", "Check"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("code = %d, want 0 through the configured socket", code)
+		}
+	})
+	if !strings.Contains(stdout, "SERVING") {
+		t.Fatalf("stdout = %q", stdout)
+	}
+
+	stdout = captureStdout(t, func() {
+		if code := Run([]string{"which", "who"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("op which returned %d", code)
+		}
+	})
+	if !strings.Contains(stdout, ".holonconfig transports.who=unix://"+socket) {
+		t.Fatalf("op which output = %q, want the configured endpoint", stdout)
+	}
+}
+
+func TestHolonConfigTransportReportsMissingUnixSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets are not supported on Windows")
+	}
+	root := t.TempDir()
+	chdirForTest(t, root)
+	t.Setenv("OPPATH", t.TempDir())
+	t.Setenv(envTransport, "")
+	writeRunServiceFixture(t, filepath.Join(root, "who"), "who")
+
+	socket := filepath.Join(root, "gone.sock")
+	config := "transports:\n  who: unix://" + socket + "\n"
+	if err := os.WriteFile(filepath.Join(root, holonConfigFile), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"who", "Check"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("code = %d, want 1 without a socket", code)
+		}
+	})
+	if !strings.Contains(stderr, "unix socket "+socket+" does not exist") {
+		t.Fatalf("stderr = %q", stderr)
+	}
+}
+
+func TestLoadHolonConfigTransports(t *testing.T) {
+	path := filepath.Join(t.TempDir(), holonConfigFile)
+	config := "transports:\n  Sophia-Who: stdio\n  atlas: grpc://localhost:9090\n  mem-holon: mem://\n"
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	settings, err := loadHolonConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	overrides := settings.Transports
+	for name, want := range map[string]TransportOverride{
+		"sophia-who": {Scheme: "stdio", Source: "config", Holon: "Sophia-Who"},
+		"atlas":      {Scheme: "tcp", Endpoint: "tcp://localhost:9090", Source: "config", Holon: "atlas"},
+		"mem-holon":  {Scheme: "mem", Source: "config", Holon: "mem-holon"},
+	} {
+		if got := overrides[name]; got == nil || *got != want {
+			t.Fatalf("%s = %+v, want %+v", name, got, want)
+		}
+	}
+
+	for _, value := range []string{"carrier-pigeon", "http://localhost:9090", "tcp://localhost"} {
+		if err := os.WriteFile(path, []byte("transports:\n  who: "+value+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadHolonConfig(path); err == nil || !strings.Contains(err.Error(), "transports: who") {
+			t.Fatalf("%s: error = %v, want it rejected", value, err)
+		}
+	}
+}
+
+func TestNestedConfigTransportsPinRestoresTheOuterOverrides(t *testing.T) {
+	outer := map[string]*TransportOverride{"who": {Scheme: "stdio", Source: "config", Holon: "who"}}
+	releaseOuter := pinConfigTransports(outer)
+	defer releaseOuter()
+
+	releaseInner := pinConfigTransports(map[string]*TransportOverride{"who": {Scheme: "mem", Source: "config", Holon: "who"}})
+	if got := configTransports["who"].Scheme; got != "mem" {
+		t.Fatalf("scheme = %q inside the inner pin, want mem", got)
+	}
+	releaseInner()
+	if got := configTransports["who"]; got == nil || got.Scheme != "stdio" {
+		t.Fatalf("override = %+v after the inner release, want the outer stdio", got)
+	}
+
+	releaseOuter()
+	if configTransports != nil {
+		t.Fatalf("configTransports = %v after both releases, want none", configTransports)
+	}
+}
+
+func TestGlobalTimeoutBoundsConnectAndCall(t *testing.T) {
+	for _, value := range []string{"0s", "-1s", "soon", "10"} {
+		if _, _, err := parseGlobalFlags([]string{"--timeout", value, "list"}); err == nil || !strings.Contains(err.Error(), "invalid duration") {
+			t.Fatalf("--timeout %s: error = %v, want it rejected", value, err)
+		}
+	}
+	opts, _, err := parseGlobalFlags([]string{"--timeout=2m", "list"})
+	if err != nil || opts.Timeout != 2*time.Minute {
+		t.Fatalf("opts = %+v, err = %v", opts, err)
+	}
+
+	address := startSlowHealthServer(t, 300*time.Millisecond)
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"--timeout", "50ms", "grpc://" + address, "Check"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("code = %d, want 1 past the global timeout", code)
+		}
+	})
+	if !strings.Contains(stderr, "DeadlineExceeded") {
+		t.Fatalf("stderr = %q, want a deadline error", stderr)
+	}
+	if got := grpcclient.Timeout(); got != grpcclient.DefaultTimeout {
+		t.Fatalf("timeout after the run = %s, want the default restored", got)
+	}
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const initUsage = "usage: op init [<dir>] [--force]"
+
+// holonConfigTemplate is the commented .holonconfig written by `op init`.
+// Everything is commented out, and it documents only the sections op
+// reads.
+const holonConfigTemplate = `# .holonconfig — workspace settings for op.
+#
+# This is a template: every setting below is commented out. Discovery roots
+# (this directory and $OPPATH/cache) and aliases (each holon's holon.yaml)
+# are not set here; op env lists them.
+
+# Transport overrides: how op reaches a holon instead of the default
+# mem -> stdio -> tcp chain. A scheme forces that step of the chain; a
+# tcp:// or unix:// address is dialed instead of launching the holon.
+# --transport and OP_TRANSPORT override them for one invocation.
+# transports:
+#   sophia-who: stdio
+#   atlas-holon: tcp://localhost:9090
+
+# Timeouts: call deadlines for methods slower or faster than the default,
+# keyed by package.Service/Method, Service/Method or a bare method name.
+# op grpc --timeout overrides them for one invocation.
+# timeouts:
+#   Infer: 2m
+#   op.v1.OPService/ListIdentities: 3s
+`
+
+// cmdInit runs `op init`: it creates a holons/ directory and a commented
+// .holonconfig template, refusing to overwrite an existing config unless
+// --force is given.
+func cmdInit(args []string) int {
+	dir, force, err := parseInitArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op init: %v\n", err)
+		fmt.Fprintln(os.Stderr, initUsage)
+		return 1
+	}
+
+	holonsDir := filepath.Join(dir, "holons")
+	configPath := filepath.Join(dir, ".holonconfig")
+
+	if _, err := os.Stat(configPath); err == nil && !force {
+		fmt.Fprintf(os.Stderr, "op init: %s already exists (use --force to overwrite)\n", configPath)
+		return 1
+	}
+	if err := os.MkdirAll(holonsDir, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "op init: %v\n", err)
+		return 1
+	}
+	if err := os.WriteFile(configPath, []byte(holonConfigTemplate), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "op init: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Created %s/\n", holonsDir)
+	fmt.Printf("Created %s\n", configPath)
+	fmt.Println()
+	fmt.Println("Next steps:")
+	fmt.Println("  op new                 create a holon identity")
+	fmt.Println("  op discover            list the holons op can find")
+	return 0
+}
+
+func parseInitArgs(args []string) (string, bool, error) {
+	force := false
+	var positional []string
+	for _, arg := range args {
+		switch {
+		case arg == "--force":
+			force = true
+		case strings.HasPrefix(arg, "-"):
+			return "", false, fmt.Errorf("unknown flag %q", arg)
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	switch len(positional) {
+	case 0:
+		return ".", force, nil
+	case 1:
+		return positional[0], force, nil
+	default:
+		return "", false, fmt.Errorf("accepts at most one directory")
+	}
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitCreatesHolonsDirAndConfig(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"init"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("code = %d, want 0", code)
+		}
+	})
+
+	if info, err := os.Stat(filepath.Join(root, "holons")); err != nil || !info.IsDir() {
+		t.Fatalf("holons/ not created: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, ".holonconfig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# transports:", "# timeouts:"} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf(".holonconfig missing %q:\n%s", want, data)
+		}
+	}
+	// op does not read these sections, so the template does not offer them.
+	for _, unread := range []string{"# roots:", "# aliases:"} {
+		if strings.Contains(string(data), unread) {
+			t.Fatalf(".holonconfig offers %q, which op ignores:\n%s", unread, data)
+		}
+	}
+	for _, want := range []string{"op new", "op discover"} {
+		if !strings.Contains(stdout, want) {
+			t.Fatalf("stdout missing hint %q:\n%s", want, stdout)
+		}
+	}
+}
+
+func TestInitRefusesToOverwriteWithoutForce(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+
+	configPath := filepath.Join(root, ".holonconfig")
+	if err := os.WriteFile(configPath, []byte("mine\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"init"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("code = %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "already exists (use --force to overwrite)") {
+		t.Fatalf("stderr = %q", stderr)
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != "mine\n" {
+		t.Fatalf(".holonconfig was overwritten: %q", data)
+	}
+
+	_ = captureStdout(t, func() {
+		if code := Run([]string{"init", "--force"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("code = %d, want 0 with --force", code)
+		}
+	})
+	if data, _ := os.ReadFile(configPath); string(data) != holonConfigTemplate {
+		t.Fatal("--force did not rewrite .holonconfig")
+	}
+}
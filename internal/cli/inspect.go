@@ -6,10 +6,10 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"time"
 
 	sdkconnect "github.com/organic-programming/go-holons/pkg/connect"
 	holonmetav1 "github.com/organic-programming/go-holons/gen/go/holonmeta/v1"
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
 	inspectpkg "github.com/organic-programming/grace-op/internal/inspect"
 )
 
@@ -95,7 +95,7 @@ func inspectRemote(address string) (*inspectpkg.Document, error) {
 	}
 	defer func() { _ = sdkconnect.Disconnect(conn) }()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), grpcclient.Timeout())
 	defer cancel()
 
 	client := holonmetav1.NewHolonMetaClient(conn)
@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
+	"github.com/organic-programming/grace-op/internal/holons"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// exitInterrupted is the exit status of a command stopped by SIGINT.
+const exitInterrupted = 130
+
+// exitTimedOut is the exit status of a command stopped by --timeout, as
+// timeout(1) reports it.
+const exitTimedOut = 124
+
+// invokeOptions shape the process of a command op dispatches to a holon,
+// locally or through the daemon's InvokeStream.
+type invokeOptions struct {
+	// Env is merged onto the inherited environment, or replaces it with
+	// ClearEnv.
+	Env      map[string]string
+	ClearEnv bool
+	// Dir is the command's working directory; empty inherits op's, or the
+	// daemon's.
+	Dir string
+	// Timeout, when positive, stops the command once it has passed.
+	Timeout time.Duration
+}
+
+// extractInvokeFlags removes --env KEY=VAL (repeatable), --clear-env, --cwd
+// and --timeout from args. Only flags ahead of the holon's command word are
+// op's: from the first other argument on, or after a "--", args belong to
+// the holon untouched.
+func extractInvokeFlags(args []string) (invokeOptions, []string, error) {
+	var opts invokeOptions
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--env", "--cwd", "--timeout":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return invokeOptions{}, nil, fmt.Errorf("%s requires a value", name)
+				}
+				value = args[i+1]
+				i++
+			}
+			if name == "--timeout" {
+				timeout, err := time.ParseDuration(value)
+				if err != nil || timeout <= 0 {
+					return invokeOptions{}, nil, fmt.Errorf("invalid --timeout %q", value)
+				}
+				opts.Timeout = timeout
+				continue
+			}
+			if name == "--cwd" {
+				if strings.TrimSpace(value) == "" {
+					return invokeOptions{}, nil, fmt.Errorf("--cwd requires a directory")
+				}
+				opts.Dir = value
+				continue
+			}
+			key, val, ok := strings.Cut(value, "=")
+			if !ok || key == "" {
+				return invokeOptions{}, nil, fmt.Errorf("--env %q: want KEY=VALUE", value)
+			}
+			if opts.Env == nil {
+				opts.Env = make(map[string]string)
+			}
+			opts.Env[key] = val
+		case "--clear-env":
+			if hasValue {
+				return invokeOptions{}, nil, fmt.Errorf("--clear-env takes no value")
+			}
+			opts.ClearEnv = true
+		case "--":
+			return opts, args[i+1:], nil
+		default:
+			return opts, args[i:], nil
+		}
+	}
+	return opts, []string{}, nil
+}
+
+// stdinHasInput reports whether op's stdin is a pipe or a file. The daemon
+// cannot forward it, so such a command runs locally. Tests replace it.
+var stdinHasInput = func() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+// daemonInvoke forwards `op <holon> <args>` to the daemon's InvokeStream and
+// prints the command's output as it arrives. The command gets what it would
+// get locally: the holon is resolved from op's working directory, and it
+// runs in that directory (or opts.Dir, made absolute) with op's environment
+// merged with opts.Env. SIGINT cancels the call, which makes the daemon
+// kill the command; so does opts.Timeout, sent in whole seconds, after
+// which op exits with exitTimedOut. ok is false when the command should run
+// locally: stdin has input to give it, or the daemon is too old to stream.
+func daemonInvoke(holon string, args []string, opts invokeOptions) (int, bool) {
+	if stdinHasInput() {
+		return 0, false
+	}
+	target, err := daemonInvokeTarget(holon)
+	if err != nil {
+		return 0, false
+	}
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return 0, false
+	}
+	conn, ok := dialDaemon()
+	if !ok {
+		return 0, false
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	stream, err := opv1.NewOPServiceClient(conn).InvokeStream(ctx, &opv1.InvokeRequest{
+		Holon:          target,
+		Args:           args,
+		Env:            callerEnv(opts),
+		WorkingDir:     dir,
+		ClearEnv:       true,
+		TimeoutSeconds: uint32(math.Ceil(opts.Timeout.Seconds())),
+	})
+	if err != nil {
+		reportDaemonFallback(holon+" "+strings.Join(args, " "), err)
+		return 0, false
+	}
+
+	code, received, err := copyInvokeChunks(stream, os.Stdout, os.Stderr)
+	switch {
+	case err == nil:
+		return int(code), true
+	case !received && status.Code(err) == codes.Unimplemented:
+		reportDaemonFallback(holon+" "+strings.Join(args, " "), err)
+		return 0, false
+	case ctx.Err() != nil:
+		return exitInterrupted, true
+	case status.Code(err) == codes.DeadlineExceeded && opts.Timeout > 0:
+		fmt.Fprintf(os.Stderr, "op: %s\n", rpcErrorMessage(err))
+		return exitTimedOut, true
+	default:
+		fmt.Fprintf(os.Stderr, "op: %s\n", rpcErrorMessage(err))
+		return 1, true
+	}
+}
+
+// daemonInvokeTarget is the holon reference the daemon resolves to the
+// binary op would run: the absolute directory of a holon found from op's
+// working directory, or holon itself for an installed or $PATH binary.
+func daemonInvokeTarget(holon string) (string, error) {
+	resolution, err := holons.TraceBinary(holon)
+	if err != nil {
+		return "", err
+	}
+	if resolution.Target == nil || resolution.Target.Dir == "" {
+		return holon, nil
+	}
+	return filepath.Abs(resolution.Target.Dir)
+}
+
+// callerEnv is the whole environment a command run locally would get, as
+// an InvokeRequest carries it: op's own with opts.Env merged onto it, or
+// opts.Env alone with opts.ClearEnv.
+func callerEnv(opts invokeOptions) map[string]string {
+	env := make(map[string]string)
+	if !opts.ClearEnv {
+		for _, entry := range os.Environ() {
+			// Windows lists per-drive directories as "=C:=C:\dir".
+			if key, value, ok := strings.Cut(entry, "="); ok && key != "" {
+				env[key] = value
+			}
+		}
+	}
+	for key, value := range opts.Env {
+		env[key] = value
+	}
+	return env
+}
+
+// copyInvokeChunks writes each InvokeChunk's data to stdout or stderr as it
+// is received and returns the exit code from the final chunk. received
+// reports whether any chunk arrived.
+func copyInvokeChunks(stream opv1.OPService_InvokeStreamClient, stdout, stderr io.Writer) (code int32, received bool, err error) {
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return 0, received, fmt.Errorf("invoke stream ended without an exit code")
+		}
+		if err != nil {
+			return 0, received, err
+		}
+		received = true
+
+		switch body := chunk.GetBody().(type) {
+		case *opv1.InvokeChunk_ExitCode:
+			return body.ExitCode, true, nil
+		case *opv1.InvokeChunk_Data:
+			w := stdout
+			if chunk.GetStream() == opv1.InvokeChunk_STDERR {
+				w = stderr
+			}
+			if _, err := w.Write(body.Data); err != nil {
+				return 0, true, err
+			}
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/organic-programming/grace-op/internal/server"
+)
+
+func TestHolonCommandLineStreamsThroughDaemon(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh and Unix sockets")
+	}
+	dir, err := os.MkdirTemp("", "opd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "op.sock")
+	t.Setenv(envDaemonSocket, socket)
+	t.Setenv(envDaemon, "on")
+	t.Setenv("OP_CALLER_ONLY", "from-op")
+	oldHasInput := stdinHasInput
+	stdinHasInput = func() bool { return false }
+	t.Cleanup(func() { stdinHasInput = oldHasInput })
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.ListenAndServeContext(ctx, "unix://"+socket, server.ServeOptions{SandboxRoot: string(filepath.Separator)})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	deadline := time.Now().Add(5 * time.Second)
+	for !daemonRunning(socket) {
+		if time.Now().After(deadline) {
+			t.Fatal("daemon socket never came up")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	work, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	chdirForTest(t, work)
+
+	var code int
+	stderr := captureStderr(t, func() {
+		stdout := captureStdout(t, func() {
+			code = Run([]string{"sh", "--", "-c", `echo "$OP_CALLER_ONLY"; pwd -P; echo err >&2; exit 4`}, "0.1.0-test")
+		})
+		if want := "from-op\n" + work + "\n"; stdout != want {
+			t.Errorf("stdout = %q, want %q: the command's output, run in op's directory and environment", stdout, want)
+		}
+	})
+	if code != 4 {
+		t.Fatalf("exit code = %d, want the command's 4", code)
+	}
+	if stderr != "err\n" {
+		t.Fatalf("stderr = %q, want the command's stderr", stderr)
+	}
+	if !strings.Contains(logs.String(), "invoke ") {
+		t.Fatalf("daemon log = %q, want the command invoked there", logs.String())
+	}
+
+	// A relative --cwd is op's, not the daemon's.
+	if err := os.Mkdir(filepath.Join(work, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	stdout := captureStdout(t, func() {
+		code = Run([]string{"sh", "--cwd", "sub", "--env", "GREETING=hi", "-c", `echo "$GREETING"; pwd -P`}, "0.1.0-test")
+	})
+	if want := "hi\n" + filepath.Join(work, "sub") + "\n"; code != 0 || stdout != want {
+		t.Fatalf("exit code = %d, stdout = %q; want %q", code, stdout, want)
+	}
+}
+
+func TestHolonCommandLineRunsLocally(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	t.Setenv(envDaemon, "")
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho \"greeter $*\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "greeter"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	chdirForTest(t, t.TempDir())
+
+	var code int
+	stdout := captureStdout(t, func() {
+		code = Run([]string{"greeter", "hello", "--loud"}, "0.1.0-test")
+	})
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	if stdout != "greeter hello --loud\n" {
+		t.Fatalf("stdout = %q, want the holon's command line run with its args", stdout)
+	}
+}
+
+func TestIsHolonCommandLine(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"build", "./cmd"}, true},
+		{[]string{"--env", "A=1", "Ping"}, true},
+		{[]string{"--timeout=5s", "sleep"}, true},
+		{[]string{"--", "-c", "true"}, true},
+		{[]string{"Ping", "{}"}, false},
+		{[]string{"list"}, false},
+		{[]string{"echo.v1.EchoService/Ping"}, false},
+		{[]string{"--call", "Ping"}, false},
+		{[]string{"-"}, false},
+		{nil, false},
+	} {
+		if got := isHolonCommandLine(tc.args); got != tc.want {
+			t.Errorf("isHolonCommandLine(%q) = %v, want %v", tc.args, got, tc.want)
+		}
+	}
+}
+
+func TestExtractInvokeFlags(t *testing.T) {
+	opts, rest, err := extractInvokeFlags([]string{"--env", "A=1", "--env=B=x=y", "--cwd", "sub", "--clear-env", "--timeout", "90s", "build", "./cmd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := invokeOptions{Env: map[string]string{"A": "1", "B": "x=y"}, ClearEnv: true, Dir: "sub", Timeout: 90 * time.Second}
+	if !reflect.DeepEqual(opts, want) || !slices.Equal(rest, []string{"build", "./cmd"}) {
+		t.Fatalf("opts = %+v, rest = %q", opts, rest)
+	}
+
+	// Past the command word, or a "--", the flags are the holon's own.
+	for _, args := range [][]string{
+		{"cmd", "--env", "A=B", "--cwd", "x", "--clear-env", "--timeout", "1s"},
+		{"--env", "C=D", "--", "--env", "A=B"},
+	} {
+		opts, rest, err := extractInvokeFlags(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(opts.Env) > 1 || opts.Dir != "" || opts.ClearEnv || opts.Timeout != 0 || slices.Contains(rest, "--") || !slices.Contains(rest, "A=B") {
+			t.Fatalf("extractInvokeFlags(%q) = %+v, %q; want the holon's flags left alone", args, opts, rest)
+		}
+	}
+	for _, bad := range [][]string{{"--env", "NOVALUE"}, {"--env", "=1"}, {"--cwd"}, {"--clear-env=1"}, {"--timeout", "soon"}} {
+		if _, _, err := extractInvokeFlags(bad); err == nil {
+			t.Fatalf("extractInvokeFlags(%q) accepted bad flags", bad)
+		}
+	}
+}
+
+func TestDispatchAppliesEnvAndCwdLocally(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	t.Setenv(envDaemon, "")
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	chdirForTest(t, dir)
+
+	var code int
+	stdout := captureStdout(t, func() {
+		code = Run([]string{"sh", "--env", "GREETING=hi", "--cwd", "sub", "-c", `echo "$GREETING"; pwd -P`}, "0.1.0-test")
+	})
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	if want := "hi\n" + filepath.Join(dir, "sub") + "\n"; stdout != want {
+		t.Fatalf("stdout = %q, want %q", stdout, want)
+	}
+}
+
+func TestDispatchPassesTrailingInvokeFlagsToHolon(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	t.Setenv(envDaemon, "")
+
+	var code int
+	stdout := captureStdout(t, func() {
+		code = cmdDispatch("sh", []string{"-c", `echo "$@"`, "cmd", "--env", "A=B", "--clear-env"})
+	})
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	if stdout != "--env A=B --clear-env\n" {
+		t.Fatalf("stdout = %q, want the holon to get --env A=B --clear-env", stdout)
+	}
+}
+
+func TestDispatchTimeoutExitsDistinctly(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	t.Setenv(envDaemon, "")
+
+	var code int
+	stderr := captureStderr(t, func() {
+		code = Run([]string{"sh", "--timeout", "200ms", "-c", "sleep 5"}, "0.1.0-test")
+	})
+	if code != exitTimedOut {
+		t.Fatalf("exit code = %d, want %d", code, exitTimedOut)
+	}
+	if !strings.Contains(stderr, "timed out after 200ms") {
+		t.Fatalf("stderr = %q, want the timeout named", stderr)
+	}
+	if code := Run([]string{"sh", "--timeout", "5s", "-c", "exit 3"}, "0.1.0-test"); code != 3 {
+		t.Fatalf("exit code = %d, want the command's own 3", code)
+	}
+}
+
+func TestHolonCommandLineStaysInOpsProcessGroup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh and process groups")
+	}
+	if _, err := exec.LookPath("ps"); err != nil {
+		t.Skip("needs ps")
+	}
+	t.Setenv(envDaemon, "")
+
+	// A command in a process group of its own would be stopped by SIGTTIN
+	// reading the terminal, and Ctrl-C would not reach it.
+	var code int
+	stdout := captureStdout(t, func() {
+		code = Run([]string{"sh", "--timeout", "5s", "--", "-c", "ps -o pgid= -p $$; ps -o pgid= -p $PPID"}, "0.1.0-test")
+	})
+	groups := strings.Fields(stdout)
+	if code != 0 || len(groups) != 2 {
+		t.Fatalf("exit code = %d, stdout = %q", code, stdout)
+	}
+	if groups[0] != groups[1] {
+		t.Fatalf("command process group = %s, op's = %s; want op's", groups[0], groups[1])
+	}
+}
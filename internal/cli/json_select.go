@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonFieldMissingError is the error selectJSONPath returns when an object
+// along the path has no such field.
+type jsonFieldMissingError struct {
+	field string
+	at    string
+}
+
+func (e *jsonFieldMissingError) Error() string {
+	return fmt.Sprintf("field %q not found at %s", e.field, e.at)
+}
+
+// selectJSONPath walks a decoded JSON value along a dotted path such as
+// "identity.given_name" or "entries[0].identity" ("entries.0.identity" works
+// too). An empty path or "." selects the whole value.
+func selectJSONPath(value any, path string) (any, error) {
+	segments, err := splitJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := value
+	walked := ""
+	for _, segment := range segments {
+		switch node := current.(type) {
+		case map[string]any:
+			next, ok := node[segment]
+			if !ok {
+				return nil, &jsonFieldMissingError{field: segment, at: jsonPathLabel(walked)}
+			}
+			current = next
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil {
+				return nil, fmt.Errorf("%s is an array; %q is not an index", jsonPathLabel(walked), segment)
+			}
+			if index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("index %d out of range at %s (length %d)", index, jsonPathLabel(walked), len(node))
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("cannot select %q from a %s at %s", segment, jsonKind(current), jsonPathLabel(walked))
+		}
+		if walked != "" {
+			walked += "."
+		}
+		walked += segment
+	}
+	return current, nil
+}
+
+func splitJSONPath(path string) ([]string, error) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), ".")
+	if path == "" {
+		return nil, nil
+	}
+
+	var segments []string
+	for _, part := range strings.Split(path, ".") {
+		name := part
+		var indexes []string
+		if i := strings.Index(part, "["); i >= 0 {
+			name = part[:i]
+			rest := part[i:]
+			for rest != "" {
+				if !strings.HasPrefix(rest, "[") {
+					return nil, fmt.Errorf("invalid path segment %q", part)
+				}
+				end := strings.Index(rest, "]")
+				if end < 0 {
+					return nil, fmt.Errorf("invalid path segment %q", part)
+				}
+				indexes = append(indexes, rest[1:end])
+				rest = rest[end+1:]
+			}
+		}
+		if name == "" && len(indexes) == 0 {
+			return nil, fmt.Errorf("invalid path %q", path)
+		}
+		if name != "" {
+			segments = append(segments, name)
+		}
+		segments = append(segments, indexes...)
+	}
+	return segments, nil
+}
+
+func jsonPathLabel(walked string) string {
+	if walked == "" {
+		return "the root"
+	}
+	return walked
+}
+
+func jsonKind(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number, float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	default:
+		return "value"
+	}
+}
+
+// decodeJSONValue decodes data for selectJSONPath. Numbers stay json.Number
+// so large integers survive being marshaled again.
+func decodeJSONValue(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
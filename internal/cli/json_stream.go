@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonArrayWriter writes responses to w as the elements of one JSON array,
+// each as soon as it is added, for --format json-stream. Unlike one object
+// per line, the output parses as a single array; Close ends it, so output
+// cut short by an error is still valid, and no elements give [].
+type jsonArrayWriter struct {
+	w      io.Writer
+	count  int
+	closed bool
+}
+
+func newJSONArrayWriter(w io.Writer) *jsonArrayWriter {
+	return &jsonArrayWriter{w: w}
+}
+
+// Add writes payload as the next element. A payload that is not JSON is
+// written as a JSON string and an empty one as null.
+func (a *jsonArrayWriter) Add(payload []byte) error {
+	if a.closed {
+		return fmt.Errorf("json-stream: array already closed")
+	}
+	element := []byte("null")
+	if raw := fanOutOutput(payload); raw != nil {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, raw); err != nil {
+			return err
+		}
+		element = compact.Bytes()
+	}
+	separator := ",\n  "
+	if a.count == 0 {
+		separator = "[\n  "
+	}
+	if _, err := io.WriteString(a.w, separator); err != nil {
+		return err
+	}
+	a.count++
+	_, err := a.w.Write(element)
+	return err
+}
+
+// Close ends the array. Later calls do nothing.
+func (a *jsonArrayWriter) Close() error {
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+	end := "\n]\n"
+	if a.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(a.w, end)
+	return err
+}
+
+// writeJSONArray writes payloads to w as one complete JSON array.
+func writeJSONArray(w io.Writer, payloads ...[]byte) error {
+	array := newJSONArrayWriter(w)
+	for _, payload := range payloads {
+		if err := array.Add(payload); err != nil {
+			_ = array.Close()
+			return err
+		}
+	}
+	return array.Close()
+}
+
+// streamsJSONArray reports whether format writes responses as one JSON array.
+// An output template replaces it, as it does every built-in format.
+func streamsJSONArray(format Format) bool {
+	return format == FormatJSONStream && outputTemplate == nil
+}
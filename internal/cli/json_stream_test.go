@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONArrayWriter(t *testing.T) {
+	var empty bytes.Buffer
+	if err := newJSONArrayWriter(&empty).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if empty.String() != "[]\n" {
+		t.Fatalf("empty array = %q, want []", empty.String())
+	}
+
+	var out bytes.Buffer
+	array := newJSONArrayWriter(&out)
+	for _, payload := range []string{"{\n  \"status\": \"SERVING\"\n}", "plain text", "", "42"} {
+		if err := array.Add([]byte(payload)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := out.String(); got != "[\n  {\"status\":\"SERVING\"},\n  \"plain text\",\n  null,\n  42" {
+		t.Fatalf("elements were not written as they were added: %q", got)
+	}
+	if err := array.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := array.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []any
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || len(decoded) != 4 {
+		t.Fatalf("output %q is not one array of four elements: %v", out.String(), err)
+	}
+	if err := array.Add([]byte("{}")); err == nil {
+		t.Fatal("adding to a closed array should fail")
+	}
+}
@@ -1,41 +1,77 @@
 package cli
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 	"sync"
-	"time"
 
 	holonsgrpcclient "github.com/organic-programming/go-holons/pkg/grpcclient"
 	"github.com/organic-programming/go-holons/pkg/transport"
 	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
 	"github.com/organic-programming/grace-op/internal/server"
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
 
 	"google.golang.org/grpc"
-	"google.golang.org/protobuf/encoding/protojson"
-	"google.golang.org/protobuf/proto"
+	"google.golang.org/grpc/reflection"
 )
 
+// memHolonComposer serves a holon in-process over a mem listener. The server
+// starts on the first dial and runs until Close; a dial after Close starts a
+// fresh one. Run closes every composer when a command ends, so a process
+// that runs several commands never carries state from one to the next. Every server also
+// serves reflection, so any method register adds can be called by name.
 type memHolonComposer struct {
 	register func(*grpc.Server)
-	callRPC  func(context.Context, *grpc.ClientConn, string, string) (string, error)
 
-	once     sync.Once
+	mu       sync.Mutex
+	server   *grpc.Server
 	listener *transport.MemListener
 }
 
+// start returns the composer's listener, starting its server if none runs.
+func (c *memHolonComposer) start() *transport.MemListener {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.listener == nil {
+		s, lis := grpc.NewServer(), transport.NewMemListener()
+		c.register(s)
+		reflection.Register(s)
+		go func() {
+			_ = s.Serve(lis)
+		}()
+		c.server, c.listener = s, lis
+	}
+	return c.listener
+}
+
+// Close stops the composer's server, closing every connection dialled to
+// it. It is a no-op when no server runs.
+func (c *memHolonComposer) Close() {
+	c.mu.Lock()
+	s := c.server
+	c.server, c.listener = nil, nil
+	c.mu.Unlock()
+	if s != nil {
+		s.Stop()
+	}
+}
+
 var sophiaMemComposer = &memHolonComposer{
 	register: registerSophiaWhoService,
-	callRPC:  callSophiaWhoRPC,
 }
 
 // memComposeRegistry maps supported third-party holon names to their in-process composer.
 var memComposeRegistry = map[string]*memHolonComposer{}
 
+// closeMemComposers stops every registered composer's server.
+func closeMemComposers() {
+	for _, composer := range memComposeRegistry {
+		composer.Close()
+	}
+}
+
 func registerSophiaWhoService(s *grpc.Server) {
 	opv1.RegisterOPServiceServer(s, &server.Server{})
 }
@@ -46,16 +82,7 @@ func dialMemHolon(ctx context.Context, holonName string) (*grpc.ClientConn, erro
 		return nil, err
 	}
 
-	composer.once.Do(func() {
-		composer.listener = transport.NewMemListener()
-		s := grpc.NewServer()
-		composer.register(s)
-		go func() {
-			_ = s.Serve(composer.listener)
-		}()
-	})
-
-	conn, err := holonsgrpcclient.DialMem(ctx, composer.listener)
+	conn, err := holonsgrpcclient.DialMem(ctx, composer.start())
 	if err != nil {
 		return nil, fmt.Errorf("dial mem composition for %q: %w", holonName, err)
 	}
@@ -76,10 +103,12 @@ func hasMemComposer(holonName string) bool {
 	return err == nil
 }
 
-func cmdGRPCMem(format Format, holonName string, args []string) int {
+// cmdGRPCMem handles mem://holon and grpc://holon when the transport chain
+// picks mem: the call goes to the holon's in-process composer.
+func cmdGRPCMem(format Format, holonName string, args []string, check connectCheck) int {
 	if len(args) < 1 {
 		fmt.Fprintln(os.Stderr, "op grpc: method required")
-		fmt.Fprintf(os.Stderr, "usage: op grpc://%s <method>\n", holonName)
+		fmt.Fprintf(os.Stderr, "usage: op mem://%s <method> [json]\n", holonName)
 		return 1
 	}
 
@@ -89,71 +118,32 @@ func cmdGRPCMem(format Format, holonName string, args []string) int {
 		inputJSON = args[1]
 	}
 
-	output, err := callViaMem(holonName, method, inputJSON)
+	result, err := explainedCall(func() (*grpcclient.CallResult, error) {
+		return callViaMem(context.Background(), holonName, method, inputJSON, check)
+	})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "op grpc: %v\n", err)
+		fmt.Fprintf(os.Stderr, "op grpc: %s\n", rpcErrorMessage(err))
 		return 1
 	}
 
-	fmt.Println(formatRPCOutput(format, method, []byte(output)))
-	return 0
+	return printRPCResult(format, method, result)
 }
 
-func callViaMem(holonName, methodName, inputJSON string) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
+// callViaMem calls method on holonName's in-process composer. The method
+// is looked up by reflection, as over any other transport, so every
+// service the composer registers can be called. check runs first, on the
+// same connection.
+func callViaMem(ctx context.Context, holonName, methodName, inputJSON string, check connectCheck) (*grpcclient.CallResult, error) {
 	conn, err := dialMemHolon(ctx, holonName)
 	if err != nil {
-		return "", err
-	}
-	defer conn.Close()
-
-	composer, err := resolveMemComposer(holonName)
-	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return composer.callRPC(ctx, conn, methodName, inputJSON)
-}
-
-func callSophiaWhoRPC(ctx context.Context, conn *grpc.ClientConn, methodName, inputJSON string) (string, error) {
-	method := canonicalMethodName(methodName)
-	client := opv1.NewOPServiceClient(conn)
-
-	switch method {
-	case "CreateIdentity":
-		req := &opv1.CreateIdentityRequest{}
-		if err := unmarshalProtoJSON(inputJSON, req); err != nil {
-			return "", fmt.Errorf("parse input JSON: %w", err)
-		}
-		resp, err := client.CreateIdentity(ctx, req)
-		if err != nil {
-			return "", err
-		}
-		return marshalProtoJSON(resp)
-	case "ShowIdentity":
-		req := &opv1.ShowIdentityRequest{}
-		if err := unmarshalProtoJSON(inputJSON, req); err != nil {
-			return "", fmt.Errorf("parse input JSON: %w", err)
-		}
-		resp, err := client.ShowIdentity(ctx, req)
-		if err != nil {
-			return "", err
-		}
-		return marshalProtoJSON(resp)
-	case "ListIdentities":
-		req := &opv1.ListIdentitiesRequest{}
-		if err := unmarshalProtoJSON(inputJSON, req); err != nil {
-			return "", fmt.Errorf("parse input JSON: %w", err)
-		}
-		resp, err := client.ListIdentities(ctx, req)
-		if err != nil {
-			return "", err
-		}
-		return marshalProtoJSON(resp)
-	default:
-		return "", fmt.Errorf("method %q not found via mem", methodName)
+	client := grpcclient.NewConnClient("mem://"+holonName, conn)
+	defer client.Close()
+	if err := verifyHolonOn(conn, "mem://"+holonName, check); err != nil {
+		return nil, err
 	}
+	return client.Call(ctx, methodName, inputJSON)
 }
 
 func canonicalMethodName(method string) string {
@@ -163,24 +153,3 @@ func canonicalMethodName(method string) string {
 	}
 	return trimmed
 }
-
-func unmarshalProtoJSON(input string, msg proto.Message) error {
-	trimmed := strings.TrimSpace(input)
-	if trimmed == "" {
-		trimmed = "{}"
-	}
-	return protojson.Unmarshal([]byte(trimmed), msg)
-}
-
-func marshalProtoJSON(msg proto.Message) (string, error) {
-	out, err := protojson.Marshal(msg)
-	if err != nil {
-		return "", err
-	}
-
-	var pretty bytes.Buffer
-	if err := json.Indent(&pretty, out, "", "  "); err != nil {
-		return string(out), nil
-	}
-	return pretty.String(), nil
-}
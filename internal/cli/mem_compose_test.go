@@ -2,7 +2,13 @@ package cli
 
 import (
 	"context"
+	"strings"
+	"sync"
 	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 func TestDialMemHolonFailsWithoutRegisteredComposer(t *testing.T) {
@@ -11,3 +17,159 @@ func TestDialMemHolonFailsWithoutRegisteredComposer(t *testing.T) {
 		t.Fatal("expected dialMemHolon to fail")
 	}
 }
+
+// registerHealthComposer registers a mem composer serving grpc.health.v1
+// under name for the duration of the test.
+func registerHealthComposer(t *testing.T, name string) *memHolonComposer {
+	t.Helper()
+	composer := &memHolonComposer{
+		register: func(s *grpc.Server) { healthpb.RegisterHealthServer(s, health.NewServer()) },
+	}
+	memComposeRegistry[name] = composer
+	t.Cleanup(func() {
+		composer.Close()
+		delete(memComposeRegistry, name)
+	})
+	return composer
+}
+
+func memHealthCheck(ctx context.Context, name string) error {
+	conn, err := dialMemHolon(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestMemComposerRestartsAfterClose(t *testing.T) {
+	composer := registerHealthComposer(t, "health-mem")
+	ctx := context.Background()
+
+	conn, err := dialMemHolon(ctx, "health-mem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check before Close: %v", err)
+	}
+	first := composer.server
+
+	composer.Close()
+	composer.Close()
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{}); err == nil {
+		t.Fatal("a connection to the closed server should fail")
+	}
+
+	if err := memHealthCheck(ctx, "health-mem"); err != nil {
+		t.Fatalf("Check after Close: %v", err)
+	}
+	if composer.server == nil || composer.server == first {
+		t.Fatal("a dial after Close should start a fresh server")
+	}
+}
+
+func TestRunClosesMemComposersWhenTheCommandEnds(t *testing.T) {
+	composer := registerHealthComposer(t, "health-mem")
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"mem://health-mem", "Check"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("mem:// Check returned %d, want 0", code)
+		}
+	})
+	if !strings.Contains(stdout, "SERVING") {
+		t.Fatalf("stdout = %q", stdout)
+	}
+	composer.mu.Lock()
+	defer composer.mu.Unlock()
+	if composer.server != nil {
+		t.Fatal("the composer's server should be stopped once the command returns")
+	}
+}
+
+func TestMemComposerSurvivesConcurrentDialsAndCloses(t *testing.T) {
+	composer := registerHealthComposer(t, "health-mem")
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%4 == 0 {
+				composer.Close()
+				return
+			}
+			// A call may race a Close; it must fail cleanly, not panic.
+			_ = memHealthCheck(ctx, "health-mem")
+		}(i)
+	}
+	wg.Wait()
+
+	if err := memHealthCheck(ctx, "health-mem"); err != nil {
+		t.Fatalf("Check after concurrent use: %v", err)
+	}
+}
+
+func TestMemComposerFirstConcurrentDialsSucceed(t *testing.T) {
+	registerHealthComposer(t, "health-mem")
+	ctx := context.Background()
+
+	// Every goroutine may be the one that starts the server; none may see
+	// it before it accepts.
+	const dials = 64
+	errs := make(chan error, dials)
+	var wg sync.WaitGroup
+	for i := 0; i < dials; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- memHealthCheck(ctx, "health-mem")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent first dial: %v", err)
+		}
+	}
+}
+
+func TestMemURIInvokesAnyRegisteredMethod(t *testing.T) {
+	registerHealthComposer(t, "health-mem")
+
+	// grpc.health.v1 is not compiled into op: the call is resolved by
+	// reflection over the mem connection.
+	for _, method := range []string{"Check", "grpc.health.v1.Health/Check"} {
+		stdout := captureStdout(t, func() {
+			if code := Run([]string{"mem://health-mem", method, `{"service":""}`}, "test"); code != 0 {
+				t.Fatalf("op mem://health-mem %s returned %d", method, code)
+			}
+		})
+		if !strings.Contains(stdout, "SERVING") {
+			t.Fatalf("op mem://health-mem %s stdout = %q", method, stdout)
+		}
+	}
+
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"mem://health-mem", "Chek"}, "test"); code != 1 {
+			t.Fatalf("an unknown method returned %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "grpc.health.v1.Health/Check") {
+		t.Fatalf("stderr = %q, want the available methods", stderr)
+	}
+
+	stderr = captureStderr(t, func() {
+		if code := Run([]string{"mem://no-such-holon", "Check"}, "test"); code != 1 {
+			t.Fatalf("an unregistered holon returned %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "mem composition not available") {
+		t.Fatalf("stderr = %q", stderr)
+	}
+}
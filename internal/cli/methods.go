@@ -0,0 +1,491 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	openv "github.com/organic-programming/grace-op/internal/env"
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const methodsUsage = "usage: op methods [<holon>|grpc://host:port|grpc+unix://path] [--timeout <duration>] [--refresh] [--summary]"
+
+// holonMethods is one holon's entry in `op methods`.
+type holonMethods struct {
+	Transport string   `json:"transport,omitempty"`
+	Methods   []string `json:"methods"`
+	// Details describes each method's types and streaming flags, in the
+	// order of Methods. Listings cached before they were recorded lack it.
+	Details []grpcclient.MethodInfo `json:"details,omitempty"`
+	Summary []serviceSummary        `json:"summary,omitempty"`
+	Error   string                  `json:"error,omitempty"`
+	Cached  bool                    `json:"cached,omitempty"`
+
+	// kinds maps each "service/Method" to its methodKind.
+	kinds map[string]string
+}
+
+// methodsCacheEntry remembers a stdio listing for a binary until it changes.
+type methodsCacheEntry struct {
+	Binary  string                  `json:"binary"`
+	ModTime time.Time               `json:"mod_time"`
+	Methods []string                `json:"methods"`
+	Kinds   map[string]string       `json:"kinds,omitempty"`
+	Details []grpcclient.MethodInfo `json:"details,omitempty"`
+}
+
+// Method kinds, derived from a method's client and server streaming flags.
+const (
+	methodKindUnary           = "unary"
+	methodKindServerStreaming = "server_streaming"
+	methodKindClientStreaming = "client_streaming"
+	methodKindBidi            = "bidi"
+)
+
+// serviceSummary counts one service's methods by kind for `op methods
+// --summary`.
+type serviceSummary struct {
+	Service         string `json:"service"`
+	Unary           int    `json:"unary"`
+	ServerStreaming int    `json:"server_streaming"`
+	ClientStreaming int    `json:"client_streaming"`
+	Bidi            int    `json:"bidi"`
+}
+
+// serviceMethods is one service of `op methods <holon>`, with its method
+// names unqualified.
+type serviceMethods struct {
+	Name    string   `json:"name"`
+	Methods []string `json:"methods"`
+}
+
+// holonServices is what `op methods <holon>` reports: the holon's methods
+// grouped by service.
+type holonServices struct {
+	Holon     string                  `json:"holon"`
+	Transport string                  `json:"transport,omitempty"`
+	Services  []serviceMethods        `json:"services"`
+	Details   []grpcclient.MethodInfo `json:"details,omitempty"`
+	Summary   []serviceSummary        `json:"summary,omitempty"`
+}
+
+// cmdMethods runs `op methods`: it lists the RPC methods of every
+// discoverable holon by reaching each over its selected transport, producing
+// a capability map of the workspace. Stdio listings are cached per binary
+// and each holon gets at most --timeout. --summary counts each service's
+// unary and streaming methods instead of listing them. Given a grpc:// or
+// grpc+unix:// address, such as an op serve listener, it lists that server's
+// methods through reflection instead; given a holon name, that holon's
+// methods grouped by service.
+func cmdMethods(format Format, args []string) int {
+	summary, args := extractBoolFlag(args, "--summary")
+	target, timeout, refresh, err := parseMethodsArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op methods: %v\n", err)
+		fmt.Fprintln(os.Stderr, methodsUsage)
+		return 1
+	}
+	if target != "" && !isServerAddress(target) {
+		return printHolonServices(format, target, timeout, refresh, summary)
+	}
+
+	var names []string
+	report := make(map[string]holonMethods)
+	if target != "" {
+		names = []string{target}
+		report[target] = collectServerMethods(target, timeout)
+	} else {
+		names, err = matchHolonNames("*")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "op methods: %v\n", err)
+			return 1
+		}
+		cache := loadMethodsCache()
+		for _, name := range names {
+			report[name] = collectHolonMethods(name, timeout, refresh, summary, cache)
+		}
+		saveMethodsCache(cache)
+	}
+	for name, entry := range report {
+		if summary && entry.Error == "" {
+			entry.Summary = summarizeMethods(entry.Methods, entry.kinds)
+			report[name] = entry
+		}
+	}
+
+	if format == FormatJSON {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "op methods: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(out))
+		return 0
+	}
+
+	if len(names) == 0 {
+		fmt.Println("No holons found.")
+		return 0
+	}
+	for _, name := range names {
+		entry := report[name]
+		header := name
+		if entry.Transport != "" {
+			header += " (" + entry.Transport + ")"
+		}
+		fmt.Println(header)
+		if entry.Error != "" {
+			fmt.Printf("  error: %s\n", entry.Error)
+			continue
+		}
+		if summary {
+			printServiceSummaries(entry.Summary)
+			continue
+		}
+		for _, method := range entry.Methods {
+			fmt.Printf("  %s\n", method)
+		}
+	}
+	return 0
+}
+
+// printHolonServices runs `op methods <holon>`: it reaches the holon
+// through the transport chain, as a call would, and prints its methods
+// grouped by service.
+func printHolonServices(format Format, name string, timeout time.Duration, refresh, summary bool) int {
+	cache := loadMethodsCache()
+	entry := collectHolonMethods(name, timeout, refresh, summary, cache)
+	saveMethodsCache(cache)
+	if entry.Error != "" {
+		fmt.Fprintf(os.Stderr, "op methods: %s: %s\n", name, entry.Error)
+		return 1
+	}
+
+	report := holonServices{Holon: name, Transport: entry.Transport, Services: groupMethodsByService(entry.Methods), Details: entry.Details}
+	if summary {
+		report.Summary = summarizeMethods(entry.Methods, entry.kinds)
+	}
+	if format == FormatJSON {
+		printJSON(report)
+		return 0
+	}
+
+	header := name
+	if report.Transport != "" {
+		header += " (" + report.Transport + ")"
+	}
+	fmt.Println(header)
+	if summary {
+		printServiceSummaries(report.Summary)
+		return 0
+	}
+	if len(report.Services) == 0 {
+		fmt.Println("  no services")
+	}
+	for _, service := range report.Services {
+		fmt.Printf("  %s\n", service.Name)
+		for _, method := range service.Methods {
+			fmt.Printf("    %s\n", method)
+		}
+	}
+	return 0
+}
+
+// groupMethodsByService splits sorted "service/Method" names into one entry
+// per service, in order.
+func groupMethodsByService(methods []string) []serviceMethods {
+	services := []serviceMethods{}
+	for _, method := range methods {
+		service, name, _ := strings.Cut(method, "/")
+		if n := len(services); n == 0 || services[n-1].Name != service {
+			services = append(services, serviceMethods{Name: service, Methods: []string{}})
+		}
+		last := &services[len(services)-1]
+		last.Methods = append(last.Methods, name)
+	}
+	return services
+}
+
+func isServerAddress(target string) bool {
+	return strings.HasPrefix(target, "grpc://") || strings.HasPrefix(target, "grpc+unix://")
+}
+
+func collectHolonMethods(name string, timeout time.Duration, refresh, needKinds bool, cache map[string]methodsCacheEntry) holonMethods {
+	decision, err := selectTransportWithReason(name, nil)
+	if err != nil {
+		return holonMethods{Methods: []string{}, Error: "not reachable (build it first)"}
+	}
+	scheme := decision.Scheme
+	if isRunningEndpoint(decision) {
+		address, err := endpointDialAddress(decision.Endpoint)
+		if err != nil {
+			return holonMethods{Transport: scheme, Methods: []string{}, Error: err.Error()}
+		}
+		return collectAddressMethods(address, scheme, timeout)
+	}
+	entry := holonMethods{Transport: scheme}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	switch scheme {
+	case "mem":
+		entry.Details, err = memServiceDetails(name)
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		entry.Methods, entry.kinds = methodNamesAndKinds(entry.Details)
+	default:
+		binary, err := resolveHolon(name)
+		if err != nil {
+			entry.Error = holonNotFoundError(name).Error()
+			break
+		}
+		info, statErr := os.Stat(binary)
+		// Listings cached before kinds were recorded cannot be summarized.
+		if cached, ok := cache[name]; ok && !refresh && statErr == nil &&
+			cached.Binary == binary && cached.ModTime.Equal(info.ModTime()) &&
+			(!needKinds || len(cached.Kinds) == len(cached.Methods)) {
+			entry.Methods, entry.kinds, entry.Details, entry.Cached = cached.Methods, cached.Kinds, cached.Details, true
+			break
+		}
+
+		conn, stop, err := dialStdioHolon(ctx, binary)
+		if err != nil {
+			entry.Error = err.Error()
+			break
+		}
+		defer stop()
+		entry.Details, err = listConnMethods(ctx, conn)
+		if err != nil {
+			entry.Error = err.Error()
+			break
+		}
+		entry.Methods, entry.kinds = methodNamesAndKinds(entry.Details)
+		if statErr == nil {
+			cache[name] = methodsCacheEntry{Binary: binary, ModTime: info.ModTime(), Methods: entry.Methods, Kinds: entry.kinds, Details: entry.Details}
+		}
+	}
+
+	if entry.Methods == nil {
+		entry.Methods = []string{}
+	}
+	return entry
+}
+
+// collectServerMethods lists the methods of the running server at uri, a
+// grpc:// or grpc+unix:// address, through reflection.
+func collectServerMethods(uri string, timeout time.Duration) holonMethods {
+	address, transport := strings.TrimPrefix(uri, "grpc://"), "tcp"
+	if path, ok := strings.CutPrefix(uri, "grpc+unix://"); ok {
+		address, transport = "unix://"+path, "unix"
+	}
+	return collectAddressMethods(address, transport, timeout)
+}
+
+// collectAddressMethods lists, through reflection, the methods of the server
+// grpcclient reaches at address: host:port or unix://path.
+func collectAddressMethods(address, transport string, timeout time.Duration) holonMethods {
+	entry := holonMethods{Transport: transport, Methods: []string{}}
+
+	conn, err := grpc.NewClient(address, grpcclient.DialOptions()...)
+	if err != nil {
+		entry.Error = err.Error()
+		return entry
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	details, err := listConnMethods(ctx, conn)
+	if err != nil {
+		entry.Error = err.Error()
+		return entry
+	}
+	entry.Details = details
+	entry.Methods, entry.kinds = methodNamesAndKinds(details)
+	return entry
+}
+
+// memServiceDetails describes the methods an in-process composer
+// registers, sorted by name. Types come from the compiled-in descriptors;
+// a service without one is listed with its streaming flags only.
+func memServiceDetails(name string) ([]grpcclient.MethodInfo, error) {
+	composer, err := resolveMemComposer(name)
+	if err != nil {
+		return nil, err
+	}
+	s := grpc.NewServer()
+	composer.register(s)
+
+	var details []grpcclient.MethodInfo
+	for service, info := range s.GetServiceInfo() {
+		if desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service)); err == nil {
+			if svc, ok := desc.(protoreflect.ServiceDescriptor); ok {
+				details = append(details, grpcclient.DescribeServiceMethods([]protoreflect.ServiceDescriptor{svc})...)
+				continue
+			}
+		}
+		for _, method := range info.Methods {
+			details = append(details, grpcclient.MethodInfo{
+				Service:         service,
+				Method:          method.Name,
+				ClientStreaming: method.IsClientStream,
+				ServerStreaming: method.IsServerStream,
+			})
+		}
+	}
+	sortMethodDetails(details)
+	return details, nil
+}
+
+// listConnMethods describes the methods conn exposes through reflection,
+// sorted by name.
+func listConnMethods(ctx context.Context, conn *grpc.ClientConn) ([]grpcclient.MethodInfo, error) {
+	services, err := grpcclient.ResolveServices(ctx, conn)
+	if err != nil {
+		return nil, errors.New(rpcErrorMessage(err))
+	}
+	details := grpcclient.DescribeServiceMethods(services)
+	sortMethodDetails(details)
+	return details, nil
+}
+
+func sortMethodDetails(details []grpcclient.MethodInfo) {
+	sort.Slice(details, func(i, j int) bool { return details[i].FullName() < details[j].FullName() })
+}
+
+// methodNamesAndKinds derives the "service/Method" names of details, in
+// order, and each one's kind.
+func methodNamesAndKinds(details []grpcclient.MethodInfo) ([]string, map[string]string) {
+	methods := make([]string, 0, len(details))
+	kinds := make(map[string]string, len(details))
+	for _, method := range details {
+		methods = append(methods, method.FullName())
+		kinds[method.FullName()] = methodKind(method.ClientStreaming, method.ServerStreaming)
+	}
+	return methods, kinds
+}
+
+func methodKind(clientStreaming, serverStreaming bool) string {
+	switch {
+	case clientStreaming && serverStreaming:
+		return methodKindBidi
+	case clientStreaming:
+		return methodKindClientStreaming
+	case serverStreaming:
+		return methodKindServerStreaming
+	default:
+		return methodKindUnary
+	}
+}
+
+// summarizeMethods counts methods by kind per service, in service order.
+// Methods of unknown kind count as unary.
+func summarizeMethods(methods []string, kinds map[string]string) []serviceSummary {
+	summaries := []serviceSummary{}
+	index := make(map[string]int)
+	for _, method := range methods {
+		service, _, _ := strings.Cut(method, "/")
+		i, ok := index[service]
+		if !ok {
+			i = len(summaries)
+			index[service] = i
+			summaries = append(summaries, serviceSummary{Service: service})
+		}
+		switch kinds[method] {
+		case methodKindServerStreaming:
+			summaries[i].ServerStreaming++
+		case methodKindClientStreaming:
+			summaries[i].ClientStreaming++
+		case methodKindBidi:
+			summaries[i].Bidi++
+		default:
+			summaries[i].Unary++
+		}
+	}
+	return summaries
+}
+
+func printServiceSummaries(summaries []serviceSummary) {
+	if len(summaries) == 0 {
+		fmt.Println("  no services")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  SERVICE\tUNARY\tSERVER-STREAMING\tCLIENT-STREAMING\tBIDI")
+	for _, s := range summaries {
+		fmt.Fprintf(w, "  %s\t%d\t%d\t%d\t%d\n", s.Service, s.Unary, s.ServerStreaming, s.ClientStreaming, s.Bidi)
+	}
+	_ = w.Flush()
+}
+
+func parseMethodsArgs(args []string) (string, time.Duration, bool, error) {
+	target := ""
+	timeout := grpcclient.Timeout()
+	refresh := false
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch {
+		case name == "--timeout":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return "", 0, false, fmt.Errorf("--timeout requires a duration")
+				}
+				value = args[i+1]
+				i++
+			}
+			parsed, err := time.ParseDuration(value)
+			if err != nil || parsed <= 0 {
+				return "", 0, false, fmt.Errorf("invalid --timeout %q", value)
+			}
+			timeout = parsed
+		case name == "--refresh":
+			refresh = true
+		case target == "" && !strings.HasPrefix(args[i], "-"):
+			target = args[i]
+		default:
+			return "", 0, false, fmt.Errorf("unknown argument %q", args[i])
+		}
+	}
+	return target, timeout, refresh, nil
+}
+
+func methodsCachePath() string {
+	return filepath.Join(openv.CacheDir(), "methods.json")
+}
+
+func loadMethodsCache() map[string]methodsCacheEntry {
+	cache := make(map[string]methodsCacheEntry)
+	data, err := os.ReadFile(methodsCachePath())
+	if err != nil {
+		return cache
+	}
+	_ = json.Unmarshal(data, &cache)
+	return cache
+}
+
+// saveMethodsCache is best effort: a failed write only costs a relaunch.
+func saveMethodsCache(cache map[string]methodsCacheEntry) {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return
+	}
+	path := methodsCachePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,338 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+)
+
+func TestParseMethodsArgs(t *testing.T) {
+	target, timeout, refresh, err := parseMethodsArgs([]string{"--timeout=2s", "--refresh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target != "" || timeout != 2*time.Second || !refresh {
+		t.Fatalf("target = %q, timeout = %v, refresh = %v", target, timeout, refresh)
+	}
+
+	if _, _, _, err := parseMethodsArgs([]string{"--timeout", "soon"}); err == nil {
+		t.Fatal("expected an invalid --timeout to fail")
+	}
+	if target, _, _, err := parseMethodsArgs([]string{"grpc://localhost:9090"}); err != nil || target != "grpc://localhost:9090" {
+		t.Fatalf("target = %q, err = %v; a server address should be accepted", target, err)
+	}
+	if target, _, _, err := parseMethodsArgs([]string{"alpha"}); err != nil || target != "alpha" {
+		t.Fatalf("target = %q, err = %v; a holon name should be accepted", target, err)
+	}
+	if _, _, _, err := parseMethodsArgs([]string{"grpc://a:1", "grpc://b:2"}); err == nil {
+		t.Fatal("expected a second address to fail")
+	}
+	if _, _, _, err := parseMethodsArgs([]string{"alpha", "beta"}); err == nil {
+		t.Fatal("expected a second holon to fail")
+	}
+}
+
+func TestMethodsEmptyWorkspace(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	t.Setenv("OPPATH", filepath.Join(root, ".op-home"))
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"methods"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("code = %d, want 0", code)
+		}
+	})
+	if strings.TrimSpace(stdout) != "No holons found." {
+		t.Fatalf("stdout = %q", stdout)
+	}
+}
+
+func TestMethodsUsesCacheForUnchangedBinary(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	t.Setenv("OPPATH", filepath.Join(root, ".op-home"))
+	t.Setenv("OPBIN", filepath.Join(root, "opbin"))
+
+	seedTransportHolon(t, root, transportHolonSeed{
+		dirName:    "beta",
+		binaryName: "beta",
+		givenName:  "beta",
+		familyName: "Holon",
+		aliases:    []string{"beta"},
+		lang:       "go",
+	})
+
+	binary, err := resolveHolon("beta-holon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(binary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saveMethodsCache(map[string]methodsCacheEntry{
+		"beta-holon": {Binary: binary, ModTime: info.ModTime(), Methods: []string{"beta.v1.Beta/Ping"}},
+	})
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"--format", "json", "methods"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("code = %d, want 0", code)
+		}
+	})
+
+	var report map[string]holonMethods
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("unmarshal %q: %v", stdout, err)
+	}
+	entry, ok := report["beta-holon"]
+	if !ok {
+		t.Fatalf("report = %+v, want a beta-holon entry", report)
+	}
+	if entry.Transport != "stdio" || !entry.Cached {
+		t.Fatalf("entry = %+v, want a cached stdio entry", entry)
+	}
+	if len(entry.Methods) != 1 || entry.Methods[0] != "beta.v1.Beta/Ping" {
+		t.Fatalf("methods = %v", entry.Methods)
+	}
+}
+
+func TestCollectHolonMethodsListsMemComposerService(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedTransportHolon(t, root, transportHolonSeed{
+		dirName:    "beta",
+		binaryName: "beta",
+		givenName:  "beta",
+		familyName: "Holon",
+		aliases:    []string{"beta"},
+		lang:       "go",
+	})
+	memComposeRegistry["beta"] = sophiaMemComposer
+	t.Cleanup(func() { delete(memComposeRegistry, "beta") })
+
+	entry := collectHolonMethods("beta", time.Second, false, true, nil)
+	if entry.Transport != "mem" || entry.Error != "" {
+		t.Fatalf("entry = %+v, want methods listed over mem", entry)
+	}
+	methods, kinds := entry.Methods, entry.kinds
+	found := false
+	for _, method := range methods {
+		if method == "op.v1.OPService/ListIdentities" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("methods = %v, want op.v1.OPService/ListIdentities", methods)
+	}
+	if kind := kinds["op.v1.OPService/ListIdentities"]; kind != methodKindUnary {
+		t.Fatalf("ListIdentities kind = %q, want %q", kind, methodKindUnary)
+	}
+}
+
+func TestMethodKindFromStreamingFlags(t *testing.T) {
+	cases := []struct {
+		client, server bool
+		want           string
+	}{
+		{false, false, methodKindUnary},
+		{false, true, methodKindServerStreaming},
+		{true, false, methodKindClientStreaming},
+		{true, true, methodKindBidi},
+	}
+	for _, tc := range cases {
+		if got := methodKind(tc.client, tc.server); got != tc.want {
+			t.Errorf("methodKind(%v, %v) = %q, want %q", tc.client, tc.server, got, tc.want)
+		}
+	}
+}
+
+func TestSummarizeMethodsCountsKindsPerService(t *testing.T) {
+	methods := []string{"a.A/Get", "a.A/Tail", "a.A/Upload", "b.B/Chat", "b.B/Ping"}
+	kinds := map[string]string{
+		"a.A/Get":    methodKindUnary,
+		"a.A/Tail":   methodKindServerStreaming,
+		"a.A/Upload": methodKindClientStreaming,
+		"b.B/Chat":   methodKindBidi,
+		"b.B/Ping":   methodKindUnary,
+	}
+
+	got := summarizeMethods(methods, kinds)
+	want := []serviceSummary{
+		{Service: "a.A", Unary: 1, ServerStreaming: 1, ClientStreaming: 1},
+		{Service: "b.B", Unary: 1, Bidi: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("summary = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("summary[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMethodsSummaryRefreshesCacheWithoutKinds(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	t.Setenv("OPPATH", filepath.Join(root, ".op-home"))
+	t.Setenv("OPBIN", filepath.Join(root, "opbin"))
+
+	seedTransportHolon(t, root, transportHolonSeed{
+		dirName:    "beta",
+		binaryName: "beta",
+		givenName:  "beta",
+		familyName: "Holon",
+		aliases:    []string{"beta"},
+		lang:       "go",
+	})
+
+	binary, err := resolveHolon("beta-holon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(binary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saveMethodsCache(map[string]methodsCacheEntry{
+		"beta-holon": {Binary: binary, ModTime: info.ModTime(), Methods: []string{"beta.v1.Beta/Ping"}},
+	})
+
+	stdout := captureStdout(t, func() {
+		Run([]string{"--format", "json", "methods", "--summary", "--timeout", "2s"}, "0.1.0-test")
+	})
+
+	var report map[string]holonMethods
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("unmarshal %q: %v", stdout, err)
+	}
+	// The seeded binary does not serve, so a relaunch fails rather than
+	// reusing the kind-less cached listing.
+	if entry := report["beta-holon"]; entry.Cached || entry.Error == "" {
+		t.Fatalf("entry = %+v, want an uncached attempt", entry)
+	}
+}
+
+func TestMethodsListsOneServer(t *testing.T) {
+	address := startOPServer(t)
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"--format", "json", "methods", "grpc://" + address, "--summary"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("code = %d, want 0", code)
+		}
+	})
+	var report map[string]holonMethods
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("stdout = %q: %v", stdout, err)
+	}
+	entry, ok := report["grpc://"+address]
+	if !ok || entry.Error != "" || entry.Transport != "tcp" {
+		t.Fatalf("report = %+v", report)
+	}
+	for _, want := range []string{"op.v1.OPService/CreateIdentity", "op.v1.OPService/InvokeStream"} {
+		if !slices.Contains(entry.Methods, want) {
+			t.Fatalf("methods = %v, missing %s", entry.Methods, want)
+		}
+	}
+	for _, summary := range entry.Summary {
+		if summary.Service == "op.v1.OPService" && summary.ServerStreaming != 1 {
+			t.Fatalf("summary = %+v, want InvokeStream counted as server-streaming", summary)
+		}
+	}
+}
+
+func TestMethodsJSONDescribesEachMethod(t *testing.T) {
+	address := startOPServer(t)
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"--format", "json", "methods", "grpc://" + address}, "0.1.0-test"); code != 0 {
+			t.Fatalf("code = %d, want 0", code)
+		}
+	})
+	var report map[string]holonMethods
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("stdout = %q: %v", stdout, err)
+	}
+	entry := report["grpc://"+address]
+	if len(entry.Details) != len(entry.Methods) {
+		t.Fatalf("details = %+v, want one per method of %v", entry.Details, entry.Methods)
+	}
+	want := grpcclient.MethodInfo{
+		Service: "op.v1.OPService", Method: "InvokeStream",
+		Input: "op.v1.InvokeRequest", Output: "op.v1.InvokeChunk",
+		ServerStreaming: true,
+	}
+	if !slices.Contains(entry.Details, want) {
+		t.Fatalf("details = %+v, missing %+v", entry.Details, want)
+	}
+}
+
+func TestMemServiceDetailsUseCompiledDescriptors(t *testing.T) {
+	memComposeRegistry["sophia-who"] = sophiaMemComposer
+	t.Cleanup(func() { delete(memComposeRegistry, "sophia-who") })
+
+	details, err := memServiceDetails("sophia-who")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := grpcclient.MethodInfo{
+		Service: "op.v1.OPService", Method: "ListIdentities",
+		Input: "op.v1.ListIdentitiesRequest", Output: "op.v1.ListIdentitiesResponse",
+	}
+	if !slices.Contains(details, want) {
+		t.Fatalf("details = %+v, missing %+v", details, want)
+	}
+}
+
+func TestMethodsGroupsOneHolonByService(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	t.Setenv("OPPATH", filepath.Join(root, ".op-home"))
+	// A registered running instance is reached over tcp, like a call.
+	recordEndpoint("beta", "tcp://"+startOPServer(t), os.Getpid())
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"--format", "json", "methods", "beta"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("code = %d, want 0", code)
+		}
+	})
+	var report holonServices
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("stdout = %q: %v", stdout, err)
+	}
+	if report.Holon != "beta" || report.Transport != "tcp" || len(report.Services) != 1 {
+		t.Fatalf("report = %+v, want OPService over tcp", report)
+	}
+	service := report.Services[0]
+	if service.Name != "op.v1.OPService" || !slices.Contains(service.Methods, "ListIdentities") {
+		t.Fatalf("service = %+v, want unqualified OPService methods", service)
+	}
+
+	stdout = captureStdout(t, func() { Run([]string{"methods", "beta"}, "0.1.0-test") })
+	if !strings.Contains(stdout, "beta (tcp)\n  op.v1.OPService\n    CancelInvoke\n") {
+		t.Fatalf("stdout = %q, want methods indented under their service", stdout)
+	}
+
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"methods", "missing"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("code = %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "op methods: missing: not reachable") {
+		t.Fatalf("stderr = %q", stderr)
+	}
+}
+
+func TestGroupMethodsByService(t *testing.T) {
+	got := groupMethodsByService([]string{"a.A/One", "a.A/Two", "b.B/Three"})
+	if len(got) != 2 || got[0].Name != "a.A" || !slices.Equal(got[0].Methods, []string{"One", "Two"}) ||
+		got[1].Name != "b.B" || !slices.Equal(got[1].Methods, []string{"Three"}) {
+		t.Fatalf("groups = %+v", got)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"strings"
-	"text/tabwriter"
 
 	opmod "github.com/organic-programming/grace-op/internal/mod"
 	"github.com/organic-programming/grace-op/internal/suggest"
@@ -255,16 +254,19 @@ func cmdModList(format Format, globalQuiet bool, args []string) int {
 	}
 
 	fmt.Printf("%s\n", result.HolonPath)
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "MODULE\tVERSION\tCACHE")
+	t := newTable(
+		tableColumn{Header: "MODULE", Truncate: true},
+		tableColumn{Header: "VERSION"},
+		tableColumn{Header: "CACHE", Drop: 1, Truncate: true},
+	)
 	for _, dep := range result.Dependencies {
 		cache := dep.CachePath
 		if _, err := os.Stat(cache); err != nil {
 			cache = "-"
 		}
-		fmt.Fprintf(w, "%s\t%s\t%s\n", dep.Path, dep.Version, cache)
+		t.add(dep.Path, dep.Version, cache)
 	}
-	_ = w.Flush()
+	t.render(os.Stdout, tableWidth(format), "")
 	return 0
 }
 
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// exitUsage is the exit code for invocations op refuses before doing any
+// work, such as asking for a format that cannot represent the output.
+const exitUsage = 2
+
+// outputShape is what a command writes to stdout. Each format declares the
+// shapes it can represent, so a mismatch fails up front instead of printing
+// something other than what was asked for.
+type outputShape int
+
+const (
+	// shapeAny is output that ignores --format, such as help or version.
+	shapeAny outputShape = iota
+	// shapeMessage is a single protobuf response.
+	shapeMessage
+	// shapeMessages is several protobuf responses, as from a fan-out.
+	shapeMessages
+	// shapeReport is one of op's own reports, which has no protobuf message.
+	shapeReport
+)
+
+func (s outputShape) String() string {
+	switch s {
+	case shapeMessage:
+		return "a single response"
+	case shapeMessages:
+		return "several responses"
+	case shapeReport:
+		return "op's own reports, which are not protobuf messages"
+	default:
+		return "any output"
+	}
+}
+
+// formatShapes lists the shapes each format can represent. Formats missing
+// here represent every shape.
+var formatShapes = map[Format][]outputShape{
+	FormatJSONStream:        {shapeMessage, shapeMessages},
+	FormatProtobin:          {shapeMessage},
+	FormatProtobinDelimited: {shapeMessage, shapeMessages},
+}
+
+// supports reports whether f can represent output of shape.
+func (f Format) supports(shape outputShape) bool {
+	shapes, restricted := formatShapes[f]
+	return shape == shapeAny || !restricted || slices.Contains(shapes, shape)
+}
+
+// checkOutputShape returns a descriptive error when format cannot represent
+// shape, naming the formats that can.
+func checkOutputShape(format Format, shape outputShape) error {
+	if format.supports(shape) {
+		return nil
+	}
+	var alternatives []string
+	for _, candidate := range []Format{FormatText, FormatWide, FormatJSON, FormatJSONStream, FormatProtobin, FormatProtobinDelimited} {
+		if candidate.supports(shape) {
+			alternatives = append(alternatives, string(candidate))
+		}
+	}
+	return fmt.Errorf("--format %s cannot represent %s; use %s", format, shape, strings.Join(alternatives, ", "))
+}
+
+// commandOutputShape returns the shape of what `op <cmd> <args>` prints.
+func commandOutputShape(cmd string, args []string) outputShape {
+	switch cmd {
+	case "list", "show", "delete", "new", "poll":
+		return shapeMessage
+	case "check", "build", "test", "clean", "install", "uninstall", "mod", "run",
+		"discover", "inspect", "reflect", "channelz", "compare", "example", "schema",
+		"methods", "tools", "env", "daemon", "stop", "ps", "which", "cancel", "plan", "apply", "pin", "releases":
+		return shapeReport
+	case "init", "mcp", "serve", "version", "completion", "__complete", "help", "--help", "-h":
+		return shapeAny
+	}
+	if cmd == "grpc" || strings.HasPrefix(cmd, "grpc://") || strings.HasPrefix(cmd, "grpcs://") || strings.HasPrefix(cmd, "grpc+") {
+		// Without a method, op lists the methods the server offers.
+		if len(args) == 0 || (cmd == "grpc" && !hasEndpointMethod(args)) {
+			return shapeReport
+		}
+		return shapeMessage
+	}
+	switch {
+	case slices.Contains(args, "--dry-run"):
+		return shapeReport
+	case isHolonPattern(cmd), slices.ContainsFunc(args, isCallFlag):
+		return shapeMessages
+	default:
+		return shapeMessage
+	}
+}
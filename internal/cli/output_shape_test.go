@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatsDeclareSupportedShapes(t *testing.T) {
+	tests := []struct {
+		format Format
+		shape  outputShape
+		want   bool
+	}{
+		{FormatText, shapeReport, true},
+		{FormatJSON, shapeMessages, true},
+		{FormatWide, shapeMessage, true},
+		{FormatProtobin, shapeMessage, true},
+		{FormatProtobin, shapeMessages, false},
+		{FormatProtobin, shapeReport, false},
+		{FormatProtobinDelimited, shapeMessages, true},
+		{FormatProtobinDelimited, shapeReport, false},
+		{FormatProtobin, shapeAny, true},
+		{FormatJSONStream, shapeMessages, true},
+		{FormatJSONStream, shapeReport, false},
+	}
+	for _, tc := range tests {
+		if got := tc.format.supports(tc.shape); got != tc.want {
+			t.Errorf("%s supports %s = %v, want %v", tc.format, tc.shape, got, tc.want)
+		}
+	}
+
+	err := checkOutputShape(FormatProtobin, shapeMessages)
+	if err == nil || !strings.Contains(err.Error(), "several responses; use text, wide, json, json-stream, protobin-delimited") {
+		t.Fatalf("error = %v, want the formats that can represent several responses", err)
+	}
+}
+
+func TestRunRejectsFormatThatCannotRepresentOutput(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--format", "protobin", "releases"}, "--format protobin cannot represent op's own reports"},
+		{[]string{"--format", "protobin-delimited", "discover"}, "--format protobin-delimited cannot represent op's own reports"},
+		{[]string{"--format", "protobin", "--all", "ListIdentities"}, "--format protobin cannot represent several responses"},
+		{[]string{"--format", "protobin", "grpc://127.0.0.1:1"}, "cannot represent op's own reports"},
+		{[]string{"--format", "json-stream", "discover"}, "--format json-stream cannot represent op's own reports"},
+	}
+	for _, tc := range tests {
+		var stdout string
+		stderr := captureStderr(t, func() {
+			stdout = captureStdout(t, func() {
+				if code := Run(tc.args, "0.1.0-test"); code != exitUsage {
+					t.Fatalf("%v returned %d, want %d", tc.args, code, exitUsage)
+				}
+			})
+		})
+		if !strings.Contains(stderr, tc.want) {
+			t.Fatalf("%v stderr = %q, want %q", tc.args, stderr, tc.want)
+		}
+		if stdout != "" {
+			t.Fatalf("%v wrote %q to stdout, want nothing", tc.args, stdout)
+		}
+	}
+
+	_ = captureStdout(t, func() {
+		if code := Run([]string{"--format", "protobin", "version"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("version with --format protobin returned %d, want 0", code)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// outputTemplate, when set, replaces the built-in rendering of RPC
+// responses. It is set for the duration of one command by --template.
+var outputTemplate *template.Template
+
+// outputTemplateFuncs is the function set available to --template, on top
+// of the text/template builtins such as len and index.
+var outputTemplateFuncs = template.FuncMap{
+	// json renders a value as compact JSON: {{json .identity}}.
+	"json": func(v any) (string, error) {
+		out, err := json.Marshal(v)
+		return string(out), err
+	},
+	// join renders a list with sep between items: {{.names | join ", "}}.
+	"join": func(sep string, items []any) string {
+		parts := make([]string, 0, len(items))
+		for _, item := range items {
+			parts = append(parts, templateText(item))
+		}
+		return strings.Join(parts, sep)
+	},
+	// default substitutes fallback for a missing or empty value:
+	// {{.status | default "unknown"}}.
+	"default": func(fallback any, value any) any {
+		if isEmptyTemplateValue(value) {
+			return fallback
+		}
+		return value
+	},
+}
+
+// extractOutputTemplateFlag removes --template from args and, when present,
+// parses it and installs it as the output template. release uninstalls it.
+func extractOutputTemplateFlag(format Format, args []string) ([]string, func(), error) {
+	text := ""
+	found := false
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--template":
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("--template requires a value")
+			}
+			text, found = args[i+1], true
+			i++
+		case strings.HasPrefix(args[i], "--template="):
+			text, found = strings.TrimPrefix(args[i], "--template="), true
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+	if !found {
+		return remaining, func() {}, nil
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, nil, fmt.Errorf("--template requires a value")
+	}
+	if isProtobin(format) {
+		return nil, nil, fmt.Errorf("--template cannot be combined with --format %s", format)
+	}
+
+	tmpl, err := template.New("template").Funcs(outputTemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse --template: %w", err)
+	}
+	outputTemplate = tmpl
+	return remaining, func() { outputTemplate = nil }, nil
+}
+
+// renderOutputTemplate executes tmpl with the decoded JSON response as its
+// data. Execution errors name the failing action, e.g.
+// `executing "template" at <len .entries>: ...`.
+func renderOutputTemplate(tmpl *template.Template, payload []byte) (string, error) {
+	data, err := decodeJSONValue(payload)
+	if err != nil {
+		return "", fmt.Errorf("--template: response is not JSON: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render --template: %w", err)
+	}
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
+
+// templateText renders a scalar without JSON quoting and anything else as
+// compact JSON.
+func templateText(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number, bool:
+		return fmt.Sprint(v)
+	default:
+		out, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(out)
+	}
+}
+
+func isEmptyTemplateValue(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestRenderOutputTemplateHelpers(t *testing.T) {
+	tmpl := template.Must(template.New("template").Funcs(outputTemplateFuncs).Parse(
+		`{{len .entries}} holons: {{.names | join ", "}}; status={{.status | default "unknown"}}; first={{json (index .entries 0)}}`,
+	))
+	payload := []byte(`{"entries":[{"id":1},{"id":2}],"names":["a","b"]}`)
+
+	got, err := renderOutputTemplate(tmpl, payload)
+	if err != nil {
+		t.Fatalf("renderOutputTemplate returned error: %v", err)
+	}
+	if want := `2 holons: a, b; status=unknown; first={"id":1}`; got != want {
+		t.Fatalf("rendered = %q, want %q", got, want)
+	}
+}
+
+func TestRenderOutputTemplateReportsFailingAction(t *testing.T) {
+	tmpl := template.Must(template.New("template").Funcs(outputTemplateFuncs).Parse(`{{index .entries 5}}`))
+
+	_, err := renderOutputTemplate(tmpl, []byte(`{"entries":[]}`))
+	if err == nil {
+		t.Fatal("expected an error for an out-of-range index")
+	}
+	if !strings.Contains(err.Error(), "<index .entries 5>") {
+		t.Fatalf("error should name the failing action: %v", err)
+	}
+}
+
+func TestExtractOutputTemplateFlagRejectsBadTemplates(t *testing.T) {
+	if _, _, err := extractOutputTemplateFlag(FormatText, []string{"ListIdentities", "--template", "{{.entries"}); err == nil || !strings.Contains(err.Error(), "parse --template") {
+		t.Fatalf("parse error = %v", err)
+	}
+	if _, _, err := extractOutputTemplateFlag(FormatProtobin, []string{"--template", "{{.}}"}); err == nil {
+		t.Fatal("--template with --format protobin should fail")
+	}
+}
+
+func TestGRPCTemplateRendersResponse(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "sophia-a", givenName: "Sophia", familyName: "A", lang: "go"})
+	address := startOPServer(t)
+
+	stdout := captureStdout(t, func() {
+		code := Run([]string{"--format", "json", "grpc://" + address, "ListIdentities", "--template", `{{len .entries}} holon(s), first {{(index .entries 0).identity.givenName}}`}, "0.1.0-test")
+		if code != 0 {
+			t.Fatalf("code = %d, want 0", code)
+		}
+	})
+	if got := strings.TrimSpace(stdout); got != "1 holon(s), first Sophia" {
+		t.Fatalf("stdout = %q", got)
+	}
+	if outputTemplate != nil {
+		t.Fatal("--template should be released when the command returns")
+	}
+}
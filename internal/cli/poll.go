@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+)
+
+const pollUsage = "usage: op poll <grpc://host:port|holon> <method> [json] --until <condition> [--interval <duration>] [--max-attempts <n>]"
+
+const (
+	defaultPollInterval    = 2 * time.Second
+	defaultPollMaxAttempts = 30
+)
+
+type pollOptions struct {
+	Until       responseCondition
+	Interval    time.Duration
+	MaxAttempts int
+}
+
+// cmdPoll runs `op poll`: it calls a unary method until --until holds for
+// the response or --max-attempts is exhausted, then prints the last
+// response. Exhaustion and call failures exit non-zero.
+func cmdPoll(format Format, args []string) int {
+	opts, positional, err := parsePollArgs(args)
+	if err == nil && (len(positional) < 2 || len(positional) > 3) {
+		err = fmt.Errorf("requires a target, a method and an optional JSON request")
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op poll: %v\n", err)
+		fmt.Fprintln(os.Stderr, pollUsage)
+		return 1
+	}
+
+	target, method := positional[0], positional[1]
+	inputJSON := "{}"
+	if len(positional) == 3 {
+		inputJSON = positional[2]
+	}
+	// Without a description of the response, a field it lacks cannot be
+	// told from a typo, so it never holds and is reported at the end.
+	if described, err := describeTargetMethod(target, method); err == nil {
+		if err := opts.Until.checkFields(described.OutputFields); err != nil {
+			fmt.Fprintf(os.Stderr, "op poll: %v\n", err)
+			return 1
+		}
+	}
+	call, closeCaller, err := pollCaller(target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op poll: %v\n", err)
+		return 1
+	}
+	defer closeCaller()
+
+	var last *grpcclient.CallResult
+	var missing *jsonFieldMissingError
+	for attempt := 1; attempt <= opts.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(opts.Interval)
+		}
+		last, err = call(method, inputJSON)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "op poll: attempt %d: %s\n", attempt, rpcErrorMessage(err))
+			return 1
+		}
+		ok, err := opts.Until.holds([]byte(last.Output))
+		switch {
+		case errors.As(err, &missing):
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "op poll: %v\n", err)
+			return 1
+		case ok:
+			return printRPCResult(format, method, last)
+		default:
+			missing = nil
+		}
+	}
+
+	printRPCResult(format, method, last)
+	if missing != nil {
+		fmt.Fprintf(os.Stderr, "op poll: %q did not hold after %d attempts: %v\n", opts.Until.expr, opts.MaxAttempts, missing)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "op poll: %q did not hold after %d attempts\n", opts.Until.expr, opts.MaxAttempts)
+	return 1
+}
+
+// pollCaller returns how to call target: directly for a gRPC address,
+// through the transport chain for a holon name. A gRPC address is called
+// through one grpcclient.Client, so only the first attempt reflects;
+// closeCaller releases it.
+func pollCaller(target string) (call func(method, inputJSON string) (*grpcclient.CallResult, error), closeCaller func(), err error) {
+	if !strings.Contains(target, "://") {
+		return func(method, inputJSON string) (*grpcclient.CallResult, error) {
+			return callHolon(context.Background(), target, method, inputJSON)
+		}, func() {}, nil
+	}
+	address, err := parseRPCAddress(target)
+	if err != nil {
+		return nil, nil, err
+	}
+	client := grpcclient.NewClient(address)
+	return func(method, inputJSON string) (*grpcclient.CallResult, error) {
+		return client.Call(context.Background(), method, inputJSON)
+	}, func() { _ = client.Close() }, nil
+}
+
+func parsePollArgs(args []string) (pollOptions, []string, error) {
+	opts := pollOptions{Interval: defaultPollInterval, MaxAttempts: defaultPollMaxAttempts}
+	until := ""
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--until", "--interval", "--max-attempts":
+		default:
+			positional = append(positional, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return opts, nil, fmt.Errorf("%s requires a value", name)
+			}
+			value = args[i+1]
+			i++
+		}
+
+		switch name {
+		case "--until":
+			until = value
+		case "--interval":
+			interval, err := time.ParseDuration(value)
+			if err != nil || interval < 0 {
+				return opts, nil, fmt.Errorf("invalid --interval %q", value)
+			}
+			opts.Interval = interval
+		case "--max-attempts":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return opts, nil, fmt.Errorf("invalid --max-attempts %q (want a positive integer)", value)
+			}
+			opts.MaxAttempts = n
+		}
+	}
+
+	if until == "" {
+		return opts, nil, fmt.Errorf("--until is required")
+	}
+	cond, err := parseResponseCondition(until)
+	if err != nil {
+		return opts, nil, err
+	}
+	opts.Until = cond
+	return opts, positional, nil
+}
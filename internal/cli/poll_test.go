@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
+	"github.com/organic-programming/grace-op/internal/server"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// startOPServer serves OP's service, with reflection, on a loopback TCP
+// port and returns its address. opts go to grpc.NewServer, e.g. an
+// interceptor that records what clients send.
+func startOPServer(t *testing.T, opts ...grpc.ServerOption) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serveOP(t, lis, opts...)
+	return lis.Addr().String()
+}
+
+// serveOP serves OP's service, with reflection, on lis until the test ends.
+func serveOP(t *testing.T, lis net.Listener, opts ...grpc.ServerOption) {
+	t.Helper()
+
+	s := grpc.NewServer(opts...)
+	opv1.RegisterOPServiceServer(s, &server.Server{})
+	reflection.Register(s)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(func() {
+		s.Stop()
+		_ = lis.Close()
+	})
+}
+
+func TestPollStopsWhenConditionHolds(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "sophia-a", givenName: "Sophia", familyName: "A", lang: "go"})
+	address := startOPServer(t)
+
+	stdout := captureStdout(t, func() {
+		code := Run([]string{"--format", "json", "poll", "grpc://" + address, "ListIdentities", "--until", `.entries[0].identity.givenName == "Sophia"`, "--interval", "0s"}, "0.1.0-test")
+		if code != 0 {
+			t.Fatalf("code = %d, want 0", code)
+		}
+	})
+	if !strings.Contains(stdout, "transport-test-sophia-a") {
+		t.Fatalf("stdout = %q, want the final response", stdout)
+	}
+}
+
+func TestPollExitsNonZeroWhenAttemptsRunOut(t *testing.T) {
+	chdirForTest(t, t.TempDir())
+	address := startOPServer(t)
+
+	var code int
+	stderr := captureStderr(t, func() {
+		_ = captureStdout(t, func() {
+			code = Run([]string{"poll", "grpc://" + address, "ListIdentities", "--until=.entries", "--interval=0s", "--max-attempts=2"}, "0.1.0-test")
+		})
+	})
+	if code != 1 {
+		t.Fatalf("code = %d, want 1", code)
+	}
+	if !strings.Contains(stderr, `".entries" did not hold after 2 attempts`) {
+		t.Fatalf("stderr = %q", stderr)
+	}
+}
+
+func TestPollRejectsAnUntilPathTheResponseCannotHave(t *testing.T) {
+	chdirForTest(t, t.TempDir())
+	address := startOPServer(t)
+
+	var code int
+	stderr := captureStderr(t, func() {
+		code = Run([]string{"poll", "grpc://" + address, "ListIdentities", "--until=.entris == 0", "--interval=0s"}, "0.1.0-test")
+	})
+	if code != 1 || !strings.Contains(stderr, `the response has no field "entris"`) {
+		t.Fatalf("code = %d, stderr = %q; want the typo rejected", code, stderr)
+	}
+}
+
+func TestPollRequiresUntil(t *testing.T) {
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"poll", "grpc://localhost:1", "ListIdentities"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("code = %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "--until is required") {
+		t.Fatalf("stderr = %q", stderr)
+	}
+}
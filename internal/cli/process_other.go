@@ -0,0 +1,8 @@
+//go:build !unix
+
+package cli
+
+import "os/exec"
+
+// detachProcess leaves cmd as it is on platforms without Unix sessions.
+func detachProcess(cmd *exec.Cmd) {}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+)
+
+const reflectUsage = "usage: op reflect <grpc://host:port|grpc+unix://path|host:port> [--dump]"
+
+// cmdReflect runs `op reflect <address>`. Without --dump it lists the methods
+// the server exposes; with --dump it prints the raw file descriptors
+// reflection reports per service, without assembling them, so duplicate or
+// aliased filenames are visible as served.
+func cmdReflect(format Format, args []string) int {
+	address, dump, err := parseReflectArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op reflect: %v\n", err)
+		fmt.Fprintln(os.Stderr, reflectUsage)
+		return 1
+	}
+
+	if !dump {
+		methods, err := grpcclient.ListMethods(address)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "op reflect: %s\n", rpcErrorMessage(err))
+			return 1
+		}
+		if format == FormatJSON {
+			out, err := json.MarshalIndent(methods, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "op reflect: %v\n", err)
+				return 1
+			}
+			fmt.Println(string(out))
+			return 0
+		}
+		for _, method := range methods {
+			fmt.Println(method)
+		}
+		return 0
+	}
+
+	result, err := grpcclient.DumpReflection(address)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op reflect: %s\n", rpcErrorMessage(err))
+		return 1
+	}
+
+	if format == FormatJSON {
+		out, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "op reflect: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(out))
+		return 0
+	}
+
+	printReflectionDump(result)
+	return 0
+}
+
+func printReflectionDump(dump *grpcclient.ReflectionDump) {
+	for _, svc := range dump.Services {
+		fmt.Println(svc.Name)
+		if svc.Error != "" {
+			fmt.Printf("  error: %s\n", svc.Error)
+			continue
+		}
+		for _, file := range svc.Files {
+			line := "  " + file.Name
+			if file.Package != "" {
+				line += " (" + file.Package + ")"
+			}
+			if file.Aliased() {
+				line += fmt.Sprintf(" [requested as %s]", file.Requested)
+			}
+			if file.Duplicate {
+				line += " [duplicate]"
+			}
+			fmt.Println(line)
+			for _, dep := range file.Dependencies {
+				fmt.Printf("    -> %s\n", dep)
+			}
+		}
+		for _, dep := range svc.MissingDependencies {
+			fmt.Printf("  missing: %s\n", dep)
+		}
+	}
+}
+
+func parseReflectArgs(args []string) (string, bool, error) {
+	dump := false
+	var positional []string
+	for _, arg := range args {
+		switch {
+		case arg == "--dump":
+			dump = true
+		case strings.HasPrefix(arg, "--"):
+			return "", false, fmt.Errorf("unknown flag %q", arg)
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) != 1 {
+		return "", false, fmt.Errorf("requires exactly one address")
+	}
+
+	address, err := parseRPCAddress(positional[0])
+	if err != nil {
+		return "", false, err
+	}
+	return address, dump, nil
+}
+
+// parseRPCAddress turns grpc://host:port, grpc+unix://path or a bare
+// host:port into a gRPC dial target.
+func parseRPCAddress(target string) (string, error) {
+	switch {
+	case strings.HasPrefix(target, "grpc+unix://"):
+		return "unix://" + strings.TrimPrefix(target, "grpc+unix://"), nil
+	case strings.HasPrefix(target, "grpc://"):
+		return strings.TrimPrefix(target, "grpc://"), nil
+	case strings.Contains(target, "://"):
+		return "", fmt.Errorf("unsupported address %q", target)
+	default:
+		return target, nil
+	}
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+)
+
+// extractRequestIDFlag removes --request-id from args and returns its value.
+func extractRequestIDFlag(args []string) (string, []string, error) {
+	id := ""
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--request-id":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return "", nil, fmt.Errorf("--request-id requires a value")
+			}
+			id = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--request-id="):
+			id = strings.TrimPrefix(args[i], "--request-id=")
+			if strings.TrimSpace(id) == "" {
+				return "", nil, fmt.Errorf("--request-id requires a value")
+			}
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+	return id, remaining, nil
+}
+
+// pinRequestID pins id, or a fresh one when id is empty, as the x-request-id
+// of every call this invocation makes. release restores the ID pinned before.
+func pinRequestID(id string) (string, func()) {
+	if id == "" {
+		id = grpcclient.NewRequestID()
+	}
+	return id, grpcclient.SetRequestID(id)
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestExtractRequestIDFlag(t *testing.T) {
+	id, rest, err := extractRequestIDFlag([]string{"ListIdentities", "--request-id=abc", "{}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "abc" || strings.Join(rest, " ") != "ListIdentities {}" {
+		t.Fatalf("id = %q, rest = %v", id, rest)
+	}
+
+	if _, _, err := extractRequestIDFlag([]string{"ListIdentities", "--request-id"}); err == nil {
+		t.Fatal("expected an error for --request-id without a value")
+	}
+}
+
+func TestGRPCSendsRequestIDHeader(t *testing.T) {
+	chdirForTest(t, t.TempDir())
+
+	var mu sync.Mutex
+	var seen []string
+	address := startOPServer(t, grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		mu.Lock()
+		seen = append(seen, md.Get(grpcclient.RequestIDHeader)...)
+		mu.Unlock()
+		return handler(ctx, req)
+	}))
+
+	stderr := captureStderr(t, func() {
+		_ = captureStdout(t, func() {
+			code := Run([]string{"grpc://" + address, "ListIdentities", "--request-id", "trace-42"}, "0.1.0-test")
+			if code != 0 {
+				t.Fatalf("code = %d, want 0", code)
+			}
+		})
+	})
+	if stderr != "" {
+		t.Fatalf("stderr = %q, want nothing without --verbose", stderr)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != 1 || seen[0] != "trace-42" {
+		t.Fatalf("server saw request IDs %v, want [trace-42]", seen)
+	}
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	inspectpkg "github.com/organic-programming/grace-op/internal/inspect"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// printRequiredFieldsHint follows an InvalidArgument failure with the method's
+// required fields and an example request built from the holon's protos, so a
+// call made with the default `{}` input has somewhere to go. It prints nothing
+// for other errors or when the holon's API cannot be inspected offline.
+func printRequiredFieldsHint(w io.Writer, holon, method string, err error) {
+	if status.Code(err) != codes.InvalidArgument {
+		return
+	}
+	catalog, loadErr := inspectpkg.LoadLocal(holon)
+	if loadErr != nil {
+		return
+	}
+
+	name := canonicalMethodName(method)
+	for _, binding := range catalog.Methods {
+		if binding.Method.Name != name {
+			continue
+		}
+		fields := binding.Method.InputFields
+		if required := inspectpkg.RequiredFields(fields); len(required) > 0 {
+			fmt.Fprintf(w, "Required fields for %s:\n", name)
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			for _, field := range required {
+				fmt.Fprintf(tw, "  %s\t%s\t%s\n", field.Name, displayHintType(field), field.Description)
+			}
+			_ = tw.Flush()
+		}
+		example, marshalErr := json.Marshal(inspectpkg.Skeleton(fields))
+		if marshalErr != nil {
+			return
+		}
+		fmt.Fprintf(w, "Example request:\n  op %s %s '%s'\n", holon, name, example)
+		return
+	}
+}
+
+func displayHintType(field inspectpkg.Field) string {
+	switch field.Label {
+	case inspectpkg.FieldLabelRepeated:
+		return "repeated " + inspectpkg.ShortName(field.Type)
+	case inspectpkg.FieldLabelMap:
+		return field.Type
+	default:
+		return inspectpkg.ShortName(field.Type)
+	}
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRequiredFieldsHintForInvalidArgument(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedInspectableHolon(t, root)
+
+	var buf bytes.Buffer
+	printRequiredFieldsHint(&buf, "rob-go", "Build", status.Error(codes.InvalidArgument, "package is required"))
+
+	out := buf.String()
+	for _, want := range []string{
+		"Required fields for Build:",
+		"package  string  The Go package to build.",
+		`op rob-go Build '{"package":"./cmd/rob"}'`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("hint missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRequiredFieldsHintIgnoresOtherErrors(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedInspectableHolon(t, root)
+
+	var buf bytes.Buffer
+	printRequiredFieldsHint(&buf, "rob-go", "Build", status.Error(codes.Unavailable, "down"))
+	printRequiredFieldsHint(&buf, "rob-go", "Build", errors.New("plain failure"))
+	if buf.Len() != 0 {
+		t.Fatalf("unexpected hint: %q", buf.String())
+	}
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/organic-programming/grace-op/internal/holons"
+)
+
+type runDirKey struct{}
+
+// withRunDir overrides the working directory of holons launched under ctx.
+// An empty dir keeps the default, the holon's own directory.
+func withRunDir(ctx context.Context, dir string) context.Context {
+	if dir == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, runDirKey{}, dir)
+}
+
+// launchDir returns the working directory for launching binary under ctx:
+// the --run-dir override when set, otherwise the holon's directory.
+func launchDir(ctx context.Context, binary string) string {
+	if dir, ok := ctx.Value(runDirKey{}).(string); ok {
+		return dir
+	}
+	return holonRunDir(binary)
+}
+
+// holonRunDir derives a holon's directory from its binary path: the nearest
+// ancestor holding a holon manifest, or the binary's own directory for
+// binaries living outside a holon tree (e.g. installed into OPBIN).
+func holonRunDir(binary string) string {
+	abs, err := filepath.Abs(binary)
+	if err != nil {
+		return ""
+	}
+	binDir := filepath.Dir(abs)
+	for dir := binDir; ; {
+		if info, err := os.Stat(filepath.Join(dir, holons.ManifestFileName)); err == nil && !info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return binDir
+		}
+		dir = parent
+	}
+}
+
+// extractRunDirFlag removes --run-dir from args and returns its value as an
+// absolute path.
+func extractRunDirFlag(args []string) (string, []string, error) {
+	dir := ""
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--run-dir":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return "", nil, fmt.Errorf("--run-dir requires a value")
+			}
+			dir = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--run-dir="):
+			dir = strings.TrimPrefix(args[i], "--run-dir=")
+			if strings.TrimSpace(dir) == "" {
+				return "", nil, fmt.Errorf("--run-dir requires a value")
+			}
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+	if dir == "" {
+		return "", remaining, nil
+	}
+	dir, err := validRunDir(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	return dir, remaining, nil
+}
+
+func validRunDir(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("--run-dir %q: %w", dir, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", fmt.Errorf("--run-dir %q: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("--run-dir %q is not a directory", dir)
+	}
+	return abs, nil
+}
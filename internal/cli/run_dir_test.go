@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writePwdHolon seeds a holon whose binary records its working directory,
+// on stdout and in <dir>/cwd.txt, then exits.
+func writePwdHolon(t *testing.T, dir, name string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script holon binaries are not supported on Windows")
+	}
+
+	writeRunServiceFixture(t, dir, name)
+	binary := filepath.Join(dir, ".op", "build", "bin", name)
+	if err := os.MkdirAll(filepath.Dir(binary), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\npwd\npwd > " + filepath.Join(dir, "cwd.txt") + "\n"
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return binary
+}
+
+func realPath(t *testing.T, path string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolved
+}
+
+func TestRunLaunchesHolonFromItsDirectory(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	dir := filepath.Join(root, "demo")
+	writePwdHolon(t, dir, "demo")
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"--quiet", "run", "demo", "--no-build"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("run returned %d, want 0", code)
+		}
+	})
+	if got, want := realPath(t, strings.TrimSpace(stdout)), realPath(t, dir); got != want {
+		t.Fatalf("child cwd = %q, want holon dir %q", got, want)
+	}
+}
+
+func TestRunDirOverridesHolonDirectory(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	writePwdHolon(t, filepath.Join(root, "demo"), "demo")
+	elsewhere := filepath.Join(root, "elsewhere")
+	if err := os.MkdirAll(elsewhere, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"--quiet", "run", "demo", "--no-build", "--run-dir", "elsewhere"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("run returned %d, want 0", code)
+		}
+	})
+	if got, want := realPath(t, strings.TrimSpace(stdout)), realPath(t, elsewhere); got != want {
+		t.Fatalf("child cwd = %q, want --run-dir %q", got, want)
+	}
+}
+
+func TestStdioLaunchRunsFromHolonDirectory(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	dir := filepath.Join(root, "demo")
+	binary := writePwdHolon(t, dir, "demo")
+
+	// The script exits without serving, so the dial fails; only its working
+	// directory matters here.
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, _, err := dialStdioHolon(ctx, binary); err == nil {
+		t.Fatal("dialStdioHolon succeeded against a non-serving binary")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "cwd.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := realPath(t, strings.TrimSpace(string(data))), realPath(t, dir); got != want {
+		t.Fatalf("child cwd = %q, want holon dir %q", got, want)
+	}
+}
+
+func TestHolonRunDirFallsBackToBinaryDirectory(t *testing.T) {
+	opbin := t.TempDir()
+	binary := filepath.Join(opbin, "demo")
+	if got := holonRunDir(binary); got != opbin {
+		t.Fatalf("holonRunDir(%q) = %q, want %q", binary, got, opbin)
+	}
+}
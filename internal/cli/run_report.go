@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/organic-programming/grace-op/internal/progress"
+)
+
+// runReport is what `op run --format json` prints: one object per
+// invocation, including failures, so automation never parses text.
+type runReport struct {
+	Holon    string `json:"holon"`
+	PID      int    `json:"pid,omitempty"`
+	Listen   string `json:"listen,omitempty"`
+	Status   string `json:"status"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Log      string `json:"log,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+const (
+	runStatusReady  = "ready"
+	runStatusExited = "exited"
+	runStatusFailed = "failed"
+)
+
+// runFailed reports a failed op run: as a runReport in JSON mode, on stderr
+// otherwise.
+func runFailed(format Format, printer *progress.Printer, holonName string, err error) int {
+	printer.Done("run failed", err)
+	if format == FormatJSON {
+		printJSON(runReport{Holon: holonName, Status: runStatusFailed, Error: err.Error()})
+	} else {
+		fmt.Fprintf(os.Stderr, "op run: %v\n", err)
+	}
+	return 1
+}
+
+// runInForeground runs cmd attached to the terminal and reports how it
+// exited. In JSON mode the child's stdout goes to stderr so stdout carries
+// only the report.
+func runInForeground(format Format, printer *progress.Printer, holonName, listen string, cmd *exec.Cmd) int {
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	if format == FormatJSON {
+		cmd.Stdout = os.Stderr
+	}
+	cmd.Stderr = os.Stderr
+
+	forget := func() {}
+	err := runForeground(cmd, func() {
+		forget = recordEndpointWhenReady(holonName, listen, cmd.Process.Pid)
+	})
+	forget()
+	code := 0
+	if err != nil {
+		var ok bool
+		if code, ok = commandExitCode(err); !ok {
+			return runFailed(format, printer, holonName, err)
+		}
+	}
+	if code == 0 {
+		printer.Done(fmt.Sprintf("%s exited in %s", holonName, humanElapsed(printer)), nil)
+	}
+	if format == FormatJSON {
+		report := runReport{Holon: holonName, Listen: listen, Status: runStatusExited, ExitCode: &code}
+		if cmd.Process != nil {
+			report.PID = cmd.Process.Pid
+		}
+		printJSON(report)
+	}
+	return code
+}
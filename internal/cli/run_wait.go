@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/organic-programming/grace-op/internal/progress"
+)
+
+const (
+	defaultRunWaitTimeout = 10 * time.Second
+	runWaitPollInterval   = 100 * time.Millisecond
+	runWaitStderrTail     = 20
+)
+
+// runUntilReady starts cmd in the background, polls until the holon accepts
+// connections on its listen address, and returns leaving it running. On
+// timeout the child is killed, and if it exits first op fails at once with
+// its exit status; either way the tail of its output is reported.
+func runUntilReady(format Format, printer *progress.Printer, holonName string, cmd *exec.Cmd, opts runOptions) int {
+	logFile, err := os.CreateTemp("", "op-run-"+sanitizeLogName(holonName)+"-*.log")
+	if err != nil {
+		return runFailed(format, printer, holonName, err)
+	}
+	defer logFile.Close()
+
+	cmd.Stdin = nil
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err := cmd.Start(); err != nil {
+		return runFailed(format, printer, holonName, err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	printer.Step("waiting for " + holonName + " on " + opts.ListenURI + "...")
+	address, err := waitForListenReady(opts.ListenURI, logFile.Name(), opts.WaitTimeout, exited)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		<-exited
+		code := runFailed(format, printer, holonName, err)
+		if tail := readLogTail(logFile.Name(), runWaitStderrTail); tail != "" {
+			fmt.Fprintf(os.Stderr, "--- %s output (last %d lines) ---\n%s\n", holonName, runWaitStderrTail, tail)
+		}
+		return code
+	}
+
+	if opts.AddrFile != "" {
+		if err := writeAddrFile(opts.AddrFile, address); err != nil {
+			_ = cmd.Process.Kill()
+			<-exited
+			return runFailed(format, printer, holonName, err)
+		}
+	}
+	pid := cmd.Process.Pid
+	recordEndpoint(holonName, address, pid)
+	printer.Done(fmt.Sprintf("%s ready in %s", holonName, humanElapsed(printer)), nil)
+	if format == FormatJSON {
+		printJSON(runReport{Holon: holonName, PID: pid, Listen: address, Status: runStatusReady, Log: logFile.Name()})
+		return 0
+	}
+	fmt.Printf("%s ready at %s (pid %d)\n", holonName, address, pid)
+	fmt.Printf("logs: %s\n", logFile.Name())
+	fmt.Printf("stop with: op stop %s\n", holonName)
+	return 0
+}
+
+// waitForListenReady polls until the listen URI accepts connections and
+// returns the resolved address. When the URI carries port 0, the address the
+// holon announces on its output is used instead. A result on exited, where
+// the holon's Wait reports, ends the wait early; it is put back so the
+// caller can still drain it.
+func waitForListenReady(listenURI, logPath string, timeout time.Duration, exited chan error) (string, error) {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-exited:
+			exited <- err
+			return "", fmt.Errorf("server exited before listening on %s (%s)", listenURI, exitStatus(err))
+		default:
+		}
+		if uri := announcedListenURI(logPath); uri != "" {
+			if dialListenURI(uri) == nil {
+				return uri, nil
+			}
+		} else if !hasEphemeralPort(listenURI) && dialListenURI(listenURI) == nil {
+			return listenURI, nil
+		}
+		time.Sleep(runWaitPollInterval)
+	}
+	return "", fmt.Errorf("server not ready on %s within %s", listenURI, timeout)
+}
+
+// exitStatus describes how a child ended, from its Wait error: "exit code
+// N", or the signal that killed it.
+func exitStatus(err error) string {
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return "exit code 0"
+	case errors.As(err, &exitErr) && exitErr.ExitCode() >= 0:
+		return fmt.Sprintf("exit code %d", exitErr.ExitCode())
+	default:
+		return err.Error()
+	}
+}
+
+func dialListenURI(uri string) error {
+	network, address, err := listenDialTarget(uri)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialTimeout(network, address, runWaitPollInterval)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// listenDialTarget converts a listen URI into the network and address a
+// client dials. A missing host means the loopback interface.
+func listenDialTarget(uri string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(uri, "tcp://"):
+		host, port, err := net.SplitHostPort(strings.TrimPrefix(uri, "tcp://"))
+		if err != nil {
+			return "", "", fmt.Errorf("invalid listen URI %q: %w", uri, err)
+		}
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = "127.0.0.1"
+		}
+		return "tcp", net.JoinHostPort(host, port), nil
+	case strings.HasPrefix(uri, "unix://"):
+		return "unix", strings.TrimPrefix(uri, "unix://"), nil
+	default:
+		return "", "", fmt.Errorf("cannot dial listen URI %q", uri)
+	}
+}
+
+func isNetworkListenURI(uri string) bool {
+	return strings.HasPrefix(uri, "tcp://") || strings.HasPrefix(uri, "unix://")
+}
+
+func hasEphemeralPort(uri string) bool {
+	if !strings.HasPrefix(uri, "tcp://") {
+		return false
+	}
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(uri, "tcp://"))
+	return err == nil && port == "0"
+}
+
+// announcedListenURI returns the first tcp:// or unix:// URI the holon
+// printed on its own line, which is how holons report a resolved address.
+func announcedListenURI(logPath string) string {
+	f, err := os.Open(logPath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if isNetworkListenURI(line) && !hasEphemeralPort(line) {
+			return line
+		}
+	}
+	return ""
+}
+
+func readLogTail(path string, lines int) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	all := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(all) > lines {
+		all = all[len(all)-lines:]
+	}
+	return strings.TrimSpace(strings.Join(all, "\n"))
+}
+
+func sanitizeLogName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, name)
+}
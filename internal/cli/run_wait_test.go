@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/organic-programming/grace-op/internal/progress"
+)
+
+func TestRunWaitFailsFastWhenHolonExits(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the holon")
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	_ = listener.Close()
+
+	cmd := exec.Command("sh", "-c", "echo 'panic: no config' >&2; exit 3")
+	opts := runOptions{ListenURI: fmt.Sprintf("tcp://127.0.0.1:%d", port), Wait: true, WaitTimeout: 10 * time.Second}
+
+	started := time.Now()
+	code := 0
+	stderr := captureStderr(t, func() {
+		code = runUntilReady(FormatText, progress.Silence(), "crasher", cmd, opts)
+	})
+	if code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("run --wait took %s, want it to fail as soon as the holon exits", elapsed)
+	}
+	for _, want := range []string{"exited before listening", "exit code 3", "panic: no config"} {
+		if !strings.Contains(stderr, want) {
+			t.Fatalf("stderr = %q, want it to contain %q", stderr, want)
+		}
+	}
+}
+
+func TestRunWaitKillsHolonThatNeverListens(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the holon")
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	_ = listener.Close()
+
+	cmd := exec.Command("sh", "-c", "i=1; while [ $i -le 30 ]; do echo \"line $i\"; i=$((i+1)); done; exec sleep 30")
+	opts := runOptions{ListenURI: fmt.Sprintf("tcp://127.0.0.1:%d", port), Wait: true, WaitTimeout: 500 * time.Millisecond}
+
+	code := 0
+	stderr := captureStderr(t, func() {
+		code = runUntilReady(FormatText, progress.Silence(), "sleeper", cmd, opts)
+	})
+	if code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+	if !strings.Contains(stderr, "not ready on "+opts.ListenURI) {
+		t.Fatalf("stderr = %q, want a readiness timeout", stderr)
+	}
+	if processAlive(cmd.Process.Pid) {
+		t.Fatalf("holon pid %d still running after the wait timed out", cmd.Process.Pid)
+	}
+	// Only the last runWaitStderrTail lines of output are reported.
+	if !strings.Contains(stderr, "line 11\n") || !strings.Contains(stderr, "line 30") || strings.Contains(stderr, "line 10\n") {
+		t.Fatalf("stderr = %q, want lines 11 to 30 of the holon's output", stderr)
+	}
+}
+
+func TestRunWaitUsesTheAnnouncedAddress(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the holon")
+	}
+	chdirForTest(t, t.TempDir())
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	announced := "tcp://" + listener.Addr().String()
+
+	// The holon asked for port 0 and reports the port it got; the
+	// unresolved address it may print first is not taken for it.
+	cmd := exec.Command("sh", "-c", "echo tcp://127.0.0.1:0; echo "+announced+"; exec sleep 30")
+	t.Cleanup(func() { _ = cmd.Process.Kill() })
+	addrFile := filepath.Join(t.TempDir(), "addr")
+	opts := runOptions{ListenURI: "tcp://127.0.0.1:0", Wait: true, WaitTimeout: 5 * time.Second, AddrFile: addrFile}
+
+	code := 0
+	stdout := captureStdout(t, func() {
+		code = runUntilReady(FormatText, progress.Silence(), "announcer", cmd, opts)
+	})
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	if !strings.Contains(stdout, "announcer ready at "+announced) {
+		t.Fatalf("stdout = %q, want the announced address", stdout)
+	}
+	entry, ok := loadEndpoint("announcer")
+	if !ok || entry.URI != announced || entry.PID != cmd.Process.Pid {
+		t.Fatalf("endpoint = %+v (ok %v), want %s for pid %d", entry, ok, announced, cmd.Process.Pid)
+	}
+	if data, err := os.ReadFile(addrFile); err != nil || string(data) != announced+"\n" {
+		t.Fatalf("--addr-file = %q, %v; want %q", data, err, announced+"\n")
+	}
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	inspectpkg "github.com/organic-programming/grace-op/internal/inspect"
+)
+
+const schemaUsage = "usage: op schema <grpc://host:port|grpc+unix://path|holon> <method>"
+
+// cmdSchema runs `op schema <target> <method>`: it prints the fields of
+// method's request and response, with their proto types, cardinality and
+// nested messages. Targets resolve as for op example. --format json prints
+// a JSON Schema document whose input and output properties describe the two
+// messages.
+func cmdSchema(format Format, args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, schemaUsage)
+		return 1
+	}
+	target, method := args[0], args[1]
+
+	described, err := describeTargetMethod(target, method)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op schema: %s\n", rpcErrorMessage(err))
+		return 1
+	}
+
+	if format == FormatJSON {
+		printJSON(methodJSONSchema(described))
+		return 0
+	}
+	fmt.Printf("%s\n\n", described.Name)
+	fmt.Printf("input: %s%s\n", described.InputType, streamSuffix(described.ClientStreaming))
+	fmt.Print(inspectpkg.RenderSchemaTree(described.InputFields))
+	fmt.Printf("\noutput: %s%s\n", described.OutputType, streamSuffix(described.ServerStreaming))
+	fmt.Print(inspectpkg.RenderSchemaTree(described.OutputFields))
+	return 0
+}
+
+func streamSuffix(streaming bool) string {
+	if streaming {
+		return " (stream)"
+	}
+	return ""
+}
+
+func methodJSONSchema(method inspectpkg.Method) map[string]any {
+	input := inspectpkg.JSONSchema(method.InputFields)
+	input["title"] = method.InputType
+	output := inspectpkg.JSONSchema(method.OutputFields)
+	output["title"] = method.OutputType
+	return map[string]any{
+		"$schema": inspectpkg.JSONSchemaDialect,
+		"title":   method.Name,
+		"type":    "object",
+		"properties": map[string]any{
+			"input":  input,
+			"output": output,
+		},
+	}
+}
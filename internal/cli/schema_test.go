@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSchemaCommandPrintsFieldTree(t *testing.T) {
+	address := startOPServer(t)
+
+	output := captureStdout(t, func() {
+		if code := Run([]string{"schema", "grpc://" + address, "CreateIdentity"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("code = %d, want 0", code)
+		}
+	})
+
+	for _, want := range []string{
+		"input: op.v1.CreateIdentityRequest\n",
+		"  aliases       repeated string\n",
+		"one of CLADE_UNSPECIFIED, DETERMINISTIC_PURE",
+		"output: op.v1.CreateIdentityResponse\n",
+		"  identity        optional HolonIdentity\n    uuid",
+	} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestSchemaCommandOfflineJSONSchema(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedInspectableHolon(t, root)
+
+	output := captureStdout(t, func() {
+		if code := Run([]string{"--format", "json", "schema", "rob-go", "Build"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("code = %d, want 0", code)
+		}
+	})
+
+	var doc struct {
+		Schema     string `json:"$schema"`
+		Title      string `json:"title"`
+		Properties struct {
+			Input struct {
+				Title      string                     `json:"title"`
+				Properties map[string]json.RawMessage `json:"properties"`
+				Required   []string                   `json:"required"`
+			} `json:"input"`
+			Output struct {
+				Properties map[string]map[string]any `json:"properties"`
+			} `json:"output"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(output), &doc); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, output)
+	}
+	if doc.Schema == "" || doc.Title != "Build" {
+		t.Fatalf("document = %+v, want a titled JSON Schema", doc)
+	}
+	input := doc.Properties.Input
+	if input.Title != "rob_go.v1.BuildRequest" || len(input.Required) != 1 || input.Required[0] != "package" {
+		t.Fatalf("input = %+v, want BuildRequest with package required", input)
+	}
+	if got := doc.Properties.Output.Properties["success"]["type"]; got != "boolean" {
+		t.Fatalf("output success type = %#v, want boolean", got)
+	}
+}
@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/organic-programming/grace-op/internal/server"
+)
+
+// Environment variables op serve and op run fall back to when the matching
+// flag is absent, so a container entrypoint needs no argv. Flags override the
+// environment, which overrides the built-in defaults.
+const (
+	envListen    = "OP_LISTEN"
+	envNoReflect = "OP_NO_REFLECT"
+	envNoHealth  = "OP_NO_HEALTH"
+)
+
+// Where op serve and op run listen when neither --listen nor OP_LISTEN
+// says.
+const (
+	defaultServeListen = "tcp://:9090"
+	defaultRunListen   = "stdio://"
+)
+
+type serveConfig struct {
+	ListenURI   string
+	Reflect     bool
+	Health      bool
+	IdleTimeout time.Duration
+	Channelz    bool
+	SocketMode  os.FileMode
+	SocketOwner *server.SocketOwner
+	MaxRequests int
+	SandboxRoot string
+	AddrFile    string
+}
+
+// parseServeArgs reads op serve's flags, falling back to OP_LISTEN,
+// OP_NO_REFLECT and OP_NO_HEALTH. Reflection and health are served unless
+// turned off. The arguments it does not know are returned, since op
+// serve has always tolerated them, for the caller to warn about.
+func parseServeArgs(args []string) (serveConfig, []string, error) {
+	cfg := serveConfig{ListenURI: defaultServeListen, Reflect: true, Health: true}
+	var ignored []string
+	listenSet, reflectSet, healthSet, oneshot := false, false, false, false
+
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		switch name {
+		case "--listen", "--port", "--idle-timeout", "--socket-mode", "--socket-owner", "--max-requests", "--sandbox-root", "--addr-file":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return cfg, nil, fmt.Errorf("%s requires a value", name)
+				}
+				value = args[i+1]
+				i++
+			}
+			switch name {
+			case "--listen":
+				cfg.ListenURI = value
+				listenSet = true
+			case "--port":
+				// Legacy spelling; --listen wins when both are given.
+				if !listenSet {
+					cfg.ListenURI = "tcp://:" + value
+				}
+			case "--socket-mode":
+				mode, err := strconv.ParseUint(value, 8, 32)
+				if err != nil || mode == 0 || mode > 0o777 {
+					return cfg, nil, fmt.Errorf("invalid --socket-mode %q (want octal permissions such as 0660)", value)
+				}
+				cfg.SocketMode = os.FileMode(mode)
+			case "--socket-owner":
+				owner, err := parseSocketOwner(value)
+				if err != nil {
+					return cfg, nil, err
+				}
+				cfg.SocketOwner = owner
+			case "--max-requests":
+				n, err := strconv.Atoi(value)
+				if err != nil || n <= 0 {
+					return cfg, nil, fmt.Errorf("invalid --max-requests %q (want a positive count)", value)
+				}
+				if oneshot {
+					return cfg, nil, fmt.Errorf("--oneshot and --max-requests are mutually exclusive")
+				}
+				cfg.MaxRequests = n
+			case "--sandbox-root":
+				root, err := filepath.Abs(value)
+				if err != nil {
+					return cfg, nil, fmt.Errorf("invalid --sandbox-root %q: %w", value, err)
+				}
+				if info, err := os.Stat(root); err != nil || !info.IsDir() {
+					return cfg, nil, fmt.Errorf("--sandbox-root %q is not a directory", value)
+				}
+				cfg.SandboxRoot = root
+			case "--addr-file":
+				if strings.TrimSpace(value) == "" {
+					return cfg, nil, fmt.Errorf("--addr-file requires a path")
+				}
+				cfg.AddrFile = value
+			default:
+				timeout, err := time.ParseDuration(value)
+				if err != nil || timeout <= 0 {
+					return cfg, nil, fmt.Errorf("invalid --idle-timeout %q", value)
+				}
+				cfg.IdleTimeout = timeout
+			}
+		case "--no-reflect":
+			cfg.Reflect = false
+			reflectSet = true
+		case "--no-health":
+			cfg.Health = false
+			healthSet = true
+		case "--channelz":
+			cfg.Channelz = true
+		case "--oneshot":
+			if cfg.MaxRequests > 0 {
+				return cfg, nil, fmt.Errorf("--oneshot and --max-requests are mutually exclusive")
+			}
+			oneshot = true
+			cfg.MaxRequests = 1
+		default:
+			ignored = append(ignored, args[i])
+		}
+	}
+
+	if !listenSet && !hasArg(args, "--port") {
+		if value, ok := lookupNonEmptyEnv(envListen); ok {
+			cfg.ListenURI = value
+		}
+	}
+	if (cfg.SocketMode != 0 || cfg.SocketOwner != nil) && !strings.HasPrefix(cfg.ListenURI, "unix://") {
+		return cfg, nil, fmt.Errorf("--socket-mode and --socket-owner need a unix:// --listen address, got %q", cfg.ListenURI)
+	}
+	if !reflectSet {
+		disabled, err := envBool(envNoReflect)
+		if err != nil {
+			return cfg, nil, err
+		}
+		cfg.Reflect = !disabled
+	}
+	if !healthSet {
+		disabled, err := envBool(envNoHealth)
+		if err != nil {
+			return cfg, nil, err
+		}
+		cfg.Health = !disabled
+	}
+	return cfg, ignored, nil
+}
+
+// parseSocketOwner resolves --socket-owner's "user:group", "user" or
+// ":group" to numeric IDs. Names are looked up in the account database;
+// numbers are taken as IDs.
+func parseSocketOwner(spec string) (*server.SocketOwner, error) {
+	userName, groupName, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	if userName == "" && groupName == "" {
+		return nil, fmt.Errorf("--socket-owner requires user:group")
+	}
+
+	owner := &server.SocketOwner{UID: -1, GID: -1}
+	if userName != "" {
+		uid, err := lookupAccountID(userName, func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("--socket-owner: unknown user %q: %w", userName, err)
+		}
+		owner.UID = uid
+	}
+	if groupName != "" {
+		gid, err := lookupAccountID(groupName, func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("--socket-owner: unknown group %q: %w", groupName, err)
+		}
+		owner.GID = gid
+	}
+	return owner, nil
+}
+
+func lookupAccountID(name string, lookup func(string) (string, error)) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil && id >= 0 {
+		return id, nil
+	}
+	raw, err := lookup(name)
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("non-numeric id %q", raw)
+	}
+	return id, nil
+}
+
+func hasArg(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == name || strings.HasPrefix(arg, name+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+func lookupNonEmptyEnv(key string) (string, bool) {
+	value, ok := os.LookupEnv(key)
+	value = strings.TrimSpace(value)
+	return value, ok && value != ""
+}
+
+// envBool reads a boolean variable such as OP_NO_REFLECT=1. Unset or empty
+// means false.
+func envBool(key string) (bool, error) {
+	value, ok := lookupNonEmptyEnv(key)
+	if !ok {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q (want true or false)", key, value)
+	}
+	return parsed, nil
+}
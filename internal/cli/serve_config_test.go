@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/organic-programming/grace-op/internal/server"
+)
+
+func TestParseServeArgsDefaults(t *testing.T) {
+	t.Setenv(envListen, "")
+	t.Setenv(envNoReflect, "")
+	t.Setenv(envNoHealth, "")
+
+	cfg, _, err := parseServeArgs(nil)
+	if err != nil {
+		t.Fatalf("parseServeArgs: %v", err)
+	}
+	want := serveConfig{ListenURI: "tcp://:9090", Reflect: true, Health: true}
+	if cfg != want {
+		t.Fatalf("cfg = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestParseServeArgsEnvOverridesDefaults(t *testing.T) {
+	t.Setenv(envListen, "unix:///tmp/op.sock")
+	t.Setenv(envNoReflect, "1")
+	t.Setenv(envNoHealth, "true")
+
+	cfg, _, err := parseServeArgs(nil)
+	if err != nil {
+		t.Fatalf("parseServeArgs: %v", err)
+	}
+	want := serveConfig{ListenURI: "unix:///tmp/op.sock", Reflect: false, Health: false}
+	if cfg != want {
+		t.Fatalf("cfg = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestParseServeArgsFlagsOverrideEnv(t *testing.T) {
+	t.Setenv(envListen, "unix:///tmp/op.sock")
+	t.Setenv(envNoReflect, "false")
+	t.Setenv(envNoHealth, "")
+
+	cfg, _, err := parseServeArgs([]string{"--listen", "stdio://", "--no-reflect", "--no-health", "--idle-timeout=30s"})
+	if err != nil {
+		t.Fatalf("parseServeArgs: %v", err)
+	}
+	want := serveConfig{ListenURI: "stdio://", Reflect: false, Health: false, IdleTimeout: 30 * time.Second}
+	if cfg != want {
+		t.Fatalf("cfg = %+v, want %+v", cfg, want)
+	}
+
+	cfg, _, err = parseServeArgs([]string{"--port", "7000"})
+	if err != nil {
+		t.Fatalf("parseServeArgs --port: %v", err)
+	}
+	if cfg.ListenURI != "tcp://:7000" {
+		t.Fatalf("--port listen = %q, want tcp://:7000 over OP_LISTEN", cfg.ListenURI)
+	}
+}
+
+func TestParseServeArgsChannelzIsOptIn(t *testing.T) {
+	cfg, _, err := parseServeArgs(nil)
+	if err != nil {
+		t.Fatalf("parseServeArgs: %v", err)
+	}
+	if cfg.Channelz {
+		t.Fatal("channelz enabled by default")
+	}
+
+	cfg, _, err = parseServeArgs([]string{"--channelz"})
+	if err != nil {
+		t.Fatalf("parseServeArgs: %v", err)
+	}
+	if !cfg.Channelz {
+		t.Fatal("--channelz did not enable channelz")
+	}
+}
+
+func TestParseServeArgsRejectsBadEnv(t *testing.T) {
+	t.Setenv(envNoReflect, "maybe")
+
+	if _, _, err := parseServeArgs(nil); err == nil {
+		t.Fatal("expected an error for OP_NO_REFLECT=maybe")
+	}
+}
+
+func TestParseRunArgsListenFromEnv(t *testing.T) {
+	t.Setenv(envListen, "tcp://:7100")
+
+	_, opts, err := parseRunArgs([]string{"demo"})
+	if err != nil {
+		t.Fatalf("parseRunArgs: %v", err)
+	}
+	if opts.ListenURI != "tcp://:7100" || opts.ListenExplicit {
+		t.Fatalf("listen = (%q, explicit %v), want OP_LISTEN as the default", opts.ListenURI, opts.ListenExplicit)
+	}
+
+	_, opts, err = parseRunArgs([]string{"demo", "--listen", "stdio://"})
+	if err != nil {
+		t.Fatalf("parseRunArgs --listen: %v", err)
+	}
+	if opts.ListenURI != "stdio://" {
+		t.Fatalf("listen = %q, want the flag over OP_LISTEN", opts.ListenURI)
+	}
+}
+
+func TestParseServeArgsSocketPermissions(t *testing.T) {
+	t.Setenv(envListen, "")
+	current, err := user.Current()
+	if err != nil {
+		t.Skipf("no current user: %v", err)
+	}
+	group, err := user.LookupGroupId(current.Gid)
+	if err != nil {
+		t.Skipf("no current group: %v", err)
+	}
+
+	cfg, _, err := parseServeArgs([]string{"--listen", "unix:///tmp/op.sock", "--socket-mode", "0660", "--socket-owner", current.Username + ":" + group.Name})
+	if err != nil {
+		t.Fatalf("parseServeArgs: %v", err)
+	}
+	uid, _ := strconv.Atoi(current.Uid)
+	gid, _ := strconv.Atoi(current.Gid)
+	if cfg.SocketMode != 0o660 || cfg.SocketOwner == nil || *cfg.SocketOwner != (server.SocketOwner{UID: uid, GID: gid}) {
+		t.Fatalf("cfg = %+v, owner %+v", cfg, cfg.SocketOwner)
+	}
+
+	cfg, _, err = parseServeArgs([]string{"--listen", "unix:///tmp/op.sock", "--socket-owner", ":" + current.Gid})
+	if err != nil || *cfg.SocketOwner != (server.SocketOwner{UID: -1, GID: gid}) {
+		t.Fatalf("group-only owner = %+v, %v", cfg.SocketOwner, err)
+	}
+
+	if _, _, err := parseServeArgs([]string{"--listen", "unix:///tmp/op.sock", "--socket-owner", "no-such-user-op-test"}); err == nil || !strings.Contains(err.Error(), `unknown user "no-such-user-op-test"`) {
+		t.Fatalf("unknown user error = %v", err)
+	}
+	if _, _, err := parseServeArgs([]string{"--listen", "tcp://:9090", "--socket-mode", "0600"}); err == nil || !strings.Contains(err.Error(), "unix://") {
+		t.Fatalf("tcp --socket-mode error = %v", err)
+	}
+	if _, _, err := parseServeArgs([]string{"--listen", "unix:///tmp/op.sock", "--socket-mode", "rw-rw----"}); err == nil {
+		t.Fatal("non-octal --socket-mode should fail")
+	}
+}
+
+func TestParseServeArgsRequestLimit(t *testing.T) {
+	t.Setenv(envListen, "")
+
+	cfg, _, err := parseServeArgs([]string{"--oneshot"})
+	if err != nil || cfg.MaxRequests != 1 {
+		t.Fatalf("--oneshot: max requests = %d, err = %v; want 1", cfg.MaxRequests, err)
+	}
+	cfg, _, err = parseServeArgs([]string{"--max-requests=3"})
+	if err != nil || cfg.MaxRequests != 3 {
+		t.Fatalf("--max-requests=3: max requests = %d, err = %v; want 3", cfg.MaxRequests, err)
+	}
+	for _, args := range [][]string{
+		{"--max-requests", "0"},
+		{"--max-requests", "many"},
+		{"--oneshot", "--max-requests", "2"},
+		{"--max-requests", "2", "--oneshot"},
+	} {
+		if _, _, err := parseServeArgs(args); err == nil {
+			t.Fatalf("parseServeArgs(%q) should fail", args)
+		}
+	}
+}
+
+func TestParseServeArgsSandboxRoot(t *testing.T) {
+	t.Setenv(envListen, "")
+	dir := t.TempDir()
+
+	cfg, _, err := parseServeArgs([]string{"--sandbox-root", dir})
+	if err != nil {
+		t.Fatalf("parseServeArgs: %v", err)
+	}
+	if cfg.SandboxRoot != dir {
+		t.Fatalf("SandboxRoot = %q, want %q", cfg.SandboxRoot, dir)
+	}
+	if _, _, err := parseServeArgs([]string{"--sandbox-root=" + filepath.Join(dir, "missing")}); err == nil {
+		t.Fatal("a missing --sandbox-root was accepted")
+	}
+}
+
+func TestParseServeArgsIgnoresUnknownArguments(t *testing.T) {
+	t.Setenv(envListen, "")
+	t.Setenv(envNoReflect, "")
+	t.Setenv(envNoHealth, "")
+
+	cfg, ignored, err := parseServeArgs([]string{"--listen", "tcp://:7000", "--verbose", "extra"})
+	if err != nil {
+		t.Fatalf("parseServeArgs: %v", err)
+	}
+	if cfg.ListenURI != "tcp://:7000" {
+		t.Fatalf("ListenURI = %q, want the flags around the unknown ones read", cfg.ListenURI)
+	}
+	if strings.Join(ignored, " ") != "--verbose extra" {
+		t.Fatalf("ignored = %q, want the unknown arguments", ignored)
+	}
+}
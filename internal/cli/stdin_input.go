@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// stdinInput is where request JSON is read from when the input argument is
+// "-". Tests replace it.
+var stdinInput io.Reader = os.Stdin
+
+// stdinIsTerminal reports whether stdinInput is an interactive terminal
+// rather than a pipe or file. Tests replace it.
+var stdinIsTerminal = func() bool {
+	file, ok := stdinInput.(*os.File)
+	return ok && term.IsTerminal(int(file.Fd()))
+}
+
+// applyStdinInput resolves a "-" request argument to the JSON read from
+// stdin, so the output of one op call can feed the next:
+//
+//	op grpc://a:9090 ShowIdentity '{"uuid":"..."}' | op grpc://b:9090 CreateIdentity - --from-field identity
+//
+// --from-field <path> selects a sub-object of the piped JSON; it implies "-"
+// when no request argument is given.
+func applyStdinInput(args []string) ([]string, error) {
+	field, hasField, args, err := extractFromFieldFlag(args)
+	if err != nil {
+		return nil, err
+	}
+
+	stdinAt := -1
+	for i := 1; i < len(args); i++ {
+		if args[i] == "-" {
+			stdinAt = i
+			break
+		}
+	}
+	if stdinAt < 0 {
+		if !hasField {
+			return args, nil
+		}
+		if len(args) > 1 && looksLikeJSON(args[1]) {
+			return nil, fmt.Errorf("--from-field reads stdin and cannot be combined with a JSON argument")
+		}
+		if len(args) == 0 {
+			return args, nil
+		}
+		args = append([]string{args[0], "-"}, args[1:]...)
+		stdinAt = 1
+	}
+
+	if stdinIsTerminal() {
+		// Nothing was piped: say why op waits instead of hanging silently.
+		fmt.Fprintf(os.Stderr, "op: reading request JSON from stdin; end with %s, or pass the JSON as an argument\n", eofKey())
+	}
+	data, err := io.ReadAll(stdinInput)
+	if err != nil {
+		return nil, fmt.Errorf("read request from stdin: %w", err)
+	}
+	input, err := requestFromPipedJSON(data, field)
+	if err != nil {
+		return nil, err
+	}
+
+	out := append([]string{}, args...)
+	out[stdinAt] = input
+	return out, nil
+}
+
+// eofKey is the keystroke that ends terminal input.
+func eofKey() string {
+	if runtime.GOOS == "windows" {
+		return "Ctrl-Z then Enter"
+	}
+	return "Ctrl-D"
+}
+
+// requestFromPipedJSON validates piped JSON and, when field is set, extracts
+// the object at that path to use as the request.
+func requestFromPipedJSON(data []byte, field string) (string, error) {
+	if strings.TrimSpace(string(data)) == "" {
+		return "", fmt.Errorf("no request JSON on stdin")
+	}
+	value, err := decodeJSONValue(data)
+	if err != nil {
+		return "", fmt.Errorf("stdin is not valid JSON: %w", err)
+	}
+
+	if field != "" {
+		value, err = selectJSONPath(value, field)
+		if err != nil {
+			return "", fmt.Errorf("--from-field %s: %w", field, err)
+		}
+	}
+	if _, ok := value.(map[string]any); !ok {
+		if field != "" {
+			return "", fmt.Errorf("--from-field %s selects a %s, not an object", field, jsonKind(value))
+		}
+		return "", fmt.Errorf("stdin JSON is a %s, not an object", jsonKind(value))
+	}
+
+	out, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// extractFromFieldFlag removes --from-field <path> from args.
+func extractFromFieldFlag(args []string) (string, bool, []string, error) {
+	field := ""
+	found := false
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--from-field":
+			if i+1 >= len(args) {
+				return "", false, nil, fmt.Errorf("--from-field requires a path")
+			}
+			field = args[i+1]
+			found = true
+			i++
+		case strings.HasPrefix(args[i], "--from-field="):
+			field = strings.TrimPrefix(args[i], "--from-field=")
+			found = true
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+	if found {
+		if _, err := splitJSONPath(field); err != nil {
+			return "", false, nil, fmt.Errorf("--from-field: %w", err)
+		}
+	}
+	return field, found, remaining, nil
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelectJSONPath(t *testing.T) {
+	value, err := decodeJSONValue([]byte(`{"entries":[{"identity":{"givenName":"Alpha","born":12345678901234567}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"entries[0].identity.givenName", "entries.0.identity.givenName", ".entries[0].identity.givenName"} {
+		got, err := selectJSONPath(value, path)
+		if err != nil {
+			t.Fatalf("selectJSONPath(%q) returned error: %v", path, err)
+		}
+		if got != "Alpha" {
+			t.Fatalf("selectJSONPath(%q) = %v, want Alpha", path, got)
+		}
+	}
+
+	if _, err := selectJSONPath(value, "entries[3]"); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Fatalf("out-of-range error = %v", err)
+	}
+	if _, err := selectJSONPath(value, "entries[0].missing"); err == nil || !strings.Contains(err.Error(), `field "missing" not found at entries.0`) {
+		t.Fatalf("missing-field error = %v", err)
+	}
+}
+
+func TestApplyStdinInputExtractsField(t *testing.T) {
+	setStdinInputForTest(t, `{"identity":{"uuid":"abc","born":12345678901234567},"filePath":"x"}`)
+
+	args, err := applyStdinInput([]string{"ShowIdentity", "--from-field", "identity"})
+	if err != nil {
+		t.Fatalf("applyStdinInput returned error: %v", err)
+	}
+	if got := strings.Join(args, " "); got != `ShowIdentity {"born":12345678901234567,"uuid":"abc"}` {
+		t.Fatalf("args = %q", got)
+	}
+}
+
+func TestApplyStdinInputDash(t *testing.T) {
+	setStdinInputForTest(t, `{"uuid":"abc"}`)
+
+	args, err := applyStdinInput([]string{"show", "-"})
+	if err != nil {
+		t.Fatalf("applyStdinInput returned error: %v", err)
+	}
+	if got := strings.Join(args, " "); got != `show {"uuid":"abc"}` {
+		t.Fatalf("args = %q", got)
+	}
+
+	stdinInput = strings.NewReader(`{"entries":[]}`)
+	if _, err := applyStdinInput([]string{"show", "-", "--from-field", "entries"}); err == nil || !strings.Contains(err.Error(), "not an object") {
+		t.Fatalf("non-object selection error = %v", err)
+	}
+}
+
+func TestApplyStdinInputWithoutDashKeepsArgs(t *testing.T) {
+	args, err := applyStdinInput([]string{"ListIdentities", `{"rootDir":"."}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(args) != 2 || args[1] != `{"rootDir":"."}` {
+		t.Fatalf("args = %q", args)
+	}
+}
+
+func setStdinInputForTest(t *testing.T, data string) {
+	t.Helper()
+	previous := stdinInput
+	stdinInput = strings.NewReader(data)
+	t.Cleanup(func() { stdinInput = previous })
+}
+
+func TestApplyStdinInputHintsWhenStdinIsATerminal(t *testing.T) {
+	setStdinInputForTest(t, `{"uuid":"abc"}`)
+	previous := stdinIsTerminal
+	t.Cleanup(func() { stdinIsTerminal = previous })
+
+	stdinIsTerminal = func() bool { return false }
+	if stderr := captureStderr(t, func() { _, _ = applyStdinInput([]string{"show", "-"}) }); stderr != "" {
+		t.Fatalf("stderr = %q, want no hint for piped input", stderr)
+	}
+
+	stdinInput = strings.NewReader(`{"uuid":"abc"}`)
+	stdinIsTerminal = func() bool { return true }
+	var args []string
+	stderr := captureStderr(t, func() {
+		var err error
+		if args, err = applyStdinInput([]string{"show", "-"}); err != nil {
+			t.Errorf("applyStdinInput returned error: %v", err)
+		}
+	})
+	if !strings.Contains(stderr, "reading request JSON from stdin; end with") {
+		t.Fatalf("stderr = %q, want the stdin hint", stderr)
+	}
+	if got := strings.Join(args, " "); got != `show {"uuid":"abc"}` {
+		t.Fatalf("args = %q, want typed input still accepted", got)
+	}
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// runStopTimeout is how long op stop waits for a holon to exit after
+// SIGTERM before it sends SIGKILL. Tests shorten it.
+var runStopTimeout = 5 * time.Second
+
+const (
+	holonStatusLive = "live"
+	holonStatusDead = "dead"
+)
+
+// trackedHolon is one entry of op ps.
+type trackedHolon struct {
+	Holon  string `json:"holon"`
+	PID    int    `json:"pid"`
+	URI    string `json:"uri"`
+	Status string `json:"status"`
+}
+
+type stopReport struct {
+	Holon  string `json:"holon"`
+	PID    int    `json:"pid"`
+	Killed bool   `json:"killed"`
+}
+
+// cmdStop runs `op stop <holon>`: it stops a holon recorded by op run or
+// op serve and removes its entry.
+func cmdStop(format Format, args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: op stop <holon>")
+		return 1
+	}
+	report, err := stopHolon(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op stop: %v\n", err)
+		return 1
+	}
+	switch {
+	case format == FormatJSON:
+		printJSON(report)
+	case report.Killed:
+		fmt.Printf("%s (pid %d) killed: still running %s after SIGTERM\n", report.Holon, report.PID, runStopTimeout)
+	default:
+		fmt.Printf("%s (pid %d) stopped\n", report.Holon, report.PID)
+	}
+	return 0
+}
+
+// stopHolon sends SIGTERM to the recorded process of holonName, escalates
+// to SIGKILL after runStopTimeout, and removes its state file. The process
+// is only signalled while its endpoint still answers: a PID reused by
+// another process since must not be killed. An entry whose process already
+// exited is removed and reported as an error.
+func stopHolon(holonName string) (stopReport, error) {
+	entry, ok := loadEndpoint(holonName)
+	if !ok {
+		return stopReport{}, fmt.Errorf("%s is not tracked; see op ps", holonName)
+	}
+	report := stopReport{Holon: holonName, PID: entry.PID}
+	if !processAlive(entry.PID) {
+		forgetEndpoint(holonName, entry.PID)
+		return report, fmt.Errorf("%s is not running (pid %d already exited); removed its entry", holonName, entry.PID)
+	}
+	if err := probeEndpoint(entry.URI); err != nil {
+		return report, fmt.Errorf("%s does not answer on %s (%v); pid %d may be another process now, so it was not signalled", holonName, entry.URI, err, entry.PID)
+	}
+
+	process, err := os.FindProcess(entry.PID)
+	if err != nil {
+		return report, err
+	}
+	if runtime.GOOS == "windows" {
+		// Windows has no SIGTERM, and processAlive cannot see the exit.
+		if err := process.Kill(); err != nil {
+			return report, fmt.Errorf("kill pid %d: %w", entry.PID, err)
+		}
+		report.Killed = true
+		forgetEndpoint(holonName, entry.PID)
+		return report, nil
+	}
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		return report, fmt.Errorf("signal pid %d: %w", entry.PID, err)
+	}
+
+	if !waitProcessExit(entry.PID, runStopTimeout) {
+		if err := process.Kill(); err != nil {
+			return report, fmt.Errorf("kill pid %d: %w", entry.PID, err)
+		}
+		report.Killed = true
+		if !waitProcessExit(entry.PID, runStopTimeout) {
+			return report, fmt.Errorf("pid %d still running after SIGKILL", entry.PID)
+		}
+	}
+	forgetEndpoint(holonName, entry.PID)
+	return report, nil
+}
+
+// waitProcessExit polls until pid is gone and reports whether it went
+// within timeout.
+func waitProcessExit(pid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for processAlive(pid) {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return true
+}
+
+// cmdPs runs `op ps`: every holon recorded by op run or op serve, live when
+// its process exists and its endpoint accepts a connection.
+func cmdPs(format Format, args []string) int {
+	if len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "usage: op ps")
+		return 1
+	}
+	holons := trackedHolons()
+	if format == FormatJSON {
+		printJSON(holons)
+		return 0
+	}
+	if len(holons) == 0 {
+		fmt.Println("no holons tracked")
+		return 0
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOLON\tPID\tURI\tSTATUS")
+	for _, h := range holons {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", h.Holon, h.PID, h.URI, h.Status)
+	}
+	_ = tw.Flush()
+	return 0
+}
+
+// trackedHolons lists the registry sorted by holon name. Unlike the
+// transport chain, it shows dead entries; one whose process has exited is
+// then removed, so it is reported once.
+func trackedHolons() []trackedHolon {
+	endpoints := loadEndpoints()
+	holons := make([]trackedHolon, 0, len(endpoints))
+	for name, entry := range endpoints {
+		status := holonStatusDead
+		alive := processAlive(entry.PID)
+		if alive && probeEndpoint(entry.URI) == nil {
+			status = holonStatusLive
+		}
+		if !alive {
+			forgetEndpoint(name, entry.PID)
+		}
+		holons = append(holons, trackedHolon{Holon: name, PID: entry.PID, URI: entry.URI, Status: status})
+	}
+	sort.Slice(holons, func(i, j int) bool { return holons[i].Holon < holons[j].Holon })
+	return holons
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTrackedProcess starts script under sh, records it as holonName
+// listening on uri, and reaps it when it exits so it does not linger as a
+// zombie that still answers signal 0.
+func startTrackedProcess(t *testing.T, holonName, uri, script string) *exec.Cmd {
+	t.Helper()
+	cmd := exec.Command("sh", "-c", script)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		<-exited
+	})
+	recordEndpoint(holonName, uri, cmd.Process.Pid)
+	return cmd
+}
+
+func TestStopTerminatesTrackedHolon(t *testing.T) {
+	chdirForTest(t, t.TempDir())
+	cmd := startTrackedProcess(t, "beta", "tcp://"+startOPServer(t), "exec sleep 30")
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"stop", "beta"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("op stop returned %d, want 0", code)
+		}
+	})
+	if !strings.Contains(stdout, "stopped") {
+		t.Fatalf("stdout = %q, want the holon reported stopped", stdout)
+	}
+	if processAlive(cmd.Process.Pid) {
+		t.Fatal("process still running after op stop")
+	}
+	if _, ok := loadEndpoints()["beta"]; ok {
+		t.Fatal("entry kept after op stop")
+	}
+}
+
+func TestStopKillsHolonIgnoringSIGTERM(t *testing.T) {
+	chdirForTest(t, t.TempDir())
+	previous := runStopTimeout
+	runStopTimeout = 200 * time.Millisecond
+	t.Cleanup(func() { runStopTimeout = previous })
+
+	cmd := startTrackedProcess(t, "stubborn", "tcp://"+startOPServer(t), `trap "" TERM; exec sleep 30`)
+	// Give sh time to install the trap before op stop signals it.
+	time.Sleep(100 * time.Millisecond)
+
+	report, err := stopHolon("stubborn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Killed || processAlive(cmd.Process.Pid) {
+		t.Fatalf("report = %+v, want the process killed after SIGTERM was ignored", report)
+	}
+}
+
+func TestStopRemovesEntryOfExitedHolon(t *testing.T) {
+	chdirForTest(t, t.TempDir())
+	exited := exec.Command("sh", "-c", "exit 0")
+	if err := exited.Run(); err != nil {
+		t.Fatal(err)
+	}
+	recordEndpoint("gone", "tcp://127.0.0.1:1", exited.Process.Pid)
+
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"stop", "gone"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("op stop returned %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "already exited") {
+		t.Fatalf("stderr = %q, want the exited process reported", stderr)
+	}
+	if _, ok := loadEndpoints()["gone"]; ok {
+		t.Fatal("stale entry kept")
+	}
+
+	stderr = captureStderr(t, func() {
+		if code := Run([]string{"stop", "gone"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("op stop returned %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "not tracked") {
+		t.Fatalf("stderr = %q, want an untracked holon reported", stderr)
+	}
+}
+
+func TestStopLeavesProcessWhoseEndpointIsGone(t *testing.T) {
+	chdirForTest(t, t.TempDir())
+	// Nothing listens on the recorded endpoint: the pid may belong to
+	// another process by now.
+	cmd := startTrackedProcess(t, "reused", "tcp://127.0.0.1:1", "exec sleep 30")
+
+	stderr := captureStderr(t, func() {
+		if code := Run([]string{"stop", "reused"}, "0.1.0-test"); code != 1 {
+			t.Fatalf("op stop returned %d, want 1", code)
+		}
+	})
+	if !strings.Contains(stderr, "not signalled") {
+		t.Fatalf("stderr = %q, want the process left alone", stderr)
+	}
+	if !processAlive(cmd.Process.Pid) {
+		t.Fatal("op stop signalled a process whose endpoint does not answer")
+	}
+}
+
+func TestStopUsesPerHolonStateFile(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	startTrackedProcess(t, "beta", "tcp://"+startOPServer(t), "exec sleep 30")
+
+	path := filepath.Join(root, ".holon", "run", "beta.json")
+	var entry runningEndpoint
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Holon != "beta" || entry.PID <= 0 || entry.URI == "" {
+		t.Fatalf("%s = %s (%v), want the holon, pid and uri", path, data, err)
+	}
+	if _, err := stopHolon("beta"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("%s kept after op stop: %v", path, err)
+	}
+}
+
+func TestPsReportsLiveAndDeadHolons(t *testing.T) {
+	chdirForTest(t, t.TempDir())
+	address := startOPServer(t)
+	recordEndpoint("alpha", "tcp://"+address, os.Getpid())
+	exited := exec.Command("sh", "-c", "exit 0")
+	if err := exited.Run(); err != nil {
+		t.Fatal(err)
+	}
+	recordEndpoint("beta", "tcp://"+address, exited.Process.Pid)
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"--format", "json", "ps"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("op ps returned %d, want 0", code)
+		}
+	})
+	var holons []trackedHolon
+	if err := json.Unmarshal([]byte(stdout), &holons); err != nil {
+		t.Fatalf("stdout %q is not JSON: %v", stdout, err)
+	}
+	want := []trackedHolon{
+		{Holon: "alpha", PID: os.Getpid(), URI: "tcp://" + address, Status: holonStatusLive},
+		{Holon: "beta", PID: exited.Process.Pid, URI: "tcp://" + address, Status: holonStatusDead},
+	}
+	if len(holons) != len(want) || holons[0] != want[0] || holons[1] != want[1] {
+		t.Fatalf("op ps = %+v, want %+v", holons, want)
+	}
+
+	// The exited holon was listed once, then pruned.
+	stdout = captureStdout(t, func() { Run([]string{"ps"}, "0.1.0-test") })
+	if !strings.HasPrefix(stdout, "HOLON") || !strings.Contains(stdout, "alpha") || strings.Contains(stdout, "beta") {
+		t.Fatalf("stdout = %q, want a table with only the live holon", stdout)
+	}
+}
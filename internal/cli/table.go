@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// tableColumn describes one column of a listing table. When the table is
+// wider than the terminal, Truncate columns are shortened first, then
+// columns are dropped from the highest Drop rank down. A zero Drop rank
+// keeps the column whatever the width.
+type tableColumn struct {
+	Header   string
+	Drop     int
+	Truncate bool
+}
+
+// table is a tabwriter table that can shrink to fit a terminal.
+type table struct {
+	columns []tableColumn
+	rows    [][]string
+}
+
+// minTruncatedWidth is the narrowest a Truncate column gets before the
+// table starts dropping columns instead.
+const minTruncatedWidth = 12
+
+// terminalWidth reports the width of stdout in columns, or 0 when stdout is
+// not a terminal. Tests replace it.
+var terminalWidth = func() int {
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return 0
+	}
+	width, _, err := term.GetSize(fd)
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// tableWidth is the width listing tables must fit in: the terminal width
+// for text output, 0 (no limit) for --format wide or when stdout is piped.
+func tableWidth(format Format) int {
+	if format == FormatWide {
+		return 0
+	}
+	return terminalWidth()
+}
+
+func newTable(columns ...tableColumn) *table {
+	return &table{columns: columns}
+}
+
+func (t *table) add(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// render writes the table, prefixing each line with indent. A width of 0
+// renders every column in full.
+func (t *table) render(w io.Writer, width int, indent string) {
+	visible, limits := t.fit(width - utf8.RuneCountInString(indent))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := make([]string, 0, len(limits))
+	for _, col := range visible {
+		header = append(header, t.columns[col].Header)
+	}
+	fmt.Fprintf(tw, "%s%s\n", indent, strings.Join(header, "\t"))
+	for _, row := range t.rows {
+		cells := make([]string, 0, len(visible))
+		for i, col := range visible {
+			cell := ""
+			if col < len(row) {
+				cell = row[col]
+			}
+			cells = append(cells, truncateCell(cell, limits[i]))
+		}
+		fmt.Fprintf(tw, "%s%s\n", indent, strings.Join(cells, "\t"))
+	}
+	_ = tw.Flush()
+}
+
+// fit picks the columns to show and their width limits, 0 meaning
+// unlimited, so that a line takes at most width runes where possible.
+func (t *table) fit(width int) ([]int, []int) {
+	visible := make([]int, len(t.columns))
+	for i := range t.columns {
+		visible[i] = i
+	}
+	for {
+		widths := t.columnWidths(visible)
+		limits := make([]int, len(visible))
+		if width <= 0 {
+			return visible, limits
+		}
+		excess := lineWidth(widths) - width
+		for i := len(visible) - 1; i >= 0 && excess > 0; i-- {
+			if !t.columns[visible[i]].Truncate || widths[i] <= minTruncatedWidth {
+				continue
+			}
+			cut := min(excess, widths[i]-minTruncatedWidth)
+			limits[i] = widths[i] - cut
+			excess -= cut
+		}
+		if excess <= 0 {
+			return visible, limits
+		}
+
+		drop := -1
+		for i, col := range visible {
+			if rank := t.columns[col].Drop; rank > 0 && (drop < 0 || rank > t.columns[visible[drop]].Drop) {
+				drop = i
+			}
+		}
+		if drop < 0 {
+			return visible, limits
+		}
+		visible = append(visible[:drop], visible[drop+1:]...)
+	}
+}
+
+func (t *table) columnWidths(visible []int) []int {
+	widths := make([]int, len(visible))
+	for i, col := range visible {
+		widths[i] = utf8.RuneCountInString(t.columns[col].Header)
+		for _, row := range t.rows {
+			if col < len(row) {
+				widths[i] = max(widths[i], utf8.RuneCountInString(row[col]))
+			}
+		}
+	}
+	return widths
+}
+
+// lineWidth is the rendered width of a row with the given column widths,
+// counting tabwriter's two-space padding.
+func lineWidth(widths []int) int {
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	if len(widths) > 1 {
+		total += 2 * (len(widths) - 1)
+	}
+	return total
+}
+
+// truncateCell shortens value to limit runes, marking the cut with an
+// ellipsis. A limit of 0 leaves the value alone.
+func truncateCell(value string, limit int) string {
+	if limit <= 0 || utf8.RuneCountInString(value) <= limit {
+		return value
+	}
+	runes := []rune(value)
+	return string(runes[:limit-1]) + "…"
+}
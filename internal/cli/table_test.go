@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
+)
+
+func TestTableRenderTruncatesThenDropsColumns(t *testing.T) {
+	tbl := newTable(
+		tableColumn{Header: "SLUG"},
+		tableColumn{Header: "PATH", Truncate: true},
+		tableColumn{Header: "ORIGIN", Drop: 1},
+	)
+	tbl.add("alpha", "holons/some/deeply/nested/alpha", "local")
+
+	render := func(width int) []string {
+		var b strings.Builder
+		tbl.render(&b, width, "")
+		return strings.Split(strings.TrimRight(b.String(), "\n"), "\n")
+	}
+
+	full := render(0)
+	if full[1] != "alpha  holons/some/deeply/nested/alpha  local" {
+		t.Fatalf("unbounded row = %q", full[1])
+	}
+
+	truncated := render(40)
+	if truncated[1] != "alpha  holons/some/deeply/neste…  local" {
+		t.Fatalf("row at width 40 = %q", truncated[1])
+	}
+	for _, line := range truncated {
+		if n := len([]rune(line)); n > 40 {
+			t.Fatalf("line %q is %d runes wide, want <= 40", line, n)
+		}
+	}
+
+	dropped := render(20)
+	if dropped[0] != "SLUG   PATH" || dropped[1] != "alpha  holons/some/…" {
+		t.Fatalf("rows at width 20 = %q", dropped)
+	}
+}
+
+func TestFormatResponseWideKeepsFullTable(t *testing.T) {
+	saved := terminalWidth
+	terminalWidth = func() int { return 40 }
+	t.Cleanup(func() { terminalWidth = saved })
+
+	resp := &opv1.ListIdentitiesResponse{
+		Entries: []*opv1.HolonEntry{{
+			Identity: &opv1.HolonIdentity{
+				Uuid:       "12345678-90ab-cdef-1234-567890abcdef",
+				GivenName:  "Alpha",
+				FamilyName: "Holon",
+				Clade:      opv1.Clade_DETERMINISTIC_PURE,
+				Status:     opv1.Status_DRAFT,
+				Lang:       "go",
+			},
+			Origin:       "local",
+			RelativePath: "holons/alpha",
+		}},
+	}
+
+	narrow := FormatResponse(FormatText, resp)
+	for _, line := range strings.Split(narrow, "\n") {
+		if n := len([]rune(line)); n > 40 {
+			t.Fatalf("text line %q is %d runes wide, want <= 40", line, n)
+		}
+	}
+	if strings.Contains(narrow, "ORIGIN") {
+		t.Fatalf("ORIGIN should be dropped first on a narrow terminal: %q", narrow)
+	}
+
+	wide := FormatResponse(FormatWide, resp)
+	for _, want := range []string{"ORIGIN", "CLADE", "12345678-90ab-cdef-1234-567890abcdef", "holons/alpha"} {
+		if !strings.Contains(wide, want) {
+			t.Fatalf("wide output missing %q: %q", want, wide)
+		}
+	}
+}
@@ -1,47 +1,221 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/organic-programming/grace-op/internal/holons"
 )
 
-// selectTransport determines the best transport for a target holon.
-// Priority:
-//  1. Already running (known endpoint) -> dial existing
+// envTransport forces the transport chain's choice, like --transport.
+const envTransport = "OP_TRANSPORT"
+
+// transportChainOrder is the order the chain tries transports in, after
+// the endpoint of a running instance.
+var transportChainOrder = []string{"mem", "stdio", "tcp"}
+
+// TransportDecision explains how op picked the transport for a holon: the
+// chosen scheme and endpoint, why, every candidate the chain considered, and
+// the override that forced the choice, if any.
+type TransportDecision struct {
+	Holon      string               `json:"holon"`
+	Scheme     string               `json:"scheme"`
+	Endpoint   string               `json:"endpoint,omitempty"`
+	Reason     string               `json:"reason"`
+	Override   *TransportOverride   `json:"override,omitempty"`
+	Candidates []TransportCandidate `json:"candidates"`
+}
+
+// TransportCandidate is one transport the chain considered.
+type TransportCandidate struct {
+	Scheme    string `json:"scheme"`
+	Available bool   `json:"available"`
+	Endpoint  string `json:"endpoint,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// TransportOverride records a transport forced by the user and where it
+// came from: "flag" (--transport), "env" (OP_TRANSPORT) or "config" (the
+// transports section of .holonconfig). A config entry may give a full
+// address, which is dialed directly.
+type TransportOverride struct {
+	Scheme   string `json:"scheme"`
+	Endpoint string `json:"endpoint,omitempty"`
+	Source   string `json:"source"`
+	// Holon is the .holonconfig key a config override was read from.
+	Holon string `json:"holon,omitempty"`
+}
+
+// selectTransportWithReason runs the transport chain for holonName and
+// explains its choice. Priority:
+//  1. Already running (known endpoint) -> tcp:// or unix://, dial existing
 //  2. Supported in-process holon -> mem:// (lazy in-process)
 //  3. Binary available locally -> stdio:// (ephemeral)
-//  4. Network reachable -> tcp://
-func selectTransport(holonName string) (scheme string, err error) {
-	target, targetErr := holons.ResolveTarget(holonName)
-	if targetErr == nil && supportsMemTransport(holonName, target) {
-		return "mem", nil
+//
+// A non-nil override forces that scheme when it is available.
+func selectTransportWithReason(holonName string, override *TransportOverride) (TransportDecision, error) {
+	decision := TransportDecision{
+		Holon:      holonName,
+		Override:   override,
+		Candidates: transportCandidates(holonName),
+	}
+	if override != nil && override.Endpoint != "" {
+		decision.Scheme = override.Scheme
+		decision.Endpoint = override.Endpoint
+		decision.Reason = "forced by " + transportOverrideOrigin(override) + "; dialing " + override.Endpoint
+		return decision, nil
 	}
 
-	binaryPath, err := resolveHolon(holonName)
-	if binaryPath != "" {
-		return "stdio", nil
+	for _, candidate := range decision.Candidates {
+		if override != nil && candidate.Scheme != override.Scheme {
+			continue
+		}
+		if !candidate.Available {
+			if override != nil {
+				return decision, fmt.Errorf("transport %s (from %s) not available: %s", override.Scheme, transportOverrideOrigin(override), candidate.Reason)
+			}
+			continue
+		}
+		decision.Scheme = candidate.Scheme
+		decision.Endpoint = candidate.Endpoint
+		decision.Reason = candidate.Reason
+		if override != nil {
+			decision.Reason = "forced by " + transportOverrideOrigin(override) + "; " + candidate.Reason
+		}
+		return decision, nil
 	}
+	if override != nil {
+		return decision, fmt.Errorf("transport %s (from %s) not available: no running instance registered", override.Scheme, transportOverrideOrigin(override))
+	}
+	return decision, fmt.Errorf("holon not reachable")
+}
 
-	return "", fmt.Errorf("holon not reachable")
+// transportCandidates evaluates the mem, stdio and tcp transports for
+// holonName, in chain order, led by the endpoint of a running instance when
+// one answers. Otherwise the chain never picks tcp on its own: any holon
+// with a binary is reached over stdio first.
+func transportCandidates(holonName string) []TransportCandidate {
+	var candidates []TransportCandidate
+	if running, ok := runningCandidate(holonName); ok {
+		candidates = append(candidates, running)
+	}
+
+	mem := memTransportCandidate(holonName)
+
+	stdio := TransportCandidate{Scheme: "stdio"}
+	tcp := TransportCandidate{Scheme: "tcp"}
+	if binaryPath, err := resolveHolon(holonName); binaryPath != "" {
+		stdio.Available, tcp.Available = true, true
+		stdio.Endpoint, tcp.Endpoint = binaryPath, binaryPath
+		stdio.Reason = "binary found; launched with serve --listen stdio://"
+		tcp.Reason = "binary found; launched on an ephemeral tcp:// port"
+	} else {
+		reason := "no binary found"
+		if err != nil {
+			reason = err.Error()
+		}
+		stdio.Reason, tcp.Reason = reason, reason
+	}
+
+	byScheme := map[string]TransportCandidate{"mem": mem, "stdio": stdio, "tcp": tcp}
+	for _, scheme := range transportChainOrder {
+		candidates = append(candidates, byScheme[scheme])
+	}
+	return candidates
 }
 
-func supportsMemTransport(requested string, target *holons.Target) bool {
-	if target == nil {
-		return false
+// parseTransportOverride validates a --transport or OP_TRANSPORT value.
+func parseTransportOverride(value, source string) (*TransportOverride, error) {
+	scheme := strings.ToLower(strings.TrimSpace(value))
+	switch scheme {
+	case "mem", "stdio", "tcp", "unix":
+		return &TransportOverride{Scheme: scheme, Source: source}, nil
+	default:
+		return nil, fmt.Errorf("invalid transport %q (from %s); use mem, stdio, tcp or unix", value, transportOverrideOrigin(&TransportOverride{Source: source}))
 	}
-	if !isGoTransportTarget(target) {
-		return false
+}
+
+// envTransportOverride reads OP_TRANSPORT; nil when unset.
+func envTransportOverride() (*TransportOverride, error) {
+	value, ok := lookupNonEmptyEnv(envTransport)
+	if !ok {
+		return nil, nil
 	}
+	return parseTransportOverride(value, "env")
+}
 
-	for _, candidate := range memCandidateNames(requested, target) {
-		if hasMemComposer(candidate) {
-			return true
+// parseConfigTransport validates a .holonconfig transports value: a bare
+// scheme, or a tcp:// (also grpc://) or unix:// address to dial.
+func parseConfigTransport(value string) (*TransportOverride, error) {
+	trimmed := strings.TrimSpace(value)
+	scheme, address, ok := strings.Cut(trimmed, "://")
+	if !ok || address == "" {
+		return parseTransportOverride(strings.TrimSuffix(trimmed, "://"), "config")
+	}
+	scheme = strings.ToLower(scheme)
+	if scheme == "grpc" {
+		scheme = "tcp"
+	}
+	if scheme != "tcp" && scheme != "unix" {
+		return nil, fmt.Errorf("invalid transport %q; use a scheme, or a tcp://, grpc:// or unix:// address", value)
+	}
+	endpoint := scheme + "://" + address
+	if _, _, err := listenDialTarget(endpoint); err != nil {
+		return nil, err
+	}
+	return &TransportOverride{Scheme: scheme, Endpoint: endpoint, Source: "config"}, nil
+}
+
+func transportOverrideOrigin(override *TransportOverride) string {
+	switch override.Source {
+	case "env":
+		return envTransport
+	case "config":
+		if override.Holon != "" {
+			return holonConfigFile + " transports." + override.Holon
 		}
+		return holonConfigFile
+	default:
+		return "--transport"
 	}
-	return false
+}
+
+// transportOverrideValue is what an override forces: its address when it
+// has one, its scheme otherwise.
+func transportOverrideValue(override *TransportOverride) string {
+	if override.Endpoint != "" {
+		return override.Endpoint
+	}
+	return override.Scheme
+}
+
+// memTransportCandidate reports whether holonName can be reached in
+// process: it must resolve to a Go holon with a registered composer.
+func memTransportCandidate(holonName string) TransportCandidate {
+	mem := TransportCandidate{Scheme: "mem"}
+	target, err := holons.ResolveTarget(holonName)
+	switch {
+	case err != nil:
+		mem.Reason = "holon not resolved: " + err.Error()
+	case !isGoTransportTarget(target):
+		mem.Reason = "not a Go holon"
+	default:
+		mem.Reason = "no in-process composer registered"
+		for _, candidate := range memCandidateNames(holonName, target) {
+			if hasMemComposer(candidate) {
+				mem.Available = true
+				mem.Endpoint = "mem://" + candidate
+				mem.Reason = "in-process composer registered for " + candidate
+				break
+			}
+		}
+	}
+	return mem
 }
 
 func isGoTransportTarget(target *holons.Target) bool {
@@ -62,3 +236,113 @@ func memCandidateNames(requested string, target *holons.Target) []string {
 	names = append(names, filepath.Base(target.Dir))
 	return names
 }
+
+type transportOverrideKey struct{}
+
+// withTransportOverride forces the transport of calls made under ctx. It
+// takes precedence over OP_TRANSPORT.
+func withTransportOverride(ctx context.Context, override *TransportOverride) context.Context {
+	if override == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, transportOverrideKey{}, override)
+}
+
+// transportDecision runs the transport chain for a call made under ctx,
+// honouring a --transport override on ctx, then OP_TRANSPORT, then the
+// holon's entry in .holonconfig transports.
+func transportDecision(ctx context.Context, holonName string) (TransportDecision, error) {
+	override, _ := ctx.Value(transportOverrideKey{}).(*TransportOverride)
+	if override == nil {
+		var err error
+		if override, err = envTransportOverride(); err != nil {
+			return TransportDecision{Holon: holonName}, err
+		}
+	}
+	if override == nil {
+		override = configTransports[strings.ToLower(strings.TrimSpace(holonName))]
+	}
+	return selectTransportWithReason(holonName, override)
+}
+
+// printTransportDecision writes decision as one line per field followed by
+// the candidate table.
+func printTransportDecision(w io.Writer, decision TransportDecision) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "holon:\t%s\n", decision.Holon)
+	scheme := decision.Scheme
+	if scheme == "" {
+		scheme = "none"
+	}
+	fmt.Fprintf(tw, "transport:\t%s\n", scheme)
+	if decision.Endpoint != "" {
+		fmt.Fprintf(tw, "endpoint:\t%s\n", decision.Endpoint)
+	}
+	if decision.Reason != "" {
+		fmt.Fprintf(tw, "reason:\t%s\n", decision.Reason)
+	}
+	if decision.Override != nil {
+		fmt.Fprintf(tw, "override:\t%s=%s\n", transportOverrideOrigin(decision.Override), transportOverrideValue(decision.Override))
+	}
+	_ = tw.Flush()
+	printTransportCandidates(w, decision.Candidates)
+}
+
+// printTransportCandidates writes the candidate table of a decision.
+func printTransportCandidates(w io.Writer, candidates []TransportCandidate) {
+	fmt.Fprintln(w, "candidates:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, candidate := range candidates {
+		status := "unavailable"
+		if candidate.Available {
+			status = "available"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", candidate.Scheme, status, candidate.Reason)
+	}
+	_ = tw.Flush()
+}
+
+// extractTransportFlag removes --transport from args and returns the
+// override it requests, or nil when absent.
+func extractTransportFlag(args []string) (*TransportOverride, []string, error) {
+	value := ""
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--transport":
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("--transport requires a value")
+			}
+			value = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--transport="):
+			value = strings.TrimPrefix(args[i], "--transport=")
+		default:
+			remaining = append(remaining, args[i])
+		}
+	}
+	if value == "" {
+		return nil, remaining, nil
+	}
+	override, err := parseTransportOverride(value, "flag")
+	if err != nil {
+		return nil, nil, err
+	}
+	return override, remaining, nil
+}
+
+// printHolonDryRun prints the transport decision for `op <holon> --dry-run`
+// without calling the holon.
+func printHolonDryRun(ctx context.Context, format Format, holon string) int {
+	decision, err := transportDecision(ctx, holon)
+	if format == FormatJSON {
+		printJSON(decision)
+	} else {
+		printTransportDecision(os.Stdout, decision)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op: %v\n", err)
+		return 1
+	}
+	return 0
+}
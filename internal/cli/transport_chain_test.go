@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -23,9 +25,9 @@ func TestSelectTransport_UnregisteredGoHolonWithoutBinaryIsNotReachable(t *testi
 		lang:       "go",
 	})
 
-	_, err := selectTransport("sophia")
+	_, err := selectTransportWithReason("sophia", nil)
 	if err == nil {
-		t.Fatal("expected selectTransport to fail")
+		t.Fatal("expected selectTransportWithReason to fail")
 	}
 	if err.Error() != "holon not reachable" {
 		t.Fatalf("error = %q, want %q", err.Error(), "holon not reachable")
@@ -45,12 +47,12 @@ func TestSelectTransport_GoHolonWithoutComposerFallsBackToStdio(t *testing.T) {
 		lang:       "go",
 	})
 
-	scheme, err := selectTransport("beta")
+	decision, err := selectTransportWithReason("beta", nil)
 	if err != nil {
-		t.Fatalf("selectTransport returned error: %v", err)
+		t.Fatalf("selectTransportWithReason returned error: %v", err)
 	}
-	if scheme != "stdio" {
-		t.Fatalf("scheme = %q, want %q", scheme, "stdio")
+	if decision.Scheme != "stdio" {
+		t.Fatalf("scheme = %q, want %q", decision.Scheme, "stdio")
 	}
 }
 
@@ -58,7 +60,7 @@ func TestSelectTransport_NotReachable(t *testing.T) {
 	root := t.TempDir()
 	chdirForTest(t, root)
 
-	_, err := selectTransport("missing")
+	_, err := selectTransportWithReason("missing", nil)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -158,3 +160,128 @@ func chdirForTest(t *testing.T, dir string) {
 		t.Fatal(err)
 	}
 }
+
+func TestCallHolonSuggestsClosestHolonName(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	t.Setenv("OPBIN", filepath.Join(root, "opbin"))
+
+	seedTransportHolon(t, root, transportHolonSeed{
+		dirName:    "beta",
+		binaryName: "beta",
+		givenName:  "beta",
+		familyName: "Holon",
+		aliases:    []string{"beta"},
+		lang:       "go",
+	})
+
+	_, err := callHolon(context.Background(), "bta", "ListIdentities", "{}")
+	if err == nil {
+		t.Fatal("expected callHolon to fail for an unknown holon")
+	}
+	if want := `holon "bta" not found; did you mean "beta"?`; err.Error() != want {
+		t.Fatalf("error = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestSelectTransportWithReasonExplainsStdioChoice(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedTransportHolon(t, root, transportHolonSeed{
+		dirName:    "beta",
+		binaryName: "beta",
+		givenName:  "beta",
+		familyName: "Holon",
+		aliases:    []string{"beta"},
+		lang:       "go",
+	})
+
+	decision, err := selectTransportWithReason("beta", nil)
+	if err != nil {
+		t.Fatalf("selectTransportWithReason returned error: %v", err)
+	}
+	if decision.Scheme != "stdio" || !strings.HasSuffix(decision.Endpoint, filepath.Join(".op", "build", "bin", "beta")) {
+		t.Fatalf("decision = %+v", decision)
+	}
+	if decision.Override != nil {
+		t.Fatalf("override = %+v, want nil", decision.Override)
+	}
+	var schemes []string
+	for _, candidate := range decision.Candidates {
+		schemes = append(schemes, candidate.Scheme)
+	}
+	if got := strings.Join(schemes, ","); got != "mem,stdio,tcp" {
+		t.Fatalf("candidates = %s", got)
+	}
+	if mem := decision.Candidates[0]; mem.Available || mem.Reason != "no in-process composer registered" {
+		t.Fatalf("mem candidate = %+v", mem)
+	}
+}
+
+func TestSelectTransportHonoursOverride(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedTransportHolon(t, root, transportHolonSeed{
+		dirName:    "beta",
+		binaryName: "beta",
+		givenName:  "beta",
+		familyName: "Holon",
+		aliases:    []string{"beta"},
+		lang:       "go",
+	})
+
+	t.Setenv(envTransport, "tcp")
+	decision, err := transportDecision(context.Background(), "beta")
+	if err != nil || decision.Scheme != "tcp" || decision.Override == nil || decision.Override.Source != "env" {
+		t.Fatalf("transportDecision = %+v, %v; want tcp from %s", decision, err, envTransport)
+	}
+
+	decision, err = transportDecision(withTransportOverride(context.Background(), &TransportOverride{Scheme: "mem", Source: "flag"}), "beta")
+	if err == nil {
+		t.Fatalf("forcing mem should fail, got %+v", decision)
+	}
+	if want := "transport mem (from --transport) not available: no in-process composer registered"; err.Error() != want {
+		t.Fatalf("error = %q, want %q", err.Error(), want)
+	}
+	if decision.Override == nil || decision.Override.Source != "flag" {
+		t.Fatalf("flag override should win over %s: %+v", envTransport, decision.Override)
+	}
+
+	t.Setenv(envTransport, "carrier-pigeon")
+	if _, err := transportDecision(context.Background(), "beta"); err == nil || !strings.Contains(err.Error(), "from OP_TRANSPORT") {
+		t.Fatalf("invalid %s error = %v", envTransport, err)
+	}
+}
+
+func TestHolonDryRunPrintsTransportDecisionJSON(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedTransportHolon(t, root, transportHolonSeed{
+		dirName:    "beta",
+		binaryName: "beta",
+		givenName:  "beta",
+		familyName: "Holon",
+		aliases:    []string{"beta"},
+		lang:       "go",
+	})
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"--format", "json", "beta", "ListIdentities", "--dry-run", "--transport", "tcp"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("dry run returned %d, want 0", code)
+		}
+	})
+
+	var decision TransportDecision
+	if err := json.Unmarshal([]byte(stdout), &decision); err != nil {
+		t.Fatalf("stdout is not a transport decision: %q: %v", stdout, err)
+	}
+	if decision.Holon != "beta" || decision.Scheme != "tcp" || len(decision.Candidates) != 3 {
+		t.Fatalf("decision = %+v", decision)
+	}
+	if decision.Override == nil || *decision.Override != (TransportOverride{Scheme: "tcp", Source: "flag"}) {
+		t.Fatalf("override = %+v", decision.Override)
+	}
+	if !strings.HasPrefix(decision.Reason, "forced by --transport") {
+		t.Fatalf("reason = %q", decision.Reason)
+	}
+}
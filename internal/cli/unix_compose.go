@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+
+	"google.golang.org/grpc"
+)
+
+// callViaUnix dials the holon serving on the Unix socket at path and calls
+// method, through compiled-in descriptors or reflection, as callViaMem
+// does for an in-process composer.
+func callViaUnix(ctx context.Context, path, method, inputJSON string) (*grpcclient.CallResult, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("unix socket %s does not exist; is the holon serving on it?", path)
+	} else if err != nil {
+		return nil, fmt.Errorf("unix socket %s: %w", path, err)
+	}
+
+	conn, err := grpc.NewClient("unix://"+path, grpcclient.DialOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("dial unix socket %s: %w", path, err)
+	}
+	client := grpcclient.NewConnClient("unix://"+path, conn)
+	defer client.Close()
+	return client.Call(ctx, method, inputJSON)
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/organic-programming/grace-op/internal/server"
+)
+
+func TestCallViaUnixKeepsTheServiceQualifier(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	seedTransportHolon(t, root, transportHolonSeed{dirName: "alpha", givenName: "Alpha", familyName: "Holon", lang: "go"})
+
+	// Unix socket paths are length-limited, so keep this one short.
+	dir, err := os.MkdirTemp("", "opu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "op.sock")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.ListenAndServeContext(ctx, "unix://"+socket, server.ServeOptions{Reflect: true})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	deadline := time.Now().Add(5 * time.Second)
+	for !daemonRunning(socket) {
+		if time.Now().After(deadline) {
+			t.Fatal("socket never came up")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	result, err := callViaUnix(context.Background(), socket, "op.v1.OPService/ListIdentities", "{}")
+	if err != nil {
+		t.Fatalf("qualified call: %v", err)
+	}
+	if !strings.Contains(result.Output, "Alpha") {
+		t.Fatalf("output = %s, want the seeded holon", result.Output)
+	}
+
+	if _, err := callViaUnix(context.Background(), socket, "other.v1.OPService/ListIdentities", "{}"); err == nil {
+		t.Fatal("a method qualified with another service should not reach op.v1.OPService")
+	}
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/organic-programming/grace-op/internal/holons"
+)
+
+// whichReport is what op which prints: where the holon's binary was looked
+// for and found, the holon it belongs to, and the transport a call would
+// use.
+type whichReport struct {
+	Holon        string               `json:"holon"`
+	Binary       string               `json:"binary,omitempty"`
+	Dir          string               `json:"dir,omitempty"`
+	IdentityPath string               `json:"identity_path,omitempty"`
+	Lang         string               `json:"lang,omitempty"`
+	Checked      []holons.BinaryProbe `json:"checked"`
+	BinaryError  string               `json:"binary_error,omitempty"`
+	Transport    TransportDecision    `json:"transport"`
+}
+
+// cmdWhich runs `op which <holon> [--transport <scheme>]`. It explains how
+// op <holon> would be dispatched without launching or calling the holon;
+// only an instance already running is probed.
+func cmdWhich(format Format, args []string) int {
+	override, args, err := extractTransportFlag(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op which: %v\n", err)
+		return 1
+	}
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: op which <holon> [--transport <mem|stdio|tcp|unix>]")
+		return 1
+	}
+	holon := args[0]
+
+	report := whichReport{Holon: holon}
+	resolution, binaryErr := holons.TraceBinary(holon)
+	report.Binary = resolution.Path
+	report.Checked = resolution.Probes
+	if binaryErr != nil {
+		report.BinaryError = binaryErr.Error()
+	}
+	target := resolution.Target
+	if target == nil {
+		// A binary found on $OPBIN or $PATH by name still belongs to a
+		// holon when the name resolves to one.
+		target, _ = holons.ResolveTarget(holon)
+	}
+	if target != nil {
+		report.Dir = target.Dir
+		report.IdentityPath = target.IdentityPath
+		if target.Identity != nil {
+			report.Lang = target.Identity.Lang
+		}
+	}
+
+	decision, decisionErr := transportDecision(withTransportOverride(context.Background(), override), holon)
+	report.Transport = decision
+
+	if format == FormatJSON {
+		printJSON(report)
+	} else {
+		printWhichReport(os.Stdout, report)
+	}
+	if decisionErr != nil {
+		fmt.Fprintf(os.Stderr, "op which: %v\n", decisionErr)
+		return 1
+	}
+	return 0
+}
+
+func printWhichReport(w io.Writer, report whichReport) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "holon:\t%s\n", report.Holon)
+	fmt.Fprintf(tw, "binary:\t%s\n", defaultDash(report.Binary))
+	if report.BinaryError != "" {
+		fmt.Fprintf(tw, "binary error:\t%s\n", report.BinaryError)
+	}
+	fmt.Fprintf(tw, "lang:\t%s\n", defaultDash(report.Lang))
+	if report.Dir != "" {
+		fmt.Fprintf(tw, "dir:\t%s\n", report.Dir)
+	}
+	if report.IdentityPath != "" {
+		fmt.Fprintf(tw, "identity:\t%s\n", report.IdentityPath)
+	}
+	decision := report.Transport
+	scheme := decision.Scheme
+	if scheme == "" {
+		scheme = "none"
+	}
+	fmt.Fprintf(tw, "transport:\t%s\n", scheme)
+	if decision.Endpoint != "" {
+		fmt.Fprintf(tw, "endpoint:\t%s\n", decision.Endpoint)
+	}
+	if decision.Reason != "" {
+		fmt.Fprintf(tw, "reason:\t%s\n", decision.Reason)
+	}
+	if decision.Override != nil {
+		fmt.Fprintf(tw, "override:\t%s=%s\n", transportOverrideOrigin(decision.Override), transportOverrideValue(decision.Override))
+	}
+	_ = tw.Flush()
+
+	fmt.Fprintln(w, "checked:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, probe := range report.Checked {
+		status := "missing"
+		if probe.Found {
+			status = "found"
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", probe.Source, status, probe.Path)
+	}
+	_ = tw.Flush()
+	printTransportCandidates(w, decision.Candidates)
+}
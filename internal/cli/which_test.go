@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/organic-programming/grace-op/internal/holons"
+)
+
+func TestWhichExplainsDispatchWithoutLaunching(t *testing.T) {
+	root := t.TempDir()
+	chdirForTest(t, root)
+	t.Setenv("OPPATH", t.TempDir())
+	seedTransportHolon(t, root, transportHolonSeed{
+		dirName:    "beta",
+		binaryName: "beta",
+		givenName:  "beta",
+		familyName: "Holon",
+		aliases:    []string{"beta"},
+		lang:       "go",
+	})
+
+	stdout := captureStdout(t, func() {
+		if code := Run([]string{"--format", "json", "which", "beta", "--transport", "tcp"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("which returned %d, want 0", code)
+		}
+	})
+	var report whichReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("stdout is not a which report: %q: %v", stdout, err)
+	}
+	wantBinary := filepath.Join(".op", "build", "bin", "beta")
+	if !strings.HasSuffix(report.Binary, wantBinary) || report.Lang != "go" || !strings.HasSuffix(report.Dir, "beta") {
+		t.Fatalf("report = %+v", report)
+	}
+	if len(report.Checked) == 0 || report.Checked[0] != (holons.BinaryProbe{Source: holons.ProbeBuild, Path: report.Binary, Found: true}) {
+		t.Fatalf("checked = %+v, want the build output first", report.Checked)
+	}
+	if report.Transport.Scheme != "tcp" || report.Transport.Override == nil || report.Transport.Override.Source != "flag" {
+		t.Fatalf("transport = %+v, want tcp forced by --transport", report.Transport)
+	}
+
+	stdout = captureStdout(t, func() {
+		if code := Run([]string{"which", "beta"}, "0.1.0-test"); code != 0 {
+			t.Fatalf("which returned %d, want 0", code)
+		}
+	})
+	for _, want := range []string{"transport:  stdio", "lang:", "checked:", "build  found", "candidates:"} {
+		if !strings.Contains(stdout, want) {
+			t.Fatalf("stdout = %q, want %q", stdout, want)
+		}
+	}
+
+	var stderr string
+	stdout = captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			if code := Run([]string{"--format", "json", "which", "nowhere"}, "0.1.0-test"); code != 1 {
+				t.Fatalf("which of an unknown holon returned %d, want 1", code)
+			}
+		})
+	})
+	report = whichReport{}
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil || report.Binary != "" || report.BinaryError == "" {
+		t.Fatalf("report = %+v (%v), want the failed lookup explained", report, err)
+	}
+	if last := report.Checked[len(report.Checked)-1]; last.Source != holons.ProbePath || last.Found {
+		t.Fatalf("checked = %+v, want the $PATH lookup recorded", report.Checked)
+	}
+	if !strings.Contains(stderr, "not reachable") {
+		t.Fatalf("stderr = %q", stderr)
+	}
+}
@@ -11,10 +11,12 @@ import (
 	"github.com/organic-programming/grace-op/internal/suggest"
 	"github.com/organic-programming/grace-op/internal/who"
 
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
-const newUsage = "usage: op new [--json <payload>] | op new --list | op new --template <name> <holon-name> [--set key=value]"
+const newUsage = "usage: op new [--json <payload>] | op new --batch <file.csv|file.json> [--fail-fast] [--dry-run] | op new --list | op new --template <name> <holon-name> [--set key=value]"
 
 func cmdWho(format Format, globalQuiet bool, verb string, args []string) int {
 	switch verb {
@@ -22,8 +24,18 @@ func cmdWho(format Format, globalQuiet bool, verb string, args []string) int {
 		return cmdWhoList(format, args)
 	case "show":
 		return cmdWhoShow(format, args)
+	case "delete":
+		return cmdWhoDelete(format, args)
 	case "new":
 		return cmdWhoNew(format, globalQuiet, args)
+	case "plan":
+		return cmdWhoPlan(format, args)
+	case "apply":
+		return cmdWhoApply(format, args)
+	case "pin":
+		return cmdWhoPin(format, args)
+	case "releases":
+		return cmdWhoReleases(format, args)
 	default:
 		fmt.Fprintf(os.Stderr, "op %s: unsupported identity verb\n", verb)
 		return 1
@@ -31,8 +43,13 @@ func cmdWho(format Format, globalQuiet bool, verb string, args []string) int {
 }
 
 func cmdWhoList(format Format, args []string) int {
+	mask, args, err := extractMaskFlag(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op list: %v\n", err)
+		return 1
+	}
 	if len(args) > 1 {
-		fmt.Fprintln(os.Stderr, "usage: op list [root]")
+		fmt.Fprintln(os.Stderr, "usage: op list [root] [--mask <fields>]")
 		return 1
 	}
 
@@ -41,7 +58,15 @@ func cmdWhoList(format Format, args []string) int {
 		root = args[0]
 	}
 
+	if resp, ok := daemonListIdentities(root, mask); ok {
+		printFormattedResponse(format, resp)
+		return 0
+	}
+
 	resp, err := who.List(root)
+	if err == nil {
+		err = who.MaskEntries(resp.GetEntries(), mask)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "op list: %v\n", err)
 		return 1
@@ -52,12 +77,20 @@ func cmdWhoList(format Format, args []string) int {
 }
 
 func cmdWhoShow(format Format, args []string) int {
+	mask, args, err := extractMaskFlag(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op show: %v\n", err)
+		return 1
+	}
 	if len(args) != 1 {
-		fmt.Fprintln(os.Stderr, "usage: op show <uuid-or-prefix>")
+		fmt.Fprintln(os.Stderr, "usage: op show <uuid-or-prefix> [--mask <fields>]")
 		return 1
 	}
 
 	resp, err := who.Show(args[0])
+	if err == nil {
+		err = who.MaskIdentity(resp.GetIdentity(), mask)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "op show: %v\n", err)
 		return 1
@@ -67,11 +100,55 @@ func cmdWhoShow(format Format, args []string) int {
 	return 0
 }
 
+func cmdWhoDelete(format Format, args []string) int {
+	req, err := parseDeleteArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op delete: %v\n", err)
+		fmt.Fprintln(os.Stderr, "usage: op delete <uuid> [--purge] [--force]")
+		return 1
+	}
+
+	resp, err := who.Delete(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op delete: %v\n", err)
+		return 1
+	}
+
+	printFormattedResponse(format, resp)
+	return 0
+}
+
+// parseDeleteArgs reads `<uuid> [--purge] [--force]`.
+func parseDeleteArgs(args []string) (*opv1.DeleteIdentityRequest, error) {
+	req := &opv1.DeleteIdentityRequest{}
+	for _, arg := range args {
+		switch {
+		case arg == "--purge":
+			req.Purge = true
+		case arg == "--force":
+			req.Force = true
+		case strings.HasPrefix(arg, "-"):
+			return nil, fmt.Errorf("unknown flag %q", arg)
+		case req.Uuid != "":
+			return nil, fmt.Errorf("unexpected argument %q", arg)
+		default:
+			req.Uuid = arg
+		}
+	}
+	if req.Uuid == "" {
+		return nil, fmt.Errorf("uuid is required")
+	}
+	return req, nil
+}
+
 func cmdWhoNew(format Format, globalQuiet bool, args []string) int {
 	ui, args, _ := extractQuietFlag(args)
 	quiet := globalQuiet || ui.Quiet
 	printer := commandProgress(format, quiet)
 
+	if usesBatchMode(args) {
+		return cmdWhoNewBatch(format, quiet, args)
+	}
 	if usesTemplateMode(args) {
 		return cmdTemplateNew(format, quiet, args)
 	}
@@ -263,6 +340,33 @@ func parseTemplateArgs(args []string) (bool, string, string, map[string]string,
 	return false, templateName, positional[0], overrides, nil
 }
 
+// extractMaskFlag removes --mask from args and returns the field mask it
+// names, or nil when it is absent.
+func extractMaskFlag(args []string) (*fieldmaskpb.FieldMask, []string, error) {
+	var mask *fieldmaskpb.FieldMask
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		if name != "--mask" {
+			remaining = append(remaining, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("--mask requires a comma-separated field list")
+			}
+			value = args[i+1]
+			i++
+		}
+		parsed, err := who.ParseIdentityMask(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("--mask: %w", err)
+		}
+		mask = parsed
+	}
+	return mask, remaining, nil
+}
+
 func parseSetOverride(value string) (string, string, error) {
 	parts := strings.SplitN(value, "=", 2)
 	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
@@ -275,6 +379,26 @@ func printFormattedResponse(format Format, resp proto.Message) {
 	if resp == nil {
 		return
 	}
+	if isProtobin(format) {
+		wire, err := proto.Marshal(resp)
+		if err == nil {
+			err = writeProtobin(os.Stdout, format, wire)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "op: write output: %v\n", err)
+		}
+		return
+	}
+	if format == FormatJSONStream {
+		payload, err := protojson.Marshal(resp)
+		if err == nil {
+			err = writeJSONArray(os.Stdout, payload)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "op: write output: %v\n", err)
+		}
+		return
+	}
 	out := strings.TrimSpace(FormatResponse(format, resp))
 	if out != "" {
 		fmt.Println(out)
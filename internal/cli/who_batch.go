@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/organic-programming/grace-op/internal/who"
+)
+
+type batchNewOptions struct {
+	Path     string
+	FailFast bool
+	DryRun   bool
+}
+
+type batchNewRowResult struct {
+	Row      int    `json:"row"`
+	UUID     string `json:"uuid,omitempty"`
+	Name     string `json:"name,omitempty"`
+	FilePath string `json:"file_path,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type batchNewReport struct {
+	DryRun    bool                `json:"dry_run,omitempty"`
+	Rows      []batchNewRowResult `json:"rows"`
+	Created   int                 `json:"created"`
+	Valid     int                 `json:"valid,omitempty"`
+	Failed    int                 `json:"failed"`
+	Remaining int                 `json:"remaining,omitempty"`
+}
+
+// usesBatchMode reports whether `op new` was asked to create identities from
+// a batch file.
+func usesBatchMode(args []string) bool {
+	for _, arg := range args {
+		if arg == "--batch" || strings.HasPrefix(arg, "--batch=") {
+			return true
+		}
+	}
+	return false
+}
+
+// cmdWhoNewBatch runs `op new --batch <file>`: one CreateIdentity per row,
+// followed by a per-row summary. With --dry-run every row is validated and
+// nothing is written.
+func cmdWhoNewBatch(format Format, quiet bool, args []string) int {
+	opts, err := parseBatchNewArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op new: %v\n", err)
+		return 1
+	}
+
+	rows, err := who.ReadBatchFile(opts.Path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op new: %v\n", err)
+		return 1
+	}
+	if len(rows) == 0 {
+		fmt.Fprintf(os.Stderr, "op new: %s has no rows\n", opts.Path)
+		return 1
+	}
+
+	printer := commandProgress(format, quiet)
+	var outcomes []who.BatchOutcome
+	if opts.DryRun {
+		printer.Step(fmt.Sprintf("validating %d rows...", len(rows)))
+		outcomes = who.ValidateBatch(rows)
+	} else {
+		printer.Step(fmt.Sprintf("creating %d identities...", len(rows)))
+		outcomes = who.CreateBatch(rows, opts.FailFast)
+	}
+
+	report := batchNewReport{DryRun: opts.DryRun, Rows: make([]batchNewRowResult, 0, len(outcomes))}
+	for _, outcome := range outcomes {
+		result := batchNewRowResult{Row: outcome.Row}
+		if outcome.Err != nil {
+			result.Error = outcome.Err.Error()
+			report.Failed++
+		} else {
+			id := outcome.Response.GetIdentity()
+			result.UUID = id.GetUuid()
+			result.Name = strings.TrimSpace(id.GetGivenName() + " " + id.GetFamilyName())
+			result.FilePath = outcome.Response.GetFilePath()
+			if opts.DryRun {
+				report.Valid++
+			} else {
+				report.Created++
+			}
+		}
+		report.Rows = append(report.Rows, result)
+	}
+	report.Remaining = len(rows) - len(outcomes)
+
+	var doneErr error
+	if opts.DryRun {
+		if report.Failed > 0 {
+			doneErr = fmt.Errorf("%d of %d rows invalid", report.Failed, len(rows))
+		}
+		printer.Done(fmt.Sprintf("validated %d rows", len(rows)), doneErr)
+	} else {
+		if report.Failed > 0 {
+			doneErr = fmt.Errorf("%d of %d rows failed", report.Failed, len(rows))
+		}
+		printer.Done(fmt.Sprintf("created %d identities", report.Created), doneErr)
+	}
+
+	if format == FormatJSON {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "op new: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(out))
+	} else {
+		printBatchNewText(report)
+	}
+
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func printBatchNewText(report batchNewReport) {
+	for _, row := range report.Rows {
+		if row.Error != "" {
+			fmt.Printf("row %d: error: %s\n", row.Row, row.Error)
+			continue
+		}
+		if report.DryRun {
+			fmt.Printf("row %d: would create %s -> %s\n", row.Row, row.Name, row.FilePath)
+			continue
+		}
+		fmt.Printf("row %d: created %s (%s) -> %s\n", row.Row, row.Name, row.UUID, row.FilePath)
+	}
+	if report.DryRun {
+		fmt.Printf("%d valid, %d invalid (dry run, nothing written)\n", report.Valid, report.Failed)
+		return
+	}
+	summary := fmt.Sprintf("%d created, %d failed", report.Created, report.Failed)
+	if report.Remaining > 0 {
+		summary += fmt.Sprintf(", %d skipped after --fail-fast", report.Remaining)
+	}
+	fmt.Println(summary)
+}
+
+func parseBatchNewArgs(args []string) (batchNewOptions, error) {
+	var opts batchNewOptions
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--batch":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("--batch requires a file")
+			}
+			opts.Path = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--batch="):
+			opts.Path = strings.TrimPrefix(args[i], "--batch=")
+		case args[i] == "--fail-fast":
+			opts.FailFast = true
+		case args[i] == "--dry-run":
+			opts.DryRun = true
+		default:
+			return opts, fmt.Errorf("unexpected argument %q with --batch", args[i])
+		}
+	}
+	if strings.TrimSpace(opts.Path) == "" {
+		return opts, fmt.Errorf("--batch requires a file")
+	}
+	return opts, nil
+}
@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/organic-programming/grace-op/internal/identity"
+	"github.com/organic-programming/grace-op/internal/who"
+)
+
+const pinUsage = "usage: op pin <uuid-or-prefix> [--from-binary <path>] [--version <v>] [--tag <tag>] [--commit <sha>] [--os <os>] [--arch <arch>]"
+
+type pinReport struct {
+	*who.PinResult
+	Binary   string              `json:"binary,omitempty"`
+	Source   string              `json:"source,omitempty"`
+	Detected identity.VersionPin `json:"detected,omitzero"`
+}
+
+type pinArgs struct {
+	target string
+	binary string
+	pin    identity.VersionPin
+}
+
+// cmdWhoPin runs `op pin <uuid>`: it records a build in the holon's
+// holon.yaml. With --from-binary the build is detected from the binary
+// itself; explicit field flags override what was detected.
+func cmdWhoPin(format Format, args []string) int {
+	parsed, err := parsePinArgs(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op pin: %v\n%s\n", err, pinUsage)
+		return 1
+	}
+
+	report := pinReport{Binary: parsed.binary}
+	pin := parsed.pin
+	if parsed.binary != "" {
+		detected, source, err := who.DetectBinaryVersion(parsed.binary)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "op pin: %v\n", err)
+			return 1
+		}
+		report.Detected, report.Source = detected, source
+		pin = mergePin(detected, parsed.pin)
+	}
+
+	result, err := who.PinVersion(parsed.target, pin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op pin: %v\n", err)
+		return 1
+	}
+	report.PinResult = result
+
+	if format == FormatJSON {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "op pin: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(out))
+		return 0
+	}
+	if report.Source != "" {
+		fmt.Printf("Detected %s from %s (%s)\n", formatPin(report.Detected), report.Binary, report.Source)
+	}
+	fmt.Printf("Pinned %s (%s) to %s in %s\n", result.Slug, shortUUID(result.UUID), formatPin(result.Pin), result.Path)
+	return 0
+}
+
+func parsePinArgs(args []string) (pinArgs, error) {
+	var parsed pinArgs
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		var field *string
+		switch name {
+		case "--from-binary":
+			field = &parsed.binary
+		case "--version":
+			field = &parsed.pin.Version
+		case "--tag":
+			field = &parsed.pin.Tag
+		case "--commit":
+			field = &parsed.pin.Commit
+		case "--os":
+			field = &parsed.pin.OS
+		case "--arch":
+			field = &parsed.pin.Arch
+		default:
+			if strings.HasPrefix(args[i], "-") {
+				return pinArgs{}, fmt.Errorf("unknown flag %q", args[i])
+			}
+			positional = append(positional, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return pinArgs{}, fmt.Errorf("%s requires a value", name)
+			}
+			value = args[i+1]
+			i++
+		}
+		if strings.TrimSpace(value) == "" {
+			return pinArgs{}, fmt.Errorf("%s requires a value", name)
+		}
+		*field = strings.TrimSpace(value)
+	}
+	if len(positional) != 1 {
+		return pinArgs{}, fmt.Errorf("expected one uuid or prefix")
+	}
+	parsed.target = positional[0]
+	if parsed.binary == "" && parsed.pin.IsZero() {
+		return pinArgs{}, fmt.Errorf("pass --from-binary or at least one of --version, --tag, --commit, --os, --arch")
+	}
+	return parsed, nil
+}
+
+// mergePin returns detected with every field set in explicit replacing it.
+func mergePin(detected, explicit identity.VersionPin) identity.VersionPin {
+	merged := detected
+	for _, f := range []struct{ dst, src *string }{
+		{&merged.Version, &explicit.Version},
+		{&merged.Tag, &explicit.Tag},
+		{&merged.Commit, &explicit.Commit},
+		{&merged.OS, &explicit.OS},
+		{&merged.Arch, &explicit.Arch},
+	} {
+		if *f.src != "" {
+			*f.dst = *f.src
+		}
+	}
+	return merged
+}
+
+func formatPin(pin identity.VersionPin) string {
+	var parts []string
+	if pin.Version != "" {
+		parts = append(parts, "version "+pin.Version)
+	}
+	if pin.Tag != "" {
+		parts = append(parts, "tag "+pin.Tag)
+	}
+	if pin.Commit != "" {
+		parts = append(parts, "commit "+pin.Commit)
+	}
+	if pin.OS != "" || pin.Arch != "" {
+		parts = append(parts, strings.Trim(pin.OS+"/"+pin.Arch, "/"))
+	}
+	if len(parts) == 0 {
+		return "nothing"
+	}
+	return strings.Join(parts, ", ")
+}
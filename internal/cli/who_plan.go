@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/organic-programming/grace-op/internal/who"
+)
+
+const (
+	planUsage  = "usage: op plan <desired-dir> [--root <dir>]"
+	applyUsage = "usage: op apply <desired-dir> [--root <dir>] [--prune]"
+)
+
+type applyReport struct {
+	Plan    *who.IdentityPlan `json:"plan"`
+	Applied []who.PlanChange  `json:"applied"`
+	Skipped []who.PlanChange  `json:"skipped,omitempty"`
+}
+
+// cmdWhoPlan runs `op plan <dir>`: it diffs a directory of desired holon.yaml
+// files against the identities under the root and prints what apply would do.
+func cmdWhoPlan(format Format, args []string) int {
+	desiredDir, root, _, err := parsePlanArgs(args, false)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, planUsage)
+		return 1
+	}
+
+	plan, err := who.Plan(desiredDir, root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op plan: %v\n", err)
+		return 1
+	}
+
+	if format == FormatJSON {
+		out, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "op plan: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(out))
+		return 0
+	}
+
+	if len(plan.Changes) == 0 {
+		fmt.Println("No changes. Identities match the desired state.")
+		return 0
+	}
+	for _, change := range plan.Changes {
+		fmt.Println(formatPlanChange(change, ""))
+	}
+	creates, updates, deletes := plan.Counts()
+	fmt.Printf("Plan: %d to create, %d to update, %d to delete.\n", creates, updates, deletes)
+	return 0
+}
+
+// cmdWhoApply runs `op apply <dir>`: it computes the same plan as `op plan`
+// and executes it. Deletes only happen with --prune.
+func cmdWhoApply(format Format, args []string) int {
+	desiredDir, root, prune, err := parsePlanArgs(args, true)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, applyUsage)
+		return 1
+	}
+
+	plan, err := who.Plan(desiredDir, root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op apply: %v\n", err)
+		return 1
+	}
+
+	applied, applyErr := who.ApplyPlan(plan, prune)
+	report := applyReport{Plan: plan, Applied: applied}
+	if !prune {
+		for _, change := range plan.Changes {
+			if change.Action == who.PlanDelete {
+				report.Skipped = append(report.Skipped, change)
+			}
+		}
+	}
+
+	if format == FormatJSON {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "op apply: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(out))
+	} else {
+		printApplyText(report)
+	}
+
+	if applyErr != nil {
+		fmt.Fprintf(os.Stderr, "op apply: %v\n", applyErr)
+		return 1
+	}
+	return 0
+}
+
+func printApplyText(report applyReport) {
+	if len(report.Plan.Changes) == 0 {
+		fmt.Println("No changes. Identities match the desired state.")
+		return
+	}
+
+	created, updated, deleted := 0, 0, 0
+	for _, change := range report.Applied {
+		fmt.Println(formatPlanChange(change, "d"))
+		switch change.Action {
+		case who.PlanCreate:
+			created++
+		case who.PlanUpdate:
+			updated++
+		case who.PlanDelete:
+			deleted++
+		}
+	}
+	for _, change := range report.Skipped {
+		fmt.Printf("%s (skipped, pass --prune to delete)\n", formatPlanChange(change, ""))
+	}
+	fmt.Printf("Applied: %d created, %d updated, %d deleted.\n", created, updated, deleted)
+}
+
+// formatPlanChange renders one change as a single line. suffix turns the
+// action verb into its past tense ("create" + "d").
+func formatPlanChange(change who.PlanChange, suffix string) string {
+	marker := map[who.PlanAction]string{who.PlanCreate: "+", who.PlanUpdate: "~", who.PlanDelete: "-"}[change.Action]
+	line := fmt.Sprintf("%s %s%s %s (%s) %s", marker, change.Action, suffix, change.Name, shortUUID(change.UUID), change.Path)
+	if len(change.Fields) > 0 {
+		line += ": " + strings.Join(change.Fields, ", ")
+	}
+	return line
+}
+
+func parsePlanArgs(args []string, allowPrune bool) (string, string, bool, error) {
+	root := "."
+	prune := false
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--root":
+			if i+1 >= len(args) {
+				return "", "", false, fmt.Errorf("--root requires a value")
+			}
+			root = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--root="):
+			root = strings.TrimPrefix(args[i], "--root=")
+		case args[i] == "--prune" && allowPrune:
+			prune = true
+		case strings.HasPrefix(args[i], "--"):
+			return "", "", false, fmt.Errorf("unknown flag %q", args[i])
+		default:
+			positional = append(positional, args[i])
+		}
+	}
+	if len(positional) != 1 {
+		return "", "", false, fmt.Errorf("requires <desired-dir>")
+	}
+	return positional[0], root, prune, nil
+}
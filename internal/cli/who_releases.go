@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/organic-programming/grace-op/internal/who"
+)
+
+const releasesUsage = "usage: op releases [root] [--unpinned-only]"
+
+// releasesCommitWidth is how much of a commit hash the text table shows.
+const releasesCommitWidth = 12
+
+type releasesReport struct {
+	Releases []who.Release `json:"releases"`
+	Unpinned int           `json:"unpinned"`
+}
+
+// cmdWhoReleases runs `op releases`: a release audit listing the build each
+// holon is pinned to, with unpinned holons called out.
+func cmdWhoReleases(format Format, args []string) int {
+	root := "."
+	unpinnedOnly := false
+	var positional []string
+	for _, arg := range args {
+		switch {
+		case arg == "--unpinned-only":
+			unpinnedOnly = true
+		case strings.HasPrefix(arg, "-"):
+			fmt.Fprintf(os.Stderr, "op releases: unknown flag %q\n%s\n", arg, releasesUsage)
+			return 1
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) > 1 {
+		fmt.Fprintln(os.Stderr, releasesUsage)
+		return 1
+	}
+	if len(positional) == 1 {
+		root = positional[0]
+	}
+
+	releases, err := who.Releases(root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "op releases: %v\n", err)
+		return 1
+	}
+
+	report := releasesReport{Releases: make([]who.Release, 0, len(releases))}
+	for _, release := range releases {
+		if !release.Pinned {
+			report.Unpinned++
+		} else if unpinnedOnly {
+			continue
+		}
+		report.Releases = append(report.Releases, release)
+	}
+
+	if format == FormatJSON {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "op releases: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(out))
+		return 0
+	}
+
+	if len(report.Releases) == 0 {
+		if unpinnedOnly {
+			fmt.Println("Every holon has a pinned version.")
+		} else {
+			fmt.Println("No holons found.")
+		}
+		return 0
+	}
+	var b strings.Builder
+	releasesTable(report.Releases, format).render(&b, tableWidth(format), "")
+	fmt.Print(b.String())
+	if report.Unpinned > 0 {
+		fmt.Printf("%d of %d holons have no pinned version.\n", report.Unpinned, len(releases))
+	}
+	return 0
+}
+
+func releasesTable(releases []who.Release, format Format) *table {
+	t := newTable(
+		tableColumn{Header: "SLUG"},
+		tableColumn{Header: "VERSION"},
+		tableColumn{Header: "TAG", Drop: 3},
+		tableColumn{Header: "COMMIT", Drop: 2},
+		tableColumn{Header: "OS/ARCH", Drop: 4},
+		tableColumn{Header: "PATH", Drop: 1, Truncate: true},
+	)
+	for _, release := range releases {
+		version, tag, commit, platform := "UNPINNED", "-", "-", "-"
+		if pin := release.Pin; pin != nil {
+			if release.Pinned {
+				version = pin.Version
+			}
+			tag = defaultDash(pin.Tag)
+			commit = pin.Commit
+			if format != FormatWide && len(commit) > releasesCommitWidth {
+				commit = commit[:releasesCommitWidth]
+			}
+			commit = defaultDash(commit)
+			if pin.OS != "" || pin.Arch != "" {
+				platform = strings.Trim(pin.OS+"/"+pin.Arch, "/")
+			}
+		}
+		t.add(defaultDash(release.Slug), version, tag, commit, platform, defaultDash(release.RelativePath))
+	}
+	return t
+}
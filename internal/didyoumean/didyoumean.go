@@ -0,0 +1,131 @@
+// Package didyoumean suggests the known names closest to a mistyped one.
+// It depends on nothing in op, so the RPC client can use it.
+package didyoumean
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxClosest bounds how many names a "did you mean" hint offers.
+const maxClosest = 3
+
+// Closest returns the candidates nearest to target by edit distance, closest
+// first. Only names within a typo's reach are kept: one edit for short names,
+// up to three for long ones. The comparison ignores case.
+func Closest(target string, candidates []string) []string {
+	target = strings.ToLower(strings.TrimSpace(target))
+	if target == "" {
+		return nil
+	}
+	limit := len([]rune(target))/3 + 1
+	if limit > 3 {
+		limit = 3
+	}
+
+	type match struct {
+		name     string
+		distance int
+	}
+	seen := make(map[string]bool, len(candidates))
+	var matches []match
+	for _, candidate := range candidates {
+		if candidate == "" || seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+		distance := Levenshtein(target, strings.ToLower(candidate))
+		if distance == 0 || distance > limit {
+			continue
+		}
+		matches = append(matches, match{name: candidate, distance: distance})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+	if len(matches) > maxClosest {
+		matches = matches[:maxClosest]
+	}
+
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = m.name
+	}
+	return names
+}
+
+// Hint renders names as a hint such as `did you mean "who"?`, or ""
+// when there is nothing to suggest.
+func Hint(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	list := quoted[0]
+	if len(quoted) > 1 {
+		list = strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+	}
+	return "did you mean " + list + "?"
+}
+
+// WithHint appends a "did you mean" hint for names to msg, if there is one.
+func WithHint(msg string, names []string) string {
+	if hint := Hint(names); hint != "" {
+		return msg + "; " + hint
+	}
+	return msg
+}
+
+// ClosestMethods is Closest for RPC method names. Both method and the
+// available names may be qualified by their service ("pkg.Service/Method")
+// and carry an input type ("Method(Request)"); only the method name is
+// compared and returned.
+func ClosestMethods(method string, available []string) []string {
+	names := make([]string, 0, len(available))
+	for _, name := range available {
+		names = append(names, bareMethodName(name))
+	}
+	return Closest(bareMethodName(method), names)
+}
+
+func bareMethodName(name string) string {
+	name = strings.TrimSpace(name)
+	if i := strings.Index(name, "("); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// Levenshtein returns the number of single-rune insertions, deletions and
+// substitutions needed to turn a into b.
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
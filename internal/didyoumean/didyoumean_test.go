@@ -0,0 +1,54 @@
+package didyoumean
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"who", "who", 0},
+		{"wh", "who", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	}
+	for _, tc := range cases {
+		if got := Levenshtein(tc.a, tc.b); got != tc.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestClosestKeepsNearNamesOnly(t *testing.T) {
+	got := Closest("wh", []string{"who", "rob-go", "atlas", "who"})
+	if want := []string{"who"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Closest = %v, want %v", got, want)
+	}
+	if got := Closest("zzzzzz", []string{"who", "rob-go"}); len(got) != 0 {
+		t.Fatalf("Closest for an unrelated name = %v, want none", got)
+	}
+}
+
+func TestClosestMethodsComparesBareNames(t *testing.T) {
+	available := []string{"op.v1.OPService/ListIdentities(ListIdentitiesRequest)", "op.v1.OPService/ShowIdentity(ShowIdentityRequest)"}
+	got := ClosestMethods("OPService/ListIdentites", available)
+	if want := []string{"ListIdentities"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ClosestMethods = %v, want %v", got, want)
+	}
+}
+
+func TestWithHint(t *testing.T) {
+	if got := WithHint(`holon "wh" not found`, []string{"who"}); got != `holon "wh" not found; did you mean "who"?` {
+		t.Fatalf("WithHint = %q", got)
+	}
+	if got := WithHint("plain", nil); got != "plain" {
+		t.Fatalf("WithHint without names = %q", got)
+	}
+	if got := Hint([]string{"a", "b", "c"}); got != `did you mean "a", "b" or "c"?` {
+		t.Fatalf("Hint = %q", got)
+	}
+}
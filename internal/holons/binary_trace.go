@@ -0,0 +1,87 @@
+package holons
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	openv "github.com/organic-programming/grace-op/internal/env"
+)
+
+// Binary probe sources, in the order a holon's candidates are checked.
+const (
+	ProbeBuild = "build" // the build output its manifest names
+	ProbeOPBIN = "opbin" // an artifact installed in $OPBIN
+	ProbePath  = "path"  // a $PATH lookup
+)
+
+// BinaryProbe is one place binary resolution looked.
+type BinaryProbe struct {
+	Source string `json:"source"`
+	// Path is the file checked; for a $PATH lookup that failed, the name
+	// looked up.
+	Path  string `json:"path"`
+	Found bool   `json:"found"`
+}
+
+// BinaryResolution is what TraceBinary found: the binary, the holon the
+// selector matched, if any, and the probes made.
+type BinaryResolution struct {
+	Path   string
+	Target *Target
+	Probes []BinaryProbe
+}
+
+// fromTarget looks for target's built binary, then for its names on the
+// system, and reports whether one was found.
+func (r *BinaryResolution) fromTarget(target *Target, requested string) bool {
+	r.Target = target
+	if r.Path = r.built(target); r.Path != "" {
+		return true
+	}
+	r.Path = r.system(binaryLookupNames(target, requested)...)
+	return r.Path != ""
+}
+
+func (r *BinaryResolution) built(target *Target) string {
+	if target == nil || target.Manifest == nil {
+		return ""
+	}
+	binaryPath := target.Manifest.BinaryPath()
+	if binaryPath == "" {
+		return ""
+	}
+	info, err := os.Stat(binaryPath)
+	found := err == nil && !info.IsDir()
+	r.probe(ProbeBuild, binaryPath, found)
+	if !found {
+		return ""
+	}
+	return binaryPath
+}
+
+// system looks each name up in $OPBIN, then on $PATH.
+func (r *BinaryResolution) system(names ...string) string {
+	for _, name := range uniqueNonEmpty(names) {
+		for _, candidate := range installedArtifactCandidates(name) {
+			installed := filepath.Join(openv.OPBIN(), candidate)
+			found := isInstalledArtifact(installed)
+			r.probe(ProbeOPBIN, installed, found)
+			if found {
+				return installed
+			}
+		}
+		path, err := exec.LookPath(name)
+		if err != nil {
+			r.probe(ProbePath, name, false)
+			continue
+		}
+		r.probe(ProbePath, path, true)
+		return path
+	}
+	return ""
+}
+
+func (r *BinaryResolution) probe(source, path string, found bool) {
+	r.Probes = append(r.Probes, BinaryProbe{Source: source, Path: path, Found: found})
+}
@@ -30,6 +30,57 @@ type LocalHolon struct {
 	Identity     identity.Identity
 	IdentityPath string
 	Manifest     *LoadedManifest
+	// Submodule is the name .gitmodules gives the submodule checked out
+	// in Dir, if any.
+	Submodule string
+}
+
+// InvalidHolon is a holon manifest discovery found but could not parse.
+type InvalidHolon struct {
+	Path         string
+	RelativePath string
+	Origin       string
+	Err          error
+}
+
+// BareHolon is a directory with an executable named after it but no identity
+// file yet: a holon still being written.
+type BareHolon struct {
+	Dir          string
+	RelativePath string
+	Binary       string
+}
+
+// IdentityFilter keeps holons by language, clade and status. Values are
+// ORed within a field and the fields are ANDed; an empty field keeps every
+// holon. Matching ignores case and surrounding space.
+type IdentityFilter struct {
+	Langs    []string
+	Clades   []string
+	Statuses []string
+}
+
+// Active reports whether the filter can leave a holon out.
+func (f IdentityFilter) Active() bool {
+	return len(f.Langs) > 0 || len(f.Clades) > 0 || len(f.Statuses) > 0
+}
+
+// Match reports whether a holon with these identity values passes.
+func (f IdentityFilter) Match(lang, clade, status string) bool {
+	return matchesAny(f.Langs, lang) && matchesAny(f.Clades, clade) && matchesAny(f.Statuses, status)
+}
+
+func matchesAny(wanted []string, value string) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	value = strings.TrimSpace(value)
+	for _, w := range wanted {
+		if strings.EqualFold(strings.TrimSpace(w), value) {
+			return true
+		}
+	}
+	return false
 }
 
 func KnownRoots() []string {
@@ -41,110 +92,231 @@ func KnownRootLabels() []string {
 }
 
 func DiscoverHolons(root string) ([]LocalHolon, error) {
-	return discoverHolonsInRoot(root, "local", holonRelativePath)
+	found, _, err := discoverHolonsInRoot(root, "local", holonRelativePath)
+	return found, err
 }
 
 func DiscoverLocalHolons() ([]LocalHolon, error) {
 	return DiscoverHolons(openv.Root())
 }
 
-func DiscoverCachedHolons() ([]LocalHolon, error) {
-	cacheDir := openv.CacheDir()
-	info, err := os.Stat(cacheDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	if !info.IsDir() {
-		return nil, nil
-	}
-	return discoverHolonsInRoot(cacheDir, "cached", cacheRelativePath)
+// ScanProgress is a snapshot of a discovery walk: how many directories it has
+// entered and how many holons it has found so far. Done is set on the final
+// report.
+type ScanProgress struct {
+	Origin string
+	Dirs   int
+	Found  int
+	Done   bool
 }
 
-func discoverHolonsInRoot(root, origin string, relPath func(string, string) string) ([]LocalHolon, error) {
-	root = strings.TrimSpace(root)
-	if root == "" {
-		root = openv.Root()
+// ScanOptions chooses where DiscoverLocalHolonsWithOptions and
+// DiscoverBareHolonsWithOptions look. The zero value walks the working root
+// to any depth.
+type ScanOptions struct {
+	// Roots are the directories walked instead of the working root.
+	Roots []string
+	// MaxDepth, when positive, is how many directories below a root the
+	// walk descends at most.
+	MaxDepth int
+	// Progress, when non-nil, is called as the walk enters directories
+	// and finds holons, and once more when it completes.
+	Progress func(ScanProgress)
+}
+
+func (o ScanOptions) roots() []string {
+	if len(o.Roots) == 0 {
+		return []string{openv.Root()}
 	}
-	absRoot, err := filepath.Abs(root)
+	return o.Roots
+}
+
+// DiscoverLocalHolonsWithOptions walks opts.Roots for holons, reporting the
+// manifests it skipped because they could not be parsed. Relative paths stay relative to the working root, so holons
+// under different roots are told apart; a root outside it gives paths
+// starting with "..". They are for display: depths are counted from the
+// root a holon was found under.
+func DiscoverLocalHolonsWithOptions(opts ScanOptions) ([]LocalHolon, []InvalidHolon, error) {
+	return scanHolons(opts.roots(), "local", workingRelativePath, opts.MaxDepth, opts.Progress)
+}
+
+// workingRelativePath is holonRelativePath from the working root, whichever
+// root the walk started from.
+func workingRelativePath(_, dir string) string {
+	root, err := filepath.Abs(openv.Root())
 	if err != nil {
-		return nil, err
+		root = openv.Root()
 	}
+	return holonRelativePath(root, dir)
+}
+
+func DiscoverCachedHolons() ([]LocalHolon, error) {
+	found, _, err := DiscoverCachedHolonsWithInvalid()
+	return found, err
+}
+
+// DiscoverCachedHolonsWithInvalid is DiscoverCachedHolons that also reports
+// the manifests it skipped because they could not be parsed.
+func DiscoverCachedHolonsWithInvalid() ([]LocalHolon, []InvalidHolon, error) {
+	return DiscoverCachedHolonsWithProgress(nil)
+}
 
-	info, err := os.Stat(absRoot)
+// DiscoverCachedHolonsWithProgress is DiscoverCachedHolonsWithInvalid with
+// progress reports, as in ScanOptions.Progress.
+func DiscoverCachedHolonsWithProgress(progress func(ScanProgress)) ([]LocalHolon, []InvalidHolon, error) {
+	cacheDir := openv.CacheDir()
+	info, err := os.Stat(cacheDir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, nil
+			return nil, nil, nil
 		}
-		return nil, err
+		return nil, nil, err
 	}
 	if !info.IsDir() {
-		return nil, nil
+		return nil, nil, nil
 	}
+	return walkHolonsInRoot(cacheDir, "cached", cacheRelativePath, progress)
+}
+
+// discoverHolonsInRoot walks root for holon manifests. A manifest that cannot
+// be parsed does not stop the walk: it is skipped and reported as invalid.
+func discoverHolonsInRoot(root, origin string, relPath func(string, string) string) ([]LocalHolon, []InvalidHolon, error) {
+	return walkHolonsInRoot(root, origin, relPath, nil)
+}
 
+func walkHolonsInRoot(root, origin string, relPath func(string, string) string, progress func(ScanProgress)) ([]LocalHolon, []InvalidHolon, error) {
+	return scanHolons([]string{root}, origin, relPath, 0, progress)
+}
+
+// scanHolons walks each of roots, to at most maxDepth directories below it
+// when maxDepth is positive, for holon manifests. A holon found under two
+// roots, or twice under one, is kept once, closest to its root. Symlinked
+// directories are not followed, so a link loop cannot trap the walk.
+func scanHolons(roots []string, origin string, relPath func(string, string) string, maxDepth int, progress func(ScanProgress)) ([]LocalHolon, []InvalidHolon, error) {
 	candidates := make(map[string]LocalHolon)
+	// depths holds how far below its root each candidate was found, which
+	// relPath need not tell.
+	depths := make(map[string]int)
 	orderedKeys := make([]string, 0)
-
-	err = filepath.WalkDir(absRoot, func(path string, d os.DirEntry, walkErr error) error {
-		if walkErr != nil {
-			return nil
+	var invalid []InvalidHolon
+	dirs := 0
+	report := func(done bool) {
+		if progress != nil {
+			progress(ScanProgress{Origin: origin, Dirs: dirs, Found: len(orderedKeys), Done: done})
 		}
+	}
 
-		if d.IsDir() {
-			if shouldSkipDiscoveryDir(absRoot, path, d.Name()) {
-				return filepath.SkipDir
-			}
-			return nil
+	visited := make(map[string]bool)
+	for _, root := range roots {
+		root = strings.TrimSpace(root)
+		if root == "" {
+			root = openv.Root()
 		}
-		if d.Name() != ManifestFileName {
-			return nil
-		}
-
-		dir := filepath.Dir(path)
-		absDir, err := filepath.Abs(dir)
+		absRoot, err := filepath.Abs(root)
 		if err != nil {
-			return nil
+			return nil, nil, err
 		}
 
-		id, _, err := identity.ReadHolonYAML(path)
+		info, err := os.Stat(absRoot)
 		if err != nil {
-			return nil
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, nil, err
 		}
-
-		manifest, err := LoadManifest(absDir)
-		if err != nil {
-			manifest = nil
+		if !info.IsDir() {
+			continue
 		}
 
-		entry := LocalHolon{
-			Dir:          absDir,
-			RelativePath: relPath(absRoot, absDir),
-			Origin:       origin,
-			Identity:     id,
-			IdentityPath: path,
-			Manifest:     manifest,
+		// Submodules are walked from their own root, so one checked out under a
+		// directory the walk skips, such as vendor/ or a hidden one, is found.
+		submodules := readSubmodules(absRoot)
+		submoduleNames := make(map[string]string, len(submodules))
+		for _, sub := range submodules {
+			submoduleNames[sub.Path] = sub.Name
 		}
 
-		key := strings.TrimSpace(id.UUID)
-		if key == "" {
-			key = absDir
-		}
-		if existing, ok := candidates[key]; ok {
-			if discoveryPathDepth(entry.RelativePath) < discoveryPathDepth(existing.RelativePath) {
-				candidates[key] = entry
+		visit := func(walkRoot, path string, d os.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return nil
+			}
+
+			if d.IsDir() {
+				if visited[path] || shouldSkipDiscoveryDir(walkRoot, path, d.Name()) {
+					return filepath.SkipDir
+				}
+				if maxDepth > 0 && discoveryPathDepth(holonRelativePath(absRoot, path)) > maxDepth {
+					return filepath.SkipDir
+				}
+				visited[path] = true
+				dirs++
+				report(false)
+				return nil
+			}
+			if !identity.IsIdentityFile(path) {
+				return nil
+			}
+
+			dir := filepath.Dir(path)
+			absDir, err := filepath.Abs(dir)
+			if err != nil {
+				return nil
 			}
+
+			id, _, err := identity.ReadIdentity(path)
+			if err != nil {
+				invalid = append(invalid, InvalidHolon{
+					Path:         path,
+					RelativePath: relPath(absRoot, absDir),
+					Origin:       origin,
+					Err:          err,
+				})
+				return nil
+			}
+
+			manifest, err := LoadManifest(absDir)
+			if err != nil {
+				manifest = nil
+			}
+
+			entry := LocalHolon{
+				Dir:          absDir,
+				RelativePath: relPath(absRoot, absDir),
+				Origin:       origin,
+				Identity:     id,
+				IdentityPath: path,
+				Manifest:     manifest,
+				Submodule:    submoduleNames[absDir],
+			}
+
+			key := strings.TrimSpace(id.UUID)
+			if key == "" {
+				key = absDir
+			}
+			depth := discoveryPathDepth(holonRelativePath(absRoot, absDir))
+			if _, ok := candidates[key]; ok {
+				if depth < depths[key] {
+					candidates[key], depths[key] = entry, depth
+				}
+				return nil
+			}
+
+			candidates[key], depths[key] = entry, depth
+			orderedKeys = append(orderedKeys, key)
+			report(false)
 			return nil
 		}
 
-		candidates[key] = entry
-		orderedKeys = append(orderedKeys, key)
-		return nil
-	})
-	if err != nil {
-		return nil, err
+		for _, walkRoot := range append([]string{absRoot}, submodulePaths(submodules)...) {
+			err = filepath.WalkDir(walkRoot, func(path string, d os.DirEntry, walkErr error) error {
+				return visit(walkRoot, path, d, walkErr)
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
 	}
+	report(true)
 
 	entries := make([]LocalHolon, 0, len(candidates))
 	for _, key := range orderedKeys {
@@ -159,7 +331,95 @@ func discoverHolonsInRoot(root, origin string, relPath func(string, string) stri
 		}
 		return entries[i].RelativePath < entries[j].RelativePath
 	})
-	return entries, nil
+	return entries, invalid, nil
+}
+
+// DiscoverBareHolons finds directories under root that have no identity file
+// but hold an executable named after the directory, either directly or in
+// .op/build/bin.
+func DiscoverBareHolons(root string) ([]BareHolon, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	return discoverBareHolons(absRoot, holonRelativePath, 0)
+}
+
+// DiscoverBareHolonsWithOptions is DiscoverBareHolons over opts.Roots, with
+// paths relative to the working root as in DiscoverLocalHolonsWithOptions.
+func DiscoverBareHolonsWithOptions(opts ScanOptions) ([]BareHolon, error) {
+	var bare []BareHolon
+	for _, root := range opts.roots() {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			return nil, err
+		}
+		found, err := discoverBareHolons(absRoot, workingRelativePath, opts.MaxDepth)
+		if err != nil {
+			return nil, err
+		}
+		bare = append(bare, found...)
+	}
+	return bare, nil
+}
+
+func discoverBareHolons(absRoot string, relPath func(string, string) string, maxDepth int) ([]BareHolon, error) {
+	if info, err := os.Stat(absRoot); err != nil || !info.IsDir() {
+		return nil, nil
+	}
+
+	var bare []BareHolon
+	err := filepath.WalkDir(absRoot, func(path string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil || !d.IsDir() {
+			return nil
+		}
+		if shouldSkipDiscoveryDir(absRoot, path, d.Name()) {
+			return filepath.SkipDir
+		}
+		if maxDepth > 0 && discoveryPathDepth(holonRelativePath(absRoot, path)) > maxDepth {
+			return filepath.SkipDir
+		}
+		if _, ok := identity.FindIdentityFile(path); ok {
+			return nil
+		}
+
+		name := filepath.Base(path)
+		for _, candidate := range []string{
+			filepath.Join(path, name),
+			filepath.Join(path, ".op", "build", "bin", name),
+		} {
+			if isExecutableFile(candidate) {
+				bare = append(bare, BareHolon{
+					Dir:          path,
+					RelativePath: relPath(absRoot, path),
+					Binary:       candidate,
+				})
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bare, nil
+}
+
+func DiscoverLocalBareHolons() ([]BareHolon, error) {
+	return DiscoverBareHolons(openv.Root())
+}
+
+func submodulePaths(submodules []Submodule) []string {
+	paths := make([]string, 0, len(submodules))
+	for _, sub := range submodules {
+		paths = append(paths, sub.Path)
+	}
+	return paths
+}
+
+func isExecutableFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0
 }
 
 func shouldSkipDiscoveryDir(root, path, name string) bool {
@@ -208,65 +468,61 @@ func ResolveTarget(ref string) (*Target, error) {
 }
 
 func ResolveBinary(name string) (string, error) {
+	resolution, err := TraceBinary(name)
+	return resolution.Path, err
+}
+
+// TraceBinary resolves name to a binary as ResolveBinary does and records
+// the holon it matched and every path checked on the way, in order.
+func TraceBinary(name string) (BinaryResolution, error) {
+	var r BinaryResolution
 	trimmed := strings.TrimSpace(name)
 	if trimmed == "" {
-		return "", fmt.Errorf("holon %q not found", name)
+		return r, fmt.Errorf("holon %q not found", name)
 	}
 
 	if dir, ok, err := existingTargetDir(trimmed); err != nil {
-		return "", err
+		return r, err
 	} else if ok {
 		target, err := resolveDir(trimmed, dir)
 		if err != nil {
-			return "", err
-		}
-		if binaryPath := builtBinaryForTarget(target); binaryPath != "" {
-			return binaryPath, nil
+			return r, err
 		}
-		if systemPath := lookupBinaryOnSystem(binaryLookupNames(target, trimmed)...); systemPath != "" {
-			return systemPath, nil
+		if r.fromTarget(target, trimmed) {
+			return r, nil
 		}
-		return "", fmt.Errorf("holon %q not found", name)
+		return r, fmt.Errorf("holon %q not found", name)
 	}
 
 	if target, err := resolveTargetBySlugFromOrigins(trimmed, true, false); err == nil {
-		if binaryPath := builtBinaryForTarget(target); binaryPath != "" {
-			return binaryPath, nil
-		}
-		if systemPath := lookupBinaryOnSystem(binaryLookupNames(target, trimmed)...); systemPath != "" {
-			return systemPath, nil
+		if r.fromTarget(target, trimmed) {
+			return r, nil
 		}
 	} else if !isTargetNotFound(err) {
-		return "", err
+		return r, err
 	}
 
-	if systemPath := lookupBinaryOnSystem(trimmed); systemPath != "" {
-		return systemPath, nil
+	if r.Path = r.system(trimmed); r.Path != "" {
+		return r, nil
 	}
 
 	if target, err := resolveTargetBySlugFromOrigins(trimmed, false, true); err == nil {
-		if binaryPath := builtBinaryForTarget(target); binaryPath != "" {
-			return binaryPath, nil
-		}
-		if systemPath := lookupBinaryOnSystem(binaryLookupNames(target, trimmed)...); systemPath != "" {
-			return systemPath, nil
+		if r.fromTarget(target, trimmed) {
+			return r, nil
 		}
 	} else if !isTargetNotFound(err) {
-		return "", err
+		return r, err
 	}
 
 	if target, err := resolveTargetByUUID(trimmed); err == nil {
-		if binaryPath := builtBinaryForTarget(target); binaryPath != "" {
-			return binaryPath, nil
-		}
-		if systemPath := lookupBinaryOnSystem(binaryLookupNames(target, trimmed)...); systemPath != "" {
-			return systemPath, nil
+		if r.fromTarget(target, trimmed) {
+			return r, nil
 		}
 	} else if !isTargetNotFound(err) {
-		return "", err
+		return r, err
 	}
 
-	return "", fmt.Errorf("holon %q not found", name)
+	return r, fmt.Errorf("holon %q not found", name)
 }
 
 func ResolveInstalledBinary(name string) string {
@@ -353,6 +609,11 @@ func filterHolonsBySlug(holons []LocalHolon, ref string) []LocalHolon {
 		// Match by identity-derived slug (given_name + family_name)
 		if idSlug := holon.Identity.Slug(); idSlug != "" && idSlug == trimmed {
 			matches = append(matches, holon)
+			continue
+		}
+		// Match by the name .gitmodules gives its submodule
+		if holon.Submodule != "" && (holon.Submodule == trimmed || filepath.Base(holon.Submodule) == trimmed) {
+			matches = append(matches, holon)
 		}
 	}
 	return matches
@@ -432,20 +693,6 @@ func shortUUIDValue(uuid string) string {
 	return uuid[:8]
 }
 
-func builtBinaryForTarget(target *Target) string {
-	if target == nil || target.Manifest == nil {
-		return ""
-	}
-	binaryPath := target.Manifest.BinaryPath()
-	if binaryPath == "" {
-		return ""
-	}
-	if info, err := os.Stat(binaryPath); err == nil && !info.IsDir() {
-		return binaryPath
-	}
-	return ""
-}
-
 func binaryLookupNames(target *Target, requested string) []string {
 	names := []string{requested}
 	if target != nil && target.Manifest != nil {
@@ -463,15 +710,8 @@ func binaryLookupNames(target *Target, requested string) []string {
 }
 
 func lookupBinaryOnSystem(names ...string) string {
-	for _, candidate := range uniqueNonEmpty(names) {
-		if installed := lookupInstalledArtifactInOPBIN(candidate); installed != "" {
-			return installed
-		}
-		if path, lookErr := exec.LookPath(candidate); lookErr == nil {
-			return path
-		}
-	}
-	return ""
+	var r BinaryResolution
+	return r.system(names...)
 }
 
 func isTargetNotFound(err error) bool {
@@ -557,10 +797,11 @@ func resolveDir(ref, dir string) (*Target, error) {
 		RelativePath: workspaceRelativePath(absDir),
 	}
 
-	identityPath := filepath.Join(absDir, ManifestFileName)
-	if id, _, err := identity.ReadHolonYAML(identityPath); err == nil {
-		target.Identity = &id
-		target.IdentityPath = identityPath
+	if identityPath, ok := identity.FindIdentityFile(absDir); ok {
+		if id, _, err := identity.ReadIdentity(identityPath); err == nil {
+			target.Identity = &id
+			target.IdentityPath = identityPath
+		}
 	}
 
 	manifestPath := filepath.Join(absDir, ManifestFileName)
@@ -590,7 +831,7 @@ func existingTargetDir(ref string) (string, bool, error) {
 	}
 
 	switch filepath.Base(ref) {
-	case ManifestFileName:
+	case ManifestFileName, identity.JSONManifestFileName:
 		return filepath.Dir(ref), true, nil
 	default:
 		return "", false, fmt.Errorf("%s is not a holon directory", ref)
@@ -3,6 +3,8 @@ package holons
 import (
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 	"testing"
 
@@ -256,3 +258,191 @@ func writeDiscoveryHolon(t *testing.T, dir string, seed discoveryHolonSeed) {
 	}
 	writeManifestWithIdentity(t, dir, id, "kind: native\nbuild:\n  runner: go-module\nartifacts:\n  binary: "+seed.binaryName+"\n")
 }
+
+func TestDiscoverHolonsReadsEachIdentitySource(t *testing.T) {
+	root := t.TempDir()
+
+	writeDiscoveryHolon(t, filepath.Join(root, "holons", "alpha"), discoveryHolonSeed{
+		uuid:       "alpha-uuid",
+		givenName:  "Alpha",
+		familyName: "Go",
+		binaryName: "alpha",
+	})
+	writeJSONIdentity(t, filepath.Join(root, "holons", "beta"), `{
+	"uuid": "beta-uuid",
+	"given_name": "Beta",
+	"family_name": "Json",
+	"aliases": ["b"],
+	"lang": "go"
+}`)
+
+	located, err := DiscoverHolons(root)
+	if err != nil {
+		t.Fatalf("DiscoverHolons returned error: %v", err)
+	}
+	got := make(map[string]LocalHolon, len(located))
+	for _, holon := range located {
+		got[holon.Identity.UUID] = holon
+	}
+	if len(got) != 2 {
+		t.Fatalf("located = %d, want 2", len(located))
+	}
+	if base := filepath.Base(got["alpha-uuid"].IdentityPath); base != identity.ManifestFileName {
+		t.Fatalf("alpha identity path = %q", got["alpha-uuid"].IdentityPath)
+	}
+	beta := got["beta-uuid"]
+	if base := filepath.Base(beta.IdentityPath); base != identity.JSONManifestFileName {
+		t.Fatalf("beta identity path = %q", beta.IdentityPath)
+	}
+	if beta.Identity.Slug() != "beta-json" || len(beta.Identity.Aliases) != 1 || beta.Identity.Aliases[0] != "b" {
+		t.Fatalf("beta identity = %+v", beta.Identity)
+	}
+
+	target, err := ResolveTarget(filepath.Join(root, "holons", "beta", identity.JSONManifestFileName))
+	if err != nil {
+		t.Fatalf("ResolveTarget returned error: %v", err)
+	}
+	if target.Identity == nil || target.Identity.UUID != "beta-uuid" {
+		t.Fatalf("target identity = %+v", target.Identity)
+	}
+}
+
+func TestDiscoverHolonsPrefersHolonYAMLOverHolonJSON(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "gamma")
+
+	writeDiscoveryHolon(t, dir, discoveryHolonSeed{
+		uuid:       "gamma-yaml",
+		givenName:  "Gamma",
+		familyName: "Yaml",
+		binaryName: "gamma",
+	})
+	writeJSONIdentity(t, dir, `{"uuid": "gamma-json", "given_name": "Gamma", "family_name": "Json"}`)
+
+	located, err := DiscoverHolons(root)
+	if err != nil {
+		t.Fatalf("DiscoverHolons returned error: %v", err)
+	}
+	if len(located) != 1 || located[0].Identity.UUID != "gamma-yaml" {
+		t.Fatalf("located = %+v, want only the holon.yaml identity", located)
+	}
+}
+
+func TestDiscoverHolonsReportsInvalidHolonJSON(t *testing.T) {
+	root := t.TempDir()
+	writeJSONIdentity(t, filepath.Join(root, "broken"), `{"uuid": "broken",`)
+
+	located, invalid, err := discoverHolonsInRoot(root, "local", holonRelativePath)
+	if err != nil {
+		t.Fatalf("discoverHolonsInRoot returned error: %v", err)
+	}
+	if len(located) != 0 || len(invalid) != 1 {
+		t.Fatalf("located = %d, invalid = %d; want 0 and 1", len(located), len(invalid))
+	}
+	if !strings.Contains(invalid[0].Err.Error(), "JSON parse error") {
+		t.Fatalf("invalid error = %v", invalid[0].Err)
+	}
+}
+
+func writeJSONIdentity(t *testing.T, dir, body string) {
+	t.Helper()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, identity.JSONManifestFileName), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscoverHolonsReportsScanProgress(t *testing.T) {
+	root := t.TempDir()
+	writeDiscoveryHolon(t, filepath.Join(root, "alpha"), discoveryHolonSeed{uuid: "uuid-alpha", givenName: "Alpha", familyName: "Holon", binaryName: "alpha"})
+	writeDiscoveryHolon(t, filepath.Join(root, "nested", "beta"), discoveryHolonSeed{uuid: "uuid-beta", givenName: "Beta", familyName: "Holon", binaryName: "beta"})
+
+	var reports []ScanProgress
+	found, _, err := walkHolonsInRoot(root, "local", holonRelativePath, func(p ScanProgress) {
+		reports = append(reports, p)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || len(reports) == 0 {
+		t.Fatalf("found %d holons with %d progress reports", len(found), len(reports))
+	}
+	for i, p := range reports[:len(reports)-1] {
+		if p.Done {
+			t.Fatalf("report %d of %d is marked done", i, len(reports))
+		}
+	}
+	last := reports[len(reports)-1]
+	if want := (ScanProgress{Origin: "local", Dirs: 4, Found: 2, Done: true}); last != want {
+		t.Fatalf("final report = %+v, want %+v", last, want)
+	}
+}
+
+func TestDiscoverLocalHolonsWithOptionsWalksRootsToMaxDepth(t *testing.T) {
+	root := t.TempDir()
+	t.Chdir(root)
+	writeDiscoveryHolon(t, filepath.Join(root, "services", "api", "who"), discoveryHolonSeed{uuid: "uuid-who", givenName: "Who", familyName: "Holon", binaryName: "who"})
+	writeDiscoveryHolon(t, filepath.Join(root, "services", "api", "deep", "er", "rob"), discoveryHolonSeed{uuid: "uuid-rob", givenName: "Rob", familyName: "Holon", binaryName: "rob"})
+	writeDiscoveryHolon(t, filepath.Join(root, "tools", "ink"), discoveryHolonSeed{uuid: "uuid-ink", givenName: "Ink", familyName: "Holon", binaryName: "ink"})
+	writeDiscoveryHolon(t, filepath.Join(root, "elsewhere", "zed"), discoveryHolonSeed{uuid: "uuid-zed", givenName: "Zed", familyName: "Holon", binaryName: "zed"})
+	if runtime.GOOS != "windows" {
+		// A link back up the tree is not followed.
+		if err := os.Symlink(root, filepath.Join(root, "services", "loop")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths := func(opts ScanOptions) []string {
+		t.Helper()
+		found, _, err := DiscoverLocalHolonsWithOptions(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, holon := range found {
+			got = append(got, holon.RelativePath)
+		}
+		return got
+	}
+
+	all := []string{"services/api/deep/er/rob", "services/api/who", "tools/ink"}
+	if got := paths(ScanOptions{Roots: []string{"services", "tools"}}); !slices.Equal(got, all) {
+		t.Fatalf("roots = %v, want %v relative to the working root", got, all)
+	}
+	if got := paths(ScanOptions{Roots: []string{"services", "tools"}, MaxDepth: 2}); !slices.Equal(got, []string{"services/api/who", "tools/ink"}) {
+		t.Fatalf("max depth 2 = %v, want the deep holon left out", got)
+	}
+	if got := paths(ScanOptions{}); len(got) != 4 {
+		t.Fatalf("no roots = %v, want every holon under the working root", got)
+	}
+}
+
+func TestDiscoverLocalHolonsWithOptionsKeepsTheCopyClosestToItsRoot(t *testing.T) {
+	outside := t.TempDir()
+	work := filepath.Join(t.TempDir(), "deep", "work", "space")
+	if err := os.MkdirAll(work, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(work)
+
+	// One holon under two roots: three levels below one inside the working
+	// root, and right under one outside it, whose working-relative path
+	// climbs out through ".." and so has more segments.
+	seed := discoveryHolonSeed{uuid: "uuid-twin", givenName: "Twin", familyName: "Holon", binaryName: "twin"}
+	writeDiscoveryHolon(t, filepath.Join(work, "src", "a", "b", "twin"), seed)
+	writeDiscoveryHolon(t, filepath.Join(outside, "twin"), seed)
+
+	found, _, err := DiscoverLocalHolonsWithOptions(ScanOptions{Roots: []string{"src", outside}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Dir != filepath.Join(outside, "twin") {
+		t.Fatalf("found = %+v, want only the copy right under its root", found)
+	}
+	if !strings.HasPrefix(found[0].RelativePath, "../") {
+		t.Fatalf("relative path = %q, want it relative to the working root", found[0].RelativePath)
+	}
+}
@@ -0,0 +1,119 @@
+package holons
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Submodule is one [submodule "name"] section of a .gitmodules file.
+type Submodule struct {
+	Name string
+	Path string
+	URL  string
+}
+
+// ParseGitmodules reads the git config format of a .gitmodules file and
+// returns its submodules in order. Sections other than submodule, and keys
+// other than path and url, are ignored; a submodule without a path is
+// dropped.
+func ParseGitmodules(r io.Reader) ([]Submodule, error) {
+	var (
+		submodules []Submodule
+		current    *Submodule
+	)
+	flush := func() {
+		if current != nil && current.Path != "" {
+			submodules = append(submodules, *current)
+		}
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			end := strings.LastIndexByte(line, ']')
+			if end < 0 {
+				return nil, fmt.Errorf(".gitmodules line %d: unterminated section header", lineNo)
+			}
+			flush()
+			section, name, _ := strings.Cut(strings.TrimSpace(line[1:end]), " ")
+			if strings.EqualFold(section, "submodule") {
+				current = &Submodule{Name: unquoteGitValue(name)}
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "path":
+			current.Path = filepath.FromSlash(unquoteGitValue(value))
+		case "url":
+			current.URL = unquoteGitValue(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return submodules, nil
+}
+
+// unquoteGitValue trims a git config value and strips a trailing comment
+// and surrounding double quotes.
+func unquoteGitValue(value string) string {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, `"`) {
+		if end := strings.IndexByte(value[1:], '"'); end >= 0 {
+			return value[1 : end+1]
+		}
+		return strings.TrimPrefix(value, `"`)
+	}
+	if i := strings.IndexAny(value, "#;"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value
+}
+
+// readSubmodules returns the checked-out submodules declared by
+// dir/.gitmodules, with absolute paths, including those nested in other
+// submodules. Declared paths that escape dir or are not directories are
+// left out; a missing or unreadable .gitmodules yields none.
+func readSubmodules(dir string) []Submodule {
+	file, err := os.Open(filepath.Join(dir, ".gitmodules"))
+	if err != nil {
+		return nil
+	}
+	parsed, err := ParseGitmodules(file)
+	_ = file.Close()
+	if err != nil {
+		return nil
+	}
+
+	var submodules []Submodule
+	for _, sub := range parsed {
+		path := filepath.Join(dir, sub.Path)
+		if rel, err := filepath.Rel(dir, path); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			continue
+		}
+		sub.Path = path
+		submodules = append(submodules, sub)
+		submodules = append(submodules, readSubmodules(path)...)
+	}
+	return submodules
+}
@@ -0,0 +1,121 @@
+package holons
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGitmodules(t *testing.T) {
+	input := `# holons checked out as submodules
+[submodule "sophia-who"]
+	path = holons/who
+	url = https://github.com/organic-programming/sophia-who.git
+[core]
+	path = not-a-submodule
+[submodule "vendor/rhizome"]
+	path = "vendor/rhizome" ; pinned
+	branch = main
+[submodule "no-path"]
+	url = https://example.com/none.git
+`
+	got, err := ParseGitmodules(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Submodule{
+		{Name: "sophia-who", Path: filepath.FromSlash("holons/who"), URL: "https://github.com/organic-programming/sophia-who.git"},
+		{Name: "vendor/rhizome", Path: filepath.FromSlash("vendor/rhizome")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("submodules = %+v, want %+v", got, want)
+	}
+
+	if _, err := ParseGitmodules(strings.NewReader("[submodule \"broken\"\n")); err == nil {
+		t.Fatal("expected an unterminated section header to be rejected")
+	}
+}
+
+func TestDiscoverHolonsFollowsSubmodules(t *testing.T) {
+	root := t.TempDir()
+	chdirForHolonTest(t, root)
+
+	writeDiscoveryHolon(t, filepath.Join(root, "holons", "who"), discoveryHolonSeed{
+		uuid:       "who-uuid",
+		givenName:  "Who",
+		familyName: "Go",
+		binaryName: "who",
+	})
+	writeDiscoveryHolon(t, filepath.Join(root, "vendor", "rhizome"), discoveryHolonSeed{
+		uuid:       "rhizome-uuid",
+		givenName:  "Rhizome",
+		familyName: "Rust",
+		binaryName: "rhizome",
+	})
+	writeDiscoveryHolon(t, filepath.Join(root, "vendor", "rhizome", ".deps", "babel"), discoveryHolonSeed{
+		uuid:       "babel-uuid",
+		givenName:  "Babel",
+		familyName: "Fish",
+		binaryName: "babel",
+	})
+	writeGitmodules(t, root, `[submodule "sophia-who"]
+	path = holons/who
+[submodule "rhizome"]
+	path = vendor/rhizome
+[submodule "missing"]
+	path = holons/missing
+[submodule "escapes"]
+	path = ../outside
+`)
+	writeGitmodules(t, filepath.Join(root, "vendor", "rhizome"), `[submodule "deps/babel-fish"]
+	path = .deps/babel
+`)
+
+	located, err := DiscoverHolons(root)
+	if err != nil {
+		t.Fatalf("DiscoverHolons returned error: %v", err)
+	}
+	got := make(map[string]string, len(located))
+	for _, holon := range located {
+		got[filepath.ToSlash(holon.RelativePath)] = holon.Submodule
+	}
+	want := map[string]string{
+		"holons/who":                 "sophia-who",
+		"vendor/rhizome":             "rhizome",
+		"vendor/rhizome/.deps/babel": "deps/babel-fish",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("located = %v, want %v", got, want)
+	}
+
+	for ref, wantDir := range map[string]string{
+		"sophia-who": filepath.Join(root, "holons", "who"),
+		"babel-fish": filepath.Join(root, "vendor", "rhizome", ".deps", "babel"),
+	} {
+		target, err := ResolveTarget(ref)
+		if err != nil {
+			t.Fatalf("ResolveTarget(%q) returned error: %v", ref, err)
+		}
+		if gotDir, _ := filepath.EvalSymlinks(target.Dir); gotDir != realHolonPath(t, wantDir) {
+			t.Fatalf("ResolveTarget(%q) dir = %q, want %q", ref, gotDir, wantDir)
+		}
+	}
+}
+
+func writeGitmodules(t *testing.T, dir, body string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, ".gitmodules"), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func realHolonPath(t *testing.T, path string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resolved
+}
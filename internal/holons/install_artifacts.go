@@ -37,18 +37,22 @@ func installedArtifactCandidates(name string) []string {
 func lookupInstalledArtifactInOPBIN(name string) string {
 	for _, candidate := range installedArtifactCandidates(name) {
 		installed := filepath.Join(openv.OPBIN(), candidate)
-		info, err := os.Stat(installed)
-		if err != nil {
-			continue
-		}
-		if info.IsDir() && !isMacAppBundlePath(installed) {
-			continue
+		if isInstalledArtifact(installed) {
+			return installed
 		}
-		return installed
 	}
 	return ""
 }
 
+// isInstalledArtifact reports whether path is a file or a macOS app bundle.
+func isInstalledArtifact(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return !info.IsDir() || isMacAppBundlePath(path)
+}
+
 func installNameForArtifact(target *Target, artifactPath string) string {
 	if target != nil && target.Manifest != nil && !manifestHasPrimaryArtifact(target.Manifest) {
 		if binary := target.Manifest.BinaryName(); binary != "" {
@@ -8,6 +8,7 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/organic-programming/grace-op/internal/identity"
 	"gopkg.in/yaml.v3"
 )
 
@@ -56,6 +57,9 @@ type Manifest struct {
 	Description string  `yaml:"description,omitempty"`
 	Skills      []Skill `yaml:"skills,omitempty"`
 
+	// Pin is the build recorded by op pin; not used by lifecycle.
+	Pin *identity.VersionPin `yaml:"pin,omitempty"`
+
 	// Operational fields — used by lifecycle.
 	Kind      string        `yaml:"kind"`
 	Transport string        `yaml:"transport,omitempty"`
@@ -37,6 +37,23 @@ type Identity struct {
 
 	// Optional descriptive text often scaffolded by Sophia.
 	Description string `yaml:"description,omitempty"`
+
+	// Pin records the build this identity is pinned to, set by op pin.
+	Pin *VersionPin `yaml:"pin,omitempty"`
+}
+
+// VersionPin identifies one build of a holon.
+type VersionPin struct {
+	Version string `yaml:"version,omitempty" json:"version,omitempty"`
+	Tag     string `yaml:"tag,omitempty" json:"tag,omitempty"`
+	Commit  string `yaml:"commit,omitempty" json:"commit,omitempty"`
+	OS      string `yaml:"os,omitempty" json:"os,omitempty"`
+	Arch    string `yaml:"arch,omitempty" json:"arch,omitempty"`
+}
+
+// IsZero reports whether no field of the pin is set.
+func (p VersionPin) IsZero() bool {
+	return p == VersionPin{}
 }
 
 // Slug derives a normalized, lowercase-hyphenated identifier from the
@@ -89,11 +89,11 @@ func ScanAllWithPaths(root string, progressEvery int, onFound func(LocatedIdenti
 		scanned++
 		reportProgress(false)
 
-		if d.Name() != ManifestFileName {
+		if !IsIdentityFile(path) {
 			return nil
 		}
 
-		id, _, err := ReadHolonYAML(path)
+		id, _, err := ReadIdentity(path)
 		if err != nil {
 			return nil
 		}
@@ -118,15 +118,15 @@ func ScanAllWithPaths(root string, progressEvery int, onFound func(LocatedIdenti
 	return nil
 }
 
-// FindByUUID locates a holon.yaml file by full UUID or prefix.
+// FindByUUID locates a holon's identity file by full UUID or prefix.
 func FindByUUID(root, target string) (string, error) {
 	var found string
 
 	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil || d.IsDir() || d.Name() != ManifestFileName {
+		if err != nil || d.IsDir() || !IsIdentityFile(path) {
 			return nil
 		}
-		id, _, err := ReadHolonYAML(path)
+		id, _, err := ReadIdentity(path)
 		if err != nil {
 			return nil
 		}
@@ -0,0 +1,93 @@
+package identity
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JSONManifestFileName holds the same identity fields as holon.yaml, as a
+// JSON object, for projects that prefer JSON.
+const JSONManifestFileName = "holon.json"
+
+// Source is a file a holon's identity can be read from.
+type Source struct {
+	FileName string
+	Parse    func(data []byte) (Identity, error)
+}
+
+// Sources lists the recognised identity files in precedence order. holon.yaml
+// is the canonical format: when a directory holds several, the first wins.
+var Sources = []Source{
+	{FileName: ManifestFileName, Parse: ParseHolonYAML},
+	{FileName: JSONManifestFileName, Parse: ParseHolonJSON},
+}
+
+// SourceFor returns the identity source for a file name such as "holon.json".
+func SourceFor(fileName string) (Source, bool) {
+	for _, source := range Sources {
+		if source.FileName == fileName {
+			return source, true
+		}
+	}
+	return Source{}, false
+}
+
+// IsIdentityFile reports whether path is the identity file that counts for
+// its directory: a recognised source with no higher-precedence source beside
+// it.
+func IsIdentityFile(path string) bool {
+	name := filepath.Base(path)
+	if _, ok := SourceFor(name); !ok {
+		return false
+	}
+	found, ok := FindIdentityFile(filepath.Dir(path))
+	return ok && filepath.Base(found) == name
+}
+
+// FindIdentityFile returns the highest-precedence identity file in dir.
+func FindIdentityFile(dir string) (string, bool) {
+	for _, source := range Sources {
+		path := filepath.Join(dir, source.FileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// ReadIdentity reads and parses an identity file with the source matching
+// its name. Unrecognised names are read as holon.yaml.
+func ReadIdentity(path string) (Identity, []byte, error) {
+	source, ok := SourceFor(filepath.Base(path))
+	if !ok {
+		return ReadHolonYAML(path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Identity{}, nil, err
+	}
+	id, err := source.Parse(data)
+	if err != nil {
+		return Identity{}, nil, err
+	}
+	return id, data, nil
+}
+
+// ParseHolonJSON extracts identity fields from a holon.json file. Keys are
+// the holon.yaml keys (given_name, family_name, ...).
+func ParseHolonJSON(data []byte) (Identity, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return Identity{}, fmt.Errorf("JSON parse error: %w", err)
+	}
+	// Re-encode as YAML so both sources share one set of field tags.
+	converted, err := yaml.Marshal(fields)
+	if err != nil {
+		return Identity{}, fmt.Errorf("JSON parse error: %w", err)
+	}
+	return ParseHolonYAML(converted)
+}
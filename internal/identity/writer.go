@@ -25,6 +25,24 @@ lang: {{ .Lang | quote }}
 proto_status: {{ .ProtoStatus }}
 description: |
 {{ .Description | indent }}
+{{- with .Pin }}
+pin:
+{{- with .Version }}
+  version: {{ . | quote }}
+{{- end }}
+{{- with .Tag }}
+  tag: {{ . | quote }}
+{{- end }}
+{{- with .Commit }}
+  commit: {{ . | quote }}
+{{- end }}
+{{- with .OS }}
+  os: {{ . | quote }}
+{{- end }}
+{{- with .Arch }}
+  arch: {{ . | quote }}
+{{- end }}
+{{- end }}
 `
 
 var tmplFuncs = template.FuncMap{
@@ -0,0 +1,102 @@
+package inspect
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// FieldsFromDescriptor describes message's fields the way ParseProtoDir
+// does, for messages known only by descriptor (e.g. through reflection).
+// Descriptors carry no comments, so descriptions, @required and @example are
+// left empty.
+func FieldsFromDescriptor(message protoreflect.MessageDescriptor) []Field {
+	return fieldsFromDescriptor(message, map[protoreflect.FullName]bool{})
+}
+
+func fieldsFromDescriptor(message protoreflect.MessageDescriptor, seen map[protoreflect.FullName]bool) []Field {
+	if message == nil || seen[message.FullName()] {
+		return nil
+	}
+	nextSeen := make(map[protoreflect.FullName]bool, len(seen)+1)
+	for name := range seen {
+		nextSeen[name] = true
+	}
+	nextSeen[message.FullName()] = true
+
+	fields := message.Fields()
+	out := make([]Field, 0, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		out = append(out, fieldFromDescriptor(fields.Get(i), nextSeen))
+	}
+	return out
+}
+
+func fieldFromDescriptor(field protoreflect.FieldDescriptor, seen map[protoreflect.FullName]bool) Field {
+	out := Field{
+		Name:     string(field.Name()),
+		JSONName: field.JSONName(),
+		Type:     reflectTypeName(field),
+		Number:   int32(field.Number()),
+		Label:    FieldLabelOptional,
+	}
+
+	value := field
+	switch {
+	case field.IsMap():
+		out.Label = FieldLabelMap
+		out.MapKeyType = reflectTypeName(field.MapKey())
+		out.MapValueType = reflectTypeName(field.MapValue())
+		value = field.MapValue()
+	case field.IsList():
+		out.Label = FieldLabelRepeated
+	case field.Cardinality() == protoreflect.Required:
+		out.Label = FieldLabelRequired
+		out.Required = true
+	}
+	// proto3 optional fields sit in a oneof of their own, which is not one.
+	if oneof := field.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+		out.Oneof = string(oneof.Name())
+	}
+
+	if enum := value.Enum(); enum != nil {
+		values := enum.Values()
+		for i := 0; i < values.Len(); i++ {
+			out.EnumValues = append(out.EnumValues, EnumValue{
+				Name:   string(values.Get(i).Name()),
+				Number: int32(values.Get(i).Number()),
+			})
+		}
+	}
+	if message := value.Message(); message != nil {
+		out.NestedFields = fieldsFromDescriptor(message, seen)
+	}
+	return out
+}
+
+func reflectTypeName(field protoreflect.FieldDescriptor) string {
+	switch {
+	case field.IsMap():
+		return fmt.Sprintf("map<%s, %s>", reflectTypeName(field.MapKey()), reflectTypeName(field.MapValue()))
+	case field.Enum() != nil:
+		return string(field.Enum().FullName())
+	case field.Message() != nil:
+		return string(field.Message().FullName())
+	default:
+		return field.Kind().String()
+	}
+}
+
+// MethodFromDescriptor describes method and its request and response
+// messages the way ParseProtoDir does, for methods known only by descriptor.
+func MethodFromDescriptor(method protoreflect.MethodDescriptor) Method {
+	return Method{
+		Name:            string(method.Name()),
+		InputType:       string(method.Input().FullName()),
+		OutputType:      string(method.Output().FullName()),
+		InputFields:     FieldsFromDescriptor(method.Input()),
+		OutputFields:    FieldsFromDescriptor(method.Output()),
+		ClientStreaming: method.IsStreamingClient(),
+		ServerStreaming: method.IsStreamingServer(),
+	}
+}
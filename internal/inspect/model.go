@@ -29,6 +29,7 @@ type Method struct {
 
 type Field struct {
 	Name         string      `json:"name"`
+	JSONName     string      `json:"json_name,omitempty"`
 	Type         string      `json:"type,omitempty"`
 	Number       int32       `json:"number,omitempty"`
 	Description  string      `json:"description,omitempty"`
@@ -39,6 +40,7 @@ type Field struct {
 	EnumValues   []EnumValue `json:"enum_values,omitempty"`
 	Required     bool        `json:"required,omitempty"`
 	Example      string      `json:"example,omitempty"`
+	Oneof        string      `json:"oneof,omitempty"`
 }
 
 type EnumValue struct {
@@ -165,6 +165,7 @@ func (b parserBuilder) buildField(field *desc.FieldDescriptor, seen map[string]b
 	meta := parseCommentBlock(sourceComments(field.GetSourceInfo()))
 	out := Field{
 		Name:        field.GetName(),
+		JSONName:    field.GetJSONName(),
 		Type:        descriptorTypeName(field),
 		Number:      field.GetNumber(),
 		Description: meta.Description,
@@ -172,6 +173,10 @@ func (b parserBuilder) buildField(field *desc.FieldDescriptor, seen map[string]b
 		Required:    meta.Required,
 		Example:     meta.Example,
 	}
+	// proto3 optional fields sit in a oneof of their own, which is not one.
+	if oneof := field.GetOneOf(); oneof != nil && !field.IsProto3Optional() {
+		out.Oneof = oneof.GetName()
+	}
 
 	if field.IsMap() {
 		out.MapKeyType = descriptorTypeName(field.GetMapKeyType())
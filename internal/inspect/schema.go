@@ -0,0 +1,154 @@
+package inspect
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"text/tabwriter"
+)
+
+// JSONSchemaDialect is the $schema of the documents JSONSchema builds.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// RenderSchemaTree prints fields one per line as name, cardinality and
+// type, with the fields of nested messages indented beneath their parent.
+// Enum fields list their values; a message that contains itself is shown
+// once and not expanded again.
+func RenderSchemaTree(fields []Field) string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	writeSchemaTree(tw, fields, 1)
+	_ = tw.Flush()
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func writeSchemaTree(tw *tabwriter.Writer, fields []Field, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, field := range fields {
+		notes := ""
+		if field.Required {
+			notes = "[required]"
+		}
+		if len(field.EnumValues) > 0 {
+			names := make([]string, 0, len(field.EnumValues))
+			for _, value := range field.EnumValues {
+				names = append(names, value.Name)
+			}
+			notes = strings.TrimSpace(notes + " one of " + strings.Join(names, ", "))
+		}
+		fmt.Fprintf(tw, "%s%s\t%s\t%s\n", indent, field.Name, schemaCardinality(field), notes)
+		writeSchemaTree(tw, field.NestedFields, depth+1)
+	}
+}
+
+// schemaCardinality is the label and type of field as written in a proto
+// file; a map's label is already part of its type.
+func schemaCardinality(field Field) string {
+	fieldType := displayFieldType(field.Type)
+	if field.Label == "" || field.Label == FieldLabelMap {
+		return fieldType
+	}
+	return field.Label + " " + fieldType
+}
+
+// JSONSchema describes the protojson form of a message with fields as a
+// JSON Schema object. Properties use the JSON names protojson writes;
+// protojson also reads the proto field names. 64-bit integers may be
+// numbers or strings, since protojson writes them as strings.
+func JSONSchema(fields []Field) map[string]any {
+	properties := make(map[string]any, len(fields))
+	var required []string
+	for _, field := range fields {
+		name := FieldJSONName(field)
+		properties[name] = fieldSchema(field)
+		if field.Required {
+			required = append(required, name)
+		}
+	}
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// FieldJSONName is the name protojson writes field under: its json_name, or
+// for fields described without one, the lowerCamelCase protoc derives.
+func FieldJSONName(field Field) string {
+	if field.JSONName != "" {
+		return field.JSONName
+	}
+	var b strings.Builder
+	upper := false
+	for _, r := range field.Name {
+		switch {
+		case r == '_':
+			upper = true
+		case upper && 'a' <= r && r <= 'z':
+			b.WriteRune(r - 'a' + 'A')
+			upper = false
+		default:
+			b.WriteRune(r)
+			upper = false
+		}
+	}
+	return b.String()
+}
+
+func fieldSchema(field Field) map[string]any {
+	switch field.Label {
+	case FieldLabelMap:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": typeSchema(field.MapValueType, field.NestedFields, field.EnumValues),
+		}
+	case FieldLabelRepeated:
+		return map[string]any{
+			"type":  "array",
+			"items": typeSchema(field.Type, field.NestedFields, field.EnumValues),
+		}
+	default:
+		return typeSchema(field.Type, field.NestedFields, field.EnumValues)
+	}
+}
+
+func typeSchema(typeName string, nested []Field, enumValues []EnumValue) map[string]any {
+	if len(enumValues) > 0 {
+		names := make([]string, 0, len(enumValues))
+		for _, value := range enumValues {
+			names = append(names, value.Name)
+		}
+		return map[string]any{"type": "string", "enum": names}
+	}
+
+	name := strings.TrimPrefix(strings.TrimSpace(typeName), ".")
+	switch name {
+	case "string":
+		return map[string]any{"type": "string"}
+	case "bytes":
+		return map[string]any{"type": "string", "contentEncoding": "base64"}
+	case "bool":
+		return map[string]any{"type": "boolean"}
+	case "int32", "uint32", "sint32", "fixed32", "sfixed32":
+		return map[string]any{"type": "integer"}
+	case "int64", "uint64", "sint64", "fixed64", "sfixed64":
+		return map[string]any{"type": []string{"integer", "string"}}
+	case "float", "double":
+		return map[string]any{"type": "number"}
+	}
+	if wkt, ok := wellKnownTypes[name]; ok {
+		return maps.Clone(wkt.schema)
+	}
+
+	schema := JSONSchema(nested)
+	schema["title"] = name
+	return schema
+}
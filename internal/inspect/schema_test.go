@@ -0,0 +1,81 @@
+package inspect
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONSchemaMapsProtoTypes(t *testing.T) {
+	fields := []Field{
+		{Name: "package", Type: "string", Label: FieldLabelOptional, Required: true},
+		{Name: "limit", Type: "int64", Label: FieldLabelOptional},
+		{Name: "tags", Type: "string", Label: FieldLabelRepeated},
+		{Name: "labels", Type: "map<string, int32>", Label: FieldLabelMap, MapKeyType: "string", MapValueType: "int32"},
+		{Name: "mode", Type: "echo.v1.Mode", Label: FieldLabelOptional, EnumValues: []EnumValue{{Name: "MODE_UNSPECIFIED"}, {Name: "MODE_UPPER", Number: 1}}},
+		{Name: "since", Type: "google.protobuf.Timestamp", Label: FieldLabelOptional},
+		{Name: "owner", Type: "example.v1.Owner", Label: FieldLabelOptional, NestedFields: []Field{
+			{Name: "active", Type: "bool", Label: FieldLabelOptional},
+		}},
+	}
+
+	out, err := json.Marshal(JSONSchema(fields))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"properties":{` +
+		`"labels":{"additionalProperties":{"type":"integer"},"type":"object"},` +
+		`"limit":{"type":["integer","string"]},` +
+		`"mode":{"enum":["MODE_UNSPECIFIED","MODE_UPPER"],"type":"string"},` +
+		`"owner":{"properties":{"active":{"type":"boolean"}},"title":"example.v1.Owner","type":"object"},` +
+		`"package":{"type":"string"},` +
+		`"since":{"format":"date-time","type":"string"},` +
+		`"tags":{"items":{"type":"string"},"type":"array"}},` +
+		`"required":["package"],"type":"object"}`
+	if string(out) != want {
+		t.Fatalf("JSONSchema = %s\nwant         %s", out, want)
+	}
+}
+
+func TestJSONSchemaUsesJSONNamesAndWellKnownTypes(t *testing.T) {
+	fields := []Field{
+		{Name: "update_mask", Type: "google.protobuf.FieldMask", Label: FieldLabelOptional, Required: true},
+		{Name: "page_size", JSONName: "pageSize", Type: "google.protobuf.Int64Value", Label: FieldLabelOptional},
+		{Name: "items", Type: "google.protobuf.ListValue", Label: FieldLabelOptional},
+		{Name: "detail", Type: ".google.protobuf.Any", Label: FieldLabelOptional, NestedFields: []Field{
+			{Name: "type_url", Type: "string", Label: FieldLabelOptional},
+		}},
+	}
+
+	out, err := json.Marshal(JSONSchema(fields))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"properties":{` +
+		`"detail":{"properties":{"@type":{"type":"string"}},"required":["@type"],"type":"object"},` +
+		`"items":{"type":"array"},` +
+		`"pageSize":{"type":["integer","string"]},` +
+		`"updateMask":{"type":"string"}},` +
+		`"required":["updateMask"],"type":"object"}`
+	if string(out) != want {
+		t.Fatalf("JSONSchema = %s\nwant         %s", out, want)
+	}
+}
+
+func TestRenderSchemaTreeIndentsNestedFields(t *testing.T) {
+	fields := []Field{
+		{Name: "id", Type: "string", Label: FieldLabelOptional, Required: true},
+		{Name: "owner", Type: "example.v1.Owner", Label: FieldLabelOptional, NestedFields: []Field{
+			{Name: "names", Type: "string", Label: FieldLabelRepeated},
+		}},
+		{Name: "labels", Type: "map<string, string>", Label: FieldLabelMap},
+	}
+
+	want := "" +
+		"  id       optional string      [required]\n" +
+		"  owner    optional Owner\n" +
+		"    names  repeated string\n" +
+		"  labels   map<string, string>\n"
+	if got := RenderSchemaTree(fields); got != want {
+		t.Fatalf("RenderSchemaTree =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,170 @@
+package inspect
+
+import (
+	"encoding/json"
+	"maps"
+	"strings"
+)
+
+// Skeleton builds an example request for fields. A field with an @example
+// uses it; otherwise it gets a placeholder for its type, in the JSON form
+// protojson reads, which for well-known types such as Timestamp is not an
+// object. Repeated fields hold a single element, maps a single entry, and
+// enums their first non-zero value. A request sets at most one member of a
+// oneof, so only the first is included.
+func Skeleton(fields []Field) map[string]any {
+	out := make(map[string]any, len(fields))
+	set := make(map[string]bool)
+	for _, field := range fields {
+		if field.Oneof != "" {
+			if set[field.Oneof] {
+				continue
+			}
+			set[field.Oneof] = true
+		}
+		out[field.Name] = fieldPlaceholder(field)
+	}
+	return out
+}
+
+// Oneofs groups the members of each oneof among fields by oneof name, in
+// declaration order.
+func Oneofs(fields []Field) (names []string, members map[string][]string) {
+	members = make(map[string][]string)
+	for _, field := range fields {
+		if field.Oneof == "" {
+			continue
+		}
+		if _, ok := members[field.Oneof]; !ok {
+			names = append(names, field.Oneof)
+		}
+		members[field.Oneof] = append(members[field.Oneof], field.Name)
+	}
+	return names, members
+}
+
+// RequiredFields returns the fields marked @required.
+func RequiredFields(fields []Field) []Field {
+	var out []Field
+	for _, field := range fields {
+		if field.Required {
+			out = append(out, field)
+		}
+	}
+	return out
+}
+
+func fieldPlaceholder(field Field) any {
+	if example, ok := parseFieldExample(field.Example); ok {
+		return example
+	}
+	switch field.Label {
+	case FieldLabelMap:
+		return map[string]any{
+			mapKeyPlaceholder(field.MapKeyType): typePlaceholder(field.MapValueType, field.NestedFields, field.EnumValues),
+		}
+	case FieldLabelRepeated:
+		return []any{typePlaceholder(field.Type, field.NestedFields, field.EnumValues)}
+	default:
+		return typePlaceholder(field.Type, field.NestedFields, field.EnumValues)
+	}
+}
+
+func typePlaceholder(typeName string, nested []Field, enumValues []EnumValue) any {
+	if len(enumValues) > 0 {
+		for _, value := range enumValues {
+			if value.Number != 0 {
+				return value.Name
+			}
+		}
+		return enumValues[0].Name
+	}
+	if placeholder, ok := wellKnownPlaceholder(typeName); ok {
+		return placeholder
+	}
+	if len(nested) > 0 {
+		return Skeleton(nested)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(typeName)) {
+	case "string", "bytes":
+		return ""
+	case "int32", "uint32", "sint32", "fixed32", "sfixed32", "float", "double":
+		return 0
+	case "int64", "uint64", "sint64", "fixed64", "sfixed64":
+		// protojson writes 64-bit integers as strings.
+		return "0"
+	case "bool":
+		return false
+	default:
+		return map[string]any{}
+	}
+}
+
+// wellKnownType is how protojson writes a well-known type: as something
+// other than an object of its fields, or for Any, with an "@type" member.
+type wellKnownType struct {
+	placeholder any
+	schema      map[string]any
+}
+
+// wellKnownTypes holds the zero value and JSON Schema of each well-known
+// type, for Skeleton and JSONSchema alike.
+var wellKnownTypes = map[string]wellKnownType{
+	"google.protobuf.Timestamp": {"1970-01-01T00:00:00Z", map[string]any{"type": "string", "format": "date-time"}},
+	"google.protobuf.Duration":  {"0s", map[string]any{"type": "string", "pattern": `^-?[0-9]+(\.[0-9]+)?s$`}},
+	"google.protobuf.FieldMask": {"", map[string]any{"type": "string"}},
+	"google.protobuf.Struct":    {map[string]any{}, map[string]any{"type": "object"}},
+	"google.protobuf.ListValue": {[]any{}, map[string]any{"type": "array"}},
+	"google.protobuf.Value":     {nil, map[string]any{}},
+	"google.protobuf.Any": {map[string]any{"@type": ""}, map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"@type": map[string]any{"type": "string"}},
+		"required":   []string{"@type"},
+	}},
+	"google.protobuf.DoubleValue": {0, map[string]any{"type": "number"}},
+	"google.protobuf.FloatValue":  {0, map[string]any{"type": "number"}},
+	"google.protobuf.Int32Value":  {0, map[string]any{"type": "integer"}},
+	"google.protobuf.UInt32Value": {0, map[string]any{"type": "integer"}},
+	"google.protobuf.Int64Value":  {"0", map[string]any{"type": []string{"integer", "string"}}},
+	"google.protobuf.UInt64Value": {"0", map[string]any{"type": []string{"integer", "string"}}},
+	"google.protobuf.BoolValue":   {false, map[string]any{"type": "boolean"}},
+	"google.protobuf.StringValue": {"", map[string]any{"type": "string"}},
+	"google.protobuf.BytesValue":  {"", map[string]any{"type": "string", "contentEncoding": "base64"}},
+}
+
+// wellKnownPlaceholder is the zero value of a well-known type that protojson
+// writes as something other than an object of its fields.
+func wellKnownPlaceholder(typeName string) (any, bool) {
+	wkt, ok := wellKnownTypes[strings.TrimPrefix(strings.TrimSpace(typeName), ".")]
+	switch placeholder := wkt.placeholder.(type) {
+	case map[string]any:
+		return maps.Clone(placeholder), ok
+	case []any:
+		return []any{}, ok
+	}
+	return wkt.placeholder, ok
+}
+
+func mapKeyPlaceholder(keyType string) string {
+	switch strings.ToLower(strings.TrimSpace(keyType)) {
+	case "string":
+		return "key"
+	case "bool":
+		return "false"
+	default:
+		return "0"
+	}
+}
+
+func parseFieldExample(raw string) (any, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, false
+	}
+	var value any
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
@@ -0,0 +1,114 @@
+package inspect
+
+import (
+	"encoding/json"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	_ "google.golang.org/protobuf/types/known/durationpb"
+	_ "google.golang.org/protobuf/types/known/structpb"
+	_ "google.golang.org/protobuf/types/known/timestamppb"
+	_ "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestSkeletonUsesExamplesAndPlaceholders(t *testing.T) {
+	fields := []Field{
+		{Name: "package", Type: "string", Label: FieldLabelOptional, Required: true, Example: `"./cmd/rob"`},
+		{Name: "tags", Type: "string", Label: FieldLabelRepeated},
+		{Name: "limit", Type: "int64", Label: FieldLabelOptional},
+		{Name: "mode", Type: "echo.v1.Mode", Label: FieldLabelOptional, EnumValues: []EnumValue{
+			{Name: "MODE_UNSPECIFIED", Number: 0},
+			{Name: "MODE_UPPER", Number: 1},
+		}},
+		{Name: "labels", Type: "map<string, string>", Label: FieldLabelMap, MapKeyType: "string", MapValueType: "string"},
+		{Name: "owner", Type: "example.v1.Owner", Label: FieldLabelOptional, NestedFields: []Field{
+			{Name: "active", Type: "bool", Label: FieldLabelOptional},
+		}},
+	}
+
+	out, err := json.Marshal(Skeleton(fields))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"labels":{"key":""},"limit":"0","mode":"MODE_UPPER","owner":{"active":false},"package":"./cmd/rob","tags":[""]}`
+	if string(out) != want {
+		t.Fatalf("Skeleton = %s\nwant      %s", out, want)
+	}
+
+	required := RequiredFields(fields)
+	if len(required) != 1 || required[0].Name != "package" {
+		t.Fatalf("RequiredFields = %+v, want [package]", required)
+	}
+}
+
+func TestSkeletonRoundTripsThroughProtojson(t *testing.T) {
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string, oneof *int32) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:       proto.String(name),
+			JsonName:   proto.String(name),
+			Number:     proto.Int32(number),
+			Label:      optional,
+			Type:       typ.Enum(),
+			OneofIndex: oneof,
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	message := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("skeleton_test.proto"),
+		Package: proto.String("skeleton.v1"),
+		Syntax:  proto.String("proto3"),
+		Dependency: []string{
+			"google/protobuf/duration.proto",
+			"google/protobuf/struct.proto",
+			"google/protobuf/timestamp.proto",
+			"google/protobuf/wrappers.proto",
+		},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Request"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("at", 1, message, ".google.protobuf.Timestamp", nil),
+				field("within", 2, message, ".google.protobuf.Duration", nil),
+				field("extra", 3, message, ".google.protobuf.Struct", nil),
+				field("any", 4, message, ".google.protobuf.Value", nil),
+				field("limit", 5, message, ".google.protobuf.Int64Value", nil),
+				field("name", 6, message, ".google.protobuf.StringValue", nil),
+				field("enabled", 7, message, ".google.protobuf.BoolValue", nil),
+				field("text", 8, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", proto.Int32(0)),
+				field("count", 9, descriptorpb.FieldDescriptorProto_TYPE_INT32, "", proto.Int32(0)),
+			},
+			OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: proto.String("payload")}},
+		}},
+	}, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := file.Messages().ByName("Request")
+
+	fields := FieldsFromDescriptor(request)
+	out, err := json.Marshal(Skeleton(fields))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := protojson.Unmarshal(out, dynamicpb.NewMessage(request)); err != nil {
+		t.Fatalf("protojson rejects the skeleton %s: %v", out, err)
+	}
+	want := `{"any":null,"at":"1970-01-01T00:00:00Z","enabled":false,"extra":{},"limit":"0","name":"","text":"","within":"0s"}`
+	if string(out) != want {
+		t.Fatalf("Skeleton = %s\nwant      %s", out, want)
+	}
+
+	names, members := Oneofs(fields)
+	if len(names) != 1 || names[0] != "payload" || len(members["payload"]) != 2 || members["payload"][1] != "count" {
+		t.Fatalf("Oneofs = %v, %v; want payload: text, count", names, members)
+	}
+}
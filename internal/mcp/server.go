@@ -8,11 +8,11 @@ import (
 	"io"
 	"sort"
 	"strings"
-	"time"
 
 	sdkconnect "github.com/organic-programming/go-holons/pkg/connect"
 	inspectpkg "github.com/organic-programming/grace-op/internal/inspect"
 	toolspkg "github.com/organic-programming/grace-op/internal/tools"
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/dynamicpb"
 )
@@ -256,7 +256,7 @@ func (s *Server) handleToolCall(ctx context.Context, params json.RawMessage) (ma
 		args = []byte("{}")
 	}
 
-	callCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	callCtx, cancel := context.WithTimeout(grpcclient.WithRequestID(ctx, grpcclient.NewRequestID()), grpcclient.Timeout())
 	defer cancel()
 
 	conn, err := sdkconnect.Connect(binding.slug)
@@ -273,7 +273,7 @@ func (s *Server) handleToolCall(ctx context.Context, params json.RawMessage) (ma
 
 	inputMsg := dynamicpb.NewMessage(binding.method.Descriptor.Input())
 	if len(args) > 0 && string(args) != "{}" {
-		if err := protojson.Unmarshal(args, inputMsg); err != nil {
+		if err := grpcclient.ParseInput(args, inputMsg); err != nil {
 			return map[string]any{
 				"content": []textContent{{
 					Type: "text",
@@ -285,7 +285,7 @@ func (s *Server) handleToolCall(ctx context.Context, params json.RawMessage) (ma
 	}
 
 	outputMsg := dynamicpb.NewMessage(binding.method.Descriptor.Output())
-	if err := conn.Invoke(callCtx, binding.method.FullMethod(), inputMsg, outputMsg); err != nil {
+	if err := conn.Invoke(callCtx, binding.method.FullMethod(), inputMsg, outputMsg, grpcclient.CallOptions()...); err != nil {
 		return map[string]any{
 			"content": []textContent{{
 				Type: "text",
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// idleTracker records RPC activity so a server can stop itself once nothing
+// has happened for a while. An RPC in flight always counts as activity.
+type idleTracker struct {
+	mu       sync.Mutex
+	active   int
+	lastSeen time.Time
+}
+
+func newIdleTracker() *idleTracker {
+	return &idleTracker{lastSeen: time.Now()}
+}
+
+func (t *idleTracker) begin() {
+	t.mu.Lock()
+	t.active++
+	t.lastSeen = time.Now()
+	t.mu.Unlock()
+}
+
+func (t *idleTracker) end() {
+	t.mu.Lock()
+	t.active--
+	t.lastSeen = time.Now()
+	t.mu.Unlock()
+}
+
+// idleFor reports how long the server has had no RPC in flight.
+func (t *idleTracker) idleFor(now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.active > 0 {
+		return 0
+	}
+	return now.Sub(t.lastSeen)
+}
+
+func (t *idleTracker) unaryInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	t.begin()
+	defer t.end()
+	return handler(ctx, req)
+}
+
+func (t *idleTracker) streamInterceptor(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	t.begin()
+	defer t.end()
+	return handler(srv, ss)
+}
+
+// watch calls stop once the tracker has been idle for timeout, or returns
+// when done is closed.
+func (t *idleTracker) watch(timeout time.Duration, done <-chan struct{}, stop func()) {
+	interval := timeout / 4
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			if t.idleFor(now) >= timeout {
+				stop()
+				return
+			}
+		}
+	}
+}
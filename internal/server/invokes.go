@@ -0,0 +1,244 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errInvokeCancelled is the cause of an invocation's context once
+// CancelInvoke has stopped it.
+var errInvokeCancelled = errors.New("cancelled by CancelInvoke")
+
+// errInvokeTimedOut is the cause of an invocation's context once its
+// timeout_seconds have passed.
+var errInvokeTimedOut = errors.New("timed out")
+
+// invokeKillGrace is how long a stopped command's process group has between
+// SIGTERM and SIGKILL.
+const invokeKillGrace = 2 * time.Second
+
+// invokeRegistry tracks the Invoke and InvokeStream calls in flight by ID,
+// so CancelInvoke can stop one from another connection. The zero value is
+// ready to use.
+type invokeRegistry struct {
+	mu     sync.Mutex
+	active map[string]*activeInvoke
+}
+
+type activeInvoke struct {
+	holon  string
+	args   []string
+	cancel context.CancelCauseFunc
+}
+
+// start registers an invocation under id, or a fresh ID when id is empty.
+// The returned context is cancelled with ctx, by cancel(id) or, when timeout
+// is positive, once it has passed; release unregisters the invocation and
+// must be called once it is done.
+func (r *invokeRegistry) start(ctx context.Context, id, holon string, args []string, timeout time.Duration) (context.Context, string, func(), error) {
+	if id == "" {
+		id = uuid.NewString()
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	if timeout > 0 {
+		var stop context.CancelFunc
+		ctx, stop = context.WithTimeoutCause(ctx, timeout, fmt.Errorf("%w after %s", errInvokeTimedOut, timeout))
+		parent := cancel
+		cancel = func(cause error) {
+			parent(cause)
+			stop()
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.active[id]; ok {
+		cancel(nil)
+		return nil, "", nil, status.Errorf(codes.AlreadyExists, "invoke %q is already in flight", id)
+	}
+	if r.active == nil {
+		r.active = make(map[string]*activeInvoke)
+	}
+	invoke := &activeInvoke{holon: holon, args: args, cancel: cancel}
+	r.active[id] = invoke
+
+	release := func() {
+		r.mu.Lock()
+		if r.active[id] == invoke {
+			delete(r.active, id)
+		}
+		r.mu.Unlock()
+		cancel(nil)
+	}
+	return ctx, id, release, nil
+}
+
+// cancel stops the invocation registered under id and reports what it was
+// running.
+func (r *invokeRegistry) cancel(id string) (*activeInvoke, bool) {
+	r.mu.Lock()
+	invoke, ok := r.active[id]
+	r.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	invoke.cancel(errInvokeCancelled)
+	return invoke, true
+}
+
+// invokeContextError is the status an invocation whose context is done
+// fails with: CANCELLED naming the ID when CancelInvoke stopped it,
+// DEADLINE_EXCEEDED naming the ID and the timeout when timeout_seconds
+// passed, and the context's own error otherwise.
+func invokeContextError(ctx context.Context, id string) error {
+	cause := context.Cause(ctx)
+	switch {
+	case errors.Is(cause, errInvokeCancelled):
+		return status.Error(codes.Canceled, fmt.Sprintf("invoke %s %v", id, errInvokeCancelled))
+	case errors.Is(cause, errInvokeTimedOut):
+		return status.Error(codes.DeadlineExceeded, fmt.Sprintf("invoke %s %v (timeout_seconds); its process group was killed", id, cause))
+	}
+	return status.FromContextError(ctx.Err()).Err()
+}
+
+// invokeCommand is the command of an Invoke or InvokeStream, bound to ctx.
+// It leads its own process group, so when ctx is done the processes it
+// started are stopped with it: the group gets SIGTERM, then SIGKILL once
+// invokeKillGrace has passed, unless the command was reaped first.
+func invokeCommand(ctx context.Context, binary string, args []string, dir string, env []string) *invocation {
+	cmd := &invocation{Cmd: exec.CommandContext(ctx, binary, args...)}
+	cmd.Dir, cmd.Env = dir, env
+	startProcessGroup(cmd.Cmd)
+	cmd.Cancel = func() error {
+		process := cmd.Process
+		cmd.mu.Lock()
+		cmd.kill = time.AfterFunc(invokeKillGrace, func() {
+			cmd.mu.Lock()
+			defer cmd.mu.Unlock()
+			if !cmd.reaped {
+				_ = signalProcessGroup(process, syscall.SIGKILL)
+			}
+		})
+		cmd.mu.Unlock()
+		return signalProcessGroup(process, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = invokeKillGrace + invokeWaitDelay
+	return cmd
+}
+
+// invocation is an invokeCommand's command. Once Wait has reaped it, its
+// process group ID may be reused by an unrelated process, so the SIGKILL
+// still pending from a cancellation is called off.
+type invocation struct {
+	*exec.Cmd
+
+	mu     sync.Mutex
+	reaped bool
+	kill   *time.Timer
+}
+
+// Run starts the command and waits for it, as exec.Cmd.Run does.
+func (c *invocation) Run() error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+	return c.Wait()
+}
+
+// Wait waits for the command as exec.Cmd.Wait does, then calls off any
+// pending SIGKILL of its process group.
+func (c *invocation) Wait() error {
+	err := c.Cmd.Wait()
+	c.mu.Lock()
+	c.reaped = true
+	if c.kill != nil {
+		c.kill.Stop()
+	}
+	c.mu.Unlock()
+	return err
+}
+
+// InvokeEnv is the environment Invoke runs a command with: the current
+// process's environment with env merged onto it, or env alone when clear is
+// set. A nil result, when there is nothing to change, inherits the
+// environment as it is.
+func InvokeEnv(env map[string]string, clear bool) []string {
+	if len(env) == 0 && !clear {
+		return nil
+	}
+	merged := []string{}
+	if !clear {
+		merged = os.Environ()
+	}
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	// exec keeps the last value of a repeated variable, so env wins.
+	for _, key := range keys {
+		merged = append(merged, key+"="+env[key])
+	}
+	return merged
+}
+
+// invokeEnv checks an InvokeRequest's environment variable names and
+// returns the command's environment, as InvokeEnv does.
+func invokeEnv(env map[string]string, clear bool) ([]string, error) {
+	for key := range env {
+		if key == "" || strings.ContainsAny(key, "=\x00") {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid environment variable name %q", key)
+		}
+	}
+	return InvokeEnv(env, clear), nil
+}
+
+// invokeDir resolves an InvokeRequest's working directory against root,
+// the server's sandbox root, or its working directory when root is empty.
+// Symbolic links are followed before the check, so none can lead out of
+// the sandbox. An empty dir keeps the server's working directory.
+func invokeDir(root, dir string) (string, error) {
+	if dir == "" {
+		return "", nil
+	}
+	if root == "" {
+		root = "."
+	}
+	root, err := filepath.Abs(root)
+	if err == nil {
+		root, err = filepath.EvalSymlinks(root)
+	}
+	if err != nil {
+		return "", status.Errorf(codes.FailedPrecondition, "sandbox root: %v", err)
+	}
+	path := dir
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "working directory %q: %v", dir, err)
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", status.Errorf(codes.InvalidArgument, "working directory %q is outside the sandbox root %s", dir, root)
+	}
+	if info, err := os.Stat(resolved); err != nil || !info.IsDir() {
+		return "", status.Errorf(codes.InvalidArgument, "working directory %q is not a directory", dir)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// requestLimiter counts finished RPCs and calls stop once max of them have
+// completed. Reflection and health RPCs are not counted: clients use them to
+// find methods and wait for readiness before making the call that matters.
+// Calls that arrive once every slot is taken are refused with Unavailable.
+type requestLimiter struct {
+	max  int
+	stop func()
+
+	mu       sync.Mutex
+	started  int
+	finished int
+	once     sync.Once
+}
+
+func newRequestLimiter(max int, stop func()) *requestLimiter {
+	return &requestLimiter{max: max, stop: stop}
+}
+
+func (l *requestLimiter) begin(method string) (counted bool, err error) {
+	if isInfrastructureMethod(method) {
+		return false, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.started >= l.max {
+		return false, status.Errorf(codes.Unavailable, "server is shutting down after %d request(s)", l.max)
+	}
+	l.started++
+	return true, nil
+}
+
+// end records a finished RPC. The stop runs in its own goroutine because a
+// graceful stop waits for this RPC, whose response is only written once the
+// interceptor returns.
+func (l *requestLimiter) end() {
+	l.mu.Lock()
+	l.finished++
+	done := l.finished >= l.max
+	l.mu.Unlock()
+	if done {
+		l.once.Do(func() { go l.stop() })
+	}
+}
+
+func (l *requestLimiter) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	counted, err := l.begin(info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	if counted {
+		defer l.end()
+	}
+	return handler(ctx, req)
+}
+
+func (l *requestLimiter) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	counted, err := l.begin(info.FullMethod)
+	if err != nil {
+		return err
+	}
+	if counted {
+		defer l.end()
+	}
+	return handler(srv, ss)
+}
+
+func isInfrastructureMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/grpc.reflection.") || strings.HasPrefix(fullMethod, "/grpc.health.v1.")
+}
@@ -0,0 +1,19 @@
+//go:build !unix
+
+package server
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// startProcessGroup leaves cmd as it is: without Unix process groups, only
+// the command itself can be signalled.
+func startProcessGroup(cmd *exec.Cmd) {}
+
+// signalProcessGroup kills p, the closest this platform has to signalling
+// its group.
+func signalProcessGroup(p *os.Process, sig syscall.Signal) error {
+	return p.Kill()
+}
@@ -0,0 +1,26 @@
+//go:build unix
+
+package server
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// startProcessGroup makes cmd lead a process group of its own, which every
+// process it starts joins unless it asks otherwise.
+func startProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// signalProcessGroup sends sig to the process group p leads. A group with
+// no process left is os.ErrProcessDone.
+func signalProcessGroup(p *os.Process, sig syscall.Signal) error {
+	err := syscall.Kill(-p.Pid, sig)
+	if errors.Is(err, syscall.ESRCH) {
+		return os.ErrProcessDone
+	}
+	return err
+}
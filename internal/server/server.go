@@ -6,8 +6,15 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
+	"os"
 	"os/exec"
+	"os/signal"
+	"runtime"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/organic-programming/go-holons/pkg/transport"
 	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
@@ -16,12 +23,35 @@ import (
 	"github.com/organic-programming/grace-op/internal/identity"
 
 	"google.golang.org/grpc"
+	channelzservice "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/codes"
+	// Registers gzip, so clients calling with op grpc --compress gzip are
+	// understood and answered in kind.
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	grpcReflection "google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
+// invokeWaitDelay bounds how long Invoke and InvokeStream wait, after a
+// cancelled command's process group was killed, for its output pipes to
+// close, which a grandchild that left the group could otherwise hold open
+// forever.
+const invokeWaitDelay = time.Second
+
+// gracefulStopTimeout bounds how long a stopping server waits for in-flight
+// RPCs before it closes their connections.
+const gracefulStopTimeout = 10 * time.Second
+
 // Server implements the OPService gRPC interface.
 type Server struct {
 	opv1.UnimplementedOPServiceServer
+
+	invokes invokeRegistry
+	// sandboxRoot is the directory an Invoke's working_dir must stay in;
+	// empty means the server's working directory.
+	sandboxRoot string
 }
 
 // --- OP-native RPCs ---
@@ -38,8 +68,16 @@ func (s *Server) Discover(ctx context.Context, req *opv1.DiscoverRequest) (*opv1
 		return nil, err
 	}
 
+	filter := holons.IdentityFilter{
+		Langs:    req.GetLangs(),
+		Clades:   req.GetClades(),
+		Statuses: req.GetStatuses(),
+	}
 	entries := make([]*opv1.HolonEntry, 0, len(localHolons))
 	for _, h := range localHolons {
+		if !filter.Match(h.Identity.Lang, h.Identity.Clade, h.Identity.Status) {
+			continue
+		}
 		entries = append(entries, &opv1.HolonEntry{
 			Identity:     toProto(h.Identity),
 			Origin:       h.Origin,
@@ -64,14 +102,35 @@ func (s *Server) Invoke(ctx context.Context, req *opv1.InvokeRequest) (*opv1.Inv
 			Stderr:   fmt.Sprintf("holon %q not found", req.Holon),
 		}, nil
 	}
+	dir, err := invokeDir(s.sandboxRoot, req.GetWorkingDir())
+	if err != nil {
+		return nil, err
+	}
+	env, err := invokeEnv(req.GetEnv(), req.GetClearEnv())
+	if err != nil {
+		return nil, err
+	}
+
+	// The call's context bounds the command: a client that cancels, or
+	// whose deadline passes, kills it, and so do CancelInvoke and
+	// timeout_seconds.
+	ctx, id, release, err := s.invokes.start(ctx, req.GetInvokeId(), req.GetHolon(), req.GetArgs(), invokeTimeout(req))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	log.Printf("invoke %s: %s %s", id, req.GetHolon(), strings.Join(req.GetArgs(), " "))
 
-	cmd := exec.CommandContext(ctx, binary, req.Args...)
+	cmd := invokeCommand(ctx, binary, req.GetArgs(), dir, env)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	exitCode := int32(0)
 	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, invokeContextError(ctx, id)
+		}
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			exitCode = int32(exitErr.ExitCode())
 		} else {
@@ -83,9 +142,112 @@ func (s *Server) Invoke(ctx context.Context, req *opv1.InvokeRequest) (*opv1.Inv
 		ExitCode: exitCode,
 		Stdout:   stdout.String(),
 		Stderr:   stderr.String(),
+		InvokeId: id,
 	}, nil
 }
 
+// InvokeStream dispatches a command to a holon by name, sending its output
+// as it is produced. The call's context bounds the command, so a client
+// that cancels kills it; CancelInvoke and timeout_seconds do too. Every
+// chunk carries the invocation's ID, and the first, sent once the command
+// has started, only that.
+func (s *Server) InvokeStream(req *opv1.InvokeRequest, stream opv1.OPService_InvokeStreamServer) error {
+	var mu sync.Mutex
+	id := ""
+	send := func(chunk *opv1.InvokeChunk) error {
+		mu.Lock()
+		defer mu.Unlock()
+		chunk.InvokeId = id
+		return stream.Send(chunk)
+	}
+	exit := func(code int32) error {
+		return send(&opv1.InvokeChunk{Body: &opv1.InvokeChunk_ExitCode{ExitCode: code}})
+	}
+
+	binary, err := holons.ResolveBinary(req.GetHolon())
+	if err != nil {
+		msg := fmt.Sprintf("holon %q not found", req.GetHolon())
+		if err := send(&opv1.InvokeChunk{Stream: opv1.InvokeChunk_STDERR, Body: &opv1.InvokeChunk_Data{Data: []byte(msg)}}); err != nil {
+			return err
+		}
+		return exit(1)
+	}
+	dir, err := invokeDir(s.sandboxRoot, req.GetWorkingDir())
+	if err != nil {
+		return err
+	}
+	env, err := invokeEnv(req.GetEnv(), req.GetClearEnv())
+	if err != nil {
+		return err
+	}
+
+	ctx, id, release, err := s.invokes.start(stream.Context(), req.GetInvokeId(), req.GetHolon(), req.GetArgs(), invokeTimeout(req))
+	if err != nil {
+		return err
+	}
+	defer release()
+	log.Printf("invoke %s: %s %s", id, req.GetHolon(), strings.Join(req.GetArgs(), " "))
+
+	cmd := invokeCommand(ctx, binary, req.GetArgs(), dir, env)
+	cmd.Stdout = &chunkWriter{stream: opv1.InvokeChunk_STDOUT, send: send}
+	cmd.Stderr = &chunkWriter{stream: opv1.InvokeChunk_STDERR, send: send}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", req.GetHolon(), err)
+	}
+	if err := send(&opv1.InvokeChunk{}); err != nil {
+		_ = cmd.Cancel()
+		_ = cmd.Wait()
+		return err
+	}
+
+	exitCode := int32(0)
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return invokeContextError(ctx, id)
+		}
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitCode = int32(exitErr.ExitCode())
+		} else {
+			return fmt.Errorf("failed to run %s: %w", req.GetHolon(), err)
+		}
+	}
+	return exit(exitCode)
+}
+
+// invokeTimeout is an InvokeRequest's timeout_seconds as a duration.
+func invokeTimeout(req *opv1.InvokeRequest) time.Duration {
+	return time.Duration(req.GetTimeoutSeconds()) * time.Second
+}
+
+// CancelInvoke kills the command of an Invoke or InvokeStream in flight.
+func (s *Server) CancelInvoke(ctx context.Context, req *opv1.CancelInvokeRequest) (*opv1.CancelInvokeResponse, error) {
+	id := strings.TrimSpace(req.GetInvokeId())
+	if id == "" {
+		return nil, status.Error(codes.InvalidArgument, "invoke_id is required")
+	}
+	invoke, ok := s.invokes.cancel(id)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no invoke %q in flight", id)
+	}
+	log.Printf("invoke %s: cancelled", id)
+	return &opv1.CancelInvokeResponse{Holon: invoke.holon, Args: invoke.args}, nil
+}
+
+// chunkWriter sends each write of a command's output as an InvokeChunk.
+type chunkWriter struct {
+	stream opv1.InvokeChunk_Stream
+	send   func(*opv1.InvokeChunk) error
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	// Send marshals the chunk before returning, so p is not retained.
+	if err := w.send(&opv1.InvokeChunk{Stream: w.stream, Body: &opv1.InvokeChunk_Data{Data: p}}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
 // --- Promoted identity RPCs ---
 
 // CreateIdentity creates a new holon identity.
@@ -99,7 +261,14 @@ func (s *Server) ListIdentities(ctx context.Context, req *opv1.ListIdentitiesReq
 	if req != nil && req.GetRootDir() != "" {
 		root = req.GetRootDir()
 	}
-	return who.List(root)
+	resp, err := who.List(root)
+	if err != nil {
+		return nil, err
+	}
+	if err := who.MaskEntries(resp.GetEntries(), req.GetFieldMask()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return resp, nil
 }
 
 // ShowIdentity retrieves a holon's identity by UUID.
@@ -107,33 +276,252 @@ func (s *Server) ShowIdentity(ctx context.Context, req *opv1.ShowIdentityRequest
 	if req == nil {
 		return nil, fmt.Errorf("uuid is required")
 	}
-	return who.Show(req.GetUuid())
+	resp, err := who.Show(req.GetUuid())
+	if err != nil {
+		return nil, err
+	}
+	if err := who.MaskIdentity(resp.GetIdentity(), req.GetFieldMask()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return resp, nil
+}
+
+// DeleteIdentity removes a local holon's identity file, or with purge its
+// directory.
+func (s *Server) DeleteIdentity(ctx context.Context, req *opv1.DeleteIdentityRequest) (*opv1.DeleteIdentityResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("uuid is required")
+	}
+	return who.Delete(req)
+}
+
+// ServeOptions configures ListenAndServeWithOptions.
+type ServeOptions struct {
+	// Reflect registers the gRPC reflection service.
+	Reflect bool
+	// Health registers the standard grpc.health.v1 service, reporting
+	// SERVING until the server starts a graceful stop. See RegisterHealth.
+	Health bool
+	// IdleTimeout stops the server once no RPC has been in flight for this
+	// long. Zero keeps it running until the listener closes.
+	IdleTimeout time.Duration
+	// Channelz registers the grpc.channelz.v1 service. It exposes channel,
+	// server and socket internals, so it is off unless asked for.
+	Channelz bool
+	// SocketMode, when non-zero, is applied to a unix:// socket after bind.
+	SocketMode os.FileMode
+	// SocketOwner, when set, is who a unix:// socket is chowned to after
+	// bind, so that only that account can connect. Unix only.
+	SocketOwner *SocketOwner
+	// MaxRequests, when positive, stops the server gracefully once that many
+	// RPCs have completed, so their responses are flushed before it exits.
+	// Reflection and health RPCs do not count.
+	MaxRequests int
+	// Listening, when set, is called with the bound address once the
+	// listener is open, before the first RPC is served.
+	Listening func(net.Addr)
+	// SandboxRoot is the directory the working_dir of an Invoke or
+	// InvokeStream must resolve inside. Empty means the server's working
+	// directory.
+	SandboxRoot string
+	// ServerOptions are passed to the gRPC server after op's own, e.g. to
+	// chain further interceptors.
+	ServerOptions []grpc.ServerOption
+}
+
+// SocketOwner identifies the owner of a unix:// socket. An ID of -1 leaves
+// that part of the ownership unchanged.
+type SocketOwner struct {
+	UID int
+	GID int
 }
 
 // ListenAndServe starts the gRPC server on the given transport URI.
 // Supported URIs: tcp://<host>:<port>, unix://<path>, stdio://
+//
+// stdio:// serves exactly one connection over the process's stdin/stdout:
+// once it is accepted, further Accepts block until the listener closes and
+// then return io.EOF. A client that needs to reconnect within the same process
+// lifetime should use tcp:// or unix:// instead.
+//
+// The grpc.health.v1 service is always registered; see RegisterHealth.
 func ListenAndServe(listenURI string, reflect bool) error {
+	return ListenAndServeWithOptions(listenURI, ServeOptions{Reflect: reflect, Health: true})
+}
+
+// RegisterHealth registers the grpc.health.v1 service on s, reporting the
+// server as a whole and op.v1.OPService SERVING. Calling Shutdown on the
+// returned server flips both to NOT_SERVING, so probes stop routing to a
+// server that is draining.
+func RegisterHealth(s *grpc.Server) *health.Server {
+	hs := health.NewServer()
+	hs.SetServingStatus(opv1.OPService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, hs)
+	return hs
+}
+
+// ListenAndServeWithOptions is ListenAndServe with an optional idle timeout.
+// The timeout matters most for stdio://, where a client that hangs without
+// closing its pipes would otherwise leave the server running forever.
+//
+// SIGINT and SIGTERM stop the server gracefully, as cancelling the context
+// of ListenAndServeContext does, and it then returns nil.
+func ListenAndServeWithOptions(listenURI string, opts ServeOptions) error {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	signals := []os.Signal{os.Interrupt}
+	if runtime.GOOS != "windows" {
+		signals = append(signals, syscall.SIGTERM)
+	}
+	received := make(chan os.Signal, 1)
+	signal.Notify(received, signals...)
+	defer signal.Stop(received)
+	go func() {
+		select {
+		case sig := <-received:
+			cancel(fmt.Errorf("received %s", sig))
+		case <-ctx.Done():
+		}
+	}()
+
+	return ListenAndServeContext(ctx, listenURI, opts)
+}
+
+// ListenAndServeContext is ListenAndServeWithOptions that stops gracefully
+// when ctx is done instead of on a signal, and then returns nil. In-flight
+// RPCs get gracefulStopTimeout to finish before their connections are
+// closed, and a unix:// socket file is removed.
+func ListenAndServeContext(ctx context.Context, listenURI string, opts ServeOptions) error {
 	lis, err := transport.Listen(listenURI)
 	if err != nil {
 		return fmt.Errorf("listen %s: %w", listenURI, err)
 	}
+	if err := applySocketPermissions(listenURI, opts); err != nil {
+		_ = lis.Close()
+		return err
+	}
 
-	s := grpc.NewServer()
-	opv1.RegisterOPServiceServer(s, &Server{})
-	if reflect {
+	var serverOpts []grpc.ServerOption
+	var idle *idleTracker
+	if opts.IdleTimeout > 0 {
+		idle = newIdleTracker()
+		serverOpts = append(serverOpts,
+			grpc.ChainUnaryInterceptor(idle.unaryInterceptor),
+			grpc.ChainStreamInterceptor(idle.streamInterceptor),
+		)
+	}
+
+	// The limiter needs the server to stop it, and the server needs the
+	// limiter's interceptors, so s is assigned once built.
+	var s *grpc.Server
+	var hs *health.Server
+	gracefulStop := func() {
+		if hs != nil {
+			hs.Shutdown()
+		}
+		drained := make(chan struct{})
+		go func() {
+			s.GracefulStop()
+			close(drained)
+		}()
+		select {
+		case <-drained:
+		case <-time.After(gracefulStopTimeout):
+			log.Printf("OP gRPC server: RPCs still in flight after %s, closing their connections", gracefulStopTimeout)
+			s.Stop()
+		}
+	}
+	if opts.MaxRequests > 0 {
+		limiter := newRequestLimiter(opts.MaxRequests, func() {
+			log.Printf("OP gRPC server handled %d request(s), shutting down", opts.MaxRequests)
+			gracefulStop()
+		})
+		serverOpts = append(serverOpts,
+			grpc.ChainUnaryInterceptor(limiter.unaryInterceptor),
+			grpc.ChainStreamInterceptor(limiter.streamInterceptor),
+		)
+	}
+
+	s = grpc.NewServer(append(serverOpts, opts.ServerOptions...)...)
+	opv1.RegisterOPServiceServer(s, &Server{sandboxRoot: opts.SandboxRoot})
+	if opts.Reflect {
 		grpcReflection.Register(s)
 	}
+	if opts.Health {
+		hs = RegisterHealth(s)
+	}
+	if opts.Channelz {
+		channelzservice.RegisterChannelzServiceToServer(s)
+	}
+
+	if idle != nil {
+		done := make(chan struct{})
+		defer close(done)
+		go idle.watch(opts.IdleTimeout, done, func() {
+			log.Printf("OP gRPC server idle for %s, shutting down", opts.IdleTimeout)
+			s.Stop()
+		})
+	}
+
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		select {
+		case <-ctx.Done():
+			log.Printf("OP gRPC server shutting down: %v", context.Cause(ctx))
+			gracefulStop()
+		case <-stopped:
+		}
+	}()
 
 	mode := "reflection ON"
-	if !reflect {
+	if !opts.Reflect {
 		mode = "reflection OFF"
 	}
 	log.Printf("OP gRPC server listening on %s (%s)", listenURI, mode)
-	return s.Serve(lis)
+	if opts.Listening != nil {
+		opts.Listening(lis.Addr())
+	}
+	err = s.Serve(lis)
+	removeSocketFile(listenURI)
+	return err
 }
 
 // --- Helpers ---
 
+// removeSocketFile removes the socket file of a unix:// listener that
+// closing it left behind. Other transports have none.
+func removeSocketFile(listenURI string) {
+	path, ok := strings.CutPrefix(listenURI, "unix://")
+	if !ok {
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Printf("OP gRPC server: remove socket %s: %v", path, err)
+	}
+}
+
+// applySocketPermissions sets the owner, then the mode, of a unix:// socket
+// as opts asks. Other transports have no socket file and are left alone.
+func applySocketPermissions(listenURI string, opts ServeOptions) error {
+	path, ok := strings.CutPrefix(listenURI, "unix://")
+	if !ok || (opts.SocketMode == 0 && opts.SocketOwner == nil) {
+		return nil
+	}
+	if opts.SocketOwner != nil {
+		if err := chownSocket(path, opts.SocketOwner.UID, opts.SocketOwner.GID); err != nil {
+			return fmt.Errorf("set socket owner: %w", err)
+		}
+	}
+	if opts.SocketMode != 0 {
+		if err := os.Chmod(path, opts.SocketMode); err != nil {
+			return fmt.Errorf("set socket mode: %w", err)
+		}
+	}
+	return nil
+}
+
 func toProto(id identity.Identity) *opv1.HolonIdentity {
 	return &opv1.HolonIdentity{
 		Uuid:         id.UUID,
@@ -6,17 +6,27 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/organic-programming/go-holons/pkg/transport"
 	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
-	"github.com/organic-programming/grace-op/internal/grpcclient"
 	"github.com/organic-programming/grace-op/internal/identity"
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
 
 	"google.golang.org/grpc"
+	channelzservice "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 const bufSize = 1024 * 1024
@@ -121,6 +131,48 @@ func TestDiscoverWithHolons(t *testing.T) {
 	}
 }
 
+func TestDiscoverFiltersByIdentity(t *testing.T) {
+	root := t.TempDir()
+	seedHolon(t, root, "disc-1", "Alpha")
+	seedHolon(t, root, "disc-2", "Beta")
+	beta := filepath.Join(root, "Beta", identity.ManifestFileName)
+	id, _, err := identity.ReadHolonYAML(beta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id.Lang, id.Status = "rust", "stable"
+	if err := identity.WriteHolonYAML(id, beta); err != nil {
+		t.Fatal(err)
+	}
+
+	client, cleanup := startTestServer(t, root)
+	defer cleanup()
+
+	for _, tc := range []struct {
+		name string
+		req  *opv1.DiscoverRequest
+		want []string
+	}{
+		{"one lang, any case", &opv1.DiscoverRequest{Langs: []string{"GO"}}, []string{"Alpha"}},
+		{"langs are ORed", &opv1.DiscoverRequest{Langs: []string{"go", "rust"}}, []string{"Alpha", "Beta"}},
+		{"fields are ANDed", &opv1.DiscoverRequest{Langs: []string{"go"}, Statuses: []string{"stable"}}, nil},
+		{"clade", &opv1.DiscoverRequest{Clades: []string{"deterministic/pure"}, Statuses: []string{"stable"}}, []string{"Beta"}},
+	} {
+		resp, err := client.Discover(context.Background(), tc.req)
+		if err != nil {
+			t.Fatalf("%s: Discover failed: %v", tc.name, err)
+		}
+		var got []string
+		for _, e := range resp.Entries {
+			got = append(got, e.GetIdentity().GetGivenName())
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s: Discover = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
 // --- Invoke tests ---
 
 func TestInvokeUnknown(t *testing.T) {
@@ -162,6 +214,343 @@ func TestInvokeEcho(t *testing.T) {
 	}
 }
 
+func TestInvokeAppliesEnvAndWorkingDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "work"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	client, cleanup := startTestServer(t, root)
+	defer cleanup()
+	t.Setenv("OP_INVOKE_INHERITED", "kept")
+
+	resp, err := client.Invoke(context.Background(), &opv1.InvokeRequest{
+		Holon:      "sh",
+		Args:       []string{"-c", `echo "$GREETING $OP_INVOKE_INHERITED"; pwd -P`},
+		Env:        map[string]string{"GREETING": "hello"},
+		WorkingDir: "work",
+	})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	work, err := filepath.EvalSymlinks(filepath.Join(root, "work"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "hello kept\n" + work + "\n"; resp.Stdout != want {
+		t.Fatalf("Stdout = %q, want %q", resp.Stdout, want)
+	}
+
+	resp, err = client.Invoke(context.Background(), &opv1.InvokeRequest{
+		Holon:    "sh",
+		Args:     []string{"-c", `echo "$GREETING[$OP_INVOKE_INHERITED]"`},
+		Env:      map[string]string{"GREETING": "alone"},
+		ClearEnv: true,
+	})
+	if err != nil {
+		t.Fatalf("Invoke with clear_env failed: %v", err)
+	}
+	if resp.Stdout != "alone[]\n" {
+		t.Fatalf("Stdout = %q, want only the request's env", resp.Stdout)
+	}
+}
+
+func TestInvokeRejectsWorkingDirOutsideSandbox(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	client, cleanup := startTestServer(t, root)
+	defer cleanup()
+
+	for _, dir := range []string{"..", outside, "escape", "missing"} {
+		_, err := client.Invoke(context.Background(), &opv1.InvokeRequest{Holon: "echo", WorkingDir: dir})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("working_dir %q: error = %v, want InvalidArgument", dir, err)
+		}
+	}
+	_, err := client.Invoke(context.Background(), &opv1.InvokeRequest{Holon: "echo", Env: map[string]string{"A=B": "c"}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("env name with '=': error = %v, want InvalidArgument", err)
+	}
+}
+
+func TestInvokeStreamSendsOutputThenExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	root := t.TempDir()
+	client, cleanup := startTestServer(t, root)
+	defer cleanup()
+
+	stream, err := client.InvokeStream(context.Background(), &opv1.InvokeRequest{
+		Holon: "sh",
+		Args:  []string{"-c", "echo out; echo err >&2; exit 3"},
+	})
+	if err != nil {
+		t.Fatalf("InvokeStream failed: %v", err)
+	}
+	var stdout, stderr strings.Builder
+	for {
+		chunk, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("stream ended before the exit code: %v", err)
+		}
+		if body, ok := chunk.GetBody().(*opv1.InvokeChunk_ExitCode); ok {
+			if body.ExitCode != 3 {
+				t.Fatalf("exit code = %d, want 3", body.ExitCode)
+			}
+			break
+		}
+		if chunk.GetStream() == opv1.InvokeChunk_STDERR {
+			stderr.Write(chunk.GetData())
+		} else {
+			stdout.Write(chunk.GetData())
+		}
+	}
+	if stdout.String() != "out\n" || stderr.String() != "err\n" {
+		t.Fatalf("stdout = %q, stderr = %q", stdout.String(), stderr.String())
+	}
+}
+
+func TestInvokeStreamCancelKillsCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	root := t.TempDir()
+	client, cleanup := startTestServer(t, root)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.InvokeStream(ctx, &opv1.InvokeRequest{
+		Holon: "sh",
+		Args:  []string{"-c", "echo $$; exec sleep 30"},
+	})
+	if err != nil {
+		t.Fatalf("InvokeStream failed: %v", err)
+	}
+	// The first chunk only names the invocation.
+	if chunk, err := stream.Recv(); err != nil || chunk.GetInvokeId() == "" || chunk.GetBody() != nil {
+		t.Fatalf("first chunk = %v, %v; want only the invoke ID", chunk, err)
+	}
+	chunk, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pid int
+	if _, err := fmt.Sscan(string(chunk.GetData()), &pid); err != nil {
+		t.Fatalf("first chunk %q is not a pid: %v", chunk.GetData(), err)
+	}
+
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for processAlive(pid) {
+		if time.Now().After(deadline) {
+			t.Fatalf("pid %d still running after the call was cancelled", pid)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestInvokeTimeoutKillsProcessGroup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh and process groups")
+	}
+	root := t.TempDir()
+	client, cleanup := startTestServer(t, root)
+	defer cleanup()
+
+	// The background loop is a child of the command, left running unless
+	// the whole process group is stopped.
+	start := time.Now()
+	_, err := client.Invoke(context.Background(), &opv1.InvokeRequest{
+		Holon:          "sh",
+		Args:           []string{"-c", "(while :; do echo tick >> ticks; sleep 0.1; done) & wait"},
+		TimeoutSeconds: 1,
+	})
+	if status.Code(err) != codes.DeadlineExceeded || !strings.Contains(err.Error(), "timed out after 1s") {
+		t.Fatalf("error = %v, want DeadlineExceeded naming the timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 1*time.Second+invokeKillGrace+invokeWaitDelay {
+		t.Fatalf("Invoke returned after %s, want soon after the 1s timeout", elapsed)
+	}
+
+	ticks := func() int64 {
+		info, err := os.Stat(filepath.Join(root, "ticks"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Size()
+	}
+	// Let a write already under way land before measuring.
+	time.Sleep(100 * time.Millisecond)
+	before := ticks()
+	time.Sleep(500 * time.Millisecond)
+	if after := ticks(); after != before {
+		t.Fatalf("the command's child kept running after the timeout (%d -> %d bytes)", before, after)
+	}
+}
+
+func TestCancelInvokeStopsStreamByID(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	root := t.TempDir()
+	client, cleanup := startTestServer(t, root)
+	defer cleanup()
+
+	stream, err := client.InvokeStream(context.Background(), &opv1.InvokeRequest{
+		Holon:    "sh",
+		Args:     []string{"-c", "exec sleep 30"},
+		InvokeId: "nightly",
+	})
+	if err != nil {
+		t.Fatalf("InvokeStream failed: %v", err)
+	}
+	if chunk, err := stream.Recv(); err != nil || chunk.GetInvokeId() != "nightly" {
+		t.Fatalf("first chunk = %v, %v; want invoke ID nightly", chunk, err)
+	}
+
+	_, err = client.Invoke(context.Background(), &opv1.InvokeRequest{Holon: "sh", Args: []string{"-c", "true"}, InvokeId: "nightly"})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("reusing an ID in flight: err = %v, want AlreadyExists", err)
+	}
+
+	resp, err := client.CancelInvoke(context.Background(), &opv1.CancelInvokeRequest{InvokeId: "nightly"})
+	if err != nil {
+		t.Fatalf("CancelInvoke failed: %v", err)
+	}
+	if resp.GetHolon() != "sh" || len(resp.GetArgs()) != 2 {
+		t.Fatalf("response = %v, want the cancelled command", resp)
+	}
+	_, err = stream.Recv()
+	if status.Code(err) != codes.Canceled || !strings.Contains(err.Error(), "CancelInvoke") {
+		t.Fatalf("stream error = %v, want Canceled by CancelInvoke", err)
+	}
+
+	_, err = client.CancelInvoke(context.Background(), &opv1.CancelInvokeRequest{InvokeId: "nightly"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("cancelling a finished invoke: err = %v, want NotFound", err)
+	}
+
+	done, err := client.Invoke(context.Background(), &opv1.InvokeRequest{Holon: "sh", Args: []string{"-c", "true"}})
+	if err != nil || done.GetInvokeId() == "" {
+		t.Fatalf("Invoke = %v, %v; want an assigned invoke ID", done, err)
+	}
+}
+
+func TestInvokeCancelKillsCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	root := t.TempDir()
+	client, cleanup := startTestServer(t, root)
+	defer cleanup()
+
+	// A grandchild keeps the output pipes open after the command is killed.
+	pidFile := filepath.Join(root, "pid")
+	script := fmt.Sprintf(`sleep 30 & echo $! > %[1]s.bg; echo $$ > %[1]s.tmp; mv %[1]s.tmp %[1]s; exec sleep 30`, pidFile)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Invoke(ctx, &opv1.InvokeRequest{Holon: "sh", Args: []string{"-c", script}})
+		done <- err
+	}()
+
+	pid := readPIDFile(t, pidFile)
+	t.Cleanup(func() {
+		if bg, err := os.ReadFile(pidFile + ".bg"); err == nil {
+			var bgPID int
+			if _, err := fmt.Sscan(string(bg), &bgPID); err == nil {
+				if process, err := os.FindProcess(bgPID); err == nil {
+					_ = process.Kill()
+				}
+			}
+		}
+	})
+
+	cancel()
+	select {
+	case err := <-done:
+		if status.Code(err) != codes.Canceled {
+			t.Fatalf("Invoke error = %v, want Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Invoke did not return after the call was cancelled")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for processAlive(pid) {
+		if time.Now().After(deadline) {
+			t.Fatalf("pid %d still running after the call was cancelled", pid)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestInvokeDeadlineKillsCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	root := t.TempDir()
+	client, cleanup := startTestServer(t, root)
+	defer cleanup()
+
+	pidFile := filepath.Join(root, "pid")
+	script := fmt.Sprintf(`echo $$ > %[1]s.tmp; mv %[1]s.tmp %[1]s; exec sleep 30`, pidFile)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	started := time.Now()
+	_, err := client.Invoke(ctx, &opv1.InvokeRequest{Holon: "sh", Args: []string{"-c", script}})
+	if code := status.Code(err); code != codes.DeadlineExceeded {
+		t.Fatalf("Invoke error = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("Invoke took %s, want it to end at the deadline", elapsed)
+	}
+
+	pid := readPIDFile(t, pidFile)
+	deadline := time.Now().Add(5 * time.Second)
+	for processAlive(pid) {
+		if time.Now().After(deadline) {
+			t.Fatalf("pid %d still running after the deadline passed", pid)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// readPIDFile waits for path to hold a pid and returns it.
+func readPIDFile(t *testing.T, path string) int {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var pid int
+			if _, err := fmt.Sscan(string(data), &pid); err != nil {
+				t.Fatalf("%s holds %q, not a pid", path, data)
+			}
+			return pid
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s never appeared", path)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
 // --- Identity RPCs (promoted from Sophia) ---
 
 func TestCreateIdentity(t *testing.T) {
@@ -256,6 +645,46 @@ func TestShowIdentity(t *testing.T) {
 	}
 }
 
+func TestIdentityFieldMaskClearsOtherFields(t *testing.T) {
+	root := t.TempDir()
+	seedHolon(t, root, "mask-uuid-7", "Zeta")
+
+	client, cleanup := startTestServer(t, root)
+	defer cleanup()
+
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"uuid", "givenName"}}
+	show, err := client.ShowIdentity(context.Background(), &opv1.ShowIdentityRequest{Uuid: "mask-uuid-7", FieldMask: mask})
+	if err != nil {
+		t.Fatalf("ShowIdentity failed: %v", err)
+	}
+	list, err := client.ListIdentities(context.Background(), &opv1.ListIdentitiesRequest{FieldMask: mask})
+	if err != nil {
+		t.Fatalf("ListIdentities failed: %v", err)
+	}
+	if len(list.Entries) != 1 {
+		t.Fatalf("ListIdentities returned %d entries, want 1", len(list.Entries))
+	}
+	for _, id := range []*opv1.HolonIdentity{show.Identity, list.Entries[0].Identity} {
+		if id.Uuid != "mask-uuid-7" || id.GivenName != "Zeta" {
+			t.Fatalf("masked identity lost a requested field: %v", id)
+		}
+		if id.FamilyName != "" || id.Motto != "" || id.Clade != opv1.Clade_CLADE_UNSPECIFIED || id.Lang != "" || id.Born != "" {
+			t.Fatalf("masked-out fields should be empty: %v", id)
+		}
+	}
+	if list.Entries[0].RelativePath == "" {
+		t.Fatal("the mask applies to the identity, not the entry around it")
+	}
+
+	_, err = client.ShowIdentity(context.Background(), &opv1.ShowIdentityRequest{
+		Uuid:      "mask-uuid-7",
+		FieldMask: &fieldmaskpb.FieldMask{Paths: []string{"nickname"}},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("unknown mask field error = %v, want InvalidArgument", err)
+	}
+}
+
 func TestShowIdentityNotFound(t *testing.T) {
 	root := t.TempDir()
 	client, cleanup := startTestServer(t, root)
@@ -285,6 +714,35 @@ func TestListenAndServePortConflict(t *testing.T) {
 	}
 }
 
+func TestListenAndServeContextReturnsNilOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	listening := make(chan net.Addr, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- ListenAndServeContext(ctx, "tcp://127.0.0.1:0", ServeOptions{
+			Health:    true,
+			Listening: func(addr net.Addr) { listening <- addr },
+		})
+	}()
+	select {
+	case <-listening:
+	case err := <-done:
+		t.Fatalf("server stopped before listening: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server never listened")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ListenAndServeContext after cancel = %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop after cancel")
+	}
+}
+
 // --- mem:// transport test (using go-holons SDK MemListener) ---
 
 func TestMemTransport(t *testing.T) {
@@ -367,3 +825,340 @@ func TestWSTransport(t *testing.T) {
 		t.Error("expected non-empty output from Discover")
 	}
 }
+
+// --- static descriptor fast path ---
+
+func TestDialWithoutReflectionUsesStaticDescriptors(t *testing.T) {
+	root := t.TempDir()
+	seedHolon(t, root, "static-1", "Static")
+
+	original, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(original) //nolint:errcheck
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	opv1.RegisterOPServiceServer(s, &Server{})
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+
+	result, err := grpcclient.Dial(lis.Addr().String(), "ListIdentities", "{}")
+	if err != nil {
+		t.Fatalf("Dial ListIdentities without reflection: %v", err)
+	}
+	if result.Service != "op.v1.OPService" || result.Method != "ListIdentities" {
+		t.Fatalf("result = %s/%s, want op.v1.OPService/ListIdentities", result.Service, result.Method)
+	}
+}
+
+func TestDialFallsBackToReflectionWhenServiceIsAbsent(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	reflection.Register(s)
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+
+	_, err = grpcclient.Dial(lis.Addr().String(), "ListIdentities", "{}")
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("Dial error = %v, want reflection method-not-found error", err)
+	}
+}
+
+// --- raw reflection dump ---
+
+func TestDumpReflectionReportsFilesAndDependencies(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	opv1.RegisterOPServiceServer(s, &Server{})
+	reflection.Register(s)
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+
+	dump, err := grpcclient.DumpReflection(lis.Addr().String())
+	if err != nil {
+		t.Fatalf("DumpReflection: %v", err)
+	}
+
+	var found bool
+	for _, svc := range dump.Services {
+		if svc.Name != "op.v1.OPService" {
+			continue
+		}
+		found = true
+		if svc.Error != "" {
+			t.Fatalf("OPService error: %s", svc.Error)
+		}
+		if len(svc.Files) == 0 || svc.Files[0].Name != "op/v1/op.proto" {
+			t.Fatalf("first file = %+v, want op/v1/op.proto", svc.Files)
+		}
+		if svc.Files[0].Package != "op.v1" {
+			t.Fatalf("package = %q, want op.v1", svc.Files[0].Package)
+		}
+		if len(svc.MissingDependencies) != 0 {
+			t.Fatalf("missing dependencies = %v, want none", svc.MissingDependencies)
+		}
+	}
+	if !found {
+		t.Fatalf("services = %+v, want op.v1.OPService", dump.Services)
+	}
+}
+
+func TestListenAndServeStopsWhenIdle(t *testing.T) {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- ListenAndServeWithOptions("tcp://127.0.0.1:0", ServeOptions{IdleTimeout: 100 * time.Millisecond})
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("ListenAndServeWithOptions: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop after the idle timeout")
+	}
+}
+
+func TestIdleTrackerIgnoresTimeWithRPCInFlight(t *testing.T) {
+	tracker := newIdleTracker()
+	tracker.begin()
+	if idle := tracker.idleFor(time.Now().Add(time.Hour)); idle != 0 {
+		t.Fatalf("idleFor with an RPC in flight = %s, want 0", idle)
+	}
+	tracker.end()
+	if idle := tracker.idleFor(time.Now().Add(time.Hour)); idle < time.Hour-time.Second {
+		t.Fatalf("idleFor after the RPC ended = %s, want about 1h", idle)
+	}
+}
+
+func TestDialMethodNotFoundListsInputTypes(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	opv1.RegisterOPServiceServer(s, &Server{})
+	reflection.Register(s)
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+
+	_, err = grpcclient.Dial(lis.Addr().String(), "ListIdentitys", "{}")
+	if err == nil {
+		t.Fatal("expected method-not-found error")
+	}
+	for _, want := range []string{
+		`did you mean "ListIdentities"?`,
+		"op.v1.OPService/ListIdentities(ListIdentitiesRequest)",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error = %q, want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestDumpChannelzReportsServerAndSockets(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	opv1.RegisterOPServiceServer(s, &Server{})
+	channelzservice.RegisterChannelzServiceToServer(s)
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+
+	dump, err := grpcclient.DumpChannelz(lis.Addr().String())
+	if err != nil {
+		t.Fatalf("DumpChannelz: %v", err)
+	}
+
+	for _, srv := range dump.Servers {
+		for _, listen := range srv.ListenSockets {
+			if listen.Local != lis.Addr().String() {
+				continue
+			}
+			if len(srv.Sockets) == 0 {
+				t.Fatalf("server %d lists no connection sockets", srv.ID)
+			}
+			if srv.Sockets[0].Remote == "" {
+				t.Fatalf("socket %+v has no remote address", srv.Sockets[0])
+			}
+			return
+		}
+	}
+	t.Fatalf("no server listening on %s in %+v", lis.Addr(), dump.Servers)
+}
+
+func TestListenAndServeAppliesSocketPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("socket ownership is Unix only")
+	}
+	dir, err := os.MkdirTemp("", "opsock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "op.sock")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- ListenAndServeContext(ctx, "unix://"+socket, ServeOptions{
+			SocketMode:  0o600,
+			SocketOwner: &SocketOwner{UID: os.Getuid(), GID: -1},
+		})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		info, err := os.Stat(socket)
+		if err == nil && info.Mode().Perm() == 0o600 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("socket mode never became 0600 (stat: %v, %v)", info, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestListenAndServeStopsAfterMaxRequests(t *testing.T) {
+	dir, err := os.MkdirTemp("", "opsock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "op.sock")
+	t.Chdir(t.TempDir())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- ListenAndServeWithOptions("unix://"+socket, ServeOptions{Health: true, MaxRequests: 1})
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if conn, err := net.Dial("unix", socket); err == nil {
+			_ = conn.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("server socket never came up")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Health checks are how harnesses wait for readiness, so they are free.
+	if _, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("health check: %v", err)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("server stopped after a health check: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if _, err := opv1.NewOPServiceClient(conn).ListIdentities(ctx, &opv1.ListIdentitiesRequest{}); err != nil {
+		t.Fatalf("the one allowed request failed: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ListenAndServeWithOptions: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop after its last request")
+	}
+}
+
+func TestRequestLimiterRefusesCallsPastTheLimit(t *testing.T) {
+	stopped := make(chan struct{})
+	limiter := newRequestLimiter(1, func() { close(stopped) })
+
+	if counted, err := limiter.begin("/grpc.reflection.v1.ServerReflection/ServerReflectionInfo"); counted || err != nil {
+		t.Fatalf("reflection call: counted=%v err=%v, want it free", counted, err)
+	}
+	if counted, err := limiter.begin("/op.v1.OPService/Discover"); !counted || err != nil {
+		t.Fatalf("first call: counted=%v err=%v", counted, err)
+	}
+	if _, err := limiter.begin("/op.v1.OPService/Discover"); status.Code(err) != codes.Unavailable {
+		t.Fatalf("second call error = %v, want Unavailable", err)
+	}
+	limiter.end()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("limiter did not stop the server after the last request finished")
+	}
+}
+
+func TestRegisterHealthReportsServingUntilShutdown(t *testing.T) {
+	lis := bufconn.Listen(bufSize)
+	s := grpc.NewServer()
+	opv1.RegisterOPServiceServer(s, &Server{})
+	hs := RegisterHealth(s)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	services := []string{"", opv1.OPService_ServiceDesc.ServiceName}
+	for _, service := range services {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("Check(%q): %v", service, err)
+		}
+		if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			t.Fatalf("Check(%q) = %v, want SERVING", service, resp.GetStatus())
+		}
+	}
+
+	hs.Shutdown()
+	for _, service := range services {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("Check(%q) after Shutdown: %v", service, err)
+		}
+		if resp.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Fatalf("Check(%q) after Shutdown = %v, want NOT_SERVING", service, resp.GetStatus())
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package server
+
+import "fmt"
+
+func chownSocket(path string, uid, gid int) error {
+	return fmt.Errorf("socket ownership is only supported on Unix")
+}
@@ -0,0 +1,9 @@
+//go:build unix
+
+package server
+
+import "os"
+
+func chownSocket(path string, uid, gid int) error {
+	return os.Chown(path, uid, gid)
+}
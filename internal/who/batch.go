@@ -0,0 +1,176 @@
+package who
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
+)
+
+// BatchRow is one CreateIdentity request read from a batch file. Row is the
+// 1-based position of the record, not counting a CSV header. Err is set when
+// the record itself could not be turned into a valid request.
+type BatchRow struct {
+	Row     int
+	Request *opv1.CreateIdentityRequest
+	Err     error
+}
+
+// BatchOutcome records the result of creating one batch row.
+type BatchOutcome struct {
+	Row      int
+	Response *opv1.CreateIdentityResponse
+	Err      error
+}
+
+// ReadBatchFile reads create requests from a CSV file whose header row names
+// the request fields, or from a JSON array of request objects. A path of "-"
+// reads from stdin.
+func ReadBatchFile(path string) ([]BatchRow, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if strings.EqualFold(filepath.Ext(path), ".json") || bytes.HasPrefix(trimmed, []byte("[")) {
+		return parseBatchJSON(trimmed)
+	}
+	return parseBatchCSV(trimmed)
+}
+
+// CreateBatch creates every row in order. Unless failFast is set it keeps
+// going after a failed row; with failFast it stops at the first failure.
+func CreateBatch(rows []BatchRow, failFast bool) []BatchOutcome {
+	outcomes := make([]BatchOutcome, 0, len(rows))
+	for _, row := range rows {
+		if row.Err != nil {
+			outcomes = append(outcomes, BatchOutcome{Row: row.Row, Err: row.Err})
+			if failFast {
+				break
+			}
+			continue
+		}
+		resp, err := Create(row.Request)
+		outcomes = append(outcomes, BatchOutcome{Row: row.Row, Response: resp, Err: err})
+		if err != nil && failFast {
+			break
+		}
+	}
+	return outcomes
+}
+
+// ValidateBatch checks every row without writing anything: required fields,
+// enum values, and that no two rows, nor an existing manifest, share an output
+// path. Valid rows get a preview response carrying the path they would use.
+func ValidateBatch(rows []BatchRow) []BatchOutcome {
+	claimed := make(map[string]int, len(rows))
+	outcomes := make([]BatchOutcome, 0, len(rows))
+	for _, row := range rows {
+		outcome := BatchOutcome{Row: row.Row, Err: row.Err}
+		if outcome.Err == nil {
+			outcome.Err = validateCreateRequest(row.Request)
+		}
+		if outcome.Err == nil {
+			path := outputPathFor(row.Request)
+			key := filepath.Clean(path)
+			if other, ok := claimed[key]; ok {
+				outcome.Err = fmt.Errorf("%s is also created by row %d", path, other)
+			} else if _, err := os.Stat(path); err == nil {
+				outcome.Err = fmt.Errorf("%s already exists", path)
+			} else {
+				claimed[key] = row.Row
+				outcome.Response = &opv1.CreateIdentityResponse{
+					Identity: &opv1.HolonIdentity{
+						GivenName:  strings.TrimSpace(row.Request.GetGivenName()),
+						FamilyName: strings.TrimSpace(row.Request.GetFamilyName()),
+						Clade:      row.Request.GetClade(),
+					},
+					FilePath: path,
+				}
+			}
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes
+}
+
+func parseBatchJSON(data []byte) ([]BatchRow, error) {
+	var records []json.RawMessage
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("batch JSON must be an array of objects: %w", err)
+	}
+
+	rows := make([]BatchRow, 0, len(records))
+	for i, record := range records {
+		rows = append(rows, parseBatchRecord(i+1, string(record)))
+	}
+	return rows, nil
+}
+
+func parseBatchCSV(data []byte) ([]BatchRow, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("read batch CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("batch CSV is empty")
+	}
+
+	header := make([]string, len(records[0]))
+	for i, column := range records[0] {
+		header[i] = strings.TrimSpace(column)
+	}
+
+	rows := make([]BatchRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		fields := make(map[string]string, len(header))
+		for col, value := range record {
+			if header[col] != "" {
+				fields[header[col]] = value
+			}
+		}
+		payload, err := json.Marshal(fields)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		rows = append(rows, parseBatchRecord(i+1, string(payload)))
+	}
+	return rows, nil
+}
+
+// parseBatchRecord turns one JSON object into a create request. Enum values
+// are checked against the raw strings, since unknown values would otherwise
+// fall back to their defaults silently.
+func parseBatchRecord(row int, raw string) BatchRow {
+	req, err := parseCreateIdentityJSON(raw)
+	if err != nil {
+		return BatchRow{Row: row, Err: err}
+	}
+
+	var payload map[string]json.RawMessage
+	_ = json.Unmarshal([]byte(raw), &payload)
+	if clade := strings.TrimSpace(jsonString(payload, "clade")); clade != "" && cladeString(req.GetClade()) == "" {
+		return BatchRow{Row: row, Request: req, Err: fmt.Errorf("invalid clade %q", clade)}
+	}
+	if mode := strings.TrimSpace(jsonString(payload, "reproduction")); mode != "" && reproductionString(req.GetReproduction()) == "" {
+		return BatchRow{Row: row, Request: req, Err: fmt.Errorf("invalid reproduction %q", mode)}
+	}
+	return BatchRow{Row: row, Request: req}
+}
@@ -0,0 +1,96 @@
+package who
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
+	openv "github.com/organic-programming/grace-op/internal/env"
+	"github.com/organic-programming/grace-op/internal/identity"
+)
+
+// Delete removes the identity file of the local holon whose UUID is
+// req.Uuid, or with req.Purge its whole directory. Only holons under the
+// scan root can be deleted, and a stable or pinned holon only with
+// req.Force.
+func Delete(req *opv1.DeleteIdentityRequest) (*opv1.DeleteIdentityResponse, error) {
+	return deleteIdentity(openv.Root(), req)
+}
+
+func deleteIdentity(root string, req *opv1.DeleteIdentityRequest) (*opv1.DeleteIdentityResponse, error) {
+	target := strings.TrimSpace(req.GetUuid())
+	if target == "" {
+		return nil, fmt.Errorf("uuid is required")
+	}
+
+	path, err := identity.FindByUUID(root, target)
+	if err != nil {
+		return nil, err
+	}
+	id, _, err := identity.ReadIdentity(path)
+	if err != nil {
+		return nil, err
+	}
+	if id.UUID != target {
+		return nil, fmt.Errorf("%q is only a prefix of %s; deleting needs the full uuid", target, id.UUID)
+	}
+	if !req.GetForce() {
+		if strings.EqualFold(strings.TrimSpace(id.Status), "stable") {
+			return nil, fmt.Errorf("%s is stable; pass --force to delete it", id.UUID)
+		}
+		if id.Pin != nil && !id.Pin.IsZero() {
+			return nil, fmt.Errorf("%s is pinned to a release; pass --force to delete it", id.UUID)
+		}
+	}
+
+	removed := path
+	if req.GetPurge() {
+		removed = filepath.Dir(path)
+	}
+	if err := checkInsideRoot(root, removed); err != nil {
+		return nil, err
+	}
+
+	if req.GetPurge() {
+		err = os.RemoveAll(removed)
+	} else {
+		err = os.Remove(removed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("delete %s: %w", removed, err)
+	}
+
+	return &opv1.DeleteIdentityResponse{
+		Identity:    toProto(id),
+		RemovedPath: removed,
+	}, nil
+}
+
+// checkInsideRoot refuses a path that, once symlinks are resolved, is the
+// scan root itself or lies outside it.
+func checkInsideRoot(root, path string) error {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return err
+	}
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+	realRoot, err = filepath.Abs(realRoot)
+	if err != nil {
+		return err
+	}
+	realPath, err = filepath.Abs(realPath)
+	if err != nil {
+		return err
+	}
+
+	rel, err := filepath.Rel(realRoot, realPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("refusing to delete %s: not inside %s", path, root)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package who
+
+import (
+	"fmt"
+	"strings"
+
+	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// ParseIdentityMask builds a field mask from a comma-separated list of
+// HolonIdentity fields, given by proto name (given_name) or JSON name
+// (givenName). Paths are normalized to proto names.
+func ParseIdentityMask(list string) (*fieldmaskpb.FieldMask, error) {
+	mask := &fieldmaskpb.FieldMask{}
+	for _, path := range strings.Split(list, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		field, err := identityField(path)
+		if err != nil {
+			return nil, err
+		}
+		mask.Paths = append(mask.Paths, string(field.Name()))
+	}
+	if len(mask.Paths) == 0 {
+		return nil, fmt.Errorf("field mask names no fields")
+	}
+	return mask, nil
+}
+
+// MaskIdentity clears every field of id that mask does not name. A nil or
+// empty mask keeps the identity whole. Only top-level fields can be named.
+func MaskIdentity(id *opv1.HolonIdentity, mask *fieldmaskpb.FieldMask) error {
+	if id == nil || len(mask.GetPaths()) == 0 {
+		return nil
+	}
+	keep := make(map[protoreflect.FieldNumber]bool, len(mask.GetPaths()))
+	for _, path := range mask.GetPaths() {
+		field, err := identityField(path)
+		if err != nil {
+			return err
+		}
+		keep[field.Number()] = true
+	}
+
+	msg := id.ProtoReflect()
+	var drop []protoreflect.FieldDescriptor
+	msg.Range(func(field protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !keep[field.Number()] {
+			drop = append(drop, field)
+		}
+		return true
+	})
+	for _, field := range drop {
+		msg.Clear(field)
+	}
+	return nil
+}
+
+// MaskEntries applies mask to the identity of every entry.
+func MaskEntries(entries []*opv1.HolonEntry, mask *fieldmaskpb.FieldMask) error {
+	for _, entry := range entries {
+		if err := MaskIdentity(entry.GetIdentity(), mask); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func identityField(path string) (protoreflect.FieldDescriptor, error) {
+	fields := (&opv1.HolonIdentity{}).ProtoReflect().Descriptor().Fields()
+	if field := fields.ByName(protoreflect.Name(path)); field != nil {
+		return field, nil
+	}
+	if field := fields.ByJSONName(path); field != nil {
+		return field, nil
+	}
+	return nil, fmt.Errorf("field mask: HolonIdentity has no field %q", path)
+}
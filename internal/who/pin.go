@@ -0,0 +1,159 @@
+package who
+
+import (
+	"bytes"
+	"context"
+	"debug/buildinfo"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/organic-programming/grace-op/internal/identity"
+	"gopkg.in/yaml.v3"
+)
+
+// Pin sources reported by DetectBinaryVersion.
+const (
+	PinSourceVersionCommand = "version --format json"
+	PinSourceGoBuildInfo    = "go build info"
+)
+
+// versionCommandTimeout bounds `<binary> version --format json`.
+const versionCommandTimeout = 5 * time.Second
+
+// PinResult is the outcome of PinVersion.
+type PinResult struct {
+	UUID string              `json:"uuid"`
+	Slug string              `json:"slug"`
+	Path string              `json:"path"`
+	Pin  identity.VersionPin `json:"pin"`
+}
+
+// PinVersion records pin under the pin key of the holon.yaml whose UUID is
+// target or starts with it, replacing any earlier pin. Other keys, their
+// order and their comments are kept.
+func PinVersion(target string, pin identity.VersionPin) (*PinResult, error) {
+	if pin.IsZero() {
+		return nil, fmt.Errorf("nothing to pin: version, tag, commit, os and arch are all empty")
+	}
+	located, err := resolveIdentity(target)
+	if err != nil {
+		return nil, err
+	}
+	path := located.IdentityPath
+	if filepath.Base(path) != identity.ManifestFileName {
+		return nil, fmt.Errorf("%s: pinning needs a %s identity", path, identity.ManifestFileName)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: manifest is not a mapping", path)
+	}
+	var value yaml.Node
+	if err := value.Encode(pin); err != nil {
+		return nil, err
+	}
+	setMappingValue(doc.Content[0], "pin", &value)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return nil, err
+	}
+
+	return &PinResult{
+		UUID: located.Identity.UUID,
+		Slug: located.Identity.Slug(),
+		Path: path,
+		Pin:  pin,
+	}, nil
+}
+
+// DetectBinaryVersion works out what build the binary at path is. It first
+// asks the binary itself with `version --format json`, then falls back to
+// the build info the Go toolchain embeds (what `go version -m` prints). The
+// returned source names the method that answered.
+func DetectBinaryVersion(path string) (identity.VersionPin, string, error) {
+	if _, err := os.Stat(path); err != nil {
+		return identity.VersionPin{}, "", err
+	}
+	pin, commandErr := versionFromCommand(path)
+	if commandErr == nil && pin.Version != "" {
+		return pin, PinSourceVersionCommand, nil
+	}
+	if commandErr == nil {
+		commandErr = fmt.Errorf("version --format json: no version reported")
+	}
+
+	pin, err := versionFromBuildInfo(path)
+	if err != nil {
+		return identity.VersionPin{}, "", fmt.Errorf("cannot detect the version of %s: %v; %v", path, commandErr, err)
+	}
+	return pin, PinSourceGoBuildInfo, nil
+}
+
+func versionFromCommand(path string) (identity.VersionPin, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), versionCommandTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path, "version", "--format", "json").Output()
+	if err != nil {
+		return identity.VersionPin{}, fmt.Errorf("version --format json: %w", err)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(out, &payload); err != nil {
+		return identity.VersionPin{}, fmt.Errorf("version --format json: %w", err)
+	}
+	field := func(keys ...string) string {
+		for _, key := range keys {
+			if value, ok := payload[key].(string); ok && strings.TrimSpace(value) != "" {
+				return strings.TrimSpace(value)
+			}
+		}
+		return ""
+	}
+	return identity.VersionPin{
+		Version: field("version"),
+		Tag:     field("tag"),
+		Commit:  field("commit", "revision"),
+		OS:      field("os", "goos"),
+		Arch:    field("arch", "goarch"),
+	}, nil
+}
+
+func versionFromBuildInfo(path string) (identity.VersionPin, error) {
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return identity.VersionPin{}, fmt.Errorf("go build info: %w", err)
+	}
+	pin := identity.VersionPin{Version: info.Main.Version}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			pin.Commit = setting.Value
+		case "GOOS":
+			pin.OS = setting.Value
+		case "GOARCH":
+			pin.Arch = setting.Value
+		}
+	}
+	return pin, nil
+}
@@ -0,0 +1,390 @@
+package who
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
+	"github.com/organic-programming/grace-op/internal/identity"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PlanAction is the kind of change a plan entry makes.
+type PlanAction string
+
+const (
+	PlanCreate PlanAction = "create"
+	PlanUpdate PlanAction = "update"
+	PlanDelete PlanAction = "delete"
+)
+
+// PlanChange is one difference between the desired and the current set of
+// identities. Path is the manifest the change writes or removes; Source is
+// the desired manifest it comes from, empty for deletes.
+type PlanChange struct {
+	Action PlanAction `json:"action"`
+	UUID   string     `json:"uuid"`
+	Name   string     `json:"name"`
+	Path   string     `json:"path"`
+	Source string     `json:"source,omitempty"`
+	Fields []string   `json:"fields,omitempty"`
+}
+
+// IdentityPlan lists the changes that bring root in line with a directory of
+// desired holon.yaml files. Identities are matched by UUID.
+type IdentityPlan struct {
+	DesiredDir string       `json:"desired_dir"`
+	Root       string       `json:"root"`
+	Changes    []PlanChange `json:"changes"`
+}
+
+// Counts returns how many creates, updates, and deletes the plan holds.
+func (p *IdentityPlan) Counts() (creates, updates, deletes int) {
+	for _, change := range p.Changes {
+		switch change.Action {
+		case PlanCreate:
+			creates++
+		case PlanUpdate:
+			updates++
+		case PlanDelete:
+			deletes++
+		}
+	}
+	return creates, updates, deletes
+}
+
+// planField is an identity field compared and written by plan/apply.
+type planField struct {
+	key   string
+	value func(identity.Identity) any
+}
+
+var planFields = []planField{
+	{"given_name", func(id identity.Identity) any { return id.GivenName }},
+	{"family_name", func(id identity.Identity) any { return id.FamilyName }},
+	{"motto", func(id identity.Identity) any { return id.Motto }},
+	{"composer", func(id identity.Identity) any { return id.Composer }},
+	{"clade", func(id identity.Identity) any { return id.Clade }},
+	{"status", func(id identity.Identity) any { return id.Status }},
+	{"parents", func(id identity.Identity) any { return id.Parents }},
+	{"reproduction", func(id identity.Identity) any { return id.Reproduction }},
+	{"aliases", func(id identity.Identity) any { return id.Aliases }},
+	{"lang", func(id identity.Identity) any { return id.Lang }},
+	{"description", func(id identity.Identity) any { return id.Description }},
+}
+
+// Plan compares the holon.yaml files under desiredDir with the identities
+// under root and returns the changes apply would make.
+func Plan(desiredDir, root string) (*IdentityPlan, error) {
+	if strings.TrimSpace(root) == "" {
+		root = "."
+	}
+	desiredAbs, err := filepath.Abs(desiredDir)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(desiredAbs); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", desiredDir)
+	}
+
+	desired, err := identity.FindAllWithPaths(desiredDir)
+	if err != nil {
+		return nil, err
+	}
+	desiredByUUID := make(map[string]identity.LocatedIdentity, len(desired))
+	for _, located := range desired {
+		uuid := strings.TrimSpace(located.Identity.UUID)
+		if uuid == "" {
+			return nil, fmt.Errorf("%s: uuid is required", located.Path)
+		}
+		if other, ok := desiredByUUID[uuid]; ok {
+			return nil, fmt.Errorf("uuid %s is declared by both %s and %s", uuid, other.Path, located.Path)
+		}
+		desiredByUUID[uuid] = located
+	}
+
+	scanned, err := identity.FindAllWithPaths(root)
+	if err != nil {
+		return nil, err
+	}
+	currentByUUID := make(map[string]identity.LocatedIdentity, len(scanned))
+	for _, located := range scanned {
+		if isWithinDir(desiredAbs, located.Path) {
+			continue
+		}
+		if uuid := strings.TrimSpace(located.Identity.UUID); uuid != "" {
+			currentByUUID[uuid] = located
+		}
+	}
+
+	plan := &IdentityPlan{DesiredDir: desiredDir, Root: root, Changes: []PlanChange{}}
+	claimed := make(map[string]string)
+	for uuid, want := range desiredByUUID {
+		have, exists := currentByUUID[uuid]
+		if !exists {
+			path := filepath.Join(root, "holons", slugFor(want.Identity.GivenName, want.Identity.FamilyName), identity.ManifestFileName)
+			if _, err := os.Stat(path); err == nil {
+				return nil, fmt.Errorf("cannot create %s for %s: file already exists", path, uuid)
+			}
+			if other, ok := claimed[path]; ok {
+				return nil, fmt.Errorf("cannot create %s for both %s and %s", path, other, uuid)
+			}
+			claimed[path] = uuid
+			plan.Changes = append(plan.Changes, PlanChange{
+				Action: PlanCreate,
+				UUID:   uuid,
+				Name:   displayName(want.Identity),
+				Path:   path,
+				Source: want.Path,
+			})
+			continue
+		}
+
+		if fields := changedPlanFields(have.Identity, want.Identity); len(fields) > 0 {
+			plan.Changes = append(plan.Changes, PlanChange{
+				Action: PlanUpdate,
+				UUID:   uuid,
+				Name:   displayName(want.Identity),
+				Path:   have.Path,
+				Source: want.Path,
+				Fields: fields,
+			})
+		}
+	}
+	for uuid, have := range currentByUUID {
+		if _, ok := desiredByUUID[uuid]; ok {
+			continue
+		}
+		plan.Changes = append(plan.Changes, PlanChange{
+			Action: PlanDelete,
+			UUID:   uuid,
+			Name:   displayName(have.Identity),
+			Path:   have.Path,
+		})
+	}
+
+	order := map[PlanAction]int{PlanCreate: 0, PlanUpdate: 1, PlanDelete: 2}
+	sort.Slice(plan.Changes, func(i, j int) bool {
+		a, b := plan.Changes[i], plan.Changes[j]
+		if a.Action != b.Action {
+			return order[a.Action] < order[b.Action]
+		}
+		return a.Path < b.Path
+	})
+	return plan, nil
+}
+
+// ApplyPlan executes plan. Creates go through CreateIdentity under the
+// desired UUID, then get the fields its request does not carry; updates
+// rewrite only the changed identity fields so build and artifact sections
+// are kept; deletes go through DeleteIdentity, and only when prune is set.
+// It returns the changes that were applied.
+func ApplyPlan(plan *IdentityPlan, prune bool) ([]PlanChange, error) {
+	applied := make([]PlanChange, 0, len(plan.Changes))
+	for _, change := range plan.Changes {
+		var err error
+		switch change.Action {
+		case PlanCreate:
+			err = applyPlanCreate(change)
+		case PlanUpdate:
+			err = applyPlanUpdate(change)
+		case PlanDelete:
+			if !prune {
+				continue
+			}
+			_, err = deleteIdentity(plan.Root, &opv1.DeleteIdentityRequest{Uuid: change.UUID})
+		}
+		if err != nil {
+			return applied, fmt.Errorf("%s %s: %w", change.Action, change.Path, err)
+		}
+		applied = append(applied, change)
+	}
+	return applied, nil
+}
+
+func applyPlanCreate(change PlanChange) error {
+	want, _, err := identity.ReadIdentity(change.Source)
+	if err != nil {
+		return err
+	}
+	id := identity.New()
+	id.UUID = want.UUID
+	id.GeneratedBy = "op"
+	if strings.TrimSpace(want.Born) != "" {
+		id.Born = want.Born
+	}
+	created, err := createIdentity(&opv1.CreateIdentityRequest{
+		GivenName:    want.GivenName,
+		FamilyName:   want.FamilyName,
+		Motto:        want.Motto,
+		Composer:     want.Composer,
+		Clade:        stringToClade(want.Clade),
+		Reproduction: stringToReproduction(want.Reproduction),
+		Lang:         want.Lang,
+		Aliases:      want.Aliases,
+		OutputDir:    filepath.Dir(change.Path),
+	}, id)
+	if err != nil {
+		return err
+	}
+
+	// Status, parents and description are not part of the request, and
+	// Create fills in defaults: bring those fields to the desired values
+	// too, so the next plan finds nothing left to do.
+	have, _, err := identity.ReadIdentity(created.GetFilePath())
+	if err != nil {
+		return err
+	}
+	fields := changedPlanFields(have, want)
+	if len(fields) == 0 {
+		return nil
+	}
+	return applyPlanUpdate(PlanChange{Path: created.GetFilePath(), Source: change.Source, Fields: fields})
+}
+
+// applyPlanUpdate sets the changed fields in the manifest at change.Path,
+// in that file's own format.
+func applyPlanUpdate(change PlanChange) error {
+	want, _, err := identity.ReadIdentity(change.Source)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(change.Path)
+	if err != nil {
+		return err
+	}
+	if filepath.Base(change.Path) == identity.JSONManifestFileName {
+		data, err = updateJSONManifest(data, change.Fields, want)
+	} else {
+		data, err = updateYAMLManifest(data, change.Fields, want)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(change.Path, data, 0o644)
+}
+
+func updateYAMLManifest(data []byte, fields []string, want identity.Identity) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("manifest is not a mapping")
+	}
+	mapping := doc.Content[0]
+	for _, field := range planFields {
+		if slices.Contains(fields, field.key) {
+			setMappingValue(mapping, field.key, planValueNode(field.value(want)))
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// updateJSONManifest sets fields in a holon.json object, keeping its other
+// keys.
+func updateJSONManifest(data []byte, fields []string, want identity.Identity) ([]byte, error) {
+	var manifest map[string]any
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("JSON parse error: %w", err)
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("manifest is not an object")
+	}
+	for _, field := range planFields {
+		if slices.Contains(fields, field.key) {
+			manifest[field.key] = field.value(want)
+		}
+	}
+	out, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+func changedPlanFields(have, want identity.Identity) []string {
+	var fields []string
+	for _, field := range planFields {
+		switch a := field.value(have).(type) {
+		case string:
+			if strings.TrimSpace(a) != strings.TrimSpace(field.value(want).(string)) {
+				fields = append(fields, field.key)
+			}
+		case []string:
+			b := field.value(want).([]string)
+			if len(a) != 0 || len(b) != 0 {
+				if !slices.Equal(a, b) {
+					fields = append(fields, field.key)
+				}
+			}
+		}
+	}
+	return fields
+}
+
+// planValueNode renders a planFields value, a string or a list of
+// strings, as a YAML node.
+func planValueNode(value any) *yaml.Node {
+	switch v := value.(type) {
+	case []string:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+		for _, item := range v {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item, Style: yaml.DoubleQuotedStyle})
+		}
+		return node
+	default:
+		text, _ := v.(string)
+		node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: text}
+		if strings.Contains(text, "\n") {
+			node.Style = yaml.LiteralStyle
+		}
+		return node
+	}
+}
+
+// setMappingValue replaces key's value in a YAML mapping with value,
+// keeping the comments of the value it replaces, and appends the key when
+// it is missing.
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	node := *value
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			node.HeadComment = mapping.Content[i+1].HeadComment
+			node.LineComment = mapping.Content[i+1].LineComment
+			*mapping.Content[i+1] = node
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &node)
+}
+
+func isWithinDir(dirAbs, path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(dirAbs, abs)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func displayName(id identity.Identity) string {
+	return strings.TrimSpace(id.GivenName + " " + id.FamilyName)
+}
@@ -0,0 +1,50 @@
+package who
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/organic-programming/grace-op/internal/holons"
+	"github.com/organic-programming/grace-op/internal/identity"
+)
+
+// Release is one holon's pinned build as recorded by op pin.
+type Release struct {
+	Slug         string               `json:"slug"`
+	UUID         string               `json:"uuid"`
+	RelativePath string               `json:"relative_path"`
+	Pin          *identity.VersionPin `json:"pin,omitempty"`
+	// Pinned is false when the holon has no pinned version.
+	Pinned bool `json:"pinned"`
+}
+
+// Releases lists the pinned build of every holon under root, sorted by
+// slug. Holons without a pin are included with a nil Pin.
+func Releases(root string) ([]Release, error) {
+	if strings.TrimSpace(root) == "" {
+		root = "."
+	}
+	located, err := holons.DiscoverHolons(root)
+	if err != nil {
+		return nil, err
+	}
+
+	releases := make([]Release, 0, len(located))
+	for _, holon := range located {
+		releases = append(releases, Release{
+			Slug:         holon.Identity.Slug(),
+			UUID:         holon.Identity.UUID,
+			RelativePath: filepath.Clean(holon.RelativePath),
+			Pin:          holon.Identity.Pin,
+			Pinned:       holon.Identity.Pin != nil && strings.TrimSpace(holon.Identity.Pin.Version) != "",
+		})
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		if releases[i].Slug == releases[j].Slug {
+			return releases[i].RelativePath < releases[j].RelativePath
+		}
+		return releases[i].Slug < releases[j].Slug
+	})
+	return releases, nil
+}
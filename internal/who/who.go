@@ -61,18 +61,39 @@ func List(root string) (*opv1.ListIdentitiesResponse, error) {
 
 // Show resolves an identity by UUID or prefix, searching local first then cache.
 func Show(target string) (*opv1.ShowIdentityResponse, error) {
+	located, err := resolveIdentity(target)
+	if err != nil {
+		return nil, err
+	}
+
+	path := located.IdentityPath
+	id, raw, err := identity.ReadIdentity(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &opv1.ShowIdentityResponse{
+		Identity:   toProto(id),
+		FilePath:   path,
+		RawContent: string(raw),
+	}, nil
+}
+
+// resolveIdentity finds the one local or cached holon whose UUID is target
+// or starts with it.
+func resolveIdentity(target string) (holons.LocalHolon, error) {
 	target = strings.TrimSpace(target)
 	if target == "" {
-		return nil, fmt.Errorf("uuid is required")
+		return holons.LocalHolon{}, fmt.Errorf("uuid is required")
 	}
 
 	local, err := holons.DiscoverHolons(openv.Root())
 	if err != nil {
-		return nil, err
+		return holons.LocalHolon{}, err
 	}
 	cached, err := holons.DiscoverCachedHolons()
 	if err != nil {
-		return nil, err
+		return holons.LocalHolon{}, err
 	}
 
 	matches := make([]holons.LocalHolon, 0)
@@ -90,23 +111,12 @@ func Show(target string) (*opv1.ShowIdentityResponse, error) {
 	appendMatches(local)
 	appendMatches(cached)
 	if len(matches) == 0 {
-		return nil, fmt.Errorf("holon not found: %s", target)
+		return holons.LocalHolon{}, fmt.Errorf("holon not found: %s", target)
 	}
 	if len(matches) > 1 {
-		return nil, fmt.Errorf("uuid prefix %q is ambiguous", target)
-	}
-
-	path := matches[0].IdentityPath
-	id, raw, err := identity.ReadHolonYAML(path)
-	if err != nil {
-		return nil, err
+		return holons.LocalHolon{}, fmt.Errorf("uuid prefix %q is ambiguous", target)
 	}
-
-	return &opv1.ShowIdentityResponse{
-		Identity:   toProto(id),
-		FilePath:   path,
-		RawContent: string(raw),
-	}, nil
+	return matches[0], nil
 }
 
 // CreateFromJSON creates an identity from a non-interactive JSON payload.
@@ -153,12 +163,19 @@ func CreateInteractive(in io.Reader, out io.Writer) (*opv1.CreateIdentityRespons
 
 // Create creates a new identity and writes holon.yaml.
 func Create(req *opv1.CreateIdentityRequest) (*opv1.CreateIdentityResponse, error) {
+	id := identity.New()
+	id.GeneratedBy = "op"
+	return createIdentity(req, id)
+}
+
+// createIdentity validates req and writes it into holon.yaml over id, which
+// supplies the UUID and birth date: a fresh identity for Create, the
+// desired one for ApplyPlan.
+func createIdentity(req *opv1.CreateIdentityRequest, id identity.Identity) (*opv1.CreateIdentityResponse, error) {
 	if err := validateCreateRequest(req); err != nil {
 		return nil, err
 	}
 
-	id := identity.New()
-	id.GeneratedBy = "op"
 	id.GivenName = strings.TrimSpace(req.GetGivenName())
 	id.FamilyName = strings.TrimSpace(req.GetFamilyName())
 	id.Motto = strings.TrimSpace(req.GetMotto())
@@ -173,15 +190,11 @@ func Create(req *opv1.CreateIdentityRequest) (*opv1.CreateIdentityResponse, erro
 		id.Reproduction = "manual"
 	}
 
-	outputDir := strings.TrimSpace(req.GetOutputDir())
-	if outputDir == "" {
-		outputDir = filepath.Join("holons", slugFor(id.GivenName, id.FamilyName))
-	}
-	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+	outputPath := outputPathFor(req)
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
 		return nil, fmt.Errorf("cannot create directory: %w", err)
 	}
 
-	outputPath := filepath.Join(outputDir, identity.ManifestFileName)
 	if err := writeIdentityYAML(id, outputPath); err != nil {
 		return nil, fmt.Errorf("write holon.yaml: %w", err)
 	}
@@ -192,6 +205,15 @@ func Create(req *opv1.CreateIdentityRequest) (*opv1.CreateIdentityResponse, erro
 	}, nil
 }
 
+// outputPathFor returns where Create writes the manifest for req.
+func outputPathFor(req *opv1.CreateIdentityRequest) string {
+	outputDir := strings.TrimSpace(req.GetOutputDir())
+	if outputDir == "" {
+		outputDir = filepath.Join("holons", slugFor(strings.TrimSpace(req.GetGivenName()), strings.TrimSpace(req.GetFamilyName())))
+	}
+	return filepath.Join(outputDir, identity.ManifestFileName)
+}
+
 func parseCreateIdentityJSON(raw string) (*opv1.CreateIdentityRequest, error) {
 	trimmed := strings.TrimSpace(raw)
 	if trimmed == "" {
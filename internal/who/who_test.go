@@ -4,10 +4,14 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 	"testing"
 
+	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
 	openv "github.com/organic-programming/grace-op/internal/env"
+	"github.com/organic-programming/grace-op/internal/holons"
 	"github.com/organic-programming/grace-op/internal/identity"
 )
 
@@ -161,6 +165,337 @@ func TestListIncludesLocalAndCachedIdentities(t *testing.T) {
 	}
 }
 
+func TestReadBatchFileParsesCSVAndJSON(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "holons.csv")
+	csvData := "given_name,family_name,motto,composer,clade\nAlpha,Builder,Builds.,test,deterministic/pure\nBeta,Probe,Probes.,test,deterministic/io_bound\n"
+	if err := os.WriteFile(csvPath, []byte(csvData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	jsonPath := filepath.Join(dir, "holons.json")
+	jsonData := `[{"givenName":"Gamma","familyName":"Relay","motto":"Relays.","composer":"test","clade":"deterministic/io_bound"}]`
+	if err := os.WriteFile(jsonPath, []byte(jsonData), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := ReadBatchFile(csvPath)
+	if err != nil {
+		t.Fatalf("ReadBatchFile(csv) returned error: %v", err)
+	}
+	if len(rows) != 2 || rows[1].Row != 2 || rows[1].Request.GetFamilyName() != "Probe" {
+		t.Fatalf("csv rows = %+v", rows)
+	}
+
+	rows, err = ReadBatchFile(jsonPath)
+	if err != nil {
+		t.Fatalf("ReadBatchFile(json) returned error: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Request.GetGivenName() != "Gamma" {
+		t.Fatalf("json rows = %+v", rows)
+	}
+}
+
+func TestCreateBatchContinuesPastErrorsUnlessFailFast(t *testing.T) {
+	root := t.TempDir()
+	chdirWhoTest(t, root)
+
+	rows, err := parseBatchJSON([]byte(`[
+		{"given_name":"Alpha","family_name":"Builder","motto":"Builds.","composer":"test","clade":"deterministic/pure"},
+		{"given_name":"Broken","family_name":"Row","composer":"test","clade":"deterministic/pure"},
+		{"given_name":"Gamma","family_name":"Relay","motto":"Relays.","composer":"test","clade":"deterministic/pure"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outcomes := CreateBatch(rows, false)
+	if len(outcomes) != 3 || outcomes[1].Err == nil || outcomes[2].Err != nil {
+		t.Fatalf("outcomes = %+v, want row 2 to fail and row 3 to succeed", outcomes)
+	}
+
+	outcomes = CreateBatch(rows[1:], true)
+	if len(outcomes) != 1 || outcomes[0].Err == nil {
+		t.Fatalf("fail-fast outcomes = %+v, want a single failure", outcomes)
+	}
+}
+
+func TestValidateBatchReportsInvalidRowsWithoutWriting(t *testing.T) {
+	root := t.TempDir()
+	chdirWhoTest(t, root)
+
+	existing := filepath.Join(root, "holons", "taken-name")
+	if err := os.MkdirAll(existing, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(existing, identity.ManifestFileName), []byte("schema: holon/v0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := parseBatchJSON([]byte(`[
+		{"given_name":"Alpha","family_name":"Builder","motto":"Builds.","composer":"test","clade":"deterministic/pure"},
+		{"given_name":"Alpha","family_name":"Builder","motto":"Again.","composer":"test","clade":"deterministic/pure"},
+		{"given_name":"Beta","family_name":"Probe","motto":"Probes.","composer":"test","clade":"deterministic/magic"},
+		{"given_name":"Taken","family_name":"Name","motto":"Exists.","composer":"test","clade":"deterministic/pure"},
+		{"given_name":"Gamma","family_name":"Relay","composer":"test","clade":"deterministic/pure"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outcomes := ValidateBatch(rows)
+	if len(outcomes) != 5 {
+		t.Fatalf("outcomes = %d, want 5", len(outcomes))
+	}
+	if outcomes[0].Err != nil || outcomes[0].Response.GetFilePath() != filepath.Join("holons", "alpha-builder", identity.ManifestFileName) {
+		t.Fatalf("row 1 = %+v, want valid preview", outcomes[0])
+	}
+	wantErrors := []string{"also created by row 1", "invalid clade", "already exists", "motto is required"}
+	for i, want := range wantErrors {
+		if err := outcomes[i+1].Err; err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("row %d error = %v, want %q", i+2, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "holons", "alpha-builder")); !os.IsNotExist(err) {
+		t.Fatalf("ValidateBatch wrote to disk: %v", err)
+	}
+}
+
+func TestPlanAndApplyConvergeIdentities(t *testing.T) {
+	root := t.TempDir()
+	chdirWhoTest(t, root)
+
+	writeManifest := func(path, uuid, given, motto, extra string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		data := "schema: holon/v0\nuuid: \"" + uuid + "\"\ngiven_name: \"" + given + "\"\nfamily_name: \"Holon\"\nmotto: \"" + motto + "\"\ncomposer: \"test\"\nclade: \"deterministic/pure\"\nstatus: draft\n" + extra
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeManifest(filepath.Join(root, "holons", "keep", identity.ManifestFileName), "uuid-keep", "Keep", "Old motto.", "kind: native\nbuild:\n  runner: go-module\n")
+	writeManifest(filepath.Join(root, "holons", "gone", identity.ManifestFileName), "uuid-gone", "Gone", "Bye.", "")
+	writeManifest(filepath.Join(root, "desired", "keep", identity.ManifestFileName), "uuid-keep", "Keep", "New motto.", "")
+	freshJSON := `{"uuid": "uuid-fresh", "given_name": "Fresh", "family_name": "Holon", "motto": "Hello.", "composer": "test", "clade": "deterministic/pure", "status": "draft", "aliases": ["fresh"]}`
+	if err := os.MkdirAll(filepath.Join(root, "desired", "fresh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "desired", "fresh", identity.JSONManifestFileName), []byte(freshJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := Plan("desired", ".")
+	if err != nil {
+		t.Fatalf("Plan returned error: %v", err)
+	}
+	creates, updates, deletes := plan.Counts()
+	if creates != 1 || updates != 1 || deletes != 1 {
+		t.Fatalf("plan counts = %d/%d/%d, want 1/1/1: %+v", creates, updates, deletes, plan.Changes)
+	}
+	if update := plan.Changes[1]; update.Action != PlanUpdate || strings.Join(update.Fields, ",") != "motto" {
+		t.Fatalf("update change = %+v, want motto only", update)
+	}
+
+	applied, err := ApplyPlan(plan, false)
+	if err != nil {
+		t.Fatalf("ApplyPlan returned error: %v", err)
+	}
+	if len(applied) != 2 {
+		t.Fatalf("applied = %+v, want create and update only", applied)
+	}
+
+	kept, raw, err := identity.ReadHolonYAML(filepath.Join(root, "holons", "keep", identity.ManifestFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept.Motto != "New motto." || !strings.Contains(string(raw), "runner: go-module") {
+		t.Fatalf("updated manifest = %s", raw)
+	}
+	fresh, raw, err := identity.ReadHolonYAML(filepath.Join(root, "holons", "fresh-holon", identity.ManifestFileName))
+	if err != nil {
+		t.Fatalf("created manifest unreadable: %v", err)
+	}
+	if fresh.UUID != "uuid-fresh" || !slices.Equal(fresh.Aliases, []string{"fresh"}) || !strings.HasPrefix(string(raw), "schema:") {
+		t.Fatalf("created manifest = %s, want holon.yaml carrying the desired uuid and aliases", raw)
+	}
+	if _, err := os.Stat(filepath.Join(root, "holons", "gone", identity.ManifestFileName)); err != nil {
+		t.Fatalf("delete applied without prune: %v", err)
+	}
+
+	plan, err = Plan("desired", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != 1 || plan.Changes[0].Action != PlanDelete {
+		t.Fatalf("second plan = %+v, want only the pending delete", plan.Changes)
+	}
+	if _, err := ApplyPlan(plan, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "holons", "gone", identity.ManifestFileName)); !os.IsNotExist(err) {
+		t.Fatalf("prune did not delete manifest: %v", err)
+	}
+
+	plan, err = Plan("desired", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != 0 {
+		t.Fatalf("plan after apply = %+v, want no changes", plan.Changes)
+	}
+}
+
+func TestPinVersionFromBinary(t *testing.T) {
+	root := t.TempDir()
+	chdirWhoTest(t, root)
+
+	id := identity.New()
+	id.GivenName = "Pinned"
+	id.FamilyName = "Holon"
+	id.Motto = "Stays put."
+	id.Composer = "test"
+	id.Clade = "deterministic/pure"
+	id.Reproduction = "manual"
+	id.Lang = "go"
+	id.UUID = "def67890-0000-0000-0000-000000000000"
+	dir := filepath.Join(root, "holons", "pinned-holon")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, identity.ManifestFileName)
+	if err := identity.WriteHolonYAML(id, path); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = manifest.WriteString("kind: native\nbuild:\n  runner: go-module\nartifacts:\n  binary: pinned-holon\n")
+	if closeErr := manifest.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	binary := filepath.Join(root, "pinned")
+	script := "#!/bin/sh\necho '{\"version\":\"1.2.3\",\"commit\":\"abc1234\",\"os\":\"linux\",\"arch\":\"arm64\"}'\n"
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	pin, source, err := DetectBinaryVersion(binary)
+	if err != nil {
+		t.Fatalf("DetectBinaryVersion returned error: %v", err)
+	}
+	want := identity.VersionPin{Version: "1.2.3", Commit: "abc1234", OS: "linux", Arch: "arm64"}
+	if pin != want || source != PinSourceVersionCommand {
+		t.Fatalf("detected %+v from %q, want %+v from %q", pin, source, want, PinSourceVersionCommand)
+	}
+
+	result, err := PinVersion("def678", pin)
+	if err != nil {
+		t.Fatalf("PinVersion returned error: %v", err)
+	}
+	if result.Path != path || result.UUID != id.UUID {
+		t.Fatalf("result = %+v", result)
+	}
+	got, _, err := identity.ReadHolonYAML(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Pin == nil || *got.Pin != want || got.Motto != id.Motto {
+		t.Fatalf("identity after pin = %+v (pin %+v)", got, got.Pin)
+	}
+	if _, err := holons.LoadManifest(dir); err != nil {
+		t.Fatalf("manifest with a pin should still load: %v", err)
+	}
+}
+
+func TestVersionFromGoBuildInfo(t *testing.T) {
+	// The test binary carries Go build info. It is not run, since it would
+	// treat the version command as a test run.
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pin, err := versionFromBuildInfo(exe)
+	if err != nil {
+		t.Fatalf("versionFromBuildInfo returned error: %v", err)
+	}
+	if pin.OS != runtime.GOOS || pin.Arch != runtime.GOARCH {
+		t.Fatalf("detected %+v, want %s/%s", pin, runtime.GOOS, runtime.GOARCH)
+	}
+}
+
+func TestDeleteGuardsStableHolonsAndTheRoot(t *testing.T) {
+	root := t.TempDir()
+	chdirWhoTest(t, root)
+
+	write := func(slug, uuid, status string) string {
+		t.Helper()
+		id := identity.New()
+		id.GivenName = slug
+		id.FamilyName = "Holon"
+		id.Motto = "Goes away."
+		id.Composer = "test"
+		id.Clade = "deterministic/pure"
+		id.Reproduction = "manual"
+		id.Lang = "go"
+		id.UUID = uuid
+		id.Status = status
+		dir := filepath.Join(root, "holons", slug)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := identity.WriteHolonYAML(id, filepath.Join(dir, identity.ManifestFileName)); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	draftDir := write("draft", "aaa11111-0000-0000-0000-000000000000", "draft")
+	stableDir := write("stable", "bbb22222-0000-0000-0000-000000000000", "stable")
+
+	if _, err := Delete(&opv1.DeleteIdentityRequest{Uuid: "aaa11111"}); err == nil || !strings.Contains(err.Error(), "full uuid") {
+		t.Fatalf("error = %v; a prefix should not be enough to delete", err)
+	}
+
+	resp, err := Delete(&opv1.DeleteIdentityRequest{Uuid: "aaa11111-0000-0000-0000-000000000000"})
+	if err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+	if resp.GetRemovedPath() != filepath.Join(draftDir, identity.ManifestFileName) {
+		t.Fatalf("removed path = %q", resp.GetRemovedPath())
+	}
+	if _, err := os.Stat(draftDir); err != nil {
+		t.Fatalf("without --purge the directory should stay: %v", err)
+	}
+
+	stable := &opv1.DeleteIdentityRequest{Uuid: "bbb22222-0000-0000-0000-000000000000", Purge: true}
+	if _, err := Delete(stable); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("error = %v; a stable holon needs --force", err)
+	}
+	stable.Force = true
+	if _, err := Delete(stable); err != nil {
+		t.Fatalf("forced Delete returned error: %v", err)
+	}
+	if _, err := os.Stat(stableDir); !os.IsNotExist(err) {
+		t.Fatalf("--purge should remove %s, stat err = %v", stableDir, err)
+	}
+
+	outside := t.TempDir()
+	link := filepath.Join(root, "holons", "elsewhere")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	for _, path := range []string{root, link, filepath.Join(root, "..")} {
+		if err := checkInsideRoot(root, path); err == nil {
+			t.Fatalf("checkInsideRoot(%q) should refuse", path)
+		}
+	}
+}
+
 func chdirWhoTest(t *testing.T, dir string) {
 	t.Helper()
 
@@ -0,0 +1,53 @@
+package grpcclient
+
+import (
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+var (
+	dialOptionsMu sync.Mutex
+	authority     string
+)
+
+// SetAuthority overrides the :authority pseudo-header of every connection
+// opened afterwards, for servers behind a proxy or gateway that routes on
+// it. An empty host restores the default, derived from the dial target.
+// restore puts back the host set before.
+func SetAuthority(host string) (restore func()) {
+	dialOptionsMu.Lock()
+	defer dialOptionsMu.Unlock()
+	previous := authority
+	authority = host
+	return func() {
+		dialOptionsMu.Lock()
+		defer dialOptionsMu.Unlock()
+		authority = previous
+	}
+}
+
+func currentAuthority() string {
+	dialOptionsMu.Lock()
+	defer dialOptionsMu.Unlock()
+	return authority
+}
+
+// DialOptions returns the options every client connection op opens shares:
+// plaintext transport, the :authority override and keepalive pings, if set,
+// followed by extra.
+func DialOptions(extra ...grpc.DialOption) []grpc.DialOption {
+	dialOptionsMu.Lock()
+	host, params := authority, keepalives
+	dialOptionsMu.Unlock()
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if host != "" {
+		opts = append(opts, grpc.WithAuthority(host))
+	}
+	if params.Time > 0 {
+		opts = append(opts, keepaliveDialOption(params))
+	}
+	return append(opts, extra...)
+}
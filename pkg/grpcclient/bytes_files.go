@@ -0,0 +1,240 @@
+package grpcclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var (
+	bytesFilesMu   sync.Mutex
+	fileReferences bool
+	bytesOutputDir string
+)
+
+// SetFileReferences makes ParseInput read a bytes field given as
+// "@path/to/file" from that file. It is off by default so that input from
+// a remote caller, such as an MCP client, can never name a local file.
+// restore puts back the setting before.
+func SetFileReferences(enabled bool) (restore func()) {
+	bytesFilesMu.Lock()
+	defer bytesFilesMu.Unlock()
+	previous := fileReferences
+	fileReferences = enabled
+	return func() {
+		bytesFilesMu.Lock()
+		defer bytesFilesMu.Unlock()
+		fileReferences = previous
+	}
+}
+
+// FileReferences reports whether ParseInput reads "@path" bytes fields from
+// files.
+func FileReferences() bool {
+	bytesFilesMu.Lock()
+	defer bytesFilesMu.Unlock()
+	return fileReferences
+}
+
+// SetBytesOutputDir makes MarshalOutput write every bytes field of a
+// response to a file in dir and print "@<file>" in its place, the form
+// ParseInput reads back. An empty dir restores inline base64. restore puts
+// back the dir set before.
+func SetBytesOutputDir(dir string) (restore func()) {
+	bytesFilesMu.Lock()
+	defer bytesFilesMu.Unlock()
+	previous := bytesOutputDir
+	bytesOutputDir = dir
+	return func() {
+		bytesFilesMu.Lock()
+		defer bytesFilesMu.Unlock()
+		bytesOutputDir = previous
+	}
+}
+
+// MarshalOutput renders a response as compact protojson, with its bytes
+// fields written to files when SetBytesOutputDir is set.
+func MarshalOutput(msg proto.Message) ([]byte, error) {
+	out, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	bytesFilesMu.Lock()
+	dir := bytesOutputDir
+	bytesFilesMu.Unlock()
+	if dir == "" {
+		return out, nil
+	}
+	return rewriteBytesFields(out, msg.ProtoReflect().Descriptor(), func(path, value string) (string, error) {
+		return writeBytesFile(dir, path, value)
+	})
+}
+
+// expandFileReferences replaces every "@path" value of a bytes field in
+// input with the base64 of the file at path. Base64 never contains '@', so
+// no literal value is mistaken for a reference.
+func expandFileReferences(input []byte, message protoreflect.MessageDescriptor) ([]byte, error) {
+	if !FileReferences() || !bytes.Contains(input, []byte(`"@`)) {
+		return input, nil
+	}
+	return rewriteBytesFields(input, message, func(path, value string) (string, error) {
+		file, ok := strings.CutPrefix(value, "@")
+		if !ok {
+			return value, nil
+		}
+		if file == "" {
+			return "", fmt.Errorf("field %s: @ needs a file path", path)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("field %s: %w", path, err)
+		}
+		return base64.StdEncoding.EncodeToString(data), nil
+	})
+}
+
+// rewriteBytesFields calls rewrite on the JSON value of every bytes field
+// in doc, a message of type message, and returns doc with the results in
+// their place. doc is returned untouched when nothing changed, or when it
+// is not a JSON object: decoding reports that better.
+func rewriteBytesFields(doc []byte, message protoreflect.MessageDescriptor, rewrite func(path, value string) (string, error)) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.UseNumber()
+	var root map[string]any
+	if err := decoder.Decode(&root); err != nil {
+		return doc, nil
+	}
+	changed := false
+	err := walkBytesFields(root, message, "", func(path, value string) (string, error) {
+		out, err := rewrite(path, value)
+		if err == nil && out != value {
+			changed = true
+		}
+		return out, err
+	})
+	if err != nil || !changed {
+		return doc, err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(root); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// walkBytesFields visits the bytes fields of obj, including those of
+// nested messages, repeated fields and map values. Keys may be JSON or
+// proto field names; unknown keys and well-known types other than
+// BytesValue are left alone.
+func walkBytesFields(obj map[string]any, message protoreflect.MessageDescriptor, prefix string, rewrite func(path, value string) (string, error)) error {
+	fields := message.Fields()
+	for key, value := range obj {
+		field := fields.ByJSONName(key)
+		if field == nil {
+			field = fields.ByName(protoreflect.Name(key))
+		}
+		if field == nil {
+			continue
+		}
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		var err error
+		switch {
+		case field.IsMap():
+			entries, _ := value.(map[string]any)
+			for k, v := range entries {
+				if entries[k], err = rewriteBytesValue(field.MapValue(), v, path+"."+k, rewrite); err != nil {
+					return err
+				}
+			}
+		case field.IsList():
+			items, _ := value.([]any)
+			for i, v := range items {
+				if items[i], err = rewriteBytesValue(field, v, path+"."+strconv.Itoa(i), rewrite); err != nil {
+					return err
+				}
+			}
+		default:
+			if obj[key], err = rewriteBytesValue(field, value, path, rewrite); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func rewriteBytesValue(field protoreflect.FieldDescriptor, value any, path string, rewrite func(path, value string) (string, error)) (any, error) {
+	if field.Kind() == protoreflect.BytesKind {
+		if text, ok := value.(string); ok {
+			return rewrite(path, text)
+		}
+		return value, nil
+	}
+	message := field.Message()
+	if message == nil {
+		return value, nil
+	}
+	if message.FullName() == "google.protobuf.BytesValue" {
+		if text, ok := value.(string); ok {
+			return rewrite(path, text)
+		}
+		return value, nil
+	}
+	if obj, ok := value.(map[string]any); ok && message.ParentFile().Package() != "google.protobuf" {
+		return value, walkBytesFields(obj, message, path, rewrite)
+	}
+	return value, nil
+}
+
+// writeBytesFile decodes value and writes it to dir under the field's
+// path. An existing file is never replaced: the name gains a -1, -2, ...
+// suffix instead, so every response of a stream keeps its own file.
+func writeBytesFile(dir, path, value string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		if data, err = base64.URLEncoding.DecodeString(value); err != nil {
+			return "", fmt.Errorf("field %s: invalid base64: %w", path, err)
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	base := filepath.Join(dir, strings.NewReplacer("/", "_", `\`, "_").Replace(path))
+	name := base
+	for n := 1; ; n++ {
+		file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			name = fmt.Sprintf("%s-%d", base, n)
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("field %s: %w", path, err)
+		}
+		if _, err := file.Write(data); err != nil {
+			_ = file.Close()
+			return "", fmt.Errorf("field %s: %w", path, err)
+		}
+		if err := file.Close(); err != nil {
+			return "", fmt.Errorf("field %s: %w", path, err)
+		}
+		return "@" + name, nil
+	}
+}
@@ -0,0 +1,165 @@
+package grpcclient
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// blobDescriptor describes
+//
+//	message Blob {
+//	  string name = 1; bytes data = 2; repeated bytes chunks = 3;
+//	  Blob child = 4; map<string, bytes> files = 5;
+//	}
+func blobDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	field := func(name string, number int32, label *descriptorpb.FieldDescriptorProto_Label, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(number), Label: label, Type: typ.Enum(), JsonName: proto.String(name)}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("blob.proto"),
+		Package: proto.String("blob.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Blob"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, optional, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("data", 2, optional, descriptorpb.FieldDescriptorProto_TYPE_BYTES, ""),
+				field("chunks", 3, repeated, descriptorpb.FieldDescriptorProto_TYPE_BYTES, ""),
+				field("child", 4, optional, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".blob.v1.Blob"),
+				field("files", 5, repeated, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".blob.v1.Blob.FilesEntry"),
+			},
+			NestedType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("FilesEntry"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("key", 1, optional, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+					field("value", 2, optional, descriptorpb.FieldDescriptorProto_TYPE_BYTES, ""),
+				},
+				Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+			}},
+		}},
+	}
+	fd, err := protodesc.NewFile(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd.Messages().Get(0)
+}
+
+func TestParseInputReadsFileReferences(t *testing.T) {
+	desc := blobDescriptor(t)
+	dir := t.TempDir()
+	image := filepath.Join(dir, "image.png")
+	if err := os.WriteFile(image, []byte{0x89, 'P', 'N', 'G'}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	input := `{"name":"@not-a-file","data":"@` + image + `","chunks":["@` + image + `","AQI="],` +
+		`"child":{"data":"@` + image + `"},"files":{"a":"@` + image + `"}}`
+
+	msg := dynamicpb.NewMessage(desc)
+	if err := ParseInput([]byte(input), msg); err == nil {
+		t.Fatal("file references were read without SetFileReferences")
+	}
+
+	t.Cleanup(SetFileReferences(true))
+	msg = dynamicpb.NewMessage(desc)
+	if err := ParseInput([]byte(input), msg); err != nil {
+		t.Fatal(err)
+	}
+	want := string([]byte{0x89, 'P', 'N', 'G'})
+	fields := desc.Fields()
+	if got := msg.Get(fields.ByName("name")).String(); got != "@not-a-file" {
+		t.Fatalf("name = %q, want string fields left alone", got)
+	}
+	if got := string(msg.Get(fields.ByName("data")).Bytes()); got != want {
+		t.Fatalf("data = %q, want the file's content", got)
+	}
+	chunks := msg.Get(fields.ByName("chunks")).List()
+	if chunks.Len() != 2 || string(chunks.Get(0).Bytes()) != want || string(chunks.Get(1).Bytes()) != "\x01\x02" {
+		t.Fatalf("chunks = %v, want the file then the inline base64", chunks)
+	}
+	child := msg.Get(fields.ByName("child")).Message()
+	if got := string(child.Get(fields.ByName("data")).Bytes()); got != want {
+		t.Fatalf("child.data = %q, want the file's content", got)
+	}
+	files := msg.Get(fields.ByName("files")).Map()
+	if got := string(files.Get(protoreflect.ValueOfString("a").MapKey()).Bytes()); got != want {
+		t.Fatalf("files[a] = %q, want the file's content", got)
+	}
+
+	err := ParseInput([]byte(`{"data":"@`+filepath.Join(dir, "missing")+`"}`), dynamicpb.NewMessage(desc))
+	if err == nil || !strings.Contains(err.Error(), "field data") {
+		t.Fatalf("missing file error = %v, want it to name the field", err)
+	}
+}
+
+func TestParseInputBoundsInputWithFileReferencesRead(t *testing.T) {
+	desc := blobDescriptor(t)
+	large := filepath.Join(t.TempDir(), "large.bin")
+	if err := os.WriteFile(large, make([]byte, 1024), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(SetFileReferences(true))
+	t.Cleanup(SetMaxInputSize(512))
+
+	input := `{"data":"@` + large + `"}`
+	err := ParseInput([]byte(input), dynamicpb.NewMessage(desc))
+	if err == nil || !strings.Contains(err.Error(), "over the 512-byte limit") {
+		t.Fatalf("error = %v, want the limit applied to the file's content", err)
+	}
+}
+
+func TestMarshalOutputWritesBytesFieldsToFiles(t *testing.T) {
+	desc := blobDescriptor(t)
+	msg := dynamicpb.NewMessage(desc)
+	fields := desc.Fields()
+	msg.Set(fields.ByName("name"), protoreflect.ValueOfString("<logo>"))
+	msg.Set(fields.ByName("data"), protoreflect.ValueOfBytes([]byte("payload")))
+
+	dir := filepath.Join(t.TempDir(), "out")
+	t.Cleanup(SetBytesOutputDir(dir))
+
+	out, err := MarshalOutput(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "data")
+	if want := `{"data":"@` + file + `","name":"<logo>"}`; string(out) != want {
+		t.Fatalf("output = %s, want %s", out, want)
+	}
+	if data, err := os.ReadFile(file); err != nil || string(data) != "payload" {
+		t.Fatalf("%s = %q, %v; want the decoded bytes", file, data, err)
+	}
+
+	again, err := MarshalOutput(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(again), `"@`+file+`-1"`) {
+		t.Fatalf("second output = %s, want a new file beside the first", again)
+	}
+
+	// The output reads back as input.
+	t.Cleanup(SetFileReferences(true))
+	back := dynamicpb.NewMessage(desc)
+	if err := ParseInput(out, back); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(back, msg) {
+		t.Fatalf("round trip = %v, want %v", back, msg)
+	}
+}
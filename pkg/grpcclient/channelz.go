@@ -0,0 +1,207 @@
+package grpcclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+)
+
+// ChannelzDump is what a server's grpc.channelz.v1 service reports: its top
+// channels (outgoing connections) and servers, each with their sockets.
+type ChannelzDump struct {
+	Address  string            `json:"address"`
+	Channels []ChannelzChannel `json:"channels"`
+	Servers  []ChannelzServer  `json:"servers"`
+}
+
+// ChannelzCalls counts the calls a channel, subchannel or server has seen.
+type ChannelzCalls struct {
+	Started   int64 `json:"started"`
+	Succeeded int64 `json:"succeeded"`
+	Failed    int64 `json:"failed"`
+}
+
+// ChannelzChannel is a top channel or one of its subchannels.
+type ChannelzChannel struct {
+	ID          int64             `json:"id"`
+	Name        string            `json:"name,omitempty"`
+	Target      string            `json:"target,omitempty"`
+	State       string            `json:"state"`
+	Calls       ChannelzCalls     `json:"calls"`
+	Subchannels []ChannelzChannel `json:"subchannels,omitempty"`
+	Sockets     []ChannelzSocket  `json:"sockets,omitempty"`
+}
+
+// ChannelzServer is one gRPC server in the inspected process.
+type ChannelzServer struct {
+	ID            int64            `json:"id"`
+	Name          string           `json:"name,omitempty"`
+	Calls         ChannelzCalls    `json:"calls"`
+	ListenSockets []ChannelzSocket `json:"listen_sockets,omitempty"`
+	Sockets       []ChannelzSocket `json:"sockets,omitempty"`
+}
+
+// ChannelzSocket is one connection (or listener) with its stream and
+// message counters.
+type ChannelzSocket struct {
+	ID               int64  `json:"id"`
+	Name             string `json:"name,omitempty"`
+	Local            string `json:"local,omitempty"`
+	Remote           string `json:"remote,omitempty"`
+	StreamsStarted   int64  `json:"streams_started"`
+	StreamsSucceeded int64  `json:"streams_succeeded"`
+	StreamsFailed    int64  `json:"streams_failed"`
+	MessagesSent     int64  `json:"messages_sent"`
+	MessagesReceived int64  `json:"messages_received"`
+}
+
+// DumpChannelz reads the channelz service at address: every top channel with
+// its subchannels, and every server with its listen and connection sockets.
+// The server must register channelz (op serve --channelz).
+func DumpChannelz(address string) (*ChannelzDump, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout())
+	defer cancel()
+
+	conn, err := grpc.NewClient(address, DialOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", address, err)
+	}
+	defer conn.Close()
+
+	client := channelzpb.NewChannelzClient(conn)
+	dump := &ChannelzDump{Address: address}
+
+	for start := int64(0); ; {
+		resp, err := client.GetTopChannels(ctx, &channelzpb.GetTopChannelsRequest{StartChannelId: start})
+		if err != nil {
+			return nil, fmt.Errorf("channelz not available at %s: %w", address, err)
+		}
+		for _, ch := range resp.GetChannel() {
+			dump.Channels = append(dump.Channels, channelzChannel(ctx, client, ch.GetRef().GetChannelId(), ch.GetRef().GetName(), ch.GetData(), ch.GetSubchannelRef(), ch.GetSocketRef()))
+			start = ch.GetRef().GetChannelId() + 1
+		}
+		if resp.GetEnd() || len(resp.GetChannel()) == 0 {
+			break
+		}
+	}
+
+	for start := int64(0); ; {
+		resp, err := client.GetServers(ctx, &channelzpb.GetServersRequest{StartServerId: start})
+		if err != nil {
+			return nil, fmt.Errorf("list servers: %w", err)
+		}
+		for _, srv := range resp.GetServer() {
+			server, err := channelzServer(ctx, client, srv)
+			if err != nil {
+				return nil, err
+			}
+			dump.Servers = append(dump.Servers, server)
+			start = srv.GetRef().GetServerId() + 1
+		}
+		if resp.GetEnd() || len(resp.GetServer()) == 0 {
+			break
+		}
+	}
+	return dump, nil
+}
+
+func channelzChannel(
+	ctx context.Context,
+	client channelzpb.ChannelzClient,
+	id int64,
+	name string,
+	data *channelzpb.ChannelData,
+	subchannels []*channelzpb.SubchannelRef,
+	sockets []*channelzpb.SocketRef,
+) ChannelzChannel {
+	channel := ChannelzChannel{
+		ID:     id,
+		Name:   name,
+		Target: data.GetTarget(),
+		State:  data.GetState().GetState().String(),
+		Calls: ChannelzCalls{
+			Started:   data.GetCallsStarted(),
+			Succeeded: data.GetCallsSucceeded(),
+			Failed:    data.GetCallsFailed(),
+		},
+		Sockets: channelzSockets(ctx, client, sockets),
+	}
+	for _, ref := range subchannels {
+		resp, err := client.GetSubchannel(ctx, &channelzpb.GetSubchannelRequest{SubchannelId: ref.GetSubchannelId()})
+		if err != nil {
+			channel.Subchannels = append(channel.Subchannels, ChannelzChannel{ID: ref.GetSubchannelId(), Name: ref.GetName(), State: "UNKNOWN"})
+			continue
+		}
+		sub := resp.GetSubchannel()
+		channel.Subchannels = append(channel.Subchannels, channelzChannel(ctx, client, ref.GetSubchannelId(), ref.GetName(), sub.GetData(), sub.GetSubchannelRef(), sub.GetSocketRef()))
+	}
+	return channel
+}
+
+func channelzServer(ctx context.Context, client channelzpb.ChannelzClient, srv *channelzpb.Server) (ChannelzServer, error) {
+	server := ChannelzServer{
+		ID:   srv.GetRef().GetServerId(),
+		Name: srv.GetRef().GetName(),
+		Calls: ChannelzCalls{
+			Started:   srv.GetData().GetCallsStarted(),
+			Succeeded: srv.GetData().GetCallsSucceeded(),
+			Failed:    srv.GetData().GetCallsFailed(),
+		},
+		ListenSockets: channelzSockets(ctx, client, srv.GetListenSocket()),
+	}
+
+	var refs []*channelzpb.SocketRef
+	for start := int64(0); ; {
+		resp, err := client.GetServerSockets(ctx, &channelzpb.GetServerSocketsRequest{ServerId: server.ID, StartSocketId: start})
+		if err != nil {
+			return server, fmt.Errorf("list sockets of server %d: %w", server.ID, err)
+		}
+		for _, ref := range resp.GetSocketRef() {
+			refs = append(refs, ref)
+			start = ref.GetSocketId() + 1
+		}
+		if resp.GetEnd() || len(resp.GetSocketRef()) == 0 {
+			break
+		}
+	}
+	server.Sockets = channelzSockets(ctx, client, refs)
+	return server, nil
+}
+
+func channelzSockets(ctx context.Context, client channelzpb.ChannelzClient, refs []*channelzpb.SocketRef) []ChannelzSocket {
+	var sockets []ChannelzSocket
+	for _, ref := range refs {
+		socket := ChannelzSocket{ID: ref.GetSocketId(), Name: ref.GetName()}
+		if resp, err := client.GetSocket(ctx, &channelzpb.GetSocketRequest{SocketId: ref.GetSocketId()}); err == nil {
+			s := resp.GetSocket()
+			socket.Local = channelzAddress(s.GetLocal())
+			socket.Remote = channelzAddress(s.GetRemote())
+			socket.StreamsStarted = s.GetData().GetStreamsStarted()
+			socket.StreamsSucceeded = s.GetData().GetStreamsSucceeded()
+			socket.StreamsFailed = s.GetData().GetStreamsFailed()
+			socket.MessagesSent = s.GetData().GetMessagesSent()
+			socket.MessagesReceived = s.GetData().GetMessagesReceived()
+		}
+		sockets = append(sockets, socket)
+	}
+	return sockets
+}
+
+func channelzAddress(addr *channelzpb.Address) string {
+	switch {
+	case addr.GetTcpipAddress() != nil:
+		tcp := addr.GetTcpipAddress()
+		return net.JoinHostPort(net.IP(tcp.GetIpAddress()).String(), strconv.Itoa(int(tcp.GetPort())))
+	case addr.GetUdsAddress() != nil:
+		return "unix://" + addr.GetUdsAddress().GetFilename()
+	case addr.GetOtherAddress() != nil:
+		return strings.TrimSpace(addr.GetOtherAddress().GetName())
+	default:
+		return ""
+	}
+}
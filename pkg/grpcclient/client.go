@@ -0,0 +1,677 @@
+// Package grpcclient connects to a holon's gRPC server and forwards calls.
+// It uses gRPC reflection to discover available services and methods,
+// making it work with ANY holon regardless of implementation language.
+package grpcclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"nhooyr.io/websocket"
+)
+
+// CallResult holds the output of a gRPC call. Wire is the serialized output
+// message, kept for callers that want the protobuf wire format.
+type CallResult struct {
+	Service string `json:"service"`
+	Method  string `json:"method"`
+	Output  string `json:"output"`
+	Wire    []byte `json:"-"`
+	// Streamed is set when the method streamed its responses to the
+	// context's StreamSink; Output and Wire are then empty.
+	Streamed bool `json:"-"`
+	// Resolution says where the method's descriptor came from, one of the
+	// Resolved* values; empty when the caller resolved it itself.
+	Resolution string `json:"-"`
+}
+
+// Values of CallResult.Resolution.
+const (
+	ResolvedByDescriptors = "compiled-in or loaded descriptors"
+	ResolvedByReflection  = "server reflection"
+)
+
+// Client calls the methods of one holon by name, with JSON requests and
+// responses. NewClient talks to a server; package pkg/grpcclient/fake
+// answers from in-process handlers, for unit tests of tooling built on op.
+type Client interface {
+	// Call invokes method, either "Method" or "package.Service/Method", with
+	// inputJSON ("" means an empty message).
+	Call(ctx context.Context, method, inputJSON string) (*CallResult, error)
+	// ListMethods returns the methods as "package.Service/Method".
+	ListMethods(ctx context.Context) ([]string, error)
+	// Close releases the connection, if any.
+	Close() error
+}
+
+// NewClient returns a Client for the gRPC server at address. It connects
+// on the first call and keeps the connection, and the descriptors it
+// resolves by reflection, until Close.
+func NewClient(address string) Client {
+	return newServerClient(address)
+}
+
+// NewConnClient returns a Client over conn, a connection made by other
+// means than an address, such as a mem:// dial to an in-process server.
+// name stands for the address in errors. Close closes conn, and the client
+// cannot reconnect after it.
+func NewConnClient(name string, conn *grpc.ClientConn) Client {
+	return newConnClient(name, conn)
+}
+
+// Dial connects to a gRPC server at the given address and calls a method.
+// It uses server reflection to discover the service and method descriptors,
+// so it works with any holon in any language.
+func Dial(address, methodName string, inputJSON string) (*CallResult, error) {
+	return DialContext(context.Background(), address, methodName, inputJSON)
+}
+
+// DialContext is Dial bound to ctx: cancelling ctx abandons the call.
+func DialContext(ctx context.Context, address, methodName string, inputJSON string) (*CallResult, error) {
+	result, err := dialAndCall(ctx, address, methodName, inputJSON)
+	return result, ExplainHTTP1Error(err, address, nil)
+}
+
+// dialAndCall connects to address with DialOptions followed by extra, then
+// calls methodName through compiled-in descriptors or reflection.
+func dialAndCall(ctx context.Context, address, methodName, inputJSON string, extra ...grpc.DialOption) (*CallResult, error) {
+	client := newServerClient(address, extra...)
+	defer client.Close()
+	return client.call(ctx, methodName, inputJSON)
+}
+
+// ListMethods returns all available service methods at the given address,
+// as "service/Method" names. extra dial options follow DialOptions, e.g.
+// TLSDialOption.
+func ListMethods(address string, extra ...grpc.DialOption) ([]string, error) {
+	detailed, err := ListMethodsDetailed(address, extra...)
+	if err != nil {
+		return nil, err
+	}
+	methods := make([]string, 0, len(detailed))
+	for _, method := range detailed {
+		methods = append(methods, method.FullName())
+	}
+	return methods, nil
+}
+
+// ListMethodsDetailed is ListMethods with each method's request and
+// response types and streaming flags.
+func ListMethodsDetailed(address string, extra ...grpc.DialOption) ([]MethodInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout())
+	defer cancel()
+
+	conn, err := grpc.NewClient(address, DialOptions(extra...)...)
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", address, err)
+	}
+	defer conn.Close()
+
+	services, err := ResolveServices(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	return DescribeServiceMethods(services), nil
+}
+
+// --- Internal helpers ---
+
+// ResolveService fetches the descriptor of serviceName over a reflection
+// stream opened by OpenReflection, so it works with v1 and v1alpha alike.
+// Dependencies missing from the first answer are requested by file name.
+func ResolveService(stream ReflectionStream, serviceName string) (protoreflect.ServiceDescriptor, error) {
+	raw, err := requestSymbolFiles(stream, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	return buildService(stream, serviceName, raw)
+}
+
+// requestSymbolFiles returns the serialized file descriptors the server
+// sends for serviceName, before any missing dependency is chased.
+func requestSymbolFiles(stream ReflectionStream, serviceName string) ([][]byte, error) {
+	if err := stream.Send(&grpc_reflection_v1alpha.ServerReflectionRequest{
+		MessageRequest: &grpc_reflection_v1alpha.ServerReflectionRequest_FileContainingSymbol{
+			FileContainingSymbol: serviceName,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	fdResp := resp.GetFileDescriptorResponse()
+	if fdResp == nil {
+		return nil, fmt.Errorf("no file descriptor for %s", serviceName)
+	}
+	return fdResp.FileDescriptorProto, nil
+}
+
+// requestSymbolFilesBatch is requestSymbolFiles for several services. The
+// requests are all sent before any answer is read, so the server answers
+// them back to back instead of waiting a round trip for each. Answers come
+// in request order: raw[i] belongs to names[i], and errs[i] is set when the
+// server had no files for it. Only a broken stream fails the whole batch.
+// An auto stream must have settled on v1 or v1alpha first, as ListServices
+// does.
+func requestSymbolFilesBatch(stream ReflectionStream, names []string) (raw [][][]byte, errs []error, err error) {
+	if err := pipelineReady(stream); err != nil {
+		return nil, nil, err
+	}
+	for _, name := range names {
+		if err := stream.Send(&grpc_reflection_v1alpha.ServerReflectionRequest{
+			MessageRequest: &grpc_reflection_v1alpha.ServerReflectionRequest_FileContainingSymbol{
+				FileContainingSymbol: name,
+			},
+		}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	raw = make([][][]byte, len(names))
+	errs = make([]error, len(names))
+	for i, name := range names {
+		resp, err := stream.Recv()
+		if err != nil {
+			return nil, nil, err
+		}
+		if fdResp := resp.GetFileDescriptorResponse(); fdResp != nil {
+			raw[i] = fdResp.FileDescriptorProto
+		} else {
+			errs[i] = fmt.Errorf("no file descriptor for %s", name)
+		}
+	}
+	return raw, errs, nil
+}
+
+// buildService parses raw, the answer to requestSymbolFiles, requests the
+// dependencies it lacks over stream and returns serviceName's descriptor.
+func buildService(stream ReflectionStream, serviceName string, raw [][]byte) (protoreflect.ServiceDescriptor, error) {
+	files, err := collectServiceFiles(stream, raw)
+	if err != nil {
+		return nil, err
+	}
+	return buildServiceFromFiles(files, serviceName)
+}
+
+// collectServiceFiles parses raw, the answer to requestSymbolFiles, and
+// requests the dependencies it lacks over stream. It returns every file the
+// service needs, in the order they were found.
+func collectServiceFiles(stream ReflectionStream, raw [][]byte) ([]*descriptorpb.FileDescriptorProto, error) {
+	// Parse descriptors returned for the symbol first.
+	filesByName := make(map[string]*descriptorpb.FileDescriptorProto)
+	var queue []string
+	for _, b := range raw {
+		fd := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(b, fd); err != nil {
+			return nil, fmt.Errorf("unmarshal file descriptor: %w", err)
+		}
+		name := fd.GetName()
+		if name == "" {
+			continue
+		}
+		if _, exists := filesByName[name]; exists {
+			continue
+		}
+		filesByName[name] = fd
+		queue = append(queue, name)
+	}
+
+	// Some transports/reflection stacks do not include transitive imports in the
+	// initial response. Resolve any missing dependencies on-demand.
+	for i := 0; i < len(queue); i++ {
+		fd := filesByName[queue[i]]
+		for _, dep := range fd.GetDependency() {
+			if _, exists := filesByName[dep]; exists {
+				continue
+			}
+
+			// Some servers register descriptors under a prefixed name such as
+			// "protos/"; ResolveDependency tries each configured prefix.
+			depFiles, err := ResolveDependency(dep, func(name string) ([]*descriptorpb.FileDescriptorProto, error) {
+				return resolveFileByName(stream, name)
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			aliasSourceName := ""
+			for _, depFD := range depFiles {
+				name := depFD.GetName()
+				if name == "" || name == dep {
+					continue
+				}
+				if strings.HasSuffix(name, dep) {
+					aliasSourceName = name
+					break
+				}
+			}
+
+			resolvedDepName := false
+			for _, depFD := range depFiles {
+				name := depFD.GetName()
+				if name == "" {
+					continue
+				}
+				if name == dep {
+					resolvedDepName = true
+				}
+				if aliasSourceName != "" && name == aliasSourceName {
+					// Keep only the aliased name to avoid duplicate symbol conflicts.
+					continue
+				}
+				if _, exists := filesByName[name]; exists {
+					continue
+				}
+				filesByName[name] = depFD
+				queue = append(queue, name)
+			}
+
+			// Some reflection servers return the right descriptor content under a
+			// different filename (e.g. prefixed with "protos/" or "api/"). Alias it
+			// to the dependency name expected by the importing file.
+			if !resolvedDepName && aliasSourceName != "" {
+				for _, depFD := range depFiles {
+					name := depFD.GetName()
+					if name != aliasSourceName {
+						continue
+					}
+					aliased := proto.Clone(depFD).(*descriptorpb.FileDescriptorProto)
+					aliased.Name = proto.String(dep)
+					filesByName[dep] = aliased
+					queue = append(queue, dep)
+					resolvedDepName = true
+					break
+				}
+			}
+		}
+	}
+
+	files := make([]*descriptorpb.FileDescriptorProto, 0, len(queue))
+	for _, name := range queue {
+		files = append(files, filesByName[name])
+	}
+	return files, nil
+}
+
+// buildServiceFromFiles builds files and returns serviceName's descriptor.
+func buildServiceFromFiles(files []*descriptorpb.FileDescriptorProto, serviceName string) (protoreflect.ServiceDescriptor, error) {
+	fileDescs, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: files})
+	if err != nil {
+		return nil, fmt.Errorf("build file descriptors: %w", err)
+	}
+
+	svcDesc, err := fileDescs.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("find service %s: %w", serviceName, err)
+	}
+
+	sd, ok := svcDesc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", serviceName)
+	}
+
+	return sd, nil
+}
+
+func resolveFileByName(stream ReflectionStream, filename string) ([]*descriptorpb.FileDescriptorProto, error) {
+	if err := stream.Send(&grpc_reflection_v1alpha.ServerReflectionRequest{
+		MessageRequest: &grpc_reflection_v1alpha.ServerReflectionRequest_FileByFilename{
+			FileByFilename: filename,
+		},
+	}); err != nil {
+		return nil, fmt.Errorf("request descriptor %s: %w", filename, err)
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, fmt.Errorf("read descriptor %s: %w", filename, err)
+	}
+
+	fdResp := resp.GetFileDescriptorResponse()
+	if fdResp == nil {
+		return nil, fmt.Errorf("no file descriptor response for %s", filename)
+	}
+
+	files := make([]*descriptorpb.FileDescriptorProto, 0, len(fdResp.FileDescriptorProto))
+	for _, b := range fdResp.FileDescriptorProto {
+		fd := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(b, fd); err != nil {
+			return nil, fmt.Errorf("unmarshal descriptor %s: %w", filename, err)
+		}
+		files = append(files, fd)
+	}
+
+	return files, nil
+}
+
+// inputError is a request that does not fit the method's input type. It
+// fails the call before anything is sent, so the caller may retry the call
+// with other descriptors.
+type inputError struct {
+	err error
+}
+
+func (e *inputError) Error() string { return "parse input JSON: " + e.err.Error() }
+
+func (e *inputError) Unwrap() error { return e.err }
+
+func callMethod(ctx context.Context, conn *grpc.ClientConn, svc protoreflect.ServiceDescriptor, method protoreflect.MethodDescriptor, inputJSON string) (*CallResult, error) {
+	if IsStreaming(method) {
+		return callStream(ctx, conn, svc, method, inputJSON)
+	}
+
+	// Build the full method path: /package.ServiceName/MethodName
+	fullMethod := fmt.Sprintf("/%s/%s", svc.FullName(), method.Name())
+
+	// Create dynamic input message
+	inputDesc := method.Input()
+	inputMsg := dynamicpb.NewMessage(inputDesc)
+
+	if err := ParseInput([]byte(inputJSON), inputMsg); err != nil {
+		return nil, &inputError{err: err}
+	}
+
+	// Create dynamic output message
+	outputDesc := method.Output()
+	outputMsg := dynamicpb.NewMessage(outputDesc)
+
+	// Call the method
+	ctx, cancel := MethodContext(ctx, svc.FullName(), method.Name())
+	defer cancel()
+	if err := conn.Invoke(ctx, fullMethod, inputMsg, outputMsg, CallOptions()...); err != nil {
+		return nil, fmt.Errorf("call %s: %w", fullMethod, ExplainCodecError(err))
+	}
+
+	wire, err := proto.Marshal(outputMsg)
+	if err != nil {
+		return nil, fmt.Errorf("marshal output: %w", err)
+	}
+
+	// Marshal output to JSON
+	outputBytes, err := MarshalOutput(outputMsg)
+	if err != nil {
+		return nil, fmt.Errorf("marshal output: %w", err)
+	}
+
+	// Pretty-print the JSON
+	var pretty json.RawMessage
+	if err := json.Unmarshal(outputBytes, &pretty); err != nil {
+		return &CallResult{
+			Service: string(svc.FullName()),
+			Method:  string(method.Name()),
+			Output:  string(outputBytes),
+			Wire:    wire,
+		}, nil
+	}
+
+	prettyBytes, _ := json.MarshalIndent(pretty, "", "  ")
+
+	return &CallResult{
+		Service: string(svc.FullName()),
+		Method:  string(method.Name()),
+		Output:  string(prettyBytes),
+		Wire:    wire,
+	}, nil
+}
+
+// DialStdioCommand starts cmd, which must serve gRPC on its stdin/stdout,
+// and returns a client connection over the pipe. The caller owns cmd once
+// the connection is returned and is responsible for stopping and reaping it;
+// on error the process has already been killed. Callers build cmd themselves
+// to control its environment, e.g. the working directory.
+func DialStdioCommand(ctx context.Context, cmd *exec.Cmd) (*grpc.ClientConn, error) {
+	conn, pipe, err := dialStdioPipe(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	// Bound transport reads and writes by the call timeout so a holon that
+	// stops responding cannot hold the pipe open past it.
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := pipe.SetDeadline(deadline); err != nil {
+			_ = conn.Close()
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+			return nil, fmt.Errorf("set stdio deadline: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// dialStdioPipe is DialStdioCommand without the deadline on the pipe: ctx
+// only bounds the startup and the handshake.
+func dialStdioPipe(ctx context.Context, cmd *exec.Cmd) (*grpc.ClientConn, *pipeConn, error) {
+	stdinPipe, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("create stdin pipe: %w", err)
+	}
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("create stdout pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("start %s: %w", cmd.Path, err)
+	}
+	started := false
+	defer func() {
+		if !started {
+			cmd.Process.Kill() //nolint:errcheck
+			cmd.Wait()         //nolint:errcheck
+		}
+	}()
+
+	// Wait for the server to write its HTTP/2 SETTINGS frame.
+	// Reading the first byte proves the gRPC server is alive and
+	// the pipe is functional. We prepend it back via prefixedReader.
+	firstByte := make([]byte, 1)
+	readCh := make(chan error, 1)
+	go func() {
+		_, err := io.ReadFull(stdoutPipe, firstByte)
+		readCh <- err
+	}()
+	select {
+	case err := <-readCh:
+		if err != nil {
+			return nil, nil, fmt.Errorf("server did not start: %w", err)
+		}
+	case <-ctx.Done():
+		return nil, nil, fmt.Errorf("server startup timeout")
+	}
+
+	// Create a net.Conn backed by the process's stdin/stdout.
+	// Prepend the first byte we already consumed.
+	pConn := &pipeConn{
+		reader: &prefixedReader{prefix: bytes.NewReader(firstByte), r: stdoutPipe},
+		writer: stdinPipe,
+	}
+	// The pipe is a single connection — the dialer must return it exactly
+	// once. Subsequent calls return an error (gRPC may try to reconnect).
+	dialer := singleConnDialer("stdio pipe", pConn)
+
+	// DialContext+WithBlock forces an immediate HTTP/2 handshake over
+	// the pipe, which is required for single-connection transports.
+	//nolint:staticcheck // DialContext is deprecated but needed for pipes.
+	conn, err := grpc.DialContext(ctx,
+		"passthrough:///stdio",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(dialer),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create grpc client over stdio: %w", err)
+	}
+	started = true
+	return conn, pConn, nil
+}
+
+// pipeConn wraps an io.ReadCloser + io.WriteCloser as a net.Conn.
+type pipeConn struct {
+	reader interface{ Read([]byte) (int, error) }
+	writer interface {
+		Write([]byte) (int, error)
+		Close() error
+	}
+}
+
+func (c *pipeConn) Read(p []byte) (int, error) { return c.reader.Read(p) }
+func (c *pipeConn) Close() error               { return c.writer.Close() }
+func (c *pipeConn) LocalAddr() net.Addr        { return pipeAddr{} }
+func (c *pipeConn) RemoteAddr() net.Addr       { return pipeAddr{} }
+
+// singleConnDialer returns a gRPC context dialer that hands out conn exactly
+// once. Pipes and upgraded WebSockets cannot be reopened, and the serving side
+// of stdio:// accepts a single connection for the life of the process, so a
+// reconnect attempt fails fast instead of waiting on a listener that will never
+// accept again.
+func singleConnDialer(name string, conn net.Conn) func(context.Context, string) (net.Conn, error) {
+	var mu sync.Mutex
+	dialed := false
+	return func(context.Context, string) (net.Conn, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if dialed {
+			return nil, fmt.Errorf("%s already consumed", name)
+		}
+		dialed = true
+		return conn, nil
+	}
+}
+
+// Write loops until p is fully written. A pipe writer may accept less than
+// the whole buffer, and a short write would truncate an HTTP/2 frame.
+func (c *pipeConn) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		n, err := c.writer.Write(p[written:])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		if n == 0 {
+			return written, io.ErrShortWrite
+		}
+	}
+	return written, nil
+}
+
+// SetDeadline sets both the read and write deadlines.
+func (c *pipeConn) SetDeadline(t time.Time) error {
+	if err := c.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.SetWriteDeadline(t)
+}
+
+// SetReadDeadline forwards to the underlying pipe. Process pipes from os/exec
+// are *os.File values that support deadlines; other readers report
+// os.ErrNoDeadline rather than silently ignoring the deadline.
+func (c *pipeConn) SetReadDeadline(t time.Time) error {
+	if d, ok := c.reader.(readDeadliner); ok {
+		return d.SetReadDeadline(t)
+	}
+	return os.ErrNoDeadline
+}
+
+// SetWriteDeadline forwards to the underlying pipe, like SetReadDeadline.
+func (c *pipeConn) SetWriteDeadline(t time.Time) error {
+	if d, ok := c.writer.(writeDeadliner); ok {
+		return d.SetWriteDeadline(t)
+	}
+	return os.ErrNoDeadline
+}
+
+type readDeadliner interface{ SetReadDeadline(time.Time) error }
+
+type writeDeadliner interface{ SetWriteDeadline(time.Time) error }
+
+// prefixedReader replays prefix before reading from r. Unlike io.MultiReader
+// it keeps r's deadline support visible to pipeConn.
+type prefixedReader struct {
+	prefix *bytes.Reader
+	r      io.Reader
+}
+
+func (p *prefixedReader) Read(b []byte) (int, error) {
+	if p.prefix.Len() > 0 {
+		return p.prefix.Read(b)
+	}
+	return p.r.Read(b)
+}
+
+func (p *prefixedReader) SetReadDeadline(t time.Time) error {
+	if d, ok := p.r.(readDeadliner); ok {
+		return d.SetReadDeadline(t)
+	}
+	return os.ErrNoDeadline
+}
+
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "pipe" }
+func (pipeAddr) String() string  { return "stdio://" }
+
+// DialWebSocketConn opens a gRPC connection over one WebSocket to wsURI.
+// The WebSocket lives as long as ctx; closing the connection closes it.
+func DialWebSocketConn(ctx context.Context, wsURI string) (*grpc.ClientConn, error) {
+	// Establish WebSocket connection
+	c, _, err := websocket.Dial(ctx, wsURI, &websocket.DialOptions{
+		Subprotocols: []string{"grpc"},
+		Host:         currentAuthority(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("websocket dial %s: %w", wsURI, err)
+	}
+
+	// Wrap as net.Conn
+	wsConn := websocket.NetConn(ctx, c, websocket.MessageBinary)
+
+	dialer := singleConnDialer("ws connection", wsConn)
+
+	//nolint:staticcheck // DialContext needed for single-connection transports.
+	conn, err := grpc.DialContext(ctx, "passthrough:///ws", DialOptions(
+		grpc.WithContextDialer(dialer),
+		grpc.WithBlock(),
+	)...)
+	if err != nil {
+		wsConn.Close()
+		return nil, fmt.Errorf("grpc handshake over ws: %w", err)
+	}
+	return conn, nil
+}
+
+// DialWebSocket connects to a holon's gRPC server via WebSocket and calls
+// a method, through compiled-in descriptors or reflection like Dial. URI
+// should be "ws://host:port/path" or "wss://...".
+func DialWebSocket(wsURI, methodName, inputJSON string) (*CallResult, error) {
+	ctx, cancel := CallContext(context.Background(), Timeout())
+	defer cancel()
+
+	conn, err := DialWebSocketConn(ctx, wsURI)
+	if err != nil {
+		return nil, err
+	}
+	client := NewConnClient(wsURI, conn)
+	defer client.Close()
+	return client.Call(ctx, methodName, inputJSON)
+}
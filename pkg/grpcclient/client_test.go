@@ -0,0 +1,240 @@
+package grpcclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestSingleConnDialerReturnsConnOnce(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	dial := singleConnDialer("stdio pipe", client)
+
+	conn, err := dial(context.Background(), "passthrough:///stdio")
+	if err != nil {
+		t.Fatalf("first dial: %v", err)
+	}
+	if conn != client {
+		t.Fatal("first dial did not return the wrapped connection")
+	}
+
+	if _, err := dial(context.Background(), "passthrough:///stdio"); err == nil || !strings.Contains(err.Error(), "stdio pipe already consumed") {
+		t.Fatalf("second dial error = %v, want stdio pipe already consumed", err)
+	}
+}
+
+// shortWriter accepts at most limit bytes per Write, like a pipe whose buffer
+// is full.
+type shortWriter struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		p = p[:w.limit]
+	}
+	return w.buf.Write(p)
+}
+
+func (w *shortWriter) Close() error { return nil }
+
+func TestPipeConnWriteCompletesLargeFrames(t *testing.T) {
+	payload := make([]byte, 4<<20)
+	if _, err := rand.Read(payload); err != nil {
+		t.Fatal(err)
+	}
+
+	writer := &shortWriter{limit: 4096}
+	conn := &pipeConn{reader: bytes.NewReader(nil), writer: writer}
+
+	n, err := conn.Write(payload)
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if n != len(payload) {
+		t.Fatalf("wrote %d bytes, want %d", n, len(payload))
+	}
+	if !bytes.Equal(writer.buf.Bytes(), payload) {
+		t.Fatal("payload corrupted across short writes")
+	}
+}
+
+func TestPipeConnWriteReportsStalledWriter(t *testing.T) {
+	conn := &pipeConn{reader: bytes.NewReader(nil), writer: &shortWriter{limit: 0}}
+
+	if _, err := conn.Write([]byte("frame")); !errors.Is(err, io.ErrShortWrite) {
+		t.Fatalf("Write error = %v, want io.ErrShortWrite", err)
+	}
+}
+
+func TestPipeConnReadDeadlineInterruptsSilentHolon(t *testing.T) {
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdoutR.Close()
+	defer stdoutW.Close()
+	stdinR, stdinW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdinR.Close()
+	defer stdinW.Close()
+
+	// The holon wrote one byte and then went silent.
+	conn := &pipeConn{
+		reader: &prefixedReader{prefix: bytes.NewReader([]byte{'x'}), r: stdoutR},
+		writer: stdinW,
+	}
+	if err := conn.SetDeadline(time.Now().Add(50 * time.Millisecond)); err != nil {
+		t.Fatalf("SetDeadline: %v", err)
+	}
+
+	buf := make([]byte, 16)
+	if n, err := conn.Read(buf); err != nil || n != 1 || buf[0] != 'x' {
+		t.Fatalf("first Read = %d, %v; want the replayed prefix byte", n, err)
+	}
+
+	start := time.Now()
+	_, err = conn.Read(buf)
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("Read error = %v, want os.ErrDeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("Read returned after %s, want it to stop at the deadline", elapsed)
+	}
+}
+
+func TestPipeConnDeadlineUnsupportedIsReported(t *testing.T) {
+	conn := &pipeConn{reader: bytes.NewReader(nil), writer: &shortWriter{limit: 1}}
+
+	if err := conn.SetReadDeadline(time.Now()); !errors.Is(err, os.ErrNoDeadline) {
+		t.Fatalf("SetReadDeadline error = %v, want os.ErrNoDeadline", err)
+	}
+	if err := conn.SetWriteDeadline(time.Now()); !errors.Is(err, os.ErrNoDeadline) {
+		t.Fatalf("SetWriteDeadline error = %v, want os.ErrNoDeadline", err)
+	}
+}
+
+func TestCallContextCarriesRequestID(t *testing.T) {
+	restore := SetRequestID("req-123")
+	t.Cleanup(restore)
+
+	ctx, cancel := CallContext(context.Background(), time.Second)
+	defer cancel()
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if got := md.Get(RequestIDHeader); len(got) != 1 || got[0] != "req-123" {
+		t.Fatalf("%s = %v, want [req-123]", RequestIDHeader, got)
+	}
+
+	ctx, cancel = CallContext(ContextWithRequestID(context.Background(), "req-456"), time.Second)
+	defer cancel()
+	md, _ = metadata.FromOutgoingContext(ctx)
+	if got := md.Get(RequestIDHeader); len(got) != 1 || got[0] != "req-456" {
+		t.Fatalf("%s = %v, want the context's [req-456] over the pinned ID", RequestIDHeader, got)
+	}
+
+	restore()
+	first, cancelFirst := CallContext(context.Background(), time.Second)
+	defer cancelFirst()
+	second, cancelSecond := CallContext(context.Background(), time.Second)
+	defer cancelSecond()
+	a, _ := metadata.FromOutgoingContext(first)
+	b, _ := metadata.FromOutgoingContext(second)
+	if a.Get(RequestIDHeader)[0] == b.Get(RequestIDHeader)[0] {
+		t.Fatal("expected a fresh request ID per call when none is pinned")
+	}
+}
+
+func TestSetAuthorityOverridesDialTarget(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(chan string, 4)
+	s := grpc.NewServer(grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		seen <- strings.Join(md.Get(":authority"), ",")
+		return status.Error(codes.Unimplemented, "recorded")
+	}))
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	// With automatic reflection, one ListMethods tries v1 then v1alpha, so
+	// every recorded call is checked.
+	expectAuthority := func(want string) {
+		t.Helper()
+		got := []string{<-seen}
+		for len(seen) > 0 {
+			got = append(got, <-seen)
+		}
+		for _, authority := range got {
+			if authority != want {
+				t.Fatalf(":authority = %q, want %q", got, want)
+			}
+		}
+	}
+
+	restore := SetAuthority("holons.example.internal")
+	t.Cleanup(restore)
+	_, _ = ListMethods(lis.Addr().String())
+	expectAuthority("holons.example.internal")
+
+	restore()
+	_, _ = ListMethods(lis.Addr().String())
+	expectAuthority(lis.Addr().String())
+}
+
+func TestSetKeepalivePassesItsParameters(t *testing.T) {
+	var passed []keepalive.ClientParameters
+	original := keepaliveDialOption
+	keepaliveDialOption = func(params keepalive.ClientParameters) grpc.DialOption {
+		passed = append(passed, params)
+		return original(params)
+	}
+	t.Cleanup(func() { keepaliveDialOption = original })
+
+	restore := SetKeepalive(30*time.Second, 10*time.Second)
+	t.Cleanup(restore)
+	DialOptions()
+	want := keepalive.ClientParameters{Time: 30 * time.Second, Timeout: 10 * time.Second}
+	if len(passed) != 1 || passed[0] != want {
+		t.Fatalf("keepalive parameters = %+v, want [%+v]", passed, want)
+	}
+
+	restore()
+	DialOptions()
+	if len(passed) != 1 {
+		t.Fatalf("keepalive parameters = %+v after restore, want no more", passed)
+	}
+}
+
+func TestNewClientCallsServerByFullMethodName(t *testing.T) {
+	address := startReflectionServer(t, ReflectV1Alpha)
+	client := NewClient(address)
+
+	result, err := client.Call(context.Background(), "grpc.health.v1.Health/Check", "")
+	if err != nil {
+		t.Fatalf("Call returned error: %v", err)
+	}
+	if !strings.Contains(result.Output, "SERVING") {
+		t.Fatalf("output = %q, want a SERVING status", result.Output)
+	}
+}
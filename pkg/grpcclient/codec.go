@@ -0,0 +1,123 @@
+package grpcclient
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/encoding/proto"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	codecMu        sync.Mutex
+	contentSubtype string
+	compressor     string
+)
+
+// SetContentSubtype makes method calls opened afterwards announce name as
+// their gRPC content-subtype (application/grpc+name), for servers that
+// register their own codec. Reflection keeps the default. op only builds
+// protobuf messages, so a name no codec is registered for is sent with the
+// protobuf wire format under that label. An empty name restores the
+// default, proto. restore puts back the name set before.
+func SetContentSubtype(name string) (restore func(), err error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if err := validateContentSubtype(name); err != nil {
+		return func() {}, err
+	}
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	previous := contentSubtype
+	contentSubtype = name
+	return func() {
+		codecMu.Lock()
+		defer codecMu.Unlock()
+		contentSubtype = previous
+	}, nil
+}
+
+// validateContentSubtype accepts the token characters gRPC allows in a
+// content-subtype.
+func validateContentSubtype(name string) error {
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || strings.ContainsRune(".-_+", r)) {
+			return fmt.Errorf("invalid codec name %q: use lowercase letters, digits, '.', '-', '_' or '+'", name)
+		}
+	}
+	return nil
+}
+
+// SetCompressor makes method calls opened afterwards compress their
+// messages with name, which must be gzip; the server answers in kind.
+// Reflection stays uncompressed. An empty name restores the default, no
+// compression. restore puts back the compressor set before.
+func SetCompressor(name string) (restore func(), err error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name != "" && name != gzip.Name {
+		return func() {}, fmt.Errorf("unsupported compressor %q: use %s", name, gzip.Name)
+	}
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	previous := compressor
+	compressor = name
+	return func() {
+		codecMu.Lock()
+		defer codecMu.Unlock()
+		compressor = previous
+	}, nil
+}
+
+// CallOptions returns the per-call options method invocations share: the
+// content-subtype set with SetContentSubtype and the compressor set with
+// SetCompressor, if any.
+func CallOptions() []grpc.CallOption {
+	codecMu.Lock()
+	name, compress := contentSubtype, compressor
+	codecMu.Unlock()
+
+	var opts []grpc.CallOption
+	if compress != "" {
+		opts = append(opts, grpc.UseCompressor(compress))
+	}
+	if name == "" || name == proto.Name {
+		return opts
+	}
+	if encoding.GetCodecV2(name) != nil {
+		return append(opts, grpc.CallContentSubtype(name))
+	}
+	return append(opts, grpc.ForceCodecV2(protoAlias{CodecV2: encoding.GetCodecV2(proto.Name), name: name}))
+}
+
+// protoAlias is the protobuf codec under another content-subtype.
+type protoAlias struct {
+	encoding.CodecV2
+	name string
+}
+
+func (c protoAlias) Name() string { return c.name }
+
+// ExplainCodecError names the content-subtype when the server turned a call
+// down in a way that points at it: an HTTP 415, or an Internal or
+// Unimplemented status mentioning the codec.
+func ExplainCodecError(err error) error {
+	codecMu.Lock()
+	name := contentSubtype
+	codecMu.Unlock()
+	if err == nil || name == "" || name == proto.Name {
+		return err
+	}
+	st, _ := status.FromError(err)
+	msg := strings.ToLower(st.Message())
+	rejected := strings.Contains(msg, "415") ||
+		((st.Code() == codes.Internal || st.Code() == codes.Unimplemented) &&
+			(strings.Contains(msg, "codec") || strings.Contains(msg, "content-type") || strings.Contains(msg, "content-subtype") || strings.Contains(msg, name)))
+	if !rejected {
+		return err
+	}
+	return fmt.Errorf("server rejected content-subtype %q (--codec): %w", name, err)
+}
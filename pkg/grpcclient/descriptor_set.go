@@ -0,0 +1,109 @@
+package grpcclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+var (
+	descriptorSetMu       sync.Mutex
+	descriptorSetServices []protoreflect.ServiceDescriptor
+)
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// LoadDescriptorSets reads FileDescriptorSet files, as written by
+// `protoc --descriptor_set_out --include_imports`, and returns the services
+// they define. Gzip-compressed files (.pb.gz) are recognised by their magic
+// bytes. The sets are merged: a file present in several sets is taken from
+// the first, so sets may share imports.
+func LoadDescriptorSets(paths ...string) ([]protoreflect.ServiceDescriptor, error) {
+	merged := &descriptorpb.FileDescriptorSet{}
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		set, err := readDescriptorSet(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range set.GetFile() {
+			if seen[file.GetName()] {
+				continue
+			}
+			seen[file.GetName()] = true
+			merged.File = append(merged.File, file)
+		}
+	}
+
+	files, err := protodesc.NewFiles(merged)
+	if err != nil {
+		return nil, fmt.Errorf("build descriptors: %w", err)
+	}
+	// Walk the files in set order so lookups prefer the earlier sets.
+	var services []protoreflect.ServiceDescriptor
+	for _, fdp := range merged.GetFile() {
+		file, err := files.FindFileByPath(fdp.GetName())
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < file.Services().Len(); i++ {
+			services = append(services, file.Services().Get(i))
+		}
+	}
+	return services, nil
+}
+
+func readDescriptorSet(path string) (*descriptorpb.FileDescriptorSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, gzipMagic) {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		data, err = io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("%s: decompress: %w", path, err)
+		}
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(data, set); err != nil {
+		return nil, fmt.Errorf("%s: not a FileDescriptorSet: %w", path, err)
+	}
+	return set, nil
+}
+
+// SetDescriptorServices makes calls opened afterwards resolve methods of
+// services from the given descriptors, as they do for compiled-in services,
+// before falling back to reflection. nil clears them. restore puts back the
+// services set before.
+func SetDescriptorServices(services []protoreflect.ServiceDescriptor) (restore func()) {
+	descriptorSetMu.Lock()
+	defer descriptorSetMu.Unlock()
+	previous := descriptorSetServices
+	descriptorSetServices = services
+	return func() {
+		descriptorSetMu.Lock()
+		defer descriptorSetMu.Unlock()
+		descriptorSetServices = previous
+	}
+}
+
+// knownServices returns the services loaded from descriptor sets followed by
+// the compiled-in ones.
+func knownServices() []protoreflect.ServiceDescriptor {
+	descriptorSetMu.Lock()
+	defer descriptorSetMu.Unlock()
+	return append(append([]protoreflect.ServiceDescriptor{}, descriptorSetServices...), staticServices...)
+}
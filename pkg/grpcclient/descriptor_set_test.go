@@ -0,0 +1,104 @@
+package grpcclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func writeDescriptorSet(t *testing.T, path string, compress bool, files ...protoreflect.FileDescriptor) {
+	t.Helper()
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, file := range files {
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(file))
+	}
+	data, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		data = buf.Bytes()
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDescriptorSetsReadsGzipAndMerges(t *testing.T) {
+	dir := t.TempDir()
+	gzipped := filepath.Join(dir, "health.pb.gz")
+	plain := filepath.Join(dir, "reflection.pb")
+	writeDescriptorSet(t, gzipped, true, healthpb.File_grpc_health_v1_health_proto)
+	// The second set repeats the health file, as sets built with
+	// --include_imports do for shared dependencies.
+	writeDescriptorSet(t, plain, false, healthpb.File_grpc_health_v1_health_proto, reflectionv1.File_grpc_reflection_v1_reflection_proto)
+
+	services, err := LoadDescriptorSets(gzipped, plain)
+	if err != nil {
+		t.Fatalf("LoadDescriptorSets returned error: %v", err)
+	}
+	var names []string
+	for _, svc := range services {
+		names = append(names, string(svc.FullName()))
+	}
+	if got := strings.Join(names, ","); got != "grpc.health.v1.Health,grpc.reflection.v1.ServerReflection" {
+		t.Fatalf("services = %s", got)
+	}
+
+	t.Cleanup(SetDescriptorServices(services))
+	for _, method := range []string{"grpc.health.v1.Health/Check", "ServerReflectionInfo"} {
+		if _, _, ok := LookupStaticMethod(method); !ok {
+			t.Fatalf("LookupStaticMethod(%q) should find the loaded service", method)
+		}
+	}
+
+	// A server without reflection answers through the loaded descriptors.
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	result, err := Dial(lis.Addr().String(), "Check", "{}")
+	if err != nil {
+		t.Fatalf("Dial returned error: %v", err)
+	}
+	if !strings.Contains(result.Output, "SERVING") {
+		t.Fatalf("output = %q, want a SERVING health status", result.Output)
+	}
+}
+
+func TestLoadDescriptorSetsRejectsGarbage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.pb.gz")
+	if err := os.WriteFile(path, []byte{0x1f, 0x8b, 0x00}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDescriptorSets(path); err == nil || !strings.Contains(err.Error(), "broken.pb.gz") {
+		t.Fatalf("error = %v, want one naming the file", err)
+	}
+}
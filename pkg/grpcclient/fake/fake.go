@@ -0,0 +1,106 @@
+// Package fake provides an op gRPC client that answers from in-process
+// handlers instead of a server, so tooling built on op can be unit tested
+// without a running holon.
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/organic-programming/grace-op/internal/didyoumean"
+	"github.com/organic-programming/grace-op/pkg/grpcclient"
+)
+
+// Handler answers one method of a fake client: it gets the request JSON
+// and returns the response JSON. An error, such as a status.Error, is
+// returned to the caller as is.
+type Handler func(inputJSON string) (string, error)
+
+// NewClient returns a grpcclient.Client that routes calls to handlers instead of a
+// server. Keys are "package.Service/Method", or a bare "Method" when the
+// service does not matter. A call by bare name matches either form; a call
+// for a method with no handler fails as it would against a server that
+// lacks it.
+func NewClient(handlers map[string]Handler) grpcclient.Client {
+	copied := make(map[string]Handler, len(handlers))
+	for name, handler := range handlers {
+		copied[name] = handler
+	}
+	return client(copied)
+}
+
+type client map[string]Handler
+
+func (c client) Call(ctx context.Context, method, inputJSON string) (*grpcclient.CallResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if inputJSON == "" {
+		inputJSON = "{}"
+	}
+	if !json.Valid([]byte(inputJSON)) {
+		return nil, fmt.Errorf("parse input JSON: invalid JSON %q", inputJSON)
+	}
+
+	key, handler, ok := c.lookup(method)
+	if !ok {
+		available, _ := c.ListMethods(ctx)
+		msg := didyoumean.WithHint(fmt.Sprintf("method %q not found", method), didyoumean.ClosestMethods(method, available))
+		return nil, fmt.Errorf("%s. Available: %v", msg, available)
+	}
+	output, err := handler(inputJSON)
+	if err != nil {
+		return nil, err
+	}
+	if output == "" {
+		output = "{}"
+	}
+	if !json.Valid([]byte(output)) {
+		return nil, fmt.Errorf("fake %s returned invalid JSON %q", key, output)
+	}
+
+	service, name, found := strings.Cut(key, "/")
+	if !found {
+		service, name = "", key
+	}
+	return &grpcclient.CallResult{Service: service, Method: name, Output: output}, nil
+}
+
+func (c client) Close() error { return nil }
+
+func (c client) lookup(method string) (string, Handler, bool) {
+	if handler, ok := c[method]; ok {
+		return method, handler, true
+	}
+	if strings.Contains(method, "/") {
+		return "", nil, false
+	}
+	var matches []string
+	for key := range c {
+		if _, name, ok := strings.Cut(key, "/"); ok && name == method {
+			matches = append(matches, key)
+		}
+	}
+	if len(matches) == 0 {
+		return "", nil, false
+	}
+	// Two services with the method would be ambiguous against a server
+	// too; pick one deterministically.
+	sort.Strings(matches)
+	return matches[0], c[matches[0]], true
+}
+
+func (c client) ListMethods(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	methods := make([]string, 0, len(c))
+	for key := range c {
+		methods = append(methods, key)
+	}
+	sort.Strings(methods)
+	return methods, nil
+}
@@ -0,0 +1,61 @@
+package fake
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClientRoutesCallsToHandlers(t *testing.T) {
+	var got string
+	client := NewClient(map[string]Handler{
+		"op.v1.OPService/Discover": func(in string) (string, error) {
+			got = in
+			return `{"entries":[{"slug":"alpha"}]}`, nil
+		},
+		"Ping": func(string) (string, error) { return "", nil },
+		"Fail": func(string) (string, error) {
+			return "", status.Error(codes.PermissionDenied, "nope")
+		},
+	})
+	ctx := context.Background()
+
+	result, err := client.Call(ctx, "Discover", `{"root_dir":"."}`)
+	if err != nil {
+		t.Fatalf("Call(Discover) returned error: %v", err)
+	}
+	if got != `{"root_dir":"."}` || result.Service != "op.v1.OPService" || result.Method != "Discover" || !strings.Contains(result.Output, "alpha") {
+		t.Fatalf("Discover: handler got %q, result %+v", got, result)
+	}
+	if _, err := client.Call(ctx, "op.v1.OPService/Discover", ""); err != nil || got != "{}" {
+		t.Fatalf("full name with empty input: got %q, err %v", got, err)
+	}
+
+	if result, err := client.Call(ctx, "Ping", ""); err != nil || result.Output != "{}" {
+		t.Fatalf("Ping = %+v, %v; want an empty message", result, err)
+	}
+	if _, err := client.Call(ctx, "Fail", ""); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("Fail error = %v, want the handler's PermissionDenied", err)
+	}
+	if _, err := client.Call(ctx, "Discovr", ""); err == nil || !strings.Contains(err.Error(), "Discover") {
+		t.Fatalf("unknown method error = %v, want a hint naming Discover", err)
+	}
+	if _, err := client.Call(ctx, "Ping", "{not json"); err == nil {
+		t.Fatal("invalid input JSON should fail before reaching the handler")
+	}
+
+	methods, err := client.ListMethods(ctx)
+	if err != nil || !slices.Equal(methods, []string{"Fail", "Ping", "op.v1.OPService/Discover"}) {
+		t.Fatalf("ListMethods = %v, %v", methods, err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := client.Call(cancelled, "Ping", ""); err == nil {
+		t.Fatal("a cancelled context should fail the call")
+	}
+}
@@ -0,0 +1,70 @@
+package grpcclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// http1ProbeTimeout bounds the extra connection ExplainHTTP1Error makes.
+const http1ProbeTimeout = time.Second
+
+// http2Preface opens every HTTP/2 connection. An HTTP/1.1 server reads it
+// as a malformed request and answers with an HTTP/1.1 error status.
+const http2Preface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+
+// ExplainHTTP1Error replaces the connection failure gRPC reports when
+// address is served by HTTP/1.1 only, typically a REST gateway, with an
+// error saying so. It is only called on failure: it connects once more and
+// peeks at the first bytes the server answers, over TLS when tlsConf is set.
+// Other errors, including nil, are returned unchanged.
+func ExplainHTTP1Error(err error, address string, tlsConf *tls.Config) error {
+	if err == nil || status.Code(err) != codes.Unavailable {
+		return err
+	}
+	if _, _, splitErr := net.SplitHostPort(address); splitErr != nil || strings.HasPrefix(address, "unix:") {
+		return err
+	}
+	if !speaksHTTP1(address, tlsConf) {
+		return err
+	}
+	return fmt.Errorf("%s spoke HTTP/1.1, not gRPC; it may be a REST gateway, call it over HTTP instead", address)
+}
+
+// speaksHTTP1 reports whether the server at address answers an HTTP/2
+// client preface with an HTTP/1.x status line or, over TLS, negotiates
+// anything but h2.
+func speaksHTTP1(address string, tlsConf *tls.Config) bool {
+	dialer := &net.Dialer{Timeout: http1ProbeTimeout}
+	if tlsConf != nil {
+		conf := tlsConf.Clone()
+		conf.NextProtos = []string{"h2", "http/1.1"}
+		conn, err := tls.DialWithDialer(dialer, "tcp", address, conf)
+		if err != nil {
+			return false
+		}
+		defer conn.Close()
+		return conn.ConnectionState().NegotiatedProtocol != "h2"
+	}
+
+	conn, err := dialer.Dial("tcp", address)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(http1ProbeTimeout))
+	if _, err := io.WriteString(conn, http2Preface); err != nil {
+		return false
+	}
+	head := make([]byte, len("HTTP/1."))
+	if _, err := io.ReadFull(conn, head); err != nil {
+		return false
+	}
+	return string(head) == "HTTP/1."
+}
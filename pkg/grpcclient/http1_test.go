@@ -0,0 +1,38 @@
+package grpcclient
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExplainHTTP1ErrorNamesRESTGateways(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	})
+
+	plain := httptest.NewServer(handler)
+	t.Cleanup(plain.Close)
+	_, err := Dial(strings.TrimPrefix(plain.URL, "http://"), "Check", "{}")
+	if err == nil || !strings.Contains(err.Error(), "spoke HTTP/1.1, not gRPC") {
+		t.Fatalf("plaintext error = %v, want the HTTP/1.1 explanation", err)
+	}
+
+	secure := httptest.NewUnstartedServer(handler)
+	secure.Config.ErrorLog = log.New(io.Discard, "", 0)
+	secure.StartTLS()
+	t.Cleanup(secure.Close)
+	conf := secure.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	_, err = DialTLS(strings.TrimPrefix(secure.URL, "https://"), "Check", "{}", conf)
+	if err == nil || !strings.Contains(err.Error(), "spoke HTTP/1.1, not gRPC") {
+		t.Fatalf("TLS error = %v, want the HTTP/1.1 explanation", err)
+	}
+
+	address, _ := startWatchServer(t)
+	if _, err := Dial(address, "NoSuchMethod", "{}"); err == nil || strings.Contains(err.Error(), "HTTP/1.1") {
+		t.Fatalf("error = %v; a gRPC server must not be reported as HTTP/1.1", err)
+	}
+}
@@ -0,0 +1,34 @@
+package grpcclient
+
+import (
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// keepalives is guarded by dialOptionsMu, with the authority.
+var keepalives keepalive.ClientParameters
+
+// keepaliveDialOption turns keepalive parameters into a dial option; tests
+// replace it to see the parameters DialOptions passes.
+var keepaliveDialOption = grpc.WithKeepaliveParams
+
+// SetKeepalive makes every connection opened afterwards ping the server
+// after interval without activity, and close when a ping goes unanswered for
+// timeout, so idle connections survive NAT and load-balancer idle timeouts.
+// A zero interval turns keepalive pings off, the default. gRPC raises
+// intervals below 10s to 10s, and servers close connections that ping more
+// often than their enforcement policy allows (5 minutes by default).
+// restore puts back the parameters set before.
+func SetKeepalive(interval, timeout time.Duration) (restore func()) {
+	dialOptionsMu.Lock()
+	defer dialOptionsMu.Unlock()
+	previous := keepalives
+	keepalives = keepalive.ClientParameters{Time: interval, Timeout: timeout}
+	return func() {
+		dialOptionsMu.Lock()
+		defer dialOptionsMu.Unlock()
+		keepalives = previous
+	}
+}
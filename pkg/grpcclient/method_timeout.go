@@ -0,0 +1,124 @@
+package grpcclient
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var (
+	methodTimeoutMu sync.Mutex
+	methodTimeouts  map[string]time.Duration
+	callTimeout     time.Duration
+	dialTimeout     time.Duration
+)
+
+// DefaultTimeout bounds connecting to a holon and calling it when op's
+// global --timeout is not given.
+const DefaultTimeout = 10 * time.Second
+
+// SetTimeout sets the deadline for connecting and calling that op's global
+// --timeout gives every operation, in place of each one's default. Zero
+// restores the defaults. restore puts back the deadline set before.
+func SetTimeout(timeout time.Duration) (restore func()) {
+	methodTimeoutMu.Lock()
+	defer methodTimeoutMu.Unlock()
+	previous := dialTimeout
+	dialTimeout = timeout
+	return func() {
+		methodTimeoutMu.Lock()
+		defer methodTimeoutMu.Unlock()
+		dialTimeout = previous
+	}
+}
+
+// Timeout returns the deadline set with SetTimeout, or DefaultTimeout when
+// none is set.
+func Timeout() time.Duration {
+	methodTimeoutMu.Lock()
+	defer methodTimeoutMu.Unlock()
+	if dialTimeout > 0 {
+		return dialTimeout
+	}
+	return DefaultTimeout
+}
+
+// SetMethodTimeouts sets per-method call deadlines. Keys are
+// "package.Service/Method", "Service/Method" or a bare method name; the most
+// specific key matching a call wins. nil clears them. restore puts back the
+// deadlines set before.
+func SetMethodTimeouts(timeouts map[string]time.Duration) (restore func()) {
+	methodTimeoutMu.Lock()
+	defer methodTimeoutMu.Unlock()
+	previous := methodTimeouts
+	methodTimeouts = timeouts
+	return func() {
+		methodTimeoutMu.Lock()
+		defer methodTimeoutMu.Unlock()
+		methodTimeouts = previous
+	}
+}
+
+// SetCallTimeout sets one deadline for every call, overriding the per-method
+// ones, as an explicit --timeout does. Zero restores them. restore puts back
+// the deadline set before.
+func SetCallTimeout(timeout time.Duration) (restore func()) {
+	methodTimeoutMu.Lock()
+	defer methodTimeoutMu.Unlock()
+	previous := callTimeout
+	callTimeout = timeout
+	return func() {
+		methodTimeoutMu.Lock()
+		defer methodTimeoutMu.Unlock()
+		callTimeout = previous
+	}
+}
+
+// MethodTimeout returns the deadline configured for method of svc, if any.
+// An explicit --timeout, per call or global, wins over the per-method ones.
+func MethodTimeout(svc protoreflect.FullName, method protoreflect.Name) (time.Duration, bool) {
+	methodTimeoutMu.Lock()
+	defer methodTimeoutMu.Unlock()
+	if callTimeout > 0 {
+		return callTimeout, true
+	}
+	if dialTimeout > 0 {
+		return dialTimeout, true
+	}
+	short := string(svc)
+	if i := strings.LastIndex(short, "."); i >= 0 {
+		short = short[i+1:]
+	}
+	for _, key := range []string{string(svc) + "/" + string(method), short + "/" + string(method), string(method)} {
+		if timeout, ok := methodTimeouts[key]; ok {
+			return timeout, true
+		}
+	}
+	return 0, false
+}
+
+// MethodContext bounds one call of method by its configured timeout. That
+// deadline replaces the default one CallContext gave ctx, which covers
+// connecting and reflection, so a slow method may outlive it; the caller's
+// own deadline and cancellation still bound the call. Without a configured
+// timeout ctx is used as is.
+func MethodContext(ctx context.Context, svc protoreflect.FullName, method protoreflect.Name) (context.Context, context.CancelFunc) {
+	timeout, ok := MethodTimeout(svc, method)
+	if !ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(callerContext(ctx), timeout)
+}
+
+// streamContext is MethodContext for a streaming call, which without a
+// configured timeout runs until the server ends it or the caller gives up: a
+// stream may rightly last longer than the default deadline of a unary call.
+func streamContext(ctx context.Context, svc protoreflect.FullName, method protoreflect.Name) (context.Context, context.CancelFunc) {
+	if timeout, ok := MethodTimeout(svc, method); ok {
+		return context.WithTimeout(callerContext(ctx), timeout)
+	}
+	return context.WithCancel(callerContext(ctx))
+}
@@ -0,0 +1,91 @@
+package grpcclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestMethodTimeoutPrefersMostSpecificKey(t *testing.T) {
+	t.Cleanup(SetMethodTimeouts(map[string]time.Duration{
+		"Check":                       time.Second,
+		"Health/Check":                2 * time.Second,
+		"grpc.health.v1.Health/Watch": 3 * time.Second,
+	}))
+
+	for method, want := range map[string]time.Duration{"Check": 2 * time.Second, "Watch": 3 * time.Second} {
+		if got, ok := MethodTimeout("grpc.health.v1.Health", protoreflect.Name(method)); !ok || got != want {
+			t.Fatalf("MethodTimeout(%s) = %s, %v; want %s", method, got, ok, want)
+		}
+	}
+	if got, ok := MethodTimeout("other.v1.Other", "Check"); !ok || got != time.Second {
+		t.Fatalf("bare-name timeout = %s, %v; want 1s", got, ok)
+	}
+	if _, ok := MethodTimeout("other.v1.Other", "List"); ok {
+		t.Fatal("an unlisted method should keep the default deadline")
+	}
+
+	t.Cleanup(SetTimeout(4 * time.Second))
+	if got, _ := MethodTimeout("grpc.health.v1.Health", "Check"); got != 4*time.Second {
+		t.Fatalf("global timeout = %s, want it to override the per-method one", got)
+	}
+	t.Cleanup(SetCallTimeout(5 * time.Second))
+	if got, _ := MethodTimeout("grpc.health.v1.Health", "Check"); got != 5*time.Second {
+		t.Fatalf("explicit call timeout = %s, want it to override the per-method one", got)
+	}
+}
+
+func TestMethodContextReplacesDefaultDeadlineButKeepsCallers(t *testing.T) {
+	t.Cleanup(SetMethodTimeouts(map[string]time.Duration{"Slow": time.Minute}))
+
+	parent, cancelParent := CallContext(context.Background(), 10*time.Millisecond)
+	defer cancelParent()
+	ctx, cancel := MethodContext(parent, "demo.v1.Demo", "Slow")
+	defer cancel()
+	<-parent.Done()
+	time.Sleep(10 * time.Millisecond)
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("call context ended with the default deadline: %v", err)
+	}
+
+	caller, cancelCaller := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelCaller()
+	parent, cancelParent = CallContext(caller, 10*time.Millisecond)
+	defer cancelParent()
+	ctx, cancel = MethodContext(parent, "demo.v1.Demo", "Slow")
+	defer cancel()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the caller's own deadline should still bound the call")
+	}
+
+	caller, cancelCaller = context.WithCancel(context.Background())
+	parent, cancelParent = CallContext(caller, time.Second)
+	defer cancelParent()
+	ctx, cancel = MethodContext(parent, "demo.v1.Demo", "Slow")
+	defer cancel()
+	cancelCaller()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("cancelling the caller should cancel the call")
+	}
+}
+
+func TestStreamContextTakesTheGlobalTimeout(t *testing.T) {
+	ctx, cancel := streamContext(context.Background(), "demo.v1.Demo", "Watch")
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatal("a stream without a timeout should have no deadline")
+	}
+	cancel()
+
+	t.Cleanup(SetTimeout(time.Minute))
+	ctx, cancel = streamContext(context.Background(), "demo.v1.Demo", "Watch")
+	defer cancel()
+	if _, ok := ctx.Deadline(); !ok {
+		t.Fatal("--timeout should bound a stream")
+	}
+}
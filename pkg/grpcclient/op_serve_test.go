@@ -0,0 +1,193 @@
+package grpcclient
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/organic-programming/grace-op/internal/server"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// startOPServe runs OP's own server as op serve does, on a Unix socket with
+// reflection and health, and returns its address. extra is added to the
+// gRPC server's options.
+func startOPServe(t *testing.T, extra ...grpc.ServerOption) string {
+	t.Helper()
+
+	// Unix socket paths are length-limited, so keep this one short.
+	dir, err := os.MkdirTemp("", "ops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "op.sock")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.ListenAndServeContext(ctx, "unix://"+socket, server.ServeOptions{Reflect: true, Health: true, ServerOptions: extra})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if conn, err := net.Dial("unix", socket); err == nil {
+			_ = conn.Close()
+			return "unix://" + socket
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("op serve socket never came up")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestOPServeIdentityMethodsThroughReflection(t *testing.T) {
+	t.Chdir(t.TempDir())
+	address := startOPServe(t)
+
+	methods, err := ListMethods(address)
+	if err != nil {
+		t.Fatalf("ListMethods: %v", err)
+	}
+	for _, want := range []string{
+		"op.v1.OPService/Discover",
+		"op.v1.OPService/CreateIdentity",
+		"op.v1.OPService/ListIdentities",
+		"op.v1.OPService/ShowIdentity",
+		"op.v1.OPService/DeleteIdentity",
+	} {
+		if !slices.Contains(methods, want) {
+			t.Fatalf("methods = %v, missing %s", methods, want)
+		}
+	}
+
+	// Leave out the compiled-in descriptors so every call below resolves
+	// its types the way a client without them would.
+	saved := staticServices
+	staticServices = []protoreflect.ServiceDescriptor{}
+	t.Cleanup(func() { staticServices = saved })
+
+	created, err := Dial(address, "CreateIdentity", `{"givenName":"Alpha","familyName":"Reflected","motto":"Found by reflection.","composer":"test","clade":"DETERMINISTIC_PURE","outputDir":"holons/alpha"}`)
+	if err != nil {
+		t.Fatalf("CreateIdentity: %v", err)
+	}
+	if created.Service != "op.v1.OPService" {
+		t.Fatalf("service = %q", created.Service)
+	}
+	var resp struct {
+		Identity struct {
+			UUID string `json:"uuid"`
+		} `json:"identity"`
+	}
+	if err := json.Unmarshal([]byte(created.Output), &resp); err != nil || resp.Identity.UUID == "" {
+		t.Fatalf("CreateIdentity output = %s (%v)", created.Output, err)
+	}
+
+	// field_mask comes from google/protobuf/field_mask.proto, a dependency
+	// reflection has to serve alongside op.proto.
+	shown, err := Dial(address, "ShowIdentity", `{"uuid":"`+resp.Identity.UUID+`","fieldMask":"givenName"}`)
+	if err != nil {
+		t.Fatalf("ShowIdentity: %v", err)
+	}
+	var show struct {
+		Identity map[string]any `json:"identity"`
+	}
+	if err := json.Unmarshal([]byte(shown.Output), &show); err != nil {
+		t.Fatal(err)
+	}
+	if show.Identity["givenName"] != "Alpha" || len(show.Identity) != 1 {
+		t.Fatalf("masked identity = %v, want only givenName", show.Identity)
+	}
+}
+
+// encodingRecorder records the grpc-encoding header of every ShowIdentity
+// request the server receives.
+type encodingRecorder struct {
+	mu        sync.Mutex
+	encodings []string
+}
+
+func (r *encodingRecorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (r *encodingRecorder) HandleRPC(_ context.Context, s stats.RPCStats) {
+	header, ok := s.(*stats.InHeader)
+	if !ok || !strings.HasSuffix(header.FullMethod, "/ShowIdentity") {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.encodings = append(r.encodings, header.Compression)
+}
+
+func (r *encodingRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r *encodingRecorder) HandleConn(context.Context, stats.ConnStats) {}
+
+func (r *encodingRecorder) show() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.encodings)
+}
+
+func TestOPServeShowIdentityRoundTripsCompressed(t *testing.T) {
+	t.Chdir(t.TempDir())
+	encodings := &encodingRecorder{}
+	address := startOPServe(t, grpc.StatsHandler(encodings))
+
+	motto := strings.TrimSpace(strings.Repeat("Compressed over the wire, identical on arrival. ", 8<<10))
+	created, err := Dial(address, "CreateIdentity", `{"givenName":"Large","familyName":"Payload","motto":"`+motto+`","composer":"test","clade":"DETERMINISTIC_PURE","outputDir":"holons/large"}`)
+	if err != nil {
+		t.Fatalf("CreateIdentity: %v", err)
+	}
+	var resp struct {
+		Identity struct {
+			UUID string `json:"uuid"`
+		} `json:"identity"`
+	}
+	if err := json.Unmarshal([]byte(created.Output), &resp); err != nil || resp.Identity.UUID == "" {
+		t.Fatalf("CreateIdentity output = %.200s (%v)", created.Output, err)
+	}
+	input := `{"uuid":"` + resp.Identity.UUID + `"}`
+
+	plain, err := Dial(address, "ShowIdentity", input)
+	if err != nil {
+		t.Fatalf("uncompressed ShowIdentity: %v", err)
+	}
+	restore, err := SetCompressor("gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(restore)
+	compressed, err := Dial(address, "ShowIdentity", input)
+	if err != nil {
+		t.Fatalf("compressed ShowIdentity: %v", err)
+	}
+	if !strings.Contains(plain.Output, motto) {
+		t.Fatalf("ShowIdentity output lacks the large motto (%d bytes)", len(plain.Output))
+	}
+	if compressed.Output != plain.Output {
+		t.Fatalf("compressed output (%d bytes) differs from uncompressed (%d bytes)", len(compressed.Output), len(plain.Output))
+	}
+
+	if got := encodings.show(); !slices.Equal(got, []string{"", "gzip"}) {
+		t.Fatalf("ShowIdentity grpc-encoding = %q, want uncompressed then gzip", got)
+	}
+}
@@ -0,0 +1,73 @@
+package grpcclient
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultMaxInputSize bounds the JSON request op accepts for one call.
+const DefaultMaxInputSize = 4 << 20
+
+var (
+	maxInputSizeMu sync.Mutex
+	maxInputSize   = DefaultMaxInputSize
+)
+
+// SetMaxInputSize sets the largest JSON input ParseInput accepts, in bytes.
+// Zero or less restores DefaultMaxInputSize. restore puts back the limit set
+// before.
+func SetMaxInputSize(n int) (restore func()) {
+	maxInputSizeMu.Lock()
+	defer maxInputSizeMu.Unlock()
+	if n <= 0 {
+		n = DefaultMaxInputSize
+	}
+	previous := maxInputSize
+	maxInputSize = n
+	return func() {
+		maxInputSizeMu.Lock()
+		defer maxInputSizeMu.Unlock()
+		maxInputSize = previous
+	}
+}
+
+// MaxInputSize returns the current input size limit.
+func MaxInputSize() int {
+	maxInputSizeMu.Lock()
+	defer maxInputSizeMu.Unlock()
+	return maxInputSize
+}
+
+// ParseInput decodes JSON input into msg, the request of a call. Empty input
+// leaves msg empty. Input over MaxInputSize is refused before decoding, and
+// a panic while decoding becomes an error, so input from an untrusted caller
+// can only ever fail the call. With SetFileReferences, bytes fields given as
+// "@path" are read from files; the limit applies both before and after they
+// are.
+func ParseInput(input []byte, msg proto.Message) (err error) {
+	limit := MaxInputSize()
+	if len(input) > limit {
+		return fmt.Errorf("input is %d bytes, over the %d-byte limit (raise it with --max-input-size)", len(input), limit)
+	}
+	if len(bytes.TrimSpace(input)) == 0 {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			proto.Reset(msg)
+			err = fmt.Errorf("malformed input: %v", r)
+		}
+	}()
+	input, err = expandFileReferences(input, msg.ProtoReflect().Descriptor())
+	if err != nil {
+		return err
+	}
+	if len(input) > limit {
+		return fmt.Errorf("input is %d bytes with its @file references read, over the %d-byte limit (raise it with --max-input-size)", len(input), limit)
+	}
+	return protojson.Unmarshal(input, msg)
+}
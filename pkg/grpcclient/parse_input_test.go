@@ -0,0 +1,51 @@
+package grpcclient
+
+import (
+	"strings"
+	"testing"
+
+	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestParseInputBoundsSizeAndAcceptsEmptyInput(t *testing.T) {
+	msg := dynamicpb.NewMessage((&opv1.ShowIdentityRequest{}).ProtoReflect().Descriptor())
+	if err := ParseInput([]byte("  \n"), msg); err != nil {
+		t.Fatalf("blank input returned %v, want an empty request", err)
+	}
+	if err := ParseInput([]byte(`{"uuid":"abc","fieldMask":"uuid,givenName"}`), msg); err != nil {
+		t.Fatalf("ParseInput returned %v", err)
+	}
+
+	t.Cleanup(SetMaxInputSize(16))
+	err := ParseInput([]byte(`{"uuid":"0123456789abcdef"}`), msg)
+	if err == nil || !strings.Contains(err.Error(), "over the 16-byte limit") {
+		t.Fatalf("oversized input error = %v", err)
+	}
+}
+
+func FuzzParseInput(f *testing.F) {
+	for _, seed := range []string{
+		``,
+		`{}`,
+		`{"name":"a.proto","messageType":[{"name":"M","field":[{"name":"f","number":1}]}]}`,
+		`{"options":{"uninterpretedOption":[{"name":[{"namePart":"x","isExtension":true}]}]}}`,
+		`{"uuid":"abc","fieldMask":"uuid,givenName"}`,
+		`{"sourceCodeInfo":{"location":[{"path":[1,2,3],"span":[0,0,1]}]}}`,
+		`[`, `{"name":`, `{"@type":"type.googleapis.com/x"}`, "\x00\xff",
+	} {
+		f.Add([]byte(seed))
+	}
+	targets := []*dynamicpb.Message{
+		dynamicpb.NewMessage((&descriptorpb.FileDescriptorProto{}).ProtoReflect().Descriptor()),
+		dynamicpb.NewMessage((&opv1.ShowIdentityRequest{}).ProtoReflect().Descriptor()),
+	}
+	f.Fuzz(func(t *testing.T, input []byte) {
+		for _, msg := range targets {
+			msg.Reset()
+			_ = ParseInput(input, msg)
+		}
+	})
+}
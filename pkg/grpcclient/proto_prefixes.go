@@ -0,0 +1,51 @@
+package grpcclient
+
+import (
+	"os"
+	"strings"
+)
+
+// EnvProtoPrefixes lists, comma-separated, the path prefixes tried when a
+// reflection server does not know an imported .proto file by its bare name.
+// Some servers register their descriptors under the directory they were
+// compiled from, such as "protos/" or "api/".
+const EnvProtoPrefixes = "OP_PROTO_PREFIXES"
+
+// defaultProtoPrefixes is used when OP_PROTO_PREFIXES is unset.
+var defaultProtoPrefixes = []string{"protos/"}
+
+// ProtoPrefixes returns the alias prefixes from OP_PROTO_PREFIXES, or the
+// default "protos/". Each prefix ends with a slash.
+func ProtoPrefixes() []string {
+	raw, ok := os.LookupEnv(EnvProtoPrefixes)
+	if !ok || strings.TrimSpace(raw) == "" {
+		return defaultProtoPrefixes
+	}
+	var prefixes []string
+	for _, prefix := range strings.Split(raw, ",") {
+		prefix = strings.Trim(strings.TrimSpace(prefix), "/")
+		if prefix != "" {
+			prefixes = append(prefixes, prefix+"/")
+		}
+	}
+	return prefixes
+}
+
+// ResolveDependency asks resolve for the file dep and, when that fails, for
+// dep under each of ProtoPrefixes in turn. A prefix dep already starts with
+// is skipped. When every name fails, the bare name's error is returned.
+func ResolveDependency[T any](dep string, resolve func(filename string) (T, error)) (T, error) {
+	files, err := resolve(dep)
+	if err == nil {
+		return files, nil
+	}
+	for _, prefix := range ProtoPrefixes() {
+		if strings.HasPrefix(dep, prefix) {
+			continue
+		}
+		if aliased, aliasErr := resolve(prefix + dep); aliasErr == nil {
+			return aliased, nil
+		}
+	}
+	return files, err
+}
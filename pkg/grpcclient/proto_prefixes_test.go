@@ -0,0 +1,122 @@
+package grpcclient
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// prefixedReflectionStream answers reflection requests from a fixed set of
+// descriptors, by symbol or by exact file name, and records the file names
+// it was asked for.
+type prefixedReflectionStream struct {
+	grpc.ClientStream
+	files     map[string]*descriptorpb.FileDescriptorProto
+	symbols   map[string]string
+	requested []string
+	pending   []*grpc_reflection_v1alpha.ServerReflectionResponse
+}
+
+func (s *prefixedReflectionStream) Send(req *grpc_reflection_v1alpha.ServerReflectionRequest) error {
+	name := req.GetFileByFilename()
+	if symbol := req.GetFileContainingSymbol(); symbol != "" {
+		name = s.symbols[symbol]
+	} else {
+		s.requested = append(s.requested, name)
+	}
+	fd, ok := s.files[name]
+	if !ok {
+		s.pending = append(s.pending, &grpc_reflection_v1alpha.ServerReflectionResponse{
+			MessageResponse: &grpc_reflection_v1alpha.ServerReflectionResponse_ErrorResponse{
+				ErrorResponse: &grpc_reflection_v1alpha.ErrorResponse{ErrorCode: 5, ErrorMessage: "not found: " + name},
+			},
+		})
+		return nil
+	}
+	raw, err := proto.Marshal(fd)
+	if err != nil {
+		return err
+	}
+	s.pending = append(s.pending, &grpc_reflection_v1alpha.ServerReflectionResponse{
+		MessageResponse: &grpc_reflection_v1alpha.ServerReflectionResponse_FileDescriptorResponse{
+			FileDescriptorResponse: &grpc_reflection_v1alpha.FileDescriptorResponse{FileDescriptorProto: [][]byte{raw}},
+		},
+	})
+	return nil
+}
+
+func (s *prefixedReflectionStream) Recv() (*grpc_reflection_v1alpha.ServerReflectionResponse, error) {
+	if len(s.pending) == 0 {
+		return nil, fmt.Errorf("no pending response")
+	}
+	resp := s.pending[0]
+	s.pending = s.pending[1:]
+	return resp, nil
+}
+
+func (s *prefixedReflectionStream) CloseSend() error { return nil }
+
+func newPrefixedReflectionStream(prefix string) *prefixedReflectionStream {
+	common := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String(prefix + "common/msg.proto"),
+		Package: proto.String("common"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Ping"),
+		}},
+	}
+	greeter := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("greeter.proto"),
+		Package:    proto.String("greet"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"common/msg.proto"},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Greeter"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("Echo"),
+				InputType:  proto.String(".common.Ping"),
+				OutputType: proto.String(".common.Ping"),
+			}},
+		}},
+	}
+	return &prefixedReflectionStream{
+		files: map[string]*descriptorpb.FileDescriptorProto{
+			greeter.GetName(): greeter,
+			common.GetName():  common,
+		},
+		symbols: map[string]string{"greet.Greeter": greeter.GetName()},
+	}
+}
+
+func TestResolveServiceTriesConfiguredProtoPrefixes(t *testing.T) {
+	t.Setenv(EnvProtoPrefixes, "proto, api/")
+
+	stream := newPrefixedReflectionStream("api/")
+	desc, err := ResolveService(stream, "greet.Greeter")
+	if err != nil {
+		t.Fatalf("resolveService returned error: %v", err)
+	}
+	if got := desc.Methods().ByName("Echo").Input().FullName(); got != "common.Ping" {
+		t.Fatalf("Echo input = %s, want common.Ping", got)
+	}
+	want := []string{"common/msg.proto", "proto/common/msg.proto", "api/common/msg.proto"}
+	if !slices.Equal(stream.requested, want) {
+		t.Fatalf("requested files = %q, want %q", stream.requested, want)
+	}
+}
+
+func TestResolveServiceDefaultsToProtosPrefix(t *testing.T) {
+	t.Setenv(EnvProtoPrefixes, "")
+
+	if _, err := ResolveService(newPrefixedReflectionStream("protos/"), "greet.Greeter"); err != nil {
+		t.Fatalf("resolveService with the default prefix returned error: %v", err)
+	}
+	if _, err := ResolveService(newPrefixedReflectionStream("api/"), "greet.Greeter"); err == nil {
+		t.Fatal("api/ is not a default prefix, so the dependency should stay unresolved")
+	}
+}
@@ -0,0 +1,149 @@
+package grpcclient
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// ReflectionDump is what a server reports through reflection, before any
+// attempt to assemble the descriptors into a FileDescriptorSet.
+type ReflectionDump struct {
+	Address  string                 `json:"address"`
+	Services []ReflectedServiceDump `json:"services"`
+}
+
+// ReflectedServiceDump lists the files returned for one service, in the order
+// the server returned them.
+type ReflectedServiceDump struct {
+	Name                string          `json:"name"`
+	Files               []ReflectedFile `json:"files,omitempty"`
+	MissingDependencies []string        `json:"missing_dependencies,omitempty"`
+	Error               string          `json:"error,omitempty"`
+}
+
+// ReflectedFile is one FileDescriptorProto as reported by the server.
+// Requested is set when the file was fetched by filename to satisfy a
+// dependency; a Requested that differs from Name means the server serves that
+// dependency under another (aliased) name. Duplicate marks a name the server
+// already returned for this service.
+type ReflectedFile struct {
+	Name         string   `json:"name"`
+	Package      string   `json:"package,omitempty"`
+	Dependencies []string `json:"dependencies,omitempty"`
+	Requested    string   `json:"requested,omitempty"`
+	Duplicate    bool     `json:"duplicate,omitempty"`
+}
+
+// Aliased reports whether the server answered a request for one filename with
+// a file of another name.
+func (f ReflectedFile) Aliased() bool {
+	return f.Requested != "" && f.Requested != f.Name
+}
+
+// DumpReflection lists the services at address and, for each, the raw file
+// descriptors reflection reports along with their dependencies.
+func DumpReflection(address string) (*ReflectionDump, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout())
+	defer cancel()
+
+	conn, err := grpc.NewClient(address, DialOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", address, err)
+	}
+	defer conn.Close()
+
+	stream, err := OpenReflection(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("reflection not available at %s: %w", address, err)
+	}
+
+	services, err := ListServices(stream)
+	if err != nil {
+		return nil, fmt.Errorf("list services: %w", err)
+	}
+	// A second list catches servers that advertise only part of their
+	// services per response.
+	if more, err := ListMoreServices(stream, services); err == nil {
+		services = append(services, more...)
+	}
+
+	dump := &ReflectionDump{Address: address}
+	for _, name := range services {
+		dump.Services = append(dump.Services, dumpService(stream, name))
+	}
+	return dump, nil
+}
+
+func dumpService(stream grpc_reflection_v1alpha.ServerReflection_ServerReflectionInfoClient, serviceName string) ReflectedServiceDump {
+	dump := ReflectedServiceDump{Name: serviceName}
+
+	if err := stream.Send(&grpc_reflection_v1alpha.ServerReflectionRequest{
+		MessageRequest: &grpc_reflection_v1alpha.ServerReflectionRequest_FileContainingSymbol{
+			FileContainingSymbol: serviceName,
+		},
+	}); err != nil {
+		dump.Error = err.Error()
+		return dump
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		dump.Error = err.Error()
+		return dump
+	}
+	if errResp := resp.GetErrorResponse(); errResp != nil {
+		dump.Error = errResp.GetErrorMessage()
+		return dump
+	}
+
+	seen := make(map[string]bool)
+	record := func(raw [][]byte, requested string) {
+		for _, b := range raw {
+			fd := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(b, fd); err != nil {
+				dump.Files = append(dump.Files, ReflectedFile{Name: "<unparseable>", Requested: requested})
+				continue
+			}
+			dump.Files = append(dump.Files, ReflectedFile{
+				Name:         fd.GetName(),
+				Package:      fd.GetPackage(),
+				Dependencies: fd.GetDependency(),
+				Requested:    requested,
+				Duplicate:    seen[fd.GetName()],
+			})
+			seen[fd.GetName()] = true
+		}
+	}
+	record(resp.GetFileDescriptorResponse().GetFileDescriptorProto(), "")
+
+	// Chase dependencies the server did not include, by exact filename, so
+	// aliased or missing files show up as reported rather than repaired.
+	requested := make(map[string]bool)
+	for i := 0; i < len(dump.Files); i++ {
+		for _, dep := range dump.Files[i].Dependencies {
+			if seen[dep] || requested[dep] {
+				continue
+			}
+			requested[dep] = true
+			files, err := resolveFileByName(stream, dep)
+			if err != nil || len(files) == 0 {
+				dump.MissingDependencies = append(dump.MissingDependencies, dep)
+				continue
+			}
+			raw := make([][]byte, 0, len(files))
+			for _, fd := range files {
+				b, err := proto.Marshal(fd)
+				if err != nil {
+					continue
+				}
+				raw = append(raw, b)
+			}
+			record(raw, dep)
+		}
+	}
+	return dump
+}
@@ -0,0 +1,178 @@
+package grpcclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ReflectProtocol selects the gRPC reflection service op talks to.
+type ReflectProtocol string
+
+const (
+	// ReflectAuto uses grpc.reflection.v1 and falls back to v1alpha when the
+	// server does not implement v1.
+	ReflectAuto    ReflectProtocol = "auto"
+	ReflectV1      ReflectProtocol = "v1"
+	ReflectV1Alpha ReflectProtocol = "v1alpha"
+)
+
+var (
+	reflectProtocolMu sync.Mutex
+	reflectProtocol   = ReflectAuto
+)
+
+// ParseReflectProtocol validates a --reflect-proto value.
+func ParseReflectProtocol(value string) (ReflectProtocol, error) {
+	switch p := ReflectProtocol(strings.ToLower(strings.TrimSpace(value))); p {
+	case ReflectAuto, ReflectV1, ReflectV1Alpha:
+		return p, nil
+	default:
+		return "", fmt.Errorf("invalid reflection protocol %q (want v1, v1alpha or auto)", value)
+	}
+}
+
+// SetReflectProtocol makes every reflection stream opened afterwards use p.
+// An empty protocol restores auto. restore puts back the protocol set
+// before.
+func SetReflectProtocol(p ReflectProtocol) (restore func()) {
+	if p == "" {
+		p = ReflectAuto
+	}
+	reflectProtocolMu.Lock()
+	defer reflectProtocolMu.Unlock()
+	previous := reflectProtocol
+	reflectProtocol = p
+	return func() {
+		reflectProtocolMu.Lock()
+		defer reflectProtocolMu.Unlock()
+		reflectProtocol = previous
+	}
+}
+
+func currentReflectProtocol() ReflectProtocol {
+	reflectProtocolMu.Lock()
+	defer reflectProtocolMu.Unlock()
+	return reflectProtocol
+}
+
+// OpenReflection opens a ServerReflectionInfo stream on conn with the
+// protocol set by SetReflectProtocol. The two protocols share one wire
+// format, so the stream speaks v1alpha messages whichever service answers.
+func OpenReflection(ctx context.Context, conn grpc.ClientConnInterface) (ReflectionStream, error) {
+	switch currentReflectProtocol() {
+	case ReflectV1:
+		return openV1Reflection(ctx, conn)
+	case ReflectV1Alpha:
+		return grpc_reflection_v1alpha.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	default:
+		stream, err := openV1Reflection(ctx, conn)
+		if err != nil {
+			return nil, err
+		}
+		return &autoReflectionStream{ReflectionStream: stream, ctx: ctx, conn: conn}, nil
+	}
+}
+
+func openV1Reflection(ctx context.Context, conn grpc.ClientConnInterface) (ReflectionStream, error) {
+	stream, err := grpc_reflection_v1.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &v1ReflectionStream{ClientStream: stream, stream: stream}, nil
+}
+
+// v1ReflectionStream carries v1alpha messages over a v1 stream.
+type v1ReflectionStream struct {
+	grpc.ClientStream
+	stream grpc_reflection_v1.ServerReflection_ServerReflectionInfoClient
+}
+
+func (s *v1ReflectionStream) Send(req *grpc_reflection_v1alpha.ServerReflectionRequest) error {
+	converted := &grpc_reflection_v1.ServerReflectionRequest{}
+	if err := convertReflectionMessage(req, converted); err != nil {
+		return err
+	}
+	return s.stream.Send(converted)
+}
+
+func (s *v1ReflectionStream) Recv() (*grpc_reflection_v1alpha.ServerReflectionResponse, error) {
+	resp, err := s.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	converted := &grpc_reflection_v1alpha.ServerReflectionResponse{}
+	if err := convertReflectionMessage(resp, converted); err != nil {
+		return nil, err
+	}
+	return converted, nil
+}
+
+func convertReflectionMessage(from, to proto.Message) error {
+	raw, err := proto.Marshal(from)
+	if err != nil {
+		return fmt.Errorf("convert reflection message: %w", err)
+	}
+	if err := proto.Unmarshal(raw, to); err != nil {
+		return fmt.Errorf("convert reflection message: %w", err)
+	}
+	return nil
+}
+
+// autoReflectionStream starts on v1. A server without v1 only says so when
+// the first response is read, so the stream remembers the first request and
+// replays it on a v1alpha stream if that read fails with Unimplemented.
+// Only that one request can be replayed, so nothing may be sent before its
+// answer is read: see pipelineReady.
+type autoReflectionStream struct {
+	ReflectionStream
+	ctx     context.Context
+	conn    grpc.ClientConnInterface
+	first   *grpc_reflection_v1alpha.ServerReflectionRequest
+	decided bool
+}
+
+func (s *autoReflectionStream) Send(req *grpc_reflection_v1alpha.ServerReflectionRequest) error {
+	if !s.decided && s.first == nil {
+		s.first = req
+	}
+	return s.ReflectionStream.Send(req)
+}
+
+func (s *autoReflectionStream) Recv() (*grpc_reflection_v1alpha.ServerReflectionResponse, error) {
+	resp, err := s.ReflectionStream.Recv()
+	if s.decided {
+		return resp, err
+	}
+	s.decided = true
+	if status.Code(err) != codes.Unimplemented || s.first == nil {
+		return resp, err
+	}
+	fallback, openErr := grpc_reflection_v1alpha.NewServerReflectionClient(s.conn).ServerReflectionInfo(s.ctx)
+	if openErr != nil {
+		return nil, err
+	}
+	s.ReflectionStream = fallback
+	if err := fallback.Send(s.first); err != nil {
+		return nil, err
+	}
+	return fallback.Recv()
+}
+
+// pipelineReady reports an error when stream cannot yet take several
+// requests in flight: an auto stream that has not read its first answer
+// could only replay the first of them on v1alpha.
+func pipelineReady(stream ReflectionStream) error {
+	if auto, ok := stream.(*autoReflectionStream); ok && !auto.decided {
+		return fmt.Errorf("reflection protocol not settled: read a first answer before pipelining requests")
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package grpcclient
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+)
+
+// startReflectionServer serves grpc.health.v1 with only the given
+// reflection protocol registered.
+func startReflectionServer(t *testing.T, protocol ReflectProtocol) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	switch protocol {
+	case ReflectV1:
+		reflection.RegisterV1(s)
+	case ReflectV1Alpha:
+		grpc_reflection_v1alpha.RegisterServerReflectionServer(s, reflection.NewServer(reflection.ServerOptions{Services: s}))
+	}
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String()
+}
+
+func withReflectProtocol(t *testing.T, p ReflectProtocol) {
+	t.Helper()
+	t.Cleanup(SetReflectProtocol(p))
+}
+
+func TestAutoReflectionFallsBackToV1Alpha(t *testing.T) {
+	for _, serverProtocol := range []ReflectProtocol{ReflectV1, ReflectV1Alpha} {
+		address := startReflectionServer(t, serverProtocol)
+		methods, err := ListMethods(address)
+		if err != nil {
+			t.Fatalf("auto against a %s server: %v", serverProtocol, err)
+		}
+		if !slices.Contains(methods, "grpc.health.v1.Health/Check") {
+			t.Fatalf("auto against a %s server listed %v", serverProtocol, methods)
+		}
+
+		result, err := Dial(address, "Check", "{}")
+		if err != nil {
+			t.Fatalf("Dial against a %s server: %v", serverProtocol, err)
+		}
+		if result.Output == "" {
+			t.Fatalf("Dial against a %s server returned no output", serverProtocol)
+		}
+	}
+}
+
+func TestResolveServiceOverEitherProtocol(t *testing.T) {
+	for _, serverProtocol := range []ReflectProtocol{ReflectV1, ReflectV1Alpha} {
+		conn, err := grpc.NewClient(startReflectionServer(t, serverProtocol), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+
+		stream, err := OpenReflection(context.Background(), conn)
+		if err != nil {
+			t.Fatalf("open reflection on a %s server: %v", serverProtocol, err)
+		}
+		var services []string
+		err = WalkServices(stream, func(name string) bool {
+			desc, err := ResolveService(stream, name)
+			if err != nil {
+				t.Fatalf("resolve %s on a %s server: %v", name, serverProtocol, err)
+			}
+			services = append(services, string(desc.FullName()))
+			return false
+		})
+		if err != nil {
+			t.Fatalf("walk services on a %s server: %v", serverProtocol, err)
+		}
+		if !slices.Equal(services, []string{"grpc.health.v1.Health"}) {
+			t.Fatalf("services on a %s server = %v, want only grpc.health.v1.Health", serverProtocol, services)
+		}
+	}
+}
+
+func TestForcedReflectProtocolIsNotSubstituted(t *testing.T) {
+	v1Only := startReflectionServer(t, ReflectV1)
+	v1AlphaOnly := startReflectionServer(t, ReflectV1Alpha)
+
+	withReflectProtocol(t, ReflectV1)
+	if _, err := ListMethods(v1Only); err != nil {
+		t.Fatalf("v1 against a v1 server: %v", err)
+	}
+	if _, err := ListMethods(v1AlphaOnly); status.Code(err) != codes.Unimplemented {
+		t.Fatalf("v1 against a v1alpha-only server: err = %v, want Unimplemented", err)
+	}
+
+	withReflectProtocol(t, ReflectV1Alpha)
+	if _, err := ListMethods(v1AlphaOnly); err != nil {
+		t.Fatalf("v1alpha against a v1alpha server: %v", err)
+	}
+	if _, err := ListMethods(v1Only); status.Code(err) != codes.Unimplemented {
+		t.Fatalf("v1alpha against a v1-only server: err = %v, want Unimplemented", err)
+	}
+}
+
+func TestParseReflectProtocol(t *testing.T) {
+	for value, want := range map[string]ReflectProtocol{"v1": ReflectV1, " V1Alpha ": ReflectV1Alpha, "auto": ReflectAuto} {
+		if got, err := ParseReflectProtocol(value); err != nil || got != want {
+			t.Fatalf("ParseReflectProtocol(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseReflectProtocol("v2"); err == nil {
+		t.Fatal("ParseReflectProtocol should reject v2")
+	}
+}
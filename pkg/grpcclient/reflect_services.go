@@ -0,0 +1,162 @@
+package grpcclient
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ReflectionStream is the client side of a ServerReflectionInfo stream.
+type ReflectionStream = grpc_reflection_v1alpha.ServerReflection_ServerReflectionInfoClient
+
+// ListServices asks the reflection stream for the services the server
+// exposes and returns their names in order, without duplicates. The
+// reflection services themselves are included.
+func ListServices(stream ReflectionStream) ([]string, error) {
+	if err := stream.Send(&grpc_reflection_v1alpha.ServerReflectionRequest{
+		MessageRequest: &grpc_reflection_v1alpha.ServerReflectionRequest_ListServices{},
+	}); err != nil {
+		return nil, err
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	list := resp.GetListServicesResponse()
+	if list == nil {
+		return nil, fmt.Errorf("no services found")
+	}
+
+	seen := make(map[string]bool, len(list.GetService()))
+	names := make([]string, 0, len(list.GetService()))
+	for _, svc := range list.GetService() {
+		if name := svc.GetName(); name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// ListMoreServices repeats ListServices and returns only the names missing
+// from known. Some reflection servers answer a ListServices request with
+// part of their services, so a second request can surface the rest.
+func ListMoreServices(stream ReflectionStream, known []string) ([]string, error) {
+	names, err := ListServices(stream)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(known))
+	for _, name := range known {
+		seen[name] = true
+	}
+	var more []string
+	for _, name := range names {
+		if !seen[name] {
+			more = append(more, name)
+		}
+	}
+	return more, nil
+}
+
+// WalkServices calls visit with each service the server exposes, skipping
+// the reflection services, until visit returns true. When the list runs out
+// without visit stopping, for instance because a method was not found,
+// WalkServices asks for the list once more and visits any services that
+// were missing the first time. Only the first ListServices failing is an
+// error; a failed retry ends the walk.
+func WalkServices(stream ReflectionStream, visit func(name string) bool) error {
+	services, err := ListServices(stream)
+	if err != nil {
+		return err
+	}
+	if walkServiceNames(services, visit) {
+		return nil
+	}
+	more, err := ListMoreServices(stream, services)
+	if err != nil {
+		return nil
+	}
+	walkServiceNames(more, visit)
+	return nil
+}
+
+func walkServiceNames(names []string, visit func(name string) bool) bool {
+	for _, name := range names {
+		if IsReflectionService(name) {
+			continue
+		}
+		if visit(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReflectionService reports whether name is one of the gRPC reflection
+// services, which callers leave out of method listings.
+func IsReflectionService(name string) bool {
+	return name == "grpc.reflection.v1alpha.ServerReflection" ||
+		name == "grpc.reflection.v1.ServerReflection"
+}
+
+// ResolveServices returns the descriptor of every service conn exposes
+// through reflection, skipping the reflection services and any service whose
+// descriptors cannot be resolved. conn may use any transport: tcp, unix, a
+// stdio pipe or an in-process listener.
+func ResolveServices(ctx context.Context, conn grpc.ClientConnInterface) ([]protoreflect.ServiceDescriptor, error) {
+	stream, err := OpenReflection(ctx, conn)
+	if err != nil {
+		return nil, fmt.Errorf("reflection not available: %w", err)
+	}
+	var services []protoreflect.ServiceDescriptor
+	err = WalkServices(stream, func(name string) bool {
+		if desc, err := ResolveService(stream, name); err == nil {
+			services = append(services, desc)
+		}
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reflection not available: %w", err)
+	}
+	return services, nil
+}
+
+// MethodInfo describes one method of a service: the structured form of the
+// "service/Method" names ListMethods returns.
+type MethodInfo struct {
+	Service         string `json:"service"`
+	Method          string `json:"method"`
+	Input           string `json:"input"`
+	Output          string `json:"output"`
+	ClientStreaming bool   `json:"client_streaming"`
+	ServerStreaming bool   `json:"server_streaming"`
+}
+
+// FullName is the method's "service/Method" name.
+func (m MethodInfo) FullName() string {
+	return m.Service + "/" + m.Method
+}
+
+// DescribeServiceMethods returns a MethodInfo for every method of services,
+// in order.
+func DescribeServiceMethods(services []protoreflect.ServiceDescriptor) []MethodInfo {
+	var methods []MethodInfo
+	for _, desc := range services {
+		for i := 0; i < desc.Methods().Len(); i++ {
+			method := desc.Methods().Get(i)
+			methods = append(methods, MethodInfo{
+				Service:         string(desc.FullName()),
+				Method:          string(method.Name()),
+				Input:           string(method.Input().FullName()),
+				Output:          string(method.Output().FullName()),
+				ClientStreaming: method.IsStreamingClient(),
+				ServerStreaming: method.IsStreamingServer(),
+			})
+		}
+	}
+	return methods
+}
@@ -0,0 +1,183 @@
+package grpcclient
+
+import (
+	"context"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// partialReflection answers the first ListServices on each stream without
+// hidden, as servers that split their service list across responses do.
+type partialReflection struct {
+	grpc_reflection_v1alpha.ServerReflectionServer
+	hidden string
+
+	mu    sync.Mutex
+	lists int
+}
+
+func (p *partialReflection) ServerReflectionInfo(stream grpc_reflection_v1alpha.ServerReflection_ServerReflectionInfoServer) error {
+	return p.ServerReflectionServer.ServerReflectionInfo(&partialReflectionStream{
+		ServerReflection_ServerReflectionInfoServer: stream,
+		owner: p,
+	})
+}
+
+type partialReflectionStream struct {
+	grpc_reflection_v1alpha.ServerReflection_ServerReflectionInfoServer
+	owner *partialReflection
+	sent  int
+}
+
+func (s *partialReflectionStream) Send(resp *grpc_reflection_v1alpha.ServerReflectionResponse) error {
+	list := resp.GetListServicesResponse()
+	if list == nil {
+		return s.ServerReflection_ServerReflectionInfoServer.Send(resp)
+	}
+	s.owner.mu.Lock()
+	s.owner.lists++
+	s.owner.mu.Unlock()
+
+	s.sent++
+	if s.sent == 1 {
+		var kept []*grpc_reflection_v1alpha.ServiceResponse
+		for _, svc := range list.GetService() {
+			if svc.GetName() != s.owner.hidden {
+				kept = append(kept, svc)
+			}
+		}
+		list.Service = kept
+	}
+	return s.ServerReflection_ServerReflectionInfoServer.Send(resp)
+}
+
+func (p *partialReflection) listCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lists
+}
+
+func startPartialReflectionServer(t *testing.T) (string, *partialReflection) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	partial := &partialReflection{
+		ServerReflectionServer: reflection.NewServer(reflection.ServerOptions{Services: s}),
+		hidden:                 "grpc.health.v1.Health",
+	}
+	grpc_reflection_v1alpha.RegisterServerReflectionServer(s, partial)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String(), partial
+}
+
+func TestListMethodsMergesPartialServiceLists(t *testing.T) {
+	address, partial := startPartialReflectionServer(t)
+
+	methods, err := ListMethods(address)
+	if err != nil {
+		t.Fatalf("ListMethods returned error: %v", err)
+	}
+	if !slices.Contains(methods, "grpc.health.v1.Health/Check") {
+		t.Fatalf("methods = %v; the service missing from the first list should be merged in", methods)
+	}
+	if got := partial.listCount(); got != 2 {
+		t.Fatalf("ListServices requests = %d, want 2", got)
+	}
+}
+
+func TestDialRetriesListServicesOnMethodMiss(t *testing.T) {
+	address, _ := startPartialReflectionServer(t)
+
+	result, err := Dial(address, "Check", "{}")
+	if err != nil {
+		t.Fatalf("Dial returned error: %v", err)
+	}
+	if !strings.Contains(result.Output, "SERVING") {
+		t.Fatalf("output = %q, want a SERVING health status", result.Output)
+	}
+
+	_, err = Dial(address, "NoSuchMethod", "{}")
+	if err == nil || !strings.Contains(err.Error(), "grpc.health.v1.Health") {
+		t.Fatalf("error = %v; available methods should include the merged service", err)
+	}
+}
+
+func TestResolveServicesOverInProcessConn(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	reflection.Register(s)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	services, err := ResolveServices(context.Background(), conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 1 || services[0].FullName() != "grpc.health.v1.Health" || services[0].Methods().ByName("Watch") == nil {
+		t.Fatalf("services = %v, want the health service alone, with its descriptors", services)
+	}
+}
+
+func TestListMethodsDetailedReportsTypesAndStreaming(t *testing.T) {
+	address, _ := startWatchServer(t)
+
+	methods, err := ListMethodsDetailed(address)
+	if err != nil {
+		t.Fatalf("ListMethodsDetailed returned error: %v", err)
+	}
+	byName := make(map[string]MethodInfo, len(methods))
+	for _, method := range methods {
+		byName[method.FullName()] = method
+	}
+	want := map[string]MethodInfo{
+		"grpc.health.v1.Health/Check": {
+			Service: "grpc.health.v1.Health", Method: "Check",
+			Input: "grpc.health.v1.HealthCheckRequest", Output: "grpc.health.v1.HealthCheckResponse",
+		},
+		"grpc.health.v1.Health/Watch": {
+			Service: "grpc.health.v1.Health", Method: "Watch",
+			Input: "grpc.health.v1.HealthCheckRequest", Output: "grpc.health.v1.HealthCheckResponse",
+			ServerStreaming: true,
+		},
+	}
+	for name, info := range want {
+		if byName[name] != info {
+			t.Fatalf("%s = %+v, want %+v", name, byName[name], info)
+		}
+	}
+
+	names, err := ListMethods(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != len(methods) || names[0] != methods[0].FullName() {
+		t.Fatalf("ListMethods = %v, want the names of %+v", names, methods)
+	}
+}
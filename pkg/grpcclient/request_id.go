@@ -0,0 +1,111 @@
+package grpcclient
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDHeader is the metadata key carrying the ID of one client call, so
+// servers that log it can be correlated with the invocation that caused it.
+const RequestIDHeader = "x-request-id"
+
+var (
+	requestIDMu     sync.Mutex
+	pinnedRequestID string
+)
+
+// NewRequestID returns a fresh request ID.
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// SetRequestID pins the ID CallContext attaches to every call. An empty id
+// restores the default of a fresh ID per call. restore puts back the ID set
+// before.
+func SetRequestID(id string) (restore func()) {
+	requestIDMu.Lock()
+	defer requestIDMu.Unlock()
+	previous := pinnedRequestID
+	pinnedRequestID = id
+	return func() {
+		requestIDMu.Lock()
+		defer requestIDMu.Unlock()
+		pinnedRequestID = previous
+	}
+}
+
+// requestIDKey carries, on a context, the ID CallContext attaches to the
+// calls made with it.
+type requestIDKey struct{}
+
+// ContextWithRequestID makes id the x-request-id of every call made with ctx,
+// ahead of the pinned one. Unlike SetRequestID it holds for ctx only, so
+// concurrent calls can each carry their own.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// WithRequestID attaches id to ctx as outgoing x-request-id metadata.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, RequestIDHeader, id)
+}
+
+// callerKey marks a context from CallContext with the caller it was derived
+// from.
+type callerKey struct{}
+
+// caller is the parent CallContext was given and the deadline it added.
+type caller struct {
+	parent   context.Context
+	deadline time.Time
+}
+
+// CallContext returns the context for one RPC derived from parent: bounded by
+// timeout and carrying parent's request ID, else the pinned one, else a
+// fresh one.
+func CallContext(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	id, _ := parent.Value(requestIDKey{}).(string)
+	if id == "" {
+		requestIDMu.Lock()
+		id = pinnedRequestID
+		requestIDMu.Unlock()
+	}
+	if id == "" {
+		id = NewRequestID()
+	}
+
+	c := &caller{parent: parent}
+	ctx, cancel := context.WithTimeout(context.WithValue(parent, callerKey{}, c), timeout)
+	c.deadline, _ = ctx.Deadline()
+	return WithRequestID(ctx, id), cancel
+}
+
+// callerContext returns ctx without the deadline CallContext gave it: its
+// values, with the deadline and cancellation of the caller's own parent.
+// Any other context, including one given a deadline of its own since, is
+// returned as is.
+func callerContext(ctx context.Context) context.Context {
+	c, ok := ctx.Value(callerKey{}).(*caller)
+	if !ok {
+		return ctx
+	}
+	if deadline, _ := ctx.Deadline(); !deadline.Equal(c.deadline) {
+		return ctx
+	}
+	return valuesContext{Context: c.parent, values: ctx}
+}
+
+// valuesContext is Context for deadline and cancellation but looks values up
+// in values.
+type valuesContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c valuesContext) Value(key any) any {
+	return c.values.Value(key)
+}
@@ -0,0 +1,406 @@
+package grpcclient
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/organic-programming/grace-op/internal/didyoumean"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// serverClient is the Client NewClient returns. It keeps one connection
+// and the service descriptors it resolves through reflection, so calling
+// several methods, or one method repeatedly, pays for reflection once.
+type serverClient struct {
+	address string
+	extra   []grpc.DialOption
+	fixed   bool
+
+	mu       sync.Mutex
+	conn     *grpc.ClientConn
+	services []protoreflect.ServiceDescriptor
+	index    methodIndex
+	// unresolved holds, by service name, why the last attempt to resolve
+	// a listed service failed.
+	unresolved map[string]error
+}
+
+// methodIndex maps method names to the resolved services that define
+// them, and keeps the signature of every method for not-found errors.
+type methodIndex struct {
+	byName     map[protoreflect.Name][]protoreflect.ServiceDescriptor
+	signatures []string
+}
+
+func (x *methodIndex) add(svc protoreflect.ServiceDescriptor) {
+	if x.byName == nil {
+		x.byName = make(map[protoreflect.Name][]protoreflect.ServiceDescriptor)
+	}
+	methods := svc.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		x.byName[method.Name()] = append(x.byName[method.Name()], svc)
+		x.signatures = append(x.signatures, MethodSignature(string(svc.FullName()), method))
+	}
+}
+
+// find returns the method called methodName, in the service called
+// serviceName, by full or short name, when serviceName is not empty.
+func (x *methodIndex) find(serviceName, methodName string) (protoreflect.ServiceDescriptor, protoreflect.MethodDescriptor) {
+	for _, svc := range x.byName[protoreflect.Name(methodName)] {
+		if serviceName == "" || serviceName == string(svc.FullName()) || serviceName == string(svc.Name()) {
+			return svc, svc.Methods().ByName(protoreflect.Name(methodName))
+		}
+	}
+	return nil, nil
+}
+
+func newServerClient(address string, extra ...grpc.DialOption) *serverClient {
+	return &serverClient{address: address, extra: extra}
+}
+
+// newConnClient is a serverClient over conn, a connection that cannot be
+// redialed, such as a stdio pipe. name stands for its address in errors
+// and in the descriptor cache.
+func newConnClient(name string, conn *grpc.ClientConn) *serverClient {
+	return &serverClient{address: name, conn: conn, fixed: true}
+}
+
+func (c *serverClient) Call(ctx context.Context, method, inputJSON string) (*CallResult, error) {
+	result, err := c.call(ctx, method, inputJSON)
+	return result, ExplainHTTP1Error(err, c.address, nil)
+}
+
+// call is Call without the HTTP/1.1 diagnosis, which callers over TLS make
+// with their own configuration.
+func (c *serverClient) call(ctx context.Context, method, inputJSON string) (*CallResult, error) {
+	if inputJSON == "" {
+		inputJSON = "{}"
+	}
+	ctx, cancel := CallContext(ctx, Timeout())
+	defer cancel()
+
+	conn, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	return CallStaticFirst(ctx, conn, method, inputJSON, func() (*CallResult, error) {
+		svc, desc, err := c.findMethod(ctx, method)
+		if err != nil {
+			return nil, err
+		}
+		return callMethod(ctx, conn, svc, desc, inputJSON)
+	})
+}
+
+// describe returns method's descriptor, resolved through the client's
+// method index.
+func (c *serverClient) describe(ctx context.Context, method string) (protoreflect.MethodDescriptor, error) {
+	ctx, cancel := context.WithTimeout(ctx, Timeout())
+	defer cancel()
+
+	if _, err := c.connect(); err != nil {
+		return nil, err
+	}
+	_, desc, err := c.findMethod(ctx, method)
+	return desc, err
+}
+
+// methodNotFoundError is findMethod's error for a method no resolved
+// service defines.
+type methodNotFoundError struct {
+	method    string
+	available []string
+	// unresolved lists the services that could not be resolved, any of
+	// which may define the method.
+	unresolved []string
+}
+
+func (e *methodNotFoundError) Error() string {
+	msg := didyoumean.WithHint(fmt.Sprintf("method %q not found", e.method), didyoumean.ClosestMethods(e.method, e.available))
+	msg = fmt.Sprintf("%s. Available: %v", msg, e.available)
+	if len(e.unresolved) > 0 {
+		msg += fmt.Sprintf(" (could not resolve %s)", strings.Join(e.unresolved, "; "))
+	}
+	return msg
+}
+
+func (c *serverClient) ListMethods(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, Timeout())
+	defer cancel()
+
+	// No method has an empty name, so this resolves every service. The
+	// methods of those that fail to resolve are left out.
+	var notFound *methodNotFoundError
+	if _, _, err := c.findMethod(ctx, ""); err != nil && !errors.As(err, &notFound) {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var methods []string
+	for _, method := range DescribeServiceMethods(c.services) {
+		methods = append(methods, method.FullName())
+	}
+	return methods, nil
+}
+
+func (c *serverClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	c.services = nil
+	c.index = methodIndex{}
+	c.unresolved = nil
+	return err
+}
+
+func (c *serverClient) connect() (*grpc.ClientConn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil && c.fixed {
+		return nil, fmt.Errorf("connect to %s: client is closed", c.address)
+	}
+	if c.conn == nil {
+		conn, err := grpc.NewClient(c.address, DialOptions(c.extra...)...)
+		if err != nil {
+			return nil, fmt.Errorf("connect to %s: %w", c.address, err)
+		}
+		c.conn = conn
+	}
+	return c.conn, nil
+}
+
+// findMethod looks method, "Method" or "package.Service/Method", up in the
+// services resolved so far. On a miss it resolves the named service alone,
+// then, if the method is still missing, all the others at once, and looks
+// again. Each service is resolved once per client. A method no resolved
+// service defines is a *methodNotFoundError.
+func (c *serverClient) findMethod(ctx context.Context, method string) (protoreflect.ServiceDescriptor, protoreflect.MethodDescriptor, error) {
+	serviceName, methodName := "", strings.TrimPrefix(method, "/")
+	if i := strings.LastIndex(methodName, "/"); i >= 0 {
+		serviceName, methodName = methodName[:i], methodName[i+1:]
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil, nil, fmt.Errorf("connect to %s: client is closed", c.address)
+	}
+	if svc, desc := c.index.find(serviceName, methodName); desc != nil {
+		return svc, desc, nil
+	}
+
+	stream, err := OpenReflection(ctx, c.conn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reflection not available at %s: %w", c.address, err)
+	}
+	defer stream.CloseSend()
+	names, err := ListServices(stream)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list services at %s: %w", c.address, err)
+	}
+	batches := [][]string{names}
+	if named := servicesNamed(names, serviceName); len(named) > 0 {
+		batches = [][]string{named, names}
+	}
+	for _, batch := range batches {
+		if err := c.resolveServices(stream, batch); err != nil {
+			return nil, nil, fmt.Errorf("resolve services at %s: %w", c.address, err)
+		}
+		if svc, desc := c.index.find(serviceName, methodName); desc != nil {
+			return svc, desc, nil
+		}
+	}
+
+	// As WalkServices does, ask once more for services a partial list
+	// left out; a failed retry only ends the search.
+	if more, err := ListMoreServices(stream, names); err == nil && len(more) > 0 {
+		if err := c.resolveServices(stream, more); err == nil {
+			if svc, desc := c.index.find(serviceName, methodName); desc != nil {
+				return svc, desc, nil
+			}
+		}
+	}
+
+	return nil, nil, c.notFound(method)
+}
+
+// notFound is findMethod's error for method: it lists the methods c
+// resolved and the services it could not.
+func (c *serverClient) notFound(method string) *methodNotFoundError {
+	err := &methodNotFoundError{method: method, available: slices.Clone(c.index.signatures)}
+	for name, cause := range c.unresolved {
+		err.unresolved = append(err.unresolved, fmt.Sprintf("%s: %v", name, cause))
+	}
+	slices.Sort(err.unresolved)
+	return err
+}
+
+// servicesNamed returns the services among names that serviceName, a full
+// or short service name, can stand for.
+func servicesNamed(names []string, serviceName string) []string {
+	if serviceName == "" {
+		return nil
+	}
+	var named []string
+	for _, name := range names {
+		if name == serviceName || strings.HasSuffix(name, "."+serviceName) {
+			named = append(named, name)
+		}
+	}
+	return named
+}
+
+// resolveServices resolves the services among names that c has not
+// resolved yet, reflection services aside, in one pipelined batch, and
+// adds them to c.services and c.index. A service that fails to resolve is
+// left out and its error kept in c.unresolved, to be tried again on the
+// next miss; only a broken stream is an error.
+func (c *serverClient) resolveServices(stream ReflectionStream, names []string) error {
+	resolved := make(map[string]bool, len(c.services))
+	for _, svc := range c.services {
+		resolved[string(svc.FullName())] = true
+	}
+	var pending []string
+	for _, name := range names {
+		if !resolved[name] && !IsReflectionService(name) {
+			resolved[name] = true
+			pending = append(pending, name)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	raw, errs, err := requestSymbolFilesBatch(stream, pending)
+	if err != nil {
+		return err
+	}
+	if c.unresolved == nil {
+		c.unresolved = make(map[string]error)
+	}
+	for i, name := range pending {
+		if errs[i] != nil {
+			c.unresolved[name] = errs[i]
+			continue
+		}
+		svc, err := buildCachedService(stream, c.address, name, raw[i])
+		if err != nil {
+			c.unresolved[name] = err
+			continue
+		}
+		delete(c.unresolved, name)
+		c.services = append(c.services, svc)
+		c.index.add(svc)
+	}
+	return nil
+}
+
+type descriptorCacheKey struct {
+	address string
+	service string
+}
+
+type cachedDescriptor struct {
+	key    descriptorCacheKey
+	digest string
+	desc   protoreflect.ServiceDescriptor
+}
+
+// descriptorCacheSize bounds the descriptors kept across clients; the least
+// recently used one is dropped first.
+const descriptorCacheSize = 256
+
+var (
+	descriptorCacheMu    sync.Mutex
+	descriptorCache      = map[descriptorCacheKey]*list.Element{}
+	descriptorCacheOrder = list.New()
+)
+
+// buildCachedService is buildService with a cache shared by every Client:
+// raw are the files the server sent for serviceName. Those files, and any
+// dependency they lack, are always fetched and hashed; when they match the
+// last resolution at the same address, the descriptor built then is
+// reused.
+func buildCachedService(stream ReflectionStream, address, serviceName string, raw [][]byte) (protoreflect.ServiceDescriptor, error) {
+	files, err := collectServiceFiles(stream, raw)
+	if err != nil {
+		return nil, err
+	}
+	key := descriptorCacheKey{address: address, service: serviceName}
+	digest, err := descriptorDigest(files)
+	if err != nil {
+		return nil, err
+	}
+	if desc, ok := cachedServiceDescriptor(key, digest); ok {
+		return desc, nil
+	}
+
+	desc, err := buildServiceFromFiles(files, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	cacheServiceDescriptor(cachedDescriptor{key: key, digest: digest, desc: desc})
+	return desc, nil
+}
+
+func cachedServiceDescriptor(key descriptorCacheKey, digest string) (protoreflect.ServiceDescriptor, bool) {
+	descriptorCacheMu.Lock()
+	defer descriptorCacheMu.Unlock()
+	elem, ok := descriptorCache[key]
+	if !ok {
+		return nil, false
+	}
+	cached := elem.Value.(cachedDescriptor)
+	if cached.digest != digest {
+		return nil, false
+	}
+	descriptorCacheOrder.MoveToFront(elem)
+	return cached.desc, true
+}
+
+func cacheServiceDescriptor(entry cachedDescriptor) {
+	descriptorCacheMu.Lock()
+	defer descriptorCacheMu.Unlock()
+	if elem, ok := descriptorCache[entry.key]; ok {
+		elem.Value = entry
+		descriptorCacheOrder.MoveToFront(elem)
+		return
+	}
+	descriptorCache[entry.key] = descriptorCacheOrder.PushFront(entry)
+	for descriptorCacheOrder.Len() > descriptorCacheSize {
+		oldest := descriptorCacheOrder.Back()
+		descriptorCacheOrder.Remove(oldest)
+		delete(descriptorCache, oldest.Value.(cachedDescriptor).key)
+	}
+}
+
+// descriptorDigest hashes every file of a service, dependencies included,
+// so a change to any of them is a new digest.
+func descriptorDigest(files []*descriptorpb.FileDescriptorProto) (string, error) {
+	h := sha256.New()
+	for _, file := range files {
+		b, err := proto.MarshalOptions{Deterministic: true}.Marshal(file)
+		if err != nil {
+			return "", fmt.Errorf("hash descriptor %s: %w", file.GetName(), err)
+		}
+		fmt.Fprintf(h, "%d:", len(b))
+		h.Write(b)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,442 @@
+package grpcclient
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc"
+	channelzservice "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// startCountingReflectionServer serves grpc.health.v1 with reflection and
+// counts the reflection streams clients open.
+func startCountingReflectionServer(tb testing.TB) (string, *atomic.Int32) {
+	tb.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	streams := &atomic.Int32{}
+	s := grpc.NewServer(grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.Contains(info.FullMethod, "ServerReflection") {
+			streams.Add(1)
+		}
+		return handler(srv, ss)
+	}))
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	reflection.Register(s)
+	go func() { _ = s.Serve(lis) }()
+	tb.Cleanup(s.Stop)
+	return lis.Addr().String(), streams
+}
+
+func TestClientResolvesDescriptorsOnce(t *testing.T) {
+	address, streams := startCountingReflectionServer(t)
+	client := NewClient(address)
+	defer client.Close()
+
+	for i := 0; i < 10; i++ {
+		result, err := client.Call(context.Background(), "Check", "")
+		if err != nil {
+			t.Fatalf("call %d: %v", i+1, err)
+		}
+		if !strings.Contains(result.Output, "SERVING") {
+			t.Fatalf("call %d output = %q, want a SERVING status", i+1, result.Output)
+		}
+	}
+	if got := streams.Load(); got != 1 {
+		t.Fatalf("10 calls opened %d reflection streams, want 1", got)
+	}
+
+	if _, err := client.Call(context.Background(), "Chek", ""); err == nil || !strings.Contains(err.Error(), "grpc.health.v1.Health/Check") {
+		t.Fatalf("unknown method error = %v, want the resolved methods listed", err)
+	}
+}
+
+// symbolCountingStream counts, per symbol, the FileContainingSymbol
+// requests a reflection stream receives.
+type symbolCountingStream struct {
+	grpc.ServerStream
+	mu      *sync.Mutex
+	symbols map[string]int
+}
+
+func (s symbolCountingStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if req, ok := m.(interface{ GetFileContainingSymbol() string }); ok && req.GetFileContainingSymbol() != "" {
+		s.mu.Lock()
+		s.symbols[req.GetFileContainingSymbol()]++
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+// staleHealthService is a loaded grpc.health.v1.Health whose Check takes a
+// request the server's own Check does not: a descriptor set out of date.
+func staleHealthService(t *testing.T) protoreflect.ServiceDescriptor {
+	t.Helper()
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("stale_health.proto"),
+		Package: proto.String("grpc.health.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Probe"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("probe"),
+				JsonName: proto.String("probe"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			}},
+		}},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Health"),
+			Method: []*descriptorpb.MethodDescriptorProto{{
+				Name:       proto.String("Check"),
+				InputType:  proto.String(".grpc.health.v1.Probe"),
+				OutputType: proto.String(".grpc.health.v1.Probe"),
+			}},
+		}},
+	}, new(protoregistry.Files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file.Services().Get(0)
+}
+
+func TestCallBareStaticMethodSkipsReflection(t *testing.T) {
+	address, streams := startCountingReflectionServer(t)
+	t.Cleanup(SetDescriptorServices([]protoreflect.ServiceDescriptor{healthpb.File_grpc_health_v1_health_proto.Services().ByName("Health")}))
+
+	result, err := Dial(address, "Check", `{"service":""}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.Output, "SERVING") {
+		t.Fatalf("output = %q, want a SERVING status", result.Output)
+	}
+	if got := streams.Load(); got != 0 {
+		t.Fatalf("a bare static method opened %d reflection streams, want none", got)
+	}
+}
+
+func TestCallFallsBackFromStaleStaticDescriptors(t *testing.T) {
+	address, _ := startCountingReflectionServer(t)
+	t.Cleanup(SetDescriptorServices([]protoreflect.ServiceDescriptor{staleHealthService(t)}))
+
+	// Both names try the loaded descriptor first and, as the request does
+	// not fit it, fall back to the server's own Check through reflection.
+	for _, method := range []string{"Check", "grpc.health.v1.Health/Check"} {
+		result, err := Dial(address, method, `{"service":""}`)
+		if err != nil {
+			t.Fatalf("Dial(%s): %v", method, err)
+		}
+		if !strings.Contains(result.Output, "SERVING") {
+			t.Fatalf("Dial(%s) output = %q, want a SERVING status", method, result.Output)
+		}
+	}
+}
+
+// startSymbolCountingServer serves grpc.health.v1 and grpc.channelz.v1
+// with reflection and counts, by symbol, the FileContainingSymbol requests
+// it answers.
+func startSymbolCountingServer(t *testing.T) (string, *sync.Mutex, map[string]int) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	symbols := map[string]int{}
+	s := grpc.NewServer(grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, symbolCountingStream{ServerStream: ss, mu: &mu, symbols: symbols})
+	}))
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	channelzservice.RegisterChannelzServiceToServer(s)
+	reflection.Register(s)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String(), &mu, symbols
+}
+
+func TestDialNotFoundListsMethodsResolvedOnce(t *testing.T) {
+	address, mu, symbols := startSymbolCountingServer(t)
+
+	_, err := Dial(address, "NoSuchMethod", "{}")
+	if err == nil {
+		t.Fatal("Dial found a method that does not exist")
+	}
+	for _, want := range []string{"grpc.health.v1.Health/Check", "grpc.channelz.v1.Channelz/GetTopChannels"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error = %v, want %s listed", err, want)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, service := range []string{"grpc.health.v1.Health", "grpc.channelz.v1.Channelz"} {
+		if got := symbols[service]; got != 1 {
+			t.Fatalf("%s was resolved %d times, want 1 (all requests: %v)", service, got, symbols)
+		}
+	}
+}
+
+func TestQualifiedMethodResolvesOnlyItsService(t *testing.T) {
+	address, mu, symbols := startSymbolCountingServer(t)
+
+	client := NewClient(address)
+	defer client.Close()
+	if _, err := client.Call(context.Background(), "grpc.health.v1.Health/Check", "{}"); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if symbols["grpc.health.v1.Health"] != 1 || symbols["grpc.channelz.v1.Channelz"] != 0 {
+		t.Fatalf("resolved %v, want grpc.health.v1.Health alone", symbols)
+	}
+}
+
+func TestDescribeMethodResolvesServicesOnce(t *testing.T) {
+	address, mu, symbols := startSymbolCountingServer(t)
+
+	desc, err := DescribeMethod(address, "grpc.health.v1.Health/Check")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.FullName() != "grpc.health.v1.Health.Check" {
+		t.Fatalf("described %s, want grpc.health.v1.Health.Check", desc.FullName())
+	}
+	if _, err := DescribeMethod(address, "grpc.channelz.v1.Channelz/Check"); err == nil {
+		t.Fatal("Check was described under a service that does not define it")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if symbols["grpc.health.v1.Health"] != 2 || symbols["grpc.channelz.v1.Channelz"] != 1 {
+		t.Fatalf("resolved %v, want each service once per lookup", symbols)
+	}
+}
+
+func TestMethodNotFoundNamesUnresolvedServices(t *testing.T) {
+	c := &serverClient{address: "unresolved-test:1"}
+	forgetCachedDescriptor(t, c.address)
+
+	if err := c.resolveServices(newPrefixedReflectionStream(""), []string{"greet.Greeter", "gone.Service"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.services) != 1 || c.unresolved["gone.Service"] == nil {
+		t.Fatalf("services = %d, unresolved = %v, want greet.Greeter resolved and gone.Service not", len(c.services), c.unresolved)
+	}
+
+	err := c.notFound("Vanish")
+	for _, want := range []string{"greet.Greeter/Echo", "could not resolve gone.Service: no file descriptor for gone.Service"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error = %q, want %q", err, want)
+		}
+	}
+}
+
+func TestSymbolBatchRefusesAnUnsettledAutoStream(t *testing.T) {
+	stream := &autoReflectionStream{ReflectionStream: newPrefixedReflectionStream("")}
+	if _, _, err := requestSymbolFilesBatch(stream, []string{"greet.Greeter"}); err == nil || !strings.Contains(err.Error(), "not settled") {
+		t.Fatalf("batch on an unsettled auto stream = %v, want it refused", err)
+	}
+
+	stream.decided = true
+	if _, errs, err := requestSymbolFilesBatch(stream, []string{"greet.Greeter"}); err != nil || errs[0] != nil {
+		t.Fatalf("batch on a settled stream = %v, %v", err, errs)
+	}
+}
+
+// forgetCachedDescriptor drops address's greet.Greeter from the cache.
+func forgetCachedDescriptor(t *testing.T, address string) {
+	t.Cleanup(func() {
+		descriptorCacheMu.Lock()
+		defer descriptorCacheMu.Unlock()
+		key := descriptorCacheKey{address: address, service: "greet.Greeter"}
+		if elem, ok := descriptorCache[key]; ok {
+			descriptorCacheOrder.Remove(elem)
+			delete(descriptorCache, key)
+		}
+	})
+}
+
+// greeterServices advertises greet.Greeter to reflection.
+type greeterServices struct{}
+
+func (greeterServices) GetServiceInfo() map[string]grpc.ServiceInfo {
+	return map[string]grpc.ServiceInfo{"greet.Greeter": {}}
+}
+
+// serveGreeterReflection serves reflection, and nothing else, for the
+// greet.Greeter files of stream, on address. It returns the address it
+// listens on and a func that stops the server.
+func serveGreeterReflection(t *testing.T, address string, stream *prefixedReflectionStream) (string, func()) {
+	t.Helper()
+
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		stream.files["common/msg.proto"],
+		stream.files["greeter.proto"],
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	opts := reflection.ServerOptions{Services: greeterServices{}, DescriptorResolver: files}
+	grpc_reflection_v1.RegisterServerReflectionServer(s, reflection.NewServerV1(opts))
+	grpc_reflection_v1alpha.RegisterServerReflectionServer(s, reflection.NewServer(opts))
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String(), s.Stop
+}
+
+// greeterThroughClient calls greet.Greeter/Echo at address with a new
+// Client and returns the descriptor the client resolved for the service.
+// Nothing implements the service, so the call itself fails.
+func greeterThroughClient(t *testing.T, address string) protoreflect.ServiceDescriptor {
+	t.Helper()
+
+	client := NewClient(address)
+	defer client.Close()
+	if _, err := client.Call(context.Background(), "greet.Greeter/Echo", "{}"); status.Code(err) != codes.Unimplemented {
+		t.Fatalf("call = %v, want Unimplemented once the method is resolved", err)
+	}
+	services := client.(*serverClient).services
+	if len(services) != 1 {
+		t.Fatalf("client resolved %d services, want greet.Greeter", len(services))
+	}
+	return services[0]
+}
+
+func TestClientReusesMatchingCachedDescriptors(t *testing.T) {
+	address, stop := serveGreeterReflection(t, "127.0.0.1:0", newPrefixedReflectionStream(""))
+	forgetCachedDescriptor(t, address)
+
+	desc := greeterThroughClient(t, address)
+	if cached := greeterThroughClient(t, address); cached != desc {
+		t.Fatal("unchanged descriptors were built again")
+	}
+
+	stop()
+	changed := newPrefixedReflectionStream("")
+	greeter := changed.files["greeter.proto"]
+	greeter.Service[0].Method = append(greeter.Service[0].Method, &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String("Shout"),
+		InputType:  proto.String(".common.Ping"),
+		OutputType: proto.String(".common.Ping"),
+	})
+	serveGreeterReflection(t, address, changed)
+	if greeterThroughClient(t, address).Methods().ByName("Shout") == nil {
+		t.Fatal("a changed service file was not rebuilt")
+	}
+}
+
+func TestClientRebuildsCachedDescriptorsOnChangedDependency(t *testing.T) {
+	address, stop := serveGreeterReflection(t, "127.0.0.1:0", newPrefixedReflectionStream(""))
+	forgetCachedDescriptor(t, address)
+	greeterThroughClient(t, address)
+
+	stop()
+	changed := newPrefixedReflectionStream("")
+	ping := changed.files["common/msg.proto"].MessageType[0]
+	ping.Field = append(ping.Field, &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String("note"),
+		JsonName: proto.String("note"),
+		Number:   proto.Int32(1),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+	})
+	serveGreeterReflection(t, address, changed)
+	if greeterThroughClient(t, address).Methods().ByName("Echo").Input().Fields().ByName("note") == nil {
+		t.Fatal("a changed dependency kept the stale descriptor")
+	}
+}
+
+func TestDescriptorCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	descriptorCacheMu.Lock()
+	saved, savedOrder := descriptorCache, descriptorCacheOrder
+	descriptorCache, descriptorCacheOrder = map[descriptorCacheKey]*list.Element{}, list.New()
+	descriptorCacheMu.Unlock()
+	t.Cleanup(func() {
+		descriptorCacheMu.Lock()
+		descriptorCache, descriptorCacheOrder = saved, savedOrder
+		descriptorCacheMu.Unlock()
+	})
+
+	first := descriptorCacheKey{address: "evict:0", service: "greet.Greeter"}
+	cacheServiceDescriptor(cachedDescriptor{key: first, digest: "d"})
+	for i := 1; i <= descriptorCacheSize; i++ {
+		if i == descriptorCacheSize/2 {
+			// Touching the first entry keeps it over the ones added since.
+			if _, ok := cachedServiceDescriptor(first, "d"); !ok {
+				t.Fatal("first entry was evicted early")
+			}
+		}
+		cacheServiceDescriptor(cachedDescriptor{key: descriptorCacheKey{address: fmt.Sprintf("evict:%d", i), service: "greet.Greeter"}, digest: "d"})
+	}
+
+	if got := len(descriptorCache); got != descriptorCacheSize {
+		t.Fatalf("cache holds %d entries, want %d", got, descriptorCacheSize)
+	}
+	if _, ok := cachedServiceDescriptor(first, "d"); !ok {
+		t.Fatal("the recently used entry was evicted")
+	}
+	if _, ok := cachedServiceDescriptor(descriptorCacheKey{address: "evict:1", service: "greet.Greeter"}, "d"); ok {
+		t.Fatal("the least recently used entry was kept")
+	}
+}
+
+// BenchmarkTenCalls compares ten calls made with Dial, which reflects on
+// every call, with ten calls on one Client, which reflects once.
+func BenchmarkTenCalls(b *testing.B) {
+	address, _ := startCountingReflectionServer(b)
+	ctx := context.Background()
+
+	b.Run("Dial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for call := 0; call < 10; call++ {
+				if _, err := Dial(address, "Check", "{}"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("Client", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			client := NewClient(address)
+			for call := 0; call < 10; call++ {
+				if _, err := client.Call(ctx, "Check", "{}"); err != nil {
+					b.Fatal(err)
+				}
+			}
+			_ = client.Close()
+		}
+	})
+}
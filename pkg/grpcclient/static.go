@@ -0,0 +1,108 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	opv1 "github.com/organic-programming/grace-op/gen/go/op/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// staticServices are services whose descriptors are compiled into op.
+// Calls to their methods skip the reflection round-trip.
+var staticServices = []protoreflect.ServiceDescriptor{
+	opv1.File_op_v1_op_proto.Services().ByName("OPService"),
+}
+
+// LookupStaticMethod finds method among the compiled-in services and those
+// loaded with SetDescriptorServices. It accepts
+// a bare method name ("ListIdentities") or one qualified by the service
+// ("OPService/ListIdentities", "op.v1.OPService/ListIdentities").
+func LookupStaticMethod(method string) (protoreflect.ServiceDescriptor, protoreflect.MethodDescriptor, bool) {
+	method = strings.TrimPrefix(strings.TrimSpace(method), "/")
+	serviceName := ""
+	if i := strings.LastIndex(method, "/"); i >= 0 {
+		serviceName, method = method[:i], method[i+1:]
+	}
+
+	for _, svc := range knownServices() {
+		if serviceName != "" && serviceName != string(svc.FullName()) && serviceName != string(svc.Name()) {
+			continue
+		}
+		if m := svc.Methods().ByName(protoreflect.Name(method)); m != nil {
+			return svc, m, true
+		}
+	}
+	return nil, nil, false
+}
+
+// InvokeStatic calls method over conn using compiled-in or loaded descriptors. ok is
+// false when the method is not known statically, when the server does not
+// implement that service, or when the request does not fit the known input
+// type, in which case the caller falls back to reflection.
+func InvokeStatic(ctx context.Context, conn *grpc.ClientConn, method, inputJSON string) (result *CallResult, ok bool, err error) {
+	svc, m, found := LookupStaticMethod(method)
+	if !found {
+		return nil, false, nil
+	}
+
+	result, err = callMethod(ctx, conn, svc, m, inputJSON)
+	if status.Code(err) == codes.Unimplemented {
+		return nil, false, nil
+	}
+	// Only the request of a call is retried: a later line of a client
+	// stream fails wrapped, once earlier ones were sent.
+	if _, ok := err.(*inputError); ok {
+		return nil, false, nil
+	}
+	return result, true, err
+}
+
+// CallStaticFirst calls method over conn through the compiled-in or loaded
+// descriptors, which need no reflection round-trip, and through
+// viaReflection when they do not know the method, the server answers
+// Unimplemented, or the request does not fit them. The result's Resolution
+// says which of the two resolved the method.
+func CallStaticFirst(ctx context.Context, conn *grpc.ClientConn, method, inputJSON string, viaReflection func() (*CallResult, error)) (*CallResult, error) {
+	if result, ok, err := InvokeStatic(ctx, conn, method, inputJSON); ok {
+		if result != nil {
+			result.Resolution = ResolvedByDescriptors
+		}
+		return result, err
+	}
+	result, err := viaReflection()
+	if result != nil && result.Resolution == "" {
+		result.Resolution = ResolvedByReflection
+	}
+	return result, err
+}
+
+// MethodSignature renders method as "service/Method(InputType)" for listings
+// that help pick between methods, such as method-not-found errors.
+func MethodSignature(service string, method protoreflect.MethodDescriptor) string {
+	return fmt.Sprintf("%s/%s(%s)", service, method.Name(), method.Input().Name())
+}
+
+// DescribeMethod resolves method's descriptor at address through reflection,
+// resolving the server's services in one batch as a Client call does. A
+// server without reflection can still be described when the method belongs
+// to a compiled-in service.
+func DescribeMethod(address, method string) (protoreflect.MethodDescriptor, error) {
+	client := newServerClient(address)
+	defer client.Close()
+
+	desc, err := client.describe(context.Background(), strings.TrimSpace(method))
+	var notFound *methodNotFoundError
+	if err != nil && !errors.As(err, &notFound) {
+		if _, m, ok := LookupStaticMethod(method); ok {
+			return m, nil
+		}
+	}
+	return desc, err
+}
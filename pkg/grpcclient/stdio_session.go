@@ -0,0 +1,73 @@
+package grpcclient
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// stdioStopGrace is how long a stopped stdio holon has to exit after
+// SIGTERM before it is killed.
+const stdioStopGrace = 5 * time.Second
+
+// StdioSession keeps one holon serving on stdio:// and calls its methods
+// over the same pipe, so a series of calls pays for the process start and
+// the HTTP/2 handshake once. Descriptors resolved for one call are reused
+// by the next.
+type StdioSession struct {
+	cmd    *exec.Cmd
+	client *serverClient
+	once   sync.Once
+}
+
+// StartStdioSession starts cmd, which must serve gRPC on its stdin and
+// stdout, and connects to it. ctx bounds the startup only; each Call has
+// its own timeout. The session owns cmd: on error it has already been
+// killed and reaped, otherwise Close stops it.
+func StartStdioSession(ctx context.Context, cmd *exec.Cmd) (*StdioSession, error) {
+	conn, _, err := dialStdioPipe(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return &StdioSession{cmd: cmd, client: newConnClient("stdio://"+cmd.Path, conn)}, nil
+}
+
+// Call calls method, "Method" or "package.Service/Method", with inputJSON.
+func (s *StdioSession) Call(ctx context.Context, method, inputJSON string) (*CallResult, error) {
+	return s.client.call(ctx, method, inputJSON)
+}
+
+// Close closes the connection and stops the process with StopCommand. It
+// may be called more than once.
+func (s *StdioSession) Close() {
+	s.once.Do(func() {
+		_ = s.client.Close()
+		StopCommand(s.cmd, stdioStopGrace)
+	})
+}
+
+// StopCommand sends SIGTERM to a started cmd, kills it if it is still
+// running after grace, and always reaps it. A process that already exited
+// is only reaped.
+func StopCommand(cmd *exec.Cmd, grace time.Duration) {
+	if cmd.Process == nil {
+		return
+	}
+	// Best effort: the process may have exited already, and Windows has
+	// no SIGTERM, in which case the kill below stops it.
+	_ = cmd.Process.Signal(syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(grace):
+		_ = cmd.Process.Kill()
+		<-done
+	}
+}
@@ -0,0 +1,121 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+const envStdioHelper = "OP_TEST_STDIO_SERVER_LOG"
+
+// TestStdioHelperProcess is not a test: run by stdioHelperCommand, it
+// appends a line to the file named by $OP_TEST_STDIO_SERVER_LOG and serves
+// grpc.health.v1 with reflection on its stdin and stdout until killed.
+func TestStdioHelperProcess(t *testing.T) {
+	logPath := os.Getenv(envStdioHelper)
+	if logPath == "" {
+		return
+	}
+	if f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644); err == nil {
+		_, _ = f.WriteString("started\n")
+		_ = f.Close()
+	}
+	s := grpc.NewServer()
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	reflection.Register(s)
+	_ = s.Serve(newStdioListener())
+	os.Exit(0)
+}
+
+func stdioHelperCommand(t *testing.T) (*exec.Cmd, string) {
+	t.Helper()
+	logPath := t.TempDir() + "/starts.log"
+	cmd := exec.Command(os.Args[0], "-test.run=^TestStdioHelperProcess$")
+	cmd.Env = append(os.Environ(), envStdioHelper+"="+logPath)
+	return cmd, logPath
+}
+
+func TestStdioSessionCallsOneProcess(t *testing.T) {
+	cmd, logPath := stdioHelperCommand(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	session, err := StartStdioSession(ctx, cmd)
+	if err != nil {
+		t.Fatalf("StartStdioSession: %v", err)
+	}
+	defer session.Close()
+
+	for i := 0; i < 3; i++ {
+		result, err := session.Call(context.Background(), "Check", "")
+		if err != nil {
+			t.Fatalf("call %d: %v", i+1, err)
+		}
+		if !strings.Contains(result.Output, "SERVING") {
+			t.Fatalf("call %d output = %q, want a SERVING status", i+1, result.Output)
+		}
+	}
+	if _, err := session.Call(context.Background(), "Chek", ""); err == nil {
+		t.Fatal("an unknown method should fail")
+	}
+	if _, err := session.Call(context.Background(), "grpc.health.v1.Health/Check", ""); err != nil {
+		t.Fatalf("a call after a failed one: %v", err)
+	}
+
+	starts, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(starts), "started"); n != 1 {
+		t.Fatalf("the holon started %d times, want once", n)
+	}
+
+	session.Close()
+	if cmd.ProcessState == nil {
+		t.Fatal("Close did not wait for the holon process")
+	}
+	session.Close()
+	if _, err := session.Call(context.Background(), "Check", ""); err == nil {
+		t.Fatal("a call after Close should fail")
+	}
+}
+
+// stdioListener accepts one connection made of the process's stdin and
+// stdout, then blocks until closed.
+type stdioListener struct {
+	once   sync.Once
+	conns  chan net.Conn
+	closed chan struct{}
+}
+
+func newStdioListener() *stdioListener {
+	l := &stdioListener{conns: make(chan net.Conn, 1), closed: make(chan struct{})}
+	l.conns <- &pipeConn{reader: os.Stdin, writer: os.Stdout}
+	return l
+}
+
+func (l *stdioListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, errors.New("listener closed")
+	}
+}
+
+func (l *stdioListener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *stdioListener) Addr() net.Addr { return pipeAddr{} }
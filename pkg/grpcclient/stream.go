@@ -0,0 +1,183 @@
+package grpcclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// StreamSink is where a streaming call reads its requests and delivers its
+// responses.
+type StreamSink struct {
+	// Input holds newline-delimited JSON requests for a client-streaming
+	// method. When nil, the call's input JSON is sent as the only request.
+	Input io.Reader
+	// Emit receives each response as it arrives. Output is compact JSON on
+	// a single line.
+	Emit func(*CallResult) error
+}
+
+type streamSinkKey struct{}
+
+// WithStream lets calls made with ctx invoke streaming methods. Without a
+// sink, calling a streaming method fails.
+func WithStream(ctx context.Context, sink StreamSink) context.Context {
+	return context.WithValue(ctx, streamSinkKey{}, sink)
+}
+
+func streamSinkFrom(ctx context.Context) (StreamSink, bool) {
+	sink, ok := ctx.Value(streamSinkKey{}).(StreamSink)
+	return sink, ok && sink.Emit != nil
+}
+
+// WriteNDJSON returns an Emit func writing each response to w as one line of
+// JSON.
+func WriteNDJSON(w io.Writer) func(*CallResult) error {
+	return func(result *CallResult) error {
+		_, err := fmt.Fprintln(w, result.Output)
+		return err
+	}
+}
+
+// IsStreaming reports whether method streams requests, responses or both.
+func IsStreaming(method protoreflect.MethodDescriptor) bool {
+	return method.IsStreamingClient() || method.IsStreamingServer()
+}
+
+// callStream runs a streaming method. Requests are sent from their own
+// goroutine so that, on a bidirectional stream, responses are emitted while
+// input is still being read. The result only names the method; its messages
+// went to the sink.
+func callStream(ctx context.Context, conn *grpc.ClientConn, svc protoreflect.ServiceDescriptor, method protoreflect.MethodDescriptor, inputJSON string) (*CallResult, error) {
+	fullMethod := fmt.Sprintf("/%s/%s", svc.FullName(), method.Name())
+	sink, ok := streamSinkFrom(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%s is a streaming method, which this transport cannot call", fullMethod)
+	}
+
+	ctx, cancel := streamContext(ctx, svc.FullName(), method.Name())
+	defer cancel()
+
+	desc := &grpc.StreamDesc{
+		StreamName:    string(method.Name()),
+		ServerStreams: method.IsStreamingServer(),
+		ClientStreams: method.IsStreamingClient(),
+	}
+	stream, err := conn.NewStream(ctx, desc, fullMethod, CallOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("call %s: %w", fullMethod, ExplainCodecError(err))
+	}
+
+	// A request that fails to send cancels the call, so the receive loop
+	// below does not wait on a server still expecting input.
+	sent := make(chan error, 1)
+	go func() {
+		err := sendRequests(stream, method.Input(), method.IsStreamingClient(), inputJSON, sink.Input)
+		sent <- err
+		if err != nil {
+			cancel()
+		}
+	}()
+
+	for {
+		outputMsg := dynamicpb.NewMessage(method.Output())
+		err := stream.RecvMsg(outputMsg)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			select {
+			case sendErr := <-sent:
+				if sendErr != nil {
+					return nil, sendErr
+				}
+			default:
+			}
+			return nil, fmt.Errorf("call %s: %w", fullMethod, ExplainCodecError(err))
+		}
+		result, err := streamedResult(svc, method, outputMsg)
+		if err != nil {
+			return nil, err
+		}
+		if err := sink.Emit(result); err != nil {
+			return nil, err
+		}
+	}
+	if err := <-sent; err != nil {
+		return nil, err
+	}
+
+	return &CallResult{
+		Service:  string(svc.FullName()),
+		Method:   string(method.Name()),
+		Streamed: true,
+	}, nil
+}
+
+// sendRequests sends the requests of one call and closes the send side.
+// Only a client-streaming method reads them from input, one JSON object per
+// line; blank lines are skipped.
+func sendRequests(stream grpc.ClientStream, inputDesc protoreflect.MessageDescriptor, clientStreams bool, inputJSON string, input io.Reader) error {
+	send := func(data []byte) error {
+		msg := dynamicpb.NewMessage(inputDesc)
+		if err := ParseInput(data, msg); err != nil {
+			return &inputError{err: err}
+		}
+		// io.EOF means the server ended the call; RecvMsg reports why.
+		if err := stream.SendMsg(msg); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		return nil
+	}
+
+	if input == nil || !clientStreams {
+		if err := send([]byte(inputJSON)); err != nil {
+			return err
+		}
+		return stream.CloseSend()
+	}
+
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxInputSize()+1)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		if err := send(scanner.Bytes()); err != nil {
+			return fmt.Errorf("request line %d: %w", line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read requests: %w", err)
+	}
+	return stream.CloseSend()
+}
+
+// streamedResult renders one response of a stream as a CallResult.
+func streamedResult(svc protoreflect.ServiceDescriptor, method protoreflect.MethodDescriptor, msg proto.Message) (*CallResult, error) {
+	wire, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("marshal output: %w", err)
+	}
+	// Compact protojson never spans lines, so each message is one line.
+	output, err := MarshalOutput(msg)
+	if err != nil {
+		return nil, fmt.Errorf("marshal output: %w", err)
+	}
+	return &CallResult{
+		Service: string(svc.FullName()),
+		Method:  string(method.Name()),
+		Output:  string(output),
+		Wire:    wire,
+	}, nil
+}
@@ -0,0 +1,90 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func startWatchServer(t *testing.T) (string, *health.Server) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(s, hs)
+	reflection.Register(s)
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+	return lis.Addr().String(), hs
+}
+
+func TestServerStreamEmitsEachResponse(t *testing.T) {
+	address, hs := startWatchServer(t)
+
+	errStop := errors.New("seen enough")
+	var lines []string
+	ctx := WithStream(context.Background(), StreamSink{Emit: func(result *CallResult) error {
+		lines = append(lines, result.Output)
+		if len(lines) == 1 {
+			hs.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+			return nil
+		}
+		return errStop
+	}})
+	_, err := DialContext(ctx, address, "Watch", "{}")
+	if !errors.Is(err, errStop) {
+		t.Fatalf("error = %v, want the emitter's", err)
+	}
+	if len(lines) != 2 || !strings.Contains(lines[0], `"SERVING"`) || !strings.Contains(lines[1], "NOT_SERVING") {
+		t.Fatalf("lines = %q, want SERVING then NOT_SERVING", lines)
+	}
+	for _, line := range lines {
+		if strings.Contains(line, "\n") {
+			t.Fatalf("line %q spans lines", line)
+		}
+	}
+
+	if _, err := Dial(address, "Watch", "{}"); err == nil || !strings.Contains(err.Error(), "streaming method") {
+		t.Fatalf("error = %v; without a sink a streaming call should be refused", err)
+	}
+}
+
+func TestBidiStreamSendsEachInputLine(t *testing.T) {
+	address, _ := startWatchServer(t)
+	t.Cleanup(SetDescriptorServices([]protoreflect.ServiceDescriptor{
+		grpc_reflection_v1alpha.File_grpc_reflection_v1alpha_reflection_proto.Services().ByName("ServerReflection"),
+	}))
+
+	var out strings.Builder
+	input := `{"listServices":""}` + "\n\n" + `{"fileContainingSymbol":"grpc.health.v1.Health"}` + "\n"
+	ctx := WithStream(context.Background(), StreamSink{Input: strings.NewReader(input), Emit: WriteNDJSON(&out)})
+	result, err := DialContext(ctx, address, "ServerReflectionInfo", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Streamed {
+		t.Fatal("result should be marked as streamed")
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "listServicesResponse") || !strings.Contains(lines[1], "fileDescriptorResponse") {
+		t.Fatalf("output = %q, want one response per request line", out.String())
+	}
+
+	bad := WithStream(context.Background(), StreamSink{Input: strings.NewReader("{\"listServices\":\"\"}\nnot json\n"), Emit: WriteNDJSON(&out)})
+	if _, err := DialContext(bad, address, "ServerReflectionInfo", ""); err == nil || !strings.Contains(err.Error(), "request line 2") {
+		t.Fatalf("error = %v, want the bad request line reported", err)
+	}
+}
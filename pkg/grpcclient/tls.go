@@ -0,0 +1,71 @@
+package grpcclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// LoadTLSConfig builds the client TLS configuration for grpcs:// calls. The
+// server certificate is verified against the PEM bundle in caFile, or the
+// system roots when caFile is empty. certFile and keyFile, given together,
+// present a client certificate for mutual TLS.
+func LoadTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	conf := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates in %s", caFile)
+		}
+		conf.RootCAs = pool
+	}
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("a client certificate needs both a certificate and a key")
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return conf, nil
+}
+
+// TLSDialOption makes a connection use TLS with conf in place of the
+// plaintext transport DialOptions starts from.
+func TLSDialOption(conf *tls.Config) grpc.DialOption {
+	return grpc.WithTransportCredentials(credentials.NewTLS(conf))
+}
+
+// DialTLS is Dial over TLS: the server certificate is verified per tlsConf
+// before reflection and the call run as they do in plaintext.
+func DialTLS(address, methodName, inputJSON string, tlsConf *tls.Config) (*CallResult, error) {
+	return DialTLSContext(context.Background(), address, methodName, inputJSON, tlsConf)
+}
+
+// DialTLSContext is DialTLS bound to ctx.
+func DialTLSContext(ctx context.Context, address, methodName, inputJSON string, tlsConf *tls.Config) (*CallResult, error) {
+	result, err := dialAndCall(ctx, address, methodName, inputJSON, TLSDialOption(tlsConf))
+	return result, ExplainHTTP1Error(ExplainTLSError(err, address), address, tlsConf)
+}
+
+// ExplainTLSError replaces the handshake failure gRPC reports when a TLS
+// client reaches a plaintext server with an error saying so. Other errors,
+// including nil, are returned unchanged.
+func ExplainTLSError(err error, address string) error {
+	if err != nil && strings.Contains(err.Error(), "first record does not look like a TLS handshake") {
+		return fmt.Errorf("%s answered in plaintext, not TLS; use grpc:// for servers without TLS", address)
+	}
+	return err
+}